@@ -1,23 +1,36 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
-	"github.com/ideaspaper/projector/pkg/config"
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/output"
-	"github.com/ideaspaper/projector/pkg/storage"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
 )
 
 var (
 	// add command flags
-	addName    string
-	addTags    []string
-	addEnabled bool
+	addName        string
+	addTags        []string
+	addEnabled     bool
+	addDescription string
+	addNotes       string
+	addScan        bool
+	addIcon        string
+	addColor       string
+	addParent      string
+	addInteractive bool
 )
 
 // addCmd represents the add command
@@ -28,6 +41,20 @@ var addCmd = &cobra.Command{
 
 If no path is provided, the current directory is used.
 
+With --scan, the remote URL of a Git or Mercurial working copy is stored
+with the project and its host and owner (e.g. github.com and acme for
+git@github.com:acme/api.git) are added as tags.
+
+With --interactive, the path, name, tags and description are asked for
+in turn, with the path, name and tags given as arguments or flags
+offered as defaults. A path that does not exist is completed to the
+folders it is the start of.
+
+With --parent, the project is a subproject of another project whose
+folder contains it, such as a service in a monorepo. Lists show
+subprojects under their parent and 'projector open --root' opens the
+parent's folder instead.
+
 Examples:
   # Add current directory as a project
   projector add
@@ -39,7 +66,19 @@ Examples:
   projector add ~/projects/myapp --name "My Application"
 
   # Add with tags
-  projector add --name "Work Project" --tag Work --tag Important`,
+  projector add --name "Work Project" --tag Work --tag Important
+
+  # Add with a description
+  projector add ~/projects/xq7 --description "Billing service prototype"
+
+  # Answer questions instead of giving flags
+  projector add -i
+
+  # Add the current repository, tagged with its host and organization
+  projector add --scan
+
+  # Add a folder of a monorepo as a subproject of the monorepo's project
+  projector add ./services/billing --parent platform`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runAdd,
 }
@@ -50,15 +89,16 @@ func init() {
 	addCmd.Flags().StringVarP(&addName, "name", "n", "", "project name (defaults to folder name)")
 	addCmd.Flags().StringSliceVarP(&addTags, "tag", "t", []string{}, "tags for the project (can be used multiple times)")
 	addCmd.Flags().BoolVar(&addEnabled, "enabled", true, "whether the project is enabled")
+	addCmd.Flags().StringVar(&addDescription, "description", "", "short description of the project")
+	addCmd.Flags().StringVar(&addNotes, "notes", "", "free-form notes about the project")
+	addCmd.Flags().StringVar(&addIcon, "icon", "", "icon shown before the name when showIcons is on (e.g. an emoji)")
+	addCmd.Flags().StringVar(&addColor, "color", "", "color of the name in lists, e.g. 'blue' or '#268bd2' (overrides tagColors)")
+	addCmd.Flags().BoolVarP(&addInteractive, "interactive", "i", false, "ask for the path, name, tags and description")
+	addCmd.Flags().StringVar(&addParent, "parent", "", "name of the project containing this folder, e.g. a monorepo")
+	addCmd.Flags().BoolVar(&addScan, "scan", false, "record the remote URL and tag the project with its host and owner")
 }
 
 func runAdd(cmd *cobra.Command, args []string) error {
-	// Load config
-	cfg, err := config.LoadOrCreateConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
 	// Determine the path
 	var projectPath string
 	if len(args) > 0 {
@@ -71,8 +111,16 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if addInteractive {
+		answers, err := askAddAnswers(projectPath)
+		if err != nil {
+			return err
+		}
+		projectPath, addName, addTags, addDescription = answers.path, answers.name, answers.tags, answers.description
+	}
+
 	// Resolve to absolute path
-	projectPath, err = filepath.Abs(projectPath)
+	projectPath, err := filepath.Abs(projectPath)
 	if err != nil {
 		return fmt.Errorf("failed to resolve path: %w", err)
 	}
@@ -86,53 +134,257 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("path is not a directory: %s", projectPath)
 	}
 
+	if _, err := output.ParseColor(addColor); err != nil {
+		return err
+	}
+
 	// Determine project name
 	name := addName
 	if name == "" {
 		name = filepath.Base(projectPath)
 	}
 
-	// Load existing projects
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	// Initialize storage
+	store, err := app.Storage()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 
+	// Create new project
+	project := &models.Project{
+		Name:        name,
+		RootPath:    projectPath,
+		Tags:        addTags,
+		Enabled:     addEnabled,
+		Description: addDescription,
+		Notes:       addNotes,
+		Icon:        addIcon,
+		Color:       addColor,
+		Language:    scanner.DetectLanguage(projectPath),
+		FolderKind:  scanner.DetectKind(projectPath),
+		Kind:        models.KindFavorite,
+	}
+
+	if addParent != "" {
+		all, err := loadProjects(store, projector.TypeFilter{})
+		if err != nil {
+			return err
+		}
+		parent, err := projector.ResolveParent(all, project, addParent)
+		if err != nil {
+			return err
+		}
+		project.Parent = parent.Name
+	}
+
+	if addScan {
+		project.RemoteURL = scanner.RemoteURL(projectPath)
+		for _, tag := range scanner.RemoteTags(project.RemoteURL) {
+			project.AddTag(tag)
+		}
+	}
+
+	if err := projector.AddFavorite(store, project); err != nil {
+		return err
+	}
+
+	// Output
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Added project '%s' at %s", name, projectPath)))
+	if project.Parent != "" {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Subproject of '%s'", project.Parent)))
+	}
+	if addScan {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Kind: %s", project.FolderKind)))
+		if project.RemoteURL != "" {
+			printStatus(formatter.FormatInfo(fmt.Sprintf("Remote: %s", project.RemoteURL)))
+		}
+		if len(project.Tags) > 0 {
+			printStatus(formatter.FormatInfo(fmt.Sprintf("Tags: %s", strings.Join(project.Tags, ", "))))
+		}
+	}
+
+	return nil
+}
+
+// addAnswers are the answers to the questions of 'add --interactive'
+type addAnswers struct {
+	path        string
+	name        string
+	tags        []string
+	description string
+}
+
+// askAddAnswers asks the 'add --interactive' questions on the terminal,
+// offering path and the --name and --tag flags as defaults
+func askAddAnswers(path string) (addAnswers, error) {
+	if noInput {
+		return addAnswers{}, fmt.Errorf("%w; give the path and flags instead of --interactive", errNoInput)
+	}
+	cfg, err := app.Config()
+	if err != nil {
+		return addAnswers{}, err
+	}
+	store, err := app.Storage()
+	if err != nil {
+		return addAnswers{}, err
+	}
 	projects, err := store.LoadProjects()
 	if err != nil {
-		return fmt.Errorf("failed to load projects: %w", err)
+		return addAnswers{}, fmt.Errorf("failed to load projects: %w", err)
 	}
 
-	// Check if project already exists
-	for _, p := range projects.Projects {
-		if p.RootPath == projectPath {
-			return fmt.Errorf("project already exists: %s", p.Name)
+	p := &setupPrompter{in: stdinReader, out: app.Out()}
+	defaults := addAnswers{path: paths.Collapse(path), name: addName, tags: addTags, description: addDescription}
+	return runAddWizard(p, cfg.Tags, projects, defaults)
+}
+
+// runAddWizard asks for the path, name, tags and description of a new
+// favorite, asking again until each answer is valid. offered are the tags
+// to choose from by number; projects are the existing favorites, whose
+// names may not be reused.
+func runAddWizard(p *setupPrompter, offered []string, projects *models.ProjectList, answers addAnswers) (addAnswers, error) {
+	// Path, completed when it names no folder
+	for {
+		answer, err := p.ask("Path", answers.path)
+		if err != nil {
+			return answers, err
+		}
+		if paths.IsDir(paths.Expand(answer)) {
+			answers.path = paths.Expand(answer)
+			break
+		}
+		if p.eof {
+			return answers, fmt.Errorf("not a folder: %s", answer)
 		}
-		if p.Name == name {
-			return fmt.Errorf("project with name '%s' already exists", name)
+		switch matches := completeDir(answer); len(matches) {
+		case 0:
+			fmt.Fprintf(p.out, "  %s is not a folder\n", answer)
+		case 1:
+			answers.path = matches[0]
+		default:
+			fmt.Fprintf(p.out, "  %s matches:\n", answer)
+			for _, m := range matches {
+				fmt.Fprintf(p.out, "    %s\n", m)
+			}
+			answers.path = commonPrefix(matches)
 		}
 	}
 
-	// Create new project
-	project := &models.Project{
-		Name:     name,
-		RootPath: projectPath,
-		Tags:     addTags,
-		Enabled:  addEnabled,
-		Kind:     models.KindFavorite,
+	// Name, unique among the favorites
+	if answers.name == "" {
+		answers.name = filepath.Base(answers.path)
+	}
+	for {
+		answer, err := p.ask("Name", answers.name)
+		if err != nil {
+			return answers, err
+		}
+		problem := ""
+		switch {
+		case answer == "":
+			problem = "a name is needed"
+		case projects.FindByName(answer) != nil:
+			problem = fmt.Sprintf("a project named '%s' already exists", answer)
+		}
+		if problem == "" {
+			answers.name = answer
+			break
+		}
+		if p.eof {
+			return answers, errors.New(problem)
+		}
+		fmt.Fprintf(p.out, "  %s\n", problem)
 	}
 
-	// Add to list
-	projects.Add(project)
+	// Tags, by number from the offered ones or by name
+	if len(offered) > 0 {
+		fmt.Fprintln(p.out, "Tags:")
+		for i, tag := range offered {
+			fmt.Fprintf(p.out, "  %d) %s\n", i+1, tag)
+		}
+	}
+	for {
+		answer, err := p.askList("Tags (numbers or names, comma-separated)", answers.tags)
+		if err != nil {
+			return answers, err
+		}
+		tags, err := selectTags(answer, offered)
+		if err == nil {
+			answers.tags = tags
+			break
+		}
+		if p.eof {
+			return answers, err
+		}
+		fmt.Fprintf(p.out, "  %v\n", err)
+	}
 
-	// Save
-	if err := store.SaveProjects(projects); err != nil {
-		return fmt.Errorf("failed to save projects: %w", err)
+	description, err := p.ask("Description", answers.description)
+	if err != nil {
+		return answers, err
 	}
+	answers.description = description
+	return answers, nil
+}
 
-	// Output
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-	fmt.Println(formatter.FormatSuccess(fmt.Sprintf("Added project '%s' at %s", name, projectPath)))
+// selectTags turns the items of a tag answer into tags: a number picks
+// one of the offered tags, anything else is a tag name
+func selectTags(items, offered []string) ([]string, error) {
+	tags := []string{}
+	for _, item := range items {
+		tag := item
+		if n, err := strconv.Atoi(item); err == nil {
+			if n < 1 || n > len(offered) {
+				return nil, fmt.Errorf("no tag numbered %d", n)
+			}
+			tag = offered[n-1]
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
 
-	return nil
+// completeDir returns the folders whose path starts with partial, written
+// the way partial is (e.g. starting with ~). Hidden folders are only
+// included when partial names a hidden folder.
+func completeDir(partial string) []string {
+	expanded := paths.Expand(partial)
+	dir, base := filepath.Split(expanded)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	written := partial[:len(partial)-len(base)]
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		if paths.IsDir(filepath.Join(dir, name)) {
+			matches = append(matches, written+name)
+		}
+	}
+	return matches
+}
+
+// commonPrefix returns the longest prefix shared by all of items
+func commonPrefix(items []string) string {
+	prefix := items[0]
+	for _, item := range items[1:] {
+		for !strings.HasPrefix(item, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	// Do not end inside a character the items differ in
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
 }
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var analyzeTag string
+
+// analyzeCmd represents the analyze command
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [project-name...]",
+	Short: "Detect the language of favorites and tag them with it",
+	Long: `Detect the language of all favorites, or the ones named, and tag each
+with it, such as lang:go or lang:ts, so they can be listed with
+--tag lang:go. The language comes from a manifest such as go.mod or
+package.json, or else from the extensions of most source files.
+
+An earlier language tag is replaced when the language changed. Scanned
+projects are tagged on every scan when languageTags is set in the config.
+
+Examples:
+  # Tag every favorite with its language
+  projector analyze
+
+  # Only some projects
+  projector analyze api web
+
+  # Then list the Go projects
+  projector list --tag lang:go`,
+	ValidArgsFunction: completeProjectNames,
+	RunE:              runAnalyze,
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+
+	analyzeCmd.Flags().StringVarP(&analyzeTag, "tag", "t", "", "only analyze favorites with this tag")
+}
+
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	// Favorites stay locked while languages are detected, so other
+	// commands wait rather than have their changes overwritten
+	formatter := app.Formatter()
+	updated := 0
+	err = store.Update(func(projects *models.ProjectList) error {
+		targets := projects.Projects
+		if len(args) > 0 {
+			targets = make([]*models.Project, 0, len(args))
+			for _, name := range args {
+				project, _, err := projector.FindProjectByName(projects.Projects, name, matchOptions())
+				if err != nil {
+					return err
+				}
+				targets = append(targets, project)
+			}
+		}
+		targets = projector.FilterByTag(targets, analyzeTag)
+
+		tw := tabwriter.NewWriter(app.Out(), 0, 0, 2, ' ', 0)
+		for _, p := range targets {
+			if err := projector.PathError(p); err != nil {
+				formatter.FprintWarning(app.Err(), fmt.Sprintf("Skipped %s: %v", p.Name, err))
+				continue
+			}
+			before := p.Language
+			p.Language = scanner.DetectLanguage(p.RootPath)
+			projector.DetectProjectLanguage(p)
+			if projector.TagLanguage(p) || p.Language != before {
+				updated++
+			}
+
+			language := p.Language
+			if language == "" {
+				language = "-"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", formatter.FormatName(p.Name), language)
+		}
+		tw.Flush()
+		if updated == 0 {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		printStatus(formatter.FormatInfo("Languages are up to date"))
+		return nil
+	}
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Updated the language of %d projects", updated)))
+	return nil
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// appContext holds the config, storage, formatter and open history shared
+// by a command and the helpers it runs, and the writers its output goes
+// to. Each is loaded on first use and kept for the rest of the run, so
+// the config is read once however many helpers need it. It is safe for
+// concurrent use.
+type appContext struct {
+	mu sync.Mutex
+
+	// out and errOut receive the command's output and its messages on
+	// stderr; nil means os.Stdout and os.Stderr
+	out    io.Writer
+	errOut io.Writer
+
+	cfg       *config.Config
+	store     *storage.Storage
+	manager   *projector.Manager
+	formatter *output.Formatter
+
+	history       []models.OpenRecord
+	historyLoaded bool
+
+	// changeLabel describes the command's changes in the undo log
+	changeLabel string
+
+	// editorsReported is set once invalid custom editors were reported
+	editorsReported bool
+
+	// staleCacheHandled makes handleStaleCache act once per command
+	staleCacheHandled bool
+}
+
+// app is the context of the running command. The --profile flag is
+// checked in rootCmd's PersistentPreRunE before anything is loaded
+// through it.
+var app = &appContext{}
+
+// newAppContext creates a context that uses cfg and store instead of
+// loading them; either may be nil to load it on first use
+func newAppContext(cfg *config.Config, store *storage.Storage) *appContext {
+	return &appContext{cfg: cfg, store: store}
+}
+
+// setApp makes a the context of the running command and returns a
+// function restoring the previous one. Tests use it to inject their own
+// config and storage.
+func setApp(a *appContext) func() {
+	previous := app
+	app = a
+	return func() { app = previous }
+}
+
+// setOutput directs the output of the running command to out and its
+// messages on stderr to errOut. rootCmd uses the writers set on it with
+// SetOut and SetErr, so tests can capture what any command prints.
+func (a *appContext) setOutput(out, errOut io.Writer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.out, a.errOut = out, errOut
+}
+
+// Out returns the writer for the command's output
+func (a *appContext) Out() io.Writer {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.out == nil {
+		return os.Stdout
+	}
+	return a.out
+}
+
+// Err returns the writer for warnings and messages that must not mix
+// with the command's output, such as lists of ambiguous matches
+func (a *appContext) Err() io.Writer {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.errOut == nil {
+		return os.Stderr
+	}
+	return a.errOut
+}
+
+// setChangeLabel sets the description recorded in the undo log with the
+// changes the command makes to projects
+func (a *appContext) setChangeLabel(label string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.changeLabel = label
+	if a.store != nil {
+		a.store.SetUndoLabel(label)
+	}
+}
+
+// Config returns the config of the active profile
+func (a *appContext) Config() (*config.Config, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.config()
+}
+
+func (a *appContext) config() (*config.Config, error) {
+	if a.cfg == nil {
+		cfg, err := config.LoadOrCreateConfig(config.LoadOptions{Profile: profile})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		a.cfg = cfg
+	}
+	return a.cfg, nil
+}
+
+// Storage returns the storage at the configured projects location
+func (a *appContext) Storage() (*storage.Storage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.storage()
+}
+
+func (a *appContext) storage() (*storage.Storage, error) {
+	if a.store == nil {
+		cfg, err := a.config()
+		if err != nil {
+			return nil, err
+		}
+		store, err := projector.OpenStorage(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize storage: %w", err)
+		}
+		store.SetUndoLabel(a.changeLabel)
+		a.store = store
+	}
+	return a.store, nil
+}
+
+// Manager returns a projector.Manager on the config and storage
+func (a *appContext) Manager() (*projector.Manager, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.manager == nil {
+		cfg, err := a.config()
+		if err != nil {
+			return nil, err
+		}
+		store, err := a.storage()
+		if err != nil {
+			return nil, err
+		}
+		a.manager = projector.NewWithStorage(cfg, store)
+		a.manager.SetStaleCacheHandler(handleStaleCache)
+	}
+	return a.manager, nil
+}
+
+// Formatter returns a formatter using the configured theme, icons and tag
+// colors, colored unless --no-color is given or showColors is off
+func (a *appContext) Formatter() *output.Formatter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.formatter == nil {
+		colored := !noColor
+		var opts output.FormatterOptions
+		if cfg, err := a.config(); err == nil {
+			colored = colored && cfg.ShowColors
+			errOut := a.errOut
+			if errOut == nil {
+				errOut = os.Stderr
+			}
+			opts = formatterOptions(cfg, errOut)
+		}
+		a.formatter = output.NewFormatter(colored, opts)
+	}
+	return a.formatter
+}
+
+// markStaleCacheHandled records that a stale cache was handled, and
+// reports whether it already was
+func (a *appContext) markStaleCacheHandled() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	handled := a.staleCacheHandled
+	a.staleCacheHandled = true
+	return handled
+}
+
+// Editors returns the built-in editors and the custom editors from the
+// config. Invalid custom editors are left out and reported once.
+func (a *appContext) Editors() *editor.Registry {
+	m, err := a.Manager()
+	if err != nil {
+		return editor.NewRegistry()
+	}
+	editors, err := m.Editors()
+	a.mu.Lock()
+	report := err != nil && !a.editorsReported
+	a.editorsReported = true
+	a.mu.Unlock()
+	if report {
+		fmt.Fprintf(a.Err(), "Warning: skipping invalid custom editors: %v\n", err)
+	}
+	return editors
+}
+
+// History returns the open history, oldest first
+func (a *appContext) History() ([]models.OpenRecord, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.historyLoaded {
+		store, err := a.storage()
+		if err != nil {
+			return nil, err
+		}
+		history, err := store.LoadHistory()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load history: %w", err)
+		}
+		a.history, a.historyLoaded = history, true
+	}
+	return a.history, nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+var (
+	archiveMove     bool
+	archiveCompress bool
+	archiveList     bool
+)
+
+// archiveCmd represents the archive command
+var archiveCmd = &cobra.Command{
+	Use:   "archive [name]",
+	Short: "Archive a project you are done with",
+	Long: `Archive a project so it is hidden from list, open, select and the
+other pickers, also when a rescan finds its folder again. Archived
+projects are kept in archived.json and restored with 'projector unarchive'.
+
+The folder is left in place unless --move or --compress is given; they
+put it in the archive directory (archiveDirectory, by default "archive"
+in the projects location), compressed into a .tar.gz file with --compress.
+
+Examples:
+  # Hide a finished project
+  projector archive old-client
+
+  # Also move its folder out of the way
+  projector archive old-client --move
+
+  # Compress the folder into the archive directory
+  projector archive old-client --compress
+
+  # Show archived projects
+  projector archive --list`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if archiveList {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: runArchive,
+}
+
+// unarchiveCmd represents the unarchive command
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive <name>",
+	Short: "Restore an archived project",
+	Long: `Restore an archived project: a moved or compressed folder is put back
+at its original path, and the project returns to favorites or the list it
+was found in.
+
+Examples:
+  projector unarchive old-client`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArchivedNames,
+	RunE:              runUnarchive,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(unarchiveCmd)
+
+	archiveCmd.Flags().BoolVar(&archiveMove, "move", false, "move the folder into the archive directory")
+	archiveCmd.Flags().BoolVar(&archiveCompress, "compress", false, "compress the folder into the archive directory and remove it")
+	archiveCmd.Flags().BoolVarP(&archiveList, "list", "l", false, "list archived projects")
+	archiveCmd.MarkFlagsMutuallyExclusive("move", "compress", "list")
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	m, err := app.Manager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	formatter := app.Formatter()
+	if archiveList {
+		archived, err := m.Storage().LoadArchived()
+		if err != nil {
+			return fmt.Errorf("failed to load archived projects: %w", err)
+		}
+		writeArchivedList(app.Out(), formatter, archived)
+		return nil
+	}
+
+	mode := projector.ArchiveInPlace
+	if archiveMove {
+		mode = projector.ArchiveMove
+	} else if archiveCompress {
+		mode = projector.ArchiveCompress
+	}
+	entry, err := m.Archive(args[0], mode)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Archived project '%s'", entry.Project.Name)
+	if entry.Location != "" {
+		msg += fmt.Sprintf(" to %s", paths.Collapse(entry.Location))
+	}
+	printStatus(formatter.FormatSuccess(msg))
+	return nil
+}
+
+func runUnarchive(cmd *cobra.Command, args []string) error {
+	m, err := app.Manager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	project, err := m.Unarchive(args[0])
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Restored project '%s' at %s", project.Name, paths.Collapse(project.RootPath))))
+	return nil
+}
+
+// writeArchivedList prints the archived projects with when they were
+// archived and where their folder is
+func writeArchivedList(out io.Writer, formatter *output.Formatter, archived []*models.ArchivedProject) {
+	if len(archived) == 0 {
+		fmt.Fprintln(out, formatter.FormatInfo("No archived projects"))
+		return
+	}
+	for _, a := range archived {
+		fmt.Fprintf(out, "%s (%s, archived %s)\n", formatter.FormatName(a.Project.Name), a.Kind, a.ArchivedAt.Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(out, "  %s\n", formatter.FormatPath(paths.Collapse(a.Project.RootPath)))
+		if a.Location != "" {
+			fmt.Fprintf(out, "  -> %s\n", formatter.FormatPath(paths.Collapse(a.Location)))
+		}
+	}
+}
+
+// completeArchivedNames completes the first argument with the names of
+// archived projects
+func completeArchivedNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	archived, err := store.LoadArchived()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var names []string
+	for _, a := range archived {
+		if strings.HasPrefix(strings.ToLower(a.Project.Name), strings.ToLower(toComplete)) {
+			names = append(names, a.Project.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var (
+	backupFile string
+	backupList bool
+	restoreYes bool
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the config and saved projects",
+	Long: `Write the config file, projects.json, the scan cache, workspaces,
+sessions, archived projects and the open history to a .tar.gz file, by
+default projector-backup-<time>.tar.gz in the current directory.
+
+Besides these backups, the previous projects.json is copied to the
+"backups" folder of the projects location whenever it changes, and
+'projector restore' archives the files it replaces there. The newest
+backupCount of each (10 by default) are kept. --list shows them. Any of
+these backups is restored with 'projector restore'.
+
+Only projects.json is copied automatically. Earlier versions of the scan
+cache, which 'projector scan' rebuilds, of workspaces and of archived
+projects are kept only in the archives written by this command.
+
+Examples:
+  # Back up to the current directory
+  projector backup
+
+  # Back up to a chosen file
+  projector backup --file ~/Dropbox/projector.tar.gz
+
+  # Show the automatic backups
+  projector backup --list`,
+	Args: cobra.NoArgs,
+	RunE: runBackup,
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore the config and saved projects from a backup",
+	Long: `Restore a backup written by 'projector backup', or an automatic backup
+of projects.json from 'projector backup --list'.
+
+The files in the backup replace the current ones after you confirm. The
+current files are backed up first to the "backups" folder of the projects
+location, and 'projector undo' brings back the replaced projects.json.
+
+Examples:
+  projector restore projector-backup-20240501-120000.tar.gz
+
+  # Restore without asking
+  projector restore ~/.projector/backups/projects-20240501-120000.000000000.json --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	backupCmd.Flags().StringVarP(&backupFile, "file", "f", "", "file to write (default: projector-backup-<time>.tar.gz)")
+	backupCmd.Flags().BoolVarP(&backupList, "list", "l", false, "list the automatic backups")
+	backupCmd.MarkFlagsMutuallyExclusive("file", "list")
+	restoreCmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "restore without asking for confirmation")
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	if backupList {
+		backups, err := store.ListBackups()
+		if err != nil {
+			return err
+		}
+		writeBackupList(app.Out(), formatter, backups)
+		return nil
+	}
+
+	path := backupFile
+	if path == "" {
+		path = fmt.Sprintf("projector-backup-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	names, err := writeBackupFile(store, cfg, paths.Expand(path))
+	if err != nil {
+		return err
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Backed up %d files to %s", len(names), path)))
+	for _, name := range names {
+		fmt.Fprintf(app.Out(), "  %s\n", name)
+	}
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(paths.Expand(args[0]))
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	files, err := storage.ReadBackup(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(app.Out(), "Restoring %s replaces:\n", args[0])
+	for _, name := range names {
+		dir := store.GetBasePath()
+		if storage.IsConfigFile(name) {
+			dir = cfg.GetConfigDir()
+		}
+		fmt.Fprintf(app.Out(), "  %s\n", formatter.FormatPath(paths.Collapse(filepath.Join(dir, name))))
+	}
+	if !restoreYes {
+		if noInput {
+			return fmt.Errorf("%w; use --yes to restore without asking", errNoInput)
+		}
+		fmt.Fprint(app.Out(), "Continue? [y/N]: ")
+		input, err := ReadUserInput()
+		if err != nil || !strings.EqualFold(input, "y") && !strings.EqualFold(input, "yes") {
+			printStatus(formatter.FormatInfo("Restore cancelled"))
+			return nil
+		}
+	}
+
+	safety := store.RestoreBackupPath(time.Now())
+	if _, err := writeBackupFile(store, cfg, safety); err != nil {
+		return fmt.Errorf("failed to back up the current files: %w", err)
+	}
+	if err := store.PruneBackups(); err != nil {
+		return err
+	}
+	if err := store.Restore(files, cfg.GetConfigDir()); err != nil {
+		return err
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Restored %d files", len(names))))
+	printStatus(formatter.FormatInfo(fmt.Sprintf("The replaced files were backed up to %s", paths.Collapse(safety))))
+	return nil
+}
+
+// writeBackupFile writes a backup of the config and storage to a new file
+// at path, returning the names of the files backed up
+func writeBackupFile(store *storage.Storage, cfg *config.Config, path string) ([]string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	configPath := cfg.GetConfigPath()
+	if _, err := os.Stat(configPath); err != nil {
+		configPath = ""
+	}
+	names, err := store.WriteBackup(f, configPath)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return names, nil
+}
+
+// writeBackupList prints the automatic backups, most recent first
+func writeBackupList(out io.Writer, formatter *output.Formatter, backups []string) {
+	if len(backups) == 0 {
+		fmt.Fprintln(out, formatter.FormatInfo("No automatic backups"))
+		return
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		fmt.Fprintf(out, "%s\n", formatter.FormatPath(paths.Collapse(backups[i])))
+	}
+}
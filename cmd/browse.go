@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/forge"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+var (
+	browseBranch string
+	browsePath   string
+	browsePrint  bool
+)
+
+// browseCmd represents the browse command
+var browseCmd = &cobra.Command{
+	Use:   "browse [project-name]",
+	Short: "Open the web page of a project's repository",
+	Long: `Open the web page of the repository a Git or Mercurial project was cloned
+from in the web browser ($BROWSER, or the default one). Without a name,
+the project containing the current directory is used.
+
+The page is derived from the origin remote, in https, ssh:// or
+git@host:owner/repo form, using the URL layout of GitHub, GitLab,
+Bitbucket or Gitea. Public forges are known; set browseHosts in the config
+for self-hosted ones:
+
+  "browseHosts": {
+    "git.example.com": "gitlab",
+    "ssh.example.com": "https://code.example.com/{repo}/-/tree/{branch}/{path}"
+  }
+
+Examples:
+  # Open the repository of a project
+  projector browse api
+
+  # Open a branch
+  projector browse api --branch release
+
+  # Open a file on the default branch
+  projector browse api --path cmd/main.go
+
+  # Print the URL instead
+  projector browse api --print`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	RunE:              runBrowse,
+}
+
+func init() {
+	rootCmd.AddCommand(browseCmd)
+
+	browseCmd.Flags().StringVarP(&browseBranch, "branch", "b", "", "open this branch")
+	browseCmd.Flags().StringVarP(&browsePath, "path", "p", "", "open this file or folder, relative to the project root")
+	browseCmd.Flags().BoolVar(&browsePrint, "print", false, "print the URL instead of opening it")
+}
+
+func runBrowse(cmd *cobra.Command, args []string) error {
+	name := projector.CurrentProjectArg
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	projects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+	project, _, err := findProject(cfg, store, projector.FilterEnabled(projects), name)
+	if err != nil {
+		return err
+	}
+
+	url, err := projectWebURL(cfg, project, browseBranch, browsePath)
+	if err != nil {
+		return err
+	}
+	if browsePrint {
+		fmt.Fprintln(app.Out(), url)
+		return nil
+	}
+	if err := editor.Browse(url); err != nil {
+		return fmt.Errorf("failed to open the browser: %w", err)
+	}
+	printStatus(app.Formatter().FormatSuccess(fmt.Sprintf("Opened %s", url)))
+	return nil
+}
+
+// projectWebURL returns the web page of the repository of project, read
+// from its working copy or else its recorded remoteUrl. A path without a
+// branch is linked on the recorded default branch, or HEAD.
+func projectWebURL(cfg *config.Config, project *models.Project, branch, path string) (string, error) {
+	remote := ""
+	if !project.Offline {
+		remote = scanner.RemoteURL(project.RootPath)
+	}
+	if remote == "" {
+		remote = project.RemoteURL
+	}
+	if remote == "" {
+		return "", fmt.Errorf("project '%s' has no remote repository", project.Name)
+	}
+
+	host, repo := scanner.ParseRemoteURL(remote)
+	if host == "" || repo == "" {
+		return "", fmt.Errorf("the remote of project '%s' is not on a web host: %s", project.Name, remote)
+	}
+	if branch == "" && path != "" {
+		branch = project.DefaultBranch
+	}
+	tmpl := forge.WebTemplate(host, cfg.BrowseHosts)
+	return forge.WebURL(tmpl, host, repo, branch, filepath.ToSlash(path)), nil
+}
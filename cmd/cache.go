@@ -2,11 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/ideaspaper/projector/pkg/config"
-	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
 	"github.com/ideaspaper/projector/pkg/storage"
 )
 
@@ -22,21 +30,111 @@ After clearing the cache, run 'projector scan' to re-detect projects.`,
 	RunE:    runClearCache,
 }
 
+var cachePruneDryRun bool
+
+// cacheCmd groups commands that manage the cache of auto-detected projects
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of auto-detected projects",
+	Args:  cobra.NoArgs,
+}
+
+// cachePruneCmd represents the cache prune command
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached projects whose folders no longer exist",
+	Long: `Remove cached auto-detected projects whose folders no longer exist,
+instead of only showing them as disabled when listing.
+
+Set "pruneMissingOnLoad": true in config.json to prune automatically
+whenever the cache is loaded.
+
+Examples:
+  # Remove dead entries
+  projector cache prune
+
+  # Show what would be removed
+  projector cache prune --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runCachePrune,
+}
+
+// cacheStatusCmd represents the cache status command
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show when each type of project was last scanned",
+	Long: `Show how many projects of each type are cached and when they were last
+scanned. Types scanned longer ago than cacheTTL are marked stale.
+
+Commands that list projects print a hint when they load a stale cache, or
+with "autoRescan": true start a rescan in the background.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheStatus,
+}
+
+// cacheClearCmd is 'clear-cache' under the cache group
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: clearCacheCmd.Short,
+	Long:  clearCacheCmd.Long,
+	Args:  cobra.NoArgs,
+	RunE:  runClearCache,
+}
+
 func init() {
 	rootCmd.AddCommand(clearCacheCmd)
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheStatusCmd)
+
+	cachePruneCmd.Flags().BoolVar(&cachePruneDryRun, "dry-run", false, "list stale entries without removing them")
 }
 
-func runClearCache(cmd *cobra.Command, args []string) error {
-	// Load config
-	cfg, err := config.LoadOrCreateConfig()
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	// Initialize storage
+	store, err := app.Storage()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
+	var removed []*models.Project
+	if cachePruneDryRun {
+		cache, err := store.LoadCache()
+		if err != nil {
+			return fmt.Errorf("failed to load cache: %w", err)
+		}
+		removed = cache.PruneMissing()
+	} else {
+		removed, err = store.PruneCache()
+		if err != nil {
+			return fmt.Errorf("failed to prune cache: %w", err)
+		}
+	}
+
+	formatter := app.Formatter()
+	if len(removed) == 0 {
+		printStatus(formatter.FormatSuccess("No stale cache entries"))
+		return nil
+	}
+
+	for _, p := range removed {
+		fmt.Fprintf(app.Out(), "  - %s (%s)\n", p.Name, p.RootPath)
+	}
+	if cachePruneDryRun {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Would remove %d stale cache entries", len(removed))))
+	} else {
+		printStatus(formatter.FormatSuccess(fmt.Sprintf("Removed %d stale cache entries", len(removed))))
+	}
+
+	return nil
+}
+
+func runClearCache(cmd *cobra.Command, args []string) error {
 	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	store, err := app.Storage()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 
 	// Clear the cache
@@ -45,8 +143,119 @@ func runClearCache(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-	fmt.Println(formatter.FormatSuccess("Cache cleared successfully"))
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess("Cache cleared successfully"))
 
 	return nil
 }
+
+func runCacheStatus(cmd *cobra.Command, args []string) error {
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	cache, err := store.LoadCache()
+	if err != nil {
+		return fmt.Errorf("failed to load cache: %w", err)
+	}
+
+	stale := make(map[scanner.ScannerType]bool)
+	for _, kind := range cache.StaleKinds(store.CacheTTL(), time.Now()) {
+		stale[kind] = true
+	}
+
+	formatter := app.Formatter()
+	tw := tabwriter.NewWriter(app.Out(), 0, 0, 2, ' ', 0)
+	for _, kind := range storage.CacheKinds {
+		scanned := "never"
+		if at, ok := cache.ScannedAt[kind]; ok {
+			scanned = at.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s", kind, len(projector.CacheBucket(cache, kind)), scanned)
+		if stale[kind] {
+			fmt.Fprint(tw, "\t"+formatter.FormatWarning("stale"))
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	return nil
+}
+
+// rescanMarkerName is touched in the storage directory when a background
+// rescan starts, so that commands run meanwhile do not start another
+const rescanMarkerName = "rescan.started"
+
+// rescanInterval is the minimum time between background rescans
+const rescanInterval = 10 * time.Minute
+
+// loadProjects loads projects like projector.LoadFilteredProjects,
+// suggesting or starting a rescan when the cache is stale
+func loadProjects(store *storage.Storage, filter projector.TypeFilter) ([]*models.Project, error) {
+	return projector.LoadFilteredProjects(store, filter, projector.LoadOptions{OnStaleCache: handleStaleCache})
+}
+
+// handleStaleCache suggests a rescan of the stale scanner types or, with
+// autoRescan, starts one in the background. Hints are only shown when
+// stderr is a terminal, so scripts reading projector's output are not
+// disturbed.
+func handleStaleCache(store *storage.Storage, stale []scanner.ScannerType) {
+	if app.markStaleCacheHandled() {
+		return
+	}
+
+	cfg, err := app.Config()
+	if err != nil {
+		return
+	}
+
+	if cfg.AutoRescan {
+		if err := startBackgroundRescan(store); err != nil {
+			slog.Warn("failed to start background rescan", "error", err)
+		}
+		return
+	}
+
+	if info, err := os.Stderr.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 || quiet {
+		return
+	}
+	kinds := make([]string, len(stale))
+	for i, kind := range stale {
+		kinds[i] = string(kind)
+	}
+	formatter := app.Formatter()
+	formatter.FprintInfo(app.Err(), fmt.Sprintf(
+		"Cached %s projects were scanned more than %s ago; run 'projector scan' to refresh them",
+		strings.Join(kinds, ", "), cfg.CacheTTL))
+}
+
+// startBackgroundRescan runs 'projector scan' as a detached process, unless
+// one was started recently. Every type is rescanned, since a scan replaces
+// the whole cache.
+func startBackgroundRescan(store *storage.Storage) error {
+	marker := filepath.Join(store.GetBasePath(), rescanMarkerName)
+	if info, err := os.Stat(marker); err == nil && time.Since(info.ModTime()) < rescanInterval {
+		return nil
+	}
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{"scan"}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+
+	c := exec.Command(exe, args...)
+	detach(c)
+	if err := c.Start(); err != nil {
+		return err
+	}
+	slog.Info("started background rescan", "pid", c.Process.Pid)
+	return c.Process.Release()
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+// Environment variables used by cd and the shell integration
+const (
+	envProject          = "PROJECTOR_PROJECT"
+	envShellIntegration = "PROJECTOR_SHELL_INTEGRATION"
+)
+
+var (
+	cdTag      string
+	cdLanguage string
+)
+
+// cdCmd represents the cd command
+var cdCmd = &cobra.Command{
+	Use:   "cd [project-name]",
+	Short: "Start a shell in a project directory",
+	Long: `Start a new shell with its working directory at the selected project,
+with PROJECTOR_PROJECT set to the project name. Exit the shell to return.
+
+If no project name is provided, an interactive selection is shown.
+
+A program cannot change the directory of the shell that runs it, so this
+starts a subshell. With the functions from 'projector init' installed,
+'pj cd' changes the current shell's directory instead.
+
+Examples:
+  # Start a shell in a project
+  projector cd api
+
+  # Pick a project tagged Work
+  projector cd --tag Work`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCd,
+}
+
+func init() {
+	rootCmd.AddCommand(cdCmd)
+
+	cdCmd.Flags().StringVarP(&cdTag, "tag", "t", "", "filter projects by tag")
+	cdCmd.Flags().StringVar(&cdLanguage, "language", "", "filter projects by detected language (e.g. go, rust)")
+}
+
+func runCd(cmd *cobra.Command, args []string) error {
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	m, err := app.Manager()
+	if err != nil {
+		return err
+	}
+	projects, err := m.List(projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+	projects = projector.FilterByTag(projects, cdTag)
+	projects = projector.FilterByLanguage(projects, cdLanguage)
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects found")
+	}
+
+	var project *models.Project
+	switch {
+	case len(args) == 0:
+		selected, err := selectProjectsForSelect(cmd, projects, cfg, false)
+		if err != nil {
+			return err
+		}
+		project = selected[0]
+	case args[0] == projector.CurrentProjectArg:
+		if project, err = projector.FindCurrentProject(projects); err != nil {
+			return err
+		}
+	default:
+		var matches []*models.Project
+		project, matches, err = projector.FindProjectByName(projects, args[0], matchOptions())
+		if err != nil {
+			for _, p := range matches {
+				fmt.Fprintf(app.Err(), "  - %s (%s)\n", p.Name, p.RootPath)
+			}
+			return err
+		}
+	}
+
+	if err := projector.PathError(project); err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	if name := os.Getenv(envShellIntegration); name != "" && !quiet {
+		formatter.FprintInfo(app.Err(), fmt.Sprintf("Tip: '%s cd' changes this shell's directory without starting a new one", name))
+	}
+	if current := os.Getenv(envProject); current != "" {
+		formatter.FprintWarning(app.Err(), fmt.Sprintf("Already in a shell for '%s'; starting a nested one", current))
+	}
+	if !quiet {
+		formatter.FprintInfo(app.Err(), fmt.Sprintf("Starting a shell in '%s' (exit to return)", project.Name))
+	}
+
+	shell := subshellCommand(project, userShell())
+	shell.Stdin, shell.Stdout, shell.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := shell.Run(); err != nil {
+		// The exit status is that of the last command run in the shell
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to start shell: %w", err)
+	}
+	return nil
+}
+
+// userShell returns the user's login shell, falling back to the platform default
+func userShell() string {
+	if runtime.GOOS == "windows" {
+		if comspec := os.Getenv("COMSPEC"); comspec != "" {
+			return comspec
+		}
+		return "cmd.exe"
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// subshellCommand builds the command starting shell at the project root with
+// PROJECTOR_PROJECT set to the project name. PWD is replaced as well, since
+// shells trust an inherited PWD over their actual directory.
+func subshellCommand(project *models.Project, shell string) *exec.Cmd {
+	c := exec.Command(shell)
+	c.Dir = project.RootPath
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, envProject+"=") && !strings.HasPrefix(kv, "PWD=") {
+			env = append(env, kv)
+		}
+	}
+	c.Env = append(env, envProject+"="+project.Name, "PWD="+project.RootPath)
+	return c
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/trash"
+)
+
+var (
+	cleanupDirs   []string
+	cleanupTag    string
+	cleanupDelete bool
+	cleanupYes    bool
+	cleanupTrash  bool
+	cleanupJSON   bool
+)
+
+// cleanupCmd represents the cleanup command
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup [project-name...]",
+	Short: "Report and delete dependency and build folders",
+	Long: `Report the disk usage of dependency and build output folders, such as
+node_modules, target, .venv and build, in all projects or the ones named,
+largest first. With --delete, choose which of them to delete to reclaim
+the space; they are recreated by installing or building again.
+
+The folder names looked for are set by artifactDirs in the config, or by
+--dir. Folders inside such a folder are not listed separately, and in Git
+projects folders holding tracked files are left out.
+
+Examples:
+  # Report artifact folders across all projects
+  projector cleanup
+
+  # Choose which to delete
+  projector cleanup --delete
+
+  # Delete every node_modules folder of the Work projects without asking
+  projector cleanup --dir node_modules --tag Work --delete --yes
+
+  # Move them to the trash instead
+  projector cleanup api web --delete --trash`,
+	ValidArgsFunction: completeProjectNames,
+	RunE:              runCleanup,
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+
+	cleanupCmd.Flags().StringArrayVar(&cleanupDirs, "dir", nil, "look for folders with this name instead of artifactDirs (can be used multiple times)")
+	cleanupCmd.Flags().StringVarP(&cleanupTag, "tag", "t", "", "only look in projects with this tag")
+	cleanupCmd.Flags().BoolVar(&cleanupDelete, "delete", false, "choose folders to delete")
+	cleanupCmd.Flags().BoolVarP(&cleanupYes, "yes", "y", false, "delete every folder found without asking (with --delete)")
+	cleanupCmd.Flags().BoolVar(&cleanupTrash, "trash", false, "move deleted folders to the trash instead of removing them")
+	cleanupCmd.Flags().BoolVar(&cleanupJSON, "json", false, "print the folders found as JSON")
+	cleanupCmd.MarkFlagsMutuallyExclusive("delete", "json")
+}
+
+func runCleanup(cmd *cobra.Command, args []string) error {
+	if (cleanupYes || cleanupTrash) && !cleanupDelete {
+		return fmt.Errorf("--yes and --trash need --delete")
+	}
+
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	projects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		named := make([]*models.Project, 0, len(args))
+		for _, name := range args {
+			project, _, err := findProject(cfg, store, projects, name)
+			if err != nil {
+				return err
+			}
+			named = append(named, project)
+		}
+		projects = named
+	}
+	projects = projector.FilterByTag(projects, cleanupTag)
+
+	names := cfg.ArtifactDirs
+	if len(cleanupDirs) > 0 {
+		names = cleanupDirs
+	}
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid artifact folder name: '%s'", name)
+		}
+	}
+
+	artifacts := projector.FindArtifacts(projects, names)
+
+	if cleanupJSON {
+		data, err := json.MarshalIndent(artifacts, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize artifacts: %w", err)
+		}
+		fmt.Fprintln(app.Out(), string(data))
+		return nil
+	}
+
+	formatter := app.Formatter()
+	if len(artifacts) == 0 {
+		printStatus(formatter.FormatSuccess("No dependency or build folders found"))
+		return nil
+	}
+	writeArtifacts(app.Out(), formatter, artifacts)
+	if !cleanupDelete {
+		return nil
+	}
+
+	selected := artifacts
+	if !cleanupYes {
+		if selected, err = promptArtifacts(artifacts); err != nil {
+			return err
+		}
+	}
+
+	var freed int64
+	deleted, failed := 0, 0
+	for _, a := range selected {
+		if err := deleteArtifact(a, names, cleanupTrash); err != nil {
+			formatter.FprintWarning(app.Err(), fmt.Sprintf("Could not delete %s: %v", a.Path, err))
+			failed++
+			continue
+		}
+		freed += a.Bytes
+		deleted++
+	}
+
+	verb := "Deleted"
+	if cleanupTrash {
+		verb = "Moved to the trash"
+	}
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("%s %d folders, %s", verb, deleted, formatBytes(freed))))
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d folders", failed)
+	}
+	return nil
+}
+
+// writeArtifacts lists artifacts with 1-based numbers and their total size
+func writeArtifacts(w io.Writer, formatter *output.Formatter, artifacts []projector.Artifact) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var total int64
+	for i, a := range artifacts {
+		total += a.Bytes
+		fmt.Fprintf(tw, "  [%d]\t%s\t%s\t%10s\n", i+1, formatter.FormatName(a.Project), formatter.FormatPath(paths.Collapse(a.Path)), formatBytes(a.Bytes))
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nTotal: %s in %d folders\n", formatBytes(total), len(artifacts))
+}
+
+// promptArtifacts asks which artifacts to delete and to confirm the choice
+func promptArtifacts(artifacts []projector.Artifact) ([]projector.Artifact, error) {
+	if noInput {
+		return nil, fmt.Errorf("%w; use --yes to delete every folder found", errNoInput)
+	}
+
+	fmt.Fprint(app.Out(), "\nDelete which folders? e.g. 1 3 5-7, 'a' for all (or 'q' to quit): ")
+	input, err := ReadUserInput()
+	if err != nil {
+		return nil, err
+	}
+	var selected []projector.Artifact
+	switch strings.ToLower(input) {
+	case "", "q":
+		return nil, errCancelled
+	case "a", "all":
+		selected = artifacts
+	default:
+		indexes, err := parseSelection(input, len(artifacts))
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range indexes {
+			selected = append(selected, artifacts[i])
+		}
+	}
+
+	var size int64
+	for _, a := range selected {
+		size += a.Bytes
+	}
+	fmt.Fprintf(app.Out(), "Delete %d folders (%s)? [y/N]: ", len(selected), formatBytes(size))
+	input, err = ReadUserInput()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(input, "y") && !strings.EqualFold(input, "yes") {
+		return nil, errCancelled
+	}
+	return selected, nil
+}
+
+// deleteArtifact removes the folder of an artifact, or moves it to the
+// trash. The folder must still be a real folder named in names, so a
+// path replaced since it was found is left alone.
+func deleteArtifact(a projector.Artifact, names []string, toTrash bool) error {
+	if !slices.Contains(names, filepath.Base(a.Path)) {
+		return fmt.Errorf("not an artifact folder")
+	}
+	info, err := os.Lstat(a.Path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("no longer a folder")
+	}
+	if toTrash {
+		return trash.Move(a.Path)
+	}
+	return os.RemoveAll(a.Path)
+}
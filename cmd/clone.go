@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+var (
+	cloneName string
+	cloneTags []string
+	cloneOpen bool
+)
+
+// cloneCmd represents the clone command
+var cloneCmd = &cobra.Command{
+	Use:   "clone <git-url> [dest]",
+	Short: "Clone a git repository and add it to favorites",
+	Long: `Clone a git repository and add it to your favorites in one step.
+
+If no destination is given, the repository is cloned into the configured
+cloneDirectory (default: ~/projects) under a folder named after the repository.
+
+Examples:
+  # Clone into ~/projects/projector and add it as a favorite
+  projector clone https://github.com/ideaspaper/projector.git
+
+  # Clone into a specific directory with tags
+  projector clone git@github.com:acme/api.git ~/work/api --tag Work
+
+  # Clone, add and open in the editor
+  projector clone https://github.com/acme/web --open`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runClone,
+}
+
+func init() {
+	rootCmd.AddCommand(cloneCmd)
+
+	cloneCmd.Flags().StringVarP(&cloneName, "name", "n", "", "project name (defaults to repository name)")
+	cloneCmd.Flags().StringSliceVarP(&cloneTags, "tag", "t", []string{}, "tags for the project (can be used multiple times)")
+	cloneCmd.Flags().BoolVarP(&cloneOpen, "open", "o", false, "open the project in the editor after cloning")
+}
+
+func runClone(cmd *cobra.Command, args []string) error {
+	url := args[0]
+
+	// With a destination given, its folder name stands in for a name the
+	// URL does not have
+	repoName, err := repoNameFromURL(url)
+	if err != nil && len(args) < 2 {
+		return err
+	}
+
+	// Load config
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	// Determine destination
+	var dest string
+	if len(args) > 1 {
+		dest = paths.Expand(args[1])
+	} else {
+		if cfg.CloneDirectory == "" {
+			return fmt.Errorf("no destination given and cloneDirectory is not configured")
+		}
+		dest = filepath.Join(paths.Expand(cfg.CloneDirectory), repoName)
+	}
+	dest, err = filepath.Abs(dest)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if repoName == "" {
+		repoName = filepath.Base(dest)
+	}
+
+	if paths.Exists(dest) {
+		return fmt.Errorf("destination already exists: %s", dest)
+	}
+
+	// Initialize storage before cloning so a broken setup fails early
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create clone directory: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatInfo(fmt.Sprintf("Cloning %s into %s...", url, dest)))
+
+	if err := gitClone(url, dest); err != nil {
+		return err
+	}
+
+	name := cloneName
+	if name == "" {
+		name = repoName
+	}
+
+	project := &models.Project{
+		Name:     name,
+		RootPath: dest,
+		Tags:     cloneTags,
+		Enabled:  true,
+		Language: scanner.DetectLanguage(dest),
+		Kind:     models.KindFavorite,
+	}
+	if err := projector.AddFavorite(store, project); err != nil {
+		return fmt.Errorf("cloned to %s but could not add project: %w", dest, err)
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Added project '%s' at %s", name, dest)))
+
+	if cloneOpen {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening '%s' in %s...", name, cfg.Editor)))
+		return openInEditor(dest, cfg.Editor, cfg.OpenInNewWindow)
+	}
+
+	return nil
+}
+
+// gitClone clones url into dest, showing git's progress. The "--" keeps
+// a url starting with "-" from being read as an option.
+func gitClone(url, dest string) error {
+	gitCmd := exec.Command("git", "clone", "--", url, dest)
+	gitCmd.Stdin = os.Stdin
+	gitCmd.Stdout = app.Out()
+	gitCmd.Stderr = app.Err()
+	if err := gitCmd.Run(); err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+	return nil
+}
+
+// repoNameFromURL derives a repository name from a git URL, handling
+// https, ssh (git@host:org/repo.git) and local path forms. URLs ending in
+// no name, "." or ".." are rejected, since the name becomes a folder.
+func repoNameFromURL(url string) (string, error) {
+	name := strings.TrimRight(url, "/")
+	name = strings.TrimSuffix(name, ".git")
+
+	if i := strings.LastIndexAny(name, "/:\\"); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("cannot derive a repository name from '%s'; give a destination", url)
+	}
+	return name, nil
+}
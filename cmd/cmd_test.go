@@ -1,12 +1,39 @@
 package cmd
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ideaspaper/projector/pkg/cloudsync"
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/forge"
 	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
 	"github.com/ideaspaper/projector/pkg/storage"
 )
 
@@ -388,43 +415,6 @@ func TestClearCacheNonExistent(t *testing.T) {
 
 // Tests for helper functions
 
-func TestTypeFilter_ShowAll(t *testing.T) {
-	tests := []struct {
-		name   string
-		filter TypeFilter
-		want   bool
-	}{
-		{
-			name:   "empty filter shows all",
-			filter: TypeFilter{},
-			want:   true,
-		},
-		{
-			name:   "favorites only",
-			filter: TypeFilter{Favorites: true},
-			want:   false,
-		},
-		{
-			name:   "git only",
-			filter: TypeFilter{Git: true},
-			want:   false,
-		},
-		{
-			name:   "multiple filters",
-			filter: TypeFilter{Favorites: true, Git: true},
-			want:   false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := tt.filter.ShowAll(); got != tt.want {
-				t.Errorf("ShowAll() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestLoadFilteredProjects(t *testing.T) {
 	tmpDir, cleanup := testSetup(t)
 	defer cleanup()
@@ -436,54 +426,54 @@ func TestLoadFilteredProjects(t *testing.T) {
 
 	tests := []struct {
 		name      string
-		filter    TypeFilter
+		filter    projector.TypeFilter
 		wantCount int
 	}{
 		{
 			name:      "load all",
-			filter:    TypeFilter{},
+			filter:    projector.TypeFilter{},
 			wantCount: 8,
 		},
 		{
 			name:      "favorites only",
-			filter:    TypeFilter{Favorites: true},
+			filter:    projector.TypeFilter{Favorites: true},
 			wantCount: 2,
 		},
 		{
 			name:      "git only",
-			filter:    TypeFilter{Git: true},
+			filter:    projector.TypeFilter{Git: true},
 			wantCount: 2,
 		},
 		{
 			name:      "svn only",
-			filter:    TypeFilter{SVN: true},
+			filter:    projector.TypeFilter{SVN: true},
 			wantCount: 1,
 		},
 		{
 			name:      "mercurial only",
-			filter:    TypeFilter{Mercurial: true},
+			filter:    projector.TypeFilter{Mercurial: true},
 			wantCount: 1,
 		},
 		{
 			name:      "vscode only",
-			filter:    TypeFilter{VSCode: true},
+			filter:    projector.TypeFilter{VSCode: true},
 			wantCount: 1,
 		},
 		{
 			name:      "any only",
-			filter:    TypeFilter{Any: true},
+			filter:    projector.TypeFilter{Any: true},
 			wantCount: 1,
 		},
 		{
 			name:      "favorites and git",
-			filter:    TypeFilter{Favorites: true, Git: true},
+			filter:    projector.TypeFilter{Favorites: true, Git: true},
 			wantCount: 4,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			projects, err := LoadFilteredProjects(store, tt.filter)
+			projects, err := projector.LoadFilteredProjects(store, tt.filter, projector.LoadOptions{})
 			if err != nil {
 				t.Fatalf("LoadFilteredProjects failed: %v", err)
 			}
@@ -494,160 +484,2077 @@ func TestLoadFilteredProjects(t *testing.T) {
 	}
 }
 
-func TestFilterEnabled(t *testing.T) {
-	projects := []*models.Project{
-		{Name: "enabled1", Enabled: true},
-		{Name: "disabled1", Enabled: false},
-		{Name: "enabled2", Enabled: true},
-		{Name: "disabled2", Enabled: false},
-	}
+func TestMergeImportedProjects(t *testing.T) {
+	projects := models.NewProjectList(models.KindFavorite)
+	projects.Add(models.NewProject("api", "/srv/api"))
 
-	filtered := FilterEnabled(projects)
+	imported := []*models.Project{
+		models.NewProject("api-copy", "/srv/api"), // same path
+		models.NewProject("API", "/srv/other"),    // same name
+		models.NewProject("blog", "/srv/blog"),
+	}
 
-	if len(filtered) != 2 {
-		t.Errorf("expected 2 enabled projects, got %d", len(filtered))
+	added, skipped := mergeImportedProjects(projects, imported)
+	if added != 1 || skipped != 2 {
+		t.Errorf("got added=%d skipped=%d, want added=1 skipped=2", added, skipped)
+	}
+	if projects.FindByName("blog") == nil {
+		t.Error("expected blog to be imported")
 	}
+}
+
+func TestExpandCommandTemplate(t *testing.T) {
+	project := &models.Project{Name: "My App", RootPath: "/home/me/my app"}
 
-	for _, p := range filtered {
-		if !p.Enabled {
-			t.Errorf("expected all filtered projects to be enabled, got disabled: %s", p.Name)
+	args := expandCommandTemplate("tmux new-window -c {{path}} -n {{name}}", project)
+	want := []string{"tmux", "new-window", "-c", "/home/me/my app", "-n", "My App"}
+
+	if len(args) != len(want) {
+		t.Fatalf("got %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("arg %d: got %q, want %q", i, args[i], want[i])
 		}
 	}
-}
 
-func TestFilterByTag(t *testing.T) {
-	projects := []*models.Project{
-		{Name: "work1", Tags: []string{"Work"}},
-		{Name: "personal1", Tags: []string{"Personal"}},
-		{Name: "work2", Tags: []string{"Work", "Go"}},
-		{Name: "notags", Tags: []string{}},
+	if args := expandCommandTemplate("   ", project); len(args) != 0 {
+		t.Errorf("expected no args for blank template, got %v", args)
 	}
+}
 
+func TestRepoNameFromURL(t *testing.T) {
 	tests := []struct {
-		name      string
-		tag       string
-		wantCount int
-		wantNames []string
+		url  string
+		want string
 	}{
-		{
-			name:      "filter by Work",
-			tag:       "Work",
-			wantCount: 2,
-			wantNames: []string{"work1", "work2"},
-		},
-		{
-			name:      "filter by Personal",
-			tag:       "Personal",
-			wantCount: 1,
-			wantNames: []string{"personal1"},
-		},
-		{
-			name:      "filter by Go",
-			tag:       "Go",
-			wantCount: 1,
-			wantNames: []string{"work2"},
-		},
-		{
-			name:      "empty tag returns all",
-			tag:       "",
-			wantCount: 4,
-		},
-		{
-			name:      "non-existent tag",
-			tag:       "NonExistent",
-			wantCount: 0,
-		},
+		{"https://github.com/ideaspaper/projector.git", "projector"},
+		{"https://github.com/ideaspaper/projector", "projector"},
+		{"https://github.com/ideaspaper/projector/", "projector"},
+		{"git@github.com:acme/api.git", "api"},
+		{"ssh://git@host:2222/team/web.git", "web"},
+		{"/srv/git/local.git", "local"},
+		{"", ""},
+		{"https://host/..", ""},
+		{"/srv/git/.", ""},
+		{"git@host:.git", ""},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			filtered := FilterByTag(projects, tt.tag)
-			if len(filtered) != tt.wantCount {
-				t.Errorf("got %d projects, want %d", len(filtered), tt.wantCount)
-			}
-
-			if tt.wantNames != nil {
-				gotNames := make(map[string]bool)
-				for _, p := range filtered {
-					gotNames[p.Name] = true
-				}
-				for _, name := range tt.wantNames {
-					if !gotNames[name] {
-						t.Errorf("expected project %q not found", name)
-					}
-				}
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := repoNameFromURL(tt.url)
+			if got != tt.want || (err != nil) != (tt.want == "") {
+				t.Errorf("repoNameFromURL(%q) = %q, %v; want %q", tt.url, got, err, tt.want)
 			}
 		})
 	}
 }
 
-func TestFindProjectByName(t *testing.T) {
-	projects := []*models.Project{
-		{Name: "my-project"},
-		{Name: "another-project"},
-		{Name: "my-other-project"},
-		{Name: "test"},
+func TestWriteCodeWorkspace(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "workspaces", "platform.code-workspace")
+
+	workspace := models.NewWorkspace("platform")
+	workspace.AddProject("/code/api")
+	workspace.AddProject("/code/unknown")
+	projects := []*models.Project{{Name: "API", RootPath: "/code/api"}}
+
+	if err := writeCodeWorkspace(file, workspace, projects); err != nil {
+		t.Fatalf("writeCodeWorkspace failed: %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read workspace file: %v", err)
+	}
+
+	var parsed struct {
+		Folders []codeWorkspaceFolder `json:"folders"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("invalid workspace file: %v", err)
+	}
+	if len(parsed.Folders) != 2 {
+		t.Fatalf("expected 2 folders, got %d", len(parsed.Folders))
+	}
+	if parsed.Folders[0].Name != "API" || parsed.Folders[0].Path != "/code/api" {
+		t.Errorf("unexpected first folder: %+v", parsed.Folders[0])
+	}
+	if parsed.Folders[1].Name != "" {
+		t.Errorf("expected unnamed folder for unknown project, got %q", parsed.Folders[1].Name)
 	}
+}
 
+func TestSplitRunArgs(t *testing.T) {
 	tests := []struct {
 		name        string
-		searchName  string
+		args        []string
+		dashAt      int
+		all         bool
 		wantProject string
-		wantMatches int
+		wantCommand []string
 		wantErr     bool
 	}{
-		{
-			name:        "exact match",
-			searchName:  "my-project",
-			wantProject: "my-project",
-			wantErr:     false,
-		},
-		{
-			name:        "exact match case insensitive",
-			searchName:  "MY-PROJECT",
-			wantProject: "my-project",
-			wantErr:     false,
-		},
-		{
-			name:        "single partial match",
-			searchName:  "test",
-			wantProject: "test",
-			wantErr:     false,
-		},
-		{
-			name:        "multiple partial matches",
-			searchName:  "my",
-			wantMatches: 2,
-			wantErr:     true,
-		},
-		{
-			name:       "no match",
-			searchName: "nonexistent",
-			wantErr:    true,
-		},
+		{"single project", []string{"api", "git", "pull"}, 1, false, "api", []string{"git", "pull"}, false},
+		{"all projects", []string{"git", "pull"}, 0, true, "", []string{"git", "pull"}, false},
+		{"missing dash", []string{"api", "git"}, -1, false, "", nil, true},
+		{"missing command", []string{"api"}, 1, false, "", nil, true},
+		{"missing project", []string{"git"}, 0, false, "", nil, true},
+		{"project with all", []string{"api", "git"}, 1, true, "", nil, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			project, matches, err := FindProjectByName(projects, tt.searchName)
+			project, command, err := splitRunArgs(tt.args, tt.dashAt, tt.all)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if project != tt.wantProject {
+				t.Errorf("project = %q, want %q", project, tt.wantProject)
+			}
+			if len(command) != len(tt.wantCommand) {
+				t.Errorf("command = %v, want %v", command, tt.wantCommand)
+			}
+		})
+	}
+}
 
-			if tt.wantErr {
-				if err == nil {
-					t.Errorf("expected error, got nil")
-				}
-				if tt.wantMatches > 0 && len(matches) != tt.wantMatches {
-					t.Errorf("expected %d matches, got %d", tt.wantMatches, len(matches))
-				}
-			} else {
-				if err != nil {
-					t.Errorf("unexpected error: %v", err)
-				}
-				if project == nil {
-					t.Errorf("expected project, got nil")
-				} else if project.Name != tt.wantProject {
-					t.Errorf("got project %q, want %q", project.Name, tt.wantProject)
+func TestSelectTagTargets(t *testing.T) {
+	projects := models.NewProjectList(models.KindFavorite)
+	projects.Add(models.NewProject("acme-api", "/work/acme/api"))
+	projects.Add(models.NewProject("acme-web", "/work/acme/web"))
+	projects.Add(models.NewProject("dotfiles", "/home/me/dotfiles"))
+
+	targets, err := selectTagTargets(projects, []string{"dotfiles"}, false, "")
+	if err != nil || len(targets) != 1 || targets[0].Name != "dotfiles" {
+		t.Errorf("expected dotfiles, got %v (err %v)", targets, err)
+	}
+
+	targets, err = selectTagTargets(projects, nil, true, "")
+	if err != nil || len(targets) != 3 {
+		t.Errorf("expected 3 targets with --all, got %d (err %v)", len(targets), err)
+	}
+
+	targets, err = selectTagTargets(projects, nil, true, "ACME")
+	if err != nil || len(targets) != 2 {
+		t.Errorf("expected 2 targets matching filter, got %d (err %v)", len(targets), err)
+	}
+
+	if _, err := selectTagTargets(projects, nil, false, ""); err == nil {
+		t.Error("expected error without names or --all")
+	}
+	if _, err := selectTagTargets(projects, []string{"dotfiles"}, true, ""); err == nil {
+		t.Error("expected error combining names with --all")
+	}
+	if _, err := selectTagTargets(projects, nil, false, "acme"); err == nil {
+		t.Error("expected error for --filter without --all")
+	}
+	if _, err := selectTagTargets(projects, []string{"missing"}, false, ""); err == nil {
+		t.Error("expected error for unknown project")
+	}
+}
+
+func TestRenameTagInList(t *testing.T) {
+	tags, changed := renameTagInList([]string{"Personal", "Work"}, "Work", "Job")
+	if !changed || tags[1] != "Job" {
+		t.Errorf("expected [Personal Job], got %v", tags)
+	}
+
+	tags, changed = renameTagInList([]string{"Personal", "Work"}, "Work", "Personal")
+	if !changed || len(tags) != 1 {
+		t.Errorf("expected [Personal], got %v", tags)
+	}
+
+	if _, changed := renameTagInList([]string{"Personal"}, "Work", "Job"); changed {
+		t.Error("expected no change for missing tag")
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"2", []int{1}, false},
+		{"1 3", []int{0, 2}, false},
+		{"1,4-6", []int{0, 3, 4, 5}, false},
+		{"3 1 3", []int{2, 0}, false},
+		{"", nil, true},
+		{"0", nil, true},
+		{"7", nil, true},
+		{"5-3", nil, true},
+		{"x", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSelection(tt.input, 6)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSelection(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseSelection(%q) = %v, want %v", tt.input, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseSelection(%q) = %v, want %v", tt.input, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestWriteSelectedPaths(t *testing.T) {
+	projects := []*models.Project{
+		models.NewProject("a", "/path/a"),
+		models.NewProject("b", "/path/with space/b"),
+	}
+
+	var buf bytes.Buffer
+	if err := writeSelectedPaths(&buf, projects, 0); err != nil {
+		t.Fatalf("writeSelectedPaths failed: %v", err)
+	}
+	if got := buf.String(); got != "/path/a\x00/path/with space/b\x00" {
+		t.Errorf("unexpected NUL-separated output: %q", got)
+	}
+
+	buf.Reset()
+	writeSelectedPaths(&buf, projects, '\n')
+	if got := buf.String(); got != "/path/a\n/path/with space/b\n" {
+		t.Errorf("unexpected newline-separated output: %q", got)
+	}
+}
+
+func TestScanAborted(t *testing.T) {
+	if err := scanAborted(nil); err != nil {
+		t.Errorf("expected nil for nil error, got %v", err)
+	}
+	if err := scanAborted(os.ErrNotExist); err != nil {
+		t.Errorf("expected nil for unrelated error, got %v", err)
+	}
+	if err := scanAborted(fmt.Errorf("wrapped: %w", context.Canceled)); err == nil {
+		t.Error("expected error for cancelled scan")
+	}
+	if err := scanAborted(context.DeadlineExceeded); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestFavoriteCopies(t *testing.T) {
+	favorites := models.NewProjectList(models.KindFavorite)
+	favorites.Add(models.NewProject("api", "/path/to/api"))
+
+	cached := []*models.Project{
+		{Name: "api", RootPath: "/path/to/api", Kind: models.KindGit},
+		{Name: "web", RootPath: "/path/to/web", Tags: []string{"Work"}, Language: "typescript", Kind: models.KindGit},
+	}
+
+	candidates := withoutFavorites(cached, favorites)
+	if len(candidates) != 1 || candidates[0].Name != "web" {
+		t.Fatalf("expected only 'web' as candidate, got %v", candidates)
+	}
+
+	added, skipped := mergeImportedProjects(favorites, favoriteCopies(cached))
+	if added != 1 || skipped != 1 {
+		t.Errorf("expected 1 added and 1 skipped, got %d and %d", added, skipped)
+	}
+
+	web := favorites.FindByName("web")
+	if web == nil || web.Kind != models.KindFavorite || web.Language != "typescript" || !web.HasTag("Work") {
+		t.Errorf("expected promoted favorite with details preserved, got %+v", web)
+	}
+	if cached[1].Kind != models.KindGit {
+		t.Error("expected cache entry to keep its kind")
+	}
+
+	web.AddTag("Extra")
+	if len(cached[1].Tags) != 1 {
+		t.Error("expected favorite tags to be independent of the cache entry")
+	}
+}
+
+func TestDaemonHandler(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	cache, _ := store.LoadCache()
+
+	var opened string
+	d := &daemonState{
+		store:   store,
+		cache:   cache,
+		editors: editor.NewRegistry(),
+		open: func(path, editor string, newWindow bool) error {
+			opened = path
+			return nil
+		},
+	}
+	handler := newDaemonHandler(d)
+
+	// GET /projects returns favorites and cache with kinds
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/projects?tag=Work", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var projects []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &projects); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(projects) != 1 || projects[0]["name"] != "favorite1" || projects[0]["kind"] != "favorites" {
+		t.Errorf("expected favorite1 with kind, got %v", projects)
+	}
+
+	post := func(handler http.Handler, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// POST /open resolves the name and calls the opener
+	rec = post(handler, "/open", `{"name": "git-repo1"}`)
+	if rec.Code != http.StatusOK || opened != "/path/to/git1" {
+		t.Errorf("expected git-repo1 to be opened, got %d %q", rec.Code, opened)
+	}
+
+	rec = post(handler, "/open", `{"name": "missing"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown project, got %d", rec.Code)
+	}
+
+	// Only built-in editors may be asked for
+	opened = ""
+	rec = post(handler, "/open", `{"name": "git-repo1", "editor": "/bin/sh"}`)
+	if rec.Code != http.StatusBadRequest || opened != "" {
+		t.Errorf("expected 400 for an unknown editor, got %d %q", rec.Code, opened)
+	}
+	rec = post(handler, "/open", `{"name": "git-repo1", "editor": "vim"}`)
+	if rec.Code != http.StatusOK || opened != "/path/to/git1" {
+		t.Errorf("expected a built-in editor to be accepted, got %d %q", rec.Code, opened)
+	}
+
+	// POST requests a web page could send without asking are rejected
+	opened = ""
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/open", strings.NewReader(`{"name": "git-repo1"}`))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType || opened != "" {
+		t.Errorf("expected 415 for a text/plain body, got %d %q", rec.Code, opened)
+	}
+
+	// Over TCP, only loopback hosts are answered
+	d.loopbackOnly = true
+	for host, want := range map[string]int{
+		"127.0.0.1:7878": http.StatusOK,
+		"localhost:7878": http.StatusOK,
+		"[::1]:7878":     http.StatusOK,
+		"evil.example":   http.StatusForbidden,
+		"10.0.0.5:7878":  http.StatusForbidden,
+		"projector":      http.StatusForbidden,
+	} {
+		rec = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/projects", nil)
+		req.Host = host
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("host %s: expected %d, got %d", host, want, rec.Code)
+		}
+	}
+	d.loopbackOnly = false
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/open", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET /open, got %d", rec.Code)
+	}
+}
+
+func TestWriteShellInit(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeShellInit(&buf, shell, "p"); err != nil {
+				t.Fatalf("writeShellInit() error = %v", err)
+			}
+			out := buf.String()
+			for _, want := range []string{"pcd", "projector select", "completion-data"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("%s snippet missing %q", shell, want)
 				}
 			}
+			if strings.Contains(out, "{{") {
+				t.Errorf("%s snippet has unrendered template actions", shell)
+			}
 		})
 	}
+
+	if err := writeShellInit(&bytes.Buffer{}, "tcsh", "pj"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+	if err := writeShellInit(&bytes.Buffer{}, "bash", "pj; rm"); err == nil {
+		t.Error("expected error for invalid function name")
+	}
+}
+
+func TestSplitProjectFile(t *testing.T) {
+	tests := []struct {
+		arg, name, file string
+	}{
+		{"api", "api", ""},
+		{"api:cmd/root.go", "api", "cmd/root.go"},
+		{"api:cmd/root.go:42", "api", "cmd/root.go:42"},
+		{".:README.md", ".", "README.md"},
+		{":weird", ":weird", ""},
+		{"client:web", "client:web", ""},
+	}
+	projects := []*models.Project{{Name: "api"}, {Name: "client:web"}}
+	matches := func(name string) bool {
+		_, _, err := projector.FindProjectByName(projects, name, projector.MatchOptions{})
+		return err == nil
+	}
+	for _, tt := range tests {
+		name, file := splitProjectFile(tt.arg, matches)
+		if name != tt.name || file != tt.file {
+			t.Errorf("splitProjectFile(%q) = %q, %q; want %q, %q", tt.arg, name, file, tt.name, tt.file)
+		}
+	}
+
+	file, line := splitFileLine("cmd/root.go:42")
+	if file != "cmd/root.go" || line != 42 {
+		t.Errorf("splitFileLine() = %q, %d", file, line)
+	}
+	file, line = splitFileLine("notes:draft.md")
+	if file != "notes:draft.md" || line != 0 {
+		t.Errorf("splitFileLine() without line = %q, %d", file, line)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dir, _ := filepath.EvalSymlinks(t.TempDir())
+	api := filepath.Join(dir, "api")
+	os.Mkdir(api, 0755)
+	link := filepath.Join(dir, "api-link")
+	if err := os.Symlink(api, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	favorite := &models.Project{Name: "api", RootPath: api, Tags: []string{"Work"}, Kind: models.KindFavorite}
+	cached := &models.Project{Name: "api-link", RootPath: link, Description: "API server", Kind: models.KindGit}
+	other := &models.Project{Name: "API", RootPath: filepath.Join(dir, "other", "api"), Kind: models.KindGit}
+	unique := &models.Project{Name: "web", RootPath: filepath.Join(dir, "web"), Kind: models.KindGit}
+
+	groups := findDuplicates([]*models.Project{favorite, cached, other, unique})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if !groups[0].samePath || len(groups[0].projects) != 2 || groups[0].projects[0] != favorite {
+		t.Errorf("unexpected same-path group: %+v", groups[0])
+	}
+	if groups[1].samePath || len(groups[1].projects) != 2 || groups[1].projects[1] != other {
+		t.Errorf("unexpected same-name group: %+v", groups[1])
+	}
+
+	removed := make(map[*models.Project]bool)
+	resolveDuplicateGroup(groups[0], 0, removed)
+	if !removed[cached] || removed[favorite] {
+		t.Errorf("expected only the cached entry removed, got %v", removed)
+	}
+	if favorite.Description != "API server" || !favorite.HasTag("Work") {
+		t.Errorf("expected details merged into the kept entry, got %+v", favorite)
+	}
+
+	favorites := &models.ProjectList{Projects: []*models.Project{favorite}}
+	cache := &storage.CachedProjects{Git: []*models.Project{cached, other, unique}}
+	removeProjects(favorites, cache, removed)
+	if len(favorites.Projects) != 1 || len(cache.Git) != 2 {
+		t.Errorf("unexpected lists after removal: %d favorites, %d git", len(favorites.Projects), len(cache.Git))
+	}
+}
+
+func TestDoctorChecks(t *testing.T) {
+	dir := t.TempDir()
+
+	if got := editorBinary(editor.NewRegistry(), "vscode"); got != editor.Code {
+		t.Errorf("editorBinary(vscode) = %q", got)
+	}
+	if got := editorBinary(editor.NewRegistry(), "nvim -p"); got != "nvim" {
+		t.Errorf("editorBinary(nvim -p) = %q", got)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Editor = "vscode"
+	cfg.TerminalCommand = "missing-term --cd {path}"
+	lookPath := func(name string) (string, error) {
+		if name == editor.Code {
+			return "/usr/bin/code", nil
+		}
+		return "", fmt.Errorf("not found")
+	}
+	findings := checkExecutables(cfg, lookPath)
+	if len(findings) != 2 || findings[0].level != doctorOK || findings[1].level != doctorFail {
+		t.Errorf("unexpected executable findings: %+v", findings)
+	}
+
+	cfg.GitBaseFolders = []string{dir, filepath.Join(dir, "missing")}
+	findings = checkBaseFolders(cfg)
+	if len(findings) != 1 || findings[0].level != doctorFail || !strings.Contains(findings[0].msg, "missing") {
+		t.Errorf("unexpected base folder findings: %+v", findings)
+	}
+
+	missing, _ := missingProjects([]*models.Project{
+		{Name: "here", RootPath: dir},
+		{Name: "gone", RootPath: filepath.Join(dir, "gone")},
+	})
+	if len(missing) != 1 || missing[0].Name != "gone" {
+		t.Errorf("unexpected missing projects: %v", missing)
+	}
+}
+
+func TestCompileSearchPattern(t *testing.T) {
+	re, err := compileSearchPattern("todo(", true, true)
+	if err != nil {
+		t.Fatalf("compileSearchPattern() error = %v", err)
+	}
+	if !re.MatchString("// TODO(me): fix") {
+		t.Error("expected literal, case-insensitive match")
+	}
+
+	if _, err := compileSearchPattern("todo(", false, false); err == nil {
+		t.Error("expected error for invalid regular expression")
+	}
+
+	projects := uniqueByPath([]*models.Project{
+		{Name: "api", RootPath: "/code/api"},
+		{Name: "api-cached", RootPath: "/code/api"},
+		{Name: "web", RootPath: "/code/web"},
+	})
+	if len(projects) != 2 || projects[0].Name != "api" {
+		t.Errorf("unexpected unique projects: %v", projects)
+	}
+
+	if got := truncateLine(strings.Repeat("x", maxSearchLineLength+10)); len(got) != maxSearchLineLength+3 {
+		t.Errorf("expected truncated line, got length %d", len(got))
+	}
+}
+
+func TestSelectProjects(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "api", Tags: []string{"Work"}},
+		{Name: "tmp-one", Tags: []string{"Archive"}},
+		{Name: "tmp-two", Tags: []string{"Archive", "Work"}},
+		{Name: "web"},
+	}
+	names := func(ps []*models.Project) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		patterns []string
+		tag      string
+		want     string
+		wantErr  bool
+	}{
+		{patterns: nil, tag: "Archive", want: "tmp-one,tmp-two"},
+		{patterns: []string{"TMP-*"}, want: "tmp-one,tmp-two"},
+		{patterns: []string{"tmp-*"}, tag: "Work", want: "tmp-two"},
+		{patterns: []string{"api", "web", "a*"}, want: "api,web"},
+		{patterns: []string{"we"}, want: "web"},
+		{patterns: []string{"tmp"}, wantErr: true},
+		{patterns: []string{"x*"}, wantErr: true},
+		{patterns: []string{"api"}, tag: "Archive", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := selectProjects(projects, tt.patterns, tt.tag)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("selectProjects(%v, %q): expected error, got %s", tt.patterns, tt.tag, names(got))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("selectProjects(%v, %q): %v", tt.patterns, tt.tag, err)
+			continue
+		}
+		if names(got) != tt.want {
+			t.Errorf("selectProjects(%v, %q) = %s, want %s", tt.patterns, tt.tag, names(got), tt.want)
+		}
+	}
+}
+
+func TestSelectProjects_FullPath(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "portal", RootPath: "/work/clients/acme"},
+		{Name: "site", RootPath: "/work/clients/globex"},
+		{Name: "tools", RootPath: "/work/internal/tools"},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.FilterOnFullPath = true
+	defer setApp(newAppContext(cfg, nil))()
+
+	got, err := selectProjects(projects, []string{"clients/*"}, "")
+	if err != nil || len(got) != 2 || got[0].Name != "portal" || got[1].Name != "site" {
+		t.Errorf("selectProjects(clients/*) = %v, %v; want portal and site", got, err)
+	}
+	got, err = selectProjects(projects, []string{"clients/acme"}, "")
+	if err != nil || len(got) != 1 || got[0].Name != "portal" {
+		t.Errorf("selectProjects(clients/acme) = %v, %v; want portal", got, err)
+	}
+}
+
+func TestBuildScanJobs(t *testing.T) {
+	origAll, origGit, origDepth := scanAll, scanGit, scanDepth
+	defer func() { scanAll, scanGit, scanDepth = origAll, origGit, origDepth }()
+
+	cfg := &config.Config{
+		GitBaseFolders: []string{"/code"},
+		GitMaxDepth:    4,
+		SVNBaseFolders: []string{"/svn"},
+		SVNMaxDepth:    2,
+	}
+
+	// All types: only types with base folders produce jobs
+	scanAll, scanGit, scanDepth = true, false, 0
+	jobs := projector.BuildScanJobs(cfg, scanOptions(nil))
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	if jobs[0].Type != scanner.ScannerGit || jobs[0].MaxDepth != 4 {
+		t.Errorf("unexpected git job: %+v", jobs[0])
+	}
+
+	// Git only, with path arguments and depth override
+	scanAll, scanGit, scanDepth = false, true, 7
+	jobs = projector.BuildScanJobs(cfg, scanOptions([]string{"/other"}))
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 job, got %d", len(jobs))
+	}
+	if jobs[0].BaseFolders[0] != "/other" || jobs[0].MaxDepth != 7 {
+		t.Errorf("expected args and depth override, got %+v", jobs[0])
+	}
+}
+
+func TestRelativeDepth(t *testing.T) {
+	if d := relativeDepth("/code", "/code/a/b"); d != 2 {
+		t.Errorf("relativeDepth = %d, want 2", d)
+	}
+	if d := relativeDepth("/code", "/code"); d != 0 {
+		t.Errorf("relativeDepth = %d, want 0", d)
+	}
+}
+
+func TestSubshellCommand(t *testing.T) {
+	t.Setenv(envProject, "outer")
+	project := &models.Project{Name: "api", RootPath: "/code/api"}
+
+	c := subshellCommand(project, "/bin/zsh")
+	if c.Path != "/bin/zsh" || c.Dir != "/code/api" {
+		t.Errorf("subshellCommand() path = %s, dir = %s", c.Path, c.Dir)
+	}
+
+	var projects []string
+	for _, kv := range c.Env {
+		if strings.HasPrefix(kv, envProject+"=") {
+			projects = append(projects, kv)
+		}
+	}
+	if len(projects) != 1 || projects[0] != envProject+"=api" {
+		t.Errorf("expected %s=api only, got %v", envProject, projects)
+	}
+}
+
+func TestMenuEntries(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "api", RootPath: "/work/api", Kind: models.KindFavorite},
+		{Name: "web", RootPath: "/a/web", Kind: models.KindGit},
+		{Name: "web", RootPath: "/b/web", Kind: models.KindGit},
+	}
+
+	lines, entries := menuEntries(projects)
+	want := []string{"★  api", "git  web  (/a/web)", "git  web  (/b/web)"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("menuEntries() lines = %q, want %q", lines, want)
+	}
+	if entries["git  web  (/b/web)"] != projects[2] {
+		t.Error("expected duplicate names to map to their own project")
+	}
+}
+
+func TestResolveMenuBackend(t *testing.T) {
+	onPath := func(names ...string) func(string) (string, error) {
+		return func(bin string) (string, error) {
+			for _, n := range names {
+				if n == bin {
+					return "/usr/bin/" + bin, nil
+				}
+			}
+			return "", exec.ErrNotFound
+		}
+	}
+
+	if got, err := resolveMenuBackend("", onPath("dmenu", "wofi")); err != nil || got != "wofi" {
+		t.Errorf("auto-detect = %q, %v; want wofi", got, err)
+	}
+	if got, err := resolveMenuBackend("dmenu", onPath()); err != nil || got != "dmenu" {
+		t.Errorf("explicit backend = %q, %v; want dmenu", got, err)
+	}
+	if _, err := resolveMenuBackend("", onPath()); err == nil {
+		t.Error("expected error when no launcher is installed")
+	}
+	if _, err := resolveMenuBackend("fzf", onPath("fzf")); err == nil {
+		t.Error("expected error for unsupported backend")
+	}
+}
+
+func TestFilterRepos(t *testing.T) {
+	repos := []forge.Repo{
+		{Name: "api"},
+		{Name: "old", Archived: true},
+		{Name: "upstream", Fork: true},
+	}
+
+	names := func(rs []forge.Repo) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+
+	if got := names(filterRepos(repos, false, false)); !reflect.DeepEqual(got, []string{"api"}) {
+		t.Errorf("default filter = %v, want [api]", got)
+	}
+	if got := names(filterRepos(repos, true, true)); len(got) != 3 {
+		t.Errorf("including archived and forks = %v, want all 3", got)
+	}
+}
+
+func TestRepoDir(t *testing.T) {
+	tests := []struct {
+		repo  forge.Repo
+		owner string
+		want  string
+	}{
+		{forge.Repo{Name: "api", FullName: "acme/api"}, "acme", "api"},
+		{forge.Repo{Name: "api", FullName: "Acme/api"}, "acme", "api"},
+		{forge.Repo{Name: "web", FullName: "acme/platform/frontend/web"}, "acme/platform", filepath.Join("frontend", "web")},
+		{forge.Repo{Name: "lib", FullName: "other/lib"}, "acme", "lib"},
+	}
+
+	for _, tt := range tests {
+		if got := repoDir(tt.repo, tt.owner); got != tt.want {
+			t.Errorf("repoDir(%q, %q) = %q, want %q", tt.repo.FullName, tt.owner, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+		3 << 30:         "3.0 GiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestRunSetupWizard(t *testing.T) {
+	base := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.GitBaseFolders = []string{"~/old"}
+	cfg.AnyBaseFolders = []string{"~/scratch"}
+	cfg.Tags = []string{"Work"}
+
+	input := strings.Join([]string{
+		base + ", " + filepath.Join(base, "missing"), // git
+		"",      // svn: keep (empty)
+		"",      // mercurial
+		"",      // vscode
+		"-",     // any: clear
+		"nvim",  // editor
+		"",      // tags: keep
+		"bogus", // rejected sort order
+		"recent",
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	p := &setupPrompter{in: bufio.NewReader(strings.NewReader(input)), out: &out}
+
+	if err := runSetupWizard(p, cfg); err != nil {
+		t.Fatalf("runSetupWizard failed: %v", err)
+	}
+
+	if want := []string{base, filepath.Join(base, "missing")}; !reflect.DeepEqual(cfg.GitBaseFolders, want) {
+		t.Errorf("GitBaseFolders = %v, want %v", cfg.GitBaseFolders, want)
+	}
+	if len(cfg.AnyBaseFolders) != 0 {
+		t.Errorf("expected AnyBaseFolders to be cleared, got %v", cfg.AnyBaseFolders)
+	}
+	if cfg.Editor != "nvim" {
+		t.Errorf("Editor = %q, want nvim", cfg.Editor)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"Work"}) {
+		t.Errorf("Tags = %v, want [Work]", cfg.Tags)
+	}
+	if cfg.SortList != config.SortByRecent {
+		t.Errorf("SortList = %q, want %q", cfg.SortList, config.SortByRecent)
+	}
+	for _, want := range []string{"does not exist yet", "'bogus' is not a sort order", "[~/old]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunSetupWizard_EndOfInputKeepsValues(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.GitBaseFolders = []string{"~/code"}
+	p := &setupPrompter{in: bufio.NewReader(strings.NewReader("")), out: io.Discard}
+
+	if err := runSetupWizard(p, cfg); err != nil {
+		t.Fatalf("runSetupWizard failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.GitBaseFolders, []string{"~/code"}) || cfg.SortList != config.SortByName {
+		t.Errorf("expected values to be kept, got %v and %q", cfg.GitBaseFolders, cfg.SortList)
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	t.Setenv("PAGER", "")
+
+	if got := pagerCommand(cfg); !reflect.DeepEqual(got, []string{"less", "-R"}) {
+		t.Errorf("expected default pager, got %v", got)
+	}
+
+	t.Setenv("PAGER", "more")
+	if got := pagerCommand(cfg); !reflect.DeepEqual(got, []string{"more"}) {
+		t.Errorf("expected $PAGER, got %v", got)
+	}
+
+	cfg.Pager = "bat --plain"
+	if got := pagerCommand(cfg); !reflect.DeepEqual(got, []string{"bat", "--plain"}) {
+		t.Errorf("expected configured pager, got %v", got)
+	}
+
+	cfg.Pager = "cat"
+	if got := pagerCommand(cfg); got != nil {
+		t.Errorf("expected cat to disable paging, got %v", got)
+	}
+
+	cfg.Pager = ""
+	cfg.UsePager = false
+	if got := pagerCommand(cfg); got != nil {
+		t.Errorf("expected usePager false to disable paging, got %v", got)
+	}
+
+	cfg.UsePager = true
+	noPager = true
+	defer func() { noPager = false }()
+	if got := pagerCommand(cfg); got != nil {
+		t.Errorf("expected --no-pager to disable paging, got %v", got)
+	}
+}
+
+func TestWriteScanPreview(t *testing.T) {
+	previews := []projector.ScanPreview{
+		{
+			Label: "Git repositories",
+			Projects: []*models.Project{
+				{Name: "api", RootPath: "/code/api"},
+				{Name: "website", RootPath: "/code/website"},
+			},
+			Added:   []string{"/code/website"},
+			Removed: []string{"/code/old"},
+		},
+		{Label: "folders", Error: "permission denied"},
+	}
+
+	var out bytes.Buffer
+	writeScanPreview(&out, output.NewFormatter(false, output.FormatterOptions{}), previews)
+	got := out.String()
+	for _, want := range []string{
+		"Would find 2 Git repositories (1 new, 1 gone)",
+		"    api      /code/api\n",
+		"  + website  /code/website\n",
+		"  - /code/old\n",
+		"Error scanning folders: permission denied",
+		"cache left unchanged",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteArchivedList(t *testing.T) {
+	var out bytes.Buffer
+	writeArchivedList(&out, output.NewFormatter(false, output.FormatterOptions{}), nil)
+	if !strings.Contains(out.String(), "No archived projects") {
+		t.Errorf("expected a note for an empty archive, got %q", out.String())
+	}
+
+	archived := []*models.ArchivedProject{
+		{Project: &models.Project{Name: "old", RootPath: "/code/old"}, Kind: models.KindGit},
+		{Project: &models.Project{Name: "client", RootPath: "/code/client"}, Kind: models.KindFavorite, Location: "/archive/client.tar.gz"},
+	}
+	out.Reset()
+	writeArchivedList(&out, output.NewFormatter(false, output.FormatterOptions{}), archived)
+	got := out.String()
+	for _, want := range []string{
+		"old (git, archived ",
+		"  /code/old\n",
+		"client (favorites, archived ",
+		"  -> /archive/client.tar.gz\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteProjectChanges(t *testing.T) {
+	before := []*models.Project{
+		{Name: "api", RootPath: "/code/api", Tags: []string{"Work"}},
+		{Name: "web", RootPath: "/code/web"},
+	}
+	after := []*models.Project{
+		{Name: "api", RootPath: "/code/api"},
+		{Name: "old", RootPath: "/code/old"},
+	}
+
+	var out bytes.Buffer
+	writeProjectChanges(&out, output.NewFormatter(false, output.FormatterOptions{}), before, after)
+	want := "  ~ api  /code/api\n  + old  /code/old\n  - web  /code/web\n"
+	if out.String() != want {
+		t.Errorf("writeProjectChanges() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestEditMatching(t *testing.T) {
+	old := &models.Project{Name: "old", Tags: []string{"Old"}, Enabled: true}
+	done := &models.Project{Name: "done", Tags: []string{"Old"}}
+	current := &models.Project{Name: "current", Tags: []string{"Work"}, Enabled: true}
+
+	filter, err := projector.ParseFilter("tag=old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := projector.ParseAssignment("enabled=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matched, updated := editMatching([]*models.Project{old, done, current}, []projector.Filter{filter}, []projector.Assignment{set})
+	if matched != 2 {
+		t.Errorf("matched = %d, want 2", matched)
+	}
+	if len(updated) != 1 || updated[0] != old {
+		t.Errorf("expected only 'old' updated, got %v", updated)
+	}
+	if old.Enabled || !current.Enabled {
+		t.Error("expected only matching projects disabled")
+	}
+}
+
+func TestWriteBackupList(t *testing.T) {
+	var out bytes.Buffer
+	writeBackupList(&out, output.NewFormatter(false, output.FormatterOptions{}), nil)
+	if !strings.Contains(out.String(), "No automatic backups") {
+		t.Errorf("expected a note without backups, got %q", out.String())
+	}
+
+	out.Reset()
+	writeBackupList(&out, output.NewFormatter(false, output.FormatterOptions{}), []string{"/b/projects-1.json", "/b/projects-2.json"})
+	if out.String() != "/b/projects-2.json\n/b/projects-1.json\n" {
+		t.Errorf("expected the newest backup first, got %q", out.String())
+	}
+}
+
+// memProvider is a cloudsync.Provider keeping the file in memory
+type memProvider struct{ data []byte }
+
+func (p *memProvider) Name() string { return "memory" }
+
+func (p *memProvider) Pull(ctx context.Context) ([]byte, error) {
+	if p.data == nil {
+		return nil, cloudsync.ErrNotFound
+	}
+	return p.data, nil
+}
+
+func (p *memProvider) Push(ctx context.Context, data []byte) error {
+	p.data = data
+	return nil
+}
+
+func TestPushAndPullFavorites(t *testing.T) {
+	provider := &memProvider{}
+	if remote, err := pullFavorites(context.Background(), provider); err != nil || remote != nil {
+		t.Fatalf("expected nothing pulled before a push, got %v, %v", remote, err)
+	}
+
+	home, _ := os.UserHomeDir()
+	api := models.NewProject("api", filepath.Join(home, "code", "api"))
+	api.Tags = []string{"Work"}
+	cfg := config.DefaultConfig()
+	if err := pushFavorites(cfg, provider, output.NewFormatter(false, output.FormatterOptions{}), []*models.Project{api}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(provider.data), `"~/code/api"`) {
+		t.Errorf("expected a collapsed path in the pushed file, got:\n%s", provider.data)
+	}
+
+	remote, err := pullFavorites(context.Background(), provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remote) != 1 || remote[0].RootPath != api.RootPath || remote[0].Kind != models.KindFavorite || !remote[0].HasTag("Work") {
+		t.Errorf("expected api back as a favorite, got %+v", remote)
+	}
+}
+
+// syncServer serves a file for the http sync provider from memory
+func syncServer(t *testing.T) (*httptest.Server, *[]byte) {
+	t.Helper()
+	var data []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if data == nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data)
+		case http.MethodPut:
+			data, _ = io.ReadAll(r.Body)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &data
+}
+
+func TestRunSync_UpToDate(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, data := syncServer(t)
+	cfg := config.DefaultConfig()
+	cfg.Sync.Provider = "http"
+	cfg.Sync.URL = srv.URL
+	defer setApp(newAppContext(cfg, store))()
+
+	stdout, _, err := runCommand(t, "sync", "--no-color")
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if strings.Contains(stdout, "Added") || !strings.Contains(stdout, "up to date") || !strings.Contains(stdout, "Pushed 2 favorites") {
+		t.Errorf("expected the favorites to be up to date and pushed, got:\n%s", stdout)
+	}
+	if !strings.Contains(string(*data), "favorite1") {
+		t.Errorf("expected the favorites to be pushed, got:\n%s", *data)
+	}
+}
+
+func TestRunSyncPull_Replace(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, _ := syncServer(t)
+	cfg := config.DefaultConfig()
+	cfg.Sync.Provider = "http"
+	cfg.Sync.URL = srv.URL
+	defer setApp(newAppContext(cfg, store))()
+
+	remote := []*models.Project{models.NewProject("remote", "/path/to/remote")}
+	if err := pushFavorites(cfg, cloudsync.NewHTTP(srv.URL, "", ""), output.NewFormatter(false, output.FormatterOptions{}), remote); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := runCommand(t, "sync", "pull", "--replace", "--no-input"); !errors.Is(err, errNoInput) {
+		t.Errorf("expected --no-input to refuse replacing without --yes, got %v", err)
+	}
+	if projects, _ := store.LoadProjects(); len(projects.Projects) != 2 {
+		t.Fatalf("expected the local favorites to be kept, got %d", len(projects.Projects))
+	}
+
+	if _, _, err := runCommand(t, "sync", "pull", "--replace", "--yes"); err != nil {
+		t.Fatalf("sync pull --replace --yes failed: %v", err)
+	}
+	projects, _ := store.LoadProjects()
+	if len(projects.Projects) != 1 || projects.Projects[0].Name != "remote" {
+		t.Errorf("expected only the remote favorite, got %+v", projects.Projects)
+	}
+}
+
+func TestFindProject_LeavesOutCurrent(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"api-server", "api-client"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+	}
+	projects := []*models.Project{
+		{Name: "api-server", RootPath: filepath.Join(root, "api-server")},
+		{Name: "api-client", RootPath: filepath.Join(root, "api-client")},
+	}
+	t.Chdir(filepath.Join(root, "api-server"))
+
+	cfg := config.DefaultConfig()
+	p, matches, err := findProject(cfg, nil, projects, "api")
+	if err != nil || p == nil || p.Name != "api-client" {
+		t.Errorf("findProject(api) = %v, %v, %v; want api-client", p, matches, err)
+	}
+	if got := withoutCurrentProject(cfg, projects); len(got) != 1 || got[0].Name != "api-client" {
+		t.Errorf("withoutCurrentProject() = %v, want api-client", got)
+	}
+
+	cfg.RemoveCurrentFromList = false
+	if _, matches, err := findProject(cfg, nil, projects, "api"); err == nil || len(matches) != 2 {
+		t.Errorf("findProject(api) without removeCurrentProjectFromList = %v, %v; want both matches", matches, err)
+	}
+	if got := withoutCurrentProject(cfg, projects); len(got) != 2 {
+		t.Errorf("withoutCurrentProject() = %v, want both", got)
+	}
+}
+
+func TestTypeFlags(t *testing.T) {
+	parse := func(args ...string) (projector.TypeFilter, error) {
+		var flags typeFlags
+		cmd := &cobra.Command{Use: "test"}
+		flags.register(cmd)
+		if err := cmd.ParseFlags(args); err != nil {
+			return projector.TypeFilter{}, err
+		}
+		return flags.filter()
+	}
+
+	tests := []struct {
+		args    []string
+		want    projector.TypeFilter
+		wantErr bool
+	}{
+		{args: nil, want: projector.TypeFilter{}},
+		{args: []string{"--git", "--type", "vscode"}, want: projector.TypeFilter{Git: true, VSCode: true}},
+		{args: []string{"--type", "git,svn", "--type=any"}, want: projector.TypeFilter{Git: true, SVN: true, Any: true}},
+		{args: []string{"--favorites", "--favorites=false", "--svn"}, want: projector.TypeFilter{SVN: true}},
+		{args: []string{"--exclude-type", "any,vscode"}, want: projector.TypeFilter{Favorites: true, Git: true, SVN: true, Mercurial: true}},
+		{args: []string{"--git", "--exclude-type", "git"}, wantErr: true},
+		{args: []string{"--type", "cvs"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parse(tt.args...)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%v: filter = %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestFindProject_Previous(t *testing.T) {
+	store, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects := []*models.Project{
+		{Name: "api", RootPath: "/work/api"},
+		{Name: "web", RootPath: "/work/web"},
+	}
+	cfg := config.DefaultConfig()
+
+	if _, _, err := findProject(cfg, store, projects, "-"); err == nil {
+		t.Error("expected an error before anything was opened")
+	}
+
+	store.RecordOpen(time.Now(), "/work/api")
+	store.RecordOpen(time.Now(), "/work/web")
+	p, _, err := findProject(cfg, store, projects, "-")
+	if err != nil || p.Name != "api" {
+		t.Errorf("findProject(-) = %v, %v; want api", p, err)
+	}
+
+	if _, _, err := findProject(cfg, store, projects[1:], "-"); err == nil {
+		t.Error("expected an error when the previous project is not listed")
+	}
+}
+
+func TestNoInput(t *testing.T) {
+	noInput = true
+	defer func() { noInput = false }()
+
+	if _, err := ReadUserInput(); !errors.Is(err, errNoInput) {
+		t.Errorf("ReadUserInput() error = %v, want errNoInput", err)
+	}
+	projects := []*models.Project{{Name: "api", RootPath: "/work/api"}}
+	if _, err := selectProjectInteractive(openCmd, projects, config.DefaultConfig()); !errors.Is(err, errNoInput) {
+		t.Errorf("selectProjectInteractive() error = %v, want errNoInput", err)
+	}
+}
+
+func TestPrintStatus_Quiet(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	printStatus("shown")
+	quiet = true
+	printStatus("hidden")
+	quiet = false
+	os.Stdout = stdout
+	w.Close()
+
+	data, _ := io.ReadAll(r)
+	if string(data) != "shown\n" {
+		t.Errorf("printed %q, want only the message before --quiet", data)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{fmt.Errorf("failed to load config"), exitFailure},
+		{fmt.Errorf("open: %w", &projector.NotFoundError{Name: "api"}), exitNotFound},
+		{withExitCode(exitAmbiguous, fmt.Errorf("please be more specific")), exitAmbiguous},
+		{withExitCode(exitPathMissing, fmt.Errorf("project path does not exist: /x")), exitPathMissing},
+		{&projector.AmbiguousError{Name: "api"}, exitAmbiguous},
+		{fmt.Errorf("cd: %w", &projector.PathMissingError{Name: "api", Path: "/x"}), exitPathMissing},
+		{fmt.Errorf("wrapped: %w", models.ErrProjectNotFound), exitNotFound},
+		{errCancelled, exitCancelled},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestAppContext(t *testing.T) {
+	store, err := storage.NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.RecordOpen(time.Now(), "/work/api")
+	cfg := config.DefaultConfig()
+	defer setApp(newAppContext(cfg, store))()
+
+	if got, err := app.Config(); err != nil || got != cfg {
+		t.Errorf("Config() = %p, %v; want the injected config", got, err)
+	}
+	if got, err := app.Storage(); err != nil || got != store {
+		t.Errorf("Storage() = %p, %v; want the injected storage", got, err)
+	}
+	if app.Formatter() != app.Formatter() {
+		t.Error("expected the formatter to be created once")
+	}
+	history, err := app.History()
+	if err != nil || len(history) != 1 || history[0].Path != "/work/api" {
+		t.Errorf("History() = %v, %v; want the recorded open", history, err)
+	}
+	m, err := app.Manager()
+	if err != nil || m.Storage() != store {
+		t.Errorf("Manager() = %v, %v; want a manager on the injected storage", m, err)
+	}
+}
+
+func TestNewerVersion(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"v1.2.3", "v1.3.0", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.10.0", "v1.9.9", false},
+		{"1.2", "v1.2.1", true},
+		{"v1.2.3-4-gabc1234-dirty", "v1.2.4", true},
+		{"dev", "v9.0.0", false},
+		{"v1.2.3", "nightly", false},
+	}
+	for _, tt := range tests {
+		if got := newerVersion(tt.current, tt.latest); got != tt.want {
+			t.Errorf("newerVersion(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestConfigLocations(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	files, err := configFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writeLocations(&buf, output.NewFormatter(false, output.FormatterOptions{}), files, []string{"cache"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "cache.json") + "\n"; buf.String() != want {
+		t.Errorf("config path cache = %q, want %q", buf.String(), want)
+	}
+
+	dirs, err := configDirs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	writeLocations(&buf, output.NewFormatter(false, output.FormatterOptions{}), dirs, nil)
+	if want := "data:     " + dir + "\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in:\n%s", want, buf.String())
+	}
+}
+
+func TestRunAddWizard(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"api-one", "api-two", "web"} {
+		if err := os.Mkdir(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projects := models.NewProjectList(models.KindFavorite)
+	projects.Add(models.NewProject("taken", "/work/taken"))
+
+	input := strings.Join([]string{
+		filepath.Join(base, "ap"),    // two matches: completed to api-
+		filepath.Join(base, "api-t"), // one match
+		"",                           // accept the completed path
+		"taken",                      // name in use
+		"",                           // accept the folder name
+		"3",                          // no such tag
+		"2, Go, 2",
+		"Billing API",
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	p := &setupPrompter{in: bufio.NewReader(strings.NewReader(input)), out: &out}
+
+	answers, err := runAddWizard(p, []string{"Personal", "Work"}, projects, addAnswers{path: base})
+	if err != nil {
+		t.Fatalf("runAddWizard failed: %v\n%s", err, out.String())
+	}
+	want := addAnswers{path: filepath.Join(base, "api-two"), name: "api-two", tags: []string{"Work", "Go"}, description: "Billing API"}
+	if !reflect.DeepEqual(answers, want) {
+		t.Errorf("answers = %+v, want %+v", answers, want)
+	}
+	for _, msg := range []string{"api-one", "already exists", "no tag numbered 3"} {
+		if !strings.Contains(out.String(), msg) {
+			t.Errorf("expected %q in output, got:\n%s", msg, out.String())
+		}
+	}
+
+	// Out of input with an invalid answer fails instead of asking again
+	p = &setupPrompter{in: bufio.NewReader(strings.NewReader(filepath.Join(base, "missing"))), out: io.Discard}
+	if _, err := runAddWizard(p, nil, projects, addAnswers{path: base}); err == nil {
+		t.Error("expected an error for a missing folder at the end of input")
+	}
+}
+
+func TestTrashProjectFolder(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	store, err := storage.NewStorage(filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+	m, err := app.Manager()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folder := filepath.Join(dir, "experiment")
+	os.MkdirAll(folder, 0755)
+	project := &models.Project{Name: "experiment", RootPath: folder}
+
+	noInput = true
+	_, err = trashProjectFolder(m, project, false)
+	noInput = false
+	if !errors.Is(err, errNoInput) {
+		t.Errorf("trashProjectFolder() without --force = %v, want errNoInput", err)
+	}
+
+	home, _ := os.UserHomeDir()
+	if _, err := trashProjectFolder(m, &models.Project{Name: "home", RootPath: home}, true); err == nil {
+		t.Error("expected the home directory to be refused")
+	}
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	if trashed, err := trashProjectFolder(m, project, true); err != nil || !trashed {
+		t.Fatalf("trashProjectFolder() = %v, %v", trashed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data", "Trash", "files", "experiment")); err != nil {
+		t.Errorf("expected the folder in the trash: %v", err)
+	}
+	if trashed, err := trashProjectFolder(m, project, true); err != nil || trashed {
+		t.Errorf("trashProjectFolder() of a missing folder = %v, %v; want false, nil", trashed, err)
+	}
+}
+
+func TestRunPath_ExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewStorage(filepath.Join(dir, "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects := models.NewProjectList(models.KindFavorite)
+	for _, name := range []string{"api-server", "api-client", "gone"} {
+		path := filepath.Join(dir, name)
+		if name != "gone" {
+			os.MkdirAll(path, 0755)
+		}
+		projects.Add(&models.Project{Name: name, RootPath: path, Enabled: true})
+	}
+	if err := store.SaveProjects(projects); err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	tests := map[string]int{"api": exitAmbiguous, "missing": exitNotFound, "gone": exitPathMissing}
+	for name, want := range tests {
+		if got := exitCode(runPath(pathCmd, []string{name})); got != want {
+			t.Errorf("path %s exit code = %d, want %d", name, got, want)
+		}
+	}
+}
+
+// updateGolden rewrites the golden files in testdata with the output of
+// the commands: go test ./cmd -run Golden -update
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// runCommand runs projector with args and returns what it wrote to stdout
+// and stderr. The flags of the command are reset afterwards, since cobra
+// keeps them between runs.
+func runCommand(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs(args)
+	c, err := rootCmd.ExecuteC()
+
+	rootCmd.SetOut(nil)
+	rootCmd.SetErr(nil)
+	rootCmd.SetArgs(nil)
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	c.Flags().VisitAll(reset)
+	rootCmd.PersistentFlags().VisitAll(reset)
+	return stdout.String(), stderr.String(), err
+}
+
+// assertGolden compares got with testdata/<name>.golden
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s:\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+func TestCommands_Golden(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("golden files use Unix paths")
+	}
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.CheckInvalidPaths = false
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"list", []string{"list", "--no-color"}},
+		{"list_tsv", []string{"list", "--output", "tsv", "--fields", "name,kind,path,tags"}},
+		{"list_favorites_path", []string{"list", "--favorites", "--path", "--no-color"}},
+		{"info", []string{"info", "favorite1", "--no-color"}},
+		{"completion_data", []string{"completion-data", "FAV"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setApp(newAppContext(cfg, store))()
+			stdout, stderr, err := runCommand(t, tt.args...)
+			if err != nil {
+				t.Fatalf("%v failed: %v\n%s", tt.args, err, stderr)
+			}
+			assertGolden(t, tt.name, stdout)
+		})
+	}
+}
+
+func TestRunCommand_Stderr(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	for _, command := range []string{"path", "open"} {
+		stdout, stderr, err := runCommand(t, command, "repo", "--no-color")
+		if exitCode(err) != exitAmbiguous {
+			t.Fatalf("%s: expected an ambiguous match, got %v", command, err)
+		}
+		if stdout != "" {
+			t.Errorf("%s: expected nothing on stdout, got %q", command, stdout)
+		}
+		if !strings.Contains(stderr, "Multiple projects match 'repo'") || !strings.Contains(stderr, "git-repo1") {
+			t.Errorf("%s: expected the matches on stderr, got %q", command, stderr)
+		}
+	}
+}
+
+func TestRunRepair(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	broken := `[{"name": "favorite1", "rootPath": "/path/to/favorite1", "enabled": true}, {"name": "fav`
+	if err := os.WriteFile(store.GetProjectsPath(), []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.LoadProjects()
+	if hint := repairHint(err); hint == "" {
+		t.Errorf("expected a repair hint for %v", err)
+	}
+
+	noInput = true
+	defer func() { noInput = false }()
+	if _, _, err := runCommand(t, "repair"); !errors.Is(err, errNoInput) {
+		t.Errorf("repair without a source and --no-input: got %v, want errNoInput", err)
+	}
+
+	stdout, _, err := runCommand(t, "repair", "--dry-run", "--no-color")
+	if err != nil {
+		t.Fatalf("repair --dry-run failed: %v", err)
+	}
+	if !strings.Contains(stdout, "salvageable from the file:     1 projects") {
+		t.Errorf("expected the salvageable count, got %q", stdout)
+	}
+
+	if _, stderr, err := runCommand(t, "repair", "--salvage"); err != nil {
+		t.Fatalf("repair --salvage failed: %v\n%s", err, stderr)
+	}
+	list, err := store.LoadProjects()
+	if err != nil || len(list.Projects) != 1 || list.Projects[0].Name != "favorite1" {
+		t.Fatalf("after repair got %v, %v; want favorite1", list, err)
+	}
+	if data, err := os.ReadFile(store.GetProjectsPath() + ".corrupt"); err != nil || string(data) != broken {
+		t.Errorf("expected the broken file kept as .corrupt, got %q, %v", data, err)
+	}
+}
+
+func TestWriteCompletionData(t *testing.T) {
+	var buf bytes.Buffer
+	writeCompletionData(&buf, []*models.Project{
+		{Name: "api", RootPath: "/srv/api", Kind: models.KindGit},
+		{Name: "odd\tname", RootPath: "/srv/odd\nname", Kind: models.KindFavorite},
+	})
+	want := "api\t/srv/api\tgit\nodd name\t/srv/odd name\tfavorites\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteRaycastScripts(t *testing.T) {
+	dir := t.TempDir()
+	cli := &launcherScript{Projector: shellQuote("/opt/it's/projector")}
+	written, err := writeRaycastScripts(dir, cli)
+	if err != nil || len(written) != 2 {
+		t.Fatalf("writeRaycastScripts = %v, %v", written, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "projector-open.sh"))
+	for _, want := range []string{"@raycast.schemaVersion 1", "@raycast.argument1", `'/opt/it'\''s/projector' --no-input --no-color open "$1"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("open script lacks %q:\n%s", want, data)
+		}
+	}
+
+	daemon := &launcherScript{Projector: "projector", Curl: "curl --unix-socket /s", URL: "http://projector"}
+	if _, err := writeRaycastScripts(dir, daemon); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "projector-rescan.sh"))
+	if !strings.Contains(string(data), "curl --unix-socket /s -X POST http://projector/rescan") {
+		t.Errorf("rescan script does not use the daemon:\n%s", data)
+	}
+}
+
+func TestDaemonURL(t *testing.T) {
+	tests := map[string]string{
+		":7878":          "http://127.0.0.1:7878",
+		"127.0.0.1:7878": "http://127.0.0.1:7878",
+		"localhost:7878": "http://localhost:7878",
+		"[::1]:7878":     "http://[::1]:7878",
+	}
+	for addr, want := range tests {
+		if got := daemonURL(addr); got != want {
+			t.Errorf("daemonURL(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestWriteAlfredWorkflow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Projector.alfredworkflow")
+	if err := writeAlfredWorkflow(path, "p<", &launcherScript{Projector: "'/bin/projector'"}); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if len(zr.File) != 1 || zr.File[0].Name != "info.plist" {
+		t.Fatalf("expected only info.plist in the workflow, got %v", zr.File)
+	}
+	f, err := zr.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plist, _ := io.ReadAll(f)
+	f.Close()
+	for _, want := range []string{"<string>p&lt;</string>", "&#39;/bin/projector&#39; completion-data --output alfred &#34;$1&#34;", "--no-input --no-color open"} {
+		if !strings.Contains(string(plist), want) {
+			t.Errorf("info.plist lacks %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestProjectWebURL(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, ".git", "config"), []byte("[remote \"origin\"]\n\turl = git@git.example.com:team/api.git\n"), 0644)
+
+	cfg := config.DefaultConfig()
+	cfg.BrowseHosts = map[string]string{"git.example.com": "gitlab"}
+	project := &models.Project{Name: "api", RootPath: dir, DefaultBranch: "main", RemoteURL: "https://github.com/old/api.git"}
+
+	url, err := projectWebURL(cfg, project, "", "cmd/main.go")
+	if err != nil || url != "https://git.example.com/team/api/-/tree/main/cmd/main.go" {
+		t.Errorf("projectWebURL = %s, %v; want the file on the default branch of the working copy's remote", url, err)
+	}
+
+	project.RootPath = filepath.Join(dir, "gone")
+	if url, err = projectWebURL(cfg, project, "", ""); err != nil || url != "https://github.com/old/api" {
+		t.Errorf("projectWebURL = %s, %v; want the recorded remote", url, err)
+	}
+
+	project.RemoteURL = "/srv/git/api.git"
+	if _, err := projectWebURL(cfg, project, "", ""); err == nil {
+		t.Error("expected an error for a local remote")
+	}
+}
+
+func TestReadPickerSelection(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	defer setApp(newAppContext(cfg, store))()
+
+	api := &models.Project{Name: "api", RootPath: filepath.Join(tmpDir, "api")}
+	web := &models.Project{Name: "web", RootPath: filepath.Join(tmpDir, "web")}
+	os.MkdirAll(api.RootPath, 0755)
+	os.MkdirAll(web.RootPath, 0755)
+	os.WriteFile(filepath.Join(api.RootPath, "README.md"), []byte("# api\nThe public API\n"), 0644)
+	projects := []*models.Project{api, web}
+
+	pick := func(inputs ...string) (string, []*models.Project, error) {
+		var out bytes.Buffer
+		read := func() (string, error) {
+			if len(inputs) == 0 {
+				return "", io.EOF
+			}
+			input := inputs[0]
+			inputs = inputs[1:]
+			return input, nil
+		}
+		selected, err := readPickerSelection(&out, cfg, "Enter project number", read, func(input string) ([]*models.Project, error) {
+			index, err := strconv.Atoi(input)
+			if err != nil || index < 1 || index > len(projects) {
+				return nil, fmt.Errorf("invalid selection: %s", input)
+			}
+			return projects[index-1 : index], nil
+		})
+		return out.String(), selected, err
+	}
+
+	out, selected, err := pick("2")
+	if err != nil || len(selected) != 1 || selected[0] != web {
+		t.Errorf("expected web to be selected, got %v, %v", selected, err)
+	}
+	if strings.Contains(out, "│") {
+		t.Errorf("expected no preview before it is toggled, got %q", out)
+	}
+
+	// With the preview on, the README is shown and declining asks again
+	out, selected, err = pick("p", "1", "n", "2", "")
+	if err != nil || len(selected) != 1 || selected[0] != web {
+		t.Errorf("expected web to be selected after declining api, got %v, %v", selected, err)
+	}
+	for _, want := range []string{"'p' to preview READMEs", "README preview on", "api: README.md", "│ The public API", "web: no README"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the picker output, got:\n%s", want, out)
+		}
+	}
+
+	if _, _, err := pick("p", "p", "q"); !errors.Is(err, errCancelled) {
+		t.Errorf("expected q to cancel, got %v", err)
+	}
+
+	cfg.PreviewLines = 0
+	if out, selected, _ := pick("p"); selected != nil || !strings.Contains(out, "('?' for actions, or 'q' to quit)") {
+		t.Errorf("expected no preview key with previewLines 0, got %v, %q", selected, out)
+	}
+}
+
+func TestRunCleanup(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	web := filepath.Join(tmpDir, "web")
+	os.MkdirAll(filepath.Join(web, "node_modules", "a"), 0755)
+	os.MkdirAll(filepath.Join(web, "dist"), 0755)
+	os.WriteFile(filepath.Join(web, "node_modules", "a", "index.js"), make([]byte, 2048), 0644)
+	os.WriteFile(filepath.Join(web, "dist", "app.js"), make([]byte, 10), 0644)
+	if err := store.SaveProjects(&models.ProjectList{Projects: []*models.Project{{Name: "web", RootPath: web, Enabled: true}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := runCommand(t, "cleanup", "--no-color")
+	if err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	for _, want := range []string{"[1]  web  " + paths.Collapse(filepath.Join(web, "node_modules")), "2.0 KiB", "Total: 2.0 KiB in 2 folders"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in the report, got:\n%s", want, stdout)
+		}
+	}
+
+	if _, _, err := runCommand(t, "cleanup", "--yes"); err == nil {
+		t.Error("expected --yes without --delete to fail")
+	}
+	if _, _, err := runCommand(t, "cleanup", "--dir", "../x"); err == nil {
+		t.Error("expected an invalid folder name to fail")
+	}
+
+	noInput = true
+	defer func() { noInput = false }()
+	if _, _, err := runCommand(t, "cleanup", "--delete"); !errors.Is(err, errNoInput) {
+		t.Errorf("cleanup --delete with --no-input: got %v, want errNoInput", err)
+	}
+
+	if _, _, err := runCommand(t, "cleanup", "web", "--dir", "node_modules", "--delete", "--yes"); err != nil {
+		t.Fatalf("cleanup --delete --yes failed: %v", err)
+	}
+	if paths.Exists(filepath.Join(web, "node_modules")) {
+		t.Error("expected node_modules to be deleted")
+	}
+	if !paths.Exists(filepath.Join(web, "dist")) {
+		t.Error("expected dist to be kept when only node_modules is looked for")
+	}
+}
+
+func TestRunTagTree(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	projects := []*models.Project{
+		{Name: "api", RootPath: "/work/api", Enabled: true, Tags: []string{"Work/ClientA/Backend", "Go"}},
+		{Name: "web", RootPath: "/work/web", Enabled: true, Tags: []string{"Work/ClientB"}},
+	}
+	if err := store.SaveProjects(&models.ProjectList{Projects: projects}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := runCommand(t, "tag", "tree")
+	if err != nil {
+		t.Fatalf("tag tree failed: %v", err)
+	}
+	want := "Tags in use:\n  - Go\n  - Work\n    - ClientA\n      - Backend\n    - ClientB\n"
+	if stdout != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, stdout)
+	}
+
+	stdout, _, err = runCommand(t, "list", "--tag", "Work", "--output", "tsv", "--fields", "name")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if stdout != "api\nweb\n" {
+		t.Errorf("expected --tag Work to match the tags below it, got %q", stdout)
+	}
+
+	stdout, _, err = runCommand(t, "__complete", "list", "--tag", "Work/")
+	if err != nil {
+		t.Fatalf("completion failed: %v", err)
+	}
+	if !strings.HasPrefix(stdout, "Work/ClientA\nWork/ClientA/\nWork/ClientB\n:") {
+		t.Errorf("expected the tags below Work, got %q", stdout)
+	}
+}
+
+func TestRunAnalyze(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	web := filepath.Join(tmpDir, "web")
+	os.MkdirAll(web, 0755)
+	os.WriteFile(filepath.Join(web, "tsconfig.json"), []byte("{}"), 0644)
+	projects := []*models.Project{
+		{Name: "web", RootPath: web, Enabled: true, Tags: []string{"Work", "lang:js"}},
+		{Name: "gone", RootPath: filepath.Join(tmpDir, "gone"), Enabled: true},
+	}
+	if err := store.SaveProjects(&models.ProjectList{Projects: projects}); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, err := runCommand(t, "analyze", "--no-color")
+	if err != nil {
+		t.Fatalf("analyze failed: %v", err)
+	}
+	if !strings.Contains(stdout, "web  typescript") || !strings.Contains(stderr, "Skipped gone") {
+		t.Errorf("unexpected output:\n%s%s", stdout, stderr)
+	}
+
+	saved, err := store.LoadProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := saved.FindByName("web"); p.Language != "typescript" || strings.Join(p.Tags, ",") != "Work,lang:ts" {
+		t.Errorf("expected web tagged lang:ts, got %s %v", p.Language, p.Tags)
+	}
+
+	stdout, _, err = runCommand(t, "list", "--tag", "lang:ts", "--output", "tsv", "--fields", "name")
+	if err != nil || stdout != "web\n" {
+		t.Errorf("expected list --tag lang:ts to find web, got %q, %v", stdout, err)
+	}
+}
+
+func TestPickerActions(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	defer setApp(newAppContext(cfg, store))()
+
+	fav := &models.Project{Name: "api", RootPath: "/work/api", Enabled: true, Tags: []string{"Work"}}
+	scanned := &models.Project{Name: "web", RootPath: "/work/web", Enabled: true}
+	if err := store.SaveProjects(&models.ProjectList{Projects: []*models.Project{fav}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveCache(&storage.CachedProjects{Git: []*models.Project{scanned}}); err != nil {
+		t.Fatal(err)
+	}
+	projects := []*models.Project{
+		{Name: "api", RootPath: "/work/api", Enabled: true, Tags: []string{"Work"}},
+		{Name: "web", RootPath: "/work/web", Enabled: true},
+	}
+
+	inputs := []string{"?", "p 2", "t2", "Go, Work/Web, Go", "f 1", "d 2", "t 3", "1"}
+	read := func() (string, error) {
+		if len(inputs) == 0 {
+			return "", io.EOF
+		}
+		input := inputs[0]
+		inputs = inputs[1:]
+		return input, nil
+	}
+	var out bytes.Buffer
+	selected, err := readPickerSelection(&out, cfg, "Enter project number", read, func(input string) ([]*models.Project, error) {
+		index, err := strconv.Atoi(input)
+		if err != nil || index < 1 || index > len(projects) {
+			return nil, fmt.Errorf("invalid selection: %s", input)
+		}
+		return projects[index-1 : index], nil
+	})
+	if err != nil || len(selected) != 1 || selected[0].Name != "api" {
+		t.Fatalf("expected api to be selected after the actions, got %v, %v\n%s", selected, err, out.String())
+	}
+	for _, want := range []string{
+		"t N  edit its tags",
+		"/work/web\n",
+		"Tags of 'web': Go, Work/Web (added to favorites)",
+		"Removed 'api' from favorites",
+		"Disabled 'web'",
+		"invalid selection: 3",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the picker output, got:\n%s", want, out.String())
+		}
+	}
+
+	favorites, err := store.LoadProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(favorites.Projects) != 1 || favorites.Projects[0].Name != "web" ||
+		strings.Join(favorites.Projects[0].Tags, ",") != "Go,Work/Web" || favorites.Projects[0].Enabled {
+		t.Errorf("expected only web as a disabled, tagged favorite, got %+v", favorites.Projects)
+	}
+	cache, err := store.LoadCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Git[0].Enabled {
+		t.Error("expected the cached web to be disabled")
+	}
+}
+
+func TestRunOpen_OpenStrategies(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.TerminalCommand = "missing-term-for-test {{path}}"
+	cfg.OpenStrategies = map[string]string{"any": "terminal", "git": "editor:missing-editor-for-test"}
+	defer setApp(newAppContext(cfg, store))()
+
+	notes := filepath.Join(tmpDir, "notes")
+	api := filepath.Join(tmpDir, "api")
+	os.MkdirAll(notes, 0755)
+	os.MkdirAll(api, 0755)
+	projects := []*models.Project{
+		{Name: "notes", RootPath: notes, Enabled: true, FolderKind: models.KindAny},
+		{Name: "api", RootPath: api, Enabled: true, FolderKind: models.KindGit},
+	}
+	if err := store.SaveProjects(&models.ProjectList{Projects: projects}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each kind goes its own way; the commands are missing, so opening fails
+	if _, _, err := runCommand(t, "open", "notes"); err == nil || !strings.Contains(err.Error(), "missing-term-for-test") {
+		t.Errorf("expected notes to open in the terminal, got %v", err)
+	}
+	if _, _, err := runCommand(t, "open", "api"); err == nil || !strings.Contains(err.Error(), "missing-editor-for-test") {
+		t.Errorf("expected api to open in the configured editor, got %v", err)
+	}
+
+	// A flag saying how to open wins over the strategy
+	if _, _, err := runCommand(t, "open", "notes", "--editor", "missing-flag-editor"); err == nil || !strings.Contains(err.Error(), "missing-flag-editor") {
+		t.Errorf("expected --editor to override the strategy, got %v", err)
+	}
+
+	cfg.OpenStrategies["any"] = "shell"
+	if _, _, err := runCommand(t, "open", "notes"); err == nil || !strings.Contains(err.Error(), "openStrategies.any") {
+		t.Errorf("expected an invalid strategy to be reported, got %v", err)
+	}
+}
+
+func TestChangeLabel(t *testing.T) {
+	tmpDir, cleanup := testSetup(t)
+	defer cleanup()
+	store, err := storage.NewStorage(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setApp(newAppContext(config.DefaultConfig(), store))()
+
+	projects := []*models.Project{{Name: "api", RootPath: "/work/api", Enabled: true, Tags: []string{}}}
+	if err := store.SaveProjects(&models.ProjectList{Projects: projects}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := runCommand(t, "tag", "add", "Go", "api"); err != nil {
+		t.Fatalf("tag add failed: %v", err)
+	}
+	entries, err := store.LoadUndo()
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("expected undo entries, got %v, %v", entries, err)
+	}
+	if got := entries[len(entries)-1].Command; got != "tag add Go api" {
+		t.Errorf("expected the command line as the undo label, got %q", got)
+	}
+}
+
+func TestAppContext_StaleCacheHandledOncePerCommand(t *testing.T) {
+	a := newAppContext(config.DefaultConfig(), nil)
+	if a.markStaleCacheHandled() || !a.markStaleCacheHandled() {
+		t.Error("expected the stale cache to be handled once")
+	}
+	if newAppContext(config.DefaultConfig(), nil).markStaleCacheHandled() {
+		t.Error("expected a new command to handle the stale cache again")
+	}
 }
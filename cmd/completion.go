@@ -1,9 +1,16 @@
 package cmd
 
 import (
-	"os"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
 )
 
 // completionCmd represents the completion command
@@ -52,17 +59,108 @@ PowerShell:
 	Run: func(cmd *cobra.Command, args []string) {
 		switch args[0] {
 		case "bash":
-			cmd.Root().GenBashCompletion(os.Stdout)
+			cmd.Root().GenBashCompletion(app.Out())
 		case "zsh":
-			cmd.Root().GenZshCompletion(os.Stdout)
+			cmd.Root().GenZshCompletion(app.Out())
 		case "fish":
-			cmd.Root().GenFishCompletion(os.Stdout, true)
+			cmd.Root().GenFishCompletion(app.Out(), true)
 		case "powershell":
-			cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+			cmd.Root().GenPowerShellCompletionWithDesc(app.Out())
 		}
 	},
 }
 
+var completionDataOutput string
+
+// completionDataCmd prints projects for completion frameworks and
+// launchers. It is hidden from help, but its output is kept stable.
+var completionDataCmd = &cobra.Command{
+	Use:   "completion-data [prefix]",
+	Short: "Print projects for completion scripts and launchers",
+	Long: `Print the enabled projects whose name starts with prefix (ignoring case),
+or all of them, one per line as name, path and kind separated by tabs.
+Tabs and line breaks inside names and paths are replaced by spaces. With
+--output alfred, the projects are printed as the JSON of an Alfred Script
+Filter instead.
+
+This output is stable, for the shell functions of 'projector init' and for
+launcher plugins such as Alfred, Raycast or Albert: columns are only ever
+added at the end of the line.`,
+	Hidden: true,
+	Args:   cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prefix := ""
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+		if completionDataOutput != "tsv" && completionDataOutput != "alfred" {
+			return fmt.Errorf("invalid output format '%s' (supported: tsv, alfred)", completionDataOutput)
+		}
+		projects, err := completionProjects(prefix)
+		if err != nil {
+			return err
+		}
+		if completionDataOutput == "alfred" {
+			return writeAlfredItems(app.Out(), projects)
+		}
+		writeCompletionData(app.Out(), projects)
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(completionDataCmd)
+
+	completionDataCmd.Flags().StringVarP(&completionDataOutput, "output", "o", "tsv", "output format: tsv or alfred")
+}
+
+// completionProjects returns the enabled projects whose name starts with
+// prefix, ignoring case
+func completionProjects(prefix string) ([]*models.Project, error) {
+	store, err := app.Storage()
+	if err != nil {
+		return nil, err
+	}
+	projects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*models.Project
+	for _, p := range projector.FilterEnabled(projects) {
+		if strings.HasPrefix(strings.ToLower(p.Name), strings.ToLower(prefix)) {
+			matches = append(matches, p)
+		}
+	}
+	return matches, nil
+}
+
+// completionField keeps a value on its own line and column
+var completionField = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// writeCompletionData writes one name<TAB>path<TAB>kind line per project
+func writeCompletionData(w io.Writer, projects []*models.Project) {
+	for _, p := range projects {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", completionField.Replace(p.Name), completionField.Replace(p.RootPath), p.Kind)
+	}
+}
+
+// alfredItem is a result of an Alfred Script Filter
+type alfredItem struct {
+	UID          string `json:"uid"`
+	Title        string `json:"title"`
+	Subtitle     string `json:"subtitle"`
+	Arg          string `json:"arg"`
+	Autocomplete string `json:"autocomplete"`
+}
+
+// writeAlfredItems writes projects as Alfred Script Filter results, whose
+// argument is the project name
+func writeAlfredItems(w io.Writer, projects []*models.Project) error {
+	items := make([]alfredItem, len(projects))
+	for i, p := range projects {
+		items[i] = alfredItem{UID: p.RootPath, Title: p.Name, Subtitle: paths.Collapse(p.RootPath), Arg: p.Name, Autocomplete: p.Name}
+	}
+	return json.NewEncoder(w).Encode(map[string][]alfredItem{"items": items})
 }
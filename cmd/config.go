@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/output"
+)
+
+// configCmd groups commands that show where projector keeps its files
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show where the config and data files are",
+	Long: `Show where projector keeps its files for the active profile, after
+PROJECTOR_* environment overrides and projectsLocation are applied.
+
+Examples:
+  # Show the config, projects and cache files
+  projector config path
+
+  # Print only the projects file, for scripts
+  projector config path projects
+
+  # Show the config, data, backup and archive directories
+  projector config dirs`,
+	Args: cobra.NoArgs,
+}
+
+// configPathCmd represents the config path command
+var configPathCmd = &cobra.Command{
+	Use:       "path [config|projects|cache]",
+	Short:     "Print the config, projects and cache file paths",
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"config", "projects", "cache"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := configFiles()
+		if err != nil {
+			return err
+		}
+		return writeLocations(app.Out(), app.Formatter(), files, args)
+	},
+}
+
+// configDirsCmd represents the config dirs command
+var configDirsCmd = &cobra.Command{
+	Use:       "dirs [config|data|backups|archive]",
+	Short:     "Print the config, data, backup and archive directories",
+	Args:      cobra.MatchAll(cobra.MaximumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"config", "data", "backups", "archive"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dirs, err := configDirs()
+		if err != nil {
+			return err
+		}
+		return writeLocations(app.Out(), app.Formatter(), dirs, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configDirsCmd)
+}
+
+// location is a named file or directory
+type location struct {
+	name string
+	path string
+}
+
+// configFiles returns the config file and the projects and cache files
+// of the storage in use
+func configFiles() ([]location, error) {
+	cfg, err := app.Config()
+	if err != nil {
+		return nil, err
+	}
+	store, err := app.Storage()
+	if err != nil {
+		return nil, err
+	}
+	return []location{
+		{"config", cfg.GetConfigPath()},
+		{"projects", store.GetProjectsPath()},
+		{"cache", store.GetCachePath()},
+	}, nil
+}
+
+// configDirs returns the config directory and the directories projector
+// keeps data in
+func configDirs() ([]location, error) {
+	cfg, err := app.Config()
+	if err != nil {
+		return nil, err
+	}
+	store, err := app.Storage()
+	if err != nil {
+		return nil, err
+	}
+	return []location{
+		{"config", cfg.GetConfigDir()},
+		{"data", store.GetBasePath()},
+		{"backups", store.GetBackupDir()},
+		{"archive", cfg.GetArchiveDirectory()},
+	}, nil
+}
+
+// writeLocations prints the locations as labelled lines, or only the
+// path of the one named in args
+func writeLocations(out io.Writer, formatter *output.Formatter, locations []location, args []string) error {
+	if len(args) > 0 {
+		for _, l := range locations {
+			if strings.EqualFold(l.name, args[0]) {
+				fmt.Fprintln(out, l.path)
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown location '%s'", args[0])
+	}
+	for _, l := range locations {
+		fmt.Fprintf(out, "%-9s %s\n", l.name+":", formatter.FormatPath(l.path))
+	}
+	return nil
+}
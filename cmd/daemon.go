@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// daemonSocketName is the default unix socket file in the storage directory
+const daemonSocketName = "daemon.sock"
+
+var (
+	daemonSocket  string
+	daemonAddr    string
+	daemonNoWatch bool
+)
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Serve projects over a local API and keep the cache warm",
+	Long: `Run in the foreground, keeping favorites and the cache in memory,
+watching base folders for changes, and serving a small HTTP API over a
+unix socket (default ~/.projector/daemon.sock) or a TCP address.
+
+Endpoints:
+  GET  /projects   all enabled projects as JSON
+                   (query: tag, language, all=true to include disabled)
+  POST /open       open a project: {"name": "api", "editor": "", "newWindow": false}
+                   (editor must be a built-in editor name; empty uses the configured one)
+  POST /rescan     rescan all configured base folders
+
+POST requests must be sent with Content-Type: application/json. On a TCP
+address, requests must name a loopback host (localhost, 127.0.0.1 or ::1)
+so web pages cannot reach the API.
+
+Examples:
+  # Start the daemon
+  projector daemon
+
+  # Query it from another process
+  curl --unix-socket ~/.projector/daemon.sock http://projector/projects
+  curl --unix-socket ~/.projector/daemon.sock -H 'Content-Type: application/json' \
+    -d '{"name": "api"}' http://projector/open
+
+  # Listen on localhost instead of a unix socket
+  projector daemon --addr 127.0.0.1:7878`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVar(&daemonSocket, "socket", "", "unix socket path (default: daemon.sock in the projects location)")
+	daemonCmd.Flags().StringVar(&daemonAddr, "addr", "", "listen on a TCP address instead of a unix socket (e.g. 127.0.0.1:7878)")
+	daemonCmd.Flags().BoolVar(&daemonNoWatch, "no-watch", false, "serve projects without watching base folders")
+	daemonCmd.MarkFlagsMutuallyExclusive("socket", "addr")
+}
+
+// daemonState holds the projects served by the daemon
+type daemonState struct {
+	store *storage.Storage
+	jobs  []projector.ScanJob
+
+	// open launches a project; replaced in tests
+	open func(path, editor string, newWindow bool) error
+	// editors are the editors /open may be asked for
+	editors *editor.Registry
+	// editor and newWindow are the configured defaults for /open
+	editor    string
+	newWindow bool
+	// loopbackOnly rejects requests for hosts other than loopback ones; set
+	// when serving TCP, where web pages in a browser can reach the daemon
+	loopbackOnly bool
+
+	mu               sync.RWMutex
+	cache            *storage.CachedProjects
+	favorites        *models.ProjectList
+	favoritesModTime time.Time
+}
+
+// daemonProject is a project with its kind, which projects.json does not store
+type daemonProject struct {
+	*models.Project
+	Kind models.ProjectKind `json:"kind"`
+}
+
+// daemonOpenRequest is the body of POST /open
+type daemonOpenRequest struct {
+	Name      string `json:"name"`
+	Editor    string `json:"editor"`
+	NewWindow bool   `json:"newWindow"`
+}
+
+// refreshFavorites reloads favorites if projects.json changed on disk
+func (d *daemonState) refreshFavorites() error {
+	var modTime time.Time
+	if info, err := os.Stat(d.store.GetProjectsPath()); err == nil {
+		modTime = info.ModTime()
+	}
+
+	d.mu.RLock()
+	fresh := d.favorites != nil && modTime.Equal(d.favoritesModTime)
+	d.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	favorites, err := d.store.LoadProjects()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.favorites = favorites
+	d.favoritesModTime = modTime
+	d.mu.Unlock()
+	return nil
+}
+
+// projects returns favorites followed by cached projects
+func (d *daemonState) projects() ([]*models.Project, error) {
+	if err := d.refreshFavorites(); err != nil {
+		return nil, fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	all := append([]*models.Project{}, d.favorites.Projects...)
+	all = append(all, d.cache.Git...)
+	all = append(all, d.cache.SVN...)
+	all = append(all, d.cache.Mercurial...)
+	all = append(all, d.cache.VSCode...)
+	all = append(all, d.cache.Any...)
+	return all, nil
+}
+
+// rescan runs every scan job and saves the cache, returning project counts by type
+func (d *daemonState) rescan(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, job := range d.jobs {
+		projects, _, err := job.Run(ctx, nil)
+		if err := scanAborted(err); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", job.Label, err)
+		}
+
+		d.mu.Lock()
+		projector.SetCacheBucket(d.cache, job.Type, projects)
+		d.mu.Unlock()
+		counts[string(job.Type)] = len(projects)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if err := d.store.SaveCache(d.cache); err != nil {
+		return nil, fmt.Errorf("failed to save cache: %w", err)
+	}
+	return counts, nil
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError writes {"error": msg} with the given status
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// newDaemonHandler returns the HTTP API served by the daemon
+func newDaemonHandler(d *daemonState) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+
+		projects, err := d.projects()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		query := r.URL.Query()
+		if all, _ := strconv.ParseBool(query.Get("all")); !all {
+			projects = projector.FilterEnabled(projects)
+		}
+		projects = projector.FilterByTag(projects, query.Get("tag"))
+		projects = projector.FilterByLanguage(projects, query.Get("language"))
+
+		result := make([]daemonProject, len(projects))
+		for i, p := range projects {
+			result[i] = daemonProject{Project: p, Kind: p.Kind}
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+
+	mux.HandleFunc("/open", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+
+		var req daemonOpenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return
+		}
+
+		projects, err := d.projects()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		project, _, err := projector.FindProjectByName(projector.FilterEnabled(projects), req.Name, matchOptions())
+		if errors.Is(err, models.ErrAmbiguousName) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		} else if err != nil {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		// Only built-in and custom editors may be asked for, since any
+		// other name is run as a command
+		editorName := req.Editor
+		if editorName == "" {
+			editorName = d.editor
+		} else if _, ok := d.editors.Lookup(editorName); !ok && editorName != editor.Auto {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unknown editor '%s' (run 'projector editors' to see which are supported)", editorName))
+			return
+		}
+		if err := d.open(project.RootPath, editorName, req.NewWindow || d.newWindow); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"opened": project.RootPath})
+	})
+
+	mux.HandleFunc("/rescan", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+
+		counts, err := d.rescan(r.Context())
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, counts)
+	})
+
+	return logRequests(guardRequests(d, mux))
+}
+
+// guardRequests rejects requests a web page could have sent: POST requests
+// without a JSON body, which browsers cannot send cross-origin without
+// asking first, and, when serving TCP, requests for a non-loopback host,
+// as sent after DNS rebinding
+func guardRequests(d *daemonState, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.loopbackOnly && !isLoopbackHost(r.Host) {
+			writeJSONError(w, http.StatusForbidden, fmt.Sprintf("host '%s' is not a loopback address", r.Host))
+			return
+		}
+		if r.Method == http.MethodPost {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				writeJSONError(w, http.StatusUnsupportedMediaType, "use Content-Type: application/json")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether the Host header value host, with or
+// without a port, names the local machine
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs each request served by next with its status and duration
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		slog.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(started))
+	})
+}
+
+// listenDaemon opens the TCP address or unix socket the daemon serves on,
+// removing a stale socket left behind by a daemon that did not shut down
+func listenDaemon(store *storage.Storage) (net.Listener, string, error) {
+	if daemonAddr != "" {
+		l, err := net.Listen("tcp", daemonAddr)
+		return l, daemonAddr, err
+	}
+
+	socket := daemonSocket
+	if socket == "" {
+		socket = filepath.Join(store.GetBasePath(), daemonSocketName)
+	}
+
+	if _, err := os.Stat(socket); err == nil {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			conn.Close()
+			return nil, "", fmt.Errorf("a daemon is already listening on %s", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, "", fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", socket)
+	return l, socket, err
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	// Load config
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.CacheProjectsBetweenSessions {
+		return fmt.Errorf("the daemon requires cacheProjectsBetweenSessions to be enabled")
+	}
+
+	// The daemon serves every configured project type
+	jobs, err := projector.ScanJobs(cfg, projector.ScanOptions{})
+	if err != nil {
+		return err
+	}
+
+	// Start from the saved cache so queries are answered immediately
+	cache, err := store.LoadCache()
+	if err != nil {
+		cache = &storage.CachedProjects{}
+	}
+
+	d := &daemonState{
+		store:        store,
+		jobs:         jobs,
+		open:         openInEditor,
+		editors:      app.Editors(),
+		editor:       cfg.Editor,
+		newWindow:    cfg.OpenInNewWindow,
+		loopbackOnly: daemonAddr != "",
+		cache:        cache,
+	}
+	if err := d.refreshFavorites(); err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	listener, address, err := listenDaemon(store)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	formatter := app.Formatter()
+	server := &http.Server{Handler: newDaemonHandler(d)}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Listening on %s", address)))
+
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if !daemonNoWatch && len(jobs) > 0 {
+		if _, err := d.rescan(ctx); err != nil {
+			formatter.FprintWarning(app.Out(), fmt.Sprintf("Initial scan failed: %v", err))
+		}
+		go func() {
+			serveErr <- watchScanJobs(ctx, store, formatter, jobs, d.cache, &d.mu)
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		<-ctx.Done()
+		return nil
+	}
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var (
+	dedupeYes    bool
+	dedupeDryRun bool
+)
+
+// dedupeCmd represents the dedupe command
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find and merge duplicate projects",
+	Long: `Find favorites and cached projects that are duplicates and merge or
+remove them interactively.
+
+Two kinds of duplicates are reported:
+  - entries resolving to the same path (after expanding ~, cleaning the
+    path and resolving symlinks); the others are merged into the entry
+    you keep, combining tags and filling in missing details
+  - entries with the same name pointing to different paths; the entries
+    you do not keep are removed
+
+Examples:
+  # Review duplicates one group at a time
+  projector dedupe
+
+  # Only report duplicates
+  projector dedupe --dry-run
+
+  # Merge same-path duplicates without prompting
+  projector dedupe --yes`,
+	Args: cobra.NoArgs,
+	RunE: runDedupe,
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+
+	dedupeCmd.Flags().BoolVarP(&dedupeYes, "yes", "y", false, "merge same-path duplicates into the favorite (or first) entry without prompting; same-name duplicates are kept")
+	dedupeCmd.Flags().BoolVar(&dedupeDryRun, "dry-run", false, "report duplicates without changing anything")
+	dedupeCmd.MarkFlagsMutuallyExclusive("yes", "dry-run")
+}
+
+// duplicateGroup is a set of projects that duplicate each other
+type duplicateGroup struct {
+	// samePath is true when the projects resolve to the same path,
+	// false when they share a name but point to different paths
+	samePath bool
+	// key is the canonical path or the shared name
+	key      string
+	projects []*models.Project
+}
+
+// findDuplicates groups projects resolving to the same canonical path, then
+// projects sharing a name (case-insensitive) across different paths.
+// Favorites come first within each group, as they do in projects.
+func findDuplicates(projects []*models.Project) []duplicateGroup {
+	var groups []duplicateGroup
+
+	var pathOrder []string
+	byPath := make(map[string][]*models.Project)
+	canonical := make(map[string]string)
+	for _, p := range projects {
+		path := paths.Canonical(p.RootPath)
+		key := paths.Key(path)
+		if _, ok := byPath[key]; !ok {
+			pathOrder = append(pathOrder, key)
+			canonical[key] = path
+		}
+		byPath[key] = append(byPath[key], p)
+	}
+	for _, key := range pathOrder {
+		if len(byPath[key]) > 1 {
+			groups = append(groups, duplicateGroup{samePath: true, key: canonical[key], projects: byPath[key]})
+		}
+	}
+
+	// One entry per path takes part in name groups; same-path entries
+	// are already covered above
+	var nameOrder []string
+	byName := make(map[string][]*models.Project)
+	for _, key := range pathOrder {
+		p := byPath[key][0]
+		name := strings.ToLower(p.Name)
+		if _, ok := byName[name]; !ok {
+			nameOrder = append(nameOrder, name)
+		}
+		byName[name] = append(byName[name], p)
+	}
+	for _, name := range nameOrder {
+		if len(byName[name]) > 1 {
+			groups = append(groups, duplicateGroup{key: byName[name][0].Name, projects: byName[name]})
+		}
+	}
+
+	return groups
+}
+
+// mergeDuplicate copies tags and missing details from dup into keep
+func mergeDuplicate(keep, dup *models.Project) {
+	for _, tag := range dup.Tags {
+		keep.AddTag(tag)
+	}
+	if keep.Description == "" {
+		keep.Description = dup.Description
+	}
+	if keep.Notes == "" {
+		keep.Notes = dup.Notes
+	}
+	if keep.Language == "" {
+		keep.Language = dup.Language
+	}
+}
+
+// withoutProjects returns list without the projects in removed
+func withoutProjects(list []*models.Project, removed map[*models.Project]bool) []*models.Project {
+	result := make([]*models.Project, 0, len(list))
+	for _, p := range list {
+		if !removed[p] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// removeProjects drops removed projects from favorites and every cache bucket
+func removeProjects(favorites *models.ProjectList, cache *storage.CachedProjects, removed map[*models.Project]bool) {
+	favorites.Projects = withoutProjects(favorites.Projects, removed)
+	cache.Git = withoutProjects(cache.Git, removed)
+	cache.SVN = withoutProjects(cache.SVN, removed)
+	cache.Mercurial = withoutProjects(cache.Mercurial, removed)
+	cache.VSCode = withoutProjects(cache.VSCode, removed)
+	cache.Any = withoutProjects(cache.Any, removed)
+}
+
+// resolveDuplicateGroup applies the chosen entry to a group: same-path
+// duplicates are merged into it, same-name duplicates are removed.
+// keep is a 0-based index into group.projects.
+func resolveDuplicateGroup(group duplicateGroup, keep int, removed map[*models.Project]bool) {
+	kept := group.projects[keep]
+	for i, p := range group.projects {
+		if i == keep {
+			continue
+		}
+		if group.samePath {
+			mergeDuplicate(kept, p)
+		}
+		removed[p] = true
+	}
+}
+
+func runDedupe(cmd *cobra.Command, args []string) error {
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	favorites, err := store.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+	cache, err := store.LoadCache()
+	if err != nil {
+		return fmt.Errorf("failed to load cache: %w", err)
+	}
+	// Entries are chosen with the storage unlocked, so favorites changed
+	// by another command meanwhile are not overwritten
+	loaded, err := json.Marshal(favorites.Projects)
+	if err != nil {
+		return err
+	}
+
+	all := append([]*models.Project{}, favorites.Projects...)
+	all = append(all, cache.Git...)
+	all = append(all, cache.SVN...)
+	all = append(all, cache.Mercurial...)
+	all = append(all, cache.VSCode...)
+	all = append(all, cache.Any...)
+
+	formatter := app.Formatter()
+
+	groups := findDuplicates(all)
+	if len(groups) == 0 {
+		printStatus(formatter.FormatSuccess("No duplicate projects found"))
+		return nil
+	}
+
+	removed := make(map[*models.Project]bool)
+	for _, group := range groups {
+		// An earlier group may already have removed some of these entries
+		group.projects = withoutProjects(group.projects, removed)
+		if len(group.projects) < 2 {
+			continue
+		}
+
+		printDuplicateGroup(formatter, group)
+
+		switch {
+		case dedupeDryRun:
+		case dedupeYes:
+			if group.samePath {
+				resolveDuplicateGroup(group, 0, removed)
+			}
+		default:
+			keep, err := promptDuplicateGroup(group)
+			if err != nil {
+				return err
+			}
+			if keep >= 0 {
+				resolveDuplicateGroup(group, keep, removed)
+			}
+		}
+		fmt.Fprintln(app.Out())
+	}
+
+	if len(removed) == 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Found %d duplicate groups, nothing changed", len(groups))))
+		return nil
+	}
+
+	removeProjects(favorites, cache, removed)
+	err = store.Update(func(current *models.ProjectList) error {
+		if data, err := json.Marshal(current.Projects); err != nil || !bytes.Equal(data, loaded) {
+			return fmt.Errorf("favorites changed while deduplicating; run 'projector dedupe' again")
+		}
+		current.Projects = favorites.Projects
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := store.SaveCache(cache); err != nil {
+		return fmt.Errorf("failed to save cache: %w", err)
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Removed %d duplicate entries", len(removed))))
+	return nil
+}
+
+// printDuplicateGroup lists the entries of a group with 1-based numbers
+func printDuplicateGroup(formatter *output.Formatter, group duplicateGroup) {
+	if group.samePath {
+		formatter.FprintWarning(app.Out(), fmt.Sprintf("Same path: %s", group.key))
+	} else {
+		formatter.FprintWarning(app.Out(), fmt.Sprintf("Same name: %s", group.key))
+	}
+	for i, p := range group.projects {
+		fmt.Fprintf(app.Out(), "  [%d] %s (%s) - %s\n", i+1, p.Name, p.Kind, p.RootPath)
+	}
+}
+
+// promptDuplicateGroup asks which entry of a group to keep. It returns the
+// 0-based index, or -1 to leave the group unchanged.
+func promptDuplicateGroup(group duplicateGroup) (int, error) {
+	if noInput {
+		return -1, fmt.Errorf("%w; use --yes or --dry-run", errNoInput)
+	}
+	if group.samePath {
+		fmt.Fprintf(app.Out(), "Keep which entry? The others are merged into it [1-%d, Enter=1, s=skip, q=quit]: ", len(group.projects))
+	} else {
+		fmt.Fprintf(app.Out(), "Keep which entry? The others are removed [1-%d, Enter=keep all, q=quit]: ", len(group.projects))
+	}
+
+	input, err := ReadUserInput()
+	if err != nil {
+		return -1, err
+	}
+
+	switch strings.ToLower(input) {
+	case "q":
+		return -1, errCancelled
+	case "s":
+		return -1, nil
+	case "":
+		if group.samePath {
+			return 0, nil
+		}
+		return -1, nil
+	}
+
+	index, err := strconv.Atoi(input)
+	if err != nil || index < 1 || index > len(group.projects) {
+		return -1, fmt.Errorf("invalid selection: %s", input)
+	}
+	return index - 1, nil
+}
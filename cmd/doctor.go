@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var doctorFix bool
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check configuration and stored projects for problems",
+	Long: `Run health checks and report problems:
+
+  - the config file parses and matches the expected options and types
+  - configured base folders exist and are readable
+  - the editor and terminal command are on your PATH
+  - the projects location is writable
+  - favorites and cached projects point to existing, accessible folders
+
+Use --fix to prune cached projects whose folders no longer exist.
+Missing favorites are only reported; remove them with 'projector remove'.
+
+Examples:
+  projector doctor
+  projector doctor --fix`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "prune cached projects whose folders no longer exist")
+}
+
+// doctorLevel is the severity of a doctor finding
+type doctorLevel int
+
+const (
+	doctorOK doctorLevel = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorFinding is the result of a single check
+type doctorFinding struct {
+	level doctorLevel
+	msg   string
+}
+
+// editorBinary returns the executable an editor setting runs
+func editorBinary(editors *editor.Registry, name string) string {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return ""
+	}
+	if e, ok := editors.Lookup(fields[0]); ok {
+		return e.Command()
+	}
+	return fields[0]
+}
+
+// checkConfigFile validates the config file, if there is one
+func checkConfigFile(path string) []doctorFinding {
+	if !paths.Exists(path) {
+		return []doctorFinding{{doctorOK, fmt.Sprintf("No config file at %s, using defaults", path)}}
+	}
+
+	problems, err := config.ValidateFile(path)
+	if err != nil {
+		return []doctorFinding{{doctorFail, fmt.Sprintf("Config %s: %v", path, err)}}
+	}
+	if len(problems) == 0 {
+		return []doctorFinding{{doctorOK, fmt.Sprintf("Config %s is valid", path)}}
+	}
+
+	findings := make([]doctorFinding, len(problems))
+	for i, problem := range problems {
+		findings[i] = doctorFinding{doctorFail, "Config " + problem}
+	}
+	return findings
+}
+
+// checkBaseFolders reports base folders that are missing, not directories or unreadable
+func checkBaseFolders(cfg *config.Config) []doctorFinding {
+	groups := []struct {
+		option  string
+		folders []string
+	}{
+		{"gitBaseFolders", cfg.GitBaseFolders},
+		{"svnBaseFolders", cfg.SVNBaseFolders},
+		{"hgBaseFolders", cfg.MercurialBaseFolders},
+		{"vscodeBaseFolders", cfg.VSCodeBaseFolders},
+		{"anyBaseFolders", cfg.AnyBaseFolders},
+	}
+
+	var findings []doctorFinding
+	count := 0
+	for _, g := range groups {
+		for _, folder := range g.folders {
+			count++
+			path := paths.Expand(folder)
+			info, err := os.Stat(path)
+			switch {
+			case os.IsNotExist(err):
+				findings = append(findings, doctorFinding{doctorFail, fmt.Sprintf("%s: %s does not exist", g.option, folder)})
+			case err != nil:
+				findings = append(findings, doctorFinding{doctorFail, fmt.Sprintf("%s: %s: %v", g.option, folder, err)})
+			case !info.IsDir():
+				findings = append(findings, doctorFinding{doctorFail, fmt.Sprintf("%s: %s is not a directory", g.option, folder)})
+			default:
+				if _, err := os.ReadDir(path); err != nil {
+					findings = append(findings, doctorFinding{doctorFail, fmt.Sprintf("%s: %s is not readable: %v", g.option, folder, err)})
+				}
+			}
+		}
+	}
+
+	if len(findings) == 0 {
+		if count == 0 {
+			return []doctorFinding{{doctorOK, "No base folders configured"}}
+		}
+		return []doctorFinding{{doctorOK, fmt.Sprintf("All %d base folders are accessible", count)}}
+	}
+	return findings
+}
+
+// checkExecutables reports editor and terminal commands missing from PATH
+func checkExecutables(cfg *config.Config, lookPath func(string) (string, error)) []doctorFinding {
+	var findings []doctorFinding
+
+	// The joined error has one line per invalid custom editor
+	editors, err := projector.EditorRegistry(cfg)
+	if err != nil {
+		for _, msg := range strings.Split(err.Error(), "\n") {
+			findings = append(findings, doctorFinding{doctorFail, fmt.Sprintf("Invalid custom editor: %s", msg)})
+		}
+	}
+
+	if cfg.Editor == editor.Auto {
+		if e, err := editors.Resolve(cfg.Editor); err != nil {
+			findings = append(findings, doctorFinding{doctorFail, "Editor 'auto' found no known editor on PATH"})
+		} else {
+			findings = append(findings, doctorFinding{doctorOK, fmt.Sprintf("Editor 'auto' opens %s", e.Name())})
+		}
+	} else if bin := editorBinary(editors, cfg.Editor); bin == "" {
+		findings = append(findings, doctorFinding{doctorFail, "No editor configured"})
+	} else if path, err := lookPath(bin); err != nil {
+		msg := fmt.Sprintf("Editor '%s' not found on PATH", bin)
+		if installed := editors.Detected(); len(installed) > 0 {
+			msg += fmt.Sprintf(" (installed: %s)", strings.Join(installed, ", "))
+		}
+		findings = append(findings, doctorFinding{doctorFail, msg})
+	} else {
+		findings = append(findings, doctorFinding{doctorOK, fmt.Sprintf("Editor '%s' found at %s", bin, path)})
+	}
+
+	if fields := strings.Fields(cfg.TerminalCommand); len(fields) > 0 {
+		if _, err := lookPath(fields[0]); err != nil {
+			findings = append(findings, doctorFinding{doctorFail, fmt.Sprintf("Terminal command '%s' not found on PATH", fields[0])})
+		} else {
+			findings = append(findings, doctorFinding{doctorOK, fmt.Sprintf("Terminal command '%s' found", fields[0])})
+		}
+	}
+
+	return findings
+}
+
+// checkStorageWritable reports a projects location that cannot be written to
+func checkStorageWritable(dir string) doctorFinding {
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return doctorFinding{doctorFail, fmt.Sprintf("Projects location %s is not writable: %v", dir, err)}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return doctorFinding{doctorOK, fmt.Sprintf("Projects location %s is writable", dir)}
+}
+
+// missingProjects returns the projects whose folders no longer exist and
+// findings for those that cannot be accessed
+func missingProjects(projects []*models.Project) ([]*models.Project, []doctorFinding) {
+	var missing []*models.Project
+	var findings []doctorFinding
+	for _, p := range projects {
+		_, err := os.Stat(p.RootPath)
+		switch {
+		case os.IsNotExist(err):
+			missing = append(missing, p)
+		case os.IsPermission(err):
+			findings = append(findings, doctorFinding{doctorFail, fmt.Sprintf("Permission denied: %s (%s)", p.Name, p.RootPath)})
+		}
+	}
+	return missing, findings
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	// Load config
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	problems := 0
+	report := func(section string, findings ...doctorFinding) {
+		fmt.Fprintln(app.Out(), section)
+		for _, f := range findings {
+			switch f.level {
+			case doctorOK:
+				fmt.Fprintln(app.Out(), "  "+formatter.FormatSuccess(f.msg))
+			case doctorWarn:
+				fmt.Fprintln(app.Out(), "  "+formatter.FormatWarning(f.msg))
+			default:
+				fmt.Fprintln(app.Out(), "  "+formatter.FormatError(f.msg))
+				problems++
+			}
+		}
+		fmt.Fprintln(app.Out())
+	}
+
+	report("Configuration", checkConfigFile(cfg.GetConfigPath())...)
+	report("Base folders", checkBaseFolders(cfg)...)
+	report("Executables", checkExecutables(cfg, exec.LookPath)...)
+
+	storageFindings := []doctorFinding{checkStorageWritable(store.GetBasePath())}
+
+	favorites, err := store.LoadProjects()
+	if err != nil {
+		finding := fmt.Sprintf("Favorites: %v", err)
+		if errors.Is(err, storage.ErrProjectsCorrupt) {
+			finding += " (run 'projector repair')"
+		}
+		storageFindings = append(storageFindings, doctorFinding{doctorFail, finding})
+		favorites = models.NewProjectList(models.KindFavorite)
+	}
+	missing, findings := missingProjects(favorites.Projects)
+	storageFindings = append(storageFindings, findings...)
+	for _, p := range missing {
+		storageFindings = append(storageFindings, doctorFinding{doctorWarn, fmt.Sprintf("Favorite '%s' points to a missing folder: %s", p.Name, p.RootPath)})
+	}
+
+	cache, err := store.LoadCache()
+	if errors.Is(err, storage.ErrCacheCorrupt) {
+		storageFindings = append(storageFindings, doctorFinding{doctorFail, fmt.Sprintf("Cache: %v (run 'projector repair' or 'projector clear-cache')", err)})
+		cache = &storage.CachedProjects{}
+	} else if err != nil {
+		storageFindings = append(storageFindings, doctorFinding{doctorFail, fmt.Sprintf("Cache: %v", err)})
+		cache = &storage.CachedProjects{}
+	}
+	var cached []*models.Project
+	for _, bucket := range [][]*models.Project{cache.Git, cache.SVN, cache.Mercurial, cache.VSCode, cache.Any} {
+		cached = append(cached, bucket...)
+	}
+	staleCached, findings := missingProjects(cached)
+	storageFindings = append(storageFindings, findings...)
+
+	switch {
+	case len(staleCached) == 0:
+		storageFindings = append(storageFindings, doctorFinding{doctorOK, fmt.Sprintf("All %d cached projects exist", len(cached))})
+	case doctorFix:
+		removed := len(cache.PruneMissing())
+		if err := store.SaveCache(cache); err != nil {
+			storageFindings = append(storageFindings, doctorFinding{doctorFail, fmt.Sprintf("Failed to save cache: %v", err)})
+		} else {
+			storageFindings = append(storageFindings, doctorFinding{doctorOK, fmt.Sprintf("Pruned %d stale cached projects", removed)})
+		}
+	default:
+		for _, p := range staleCached {
+			storageFindings = append(storageFindings, doctorFinding{doctorFail, fmt.Sprintf("Stale cache entry '%s': %s", p.Name, p.RootPath)})
+		}
+	}
+
+	report(fmt.Sprintf("Storage (%s)", filepath.Clean(store.GetBasePath())), storageFindings...)
+
+	if problems > 0 {
+		if !doctorFix && len(staleCached) > 0 {
+			printStatus(formatter.FormatInfo("Run 'projector doctor --fix' to prune stale cache entries"))
+		}
+		return fmt.Errorf("found %d problems", problems)
+	}
+
+	printStatus(formatter.FormatSuccess("No problems found"))
+	return nil
+}
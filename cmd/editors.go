@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/editor"
+)
+
+var editorsAvailable bool
+
+// editorsCmd represents the editors command
+var editorsCmd = &cobra.Command{
+	Use:   "editors",
+	Short: "List the editors projector can open projects in",
+	Long: `List the built-in and custom editors and whether each one is installed,
+that is, found on your PATH. Any listed name (or alias, in parentheses)
+can be used as the editor setting or with 'open --editor'.
+
+Set the editor to "auto" to open projects in the best installed editor,
+picked each time a project is opened.
+
+Examples:
+  # Show all editors
+  projector editors
+
+  # Show only the installed ones
+  projector editors --available`,
+	Args: cobra.NoArgs,
+	RunE: runEditors,
+}
+
+func init() {
+	rootCmd.AddCommand(editorsCmd)
+
+	editorsCmd.Flags().BoolVarP(&editorsAvailable, "available", "a", false, "only show installed editors")
+}
+
+// editorLabel returns an editor's name followed by its aliases in editors
+func editorLabel(editors *editor.Registry, e editor.Editor) string {
+	if aliases := editors.Aliases(e); len(aliases) > 0 {
+		return fmt.Sprintf("%s (%s)", e.Name(), strings.Join(aliases, ", "))
+	}
+	return e.Name()
+}
+
+func runEditors(cmd *cobra.Command, args []string) error {
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	editors := app.Editors()
+
+	var missing []string
+	fmt.Fprintln(app.Out(), "Installed")
+	for _, e := range editors.All() {
+		path, err := exec.LookPath(e.Command())
+		if err != nil {
+			missing = append(missing, editorLabel(editors, e))
+			continue
+		}
+		fmt.Fprintf(app.Out(), "  %s  %s\n", formatter.FormatSuccess(editorLabel(editors, e)), path)
+	}
+	if len(missing) == len(editors.All()) {
+		fmt.Fprintln(app.Out(), "  "+formatter.FormatWarning("No known editor found on PATH"))
+	}
+
+	if !editorsAvailable && len(missing) > 0 {
+		fmt.Fprintln(app.Out())
+		fmt.Fprintln(app.Out(), "Not found")
+		for _, label := range missing {
+			fmt.Fprintln(app.Out(), "  "+label)
+		}
+	}
+
+	fmt.Fprintln(app.Out())
+	setting := cfg.Editor
+	if setting == editor.Auto {
+		if e, err := editors.Resolve(setting); err == nil {
+			setting = fmt.Sprintf("%s (opens %s)", editor.Auto, e.Name())
+		}
+	}
+	printStatus(formatter.FormatInfo(fmt.Sprintf("Configured editor: %s", setting)))
+	return nil
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var (
+	toggleTag       string
+	toggleFavorites bool
+	toggleGit       bool
+	toggleSVN       bool
+	toggleMercurial bool
+	toggleVSCode    bool
+	toggleAny       bool
+)
+
+// disableCmd represents the disable command
+var disableCmd = &cobra.Command{
+	Use:   "disable [name-or-pattern...]",
+	Short: "Disable projects",
+	Long: `Disable projects so they are hidden from list, open and select.
+Use 'projector list --all' to see disabled projects.
+
+Projects are chosen by name (exact or unique partial match), by glob
+patterns such as "old-*", and narrowed by --tag and the type flags.
+Disabled auto-detected projects stay disabled when rescanned.
+
+Examples:
+  # Disable a single project
+  projector disable myproject
+
+  # Disable everything tagged Archive
+  projector disable --tag Archive
+
+  # Disable git repositories whose names start with "tmp-"
+  projector disable --git 'tmp-*'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetEnabled(args, false)
+	},
+}
+
+// enableCmd represents the enable command
+var enableCmd = &cobra.Command{
+	Use:   "enable [name-or-pattern...]",
+	Short: "Enable disabled projects",
+	Long: `Enable projects that were disabled, selecting them the same way as
+'projector disable'.
+
+Examples:
+  # Enable a single project
+  projector enable myproject
+
+  # Enable everything tagged Archive again
+  projector enable --tag Archive`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetEnabled(args, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(disableCmd)
+	rootCmd.AddCommand(enableCmd)
+
+	for _, c := range []*cobra.Command{disableCmd, enableCmd} {
+		c.Flags().StringVarP(&toggleTag, "tag", "t", "", "only projects with this tag")
+		c.Flags().BoolVar(&toggleFavorites, "favorites", false, "only favorites")
+		c.Flags().BoolVar(&toggleGit, "git", false, "only git repositories")
+		c.Flags().BoolVar(&toggleSVN, "svn", false, "only svn repositories")
+		c.Flags().BoolVar(&toggleMercurial, "mercurial", false, "only mercurial repositories")
+		c.Flags().BoolVar(&toggleVSCode, "vscode", false, "only vscode workspaces")
+		c.Flags().BoolVar(&toggleAny, "any", false, "only any-folder projects")
+	}
+}
+
+func runSetEnabled(args []string, enabled bool) error {
+	filter := projector.TypeFilter{
+		Favorites: toggleFavorites,
+		Git:       toggleGit,
+		SVN:       toggleSVN,
+		Mercurial: toggleMercurial,
+		VSCode:    toggleVSCode,
+		Any:       toggleAny,
+	}
+	if len(args) == 0 && toggleTag == "" && filter.ShowAll() {
+		return fmt.Errorf("specify project names or patterns, --tag, or a project type")
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	cache, err := store.LoadCache()
+	if err != nil {
+		cache = &storage.CachedProjects{}
+	}
+
+	var targets, changed []*models.Project
+	cacheChanged := false
+	err = store.Update(func(projects *models.ProjectList) error {
+		var err error
+		if targets, err = selectProjects(toggleCandidates(projects, cache, filter), args, toggleTag); err != nil {
+			return err
+		}
+
+		favorites := make(map[*models.Project]bool, len(projects.Projects))
+		for _, p := range projects.Projects {
+			favorites[p] = true
+		}
+
+		favoritesChanged := false
+		for _, p := range targets {
+			if p.Enabled == enabled {
+				continue
+			}
+			p.Enabled = enabled
+			changed = append(changed, p)
+			if favorites[p] {
+				favoritesChanged = true
+			} else {
+				cacheChanged = true
+			}
+		}
+		if !favoritesChanged {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Disabled"
+	if enabled {
+		verb = "Enabled"
+	}
+
+	formatter := app.Formatter()
+	if len(changed) == 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("No projects to change (%d already %s)", len(targets), strings.ToLower(verb))))
+		return nil
+	}
+
+	if cacheChanged {
+		if err := store.SaveCache(cache); err != nil {
+			return fmt.Errorf("failed to save cache: %w", err)
+		}
+	}
+
+	for _, p := range changed {
+		fmt.Fprintf(app.Out(), "  %s  %s\n", formatter.FormatName(p.Name), formatter.FormatPath(p.RootPath))
+	}
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("%s %d projects", verb, len(changed))))
+
+	return nil
+}
+
+// toggleCandidates returns the favorites and cached projects of the types
+// selected by filter
+func toggleCandidates(projects *models.ProjectList, cache *storage.CachedProjects, filter projector.TypeFilter) []*models.Project {
+	showAll := filter.ShowAll()
+	var candidates []*models.Project
+	if showAll || filter.Favorites {
+		candidates = append(candidates, projects.Projects...)
+	}
+	if showAll || filter.Git {
+		candidates = append(candidates, cache.Git...)
+	}
+	if showAll || filter.SVN {
+		candidates = append(candidates, cache.SVN...)
+	}
+	if showAll || filter.Mercurial {
+		candidates = append(candidates, cache.Mercurial...)
+	}
+	if showAll || filter.VSCode {
+		candidates = append(candidates, cache.VSCode...)
+	}
+	if showAll || filter.Any {
+		candidates = append(candidates, cache.Any...)
+	}
+	return candidates
+}
+
+// selectProjects returns the projects with tag (if given) that match any
+// of patterns, or all of them when there are no patterns. Glob patterns
+// match names case-insensitively, and also the trailing segments of paths
+// with filterOnFullPath; other patterns are resolved with
+// projector.FindProjectByName and must identify a single project.
+func selectProjects(projects []*models.Project, patterns []string, tag string) ([]*models.Project, error) {
+	projects = projector.FilterByTag(projects, tag)
+	if len(patterns) == 0 {
+		return projects, nil
+	}
+
+	opts := matchOptions()
+	seen := make(map[*models.Project]bool)
+	var selected []*models.Project
+	add := func(p *models.Project) {
+		if !seen[p] {
+			seen[p] = true
+			selected = append(selected, p)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			project, _, err := projector.FindProjectByName(projects, pattern, opts)
+			if err != nil {
+				return nil, err
+			}
+			add(project)
+			continue
+		}
+
+		matched := false
+		for _, p := range projects {
+			ok, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(p.Name))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+			}
+			if !ok && opts.FullPath {
+				// Match as many trailing path segments as the pattern has
+				tail := projector.PathTail(p, strings.Count(pattern, "/")+1)
+				ok, _ = path.Match(strings.ToLower(pattern), strings.ToLower(tail))
+			}
+			if ok {
+				add(p)
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no projects match '%s'", pattern)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// Exit codes of the projector command, so scripts can tell why a command
+// failed without parsing its error message
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitNotFound    = 2
+	exitAmbiguous   = 3
+	exitPathMissing = 4
+	exitCancelled   = 5
+)
+
+// errCancelled is returned when the user quits a prompt
+var errCancelled = errors.New("cancelled")
+
+// codedError is an error with a specific exit code
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+
+func (e *codedError) Unwrap() error { return e.err }
+
+// withExitCode makes the command exit with code when it fails with err
+func withExitCode(code int, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// exitCode returns the exit code for the error a command returned. An
+// explicit code set with withExitCode wins over the one for the kind of
+// error (see the errors in pkg/models).
+func exitCode(err error) int {
+	var coded *codedError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errCancelled):
+		return exitCancelled
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, models.ErrProjectNotFound):
+		return exitNotFound
+	case errors.Is(err, models.ErrAmbiguousName):
+		return exitAmbiguous
+	case errors.Is(err, models.ErrPathMissing):
+		return exitPathMissing
+	}
+	return exitFailure
+}
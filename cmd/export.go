@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/export"
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+var (
+	exportFormat       string
+	exportFile         string
+	exportIncludeCache bool
+
+	importFormat  string
+	importReplace bool
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export projects for backup or migration",
+	Long: `Export your favorites (and optionally the cache) in JSON, YAML, TOML or CSV.
+
+Paths under your home directory are written with ~ so the export can be
+imported on another machine. Use 'projector import' to load the file again.
+
+Examples:
+  # Export favorites as JSON to stdout
+  projector export
+
+  # Export favorites and cache as YAML to a file
+  projector export --format yaml --include-cache --file projects.yaml
+
+  # Export as CSV (format inferred from the file extension)
+  projector export --file projects.csv`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import projects from an export file",
+	Long: `Import projects from a file created by 'projector export'.
+
+Favorites are merged into projects.json; projects whose path or name already
+exists are skipped. Use --replace to overwrite your favorites instead.
+If the file contains cached projects, they replace the current cache.
+Use '-' to read from stdin.
+
+Examples:
+  # Import an export file (format inferred from the extension)
+  projector import projects.yaml
+
+  # Replace all favorites with the contents of a backup
+  projector import backup.json --replace
+
+  # Import from stdin
+  cat projects.csv | projector import - --format csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "output format: json, yaml, toml, csv (default: from file extension, or json)")
+	exportCmd.Flags().StringVarP(&exportFile, "file", "o", "", "write to file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportIncludeCache, "include-cache", false, "also export cached auto-detected projects")
+
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "", "input format: json, yaml, toml, csv (default: from file extension, or json)")
+	importCmd.Flags().BoolVar(&importReplace, "replace", false, "replace existing favorites instead of merging")
+}
+
+// resolveExportFormat determines the format from the flag or the file extension
+func resolveExportFormat(flag, file string) (export.Format, error) {
+	if flag != "" {
+		return export.ParseFormat(flag)
+	}
+	if format, ok := export.FormatFromPath(file); ok {
+		return format, nil
+	}
+	return export.FormatJSON, nil
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	format, err := resolveExportFormat(exportFormat, exportFile)
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	projects, err := store.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	doc := &export.Document{Favorites: projects.Projects}
+	if exportIncludeCache {
+		cache, err := store.LoadCache()
+		if err != nil {
+			return fmt.Errorf("failed to load cache: %w", err)
+		}
+		doc.Cache = cache
+	}
+
+	if exportFile == "" {
+		return export.Encode(app.Out(), doc, format)
+	}
+
+	f, err := os.Create(exportFile)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := export.Encode(f, doc, format); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Exported %d projects to %s", len(doc.Favorites), exportFile)))
+
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	file := args[0]
+
+	format, err := resolveExportFormat(importFormat, file)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	doc, err := export.Decode(r, format)
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	var added, skipped int
+	if importReplace {
+		projects := models.NewProjectList(models.KindFavorite)
+		added, skipped = mergeImportedProjects(projects, doc.Favorites)
+		if err := store.SaveProjects(projects); err != nil {
+			return fmt.Errorf("failed to save projects: %w", err)
+		}
+	} else {
+		err = store.Update(func(projects *models.ProjectList) error {
+			added, skipped = mergeImportedProjects(projects, doc.Favorites)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Imported %d projects", added)))
+	if skipped > 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Skipped %d projects that already exist", skipped)))
+	}
+
+	if doc.Cache != nil {
+		if err := store.SaveCache(doc.Cache); err != nil {
+			return fmt.Errorf("failed to save cache: %w", err)
+		}
+		printStatus(formatter.FormatSuccess("Cache imported"))
+	}
+
+	return nil
+}
+
+// mergeImportedProjects adds imported projects to the list, skipping any
+// whose path or name is already present. It returns the added and skipped counts.
+func mergeImportedProjects(projects *models.ProjectList, imported []*models.Project) (int, int) {
+	added, skipped := 0, 0
+	for _, p := range imported {
+		if projects.FindByPath(p.RootPath) != nil || projects.FindByName(p.Name) != nil {
+			skipped++
+			continue
+		}
+		projects.Add(p)
+		added++
+	}
+	return added, skipped
+}
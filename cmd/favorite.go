@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var favoritePick bool
+
+// favoriteCmd represents the favorite command
+var favoriteCmd = &cobra.Command{
+	Use:   "favorite [project-name...]",
+	Short: "Promote auto-detected projects to favorites",
+	Long: `Copy auto-detected projects from the cache into your favorites,
+keeping their path, tags and other details.
+
+Projects whose path or name is already a favorite are skipped.
+
+Examples:
+  # Promote a scanned repository
+  projector favorite api
+
+  # Choose several cached projects from a list
+  projector favorite --pick`,
+	Aliases: []string{"fav"},
+	RunE:    runFavorite,
+}
+
+// unfavoriteCmd represents the unfavorite command
+var unfavoriteCmd = &cobra.Command{
+	Use:   "unfavorite <project-name>",
+	Short: "Remove a project from favorites",
+	Long: `Remove a project from your favorites. If the project is also
+auto-detected, it stays available from the cache.`,
+	Aliases: []string{"unfav"},
+	Args:    cobra.ExactArgs(1),
+	RunE:    runUnfavorite,
+}
+
+func init() {
+	rootCmd.AddCommand(favoriteCmd)
+	rootCmd.AddCommand(unfavoriteCmd)
+
+	favoriteCmd.Flags().BoolVarP(&favoritePick, "pick", "p", false, "choose cached projects to promote from a list")
+}
+
+// loadCachedProjects returns all auto-detected projects from the cache
+func loadCachedProjects(store *storage.Storage) ([]*models.Project, error) {
+	return loadProjects(store, projector.TypeFilter{
+		Git:       true,
+		SVN:       true,
+		Mercurial: true,
+		VSCode:    true,
+		Any:       true,
+	})
+}
+
+// withoutFavorites returns the cached projects whose path is not already a favorite
+func withoutFavorites(cached []*models.Project, favorites *models.ProjectList) []*models.Project {
+	result := make([]*models.Project, 0, len(cached))
+	for _, p := range cached {
+		if favorites.FindByPath(p.RootPath) == nil {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// favoriteCopies returns copies of cached projects to add as favorites,
+// leaving the cache entries untouched
+func favoriteCopies(cached []*models.Project) []*models.Project {
+	copies := make([]*models.Project, len(cached))
+	for i, p := range cached {
+		cp := *p
+		cp.Tags = append([]string{}, p.Tags...)
+		copies[i] = &cp
+	}
+	return copies
+}
+
+func runFavorite(cmd *cobra.Command, args []string) error {
+	if favoritePick && len(args) > 0 {
+		return fmt.Errorf("cannot combine project names with --pick")
+	}
+	if !favoritePick && len(args) == 0 {
+		return fmt.Errorf("specify project names or use --pick")
+	}
+
+	// Load config
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	projects, err := store.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	cached, err := loadCachedProjects(store)
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+
+	var selected []*models.Project
+	if favoritePick {
+		candidates := withoutFavorites(projector.FilterEnabled(cached), projects)
+		if len(candidates) == 0 {
+			printStatus(formatter.FormatInfo("No cached projects to promote (run 'projector scan' first)"))
+			return nil
+		}
+		selected, err = pickCachedProjects(cfg, formatter, candidates)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, name := range args {
+			project, _, err := projector.FindProjectByName(cached, name, matchOptions())
+			if err != nil {
+				return err
+			}
+			selected = append(selected, project)
+		}
+	}
+
+	var added, skipped int
+	err = store.Update(func(projects *models.ProjectList) error {
+		if added, skipped = mergeImportedProjects(projects, favoriteCopies(selected)); added == 0 {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if added > 0 {
+		printStatus(formatter.FormatSuccess(fmt.Sprintf("Added %d projects to favorites", added)))
+	}
+	if skipped > 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Skipped %d projects that are already favorites", skipped)))
+	}
+
+	return nil
+}
+
+// pickCachedProjects lists candidates and reads a multi-selection such as "1 3 5-7"
+func pickCachedProjects(cfg *config.Config, formatter *output.Formatter, candidates []*models.Project) ([]*models.Project, error) {
+	if noInput {
+		return nil, errNoInput
+	}
+	sortProjects(candidates, cfg)
+
+	fmt.Fprintln(app.Out(), "Select projects to add to favorites:")
+	fmt.Fprintln(app.Out())
+
+	opts := output.ListOptions{
+		ShowIndex:       true,
+		Grouped:         cfg.GroupList,
+		ShowDescription: true,
+	}
+	listOutput, indexedProjects := formatter.FormatProjectList(candidates, opts)
+	fmt.Fprintln(app.Out(), listOutput)
+	fmt.Fprintln(app.Out())
+
+	fmt.Fprint(app.Out(), "Enter project numbers, e.g. 1 3 5-7 (or 'q' to quit): ")
+	input, err := ReadUserInput()
+	if err != nil {
+		return nil, err
+	}
+
+	if input == "q" || input == "Q" {
+		return nil, errCancelled
+	}
+
+	indexes, err := parseSelection(input, len(indexedProjects))
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]*models.Project, len(indexes))
+	for i, index := range indexes {
+		selected[i] = indexedProjects[index]
+	}
+	return selected, nil
+}
+
+func runUnfavorite(cmd *cobra.Command, args []string) error {
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	var project *models.Project
+	err = store.Update(func(projects *models.ProjectList) error {
+		var err error
+		if project, _, err = projector.FindProjectByName(projects.Projects, args[0], matchOptions()); err != nil {
+			return err
+		}
+		projects.Remove(project.Name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Removed '%s' from favorites", project.Name)))
+
+	cached, err := loadCachedProjects(store)
+	if err == nil && findProjectByPath(cached, project.RootPath) != nil {
+		printStatus(formatter.FormatInfo("The project is still available from the cache"))
+	}
+
+	return nil
+}
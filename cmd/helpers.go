@@ -3,136 +3,365 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
 	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/logging"
 	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
 	"github.com/ideaspaper/projector/pkg/storage"
 )
 
-// TypeFilter specifies which project types to include when loading projects.
-type TypeFilter struct {
-	Favorites bool
-	Git       bool
-	SVN       bool
-	Mercurial bool
-	VSCode    bool
-	Any       bool
+// errNoInput is returned instead of prompting when --no-input is set
+var errNoInput = errors.New("input is needed but --no-input is set")
+
+// stdinReader is shared by prompts so input buffered for one prompt, e.g.
+// piped answers, is not lost to the next
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// ReadUserInput reads a line of input from stdin, handling edge cases properly.
+// With --no-input it fails with errNoInput instead.
+func ReadUserInput() (string, error) {
+	if noInput {
+		return "", errNoInput
+	}
+	input, err := stdinReader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
 }
 
-// ShowAll returns true if no specific type filter is set.
-func (f TypeFilter) ShowAll() bool {
-	return !f.Favorites && !f.Git && !f.SVN && !f.Mercurial && !f.VSCode && !f.Any
+// filterByQueries keeps the projects matching every --filter expression
+// (see projector.Query)
+func filterByQueries(projects []*models.Project, exprs []string) ([]*models.Project, error) {
+	queries := make([]*projector.Query, len(exprs))
+	for i, expr := range exprs {
+		q, err := projector.ParseQuery(expr)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = q
+	}
+	return projector.FilterByQuery(projects, queries), nil
+}
+
+// printStatus prints a success or info message, unless --quiet is set
+func printStatus(msg string) {
+	if !quiet {
+		fmt.Fprintln(app.Out(), msg)
+	}
 }
 
-// LoadFilteredProjects loads projects from storage based on the given type filter.
-// It returns all matching projects from both favorites and cache.
-func LoadFilteredProjects(store *storage.Storage, filter TypeFilter) ([]*models.Project, error) {
-	var allProjects []*models.Project
-	showAll := filter.ShowAll()
+// formatterOptions returns the theme and tag colors in cfg, and the icons
+// when showIcons is on, as formatter options. An invalid theme is reported
+// on w and the default theme is kept; invalid tag colors or icons are
+// reported and not used.
+func formatterOptions(cfg *config.Config, w io.Writer) output.FormatterOptions {
+	var opts output.FormatterOptions
+	overrides := output.Theme{
+		Name:    cfg.Theme.Name,
+		Path:    cfg.Theme.Path,
+		Tag:     cfg.Theme.Tag,
+		Kind:    cfg.Theme.Kind,
+		Success: cfg.Theme.Success,
+		Error:   cfg.Theme.Error,
+		Warning: cfg.Theme.Warning,
+		Info:    cfg.Theme.Info,
+	}
+	theme, err := output.ResolveTheme(cfg.Theme.Base, overrides)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: invalid theme, using defaults: %v\n", err)
+	}
+	opts.Theme = theme
+
+	tagColors, err := output.ParseTagColors(cfg.TagColors)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: invalid tagColors, not using them: %v\n", err)
+	}
+	opts.TagColors = tagColors
 
-	// Load favorites
-	if showAll || filter.Favorites {
-		projects, err := store.LoadProjects()
+	if cfg.ShowIcons {
+		icons := output.Icons{
+			models.KindFavorite:  cfg.Icons.Favorites,
+			models.KindGit:       cfg.Icons.Git,
+			models.KindSVN:       cfg.Icons.SVN,
+			models.KindMercurial: cfg.Icons.Mercurial,
+			models.KindVSCode:    cfg.Icons.VSCode,
+			models.KindAny:       cfg.Icons.Any,
+		}
+		resolved, err := output.ResolveIcons(cfg.Icons.Set, icons)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load projects: %w", err)
+			fmt.Fprintf(w, "Warning: invalid icons, not showing them: %v\n", err)
 		}
-		allProjects = append(allProjects, projects.Projects...)
+		opts.Icons = resolved
 	}
+	return opts
+}
 
-	// Load cached auto-detected projects
-	if showAll || filter.Git || filter.SVN || filter.Mercurial || filter.VSCode || filter.Any {
-		cache, err := store.LoadCache()
-		if err == nil {
-			if showAll || filter.Git {
-				allProjects = append(allProjects, cache.Git...)
-			}
-			if showAll || filter.SVN {
-				allProjects = append(allProjects, cache.SVN...)
+// matchOptions returns how project names given on the command line are
+// matched, from the filterOnFullPath option
+func matchOptions() projector.MatchOptions {
+	cfg, err := app.Config()
+	if err != nil {
+		return projector.MatchOptions{}
+	}
+	return projector.MatchOptions{FullPath: cfg.FilterOnFullPath}
+}
+
+// pickerUsage fills in the "Recently Used" section and open count badges
+// of an interactive picker, as set by recentlyUsedCount and showOpenCounts.
+// Projects must already be sorted; the picker is shown without them when
+// the open counts cannot be loaded.
+func pickerUsage(cfg *config.Config, projects []*models.Project, opts *output.ListOptions) {
+	if cfg.RecentlyUsedCount <= 0 && !cfg.ShowOpenCounts {
+		return
+	}
+	store, err := app.Storage()
+	if err != nil {
+		return
+	}
+	counts, err := store.LoadOpenCounts()
+	if err != nil {
+		return
+	}
+	opts.Pinned = projector.MostUsed(projects, counts, cfg.RecentlyUsedCount)
+	if cfg.ShowOpenCounts {
+		opts.OpenCounts = counts
+	}
+}
+
+// readPickerSelection prompts on w until a selection is entered at an
+// interactive picker and returns the projects parse makes of it. Entering
+// "p" toggles a preview of the first previewLines lines of the README of
+// the selected projects, which is shown before the selection is accepted,
+// to tell similarly named projects apart; "q" cancels. An action key
+// before a number, as in "t 3", acts on that project and asks again (see
+// pickerActions), and "?" lists the actions.
+func readPickerSelection(w io.Writer, cfg *config.Config, prompt string, read func() (string, error), parse func(string) ([]*models.Project, error)) ([]*models.Project, error) {
+	hint := "'?' for actions, or 'q' to quit"
+	if cfg.PreviewLines > 0 {
+		hint = "'p' to preview READMEs, '?' for actions, 'q' to quit"
+	}
+
+	preview := false
+	for {
+		fmt.Fprintf(w, "%s (%s): ", prompt, hint)
+		input, err := read()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case strings.EqualFold(input, "q"):
+			return nil, errCancelled
+		case strings.EqualFold(input, "p") && cfg.PreviewLines > 0:
+			preview = !preview
+			if preview {
+				fmt.Fprintln(w, "README preview on")
+			} else {
+				fmt.Fprintln(w, "README preview off")
 			}
-			if showAll || filter.Mercurial {
-				allProjects = append(allProjects, cache.Mercurial...)
+			continue
+		case input == "?":
+			writePickerHelp(w)
+			continue
+		}
+
+		formatter := app.Formatter()
+		if action, number, ok := parsePickerAction(input); ok {
+			selected, err := parse(number)
+			if err == nil && len(selected) != 1 {
+				err = fmt.Errorf("actions apply to one project")
 			}
-			if showAll || filter.VSCode {
-				allProjects = append(allProjects, cache.VSCode...)
+			if err == nil {
+				err = action.run(w, selected[0], read)
 			}
-			if showAll || filter.Any {
-				allProjects = append(allProjects, cache.Any...)
+			if err != nil {
+				formatter.FprintWarning(w, err.Error())
 			}
+			continue
 		}
-	}
 
-	return allProjects, nil
-}
+		selected, err := parse(input)
+		if err != nil || !preview {
+			return selected, err
+		}
 
-// FilterEnabled returns only enabled projects from the given list.
-func FilterEnabled(projects []*models.Project) []*models.Project {
-	filtered := make([]*models.Project, 0, len(projects))
-	for _, p := range projects {
-		if p.Enabled {
-			filtered = append(filtered, p)
+		width, _ := terminalSize()
+		fmt.Fprintln(w)
+		for _, p := range selected {
+			path, lines, err := projector.ReadmePreview(p.RootPath, cfg.PreviewLines)
+			if err != nil {
+				formatter.FprintWarning(w, fmt.Sprintf("Could not read the README of '%s': %v", p.Name, err))
+				continue
+			}
+			fmt.Fprintln(w, formatter.FormatReadmePreview(p, path, lines, width))
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, "Select? [Y/n]: ")
+		answer, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" || strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
+			return selected, nil
 		}
 	}
-	return filtered
 }
 
-// FilterByTag returns only projects that have the specified tag.
-func FilterByTag(projects []*models.Project, tag string) []*models.Project {
-	if tag == "" {
+// withoutCurrentProject leaves the project containing the current
+// directory out of a list or picker when removeCurrentProjectFromList is
+// set
+func withoutCurrentProject(cfg *config.Config, projects []*models.Project) []*models.Project {
+	if !cfg.RemoveCurrentFromList {
 		return projects
 	}
-	filtered := make([]*models.Project, 0)
-	for _, p := range projects {
-		if p.HasTag(tag) {
-			filtered = append(filtered, p)
-		}
+	return projector.ExcludeCurrent(projects)
+}
+
+// findProject finds a project by name like projector.FindProjectByName,
+// leaving the current project out of the matches of an ambiguous name as
+// withoutCurrentProject does. The name resolves when a single match
+// remains. The name "-" finds the previously opened project.
+func findProject(cfg *config.Config, store *storage.Storage, projects []*models.Project, name string) (*models.Project, []*models.Project, error) {
+	if name == projector.PreviousProjectArg {
+		project, err := projector.FindPreviousProject(store, projects)
+		return project, nil, err
+	}
+	project, matches, err := projector.FindProjectByName(projects, name, matchOptions())
+	if len(matches) < 2 {
+		return project, matches, err
+	}
+	switch rest := withoutCurrentProject(cfg, matches); len(rest) {
+	case 1:
+		return rest[0], nil, nil
+	case 0:
+		return project, matches, err
+	default:
+		return project, rest, err
 	}
-	return filtered
 }
 
-// FindProjectByName finds a project by name with exact or partial matching.
-// Returns the matched project and any error.
-// If multiple partial matches are found, returns an error with the matches.
-func FindProjectByName(projects []*models.Project, name string) (*models.Project, []*models.Project, error) {
-	// First try exact match (case-insensitive)
-	for _, p := range projects {
-		if strings.EqualFold(p.Name, name) {
-			return p, nil, nil
+// setChangeLabel records the command line, without the program name, as
+// the description of the changes it makes in the undo log. Global flags
+// such as --no-color are left out.
+func setChangeLabel(cmd *cobra.Command, args []string) {
+	parts := append(strings.Fields(cmd.CommandPath())[1:], args...)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if cmd.InheritedFlags().Lookup(f.Name) != nil {
+			return
+		}
+		if f.Value.Type() == "bool" {
+			parts = append(parts, "--"+f.Name)
+		} else {
+			parts = append(parts, fmt.Sprintf("--%s=%s", f.Name, f.Value))
 		}
+	})
+	app.setChangeLabel(strings.Join(parts, " "))
+}
+
+// setupLogging configures the slog default logger from the log flags,
+// falling back to the config. --verbose is shorthand for debug level.
+func setupLogging() error {
+	cfg, err := app.Config()
+	if err != nil {
+		cfg = config.DefaultConfig()
 	}
 
-	// Try partial match
-	var matches []*models.Project
-	for _, p := range projects {
-		if strings.Contains(strings.ToLower(p.Name), strings.ToLower(name)) {
-			matches = append(matches, p)
-		}
+	opts := logging.Options{
+		Level:  cfg.LogLevel,
+		Format: cfg.LogFormat,
+		File:   cfg.GetLogFile(),
+	}
+	if logLevel != "" {
+		opts.Level = logLevel
+	} else if verbose {
+		opts.Level = "debug"
+	}
+	if logFormat != "" {
+		opts.Format = logFormat
+	}
+	if logFile != "" {
+		opts.File = paths.Expand(logFile)
 	}
 
-	if len(matches) == 1 {
-		return matches[0], nil, nil
-	} else if len(matches) > 1 {
-		return nil, matches, fmt.Errorf("multiple projects match '%s'", name)
+	closer, err := logging.Setup(opts)
+	if err != nil {
+		return err
 	}
+	logCloser = closer
+	return nil
+}
 
-	return nil, nil, fmt.Errorf("project '%s' not found", name)
+// typeFlags are the --type and --exclude-type flags of the commands that
+// pick from projects. The older --favorites, --git, --svn, --mercurial,
+// --vscode and --any flags are kept as shorthands for --type.
+type typeFlags struct {
+	types   []string
+	exclude []string
+	aliases map[string]bool
 }
 
-// ReadUserInput reads a line of input from stdin, handling edge cases properly.
-func ReadUserInput() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
+// register adds the type flags to cmd
+func (t *typeFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&t.types, "type", nil, "show only these project types: "+strings.Join(projector.TypeNames, ", ")+" (can be repeated)")
+	cmd.Flags().StringSliceVar(&t.exclude, "exclude-type", nil, "leave out these project types (can be repeated)")
+	t.aliases = make(map[string]bool)
+	for _, name := range projector.TypeNames {
+		flag := cmd.Flags().VarPF(&typeAlias{flags: t, name: name}, name, "", "same as --type "+name)
+		flag.NoOptDefVal = "true"
+	}
+}
+
+// filter returns the type filter selected by the flags
+func (t *typeFlags) filter() (projector.TypeFilter, error) {
+	names := append([]string{}, t.types...)
+	for _, name := range projector.TypeNames {
+		if t.aliases[name] {
+			names = append(names, name)
+		}
+	}
+	include, err := projector.ParseTypes(names)
 	if err != nil {
-		return "", err
+		return projector.TypeFilter{}, fmt.Errorf("--type: %w", err)
 	}
-	return strings.TrimSpace(input), nil
+	exclude, err := projector.ParseTypes(t.exclude)
+	if err != nil {
+		return projector.TypeFilter{}, fmt.Errorf("--exclude-type: %w", err)
+	}
+	return include.Exclude(exclude)
+}
+
+// typeAlias is a boolean flag such as --git that selects its type like
+// --type does
+type typeAlias struct {
+	flags *typeFlags
+	name  string
 }
 
-// logVerbose prints a message if verbose mode is enabled.
-func logVerbose(cfg *config.Config, format string, args ...interface{}) {
-	if verbose {
-		fmt.Printf("[DEBUG] "+format+"\n", args...)
+func (a *typeAlias) String() string {
+	return strconv.FormatBool(a.flags != nil && a.flags.aliases[a.name])
+}
+
+func (a *typeAlias) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
 	}
+	a.flags.aliases[a.name] = v
+	return nil
+}
+
+func (a *typeAlias) Type() string {
+	return "bool"
 }
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+// infoCmd represents the info command
+var infoCmd = &cobra.Command{
+	Use:   "info [project-name]",
+	Short: "Show details about a project",
+	Long: `Show all stored details about a project.
+
+Without a name, or with ".", shows the project containing the current
+directory.
+
+Examples:
+  # Details of the project you are in
+  projector info
+
+  # Details of a project by name
+  projector info myproject`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runInfo,
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	name := projector.CurrentProjectArg
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	allProjects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+
+	project, _, err := projector.FindProjectByName(allProjects, name, matchOptions())
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	fmt.Fprintln(app.Out(), formatter.FormatProjectDetails(project))
+
+	if project.Offline {
+		formatter.FprintWarning(app.Out(), fmt.Sprintf("Project is offline: %s is not mounted", project.Volume))
+	} else if _, err := os.Stat(project.RootPath); os.IsNotExist(err) {
+		formatter.FprintWarning(app.Out(), "Project path does not exist")
+	}
+
+	return nil
+}
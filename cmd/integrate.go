@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+var (
+	integrateDir     string
+	integrateDaemon  bool
+	integrateSocket  string
+	integrateAddr    string
+	integrateKeyword string
+)
+
+// integrateCmd represents the integrate command group
+var integrateCmd = &cobra.Command{
+	Use:   "integrate",
+	Short: "Generate launcher integrations (Raycast, Alfred)",
+	Long: `Generate the files that add a project switcher to a macOS launcher.
+
+The generated scripts run this projector binary by its full path, with the
+current --profile, so they work without the PATH of your shell. With
+--daemon they open projects through a running 'projector daemon' instead,
+which avoids starting projector for every launch.
+
+Examples:
+  # Raycast script commands, then add the folder in Raycast
+  projector integrate raycast
+
+  # An Alfred workflow, then open the file to install it
+  projector integrate alfred --keyword p
+
+  # Open projects through the daemon
+  projector integrate raycast --daemon`,
+}
+
+var integrateRaycastCmd = &cobra.Command{
+	Use:   "raycast",
+	Short: "Write Raycast script commands to open and rescan projects",
+	Long: `Write Raycast script commands to open a project by name and to rescan
+projects, by default into integrations/raycast in the projects location.
+Add the folder in Raycast under Extensions > Script Commands > Add
+Directories. Run the command again after moving projector.`,
+	Args: cobra.NoArgs,
+	RunE: runIntegrateRaycast,
+}
+
+var integrateAlfredCmd = &cobra.Command{
+	Use:   "alfred",
+	Short: "Write an Alfred workflow listing and opening projects",
+	Long: `Write Projector.alfredworkflow, by default into integrations in the
+projects location. Opening the file installs the workflow: typing the
+keyword (pj by default) in Alfred lists the projects whose name starts
+with what follows, and Enter opens the selected one. Projects are listed
+with 'projector completion-data'. Run the command again after moving
+projector.`,
+	Args: cobra.NoArgs,
+	RunE: runIntegrateAlfred,
+}
+
+func init() {
+	rootCmd.AddCommand(integrateCmd)
+	integrateCmd.AddCommand(integrateRaycastCmd)
+	integrateCmd.AddCommand(integrateAlfredCmd)
+
+	integrateCmd.PersistentFlags().StringVar(&integrateDir, "dir", "", "directory to write the files to")
+	integrateCmd.PersistentFlags().BoolVar(&integrateDaemon, "daemon", false, "open projects through 'projector daemon' on its default socket")
+	integrateCmd.PersistentFlags().StringVar(&integrateSocket, "socket", "", "open projects through the daemon listening on this unix socket")
+	integrateCmd.PersistentFlags().StringVar(&integrateAddr, "addr", "", "open projects through the daemon listening on this TCP address")
+	integrateCmd.MarkFlagsMutuallyExclusive("socket", "addr")
+	integrateAlfredCmd.Flags().StringVar(&integrateKeyword, "keyword", "pj", "Alfred keyword listing the projects")
+}
+
+// launcherScript holds what the generated scripts run
+type launcherScript struct {
+	// Projector is the shell command running this binary
+	Projector string
+	// Curl is the curl command reaching the daemon, without the URL; empty
+	// when projects are opened by the CLI
+	Curl string
+	// URL is the base URL of the daemon API
+	URL string
+}
+
+// newLauncherScript returns the commands for the generated scripts, from
+// the integrate flags
+func newLauncherScript(basePath string) (*launcherScript, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the projector binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	s := &launcherScript{Projector: shellQuote(exe)}
+	if profile != "" {
+		s.Projector += " --profile " + shellQuote(profile)
+	}
+	switch {
+	case integrateAddr != "":
+		s.Curl = "curl -sS --fail-with-body -H 'Content-Type: application/json'"
+		s.URL = daemonURL(integrateAddr)
+	case integrateSocket != "" || integrateDaemon:
+		socket := integrateSocket
+		if socket == "" {
+			socket = filepath.Join(basePath, daemonSocketName)
+		}
+		s.Curl = "curl -sS --fail-with-body -H 'Content-Type: application/json' --unix-socket " + shellQuote(paths.Expand(socket))
+		s.URL = "http://projector"
+	}
+	return s, nil
+}
+
+// daemonURL returns the URL of the daemon listening on the TCP address
+// addr. An address without a host, such as ":7878", is reached on the
+// IPv4 loopback address.
+func daemonURL(addr string) string {
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+	return "http://" + addr
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// launcherPath puts the usual Homebrew folders on the PATH of launcher
+// scripts, so editors installed there are found
+const launcherPath = `export PATH="/opt/homebrew/bin:/usr/local/bin:$PATH"`
+
+// launcherOpen opens the project named by the first argument
+const launcherOpen = `{{if .Curl}}name=$(printf '%s' "$1" | sed -e 's/\\/\\\\/g' -e 's/"/\\"/g')
+{{.Curl}} -X POST {{.URL}}/open -d "{\"name\": \"$name\"}" >/dev/null && echo "Opened $1"
+{{else}}{{.Projector}} --no-input --no-color open "$1"
+{{end}}`
+
+// raycastScripts are the Raycast script commands, by file name
+var raycastScripts = map[string]string{
+	"projector-open.sh": `#!/bin/bash
+
+# Required parameters:
+# @raycast.schemaVersion 1
+# @raycast.title Open Project
+# @raycast.mode silent
+
+# Optional parameters:
+# @raycast.icon 📂
+# @raycast.argument1 { "type": "text", "placeholder": "Project" }
+# @raycast.packageName Projector
+
+# Documentation:
+# @raycast.description Open a project in its editor
+
+# Generated by 'projector integrate raycast'
+` + launcherPath + `
+` + launcherOpen,
+
+	"projector-rescan.sh": `#!/bin/bash
+
+# Required parameters:
+# @raycast.schemaVersion 1
+# @raycast.title Rescan Projects
+# @raycast.mode compact
+
+# Optional parameters:
+# @raycast.icon 🔄
+# @raycast.packageName Projector
+
+# Documentation:
+# @raycast.description Rescan the base folders for projects
+
+# Generated by 'projector integrate raycast'
+` + launcherPath + `
+{{if .Curl}}{{.Curl}} -X POST {{.URL}}/rescan >/dev/null && echo "Projects rescanned"
+{{else}}{{.Projector}} --quiet --no-color scan && echo "Projects rescanned"
+{{end}}`,
+}
+
+// writeRaycastScripts writes the Raycast script commands into dir and
+// returns their paths
+func writeRaycastScripts(dir string, s *launcherScript) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	var written []string
+	for _, name := range slices.Sorted(maps.Keys(raycastScripts)) {
+		var buf bytes.Buffer
+		if err := template.Must(template.New(name).Parse(raycastScripts[name])).Execute(&buf, s); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0755); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// alfredInfoPlist is the info.plist of the Alfred workflow: a Script
+// Filter listing projects, connected to a script opening the selected one
+const alfredInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>bundleid</key>
+	<string>com.github.ideaspaper.projector</string>
+	<key>name</key>
+	<string>Projector</string>
+	<key>description</key>
+	<string>Open projects saved or found by projector</string>
+	<key>createdby</key>
+	<string>projector integrate alfred</string>
+	<key>connections</key>
+	<dict>
+		<key>projector.list</key>
+		<array>
+			<dict>
+				<key>destinationuid</key>
+				<string>projector.open</string>
+				<key>modifiers</key>
+				<integer>0</integer>
+				<key>modifiersubtext</key>
+				<string></string>
+			</dict>
+		</array>
+	</dict>
+	<key>objects</key>
+	<array>
+		<dict>
+			<key>type</key>
+			<string>alfred.workflow.input.scriptfilter</string>
+			<key>uid</key>
+			<string>projector.list</string>
+			<key>version</key>
+			<integer>3</integer>
+			<key>config</key>
+			<dict>
+				<key>keyword</key>
+				<string>{{xml .Keyword}}</string>
+				<key>title</key>
+				<string>Open a project</string>
+				<key>runningsubtext</key>
+				<string>Loading projects…</string>
+				<key>argumenttype</key>
+				<integer>1</integer>
+				<key>withspace</key>
+				<true/>
+				<key>alfredfiltersresults</key>
+				<false/>
+				<key>type</key>
+				<integer>0</integer>
+				<key>scriptargtype</key>
+				<integer>1</integer>
+				<key>script</key>
+				<string>{{xml .List}}</string>
+			</dict>
+		</dict>
+		<dict>
+			<key>type</key>
+			<string>alfred.workflow.action.script</string>
+			<key>uid</key>
+			<string>projector.open</string>
+			<key>version</key>
+			<integer>2</integer>
+			<key>config</key>
+			<dict>
+				<key>type</key>
+				<integer>0</integer>
+				<key>scriptargtype</key>
+				<integer>1</integer>
+				<key>script</key>
+				<string>{{xml .Open}}</string>
+			</dict>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+// writeAlfredWorkflow writes the Alfred workflow to path
+func writeAlfredWorkflow(path, keyword string, s *launcherScript) error {
+	var open bytes.Buffer
+	if err := template.Must(template.New("open").Parse(launcherOpen)).Execute(&open, s); err != nil {
+		return err
+	}
+	data := map[string]string{
+		"Keyword": keyword,
+		"List":    launcherPath + "\n" + s.Projector + ` completion-data --output alfred "$1"` + "\n",
+		"Open":    launcherPath + "\n" + open.String(),
+	}
+	funcs := template.FuncMap{"xml": func(s string) (string, error) {
+		var buf bytes.Buffer
+		err := xml.EscapeText(&buf, []byte(s))
+		return buf.String(), err
+	}}
+	var plist bytes.Buffer
+	if err := template.Must(template.New("info.plist").Funcs(funcs).Parse(alfredInfoPlist)).Execute(&plist, data); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("info.plist")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(plist.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// integrateOutputDir returns --dir, or sub in the integrations folder of
+// the projects location
+func integrateOutputDir(basePath string, sub ...string) string {
+	if integrateDir != "" {
+		return paths.Expand(integrateDir)
+	}
+	return filepath.Join(append([]string{basePath, "integrations"}, sub...)...)
+}
+
+func runIntegrateRaycast(cmd *cobra.Command, args []string) error {
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+	s, err := newLauncherScript(store.GetBasePath())
+	if err != nil {
+		return err
+	}
+
+	dir := integrateOutputDir(store.GetBasePath(), "raycast")
+	written, err := writeRaycastScripts(dir, s)
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	for _, path := range written {
+		printStatus(formatter.FormatSuccess(fmt.Sprintf("Wrote %s", paths.Collapse(path))))
+	}
+	printStatus(formatter.FormatInfo(fmt.Sprintf("In Raycast, add %s under Extensions > Script Commands > Add Directories", paths.Collapse(dir))))
+	return nil
+}
+
+func runIntegrateAlfred(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(integrateKeyword) == "" {
+		return fmt.Errorf("the keyword cannot be empty")
+	}
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+	s, err := newLauncherScript(store.GetBasePath())
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(integrateOutputDir(store.GetBasePath()), "Projector.alfredworkflow")
+	if err := writeAlfredWorkflow(path, integrateKeyword, s); err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Wrote %s", paths.Collapse(path))))
+	printStatus(formatter.FormatInfo(fmt.Sprintf("Open it to install the workflow, then type '%s' in Alfred", integrateKeyword)))
+	return nil
+}
@@ -1,32 +1,47 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"syscall"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ideaspaper/projector/pkg/config"
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
 	"github.com/ideaspaper/projector/pkg/scanner"
-	"github.com/ideaspaper/projector/pkg/storage"
 )
 
 var (
 	// list command flags
-	listTag       string
-	listShowPath  bool
-	listGrouped   bool
-	listAll       bool
-	listFavorites bool
-	listGit       bool
-	listSVN       bool
-	listMercurial bool
-	listVSCode    bool
-	listAny       bool
+	listTag      string
+	listLanguage string
+	listShowPath bool
+	listGrouped  bool
+	listAll      bool
+	listTypes    typeFlags
+	listTemplate string
+	listOutput   string
+	listBorders  bool
+	listFilters  []string
+	listFields   string
+	listPrint0   bool
 )
 
 // listCmd represents the list command
@@ -42,17 +57,32 @@ Examples:
   # List only favorites
   projector list --favorites
 
-  # List only git repositories
-  projector list --git
+  # List only git repositories and VS Code workspaces
+  projector list --type git,vscode
+
+  # List everything but folders found by the "any" scanner
+  projector list --exclude-type any
 
   # Filter by tag
   projector list --tag Work
 
+  # Filter with an expression over kind, tags, name, path and more
+  projector list --filter 'kind==git && tag in (Work,OSS) && name~api'
+
   # Show project paths
   projector list --path
 
   # Group by project type
-  projector list --grouped`,
+  projector list --grouped
+
+  # Aligned columns with the kind, tags and last open time
+  projector list --output table
+
+  # One project per line, tab-separated, for awk, cut and fzf
+  projector list --output tsv --fields name,path,tags
+
+  # Custom output for scripts and menus (Go text/template)
+  projector list --template '{{.Name}}\t{{.RootPath}}\t{{join .Tags ","}}'`,
 	Aliases: []string{"ls"},
 	RunE:    runList,
 }
@@ -61,70 +91,123 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 
 	listCmd.Flags().StringVarP(&listTag, "tag", "t", "", "filter projects by tag")
+	listCmd.Flags().StringArrayVar(&listFilters, "filter", nil, "filter projects with an expression, e.g. 'kind==git && tag in (Work,OSS)' (can be used multiple times)")
+	listCmd.Flags().StringVar(&listLanguage, "language", "", "filter projects by detected language (e.g. go, rust)")
 	listCmd.Flags().BoolVarP(&listShowPath, "path", "p", false, "show project paths")
 	listCmd.Flags().BoolVarP(&listGrouped, "grouped", "g", false, "group projects by type")
 	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "include disabled projects")
-	listCmd.Flags().BoolVar(&listFavorites, "favorites", false, "show only favorites")
-	listCmd.Flags().BoolVar(&listGit, "git", false, "show only git repositories")
-	listCmd.Flags().BoolVar(&listSVN, "svn", false, "show only svn repositories")
-	listCmd.Flags().BoolVar(&listMercurial, "mercurial", false, "show only mercurial repositories")
-	listCmd.Flags().BoolVar(&listVSCode, "vscode", false, "show only vscode workspaces")
-	listCmd.Flags().BoolVar(&listAny, "any", false, "show only any-folder projects")
+	listTypes.register(listCmd)
+	listCmd.Flags().StringVar(&listTemplate, "template", "", "format each project with a Go template, e.g. '{{.Name}}\\t{{.RootPath}}'")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "text", "output format: text, table or tsv")
+	listCmd.Flags().BoolVar(&listBorders, "borders", false, "draw table borders (with --output table)")
+	listCmd.Flags().StringVar(&listFields, "fields", "", "comma-separated fields to print with --output tsv (default: name,path,kind,tags)")
+	listCmd.Flags().BoolVarP(&listPrint0, "print0", "0", false, "end each project with NUL instead of newline (with --output tsv)")
+	listCmd.MarkFlagsMutuallyExclusive("template", "grouped")
+	listCmd.MarkFlagsMutuallyExclusive("template", "path")
+	listCmd.MarkFlagsMutuallyExclusive("template", "output")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	// Load config
-	cfg, err := config.LoadOrCreateConfig()
+	var tmpl *template.Template
+	if listTemplate != "" {
+		var err error
+		if tmpl, err = output.ParseListTemplate(listTemplate); err != nil {
+			return err
+		}
+	}
+	if listOutput != "text" && listOutput != "table" && listOutput != "tsv" {
+		return fmt.Errorf("invalid output format '%s' (use text, table or tsv)", listOutput)
+	}
+	if listOutput != "tsv" && (cmd.Flags().Changed("fields") || listPrint0) {
+		return fmt.Errorf("--fields and --print0 need --output tsv")
+	}
+	fields, err := output.ParseTSVFields(listFields)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	logVerbose(cfg, "Loading projects with filters: favorites=%v git=%v svn=%v mercurial=%v vscode=%v any=%v",
-		listFavorites, listGit, listSVN, listMercurial, listVSCode, listAny)
+	// Load config
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
 
 	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	store, err := app.Storage()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 
 	// Load projects with type filter
-	filter := TypeFilter{
-		Favorites: listFavorites,
-		Git:       listGit,
-		SVN:       listSVN,
-		Mercurial: listMercurial,
-		VSCode:    listVSCode,
-		Any:       listAny,
-	}
-	allProjects, err := LoadFilteredProjects(store, filter)
+	filter, err := listTypes.filter()
+	if err != nil {
+		return err
+	}
+	slog.Debug("loading projects", "favorites", filter.Favorites, "git", filter.Git, "svn", filter.SVN,
+		"mercurial", filter.Mercurial, "vscode", filter.VSCode, "any", filter.Any)
+	allProjects, err := loadProjects(store, filter)
 	if err != nil {
 		return err
 	}
 
-	logVerbose(cfg, "Loaded %d projects before filtering", len(allProjects))
+	slog.Debug("loaded projects", "count", len(allProjects))
 
 	// Filter by enabled
 	if !listAll {
-		allProjects = FilterEnabled(allProjects)
+		allProjects = projector.FilterEnabled(allProjects)
 	}
 
 	// Filter by tag
-	allProjects = FilterByTag(allProjects, listTag)
+	allProjects = projector.FilterByTag(allProjects, listTag)
+	allProjects = projector.FilterByLanguage(allProjects, listLanguage)
+	if allProjects, err = filterByQueries(allProjects, listFilters); err != nil {
+		return err
+	}
+	allProjects = withoutCurrentProject(cfg, allProjects)
 
-	logVerbose(cfg, "After filtering: %d projects", len(allProjects))
+	slog.Debug("filtered projects", "count", len(allProjects), "tag", listTag, "language", listLanguage)
 
 	// Check for invalid paths if configured
 	if cfg.CheckInvalidPaths {
 		for _, p := range allProjects {
-			if _, err := os.Stat(p.RootPath); os.IsNotExist(err) {
+			if _, err := os.Stat(p.RootPath); os.IsNotExist(err) && !p.Offline {
 				p.Enabled = false
 			}
 		}
 	}
 
 	// Sort projects
-	sortProjects(allProjects, cfg.SortList)
+	sortProjects(allProjects, cfg)
+
+	if tmpl != nil {
+		rendered, err := output.FormatTemplate(tmpl, allProjects)
+		if err != nil {
+			return err
+		}
+		printPaged(cfg, rendered)
+		return nil
+	}
+
+	formatter := app.Formatter()
+
+	if listOutput == "tsv" {
+		fmt.Fprint(app.Out(), output.FormatTSV(allProjects, fields, listPrint0))
+		return nil
+	}
+
+	if listOutput == "table" {
+		history, err := app.History()
+		if err != nil {
+			return err
+		}
+		width, _ := terminalSize()
+		printPaged(cfg, formatter.FormatProjectTable(allProjects, output.TableOptions{
+			Width:      width,
+			Borders:    listBorders,
+			LastOpened: projector.LastOpened(history),
+		})+"\n")
+		return nil
+	}
 
 	// Override grouping from flag or config
 	// Flag takes precedence if explicitly set
@@ -134,34 +217,48 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Format and display
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
 	opts := output.ListOptions{
 		ShowPath:  listShowPath,
 		ShowIndex: false,
 		Grouped:   grouped,
 	}
 	listOutput, _ := formatter.FormatProjectList(allProjects, opts)
-	fmt.Println(listOutput)
+	printPaged(cfg, listOutput+"\n")
+	if len(allProjects) == 0 && !paths.Exists(cfg.GetConfigPath()) {
+		printStatus(formatter.FormatInfo("Run 'projector setup' to choose folders to scan, or 'projector add' to add a project"))
+	}
 
 	return nil
 }
 
-// sortProjects sorts projects according to the specified order
-func sortProjects(projects []*models.Project, order config.SortOrder) {
-	switch order {
+// sortProjects sorts projects according to the sort order in cfg
+func sortProjects(projects []*models.Project, cfg *config.Config) {
+	switch cfg.SortList {
 	case config.SortByName:
-		sort.Slice(projects, func(i, j int) bool {
-			return strings.ToLower(projects[i].Name) < strings.ToLower(projects[j].Name)
-		})
+		sortByName(projects)
 	case config.SortByPath:
 		sort.Slice(projects, func(i, j int) bool {
 			return strings.ToLower(projects[i].RootPath) < strings.ToLower(projects[j].RootPath)
 		})
+	case config.SortByActivity:
+		store, err := app.Storage()
+		if err != nil {
+			slog.Warn("cannot sort by activity", "error", err)
+			return
+		}
+		projector.SortByActivity(projects, projector.ActivityTimes(store, projects))
 	case config.SortBySaved, config.SortByRecent:
 		// Keep original order for saved/recent
 	}
 }
 
+// sortByName sorts projects by name, ignoring case
+func sortByName(projects []*models.Project) {
+	sort.Slice(projects, func(i, j int) bool {
+		return strings.ToLower(projects[i].Name) < strings.ToLower(projects[j].Name)
+	})
+}
+
 // scanCmd represents the scan command
 var scanCmd = &cobra.Command{
 	Use:   "scan [paths...]",
@@ -177,7 +274,26 @@ Examples:
   projector scan --all
 
   # Scan for git repos with custom depth
-  projector scan --git --depth 5 ~/code`,
+  projector scan --git --depth 5 ~/code
+
+  # Keep scanning and update the cache as repositories come and go
+  projector scan --watch
+
+  # Give up if scanning takes longer than a minute
+  projector scan --all --timeout 1m
+
+  # Read every directory again instead of skipping unchanged ones
+  projector scan --all --full
+
+  # On Windows, scan /home/me/code inside the Ubuntu WSL distro
+  projector scan --git --wsl Ubuntu /home/me/code
+
+  # See what a deeper scan would find without updating the cache
+  projector scan --all --depth 6 --dry-run
+
+  # Record remote URLs, default branches and last commit dates for list
+  # and info; later scans keep them until the next --collect-meta
+  projector scan --git --collect-meta`,
 	RunE: runScan,
 }
 
@@ -189,6 +305,16 @@ var (
 	scanAny       bool
 	scanAll       bool
 	scanDepth     int
+	scanWatch     bool
+	scanFull      bool
+	scanWSL       string
+	scanDryRun    bool
+	scanJSON      bool
+
+	scanRespectGitignore bool
+	scanOneFileSystem    bool
+	scanTimeout          time.Duration
+	scanCollectMeta      bool
 )
 
 func init() {
@@ -201,6 +327,16 @@ func init() {
 	scanCmd.Flags().BoolVar(&scanAny, "any", false, "scan for any folder")
 	scanCmd.Flags().BoolVarP(&scanAll, "all", "a", false, "scan for all types")
 	scanCmd.Flags().IntVarP(&scanDepth, "depth", "d", 0, "maximum scan depth (0 = use config default)")
+	scanCmd.Flags().BoolVarP(&scanWatch, "watch", "w", false, "keep running and update the cache when projects change")
+	scanCmd.Flags().BoolVar(&scanFull, "full", false, "read every directory instead of reusing unchanged ones from the last scan")
+	scanCmd.Flags().StringVar(&scanWSL, "wsl", "", "scan inside this WSL distro; paths are Linux paths in the distro (Windows only)")
+	scanCmd.Flags().DurationVar(&scanTimeout, "timeout", 0, "abort a scan that takes longer than this (e.g. 30s, 2m; 0 = no limit)")
+	scanCmd.Flags().BoolVar(&scanDryRun, "dry-run", false, "print what the scan finds without updating the cache")
+	scanCmd.Flags().BoolVar(&scanJSON, "json", false, "print the --dry-run results as JSON")
+	scanCmd.MarkFlagsMutuallyExclusive("dry-run", "watch")
+	scanCmd.Flags().BoolVar(&scanRespectGitignore, "respect-gitignore", false, "skip directories ignored by .gitignore/.ignore files (overrides config)")
+	scanCmd.Flags().BoolVar(&scanOneFileSystem, "one-file-system", false, "do not descend into other file systems, such as mount points (overrides config)")
+	scanCmd.Flags().BoolVar(&scanCollectMeta, "collect-meta", false, "record each repository's remote URL, default branch and last commit date")
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
@@ -208,166 +344,165 @@ func runScan(cmd *cobra.Command, args []string) error {
 	if scanDepth < 0 {
 		return fmt.Errorf("--depth must be a non-negative integer, got %d", scanDepth)
 	}
+	if scanTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative, got %s", scanTimeout)
+	}
+	if scanJSON && !scanDryRun {
+		return fmt.Errorf("--json requires --dry-run")
+	}
+
+	// Ctrl-C cancels the scan; the cache is only written after a complete scan
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Load config
-	cfg, err := config.LoadOrCreateConfig()
+	cfg, err := app.Config()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
-	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+	if scanWSL != "" && runtime.GOOS != "windows" {
+		return fmt.Errorf("--wsl is only available on Windows")
 	}
 
-	// Determine what to scan
-	if !scanGit && !scanSVN && !scanMercurial && !scanVSCode && !scanAny && !scanAll {
-		scanAll = true
+	if scanWatch && !cfg.CacheProjectsBetweenSessions {
+		return fmt.Errorf("--watch requires cacheProjectsBetweenSessions to be enabled")
 	}
 
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-	cache := &storage.CachedProjects{}
+	formatter := app.Formatter()
 
-	// Scan Git
-	if scanAll || scanGit {
-		baseFolders := cfg.GitBaseFolders
-		if len(args) > 0 {
-			baseFolders = args
-		}
-		if len(baseFolders) > 0 {
-			s := scanner.NewScanner(scanner.ScannerGit)
-			s.SetBaseFolders(baseFolders)
-			s.SetIgnoredFolders(cfg.GitIgnoredFolders)
-			depth := cfg.GitMaxDepth
-			if scanDepth > 0 {
-				depth = scanDepth
-			}
-			s.SetMaxDepth(depth)
-			s.SetIgnoreWithinProjects(cfg.IgnoreProjectsWithinProjects)
-			s.SetSupportSymlinks(cfg.SupportSymlinks)
+	m, err := app.Manager()
+	if err != nil {
+		return err
+	}
 
-			projects, err := s.Scan()
-			if err != nil {
-				fmt.Println(formatter.FormatWarning(fmt.Sprintf("Error scanning Git repositories: %v", err)))
-			} else {
-				cache.Git = projects
-				fmt.Println(formatter.FormatInfo(fmt.Sprintf("Found %d Git repositories", len(projects))))
-			}
-		}
+	jobs, err := projector.ScanJobs(cfg, scanOptions(args))
+	if err != nil {
+		return err
 	}
 
-	// Scan SVN
-	if scanAll || scanSVN {
-		baseFolders := cfg.SVNBaseFolders
-		if len(args) > 0 {
-			baseFolders = args
+	if scanDryRun {
+		previews, err := m.PreviewScanJobs(ctx, jobs, scanFull)
+		if err := scanAborted(err); err != nil {
+			return err
 		}
-		if len(baseFolders) > 0 {
-			s := scanner.NewScanner(scanner.ScannerSVN)
-			s.SetBaseFolders(baseFolders)
-			s.SetIgnoredFolders(cfg.SVNIgnoredFolders)
-			depth := cfg.SVNMaxDepth
-			if scanDepth > 0 {
-				depth = scanDepth
-			}
-			s.SetMaxDepth(depth)
-
-			projects, err := s.Scan()
+		if err != nil {
+			return err
+		}
+		if scanJSON {
+			data, err := json.MarshalIndent(previews, "", "    ")
 			if err != nil {
-				fmt.Println(formatter.FormatWarning(fmt.Sprintf("Error scanning SVN repositories: %v", err)))
-			} else {
-				cache.SVN = projects
-				fmt.Println(formatter.FormatInfo(fmt.Sprintf("Found %d SVN repositories", len(projects))))
+				return fmt.Errorf("failed to serialize scan results: %w", err)
 			}
+			fmt.Fprintln(app.Out(), string(data))
+			return nil
 		}
+		writeScanPreview(app.Out(), formatter, previews)
+		return nil
 	}
 
-	// Scan Mercurial
-	if scanAll || scanMercurial {
-		baseFolders := cfg.MercurialBaseFolders
-		if len(args) > 0 {
-			baseFolders = args
+	cache, err := m.RunScanJobs(ctx, jobs, scanFull, func(job projector.ScanJob, found int, err error) {
+		if err != nil {
+			formatter.FprintWarning(app.Out(), fmt.Sprintf("Error scanning %s: %v", job.Label, err))
+			return
 		}
-		if len(baseFolders) > 0 {
-			s := scanner.NewScanner(scanner.ScannerMercurial)
-			s.SetBaseFolders(baseFolders)
-			s.SetIgnoredFolders(cfg.MercurialIgnoredFolders)
-			depth := cfg.MercurialMaxDepth
-			if scanDepth > 0 {
-				depth = scanDepth
-			}
-			s.SetMaxDepth(depth)
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Found %d %s", found, job.Label)))
+	})
+	if err := scanAborted(err); err != nil {
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cfg.CacheProjectsBetweenSessions {
+		printStatus(formatter.FormatSuccess("Cache updated"))
+	}
 
-			projects, err := s.Scan()
-			if err != nil {
-				fmt.Println(formatter.FormatWarning(fmt.Sprintf("Error scanning Mercurial repositories: %v", err)))
-			} else {
-				cache.Mercurial = projects
-				fmt.Println(formatter.FormatInfo(fmt.Sprintf("Found %d Mercurial repositories", len(projects))))
-			}
-		}
+	if scanWatch {
+		return watchScanJobs(ctx, m.Storage(), formatter, jobs, cache, &sync.RWMutex{})
 	}
 
-	// Scan VSCode
-	if scanAll || scanVSCode {
-		baseFolders := cfg.VSCodeBaseFolders
-		if len(args) > 0 {
-			baseFolders = args
-		}
-		if len(baseFolders) > 0 {
-			s := scanner.NewScanner(scanner.ScannerVSCode)
-			s.SetBaseFolders(baseFolders)
-			s.SetIgnoredFolders(cfg.VSCodeIgnoredFolders)
-			depth := cfg.VSCodeMaxDepth
-			if scanDepth > 0 {
-				depth = scanDepth
-			}
-			s.SetMaxDepth(depth)
+	return nil
+}
 
-			projects, err := s.Scan()
-			if err != nil {
-				fmt.Println(formatter.FormatWarning(fmt.Sprintf("Error scanning VS Code workspaces: %v", err)))
-			} else {
-				cache.VSCode = projects
-				fmt.Println(formatter.FormatInfo(fmt.Sprintf("Found %d VS Code workspaces", len(projects))))
-			}
+// writeScanPreview prints the projects a dry-run scan found for each type,
+// marking those not in the cache with + and listing cached projects that
+// were not found with -
+func writeScanPreview(out io.Writer, formatter *output.Formatter, previews []projector.ScanPreview) {
+	for _, preview := range previews {
+		if preview.Error != "" {
+			fmt.Fprintln(out, formatter.FormatWarning(fmt.Sprintf("Error scanning %s: %s", preview.Label, preview.Error)))
+			continue
 		}
-	}
+		fmt.Fprintln(out, formatter.FormatInfo(fmt.Sprintf("Would find %d %s (%d new, %d gone)",
+			len(preview.Projects), preview.Label, len(preview.Added), len(preview.Removed))))
 
-	// Scan Any
-	if scanAll || scanAny {
-		baseFolders := cfg.AnyBaseFolders
-		if len(args) > 0 {
-			baseFolders = args
+		added := make(map[string]bool, len(preview.Added))
+		for _, path := range preview.Added {
+			added[path] = true
 		}
-		if len(baseFolders) > 0 {
-			s := scanner.NewScanner(scanner.ScannerAny)
-			s.SetBaseFolders(baseFolders)
-			s.SetIgnoredFolders(cfg.AnyIgnoredFolders)
-			depth := cfg.AnyMaxDepth
-			if scanDepth > 0 {
-				depth = scanDepth
-			}
-			s.SetMaxDepth(depth)
-
-			projects, err := s.Scan()
-			if err != nil {
-				fmt.Println(formatter.FormatWarning(fmt.Sprintf("Error scanning folders: %v", err)))
-			} else {
-				cache.Any = projects
-				fmt.Println(formatter.FormatInfo(fmt.Sprintf("Found %d folders", len(projects))))
+		width := 0
+		for _, p := range preview.Projects {
+			width = max(width, utf8.RuneCountInString(p.Name))
+		}
+		for _, p := range preview.Projects {
+			mark := " "
+			if added[p.RootPath] {
+				mark = "+"
 			}
+			name := p.Name + strings.Repeat(" ", width-utf8.RuneCountInString(p.Name))
+			fmt.Fprintf(out, "  %s %s  %s\n", mark, formatter.FormatName(name), formatter.FormatPath(paths.Collapse(p.RootPath)))
+		}
+		for _, path := range preview.Removed {
+			fmt.Fprintf(out, "  - %s\n", formatter.FormatPath(paths.Collapse(path)))
 		}
 	}
+	fmt.Fprintln(out, formatter.FormatSuccess("Dry run: cache left unchanged"))
+}
 
-	// Save cache
-	if cfg.CacheProjectsBetweenSessions {
-		if err := store.SaveCache(cache); err != nil {
-			return fmt.Errorf("failed to save cache: %w", err)
+// scanOptions returns the scan options selected by the scan flags, with
+// args replacing the configured base folders. Every type is scanned when
+// none is selected.
+func scanOptions(args []string) projector.ScanOptions {
+	opts := projector.ScanOptions{
+		Paths:            args,
+		Depth:            scanDepth,
+		RespectGitignore: scanRespectGitignore,
+		OneFileSystem:    scanOneFileSystem,
+		WSLDistro:        scanWSL,
+		Full:             scanFull,
+		Timeout:          scanTimeout,
+		CollectMeta:      scanCollectMeta,
+	}
+	if scanAll {
+		return opts
+	}
+	for _, t := range []struct {
+		enabled     bool
+		scannerType scanner.ScannerType
+	}{
+		{scanGit, scanner.ScannerGit},
+		{scanSVN, scanner.ScannerSVN},
+		{scanMercurial, scanner.ScannerMercurial},
+		{scanVSCode, scanner.ScannerVSCode},
+		{scanAny, scanner.ScannerAny},
+	} {
+		if t.enabled {
+			opts.Types = append(opts.Types, t.scannerType)
 		}
-		fmt.Println(formatter.FormatSuccess("Cache updated"))
 	}
+	return opts
+}
 
-	return nil
+// scanAborted converts a cancelled or timed-out scan into a user-facing
+// error, returning nil for any other error
+func scanAborted(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("scan timed out after %s; cache left unchanged", scanTimeout)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("scan cancelled; cache left unchanged")
+	default:
+		return nil
+	}
 }
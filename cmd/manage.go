@@ -2,76 +2,156 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
 	"github.com/ideaspaper/projector/pkg/storage"
+	"github.com/ideaspaper/projector/pkg/trash"
 )
 
 // removeCmd represents the remove command
 var removeCmd = &cobra.Command{
-	Use:     "remove <project-name>",
-	Short:   "Remove a project from favorites",
-	Long:    `Remove a project from your saved favorites by name.`,
+	Use:   "remove <project-name>",
+	Short: "Remove a project from favorites",
+	Long: `Remove a project from your saved favorites by name, or use "." for the project containing the current directory.
+
+The project folder is left alone unless --delete-folder is given, which
+also moves it to the trash (the macOS Trash, the Windows Recycle Bin or
+the desktop trash on Linux), where it can be restored from. You are asked
+to type the project name to confirm; --force skips the question.
+
+Examples:
+  projector remove myproject
+
+  # Also move an abandoned experiment's folder to the trash
+  projector remove old-experiment --delete-folder`,
 	Aliases: []string{"rm", "delete"},
 	Args:    cobra.ExactArgs(1),
 	RunE:    runRemove,
 }
 
+var (
+	removeDeleteFolder bool
+	removeForce        bool
+)
+
 func init() {
 	rootCmd.AddCommand(removeCmd)
+
+	removeCmd.Flags().BoolVar(&removeDeleteFolder, "delete-folder", false, "also move the project folder to the trash")
+	removeCmd.Flags().BoolVarP(&removeForce, "force", "f", false, "delete the folder without asking (with --delete-folder)")
 }
 
 func runRemove(cmd *cobra.Command, args []string) error {
-	projectName := args[0]
+	if removeForce && !removeDeleteFolder {
+		return fmt.Errorf("--force needs --delete-folder")
+	}
 
-	// Load config
-	cfg, err := config.LoadOrCreateConfig()
+	m, err := app.Manager()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
-	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+	formatter := app.Formatter()
+	if removeDeleteFolder {
+		project, err := m.FindFavorite(args[0])
+		if err != nil {
+			return err
+		}
+		trashed, err := trashProjectFolder(m, project, removeForce)
+		if err != nil {
+			return err
+		}
+		if trashed {
+			printStatus(formatter.FormatSuccess(fmt.Sprintf("Moved %s to the trash", paths.Collapse(project.RootPath))))
+		} else {
+			printStatus(formatter.FormatInfo(fmt.Sprintf("The folder %s no longer exists", paths.Collapse(project.RootPath))))
+		}
+		args = []string{project.Name}
 	}
 
-	// Load projects
-	projects, err := store.LoadProjects()
+	project, err := m.Remove(args[0])
 	if err != nil {
-		return fmt.Errorf("failed to load projects: %w", err)
+		return err
 	}
 
-	// Find and remove project
-	if !projects.Remove(projectName) {
-		return fmt.Errorf("project '%s' not found", projectName)
-	}
+	// Output
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Removed project '%s'", project.Name)))
 
-	// Save
-	if err := store.SaveProjects(projects); err != nil {
-		return fmt.Errorf("failed to save projects: %w", err)
+	return nil
+}
+
+// trashProjectFolder moves the folder of project to the trash after the
+// user types the project's name, or right away with force. Folders that
+// hold the home directory are refused. It reports false when the folder
+// is already gone.
+func trashProjectFolder(m *projector.Manager, project *models.Project, force bool) (bool, error) {
+	folder := project.RootPath
+	if _, err := os.Lstat(folder); os.IsNotExist(err) {
+		return false, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil && paths.Within(folder, home) {
+		return false, fmt.Errorf("refusing to delete %s, which holds your home directory", folder)
+	}
+	if filepath.Dir(folder) == folder {
+		return false, fmt.Errorf("refusing to delete the root folder %s", folder)
 	}
 
-	// Output
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-	fmt.Println(formatter.FormatSuccess(fmt.Sprintf("Removed project '%s'", projectName)))
+	if !force {
+		if noInput {
+			return false, fmt.Errorf("%w; use --force to delete the folder without asking", errNoInput)
+		}
+		formatter := app.Formatter()
+		formatter.FprintWarning(app.Out(), fmt.Sprintf("This moves %s and everything in it to the trash.", formatter.FormatPath(folder)))
+		if all, err := loadProjects(m.Storage(), projector.TypeFilter{}); err == nil {
+			var inside []string
+			for _, p := range all {
+				if p != project && paths.Within(folder, p.RootPath) && !paths.Equal(folder, p.RootPath) {
+					inside = append(inside, p.Name)
+				}
+			}
+			if len(inside) > 0 {
+				fmt.Fprintf(app.Out(), "It also holds the projects %s.\n", strings.Join(inside, ", "))
+			}
+		}
+		fmt.Fprintf(app.Out(), "Type the project name (%s) to confirm: ", project.Name)
+		input, err := ReadUserInput()
+		if err != nil || input != project.Name {
+			return false, errCancelled
+		}
+	}
 
-	return nil
+	if err := trash.Move(folder); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // editCmd represents the edit command
 var editCmd = &cobra.Command{
-	Use:   "edit <project-name>",
+	Use:   "edit [project-name]",
 	Short: "Edit a project's properties",
-	Long: `Edit a project's name, path, tags, or enabled state.
+	Long: `Edit a project's name, path, kind, parent, icon, color, tags, description, notes, or enabled state.
+
+The kind (git, svn, mercurial, vscode or any) says what the project folder
+is. It is detected when the project is added or its path changes.
+
+With --filter, every favorite matching all the filters is changed by the
+--set assignments instead of naming one project. A filter is field=value
+or field!=value on name, path, tag, kind, language, enabled, description,
+notes, icon or color; values ignore case and may use * and ? wildcards. An
+assignment is field=value on enabled, tags, kind, description, notes, icon
+or color, or tag+=value and tag-=value to add or remove a tag.
 
 Examples:
   # Rename a project
@@ -87,8 +167,31 @@ Examples:
   projector edit myproject --add-tag Work --add-tag Important
 
   # Remove a tag
-  projector edit myproject --remove-tag Old`,
-	Args: cobra.ExactArgs(1),
+  projector edit myproject --remove-tag Old
+
+  # Set a description (use an empty string to clear it)
+  projector edit myproject --description "Customer portal frontend"
+
+  # Treat the project folder as a Git repository
+  projector edit myproject --kind git
+
+  # Make a project a subproject of the monorepo containing it
+  projector edit billing --parent platform
+
+  # Disable every project tagged Old
+  projector edit --filter tag=Old --set enabled=false
+
+  # Tag all client projects and drop their Active tag
+  projector edit --filter 'path=~/clients/*' --set tag+=Archived --set tag-=Active`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(editFilters) > 0 {
+			if len(args) > 0 {
+				return fmt.Errorf("a project name cannot be used with --filter")
+			}
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: runEdit,
 }
 
@@ -98,6 +201,14 @@ var (
 	editEnabled    string
 	editAddTags    []string
 	editRemoveTags []string
+	editDesc       string
+	editNotes      string
+	editKind       string
+	editIcon       string
+	editColor      string
+	editFilters    []string
+	editSets       []string
+	editParent     string
 )
 
 func init() {
@@ -108,122 +219,296 @@ func init() {
 	editCmd.Flags().StringVar(&editEnabled, "enabled", "", "enable/disable project (true/false)")
 	editCmd.Flags().StringSliceVar(&editAddTags, "add-tag", []string{}, "add a tag to the project (can be used multiple times)")
 	editCmd.Flags().StringSliceVar(&editRemoveTags, "remove-tag", []string{}, "remove a tag from the project (can be used multiple times)")
+	editCmd.Flags().StringVar(&editDesc, "description", "", "new project description")
+	editCmd.Flags().StringVar(&editNotes, "notes", "", "new project notes")
+	editCmd.Flags().StringVar(&editIcon, "icon", "", "project icon (empty string restores the kind's icon)")
+	editCmd.Flags().StringVar(&editColor, "color", "", "color of the name in lists, e.g. 'blue' or '#268bd2' (empty string restores the tag or theme color)")
+	editCmd.Flags().StringVar(&editParent, "parent", "", "name of the project containing this one, e.g. a monorepo (empty string makes it top-level)")
+	editCmd.Flags().StringVar(&editKind, "kind", "", "project folder kind (git, svn, mercurial, vscode, any)")
+	editCmd.Flags().StringArrayVar(&editFilters, "filter", nil, "edit every project matching field=value or field!=value (can be used multiple times)")
+	editCmd.Flags().StringArrayVar(&editSets, "set", nil, "change field=value, tag+=value or tag-=value (can be used multiple times)")
 }
 
 func runEdit(cmd *cobra.Command, args []string) error {
-	projectName := args[0]
-
-	// Load config
-	cfg, err := config.LoadOrCreateConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+	if len(editFilters) > 0 {
+		return runEditFilter(cmd)
 	}
+	projectName := args[0]
 
 	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	store, err := app.Storage()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 
-	// Load projects
-	projects, err := store.LoadProjects()
-	if err != nil {
-		return fmt.Errorf("failed to load projects: %w", err)
+	// Candidate parents are loaded first, since the storage is locked
+	// while the project is changed
+	var all []*models.Project
+	if editParent != "" {
+		if all, err = loadProjects(store, projector.TypeFilter{}); err != nil {
+			return err
+		}
 	}
 
-	// Find project
-	project := projects.FindByName(projectName)
-	if project == nil {
-		return fmt.Errorf("project '%s' not found", projectName)
-	}
+	var project *models.Project
+	err = store.Update(func(projects *models.ProjectList) error {
+		// Find project
+		var err error
+		project = projects.FindByName(projectName)
+		if projectName == projector.CurrentProjectArg {
+			if project, err = projector.FindCurrentProject(projects.Projects); err != nil {
+				return err
+			}
+		}
+		if project == nil {
+			return &projector.NotFoundError{Name: projectName}
+		}
 
-	// Apply changes
-	changed := false
+		// Apply changes
+		changed := false
+
+		if editName != "" {
+			// Check for name conflict
+			if existing := projects.FindByName(editName); existing != nil && existing != project {
+				return fmt.Errorf("project with name '%s' already exists", editName)
+			}
+			// Keep the subprojects pointing at their parent
+			for _, p := range projects.Projects {
+				if strings.EqualFold(p.Parent, project.Name) {
+					p.Parent = editName
+				}
+			}
+			project.Name = editName
+			changed = true
+		}
 
-	if editName != "" {
-		// Check for name conflict
-		if existing := projects.FindByName(editName); existing != nil && existing != project {
-			return fmt.Errorf("project with name '%s' already exists", editName)
+		if editPath != "" {
+			// Resolve to absolute path
+			absPath, err := filepath.Abs(editPath)
+			if err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+			// Check if path exists
+			info, err := os.Stat(absPath)
+			if err != nil {
+				return fmt.Errorf("path does not exist: %s", absPath)
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("path is not a directory: %s", absPath)
+			}
+			project.RootPath = absPath
+			project.FolderKind = scanner.DetectKind(absPath)
+			project.DevContainer = scanner.DevContainerConfig(absPath)
+			project.Volume = paths.VolumeRoot(absPath)
+			changed = true
 		}
-		project.Name = editName
-		changed = true
-	}
 
-	if editPath != "" {
-		// Resolve to absolute path
-		absPath, err := filepath.Abs(editPath)
-		if err != nil {
-			return fmt.Errorf("failed to resolve path: %w", err)
+		if editKind != "" {
+			kind, err := models.ParseFolderKind(editKind)
+			if err != nil {
+				return err
+			}
+			project.FolderKind = kind
+			changed = true
 		}
-		// Check if path exists
-		info, err := os.Stat(absPath)
-		if err != nil {
-			return fmt.Errorf("path does not exist: %s", absPath)
+
+		if editPath != "" || editKind != "" {
+			project.WorkspaceFile = ""
+			if project.FolderKind == models.KindVSCode {
+				project.WorkspaceFile = scanner.WorkspaceFile(project.RootPath)
+			}
 		}
-		if !info.IsDir() {
-			return fmt.Errorf("path is not a directory: %s", absPath)
+
+		if cmd.Flags().Changed("parent") {
+			project.Parent = ""
+			if editParent != "" {
+				parent, err := projector.ResolveParent(all, project, editParent)
+				if err != nil {
+					return err
+				}
+				project.Parent = parent.Name
+			}
+			changed = true
 		}
-		project.RootPath = absPath
-		changed = true
-	}
 
-	if editEnabled != "" {
-		enabled, err := strconv.ParseBool(editEnabled)
-		if err != nil {
-			return fmt.Errorf("--enabled must be a boolean value (true, false, 1, 0, etc.): %w", err)
+		if editEnabled != "" {
+			enabled, err := strconv.ParseBool(editEnabled)
+			if err != nil {
+				return fmt.Errorf("--enabled must be a boolean value (true, false, 1, 0, etc.): %w", err)
+			}
+			project.Enabled = enabled
+			changed = true
 		}
-		project.Enabled = enabled
-		changed = true
-	}
 
-	// Add tags
-	for _, tag := range editAddTags {
-		tag = strings.TrimSpace(tag)
-		if tag == "" {
-			continue
+		// Description and notes may be set to an empty string to clear them,
+		// so check whether the flag was given rather than its value
+		if cmd.Flags().Changed("description") {
+			project.Description = editDesc
+			changed = true
+		}
+
+		if cmd.Flags().Changed("notes") {
+			project.Notes = editNotes
+			changed = true
 		}
-		if project.HasTag(tag) {
-			return fmt.Errorf("project already has tag '%s'", tag)
+
+		if cmd.Flags().Changed("icon") {
+			project.Icon = editIcon
+			changed = true
+		}
+
+		if cmd.Flags().Changed("color") {
+			if _, err := output.ParseColor(editColor); err != nil {
+				return err
+			}
+			project.Color = editColor
+			changed = true
+		}
+
+		// Add tags
+		for _, tag := range editAddTags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			if project.HasTag(tag) {
+				return fmt.Errorf("project already has tag '%s'", tag)
+			}
+			project.AddTag(tag)
+			changed = true
+		}
+
+		// Remove tags
+		for _, tag := range editRemoveTags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			if !project.HasTag(tag) {
+				return fmt.Errorf("project does not have tag '%s'", tag)
+			}
+			project.RemoveTag(tag)
+			changed = true
 		}
-		project.AddTag(tag)
-		changed = true
+
+		for _, expr := range editSets {
+			assignment, err := projector.ParseAssignment(expr)
+			if err != nil {
+				return err
+			}
+			assignment.Apply(project)
+			changed = true
+		}
+
+		if !changed {
+			return fmt.Errorf("no changes specified (use --name, --path, --enabled, --add-tag, --remove-tag, --description, --notes, --parent or --set)")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Remove tags
-	for _, tag := range editRemoveTags {
-		tag = strings.TrimSpace(tag)
-		if tag == "" {
-			continue
+	// Output
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Updated project '%s'", project.Name)))
+
+	return nil
+}
+
+// runEditFilter applies the --set assignments to every favorite matching
+// the --filter expressions
+func runEditFilter(cmd *cobra.Command) error {
+	for _, name := range []string{"name", "path", "enabled", "add-tag", "remove-tag", "description", "notes", "icon", "color", "kind"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be used with --filter (use --set)", name)
+		}
+	}
+	if len(editSets) == 0 {
+		return fmt.Errorf("--filter needs at least one --set")
+	}
+
+	filters := make([]projector.Filter, len(editFilters))
+	for i, expr := range editFilters {
+		f, err := projector.ParseFilter(expr)
+		if err != nil {
+			return err
 		}
-		if !project.HasTag(tag) {
-			return fmt.Errorf("project does not have tag '%s'", tag)
+		filters[i] = f
+	}
+	assignments := make([]projector.Assignment, len(editSets))
+	for i, expr := range editSets {
+		a, err := projector.ParseAssignment(expr)
+		if err != nil {
+			return err
 		}
-		project.RemoveTag(tag)
-		changed = true
+		assignments[i] = a
 	}
 
-	if !changed {
-		return fmt.Errorf("no changes specified (use --name, --path, --enabled, --add-tag, or --remove-tag)")
+	store, err := app.Storage()
+	if err != nil {
+		return err
 	}
 
-	// Save
-	if err := store.SaveProjects(projects); err != nil {
-		return fmt.Errorf("failed to save projects: %w", err)
+	var matched int
+	var updated []*models.Project
+	err = store.Update(func(projects *models.ProjectList) error {
+		if matched, updated = editMatching(projects.Projects, filters, assignments); len(updated) == 0 {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Output
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-	fmt.Println(formatter.FormatSuccess(fmt.Sprintf("Updated project '%s'", project.Name)))
+	formatter := app.Formatter()
+	if matched == 0 {
+		printStatus(formatter.FormatInfo("No projects match the filter"))
+		return nil
+	}
+	if len(updated) == 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("%d matching projects already up to date", matched)))
+		return nil
+	}
 
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Updated %d of %d matching projects", len(updated), matched)))
+	for _, p := range updated {
+		fmt.Fprintf(app.Out(), "  %s\n", formatter.FormatName(p.Name))
+	}
 	return nil
 }
 
+// editMatching applies the assignments to the projects matching every
+// filter, returning how many matched and the ones that changed
+func editMatching(projects []*models.Project, filters []projector.Filter, assignments []projector.Assignment) (int, []*models.Project) {
+	matched := 0
+	var updated []*models.Project
+	for _, p := range projects {
+		if !projector.MatchFilters(p, filters) {
+			continue
+		}
+		matched++
+		changed := false
+		for _, a := range assignments {
+			if a.Apply(p) {
+				changed = true
+			}
+		}
+		if changed {
+			updated = append(updated, p)
+		}
+	}
+	return matched, updated
+}
+
 // tagsCmd represents the tags command
 var tagsCmd = &cobra.Command{
 	Use:   "tags",
 	Short: "List all tags in use",
-	Long:  `List all unique tags currently used by projects.`,
-	RunE:  runTags,
+	Long: `List all unique tags currently used by projects. Tags containing '/',
+such as Work/ClientA/Backend, are shown as a tree below their parents.`,
+	RunE: runTags,
 }
 
 func init() {
@@ -231,16 +516,10 @@ func init() {
 }
 
 func runTags(cmd *cobra.Command, args []string) error {
-	// Load config
-	cfg, err := config.LoadOrCreateConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
 	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	store, err := app.Storage()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 
 	// Load projects
@@ -257,24 +536,29 @@ func runTags(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
+	formatter := app.Formatter()
 
 	if len(tagSet) == 0 {
-		fmt.Println(formatter.FormatInfo("No tags in use"))
+		printStatus(formatter.FormatInfo("No tags in use"))
 		return nil
 	}
 
-	// Convert to sorted slice
 	tags := make([]string, 0, len(tagSet))
 	for tag := range tagSet {
 		tags = append(tags, tag)
 	}
-	sort.Strings(tags)
 
-	fmt.Println("Tags in use:")
-	for _, tag := range tags {
-		fmt.Printf("  - %s\n", tag)
-	}
+	fmt.Fprintln(app.Out(), "Tags in use:")
+	writeTagTree(app.Out(), tags)
 
 	return nil
 }
+
+// writeTagTree lists tags sorted, with each level of a hierarchical tag
+// indented below its parent
+func writeTagTree(w io.Writer, tags []string) {
+	for _, tag := range projector.ExpandTags(tags) {
+		parts := strings.Split(tag, models.TagSeparator)
+		fmt.Fprintf(w, "%s- %s\n", strings.Repeat("  ", len(parts)), parts[len(parts)-1])
+	}
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+var (
+	menuBackend  string
+	menuTag      string
+	menuTerminal bool
+)
+
+// menuLauncher describes how to run an external launcher in "pick a line" mode
+type menuLauncher struct {
+	args  []string
+	icons bool // accepts rofi's "\x00icon\x1f<name>" row options
+}
+
+// menuLaunchers are the supported launchers by name
+var menuLaunchers = map[string]menuLauncher{
+	"rofi":   {args: []string{"rofi", "-dmenu", "-i", "-p", "Project", "-show-icons"}, icons: true},
+	"wofi":   {args: []string{"wofi", "--dmenu", "-i", "-p", "Project"}},
+	"dmenu":  {args: []string{"dmenu", "-i", "-p", "Project"}},
+	"choose": {args: []string{"choose"}},
+}
+
+// menuLauncherOrder is the order in which auto-detection tries launchers
+var menuLauncherOrder = []string{"rofi", "wofi", "dmenu", "choose"}
+
+// menuKindPrefixes label each entry with its kind
+var menuKindPrefixes = map[models.ProjectKind]string{
+	models.KindFavorite:  "★",
+	models.KindGit:       "git",
+	models.KindSVN:       "svn",
+	models.KindMercurial: "hg",
+	models.KindVSCode:    "code",
+	models.KindAny:       "dir",
+}
+
+// menuKindIcons are the freedesktop icon names shown by launchers with icons
+var menuKindIcons = map[models.ProjectKind]string{
+	models.KindFavorite:  "starred",
+	models.KindGit:       "git",
+	models.KindSVN:       "folder-remote",
+	models.KindMercurial: "folder-remote",
+	models.KindVSCode:    "visual-studio-code",
+	models.KindAny:       "folder",
+}
+
+// menuCmd represents the menu command
+var menuCmd = &cobra.Command{
+	Use:   "menu",
+	Short: "Pick a project with rofi, wofi, dmenu or choose and open it",
+	Long: `Show projects in an external launcher and open the selected one.
+Bind it to a hotkey in your desktop or window manager to switch projects
+from anywhere.
+
+Without --backend the first launcher found on PATH is used, trying rofi,
+wofi, dmenu and choose in that order. Each entry is prefixed with its
+kind; rofi also shows an icon.
+
+Examples:
+  # Pick a project with rofi and open it in the editor
+  projector menu --backend rofi
+
+  # Open a terminal at the selected Work project
+  projector menu --tag Work --terminal`,
+	Args: cobra.NoArgs,
+	RunE: runMenu,
+}
+
+func init() {
+	rootCmd.AddCommand(menuCmd)
+
+	menuCmd.Flags().StringVarP(&menuBackend, "backend", "b", "", "launcher to use: rofi, wofi, dmenu or choose (default: first found)")
+	menuCmd.Flags().StringVarP(&menuTag, "tag", "t", "", "filter projects by tag")
+	menuCmd.Flags().BoolVarP(&menuTerminal, "terminal", "T", false, "open a terminal at the project root instead of an editor")
+}
+
+func runMenu(cmd *cobra.Command, args []string) error {
+	name, err := resolveMenuBackend(menuBackend, exec.LookPath)
+	if err != nil {
+		return err
+	}
+	launcher := menuLaunchers[name]
+
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	m, err := app.Manager()
+	if err != nil {
+		return err
+	}
+	projects, err := m.List(projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+	projects = projector.FilterByTag(projects, menuTag)
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects found")
+	}
+	sortProjects(projects, cfg)
+
+	lines, entries := menuEntries(projects)
+
+	var input bytes.Buffer
+	for _, line := range lines {
+		input.WriteString(line)
+		if icon := menuKindIcons[entries[line].Kind]; launcher.icons && icon != "" {
+			input.WriteString("\x00icon\x1f" + icon)
+		}
+		input.WriteString("\n")
+	}
+
+	c := exec.Command(launcher.args[0], launcher.args[1:]...)
+	c.Stdin = &input
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		// Launchers exit non-zero when the menu is dismissed
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to run %s: %w", name, err)
+	}
+
+	choice := strings.TrimRight(string(out), "\r\n")
+	if choice == "" {
+		return nil
+	}
+	project, ok := entries[choice]
+	if !ok {
+		return fmt.Errorf("unknown selection: %s", choice)
+	}
+
+	if menuTerminal {
+		return openInTerminal(project, cfg.TerminalCommand)
+	}
+	return m.Open(project, projector.OpenOptions{})
+}
+
+// resolveMenuBackend returns the requested launcher after checking it is
+// supported, or the first supported launcher found with lookPath
+func resolveMenuBackend(requested string, lookPath func(string) (string, error)) (string, error) {
+	if requested != "" {
+		if _, ok := menuLaunchers[requested]; !ok {
+			return "", fmt.Errorf("unsupported backend '%s' (supported: %s)", requested, strings.Join(menuLauncherOrder, ", "))
+		}
+		return requested, nil
+	}
+	for _, name := range menuLauncherOrder {
+		if _, err := lookPath(menuLaunchers[name].args[0]); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no launcher found (install one of %s or pass --backend)", strings.Join(menuLauncherOrder, ", "))
+}
+
+// menuEntries returns the launcher lines for projects, prefixed with their
+// kind, and the project each line selects. Lines that would be identical
+// get the project path appended.
+func menuEntries(projects []*models.Project) ([]string, map[string]*models.Project) {
+	label := func(p *models.Project) string {
+		prefix := menuKindPrefixes[p.Kind]
+		if prefix == "" {
+			return p.Name
+		}
+		return prefix + "  " + p.Name
+	}
+
+	counts := make(map[string]int, len(projects))
+	for _, p := range projects {
+		counts[label(p)]++
+	}
+
+	lines := make([]string, 0, len(projects))
+	entries := make(map[string]*models.Project, len(projects))
+	for _, p := range projects {
+		line := label(p)
+		if counts[line] > 1 {
+			line += "  (" + p.RootPath + ")"
+		}
+		if _, dup := entries[line]; dup {
+			continue
+		}
+		lines = append(lines, line)
+		entries[line] = p
+	}
+	return lines, entries
+}
@@ -2,59 +2,80 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
 	"github.com/ideaspaper/projector/pkg/storage"
 )
 
-// Editor constants for supported editors
-const (
-	EditorCode     = "code"
-	EditorVSCode   = "vscode"
-	EditorCursor   = "cursor"
-	EditorSublime  = "subl"
-	EditorSublAlt  = "sublime"
-	EditorAtom     = "atom"
-	EditorVim      = "vim"
-	EditorNeoVim   = "nvim"
-	EditorEmacs    = "emacs"
-	EditorIdea     = "idea"
-	EditorIntelliJ = "intellij"
-	EditorWebStorm = "webstorm"
-	EditorGoLand   = "goland"
-	EditorPyCharm  = "pycharm"
-	EditorOpen     = "open"     // macOS
-	EditorXdgOpen  = "xdg-open" // Linux
-	EditorExplorer = "explorer" // Windows
-)
-
 var (
-	openNewWindow bool
-	openEditor    string
-	openTag       string
-	openGrouped   bool
-	openFavorites bool
-	openGit       bool
-	openSVN       bool
-	openMercurial bool
-	openVSCode    bool
-	openAny       bool
+	openNewWindow    bool
+	openTerminal     bool
+	openReveal       bool
+	openEditor       string
+	openTag          string
+	openLanguage     string
+	openGrouped      bool
+	openTypes        typeFlags
+	openFile         string
+	openBest         bool
+	openInteractive  bool
+	openRoot         bool
+	openFilters      []string
+	openRestore      bool
+	openDevContainer bool
+	openMountHint    bool
 )
 
 // openCmd represents the open command
 var openCmd = &cobra.Command{
-	Use:   "open [project-name]",
+	Use:   "open [project-name[:file[:line]]]",
 	Short: "Open a project in your editor",
 	Long: `Open a project in your configured editor (default: VS Code).
 
 If no project name is provided, an interactive selection is shown.
+A name that is not exact matches projects containing it, or failing
+that projects whose name contains its letters in order, ranked
+fzf-style. When several match, --best opens the highest-ranked one and
+--interactive shows the selection limited to the matches. The name "-"
+opens the project opened before the last one, like "cd -".
+
+Append ":path/to/file" to the project name (or use --file) to open a file
+relative to the project root, and ":line" to jump to a line in editors
+that support it.
+
+For a subproject (see 'projector add --parent'), --root opens the folder
+of its parent, such as the monorepo root, instead. A subproject picked
+from the interactive selection asks which of the two to open.
+
+With rememberContext set in the config, opening a project records the
+Git branch it is on and, in vim or neovim, the editor session. The next
+open offers to check that branch out again if another one is checked
+out, and to load the session; --restore does both without asking.
+
+Projects with a .devcontainer/devcontainer.json (or .devcontainer.json)
+open inside their dev container with --devcontainer, through
+'devcontainer open' when installed or VS Code's dev container URI.
+
+Set openStrategies in the config to open each kind of project its own
+way when no flag says how, e.g. VS Code workspaces by their workspace
+file, plain folders in a terminal and Git repositories in an editor:
+
+  "openStrategies": {"vscode": "workspace", "any": "terminal", "git": "editor:nvim"}
+
+Projects on a removable drive or network share that is not mounted are
+offline. Opening one fails, unless --mount-hint is given: then you are
+asked to connect the drive, and the project opens once it is mounted.
 
 Examples:
   # Open a project by name
@@ -63,11 +84,44 @@ Examples:
   # Open in a new window
   projector open myproject --new-window
 
+  # Open the best match for "api" (e.g. "api" over "legacy-api-v1")
+  projector open api --best
+
   # Open with a specific editor
   projector open myproject --editor vim
 
+  # Open a file at line 42
+  projector open myproject:cmd/root.go:42
+
+  # Open a file in the current directory's project
+  projector open . --file README.md
+
+  # Go back to the previously opened project
+  projector open -
+
+  # Open the monorepo containing the billing subproject
+  projector open billing --root
+
+  # Open on the branch and vim session it was left in (rememberContext)
+  projector open myproject --editor nvim --restore
+
+  # Open a container-first repository inside its dev container
+  projector open myproject --devcontainer
+
+  # Open a project on an external drive, waiting for the drive to be connected
+  projector open myproject --mount-hint
+
+  # Open a terminal at the project root instead of an editor
+  projector open myproject --terminal
+
+  # Show the project folder in Finder, Explorer or the desktop's file manager
+  projector open myproject --reveal
+
   # Filter interactive selection by tag
-  projector open --tag Work`,
+  projector open --tag Work
+
+  # Pick among the Git repositories tagged Work or OSS
+  projector open --filter 'kind==git && tag in (Work,OSS)'`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runOpen,
 }
@@ -77,48 +131,63 @@ func init() {
 
 	openCmd.Flags().BoolVarP(&openNewWindow, "new-window", "n", false, "open in a new window")
 	openCmd.Flags().StringVarP(&openEditor, "editor", "e", "", "editor to use (overrides config)")
+	openCmd.Flags().BoolVarP(&openTerminal, "terminal", "T", false, "open a terminal at the project root instead of an editor")
+	openCmd.MarkFlagsMutuallyExclusive("editor", "terminal")
+	openCmd.Flags().StringVarP(&openFile, "file", "f", "", "open a file relative to the project root (optionally file:line)")
+	openCmd.MarkFlagsMutuallyExclusive("file", "terminal")
+	openCmd.Flags().BoolVarP(&openReveal, "reveal", "R", false, "open the project folder in the file manager instead of an editor")
+	openCmd.MarkFlagsMutuallyExclusive("reveal", "terminal")
+	openCmd.MarkFlagsMutuallyExclusive("reveal", "editor")
+	openCmd.MarkFlagsMutuallyExclusive("reveal", "file")
+	openCmd.Flags().BoolVar(&openBest, "best", false, "open the best-ranked project when several match the name")
+	openCmd.Flags().BoolVarP(&openInteractive, "interactive", "i", false, "choose among the projects matching the name")
+	openCmd.MarkFlagsMutuallyExclusive("best", "interactive")
+	openCmd.Flags().BoolVar(&openDevContainer, "devcontainer", false, "open the project inside its dev container")
+	openCmd.MarkFlagsMutuallyExclusive("devcontainer", "terminal")
+	openCmd.MarkFlagsMutuallyExclusive("devcontainer", "reveal")
+	openCmd.MarkFlagsMutuallyExclusive("devcontainer", "file")
+	openCmd.Flags().BoolVar(&openRestore, "restore", false, "check out the last branch and load the last vim session without asking (with rememberContext)")
+	openCmd.Flags().BoolVar(&openMountHint, "mount-hint", false, "when the project's volume is not mounted, ask to mount it and wait")
+	openCmd.Flags().BoolVar(&openRoot, "root", false, "open the parent project (e.g. the monorepo root) of a subproject")
 	openCmd.Flags().StringVarP(&openTag, "tag", "t", "", "filter projects by tag")
+	openCmd.Flags().StringArrayVar(&openFilters, "filter", nil, "filter projects with an expression, e.g. 'kind==git && tag in (Work,OSS)' (can be used multiple times)")
+	openCmd.Flags().StringVar(&openLanguage, "language", "", "filter projects by detected language (e.g. go, rust)")
 	openCmd.Flags().BoolVarP(&openGrouped, "grouped", "g", false, "group projects by type")
-	openCmd.Flags().BoolVar(&openFavorites, "favorites", false, "show only favorites")
-	openCmd.Flags().BoolVar(&openGit, "git", false, "show only git repositories")
-	openCmd.Flags().BoolVar(&openSVN, "svn", false, "show only svn repositories")
-	openCmd.Flags().BoolVar(&openMercurial, "mercurial", false, "show only mercurial repositories")
-	openCmd.Flags().BoolVar(&openVSCode, "vscode", false, "show only vscode workspaces")
-	openCmd.Flags().BoolVar(&openAny, "any", false, "show only any-folder projects")
+	openTypes.register(openCmd)
 }
 
 func runOpen(cmd *cobra.Command, args []string) error {
 	// Load config
-	cfg, err := config.LoadOrCreateConfig()
+	cfg, err := app.Config()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	m, err := app.Manager()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 
 	// Load projects with type filter
-	filter := TypeFilter{
-		Favorites: openFavorites,
-		Git:       openGit,
-		SVN:       openSVN,
-		Mercurial: openMercurial,
-		VSCode:    openVSCode,
-		Any:       openAny,
-	}
-	allProjects, err := LoadFilteredProjects(store, filter)
+	filter, err := openTypes.filter()
+	if err != nil {
+		return err
+	}
+	allProjects, err := loadProjects(m.Storage(), filter)
 	if err != nil {
 		return err
 	}
 
 	// Filter enabled only
-	allProjects = FilterEnabled(allProjects)
+	allProjects = projector.FilterEnabled(allProjects)
 
 	// Filter by tag if specified
-	allProjects = FilterByTag(allProjects, openTag)
+	allProjects = projector.FilterByTag(allProjects, openTag)
+	allProjects = projector.FilterByLanguage(allProjects, openLanguage)
+	if allProjects, err = filterByQueries(allProjects, openFilters); err != nil {
+		return err
+	}
 
 	if len(allProjects) == 0 {
 		return fmt.Errorf("no projects found")
@@ -126,75 +195,171 @@ func runOpen(cmd *cobra.Command, args []string) error {
 
 	// Find project
 	var selectedProject *models.Project
+	picked := false
 
+	file := openFile
 	if len(args) > 0 {
-		projectName := args[0]
-
-		// First try exact match
-		for _, p := range allProjects {
-			if strings.EqualFold(p.Name, projectName) {
-				selectedProject = p
-				break
+		projectName, argFile := splitProjectFile(args[0], func(name string) bool {
+			project, matches, err := findProject(cfg, m.Storage(), allProjects, name)
+			return (err == nil && project != nil) || len(matches) > 0
+		})
+		if argFile != "" {
+			if file != "" {
+				return fmt.Errorf("cannot combine project:file with --file")
 			}
+			if openTerminal {
+				return fmt.Errorf("cannot open a file with --terminal")
+			}
+			if openReveal {
+				return fmt.Errorf("cannot open a file with --reveal")
+			}
+			file = argFile
 		}
 
-		// If no exact match, try partial match
-		if selectedProject == nil {
-			var matches []*models.Project
-			for _, p := range allProjects {
-				if strings.Contains(strings.ToLower(p.Name), strings.ToLower(projectName)) {
-					matches = append(matches, p)
-				}
+		project, matches, err := findProject(cfg, m.Storage(), allProjects, projectName)
+		switch {
+		case len(matches) > 1 && openBest:
+			selectedProject = matches[0]
+		case len(matches) > 1 && openInteractive:
+			// Pick among the matches only
+			selectedProject, err = selectProjectInteractive(cmd, matches, cfg)
+			if err != nil {
+				return err
 			}
-
-			if len(matches) == 1 {
-				selectedProject = matches[0]
-			} else if len(matches) > 1 {
-				// Multiple matches - show selection
-				formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-				fmt.Println(formatter.FormatWarning(fmt.Sprintf("Multiple projects match '%s':", projectName)))
-				for _, p := range matches {
-					fmt.Printf("  - %s (%s)\n", p.Name, p.RootPath)
-				}
-				return fmt.Errorf("please be more specific")
-			} else {
-				return fmt.Errorf("project '%s' not found", projectName)
+			picked = true
+		case len(matches) > 1:
+			formatter := app.Formatter()
+			formatter.FprintWarning(app.Err(), fmt.Sprintf("Multiple projects match '%s':", projectName))
+			for _, p := range matches {
+				fmt.Fprintf(app.Err(), "  - %s (%s)\n", p.Name, p.RootPath)
 			}
+			return withExitCode(exitAmbiguous, fmt.Errorf("please be more specific (or use --best or --interactive)"))
+		case err != nil:
+			return err
+		default:
+			selectedProject = project
 		}
 	} else {
 		// Interactive selection
+		allProjects = withoutCurrentProject(cfg, allProjects)
+		if len(allProjects) == 0 {
+			return fmt.Errorf("no projects found")
+		}
 		selectedProject, err = selectProjectInteractive(cmd, allProjects, cfg)
 		if err != nil {
 			return err
 		}
+		picked = true
+	}
+
+	if selectedProject, err = monorepoRoot(m.Storage(), selectedProject, openRoot, picked && !openRoot); err != nil {
+		return err
+	}
+
+	// Verify path exists, or wait for its volume to be mounted
+	if selectedProject.Offline && openMountHint {
+		if err := waitForVolume(selectedProject); err != nil {
+			return err
+		}
+	}
+	if err := projector.PathError(selectedProject); err != nil {
+		if selectedProject.Offline {
+			err = fmt.Errorf("%w (connect it, or use --mount-hint to wait for it)", err)
+		}
+		return err
+	}
+
+	formatter := app.Formatter()
+
+	// Without a flag saying how, the project opens as set for its kind
+	terminal, reveal, devContainer, editorName := openTerminal, openReveal, openDevContainer, openEditor
+	workspace := false
+	if !terminal && !reveal && !devContainer && editorName == "" {
+		strategy, strategyEditor, err := projector.OpenStrategyFor(cfg, selectedProject)
+		if err != nil {
+			return err
+		}
+		switch strategy {
+		case config.OpenWorkspace:
+			workspace = true
+		case config.OpenInTerminal:
+			terminal = file == ""
+		case config.OpenInFileManager:
+			reveal = file == ""
+		case config.OpenInDevContainer:
+			devContainer = file == ""
+		}
+		editorName = strategyEditor
+	}
+
+	if terminal {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening terminal at '%s'...", selectedProject.Name)))
+		return openInTerminal(selectedProject, cfg.TerminalCommand)
+	}
+
+	if reveal {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening '%s' in the file manager...", selectedProject.Name)))
+		return m.Open(selectedProject, projector.OpenOptions{Reveal: true})
+	}
+
+	if devContainer {
+		if file != "" {
+			return fmt.Errorf("cannot open a file with --devcontainer")
+		}
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening '%s' in its dev container...", selectedProject.Name)))
+		return m.Open(selectedProject, projector.OpenOptions{Editor: editorName, DevContainer: true})
+	}
+
+	// Determine editor, resolving "auto" so the message names the one used
+	if editorName == "" {
+		editorName = cfg.Editor
+	}
+	if editorName == editor.Auto {
+		e, err := app.Editors().Resolve(editorName)
+		if err != nil {
+			return err
+		}
+		editorName = e.Name()
 	}
 
-	// Verify path exists
-	if _, err := os.Stat(selectedProject.RootPath); os.IsNotExist(err) {
-		return fmt.Errorf("project path does not exist: %s", selectedProject.RootPath)
+	opts := projector.OpenOptions{Editor: editorName, NewWindow: openNewWindow, Workspace: workspace}
+	if cfg.RememberContext {
+		// A session restores its own files, so it is not offered for --file
+		sessionEditor := editorName
+		if file != "" {
+			sessionEditor = ""
+		}
+		opts.RestoreSession = restoreContext(m, selectedProject, sessionEditor, openRestore)
 	}
 
-	// Determine editor
-	editor := openEditor
-	if editor == "" {
-		editor = cfg.Editor
+	// Open a file within the project
+	if file != "" {
+		opts.File, opts.Line = splitFileLine(file)
+		if _, err := projector.ResolveProjectFile(selectedProject.RootPath, opts.File); err != nil {
+			return err
+		}
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening '%s' from '%s' in %s...", opts.File, selectedProject.Name, editorName)))
+		return m.Open(selectedProject, opts)
 	}
 
 	// Open project
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-	fmt.Println(formatter.FormatInfo(fmt.Sprintf("Opening '%s' in %s...", selectedProject.Name, editor)))
+	printStatus(formatter.FormatInfo(fmt.Sprintf("Opening '%s' in %s...", selectedProject.Name, editorName)))
 
-	return openInEditor(selectedProject.RootPath, editor, openNewWindow || cfg.OpenInNewWindow)
+	return m.Open(selectedProject, opts)
 }
 
 // selectProjectInteractive shows an interactive selection menu
 func selectProjectInteractive(cmd *cobra.Command, projects []*models.Project, cfg *config.Config) (*models.Project, error) {
+	if noInput {
+		return nil, fmt.Errorf("%w; give a project name", errNoInput)
+	}
+
 	// Sort according to config
-	sortProjects(projects, cfg.SortList)
+	sortProjects(projects, cfg)
 
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-	fmt.Println("Select a project to open:")
-	fmt.Println()
+	formatter := app.Formatter()
+	fmt.Fprintln(app.Out(), "Select a project to open:")
+	fmt.Fprintln(app.Out())
 
 	// Determine grouping: flag takes precedence if explicitly set
 	grouped := cfg.GroupList
@@ -204,116 +369,163 @@ func selectProjectInteractive(cmd *cobra.Command, projects []*models.Project, cf
 
 	// Use grouped display based on config
 	opts := output.ListOptions{
-		ShowPath:  false,
-		ShowIndex: true,
-		Grouped:   grouped,
+		ShowPath:        false,
+		ShowIndex:       true,
+		Grouped:         grouped,
+		ShowDescription: true,
 	}
+	pickerUsage(cfg, projects, &opts)
 	listOutput, indexedProjects := formatter.FormatProjectList(projects, opts)
-	fmt.Println(listOutput)
-	fmt.Println()
+	fmt.Fprintln(app.Out(), listOutput)
+	fmt.Fprintln(app.Out())
 
-	fmt.Print("Enter project number (or 'q' to quit): ")
-	input, err := ReadUserInput()
+	selected, err := readPickerSelection(app.Out(), cfg, "Enter project number", ReadUserInput, func(input string) ([]*models.Project, error) {
+		var index int
+		if _, err := fmt.Sscanf(input, "%d", &index); err != nil {
+			return nil, fmt.Errorf("invalid selection")
+		}
+
+		// Convert 1-based input to 0-based index
+		index--
+		if index < 0 || index >= len(indexedProjects) {
+			return nil, fmt.Errorf("invalid selection: index out of range")
+		}
+		return []*models.Project{indexedProjects[index]}, nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return selected[0], nil
+}
 
-	if input == "q" || input == "Q" {
-		os.Exit(0)
+// monorepoRoot returns the parent of a subproject when root is set or,
+// with ask, when the user chooses it over the subproject. Other projects
+// are returned as they are.
+func monorepoRoot(store *storage.Storage, project *models.Project, root, ask bool) (*models.Project, error) {
+	if project.Parent == "" {
+		if root {
+			return nil, fmt.Errorf("project '%s' is not a subproject (see 'projector add --parent')", project.Name)
+		}
+		return project, nil
 	}
-
-	var index int
-	if _, err := fmt.Sscanf(input, "%d", &index); err != nil {
-		return nil, fmt.Errorf("invalid selection")
+	if !root && !ask {
+		return project, nil
 	}
 
-	// Convert 1-based input to 0-based index
-	index--
-	if index < 0 || index >= len(indexedProjects) {
-		return nil, fmt.Errorf("invalid selection: index out of range")
+	all, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return nil, err
+	}
+	parent := projector.FindParent(all, project)
+	if parent == nil {
+		if root {
+			return nil, &projector.NotFoundError{Name: project.Parent}
+		}
+		return project, nil
+	}
+	if root {
+		return parent, nil
 	}
 
-	return indexedProjects[index], nil
+	fmt.Fprintf(app.Out(), "Open '%s' or its parent '%s'? [S]ubproject/[r]oot: ", project.Name, parent.Name)
+	input, err := ReadUserInput()
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(input, "r") || strings.EqualFold(input, "root") {
+		return parent, nil
+	}
+	return project, nil
 }
 
-// openInEditor opens a path in the specified editor
-func openInEditor(path, editor string, newWindow bool) error {
-	var cmd *exec.Cmd
-
-	switch editor {
-	case EditorCode, EditorVSCode:
-		args := []string{path}
-		if newWindow {
-			args = append([]string{"--new-window"}, args...)
+// restoreContext offers to check out the branch project was on when it
+// was last opened, and reports whether to load the vim session saved for
+// it when editorName keeps sessions. With restore both happen without
+// asking; with --no-input neither does.
+func restoreContext(m *projector.Manager, project *models.Project, editorName string, restore bool) bool {
+	saved, err := m.SavedContext(project)
+	if err != nil || saved == nil {
+		return false
+	}
+	formatter := app.Formatter()
+	confirm := func(question string, yes bool) bool {
+		if restore || noInput {
+			return restore
 		}
-		cmd = exec.Command(EditorCode, args...)
-
-	case EditorCursor:
-		args := []string{path}
-		if newWindow {
-			args = append([]string{"--new-window"}, args...)
+		fmt.Fprint(app.Out(), question)
+		input, err := ReadUserInput()
+		if err != nil {
+			return false
 		}
-		cmd = exec.Command(EditorCursor, args...)
-
-	case EditorSublime, EditorSublAlt:
-		args := []string{path}
-		if newWindow {
-			args = append([]string{"--new-window"}, args...)
+		if input == "" {
+			return yes
 		}
-		cmd = exec.Command(EditorSublime, args...)
+		return strings.EqualFold(input, "y") || strings.EqualFold(input, "yes")
+	}
 
-	case EditorAtom:
-		args := []string{path}
-		if newWindow {
-			args = append([]string{"--new-window"}, args...)
+	current := scanner.CurrentBranch(project.RootPath)
+	if saved.Branch != "" && current != "" && current != saved.Branch &&
+		confirm(fmt.Sprintf("'%s' was on branch '%s' when last opened, now '%s'. Check it out? [y/N]: ", project.Name, saved.Branch, current), false) {
+		out, err := exec.Command("git", "-C", project.RootPath, "checkout", saved.Branch).CombinedOutput()
+		if err != nil {
+			printStatus(formatter.FormatWarning(fmt.Sprintf("Could not check out '%s': %s", saved.Branch, strings.TrimSpace(string(out)))))
+		} else {
+			printStatus(formatter.FormatSuccess(fmt.Sprintf("Checked out '%s'", saved.Branch)))
 		}
-		cmd = exec.Command(EditorAtom, args...)
-
-	case EditorVim, EditorNeoVim:
-		cmd = exec.Command(editor, path)
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-	case EditorEmacs:
-		cmd = exec.Command(EditorEmacs, path)
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-	case EditorIdea, EditorIntelliJ:
-		cmd = exec.Command(EditorIdea, path)
-
-	case EditorWebStorm:
-		cmd = exec.Command(EditorWebStorm, path)
-
-	case EditorGoLand:
-		cmd = exec.Command(EditorGoLand, path)
-
-	case EditorPyCharm:
-		cmd = exec.Command(EditorPyCharm, path)
+	}
 
-	case EditorOpen:
-		// macOS open command
-		cmd = exec.Command(EditorOpen, path)
+	e, ok := app.Editors().Lookup(editorName)
+	if saved.Session == "" || !ok || !editor.KeepsSessions(e) || !paths.Exists(saved.Session) {
+		return false
+	}
+	return confirm(fmt.Sprintf("Restore the %s session of '%s'? [Y/n]: ", e.Name(), project.Name), true)
+}
 
-	case EditorXdgOpen:
-		// Linux open command
-		cmd = exec.Command(EditorXdgOpen, path)
+// waitForVolume asks to mount the volume of an offline project until it
+// is mounted. "q" cancels, and with --no-input it fails right away.
+func waitForVolume(project *models.Project) error {
+	formatter := app.Formatter()
+	for !paths.VolumeMounted(project.Volume) {
+		if noInput {
+			return fmt.Errorf("%w; '%s' is offline until %s is mounted", errNoInput, project.Name, project.Volume)
+		}
+		formatter.FprintWarning(app.Out(), fmt.Sprintf("'%s' is on %s, which is not mounted", project.Name, project.Volume))
+		fmt.Fprint(app.Out(), "Connect or mount it, then press Enter to open the project (q to cancel): ")
+		input, err := ReadUserInput()
+		if err != nil || strings.EqualFold(input, "q") {
+			return errCancelled
+		}
+	}
+	project.Offline = false
+	return nil
+}
 
-	case EditorExplorer:
-		// Windows Explorer
-		cmd = exec.Command(EditorExplorer, path)
+// openInEditor opens a path in the specified editor
+func openInEditor(path, name string, newWindow bool) error {
+	return app.Editors().OpenPath(name, path, editor.Options{NewWindow: newWindow})
+}
 
-	default:
-		// Try to run the editor directly
-		cmd = exec.Command(editor, path)
+// splitProjectFile splits a "project:path/to/file[:line]" argument into
+// the project name and the file reference. Arguments without a colon, and
+// arguments that match a project as a whole, such as a name containing a
+// colon, are returned unchanged with an empty file.
+func splitProjectFile(arg string, matches func(name string) bool) (name, file string) {
+	name, file, found := strings.Cut(arg, ":")
+	if !found || name == "" || matches(arg) {
+		return arg, ""
 	}
+	return name, file
+}
 
-	// For GUI editors, don't wait
-	if editor != EditorVim && editor != EditorNeoVim && editor != EditorEmacs {
-		return cmd.Start()
+// splitFileLine splits an optional ":line" suffix off a file reference
+func splitFileLine(file string) (string, int) {
+	i := strings.LastIndex(file, ":")
+	if i < 0 {
+		return file, 0
 	}
-
-	return cmd.Run()
+	line, err := strconv.Atoi(file[i+1:])
+	if err != nil || line <= 0 {
+		return file, 0
+	}
+	return file[:i], line
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/config"
+)
+
+// defaultPager is used when neither the pager setting nor $PAGER is set
+const defaultPager = "less -R"
+
+// pagerCommand returns the pager to use for cfg, or nil when output should
+// not be paged. A pager of "cat" or "" (after $PAGER) disables paging.
+func pagerCommand(cfg *config.Config) []string {
+	if noPager || !cfg.UsePager {
+		return nil
+	}
+	pager := cfg.Pager
+	if pager == "" {
+		pager = os.Getenv("PAGER")
+	}
+	if pager == "" {
+		pager = defaultPager
+	}
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return nil
+	}
+	return fields
+}
+
+// printPaged prints text, piping it through the pager when stdout is a
+// terminal and text does not fit on one screen. If the pager cannot be
+// started, or output was redirected with rootCmd.SetOut, text is printed
+// directly.
+func printPaged(cfg *config.Config, text string) {
+	pager := pagerCommand(cfg)
+	_, height := terminalSize()
+	if pager == nil || height == 0 || app.Out() != os.Stdout || strings.Count(text, "\n") < height {
+		fmt.Fprint(app.Out(), text)
+		return
+	}
+
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Like git, let less keep colors and quit when the output fits
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// The pager ran; quitting it early is not an error
+			return
+		}
+		slog.Debug("pager failed, printing directly", "pager", pager[0], "error", err)
+		fmt.Fprint(app.Out(), text)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+var (
+	pathTag      string
+	pathLanguage string
+	pathTypes    typeFlags
+	pathBest     bool
+	pathFilters  []string
+)
+
+// pathCmd represents the path command
+var pathCmd = &cobra.Command{
+	Use:   "path <project-name>",
+	Short: "Print the path of a project",
+	Long: `Print the path of the project matching a name, for scripts and shell
+aliases.
+
+Names are matched like in 'projector select <name>', but path never
+prompts: when several projects match it lists them on stderr and exits
+with code 3, unless --best picks the highest-ranked one. A missing
+project exits with code 2 and a project whose folder is gone with code 4,
+so nothing but the path is ever printed to stdout.
+
+Examples:
+  cd "$(projector path api)"
+
+  # Best fuzzy match
+  alias cdapi='cd "$(projector path api --best)"'
+
+  # Path of the previously opened project
+  projector path -`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPath,
+}
+
+func init() {
+	rootCmd.AddCommand(pathCmd)
+
+	pathCmd.Flags().BoolVar(&pathBest, "best", false, "print the best-ranked project when several match the name")
+	pathCmd.Flags().StringVarP(&pathTag, "tag", "t", "", "filter projects by tag")
+	pathCmd.Flags().StringArrayVar(&pathFilters, "filter", nil, "filter projects with an expression, e.g. 'kind==git && tag in (Work,OSS)' (can be used multiple times)")
+	pathCmd.Flags().StringVar(&pathLanguage, "language", "", "filter projects by detected language (e.g. go, rust)")
+	pathTypes.register(pathCmd)
+}
+
+func runPath(cmd *cobra.Command, args []string) error {
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	filter, err := pathTypes.filter()
+	if err != nil {
+		return err
+	}
+	projects, err := loadProjects(store, filter)
+	if err != nil {
+		return err
+	}
+	projects = projector.FilterEnabled(projects)
+	projects = projector.FilterByTag(projects, pathTag)
+	projects = projector.FilterByLanguage(projects, pathLanguage)
+	if projects, err = filterByQueries(projects, pathFilters); err != nil {
+		return err
+	}
+
+	project, matches, err := findProject(cfg, store, projects, args[0])
+	switch {
+	case len(matches) > 1 && pathBest:
+		project = matches[0]
+	case len(matches) > 1:
+		formatter := app.Formatter()
+		formatter.FprintWarning(app.Err(), fmt.Sprintf("Multiple projects match '%s':", args[0]))
+		for _, p := range matches {
+			fmt.Fprintf(app.Err(), "  - %s (%s)\n", p.Name, p.RootPath)
+		}
+		return withExitCode(exitAmbiguous, fmt.Errorf("please be more specific (or use --best)"))
+	case err != nil:
+		return err
+	}
+
+	if err := projector.PathError(project); err != nil {
+		return err
+	}
+	fmt.Fprintln(app.Out(), project.RootPath)
+	return nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// pickerAction acts on one project at an interactive picker instead of
+// selecting it
+type pickerAction struct {
+	key  string
+	help string
+	run  func(w io.Writer, project *models.Project, read func() (string, error)) error
+}
+
+// pickerActions are entered before a project number, as in "t 3"
+var pickerActions = []pickerAction{
+	{"p", "print its path", pickerPrintPath},
+	{"t", "edit its tags", pickerEditTags},
+	{"f", "add it to or remove it from favorites", pickerToggleFavorite},
+	{"d", "disable it", pickerDisable},
+}
+
+// parsePickerAction returns the action entered in input, such as "t 3" or
+// "t3", and the project number after its key
+func parsePickerAction(input string) (*pickerAction, string, bool) {
+	for i := range pickerActions {
+		a := &pickerActions[i]
+		rest, ok := strings.CutPrefix(strings.ToLower(input), a.key)
+		if rest = strings.TrimSpace(rest); ok && rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+			return a, rest, true
+		}
+	}
+	return nil, "", false
+}
+
+// writePickerHelp lists the picker actions
+func writePickerHelp(w io.Writer) {
+	fmt.Fprintln(w, "Actions, entered before a project number (e.g. t 3):")
+	for _, a := range pickerActions {
+		fmt.Fprintf(w, "  %s N  %s\n", a.key, a.help)
+	}
+}
+
+func pickerPrintPath(w io.Writer, project *models.Project, read func() (string, error)) error {
+	fmt.Fprintln(w, project.RootPath)
+	return nil
+}
+
+// pickerEditTags replaces the tags of a project with the ones entered.
+// Tags of auto-detected projects do not survive a rescan, so such a
+// project is added to favorites with the tags.
+func pickerEditTags(w io.Writer, project *models.Project, read func() (string, error)) error {
+	fmt.Fprintf(w, "Tags for %s, comma-separated ('-' to clear) [%s]: ", project.Name, strings.Join(project.Tags, ", "))
+	answer, err := read()
+	if err != nil || answer == "" {
+		return err
+	}
+	tags := []string{}
+	if answer != "-" {
+		for _, tag := range strings.Split(answer, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+	var added bool
+	err = store.Update(func(favorites *models.ProjectList) error {
+		favorite := favorites.FindByPath(project.RootPath)
+		if added = favorite == nil; added {
+			if favorites.FindByName(project.Name) != nil {
+				return fmt.Errorf("a favorite named '%s' already exists", project.Name)
+			}
+			favorite = favoriteCopies([]*models.Project{project})[0]
+			favorites.Add(favorite)
+		}
+		favorite.Tags = tags
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	project.Tags = append([]string{}, tags...)
+
+	msg := fmt.Sprintf("Tags of '%s': %s", project.Name, strings.Join(tags, ", "))
+	if len(tags) == 0 {
+		msg = fmt.Sprintf("Removed the tags of '%s'", project.Name)
+	}
+	if added {
+		msg += " (added to favorites)"
+	}
+	fmt.Fprintln(w, app.Formatter().FormatSuccess(msg))
+	return nil
+}
+
+// pickerToggleFavorite adds a project to favorites, or removes it when it
+// is one
+func pickerToggleFavorite(w io.Writer, project *models.Project, read func() (string, error)) error {
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+	msg := fmt.Sprintf("Added '%s' to favorites", project.Name)
+	err = store.Update(func(favorites *models.ProjectList) error {
+		kept := favorites.Projects[:0]
+		for _, p := range favorites.Projects {
+			if !paths.Equal(p.RootPath, project.RootPath) {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) < len(favorites.Projects) {
+			favorites.Projects = kept
+			msg = fmt.Sprintf("Removed '%s' from favorites", project.Name)
+		} else if added, _ := mergeImportedProjects(favorites, favoriteCopies([]*models.Project{project})); added == 0 {
+			return fmt.Errorf("a favorite named '%s' already exists", project.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, app.Formatter().FormatSuccess(msg))
+	return nil
+}
+
+// pickerDisable disables a project in favorites and the cache, wherever
+// its folder is listed
+func pickerDisable(w io.Writer, project *models.Project, read func() (string, error)) error {
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+	cache, err := store.LoadCache()
+	if err != nil {
+		cache = &storage.CachedProjects{}
+	}
+
+	disable := func(projects []*models.Project) bool {
+		changed := false
+		for _, p := range projects {
+			if p.Enabled && paths.Equal(p.RootPath, project.RootPath) {
+				p.Enabled = false
+				changed = true
+			}
+		}
+		return changed
+	}
+	err = store.Update(func(favorites *models.ProjectList) error {
+		if !disable(favorites.Projects) {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if disable(toggleCandidates(models.NewProjectList(models.KindFavorite), cache, projector.TypeFilter{})) {
+		if err := store.SaveCache(cache); err != nil {
+			return fmt.Errorf("failed to save cache: %w", err)
+		}
+	}
+	project.Enabled = false
+	fmt.Fprintln(w, app.Formatter().FormatSuccess(fmt.Sprintf("Disabled '%s'", project.Name)))
+	return nil
+}
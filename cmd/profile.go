@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/output"
+)
+
+// profileCmd groups commands that manage profiles
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage profiles with separate config and projects",
+	Long: `Profiles keep separate sets of projects, each with its own config.json,
+projects.json and cache. The default profile lives in ~/.projector and
+other profiles in ~/.projector/profiles/<name>.
+
+The active profile is chosen by, in order: the --profile flag, the
+PROJECTOR_PROFILE environment variable, and 'projector profile use'.
+
+Examples:
+  # Create a profile for client work and switch to it
+  projector profile create acme
+  projector profile use acme
+
+  # Run a single command in another profile
+  projector --profile personal list
+
+  # Go back to the default profile
+  projector profile use default`,
+	Args: cobra.NoArgs,
+}
+
+// profileListCmd represents the profile list command
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List profiles, marking the active one",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runProfileList,
+}
+
+// profileCurrentCmd represents the profile current command
+var profileCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Print the active profile",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintln(app.Out(), activeProfile())
+		return nil
+	},
+}
+
+// profileCreateCmd represents the profile create command
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile with a default config",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileCreate,
+}
+
+// profileUseCmd represents the profile use command
+var profileUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Make a profile the active one",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileNames,
+	RunE:              runProfileUse,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileCurrentCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileUseCmd)
+}
+
+// completeProfileNames completes the first argument with profile names
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, err := config.ListProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// activeProfile returns the profile given with --profile, else the one
+// config.ActiveProfile selects
+func activeProfile() string {
+	if profile != "" {
+		return profile
+	}
+	return config.ActiveProfile()
+}
+
+// profileFormatter returns a formatter honoring the colors setting and theme
+// of a profile; an empty name uses the active profile
+func profileFormatter(name string) *output.Formatter {
+	cfg, err := config.LoadOrCreateConfig(config.LoadOptions{Profile: name})
+	if err != nil {
+		return output.NewFormatter(!noColor, output.FormatterOptions{})
+	}
+	return output.NewFormatter(!noColor && cfg.ShowColors, formatterOptions(cfg, app.Err()))
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	names, err := config.ListProfiles()
+	if err != nil {
+		return err
+	}
+
+	active := activeProfile()
+	for _, name := range names {
+		if name == active {
+			fmt.Fprintf(app.Out(), "* %s\n", name)
+		} else {
+			fmt.Fprintf(app.Out(), "  %s\n", name)
+		}
+	}
+	return nil
+}
+
+func runProfileCreate(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := config.CreateProfile(name); err != nil {
+		return err
+	}
+
+	dir, _ := config.ProfileDir(name)
+	formatter := profileFormatter(profile)
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Created profile '%s' in %s", name, dir)))
+	printStatus(formatter.FormatInfo(fmt.Sprintf("Switch to it with 'projector profile use %s'", name)))
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !config.ProfileExists(name) {
+		return fmt.Errorf("profile '%s' does not exist (create it with 'projector profile create %s')", name, name)
+	}
+	if err := config.UseProfile(name); err != nil {
+		return err
+	}
+
+	// Report with the colors of the profile just selected
+	printStatus(profileFormatter(name).FormatSuccess(fmt.Sprintf("Using profile '%s'", name)))
+	return nil
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/forge"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+var (
+	remoteGitHub   string
+	remoteGitLab   string
+	remoteAPIURL   string
+	remoteArchived bool
+	remoteForks    bool
+
+	remoteCloneDest string
+	remoteCloneSSH  bool
+	remoteCloneAll  bool
+	remoteCloneTags []string
+)
+
+// remoteCmd represents the remote command group
+var remoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "List and clone repositories from GitHub or GitLab",
+	Long: `List the repositories of a GitHub organization or user, or of a GitLab
+group, and clone the ones you pick as projects.
+
+Set GITHUB_TOKEN (or GH_TOKEN) or GITLAB_TOKEN to include private
+repositories and raise API rate limits. Archived repositories and forks
+are left out unless --archived or --forks is given.
+
+Examples:
+  # List an organization's repositories
+  projector remote list --github acme
+
+  # Pick repositories of a GitLab group, clone them and add them as favorites
+  projector remote clone --gitlab acme/platform
+
+  # Clone every repository over SSH into ~/work/acme
+  projector remote clone --github acme --all --ssh --dest ~/work/acme`,
+}
+
+var remoteListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List repositories",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runRemoteList,
+}
+
+var remoteCloneCmd = &cobra.Command{
+	Use:   "clone",
+	Short: "Select repositories, clone them and add them as favorites",
+	Args:  cobra.NoArgs,
+	RunE:  runRemoteClone,
+}
+
+func init() {
+	rootCmd.AddCommand(remoteCmd)
+	remoteCmd.AddCommand(remoteListCmd)
+	remoteCmd.AddCommand(remoteCloneCmd)
+
+	for _, c := range []*cobra.Command{remoteListCmd, remoteCloneCmd} {
+		c.Flags().StringVar(&remoteGitHub, "github", "", "GitHub organization or user")
+		c.Flags().StringVar(&remoteGitLab, "gitlab", "", "GitLab group (e.g. acme/platform)")
+		c.Flags().StringVar(&remoteAPIURL, "api-url", "", "API URL for GitHub Enterprise or a self-hosted GitLab")
+		c.Flags().BoolVar(&remoteArchived, "archived", false, "include archived repositories")
+		c.Flags().BoolVar(&remoteForks, "forks", false, "include forks")
+		c.MarkFlagsMutuallyExclusive("github", "gitlab")
+		c.MarkFlagsOneRequired("github", "gitlab")
+	}
+
+	remoteCloneCmd.Flags().StringVarP(&remoteCloneDest, "dest", "d", "", "base folder for the clones (default: cloneDirectory/<owner>)")
+	remoteCloneCmd.Flags().BoolVar(&remoteCloneSSH, "ssh", false, "clone over SSH instead of HTTPS")
+	remoteCloneCmd.Flags().BoolVarP(&remoteCloneAll, "all", "a", false, "clone every repository without asking")
+	remoteCloneCmd.Flags().StringSliceVarP(&remoteCloneTags, "tag", "t", []string{}, "tags for the added projects (can be used multiple times)")
+}
+
+// fetchRemoteRepos lists the repositories selected by the remote flags and
+// returns them with the owner they belong to
+func fetchRemoteRepos() ([]forge.Repo, string, error) {
+	var (
+		repos []forge.Repo
+		owner string
+		err   error
+	)
+	if remoteGitHub != "" {
+		owner = remoteGitHub
+		client := forge.NewClient(orDefault(remoteAPIURL, forge.GitHubAPI), orDefault(os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN")))
+		repos, err = client.ListGitHub(context.Background(), owner)
+	} else {
+		owner = remoteGitLab
+		client := forge.NewClient(orDefault(remoteAPIURL, forge.GitLabAPI), os.Getenv("GITLAB_TOKEN"))
+		repos, err = client.ListGitLab(context.Background(), owner)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list repositories: %w", err)
+	}
+	repos = filterRepos(repos, remoteArchived, remoteForks)
+	if len(repos) == 0 {
+		return nil, "", fmt.Errorf("no repositories found for '%s'", owner)
+	}
+	return repos, owner, nil
+}
+
+// orDefault returns s, or def when s is empty
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// filterRepos drops archived repositories and forks unless they are wanted
+func filterRepos(repos []forge.Repo, archived, forks bool) []forge.Repo {
+	var kept []forge.Repo
+	for _, r := range repos {
+		if (r.Archived && !archived) || (r.Fork && !forks) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// repoDir returns the folder for a repository below the owner's base
+// folder, keeping GitLab subgroups as subfolders
+func repoDir(repo forge.Repo, owner string) string {
+	prefix := strings.ToLower(owner) + "/"
+	if strings.HasPrefix(strings.ToLower(repo.FullName), prefix) {
+		return filepath.FromSlash(repo.FullName[len(prefix):])
+	}
+	return repo.Name
+}
+
+// formatRepo formats a repository as a list line
+func formatRepo(formatter *output.Formatter, r forge.Repo) string {
+	line := formatter.FormatName(r.FullName)
+	var flags []string
+	if r.Archived {
+		flags = append(flags, "archived")
+	}
+	if r.Fork {
+		flags = append(flags, "fork")
+	}
+	if len(flags) > 0 {
+		line += " (" + strings.Join(flags, ", ") + ")"
+	}
+	if r.Description != "" {
+		line += " - " + r.Description
+	}
+	return line
+}
+
+func runRemoteList(cmd *cobra.Command, args []string) error {
+	repos, _, err := fetchRemoteRepos()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	for _, r := range repos {
+		fmt.Fprintln(app.Out(), formatRepo(formatter, r))
+	}
+	return nil
+}
+
+func runRemoteClone(cmd *cobra.Command, args []string) error {
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage before cloning so a broken setup fails early
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	repos, owner, err := fetchRemoteRepos()
+	if err != nil {
+		return err
+	}
+
+	base := paths.Expand(remoteCloneDest)
+	if base == "" {
+		if cfg.CloneDirectory == "" {
+			return fmt.Errorf("no --dest given and cloneDirectory is not configured")
+		}
+		base = filepath.Join(paths.Expand(cfg.CloneDirectory), filepath.FromSlash(owner))
+	}
+	if base, err = filepath.Abs(base); err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	formatter := app.Formatter()
+
+	selected := repos
+	if !remoteCloneAll {
+		if noInput {
+			return fmt.Errorf("%w; use --all to clone every repository", errNoInput)
+		}
+		for i, r := range repos {
+			fmt.Fprintf(app.Out(), "%3d. %s\n", i+1, formatRepo(formatter, r))
+		}
+		fmt.Fprintln(app.Out())
+		fmt.Fprint(app.Out(), "Enter repository numbers, e.g. 1 3 5-7, or 'a' for all (or 'q' to quit): ")
+		input, err := ReadUserInput()
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(input) {
+		case "q":
+			return nil
+		case "a":
+		default:
+			indexes, err := parseSelection(input, len(repos))
+			if err != nil {
+				return err
+			}
+			selected = make([]forge.Repo, len(indexes))
+			for i, index := range indexes {
+				selected[i] = repos[index]
+			}
+		}
+	}
+
+	added, failed := 0, 0
+	for _, r := range selected {
+		url := r.CloneURL
+		if remoteCloneSSH {
+			url = r.SSHURL
+		}
+		dest := filepath.Join(base, repoDir(r, owner))
+
+		if paths.Exists(dest) {
+			printStatus(formatter.FormatInfo(fmt.Sprintf("%s already exists, not cloning", dest)))
+		} else {
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return fmt.Errorf("failed to create clone directory: %w", err)
+			}
+			printStatus(formatter.FormatInfo(fmt.Sprintf("Cloning %s into %s...", r.FullName, dest)))
+			if err := gitClone(url, dest); err != nil {
+				formatter.FprintWarning(app.Out(), fmt.Sprintf("Skipping %s: %v", r.FullName, err))
+				failed++
+				continue
+			}
+		}
+
+		project := &models.Project{
+			Name:        r.Name,
+			RootPath:    dest,
+			Tags:        remoteCloneTags,
+			Enabled:     true,
+			Description: r.Description,
+			Language:    scanner.DetectLanguage(dest),
+			Kind:        models.KindFavorite,
+		}
+		if err := projector.AddFavorite(store, project); err != nil {
+			formatter.FprintWarning(app.Out(), fmt.Sprintf("Not adding %s: %v", r.FullName, err))
+			continue
+		}
+		added++
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Added %d projects from %s", added, owner)))
+	if failed > 0 {
+		return fmt.Errorf("%d repositories failed to clone", failed)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var (
+	repairBackup  bool
+	repairSalvage bool
+	repairDryRun  bool
+)
+
+// repairCmd represents the repair command
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Recover projects.json or cache.json after it was corrupted",
+	Long: `Check that projects.json and cache.json can be read, and recover the
+ones that cannot, e.g. after a crash, a full disk or a bad manual edit.
+
+Every write of these files also keeps a copy of what was written, with a
+.bak suffix. A broken file can be replaced by that copy (--backup), or by
+the projects that can still be read from the broken file itself
+(--salvage). Without either flag, repair shows what each would recover
+and asks. The broken file is kept with a .corrupt suffix, and a repaired
+projects.json can be undone with 'projector undo'.
+
+Examples:
+  # Check the files and choose how to recover a broken one
+  projector repair
+
+  # Only report what is broken and what could be recovered
+  projector repair --dry-run
+
+  # Recover whatever projects the broken file still holds
+  projector repair --salvage`,
+	Args: cobra.NoArgs,
+	RunE: runRepair,
+}
+
+func init() {
+	rootCmd.AddCommand(repairCmd)
+
+	repairCmd.Flags().BoolVar(&repairBackup, "backup", false, "restore broken files from the copy of their last good write")
+	repairCmd.Flags().BoolVar(&repairSalvage, "salvage", false, "keep the projects that can still be read from broken files")
+	repairCmd.MarkFlagsMutuallyExclusive("backup", "salvage")
+	repairCmd.Flags().BoolVar(&repairDryRun, "dry-run", false, "only report broken files and what could be recovered")
+}
+
+func runRepair(cmd *cobra.Command, args []string) error {
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	for _, file := range storage.RepairFiles {
+		d, err := store.Diagnose(file)
+		if err != nil {
+			return err
+		}
+		if d.Err == nil {
+			printStatus(formatter.FormatSuccess(fmt.Sprintf("%s is fine", file)))
+			continue
+		}
+
+		formatter.FprintError(app.Out(), d.Err.Error())
+		if d.BackupProjects >= 0 {
+			fmt.Fprintf(app.Out(), "  backup of the last good write: %d projects\n", d.BackupProjects)
+		} else {
+			fmt.Fprintln(app.Out(), "  backup of the last good write: none")
+		}
+		fmt.Fprintf(app.Out(), "  salvageable from the file:     %d projects\n", d.SalvageProjects)
+		if repairDryRun {
+			continue
+		}
+
+		source, err := repairSource(d)
+		if err != nil {
+			return err
+		}
+		n, err := store.Repair(file, source)
+		if err != nil {
+			return err
+		}
+		how := "restored from its backup"
+		if source == storage.RepairBySalvage {
+			how = "salvaged"
+		}
+		printStatus(formatter.FormatSuccess(fmt.Sprintf("Repaired %s: %d projects %s; the broken file is kept as %s.corrupt", file, n, how, file)))
+	}
+	return nil
+}
+
+// repairSource returns how to recover the broken file d describes: from
+// --backup or --salvage, or else by asking. The backup is the default
+// answer when there is one.
+func repairSource(d *storage.Diagnosis) (storage.RepairSource, error) {
+	hasBackup := d.BackupProjects >= 0
+	switch {
+	case repairBackup && !hasBackup:
+		return 0, fmt.Errorf("%s has no readable backup; use --salvage", d.File)
+	case repairBackup:
+		return storage.RepairFromBackup, nil
+	case repairSalvage:
+		return storage.RepairBySalvage, nil
+	case noInput:
+		return 0, fmt.Errorf("%w; use --backup or --salvage", errNoInput)
+	}
+
+	if hasBackup {
+		fmt.Fprint(app.Out(), "Restore the [b]ackup, [s]alvage the file, or [q]uit? [B/s/q]: ")
+	} else {
+		fmt.Fprint(app.Out(), "[S]alvage the file, or [q]uit? [S/q]: ")
+	}
+	input, err := ReadUserInput()
+	if err != nil {
+		return 0, errCancelled
+	}
+	switch strings.ToLower(input) {
+	case "":
+		if hasBackup {
+			return storage.RepairFromBackup, nil
+		}
+		return storage.RepairBySalvage, nil
+	case "b", "backup":
+		if hasBackup {
+			return storage.RepairFromBackup, nil
+		}
+	case "s", "salvage":
+		return storage.RepairBySalvage, nil
+	}
+	return 0, errCancelled
+}
+
+// repairHint suggests 'projector repair' when err comes from a storage
+// file that cannot be parsed
+func repairHint(err error) string {
+	if errors.Is(err, storage.ErrProjectsCorrupt) || errors.Is(err, storage.ErrCacheCorrupt) {
+		return "Run 'projector repair' to restore the last good version or salvage what can still be read"
+	}
+	return ""
+}
@@ -1,19 +1,36 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
 )
 
 var (
-	// version is set at build time via ldflags
+	// version, commit and date are set at build time via ldflags
 	version = "dev"
+	commit  = ""
+	date    = ""
 
 	// Global flags
 	noColor bool
+	noPager bool
 	verbose bool
+	quiet   bool
+	noInput bool
+	profile string
+
+	logLevel  string
+	logFormat string
+	logFile   string
+
+	// logCloser closes the log file opened by setupLogging
+	logCloser io.Closer
 )
 
 // rootCmd represents the base command
@@ -51,14 +68,44 @@ Examples:
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	err := rootCmd.Execute()
+	if logCloser != nil {
+		logCloser.Close()
+	}
+	if err != nil {
+		if !errors.Is(err, errCancelled) {
+			fmt.Fprintln(rootCmd.ErrOrStderr(), err)
+			if hint := repairHint(err); hint != "" {
+				fmt.Fprintln(rootCmd.ErrOrStderr(), hint)
+			}
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "do not pipe long output into a pager")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output (same as --log-level debug)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only print errors and requested output, no success or info messages")
+	rootCmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "never prompt; fail when input would be needed")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "log level: debug, info, warn or error (default: logLevel from config)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "log format: text or json (default: logFormat from config)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "also write logs to this file (default: logFile from config)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "profile to use (default: $PROJECTOR_PROFILE or 'projector profile use')")
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		app.setOutput(cmd.OutOrStdout(), cmd.ErrOrStderr())
+		if profile != "" {
+			if err := config.ValidateProfileName(profile); err != nil {
+				return err
+			}
+			if !config.ProfileExists(profile) {
+				return fmt.Errorf("profile '%s' does not exist (create it with 'projector profile create %s')", profile, profile)
+			}
+		}
+		setChangeLabel(cmd, args)
+		return setupLogging()
+	}
 }
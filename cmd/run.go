@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+var (
+	runAll bool
+	runTag string
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run [project-name] -- <command> [args...]",
+	Short: "Run a command inside a project",
+	Long: `Run a command with the working directory set to a project's root.
+
+Output is streamed as the command runs. With --all, the command runs in every
+enabled project (optionally filtered by --tag); failures are reported at the
+end and do not stop the remaining projects.
+
+Examples:
+  # Run the tests of one project
+  projector run api -- go test ./...
+
+  # Pull every project tagged Work
+  projector run --all --tag Work -- git pull`,
+	RunE: runRun,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().BoolVar(&runAll, "all", false, "run in all enabled projects")
+	runCmd.Flags().StringVarP(&runTag, "tag", "t", "", "with --all, only run in projects with this tag")
+}
+
+// splitRunArgs separates the project name from the command given after "--"
+func splitRunArgs(args []string, dashAt int, all bool) (string, []string, error) {
+	if dashAt < 0 {
+		return "", nil, fmt.Errorf("missing command: use 'projector run <project> -- <command>'")
+	}
+
+	before, command := args[:dashAt], args[dashAt:]
+	if len(command) == 0 {
+		return "", nil, fmt.Errorf("missing command after '--'")
+	}
+
+	if all {
+		if len(before) > 0 {
+			return "", nil, fmt.Errorf("cannot combine a project name with --all")
+		}
+		return "", command, nil
+	}
+
+	if len(before) != 1 {
+		return "", nil, fmt.Errorf("expected exactly one project name before '--'")
+	}
+	return before[0], command, nil
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	projectName, command, err := splitRunArgs(args, cmd.ArgsLenAtDash(), runAll)
+	if err != nil {
+		return err
+	}
+	if runTag != "" && !runAll {
+		return fmt.Errorf("--tag can only be used with --all")
+	}
+
+	// Load config
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	allProjects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+	allProjects = projector.FilterEnabled(allProjects)
+
+	formatter := app.Formatter()
+
+	if !runAll {
+		project, _, err := projector.FindProjectByName(allProjects, projectName, matchOptions())
+		if err != nil {
+			return err
+		}
+		return runInProject(project, command)
+	}
+
+	targets := projector.FilterByTag(allProjects, runTag)
+	if len(targets) == 0 {
+		return fmt.Errorf("no projects found")
+	}
+	sortProjects(targets, cfg)
+
+	var failed []string
+	for _, p := range targets {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("%s (%s)", p.Name, p.RootPath)))
+		if err := runInProject(p, command); err != nil {
+			formatter.FprintError(app.Out(), err.Error())
+			failed = append(failed, p.Name)
+		}
+		fmt.Fprintln(app.Out())
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("command failed in %d of %d projects: %v", len(failed), len(targets), failed)
+	}
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Command succeeded in %d projects", len(targets))))
+
+	return nil
+}
+
+// runInProject runs a command in the project root, streaming its output
+func runInProject(project *models.Project, command []string) error {
+	if err := projector.PathError(project); err != nil {
+		return err
+	}
+
+	c := exec.Command(command[0], command[1:]...)
+	c.Dir = project.RootPath
+	c.Stdin = os.Stdin
+	c.Stdout = app.Out()
+	c.Stderr = app.Err()
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("%s: %w", project.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"regexp"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/search"
+)
+
+// maxSearchLineLength truncates long matching lines, e.g. in minified files
+const maxSearchLineLength = 200
+
+var (
+	searchTag        string
+	searchLanguage   string
+	searchIgnoreCase bool
+	searchFixed      bool
+	searchGlobs      []string
+	searchHidden     bool
+	searchNoIgnore   bool
+	searchFilesOnly  bool
+)
+
+// searchCmd represents the search command
+var searchCmd = &cobra.Command{
+	Use:   "search <pattern> [project-name...]",
+	Short: "Search the files of your projects",
+	Long: `Search the files of all enabled projects, or the named ones, for a
+regular expression and print matching lines grouped by project.
+
+Like ripgrep, VCS directories, node_modules, hidden files, binary files,
+files over 1 MB and files ignored by .gitignore or .ignore are skipped.
+
+Examples:
+  # Find which projects use a symbol
+  projector search NewServer
+
+  # Case-insensitive literal search in Go files of Work projects
+  projector search -i -F "todo(" --glob "*.go" --tag Work
+
+  # List matching files only, in two projects
+  projector search -l "deprecated" api web`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringVarP(&searchTag, "tag", "t", "", "search only projects with this tag")
+	searchCmd.Flags().StringVar(&searchLanguage, "language", "", "search only projects with this detected language (e.g. go, rust)")
+	searchCmd.Flags().BoolVarP(&searchIgnoreCase, "ignore-case", "i", false, "match case-insensitively")
+	searchCmd.Flags().BoolVarP(&searchFixed, "fixed-strings", "F", false, "treat the pattern as a literal string")
+	searchCmd.Flags().StringSliceVarP(&searchGlobs, "glob", "g", nil, "search only files matching these name patterns (e.g. *.go)")
+	searchCmd.Flags().BoolVar(&searchHidden, "hidden", false, "search hidden files and directories")
+	searchCmd.Flags().BoolVar(&searchNoIgnore, "no-ignore", false, "do not respect .gitignore and .ignore files")
+	searchCmd.Flags().BoolVarP(&searchFilesOnly, "files-with-matches", "l", false, "print only the paths of matching files")
+
+	searchCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeProjectNames(cmd, nil, toComplete)
+	}
+}
+
+// compileSearchPattern builds the regular expression for a search pattern
+func compileSearchPattern(pattern string, fixed, ignoreCase bool) (*regexp.Regexp, error) {
+	if fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// uniqueByPath drops projects whose root path was already seen, so a
+// favorite that is also cached is searched once
+func uniqueByPath(projects []*models.Project) []*models.Project {
+	seen := make(map[string]bool, len(projects))
+	result := make([]*models.Project, 0, len(projects))
+	for _, p := range projects {
+		if seen[p.RootPath] {
+			continue
+		}
+		seen[p.RootPath] = true
+		result = append(result, p)
+	}
+	return result
+}
+
+// truncateLine shortens a line to maxSearchLineLength runes
+func truncateLine(line string) string {
+	runes := []rune(line)
+	if len(runes) <= maxSearchLineLength {
+		return line
+	}
+	return string(runes[:maxSearchLineLength]) + "..."
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	re, err := compileSearchPattern(args[0], searchFixed, searchIgnoreCase)
+	if err != nil {
+		return err
+	}
+
+	// Initialize storage
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	allProjects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+	allProjects = projector.FilterEnabled(allProjects)
+	allProjects = projector.FilterByTag(allProjects, searchTag)
+	allProjects = projector.FilterByLanguage(allProjects, searchLanguage)
+
+	var projects []*models.Project
+	if names := args[1:]; len(names) > 0 {
+		for _, name := range names {
+			project, _, err := projector.FindProjectByName(allProjects, name, matchOptions())
+			if err != nil {
+				return err
+			}
+			projects = append(projects, project)
+		}
+	} else {
+		projects = allProjects
+	}
+	projects = uniqueByPath(projects)
+	sortByName(projects)
+
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects found")
+	}
+
+	// Ctrl-C stops the search
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	formatter := app.Formatter()
+	opts := search.Options{
+		Globs:         searchGlobs,
+		IncludeHidden: searchHidden,
+		NoIgnore:      searchNoIgnore,
+	}
+
+	matchedFiles, matchedProjects := 0, 0
+	for _, p := range projects {
+		slog.Debug("searching project", "path", p.RootPath)
+
+		headerShown := false
+		lastFile := ""
+		err := search.Dir(ctx, p.RootPath, re, opts, func(m search.Match) error {
+			if !headerShown {
+				if matchedProjects > 0 {
+					fmt.Fprintln(app.Out())
+				}
+				fmt.Fprintln(app.Out(), formatter.FormatName(p.Name)+" "+formatter.FormatPath(p.RootPath))
+				headerShown = true
+				matchedProjects++
+			}
+			if m.Path != lastFile {
+				lastFile = m.Path
+				matchedFiles++
+				if searchFilesOnly {
+					fmt.Fprintln(app.Out(), "  "+m.Rel)
+				}
+			}
+			if !searchFilesOnly {
+				fmt.Fprintf(app.Out(), "  %s:%d: %s\n", formatter.FormatPath(m.Rel), m.Line, truncateLine(m.Text))
+			}
+			return nil
+		})
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("search cancelled")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to search %s: %w", p.Name, err)
+		}
+	}
+
+	if matchedProjects == 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("No matches in %d projects", len(projects))))
+		return nil
+	}
+
+	fmt.Fprintln(app.Out())
+	printStatus(formatter.FormatInfo(fmt.Sprintf("%d files in %d of %d projects", matchedFiles, matchedProjects, len(projects))))
+	return nil
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strconv"
@@ -14,18 +15,20 @@ import (
 	"github.com/ideaspaper/projector/pkg/config"
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/output"
-	"github.com/ideaspaper/projector/pkg/storage"
+	"github.com/ideaspaper/projector/pkg/projector"
 )
 
 var (
-	selectTag       string
-	selectGrouped   bool
-	selectFavorites bool
-	selectGit       bool
-	selectSVN       bool
-	selectMercurial bool
-	selectVSCode    bool
-	selectAny       bool
+	selectTag         string
+	selectLanguage    string
+	selectGrouped     bool
+	selectTypes       typeFlags
+	selectMulti       bool
+	selectPrint0      bool
+	selectPorcelain   bool
+	selectBest        bool
+	selectInteractive bool
+	selectFilters     []string
 )
 
 // selectCmd represents the select command
@@ -37,6 +40,11 @@ var selectCmd = &cobra.Command{
 If no project name is provided, an interactive selection is shown.
 This is useful for scripting and shell integration.
 
+Names are matched like in 'projector open': when several projects match,
+--best selects the highest-ranked one, --interactive shows the selection
+limited to the matches and --multi outputs them all. The name "-" selects
+the project opened before the last one.
+
 Examples:
   # Interactive selection
   projector select
@@ -47,12 +55,18 @@ Examples:
   # Filter interactive selection by tag
   projector select --tag Work
 
-Shell function for cd:
-  pjcd() {
-    local dir
-    dir=$(projector select)
-    [ -n "$dir" ] && [ -d "$dir" ] && cd "$dir"
-  }`,
+  # Best fuzzy match, e.g. for shell functions
+  projector select prj --best
+
+  # Path of the previously opened project
+  projector select -
+
+  # Pick several projects and run a command in each
+  projector select --multi --print0 | xargs -0 -I{} git -C {} pull
+
+Shell functions for cd (see 'projector init'):
+  eval "$(projector init bash)"
+  pjcd myproject`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runSelect,
 }
@@ -60,113 +74,187 @@ Shell function for cd:
 func init() {
 	rootCmd.AddCommand(selectCmd)
 
+	selectCmd.Flags().BoolVar(&selectBest, "best", false, "select the best-ranked project when several match the name")
+	selectCmd.Flags().BoolVarP(&selectInteractive, "interactive", "i", false, "choose among the projects matching the name")
+	selectCmd.MarkFlagsMutuallyExclusive("best", "interactive")
 	selectCmd.Flags().StringVarP(&selectTag, "tag", "t", "", "filter projects by tag")
+	selectCmd.Flags().StringArrayVar(&selectFilters, "filter", nil, "filter projects with an expression, e.g. 'kind==git && tag in (Work,OSS)' (can be used multiple times)")
+	selectCmd.Flags().StringVar(&selectLanguage, "language", "", "filter projects by detected language (e.g. go, rust)")
 	selectCmd.Flags().BoolVarP(&selectGrouped, "grouped", "g", false, "group projects by type")
-	selectCmd.Flags().BoolVar(&selectFavorites, "favorites", false, "show only favorites")
-	selectCmd.Flags().BoolVar(&selectGit, "git", false, "show only git repositories")
-	selectCmd.Flags().BoolVar(&selectSVN, "svn", false, "show only svn repositories")
-	selectCmd.Flags().BoolVar(&selectMercurial, "mercurial", false, "show only mercurial repositories")
-	selectCmd.Flags().BoolVar(&selectVSCode, "vscode", false, "show only vscode workspaces")
-	selectCmd.Flags().BoolVar(&selectAny, "any", false, "show only any-folder projects")
+	selectTypes.register(selectCmd)
+	selectCmd.Flags().BoolVarP(&selectMulti, "multi", "m", false, "allow selecting several projects (e.g. '1 3 5-7')")
+	selectCmd.Flags().BoolVarP(&selectPrint0, "print0", "0", false, "separate output paths with NUL instead of newline")
+	selectCmd.Flags().BoolVar(&selectPorcelain, "porcelain", false, "print one path per line with no other output")
+	selectCmd.MarkFlagsMutuallyExclusive("print0", "porcelain")
 }
 
 func runSelect(cmd *cobra.Command, args []string) error {
 	// Load config
-	cfg, err := config.LoadOrCreateConfig()
+	cfg, err := app.Config()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
 	// Initialize storage
-	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	store, err := app.Storage()
 	if err != nil {
-		return fmt.Errorf("failed to initialize storage: %w", err)
+		return err
 	}
 
 	// Load projects with type filter
-	filter := TypeFilter{
-		Favorites: selectFavorites,
-		Git:       selectGit,
-		SVN:       selectSVN,
-		Mercurial: selectMercurial,
-		VSCode:    selectVSCode,
-		Any:       selectAny,
-	}
-	allProjects, err := LoadFilteredProjects(store, filter)
+	filter, err := selectTypes.filter()
+	if err != nil {
+		return err
+	}
+	allProjects, err := loadProjects(store, filter)
 	if err != nil {
 		return err
 	}
 
 	// Filter enabled only
-	allProjects = FilterEnabled(allProjects)
+	allProjects = projector.FilterEnabled(allProjects)
 
 	// Filter by tag if specified
-	allProjects = FilterByTag(allProjects, selectTag)
+	allProjects = projector.FilterByTag(allProjects, selectTag)
+	allProjects = projector.FilterByLanguage(allProjects, selectLanguage)
+	if allProjects, err = filterByQueries(allProjects, selectFilters); err != nil {
+		return err
+	}
 
 	if len(allProjects) == 0 {
 		return fmt.Errorf("no projects found")
 	}
 
-	// Find project
-	var selectedProject *models.Project
+	// Find projects
+	var selected []*models.Project
 
 	if len(args) > 0 {
 		projectName := args[0]
 
-		// First try exact match
-		for _, p := range allProjects {
-			if strings.EqualFold(p.Name, projectName) {
-				selectedProject = p
-				break
-			}
-		}
-
-		// If no exact match, try partial match
-		if selectedProject == nil {
-			var matches []*models.Project
-			for _, p := range allProjects {
-				if strings.Contains(strings.ToLower(p.Name), strings.ToLower(projectName)) {
-					matches = append(matches, p)
-				}
+		project, matches, err := findProject(cfg, store, allProjects, projectName)
+		switch {
+		case len(matches) > 1 && selectBest:
+			selected = matches[:1]
+		case len(matches) > 1 && selectInteractive:
+			// Pick among the matches only
+			selected, err = selectProjectsForSelect(cmd, matches, cfg, selectMulti)
+			if err != nil {
+				return err
 			}
-
-			if len(matches) == 1 {
-				selectedProject = matches[0]
-			} else if len(matches) > 1 {
-				// Multiple matches - show selection
-				formatter := output.NewFormatter(!noColor && cfg.ShowColors)
-				fmt.Fprintln(os.Stderr, formatter.FormatWarning(fmt.Sprintf("Multiple projects match '%s':", projectName)))
-				for _, p := range matches {
-					fmt.Fprintf(os.Stderr, "  - %s (%s)\n", p.Name, p.RootPath)
-				}
-				return fmt.Errorf("please be more specific")
-			} else {
-				return fmt.Errorf("project '%s' not found", projectName)
+		case len(matches) > 1 && selectMulti:
+			selected = matches
+		case len(matches) > 1:
+			formatter := app.Formatter()
+			formatter.FprintWarning(app.Err(), fmt.Sprintf("Multiple projects match '%s':", projectName))
+			for _, p := range matches {
+				fmt.Fprintf(app.Err(), "  - %s (%s)\n", p.Name, p.RootPath)
 			}
+			return withExitCode(exitAmbiguous, fmt.Errorf("please be more specific (or use --best or --interactive)"))
+		case err != nil:
+			return err
+		default:
+			selected = []*models.Project{project}
 		}
 	} else {
 		// Interactive selection
-		selectedProject, err = selectProjectForSelect(cmd, allProjects, cfg)
+		allProjects = withoutCurrentProject(cfg, allProjects)
+		if len(allProjects) == 0 {
+			return fmt.Errorf("no projects found")
+		}
+		selected, err = selectProjectsForSelect(cmd, allProjects, cfg, selectMulti)
 		if err != nil {
 			return err
 		}
 	}
 
-	// Verify path exists
-	if _, err := os.Stat(selectedProject.RootPath); os.IsNotExist(err) {
-		return fmt.Errorf("project path does not exist: %s", selectedProject.RootPath)
+	// Verify paths exist; with several selections skip missing ones
+	existing := make([]*models.Project, 0, len(selected))
+	for _, p := range selected {
+		if err := projector.PathError(p); err != nil {
+			if len(selected) == 1 {
+				return err
+			}
+			if !selectPorcelain {
+				if p.Offline {
+					fmt.Fprintf(app.Err(), "Skipping offline project: %s\n", p.Name)
+				} else {
+					fmt.Fprintf(app.Err(), "Skipping missing project path: %s\n", p.RootPath)
+				}
+			}
+			continue
+		}
+		existing = append(existing, p)
 	}
+	if len(existing) == 0 {
+		return fmt.Errorf("none of the selected project paths exist")
+	}
+
+	// Output the paths to stdout
+	sep := byte('\n')
+	if selectPrint0 {
+		sep = 0
+	}
+	return writeSelectedPaths(app.Out(), existing, sep)
+}
 
-	// Output the path to stdout
-	fmt.Println(selectedProject.RootPath)
+// writeSelectedPaths writes each project's path followed by sep
+func writeSelectedPaths(w io.Writer, projects []*models.Project, sep byte) error {
+	for _, p := range projects {
+		if _, err := fmt.Fprintf(w, "%s%c", p.RootPath, sep); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// selectProjectForSelect shows an interactive selection menu for the select command
-// It writes prompts to /dev/tty so only the path goes to stdout
-func selectProjectForSelect(cmd *cobra.Command, projects []*models.Project, cfg *config.Config) (*models.Project, error) {
+// parseSelection parses picker input such as "2", "1 3" or "1,4-6" into
+// 0-based indexes, keeping the order given and dropping duplicates.
+func parseSelection(input string, count int) ([]int, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no selection given")
+	}
+
+	seen := make(map[int]bool)
+	var indexes []int
+	for _, field := range fields {
+		first, last := field, field
+		if i := strings.Index(field, "-"); i > 0 {
+			first, last = field[:i], field[i+1:]
+		}
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection: %s", field)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection: %s", field)
+		}
+		if from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("invalid selection: %s", field)
+		}
+		for n := from; n <= to; n++ {
+			if !seen[n] {
+				seen[n] = true
+				indexes = append(indexes, n-1)
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// selectProjectsForSelect shows an interactive selection menu for the select command.
+// It writes prompts to /dev/tty so only the paths go to stdout.
+// With multi set, several numbers and ranges may be entered.
+func selectProjectsForSelect(cmd *cobra.Command, projects []*models.Project, cfg *config.Config, multi bool) ([]*models.Project, error) {
+	if noInput {
+		return nil, fmt.Errorf("%w; give a project name", errNoInput)
+	}
+
 	// Sort according to config
-	sortProjects(projects, cfg.SortList)
+	sortProjects(projects, cfg)
 
 	// Open /dev/tty for interactive output (works even when stdout is redirected)
 	var tty *os.File
@@ -188,7 +276,7 @@ func selectProjectForSelect(cmd *cobra.Command, projects []*models.Project, cfg
 	}
 
 	// Display list to tty
-	formatter := output.NewFormatter(!noColor && cfg.ShowColors)
+	formatter := app.Formatter()
 	fmt.Fprintln(tty, "Select a project:")
 	fmt.Fprintln(tty)
 
@@ -200,38 +288,49 @@ func selectProjectForSelect(cmd *cobra.Command, projects []*models.Project, cfg
 
 	// Use grouped display based on config
 	opts := output.ListOptions{
-		ShowPath:  false,
-		ShowIndex: true,
-		Grouped:   grouped,
+		ShowPath:        false,
+		ShowIndex:       true,
+		Grouped:         grouped,
+		ShowDescription: true,
 	}
+	pickerUsage(cfg, projects, &opts)
 	listOutput, indexedProjects := formatter.FormatProjectList(projects, opts)
 	fmt.Fprintln(tty, listOutput)
 	fmt.Fprintln(tty)
 
 	// Read selection (prompt to tty)
-	fmt.Fprint(tty, "Enter project number (or 'q' to quit): ")
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, err
-	}
-
-	input = strings.TrimSpace(input)
-
-	if input == "q" || input == "Q" {
-		os.Exit(0)
+	prompt := "Enter project number"
+	if multi {
+		prompt = "Enter project numbers, e.g. 1 3 5-7"
 	}
-
-	index, err := strconv.Atoi(input)
-	if err != nil {
-		return nil, fmt.Errorf("invalid selection: %s", input)
+	reader := bufio.NewReader(os.Stdin)
+	read := func() (string, error) {
+		input, err := reader.ReadString('\n')
+		return strings.TrimSpace(input), err
 	}
+	return readPickerSelection(tty, cfg, prompt, read, func(input string) ([]*models.Project, error) {
+		if multi {
+			indexes, err := parseSelection(input, len(indexedProjects))
+			if err != nil {
+				return nil, err
+			}
+			selected := make([]*models.Project, len(indexes))
+			for i, index := range indexes {
+				selected[i] = indexedProjects[index]
+			}
+			return selected, nil
+		}
 
-	// Convert 1-based input to 0-based index
-	index--
-	if index < 0 || index >= len(indexedProjects) {
-		return nil, fmt.Errorf("invalid selection: %d", index+1)
-	}
+		index, err := strconv.Atoi(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection: %s", input)
+		}
 
-	return indexedProjects[index], nil
+		// Convert 1-based input to 0-based index
+		index--
+		if index < 0 || index >= len(indexedProjects) {
+			return nil, fmt.Errorf("invalid selection: %d", index+1)
+		}
+		return []*models.Project{indexedProjects[index]}, nil
+	})
 }
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var (
+	sessionSaveWithin    time.Duration
+	sessionRestoreEditor string
+)
+
+// sessionCmd represents the session command group
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Save and restore sets of open projects",
+	Long: `Save the projects you have been working on as a named session and
+reopen them all later, to switch between contexts such as features or
+clients.
+
+Every project opened with projector is recorded in history.json. A
+session captures the projects opened within a time window (the last 8
+hours by default). Sessions are stored in sessions.json.
+
+Examples:
+  # Save what was opened today as "feature-x"
+  projector session save feature-x
+
+  # Only count projects opened in the last hour
+  projector session save hotfix --within 1h
+
+  # Reopen every project of the session
+  projector session restore feature-x`,
+}
+
+var sessionSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the recently opened projects as a session",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionSave,
+}
+
+var sessionRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Reopen every project of a session",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionRestore,
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List saved sessions",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runSessionList,
+}
+
+var sessionDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a saved session",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSessionDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionSaveCmd)
+	sessionCmd.AddCommand(sessionRestoreCmd)
+	sessionCmd.AddCommand(sessionListCmd)
+	sessionCmd.AddCommand(sessionDeleteCmd)
+
+	sessionSaveCmd.Flags().DurationVarP(&sessionSaveWithin, "within", "w", 8*time.Hour, "include projects opened within this long ago")
+	sessionRestoreCmd.Flags().StringVarP(&sessionRestoreEditor, "editor", "e", "", "editor to use (overrides config)")
+}
+
+// loadSessionContext loads the storage and sessions shared by all session subcommands
+func loadSessionContext() (*storage.Storage, []*models.Session, error) {
+	store, err := app.Storage()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessions, err := store.LoadSessions()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	return store, sessions, nil
+}
+
+func runSessionSave(cmd *cobra.Command, args []string) error {
+	if sessionSaveWithin <= 0 {
+		return fmt.Errorf("--within must be positive")
+	}
+
+	store, sessions, err := loadSessionContext()
+	if err != nil {
+		return err
+	}
+
+	history, err := app.History()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	opened := models.OpenedSince(history, now.Add(-sessionSaveWithin))
+	if len(opened) == 0 {
+		return fmt.Errorf("no projects were opened in the last %s", sessionSaveWithin)
+	}
+
+	verb := "Saved"
+	session := models.FindSession(sessions, args[0])
+	if session == nil {
+		session = &models.Session{Name: args[0]}
+		sessions = append(sessions, session)
+	} else {
+		verb = "Updated"
+	}
+	session.SavedAt = now
+	session.Projects = opened
+
+	if err := store.SaveSessions(sessions); err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("%s session '%s' with %d projects", verb, session.Name, len(opened))))
+
+	return nil
+}
+
+func runSessionRestore(cmd *cobra.Command, args []string) error {
+	store, sessions, err := loadSessionContext()
+	if err != nil {
+		return err
+	}
+
+	session := models.FindSession(sessions, args[0])
+	if session == nil {
+		return fmt.Errorf("session '%s' not found", args[0])
+	}
+
+	allProjects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+
+	m, err := app.Manager()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	opts := projector.OpenOptions{Editor: sessionRestoreEditor, NewWindow: true}
+	opened := 0
+	for _, path := range session.Projects {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			formatter.FprintWarning(app.Out(), fmt.Sprintf("Skipping missing project path: %s", path))
+			continue
+		}
+		project := findProjectByPath(allProjects, path)
+		if project == nil {
+			project = models.NewProject(filepath.Base(path), path)
+		}
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening '%s'...", project.Name)))
+		if err := m.Open(project, opts); err != nil {
+			return err
+		}
+		opened++
+	}
+
+	if opened == 0 {
+		return fmt.Errorf("none of the projects in session '%s' exist", session.Name)
+	}
+	return nil
+}
+
+func runSessionList(cmd *cobra.Command, args []string) error {
+	_, sessions, err := loadSessionContext()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	if len(sessions) == 0 {
+		printStatus(formatter.FormatInfo("No sessions saved"))
+		return nil
+	}
+
+	for _, s := range sessions {
+		fmt.Fprintf(app.Out(), "%s (%d projects, saved %s)\n", formatter.FormatName(s.Name), len(s.Projects), s.SavedAt.Local().Format("2006-01-02 15:04"))
+		for _, path := range s.Projects {
+			fmt.Fprintf(app.Out(), "  - %s\n", formatter.FormatPath(path))
+		}
+	}
+
+	return nil
+}
+
+func runSessionDelete(cmd *cobra.Command, args []string) error {
+	store, sessions, err := loadSessionContext()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]*models.Session, 0, len(sessions))
+	var deleted *models.Session
+	for _, s := range sessions {
+		if deleted == nil && strings.EqualFold(s.Name, args[0]) {
+			deleted = s
+			continue
+		}
+		remaining = append(remaining, s)
+	}
+	if deleted == nil {
+		return fmt.Errorf("session '%s' not found", args[0])
+	}
+
+	if err := store.SaveSessions(remaining); err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Deleted session '%s'", deleted.Name)))
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+var setupNoScan bool
+
+// setupCmd represents the setup command
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Configure projector interactively",
+	Long: `Walk through the main settings - base folders to scan for each project
+type, the editor, the tags to offer and the sort order - write them to the
+config file, and run a first scan.
+
+Each question shows the current value in brackets. Press Enter to keep it,
+or enter '-' to clear it. Folders are separated by commas.
+
+Examples:
+  # Configure projector and scan for projects
+  projector setup
+
+  # Configure a profile without scanning yet
+  projector --profile acme setup --no-scan`,
+	Args: cobra.NoArgs,
+	RunE: runSetup,
+}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+
+	setupCmd.Flags().BoolVar(&setupNoScan, "no-scan", false, "do not scan after saving the config")
+}
+
+// defaultCodeFolders are offered as Git base folders when none are set
+var defaultCodeFolders = []string{"~/projects", "~/code", "~/src", "~/dev", "~/workspace", "~/repos"}
+
+// setupPrompter asks the setup questions
+type setupPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	eof bool
+}
+
+// ask prints question with the current value and returns the answer:
+// current for an empty answer, or an empty string for '-'
+func (p *setupPrompter) ask(question, current string) (string, error) {
+	if current != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, current)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if errors.Is(err, io.EOF) {
+		// Out of input: the remaining questions keep their values
+		p.eof = true
+		if line == "" {
+			fmt.Fprintln(p.out)
+		}
+	} else if err != nil {
+		return "", err
+	}
+	switch answer := strings.TrimSpace(line); answer {
+	case "":
+		return current, nil
+	case "-":
+		return "", nil
+	default:
+		return answer, nil
+	}
+}
+
+// askList asks for a comma-separated list
+func (p *setupPrompter) askList(question string, current []string) ([]string, error) {
+	answer, err := p.ask(question, strings.Join(current, ", "))
+	if err != nil {
+		return nil, err
+	}
+	return splitList(answer), nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runSetupWizard asks the setup questions and updates cfg with the answers
+func runSetupWizard(p *setupPrompter, cfg *config.Config) error {
+	gitFolders := cfg.GitBaseFolders
+	if len(gitFolders) == 0 {
+		for _, folder := range defaultCodeFolders {
+			if paths.Exists(paths.Expand(folder)) {
+				gitFolders = append(gitFolders, folder)
+			}
+		}
+	}
+
+	folders := []struct {
+		question string
+		current  []string
+		target   *[]string
+	}{
+		{"Folders with Git repositories", gitFolders, &cfg.GitBaseFolders},
+		{"Folders with SVN working copies", cfg.SVNBaseFolders, &cfg.SVNBaseFolders},
+		{"Folders with Mercurial repositories", cfg.MercurialBaseFolders, &cfg.MercurialBaseFolders},
+		{"Folders with VS Code workspaces", cfg.VSCodeBaseFolders, &cfg.VSCodeBaseFolders},
+		{"Folders whose subfolders are all projects", cfg.AnyBaseFolders, &cfg.AnyBaseFolders},
+	}
+	for _, f := range folders {
+		answer, err := p.askList(f.question, f.current)
+		if err != nil {
+			return err
+		}
+		for _, folder := range answer {
+			if !paths.Exists(paths.Expand(folder)) {
+				fmt.Fprintf(p.out, "  note: %s does not exist yet\n", folder)
+			}
+		}
+		*f.target = answer
+	}
+
+	editor, err := p.ask("Editor command", cfg.Editor)
+	if err != nil {
+		return err
+	}
+	cfg.Editor = editor
+
+	if cfg.Tags, err = p.askList("Tags to offer when tagging projects", cfg.Tags); err != nil {
+		return err
+	}
+
+	for {
+		answer, err := p.ask("Sort projects by (Name, Path, Saved, Recent, Activity)", string(cfg.SortList))
+		if err != nil {
+			return err
+		}
+		if order, ok := parseSortOrder(answer); ok {
+			cfg.SortList = order
+			return nil
+		}
+		if p.eof {
+			return fmt.Errorf("invalid sort order '%s'", answer)
+		}
+		fmt.Fprintf(p.out, "  '%s' is not a sort order\n", answer)
+	}
+}
+
+// parseSortOrder matches a sort order name case-insensitively
+func parseSortOrder(name string) (config.SortOrder, bool) {
+	for _, order := range []config.SortOrder{config.SortByName, config.SortByPath, config.SortBySaved, config.SortByRecent, config.SortByActivity} {
+		if strings.EqualFold(name, string(order)) {
+			return order, true
+		}
+	}
+	return "", false
+}
+
+func runSetup(cmd *cobra.Command, args []string) error {
+	cfg, err := app.Config()
+	if err != nil {
+		return err
+	}
+
+	if noInput {
+		return errNoInput
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatInfo(fmt.Sprintf("Setting up projector (profile %s). Press Enter to keep a value, '-' to clear it.", activeProfile())))
+	fmt.Fprintln(app.Out())
+
+	prompter := &setupPrompter{in: bufio.NewReader(os.Stdin), out: app.Out()}
+	if err := runSetupWizard(prompter, cfg); err != nil {
+		return err
+	}
+
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(app.Out())
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Saved %s", paths.Collapse(cfg.GetConfigPath()))))
+
+	if setupNoScan {
+		return nil
+	}
+
+	m, err := app.Manager()
+	if err != nil {
+		return err
+	}
+	jobs, err := projector.ScanJobs(cfg, projector.ScanOptions{})
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		printStatus(formatter.FormatInfo("No base folders to scan; add favorites with 'projector add'"))
+		return nil
+	}
+	_, err = m.RunScanJobs(context.Background(), jobs, false, func(job projector.ScanJob, found int, err error) {
+		if err != nil {
+			formatter.FprintWarning(app.Out(), fmt.Sprintf("Error scanning %s: %v", job.Label, err))
+			return
+		}
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Found %d %s", found, job.Label)))
+	})
+	if err != nil {
+		return err
+	}
+	printStatus(formatter.FormatSuccess("Setup complete; run 'projector list' to see your projects"))
+	return nil
+}
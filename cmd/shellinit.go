@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+var shellInitCmdName string
+
+// shellInitCmd represents the init command
+var shellInitCmd = &cobra.Command{
+	Use:   "init <bash|zsh|fish|powershell>",
+	Short: "Print shell functions for changing into projects",
+	Long: `Print a snippet defining shell functions, with completion:
+
+  pj [args...]     shorthand for projector; "pj cd [name]" changes directory
+  pjcd [name]      change into a project (interactive without a name)
+
+A program cannot change its parent shell's directory, so these functions
+wrap 'projector select'. Use --cmd to choose another prefix.
+
+Setup:
+  # bash (~/.bashrc)
+  eval "$(projector init bash)"
+
+  # zsh (~/.zshrc)
+  eval "$(projector init zsh)"
+
+  # fish (~/.config/fish/config.fish)
+  projector init fish | source
+
+  # PowerShell ($PROFILE)
+  Invoke-Expression (& projector init powershell | Out-String)`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeShellInit(app.Out(), args[0], shellInitCmdName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(shellInitCmd)
+
+	shellInitCmd.Flags().StringVar(&shellInitCmdName, "cmd", "pj", "name of the generated function (the cd function gets a 'cd' suffix)")
+
+	// Project names complete for commands taking a project
+	for _, c := range []*cobra.Command{openCmd, selectCmd, infoCmd, cdCmd, archiveCmd} {
+		c.ValidArgsFunction = completeProjectNames
+	}
+}
+
+// completeProjectNames completes the first argument with project names
+func completeProjectNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	projects, err := completionProjects(toComplete)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// shellFuncName matches names that are safe to use as a shell function
+var shellFuncName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// writeShellInit writes the init snippet for shell, naming the functions after name
+func writeShellInit(w io.Writer, shell, name string) error {
+	if !shellFuncName.MatchString(name) {
+		return fmt.Errorf("invalid function name '%s'", name)
+	}
+	tmpl, ok := shellInitTemplates[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish, powershell)", shell)
+	}
+	return template.Must(template.New(shell).Parse(tmpl)).Execute(w, map[string]string{"Cmd": name})
+}
+
+// shellInitTemplates hold the snippets printed by 'projector init'
+var shellInitTemplates = map[string]string{
+	"bash": `# projector shell integration (bash)
+export PROJECTOR_SHELL_INTEGRATION={{.Cmd}}
+
+{{.Cmd}}cd() {
+    local dir
+    dir="$(command projector select "$@")" || return
+    [ -n "$dir" ] && [ -d "$dir" ] && builtin cd -- "$dir"
+}
+
+{{.Cmd}}() {
+    if [ "$1" = "cd" ]; then
+        shift
+        {{.Cmd}}cd "$@"
+    else
+        command projector "$@"
+    fi
+}
+
+_{{.Cmd}}cd_complete() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    COMPREPLY=($(command projector completion-data "$cur" 2>/dev/null | cut -f1))
+}
+complete -F _{{.Cmd}}cd_complete {{.Cmd}}cd
+
+if ! declare -F __start_projector >/dev/null; then
+    source <(command projector completion bash)
+fi
+complete -o default -F __start_projector {{.Cmd}}
+`,
+
+	"zsh": `# projector shell integration (zsh)
+export PROJECTOR_SHELL_INTEGRATION={{.Cmd}}
+
+{{.Cmd}}cd() {
+    local dir
+    dir="$(command projector select "$@")" || return
+    [[ -n "$dir" && -d "$dir" ]] && builtin cd -- "$dir"
+}
+
+{{.Cmd}}() {
+    if [[ "$1" == "cd" ]]; then
+        shift
+        {{.Cmd}}cd "$@"
+    else
+        command projector "$@"
+    fi
+}
+
+if (( $+functions[compdef] )); then
+    _{{.Cmd}}cd_complete() {
+        local -a names
+        names=("${(@f)$(command projector completion-data "${words[CURRENT]}" 2>/dev/null | cut -f1)}")
+        compadd -a names
+    }
+    compdef _{{.Cmd}}cd_complete {{.Cmd}}cd
+
+    (( $+functions[_projector] )) || source <(command projector completion zsh)
+    compdef _projector {{.Cmd}}
+fi
+`,
+
+	"fish": `# projector shell integration (fish)
+set -gx PROJECTOR_SHELL_INTEGRATION {{.Cmd}}
+
+function {{.Cmd}}cd
+    set -l dir (command projector select $argv); or return
+    test -n "$dir"; and test -d "$dir"; and builtin cd -- $dir
+end
+
+function {{.Cmd}}
+    if test "$argv[1]" = cd
+        {{.Cmd}}cd $argv[2..-1]
+    else
+        command projector $argv
+    end
+end
+
+complete -c {{.Cmd}}cd -f -a "(command projector completion-data (commandline -ct) 2>/dev/null | string replace -r '\t.*' '')"
+complete -c {{.Cmd}} -w projector
+`,
+
+	"powershell": `# projector shell integration (PowerShell)
+$env:PROJECTOR_SHELL_INTEGRATION = '{{.Cmd}}'
+
+function {{.Cmd}}cd {
+    $dir = & projector select @args
+    if ($LASTEXITCODE -eq 0 -and $dir -and (Test-Path -LiteralPath $dir -PathType Container)) {
+        Set-Location -LiteralPath $dir
+    }
+}
+
+function {{.Cmd}} {
+    if ($args.Count -gt 0 -and $args[0] -eq 'cd') {
+        $rest = @($args | Select-Object -Skip 1)
+        {{.Cmd}}cd @rest
+    } else {
+        & projector @args
+    }
+}
+
+Register-ArgumentCompleter -CommandName '{{.Cmd}}cd' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    & projector completion-data $wordToComplete 2>$null |
+        ForEach-Object { ($_ -split "` + "`" + `t")[0] } |
+        ForEach-Object { [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_) }
+}
+
+if (-not (Get-Variable -Name __projectorCompleterBlock -ErrorAction SilentlyContinue)) {
+    & projector completion powershell | Out-String | Invoke-Expression
+}
+Register-ArgumentCompleter -CommandName '{{.Cmd}}' -ScriptBlock ${__projectorCompleterBlock}
+`,
+}
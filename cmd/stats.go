@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+)
+
+var (
+	statsSize        bool
+	statsJSON        bool
+	statsTop         int
+	statsStaleMonths int
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics about your projects",
+	Long: `Summarize your projects: counts per kind, tag and language, the most
+opened projects, and projects not opened in a while.
+
+Open counts come from the open history (history.json), which keeps the
+most recent 500 opens. Measuring disk usage walks every project folder,
+so it is only done with --size.
+
+Examples:
+  # Show statistics as tables
+  projector stats
+
+  # Include disk usage and treat projects unopened for 3 months as stale
+  projector stats --size --stale 3
+
+  # Export statistics as JSON
+  projector stats --json > stats.json`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().BoolVar(&statsSize, "size", false, "measure disk usage per project")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "print statistics as JSON")
+	statsCmd.Flags().IntVar(&statsTop, "top", 10, "number of most-opened projects to show (0 for all)")
+	statsCmd.Flags().IntVar(&statsStaleMonths, "stale", 6, "months without opening after which a project is stale")
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsTop < 0 || statsStaleMonths < 0 {
+		return fmt.Errorf("--top and --stale must not be negative")
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	projects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+
+	history, err := app.History()
+	if err != nil {
+		return err
+	}
+
+	stats := projector.ComputeStats(projects, history, projector.StatsOptions{
+		Top:        statsTop,
+		StaleSince: time.Now().AddDate(0, -statsStaleMonths, 0),
+		Sizes:      statsSize,
+	})
+
+	if statsJSON {
+		data, err := json.MarshalIndent(stats, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize stats: %w", err)
+		}
+		fmt.Fprintln(app.Out(), string(data))
+		return nil
+	}
+
+	formatter := app.Formatter()
+	writeStats(app.Out(), formatter, stats, statsStaleMonths)
+	return nil
+}
+
+// writeStats prints stats as a series of tables
+func writeStats(out io.Writer, formatter *output.Formatter, stats *projector.Stats, staleMonths int) {
+	fmt.Fprintf(out, "%s %d (%d enabled)\n", formatter.FormatName("Projects:"), stats.Total, stats.Enabled)
+
+	writeCounts := func(title string, counts []projector.Count) {
+		if len(counts) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "\n%s\n", formatter.FormatName(title))
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, c := range counts {
+			fmt.Fprintf(tw, "  %s\t%d\n", c.Name, c.Count)
+		}
+		tw.Flush()
+	}
+	writeCounts("By kind", stats.ByKind)
+	writeCounts("By tag", stats.ByTag)
+	writeCounts("By language", stats.ByLanguage)
+
+	if len(stats.MostOpened) > 0 {
+		fmt.Fprintf(out, "\n%s\n", formatter.FormatName("Most opened"))
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, u := range stats.MostOpened {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\n", u.Name, u.Opens, u.LastOpened.Local().Format("2006-01-02"))
+		}
+		tw.Flush()
+	}
+
+	if len(stats.Stale) > 0 {
+		fmt.Fprintf(out, "\n%s\n", formatter.FormatName(fmt.Sprintf("Not opened in %d months", staleMonths)))
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, u := range stats.Stale {
+			last := "never"
+			if u.LastOpened != nil {
+				last = u.LastOpened.Local().Format("2006-01-02")
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", u.Name, last, formatter.FormatPath(paths.Collapse(u.Path)))
+		}
+		tw.Flush()
+	}
+
+	if len(stats.Sizes) > 0 {
+		fmt.Fprintf(out, "\n%s\n", formatter.FormatName("Disk usage"))
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		var total int64
+		for _, s := range stats.Sizes {
+			total += s.Bytes
+			fmt.Fprintf(tw, "  %s\t%10s\n", s.Name, formatBytes(s.Bytes))
+		}
+		fmt.Fprintf(tw, "  total\t%10s\n", formatBytes(total))
+		tw.Flush()
+	}
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
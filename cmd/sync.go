@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/cloudsync"
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/export"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var (
+	syncPullReplace bool
+	syncPullYes     bool
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync favorites with a gist or an HTTP/WebDAV URL",
+	Long: `Keep favorites in step between machines without a git repository.
+
+Favorites are stored remotely in a private GitHub Gist or at a URL read
+with GET and written with PUT, such as a file on a WebDAV server, as set
+up in the sync section of the config:
+
+  "sync": { "provider": "gist" }
+  "sync": { "provider": "http", "url": "https://dav.example.com/projector.json", "username": "me" }
+
+The token comes from PROJECTOR_SYNC_TOKEN or the output of
+sync.tokenCommand (e.g. a keyring lookup); gists also use GITHUB_TOKEN or
+GH_TOKEN. The first push to a gist creates it and saves its ID as
+sync.gistId; set the same ID on your other machines.
+
+Without a subcommand, remote favorites missing locally are added (matched
+by path and name), then the merged list is pushed. Removing a favorite is
+not synced this way; use 'sync push' after removing it.
+
+Examples:
+  # Merge with the remote favorites
+  projector sync
+
+  # Replace the remote favorites with the local ones
+  projector sync push
+
+  # Replace the local favorites with the remote ones, without asking
+  projector sync pull --replace --yes`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+var syncPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Replace the remote favorites with the local ones",
+	Args:  cobra.NoArgs,
+	RunE:  runSyncPush,
+}
+
+var syncPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Add the remote favorites missing locally",
+	Args:  cobra.NoArgs,
+	RunE:  runSyncPull,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.AddCommand(syncPushCmd)
+	syncCmd.AddCommand(syncPullCmd)
+
+	syncPullCmd.Flags().BoolVar(&syncPullReplace, "replace", false, "replace the local favorites instead of adding to them")
+	syncPullCmd.Flags().BoolVarP(&syncPullYes, "yes", "y", false, "replace without asking for confirmation")
+}
+
+// syncSetup loads what every sync command needs
+func syncSetup() (*config.Config, *storage.Storage, cloudsync.Provider, *output.Formatter, error) {
+	cfg, err := app.Config()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	provider, err := projector.SyncProvider(cfg)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return cfg, store, provider, app.Formatter(), nil
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	cfg, store, provider, formatter, err := syncSetup()
+	if err != nil {
+		return err
+	}
+
+	remote, err := pullFavorites(context.Background(), provider)
+	if err != nil {
+		return err
+	}
+
+	var added int
+	var projects *models.ProjectList
+	err = store.Update(func(favorites *models.ProjectList) error {
+		projects = favorites
+		if added, _ = mergeImportedProjects(favorites, remote); added == 0 {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if added > 0 {
+		printStatus(formatter.FormatSuccess(fmt.Sprintf("Added %d favorites from %s", added, provider.Name())))
+	} else {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Local favorites are up to date with %s", provider.Name())))
+	}
+
+	return pushFavorites(cfg, provider, formatter, projects.Projects)
+}
+
+func runSyncPush(cmd *cobra.Command, args []string) error {
+	cfg, store, provider, formatter, err := syncSetup()
+	if err != nil {
+		return err
+	}
+
+	projects, err := store.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+	return pushFavorites(cfg, provider, formatter, projects.Projects)
+}
+
+func runSyncPull(cmd *cobra.Command, args []string) error {
+	_, store, provider, formatter, err := syncSetup()
+	if err != nil {
+		return err
+	}
+
+	remote, err := pullFavorites(context.Background(), provider)
+	if err != nil {
+		return err
+	}
+	if remote == nil {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Nothing has been pushed to %s yet", provider.Name())))
+		return nil
+	}
+	if syncPullReplace && !syncPullYes {
+		if noInput {
+			return fmt.Errorf("%w; use --yes to replace the local favorites without asking", errNoInput)
+		}
+		fmt.Fprintf(app.Out(), "Replace the local favorites with the %d from %s? [y/N]: ", len(remote), provider.Name())
+		input, err := ReadUserInput()
+		if err != nil || !strings.EqualFold(input, "y") && !strings.EqualFold(input, "yes") {
+			printStatus(formatter.FormatInfo("Pull cancelled"))
+			return nil
+		}
+	}
+
+	var added, skipped int
+	err = store.Update(func(projects *models.ProjectList) error {
+		if syncPullReplace {
+			projects.Projects = []*models.Project{}
+		}
+		added, skipped = mergeImportedProjects(projects, remote)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Added %d favorites from %s", added, provider.Name())))
+	if skipped > 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Skipped %d favorites that already exist", skipped)))
+	}
+	return nil
+}
+
+// pullFavorites fetches the remote favorites; nil means nothing was
+// pushed yet
+func pullFavorites(ctx context.Context, provider cloudsync.Provider) ([]*models.Project, error) {
+	data, err := provider.Pull(ctx)
+	if errors.Is(err, cloudsync.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull from %s: %w", provider.Name(), err)
+	}
+	doc, err := export.Decode(bytes.NewReader(data), export.FormatJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the favorites from %s: %w", provider.Name(), err)
+	}
+	return doc.Favorites, nil
+}
+
+// pushFavorites uploads the favorites, saving the ID of a newly created
+// gist in the config
+func pushFavorites(cfg *config.Config, provider cloudsync.Provider, formatter *output.Formatter, projects []*models.Project) error {
+	var buf bytes.Buffer
+	if err := export.Encode(&buf, &export.Document{Favorites: projects}, export.FormatJSON); err != nil {
+		return err
+	}
+	if err := provider.Push(context.Background(), buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to push to %s: %w", provider.Name(), err)
+	}
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Pushed %d favorites to %s", len(projects), provider.Name())))
+
+	if g, ok := provider.(*cloudsync.Gist); ok && g.ID != cfg.Sync.GistID {
+		cfg.Sync.GistID = g.ID
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("created gist %s but failed to save its ID: %w", g.ID, err)
+		}
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Saved sync.gistId %s; set it on your other machines", g.ID)))
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var (
+	tagAddAll    bool
+	tagAddFilter string
+
+	tagRemoveAll bool
+)
+
+// tagCmd represents the tag command group
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage tags across projects",
+	Long: `Rename tags and add or remove them on many favorites at once.
+
+Tags can be nested with '/', as in Work/ClientA/Backend: filtering by
+--tag Work also matches the tags below it, and 'tag list' shows them as a
+tree.
+
+Examples:
+  # Rename a tag everywhere (favorites and the configured tag list)
+  projector tag rename Work Job
+
+  # Tag several projects
+  projector tag add Client api web
+
+  # Tag every favorite whose name or path contains "acme"
+  projector tag add Client --all --filter acme
+
+  # Remove a tag from every favorite
+  projector tag remove Old --all
+
+  # Show the tags as a tree
+  projector tag tree`,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List all tags in use, nested tags as a tree",
+	Aliases: []string{"ls", "tree"},
+	Args:    cobra.NoArgs,
+	RunE:    runTags,
+}
+
+var tagRenameCmd = &cobra.Command{
+	Use:               "rename <old> <new>",
+	Short:             "Rename a tag on every project and in the config",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeTagArg,
+	RunE:              runTagRename,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:               "add <tag> [project-name...]",
+	Short:             "Add a tag to projects",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTagArg,
+	RunE:              runTagAdd,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:               "remove <tag> [project-name...]",
+	Short:             "Remove a tag from projects",
+	Aliases:           []string{"rm"},
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTagArg,
+	RunE:              runTagRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagListCmd)
+	tagCmd.AddCommand(tagRenameCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+
+	tagAddCmd.Flags().BoolVar(&tagAddAll, "all", false, "add the tag to all favorites")
+	tagAddCmd.Flags().StringVar(&tagAddFilter, "filter", "", "with --all, only favorites whose name or path contains this text")
+
+	tagRemoveCmd.Flags().BoolVar(&tagRemoveAll, "all", false, "remove the tag from all favorites")
+
+	// Tags complete one level at a time for every --tag flag
+	for _, c := range []*cobra.Command{
+		addCmd, analyzeCmd, cdCmd, cleanupCmd, cloneCmd, disableCmd, enableCmd, listCmd, menuCmd,
+		openCmd, pathCmd, remoteCloneCmd, runCmd, searchCmd, selectCmd,
+	} {
+		_ = c.RegisterFlagCompletionFunc("tag", completeTags)
+	}
+}
+
+// completeTags completes a tag from those used by projects and the
+// configured ones, up to the next '/'
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := app.Config()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	store, err := app.Storage()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	projects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	tags := append([]string{}, cfg.Tags...)
+	for _, p := range projects {
+		tags = append(tags, p.Tags...)
+	}
+	matches := projector.CompleteTag(tags, toComplete)
+	for _, m := range matches {
+		if strings.HasSuffix(m, models.TagSeparator) {
+			return matches, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTagArg completes the tag argument of the tag subcommands and
+// project names after it
+func completeTagArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeTags(cmd, args, toComplete)
+	}
+	if cmd.Name() == "rename" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProjectNames(cmd, nil, toComplete)
+}
+
+// loadTagContext loads the config and storage shared by the tag subcommands
+func loadTagContext() (*config.Config, *storage.Storage, error) {
+	cfg, err := app.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, store, nil
+}
+
+// selectTagTargets returns the favorites a bulk tag operation applies to:
+// the named projects, or with all set every favorite whose name or path
+// contains filter (case-insensitive).
+func selectTagTargets(projects *models.ProjectList, names []string, all bool, filter string) ([]*models.Project, error) {
+	if all && len(names) > 0 {
+		return nil, fmt.Errorf("cannot combine project names with --all")
+	}
+	if filter != "" && !all {
+		return nil, fmt.Errorf("--filter requires --all")
+	}
+
+	if !all {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("specify project names or use --all")
+		}
+		targets := make([]*models.Project, 0, len(names))
+		for _, name := range names {
+			project, _, err := projector.FindProjectByName(projects.Projects, name, matchOptions())
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, project)
+		}
+		return targets, nil
+	}
+
+	filter = strings.ToLower(filter)
+	targets := make([]*models.Project, 0, len(projects.Projects))
+	for _, p := range projects.Projects {
+		if filter == "" ||
+			strings.Contains(strings.ToLower(p.Name), filter) ||
+			strings.Contains(strings.ToLower(p.RootPath), filter) {
+			targets = append(targets, p)
+		}
+	}
+	return targets, nil
+}
+
+// renameTagInList renames a tag in a tag list, dropping it if the new
+// name is already present. It reports whether the list changed.
+func renameTagInList(tags []string, oldTag, newTag string) ([]string, bool) {
+	p := &models.Project{Tags: tags}
+	changed := p.RenameTag(oldTag, newTag)
+	return p.Tags, changed
+}
+
+func runTagRename(cmd *cobra.Command, args []string) error {
+	oldTag, newTag := strings.TrimSpace(args[0]), strings.TrimSpace(args[1])
+	if oldTag == "" || newTag == "" {
+		return fmt.Errorf("tag names cannot be empty")
+	}
+	if oldTag == newTag {
+		return fmt.Errorf("old and new tag names are the same")
+	}
+
+	cfg, store, err := loadTagContext()
+	if err != nil {
+		return err
+	}
+
+	renamed := 0
+	err = store.Update(func(projects *models.ProjectList) error {
+		for _, p := range projects.Projects {
+			if p.RenameTag(oldTag, newTag) {
+				renamed++
+			}
+		}
+		if renamed == 0 {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var configChanged bool
+	cfg.Tags, configChanged = renameTagInList(cfg.Tags, oldTag, newTag)
+
+	if renamed == 0 && !configChanged {
+		return fmt.Errorf("tag '%s' not found", oldTag)
+	}
+
+	if configChanged {
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Renamed tag '%s' to '%s' on %d projects", oldTag, newTag, renamed)))
+
+	return nil
+}
+
+func runTagAdd(cmd *cobra.Command, args []string) error {
+	tag := strings.TrimSpace(args[0])
+	if tag == "" {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+
+	_, store, err := loadTagContext()
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	err = store.Update(func(projects *models.ProjectList) error {
+		targets, err := selectTagTargets(projects, args[1:], tagAddAll, tagAddFilter)
+		if err != nil {
+			return err
+		}
+		for _, p := range targets {
+			if !p.HasTag(tag) {
+				p.AddTag(tag)
+				added++
+			}
+		}
+		if added == 0 {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	if added == 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("No projects needed tag '%s'", tag)))
+		return nil
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Added tag '%s' to %d projects", tag, added)))
+
+	return nil
+}
+
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	tag := strings.TrimSpace(args[0])
+
+	_, store, err := loadTagContext()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	err = store.Update(func(projects *models.ProjectList) error {
+		targets, err := selectTagTargets(projects, args[1:], tagRemoveAll, "")
+		if err != nil {
+			return err
+		}
+		for _, p := range targets {
+			if p.HasTag(tag) {
+				p.RemoveTag(tag)
+				removed++
+			}
+		}
+		if removed == 0 {
+			return storage.ErrNoChanges
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	if removed == 0 {
+		printStatus(formatter.FormatInfo(fmt.Sprintf("No projects have tag '%s'", tag)))
+		return nil
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Removed tag '%s' from %d projects", tag, removed)))
+
+	return nil
+}
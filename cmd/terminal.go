@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// Placeholders supported in the terminalCommand setting
+const (
+	placeholderPath = "{{path}}"
+	placeholderName = "{{name}}"
+)
+
+// defaultTerminalCommand returns the terminal command used when terminalCommand
+// is not configured. Inside tmux a new window is opened; otherwise the
+// platform's default terminal is launched.
+func defaultTerminalCommand() string {
+	if os.Getenv("TMUX") != "" {
+		return "tmux new-window -c {{path}} -n {{name}}"
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return "open -a Terminal {{path}}"
+	case "windows":
+		return "cmd /c start cmd /k cd /d {{path}}"
+	default:
+		return "x-terminal-emulator"
+	}
+}
+
+// expandCommandTemplate splits a command template into arguments and
+// substitutes the project placeholders. The template is split before
+// substitution so paths and names containing spaces stay a single argument.
+func expandCommandTemplate(template string, project *models.Project) []string {
+	replacer := strings.NewReplacer(
+		placeholderPath, project.RootPath,
+		placeholderName, project.Name,
+	)
+
+	fields := strings.Fields(template)
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		args[i] = replacer.Replace(field)
+	}
+	return args
+}
+
+// openInTerminal launches a terminal at the project root using the given
+// command template (or the platform default if empty)
+func openInTerminal(project *models.Project, template string) error {
+	if template == "" {
+		template = defaultTerminalCommand()
+	}
+
+	args := expandCommandTemplate(template, project)
+	if len(args) == 0 {
+		return fmt.Errorf("terminal command is empty")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	// Terminals that don't accept a directory argument start in their cwd
+	cmd.Dir = project.RootPath
+	return cmd.Start()
+}
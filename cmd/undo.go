@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var undoList bool
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last change to saved projects",
+	Long: `Undo the most recent change to projects.json, such as an add, remove,
+edit or tag command, by restoring the file as it was before.
+
+The last 20 changes are kept in undo.json, so undo can be repeated to go
+further back. Undone changes cannot be redone, but the undone version is
+kept in the automatic backups (see 'projector backup --list').
+
+Examples:
+  # Bring back a project removed by mistake
+  projector undo
+
+  # Show the changes that can be undone, most recent first
+  projector undo --list`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+
+	undoCmd.Flags().BoolVarP(&undoList, "list", "l", false, "list the changes that can be undone")
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	store, err := app.Storage()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	if undoList {
+		entries, err := store.LoadUndo()
+		if err != nil {
+			return err
+		}
+		writeUndoList(app.Out(), formatter, entries)
+		return nil
+	}
+
+	before, err := store.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+	entry, err := store.Undo()
+	if errors.Is(err, storage.ErrNothingToUndo) {
+		printStatus(formatter.FormatInfo("Nothing to undo"))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	after, err := store.LoadProjects()
+	if err != nil {
+		return fmt.Errorf("failed to load projects: %w", err)
+	}
+
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Undid %s", undoDescription(entry))))
+	writeProjectChanges(app.Out(), formatter, before.Projects, after.Projects)
+	return nil
+}
+
+// undoDescription names the change an undo entry reverts
+func undoDescription(entry *storage.UndoEntry) string {
+	when := entry.At.Local().Format("2006-01-02 15:04")
+	if entry.Command == "" {
+		return fmt.Sprintf("change from %s", when)
+	}
+	return fmt.Sprintf("'%s' from %s", entry.Command, when)
+}
+
+// writeUndoList prints the undo log, most recent change first
+func writeUndoList(out io.Writer, formatter *output.Formatter, entries []storage.UndoEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, formatter.FormatInfo("Nothing to undo"))
+		return
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		fmt.Fprintf(out, "%d. %s\n", len(entries)-i, undoDescription(&entries[i]))
+	}
+}
+
+// writeProjectChanges prints the projects restored (+), dropped (-) and
+// changed (~) going from before to after, matched by path
+func writeProjectChanges(out io.Writer, formatter *output.Formatter, before, after []*models.Project) {
+	old := make(map[string]*models.Project, len(before))
+	for _, p := range before {
+		old[p.RootPath] = p
+	}
+	for _, p := range after {
+		prev, ok := old[p.RootPath]
+		delete(old, p.RootPath)
+		switch {
+		case !ok:
+			fmt.Fprintf(out, "  + %s  %s\n", formatter.FormatName(p.Name), formatter.FormatPath(paths.Collapse(p.RootPath)))
+		case !reflect.DeepEqual(prev, p):
+			fmt.Fprintf(out, "  ~ %s  %s\n", formatter.FormatName(p.Name), formatter.FormatPath(paths.Collapse(p.RootPath)))
+		}
+	}
+	for _, p := range before {
+		if _, dropped := old[p.RootPath]; dropped {
+			fmt.Fprintf(out, "  - %s  %s\n", formatter.FormatName(p.Name), formatter.FormatPath(paths.Collapse(p.RootPath)))
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/forge"
+)
+
+// releaseRepo is the GitHub repository projector is released from
+const releaseRepo = "ideaspaper/projector"
+
+var versionCheckUpdate bool
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build information",
+	Long: `Show the version, commit, build date, Go version and platform of this
+binary. Release builds get them from the build; binaries installed with
+'go install' show what the Go toolchain recorded.
+
+--check-update asks the GitHub releases API for the latest release and
+reports whether it is newer. Set GITHUB_TOKEN (or GH_TOKEN) if you hit the
+API rate limit.
+
+Examples:
+  projector version
+
+  # See if a newer release exists
+  projector version --check-update`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().BoolVar(&versionCheckUpdate, "check-update", false, "check GitHub for a newer release")
+}
+
+// buildInfo describes the running binary
+type buildInfo struct {
+	Version   string
+	Commit    string
+	Date      string
+	GoVersion string
+	Platform  string
+}
+
+// currentBuild returns the build information set with ldflags, falling
+// back to what the Go toolchain recorded for builds without them
+func currentBuild() buildInfo {
+	b := buildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	if b.Version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		b.Version = info.Main.Version
+	}
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision" && b.Commit == "":
+			b.Commit = s.Value
+		case s.Key == "vcs.time" && b.Date == "":
+			b.Date = s.Value
+		}
+	}
+	return b
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	b := currentBuild()
+	fmt.Fprintf(app.Out(), "projector %s\n", b.Version)
+	fmt.Fprintf(app.Out(), "commit:   %s\n", orDefault(b.Commit, "unknown"))
+	fmt.Fprintf(app.Out(), "built:    %s\n", orDefault(b.Date, "unknown"))
+	fmt.Fprintf(app.Out(), "go:       %s\n", b.GoVersion)
+	fmt.Fprintf(app.Out(), "platform: %s\n", b.Platform)
+
+	if !versionCheckUpdate {
+		return nil
+	}
+
+	client := forge.NewClient(forge.GitHubAPI, orDefault(os.Getenv("GITHUB_TOKEN"), os.Getenv("GH_TOKEN")))
+	release, err := client.LatestGitHubRelease(context.Background(), releaseRepo)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	formatter := app.Formatter()
+	fmt.Fprintln(app.Out())
+	switch {
+	case !isReleaseVersion(b.Version):
+		formatter.FprintInfo(app.Out(), fmt.Sprintf("This is a development build; the latest release is %s", release.TagName))
+	case newerVersion(b.Version, release.TagName):
+		formatter.FprintWarning(app.Out(), fmt.Sprintf("A newer release is available: %s (you have %s)", release.TagName, b.Version))
+		fmt.Fprintf(app.Out(), "  %s\n", release.URL)
+	default:
+		formatter.FprintSuccess(app.Out(), fmt.Sprintf("%s is the latest release", b.Version))
+	}
+	return nil
+}
+
+// parseVersion returns the major, minor and patch numbers of a version
+// such as "v1.2.3", "1.2" or "v1.2.3-4-gabc1234-dirty"
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// isReleaseVersion reports whether v is a version number rather than a
+// development build name such as "dev"
+func isReleaseVersion(v string) bool {
+	_, ok := parseVersion(v)
+	return ok
+}
+
+// newerVersion reports whether latest is a newer version than current.
+// Versions that cannot be parsed are never newer.
+func newerVersion(current, latest string) bool {
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := range c {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// watchDebounce is how long to wait for file system events to settle
+// before rescanning, so that a clone or a large move triggers one rescan.
+const watchDebounce = 2 * time.Second
+
+// watchScanJobs watches the base folders of the given scan jobs and
+// rescans the affected project types whenever directories are created,
+// removed or renamed, saving the updated cache after each rescan.
+// mu guards cache for readers running alongside the watcher.
+// It runs until ctx is cancelled.
+func watchScanJobs(ctx context.Context, store *storage.Storage, formatter *output.Formatter, jobs []projector.ScanJob, cache *storage.CachedProjects, mu *sync.RWMutex) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	for _, job := range jobs {
+		for _, base := range paths.ExpandAll(job.BaseFolders) {
+			addWatchTree(watcher, base, job.MaxDepth, job.IgnoredFolders)
+		}
+	}
+
+	printStatus(formatter.FormatInfo("Watching for changes (press Ctrl-C to stop)..."))
+
+	pending := make(map[scanner.ScannerType]bool)
+	var rescan <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			slog.Debug("file system event", "path", event.Name, "op", event.Op.String())
+
+			for _, job := range jobs {
+				base, ok := job.BaseFolderFor(event.Name)
+				if !ok {
+					continue
+				}
+				pending[job.Type] = true
+
+				// Newly created directories need their own watches
+				if event.Has(fsnotify.Create) && paths.IsDir(event.Name) {
+					remaining := job.MaxDepth - relativeDepth(base, event.Name)
+					addWatchTree(watcher, event.Name, remaining, job.IgnoredFolders)
+				}
+			}
+			if len(pending) > 0 {
+				rescan = time.After(watchDebounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			formatter.FprintWarning(app.Out(), fmt.Sprintf("Watch error: %v", err))
+
+		case <-rescan:
+			rescan = nil
+			for _, job := range jobs {
+				if !pending[job.Type] {
+					continue
+				}
+				projects, _, err := job.Run(ctx, nil)
+				if ctx.Err() != nil {
+					return nil
+				}
+				if err != nil {
+					formatter.FprintWarning(app.Out(), fmt.Sprintf("Error scanning %s: %v", job.Label, err))
+					continue
+				}
+				mu.Lock()
+				projector.SetCacheBucket(cache, job.Type, projects)
+				mu.Unlock()
+				printStatus(formatter.FormatInfo(fmt.Sprintf("Found %d %s", len(projects), job.Label)))
+			}
+			pending = make(map[scanner.ScannerType]bool)
+
+			mu.RLock()
+			err := store.SaveCache(cache)
+			mu.RUnlock()
+			if err != nil {
+				formatter.FprintWarning(app.Out(), fmt.Sprintf("Failed to save cache: %v", err))
+				continue
+			}
+			printStatus(formatter.FormatSuccess("Cache updated"))
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// relativeDepth returns how many directory levels path is below base
+func relativeDepth(base, path string) int {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// addWatchTree watches dir and its subdirectories down to depth levels,
+// skipping hidden and ignored folders the same way the scanner does.
+func addWatchTree(watcher *fsnotify.Watcher, dir string, depth int, ignored []string) {
+	if depth < 0 {
+		return
+	}
+	if err := watcher.Add(dir); err != nil {
+		return
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		sub := filepath.Join(dir, name)
+		if strings.HasPrefix(name, ".") || scanner.IsIgnoredFolder(ignored, sub) {
+			continue
+		}
+		addWatchTree(watcher, sub, depth-1, ignored)
+	}
+}
@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/projector"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+var workspaceOpenEditor string
+
+// workspaceCmd represents the workspace command group
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Group projects into named workspaces",
+	Long: `Group several projects under a name and open them together.
+
+Workspaces are stored in workspaces.json next to projects.json.
+Opening a workspace in VS Code or Cursor generates a multi-root
+.code-workspace file; other editors open each project in turn.
+
+Examples:
+  # Create a workspace with two projects
+  projector workspace create platform api web
+
+  # Add another project
+  projector workspace add platform billing
+
+  # Open all projects in the workspace
+  projector workspace open platform`,
+	Aliases: []string{"ws"},
+}
+
+var workspaceCreateCmd = &cobra.Command{
+	Use:   "create <workspace> [project-name...]",
+	Short: "Create a workspace",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runWorkspaceCreate,
+}
+
+var workspaceAddCmd = &cobra.Command{
+	Use:   "add <workspace> <project-name...>",
+	Short: "Add projects to a workspace",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runWorkspaceAdd,
+}
+
+var workspaceRemoveCmd = &cobra.Command{
+	Use:     "remove <workspace> <project-name...>",
+	Short:   "Remove projects from a workspace",
+	Aliases: []string{"rm"},
+	Args:    cobra.MinimumNArgs(2),
+	RunE:    runWorkspaceRemove,
+}
+
+var workspaceDeleteCmd = &cobra.Command{
+	Use:   "delete <workspace>",
+	Short: "Delete a workspace (projects are not affected)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWorkspaceDelete,
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List workspaces and their projects",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runWorkspaceList,
+}
+
+var workspaceOpenCmd = &cobra.Command{
+	Use:   "open <workspace>",
+	Short: "Open all projects in a workspace",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runWorkspaceOpen,
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceCreateCmd)
+	workspaceCmd.AddCommand(workspaceAddCmd)
+	workspaceCmd.AddCommand(workspaceRemoveCmd)
+	workspaceCmd.AddCommand(workspaceDeleteCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+	workspaceCmd.AddCommand(workspaceOpenCmd)
+
+	workspaceOpenCmd.Flags().StringVarP(&workspaceOpenEditor, "editor", "e", "", "editor to use (overrides config)")
+}
+
+// loadWorkspaceContext loads config, storage and workspaces shared by all workspace subcommands
+func loadWorkspaceContext() (*config.Config, *storage.Storage, []*models.Workspace, error) {
+	cfg, err := app.Config()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	store, err := app.Storage()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	workspaces, err := store.LoadWorkspaces()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load workspaces: %w", err)
+	}
+
+	return cfg, store, workspaces, nil
+}
+
+// resolveProjectNames finds each named project among all known projects
+func resolveProjectNames(store *storage.Storage, names []string) ([]*models.Project, error) {
+	allProjects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make([]*models.Project, 0, len(names))
+	for _, name := range names {
+		project, _, err := projector.FindProjectByName(allProjects, name, matchOptions())
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, project)
+	}
+	return resolved, nil
+}
+
+func runWorkspaceCreate(cmd *cobra.Command, args []string) error {
+	_, store, workspaces, err := loadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	name := args[0]
+	if err := models.ValidateWorkspaceName(name); err != nil {
+		return err
+	}
+	if models.FindWorkspace(workspaces, name) != nil {
+		return fmt.Errorf("workspace '%s' already exists", name)
+	}
+
+	members, err := resolveProjectNames(store, args[1:])
+	if err != nil {
+		return err
+	}
+
+	workspace := models.NewWorkspace(name)
+	for _, p := range members {
+		workspace.AddProject(p.RootPath)
+	}
+	workspaces = append(workspaces, workspace)
+
+	if err := store.SaveWorkspaces(workspaces); err != nil {
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Created workspace '%s' with %d projects", name, len(workspace.Projects))))
+
+	return nil
+}
+
+func runWorkspaceAdd(cmd *cobra.Command, args []string) error {
+	_, store, workspaces, err := loadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspace := models.FindWorkspace(workspaces, args[0])
+	if workspace == nil {
+		return fmt.Errorf("workspace '%s' not found", args[0])
+	}
+
+	members, err := resolveProjectNames(store, args[1:])
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	for _, p := range members {
+		if workspace.AddProject(p.RootPath) {
+			added++
+		}
+	}
+
+	if err := store.SaveWorkspaces(workspaces); err != nil {
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Added %d projects to workspace '%s'", added, workspace.Name)))
+
+	return nil
+}
+
+func runWorkspaceRemove(cmd *cobra.Command, args []string) error {
+	_, store, workspaces, err := loadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspace := models.FindWorkspace(workspaces, args[0])
+	if workspace == nil {
+		return fmt.Errorf("workspace '%s' not found", args[0])
+	}
+
+	members, err := resolveProjectNames(store, args[1:])
+	if err != nil {
+		return err
+	}
+
+	for _, p := range members {
+		if !workspace.RemoveProject(p.RootPath) {
+			return fmt.Errorf("project '%s' is not in workspace '%s'", p.Name, workspace.Name)
+		}
+	}
+
+	if err := store.SaveWorkspaces(workspaces); err != nil {
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Removed %d projects from workspace '%s'", len(members), workspace.Name)))
+
+	return nil
+}
+
+func runWorkspaceDelete(cmd *cobra.Command, args []string) error {
+	_, store, workspaces, err := loadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	remaining := make([]*models.Workspace, 0, len(workspaces))
+	var deleted *models.Workspace
+	for _, w := range workspaces {
+		if deleted == nil && strings.EqualFold(w.Name, args[0]) {
+			deleted = w
+			continue
+		}
+		remaining = append(remaining, w)
+	}
+	if deleted == nil {
+		return fmt.Errorf("workspace '%s' not found", args[0])
+	}
+
+	if err := store.SaveWorkspaces(remaining); err != nil {
+		return fmt.Errorf("failed to save workspaces: %w", err)
+	}
+
+	formatter := app.Formatter()
+	printStatus(formatter.FormatSuccess(fmt.Sprintf("Deleted workspace '%s'", deleted.Name)))
+
+	return nil
+}
+
+func runWorkspaceList(cmd *cobra.Command, args []string) error {
+	_, store, workspaces, err := loadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	formatter := app.Formatter()
+	if len(workspaces) == 0 {
+		printStatus(formatter.FormatInfo("No workspaces defined"))
+		return nil
+	}
+
+	allProjects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range workspaces {
+		fmt.Fprintf(app.Out(), "%s (%d projects)\n", w.Name, len(w.Projects))
+		for _, path := range w.Projects {
+			name := filepath.Base(path)
+			if p := findProjectByPath(allProjects, path); p != nil {
+				name = p.Name
+			}
+			fmt.Fprintf(app.Out(), "  - %s (%s)\n", name, path)
+		}
+	}
+
+	return nil
+}
+
+func runWorkspaceOpen(cmd *cobra.Command, args []string) error {
+	cfg, store, workspaces, err := loadWorkspaceContext()
+	if err != nil {
+		return err
+	}
+
+	workspace := models.FindWorkspace(workspaces, args[0])
+	if workspace == nil {
+		return fmt.Errorf("workspace '%s' not found", args[0])
+	}
+	if len(workspace.Projects) == 0 {
+		return fmt.Errorf("workspace '%s' has no projects", workspace.Name)
+	}
+
+	allProjects, err := loadProjects(store, projector.TypeFilter{})
+	if err != nil {
+		return err
+	}
+
+	editorName := workspaceOpenEditor
+	if editorName == "" {
+		editorName = cfg.Editor
+	}
+	editors := app.Editors()
+	if editorName == editor.Auto {
+		e, err := editors.Resolve(editorName)
+		if err != nil {
+			return err
+		}
+		editorName = e.Name()
+	}
+
+	formatter := app.Formatter()
+
+	// Editors with multi-root support get a generated workspace file
+	if editor.OpensWorkspaceFiles(editors.Get(editorName)) {
+		// Names saved before they were validated may not be file names
+		if err := models.ValidateWorkspaceName(workspace.Name); err != nil {
+			return err
+		}
+		file := filepath.Join(store.GetBasePath(), "workspaces", workspace.Name+".code-workspace")
+		if err := writeCodeWorkspace(file, workspace, allProjects); err != nil {
+			return err
+		}
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening workspace '%s' in %s...", workspace.Name, editorName)))
+		if err := openInEditor(file, editorName, true); err != nil {
+			return err
+		}
+		_ = store.RecordOpen(time.Now(), workspace.Projects...)
+		return nil
+	}
+
+	for _, path := range workspace.Projects {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			formatter.FprintWarning(app.Out(), fmt.Sprintf("Skipping missing project path: %s", path))
+			continue
+		}
+		printStatus(formatter.FormatInfo(fmt.Sprintf("Opening %s in %s...", path, editorName)))
+		if err := openInEditor(path, editorName, true); err != nil {
+			return err
+		}
+		_ = store.RecordOpen(time.Now(), path)
+	}
+
+	return nil
+}
+
+// codeWorkspaceFolder is a folder entry in a VS Code .code-workspace file
+type codeWorkspaceFolder struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path"`
+}
+
+// writeCodeWorkspace writes a multi-root VS Code workspace file for the workspace members
+func writeCodeWorkspace(file string, workspace *models.Workspace, allProjects []*models.Project) error {
+	folders := make([]codeWorkspaceFolder, 0, len(workspace.Projects))
+	for _, path := range workspace.Projects {
+		folder := codeWorkspaceFolder{Path: path}
+		if p := findProjectByPath(allProjects, path); p != nil {
+			folder.Name = p.Name
+		}
+		folders = append(folders, folder)
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{"folders": folders}, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize workspace file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write workspace file: %w", err)
+	}
+	return nil
+}
+
+// findProjectByPath returns the project with the given root path, if any
+func findProjectByPath(projects []*models.Project, path string) *models.Project {
+	for _, p := range projects {
+		if paths.Equal(p.RootPath, path) {
+			return p
+		}
+	}
+	return nil
+}
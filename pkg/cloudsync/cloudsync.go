@@ -0,0 +1,239 @@
+// Package cloudsync stores the synced favorites file with a remote
+// provider, a GitHub Gist or any HTTP endpoint accepting PUT such as
+// WebDAV, so favorites can follow a user between machines without a git
+// repository.
+package cloudsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the synced file in a gist
+const FileName = "projector-favorites.json"
+
+// GitHubAPI is the default API endpoint of the Gist provider
+const GitHubAPI = "https://api.github.com"
+
+// ErrNotFound is returned by Pull when nothing has been pushed yet
+var ErrNotFound = errors.New("nothing synced yet")
+
+// Provider stores one file remotely
+type Provider interface {
+	// Name describes the provider in messages, e.g. "gist 1a2b3c"
+	Name() string
+	// Pull returns the stored file, or ErrNotFound
+	Pull(ctx context.Context) ([]byte, error)
+	// Push replaces the stored file with data
+	Push(ctx context.Context, data []byte) error
+}
+
+// Gist stores the file in a private GitHub Gist
+type Gist struct {
+	// BaseURL is the API root, GitHubAPI or a GitHub Enterprise
+	// ".../api/v3" URL
+	BaseURL string
+
+	// ID is the gist holding the file. Push creates a gist and sets ID
+	// when it is empty.
+	ID string
+
+	// Token is a GitHub token with the gist scope
+	Token string
+
+	HTTP *http.Client
+}
+
+// NewGist creates a Gist provider for the gist with the given ID, which
+// may be empty to create one on the first push
+func NewGist(baseURL, id, token string) *Gist {
+	return &Gist{BaseURL: baseURL, ID: id, Token: token, HTTP: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// gistFile is a file in Gist API requests and responses
+type gistFile struct {
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+	RawURL    string `json:"raw_url,omitempty"`
+}
+
+// gist is a gist in Gist API requests and responses
+type gist struct {
+	ID          string              `json:"id,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Public      *bool               `json:"public,omitempty"`
+	Files       map[string]gistFile `json:"files"`
+}
+
+// Name implements Provider
+func (g *Gist) Name() string {
+	if g.ID == "" {
+		return "a new gist"
+	}
+	return "gist " + g.ID
+}
+
+// Pull implements Provider
+func (g *Gist) Pull(ctx context.Context) ([]byte, error) {
+	if g.ID == "" {
+		return nil, ErrNotFound
+	}
+	body, err := g.request(ctx, http.MethodGet, g.url("/gists/"+g.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp gist
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	file, ok := resp.Files[FileName]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if file.Truncated {
+		// Large files are only returned in full from their raw URL
+		return g.request(ctx, http.MethodGet, file.RawURL, nil)
+	}
+	return []byte(file.Content), nil
+}
+
+// Push implements Provider
+func (g *Gist) Push(ctx context.Context, data []byte) error {
+	if g.Token == "" {
+		return fmt.Errorf("a GitHub token with the gist scope is needed to push")
+	}
+	req := gist{Files: map[string]gistFile{FileName: {Content: string(data)}}}
+	if g.ID != "" {
+		_, err := g.request(ctx, http.MethodPatch, g.url("/gists/"+g.ID), req)
+		return err
+	}
+
+	public := false
+	req.Description = "projector favorites"
+	req.Public = &public
+	body, err := g.request(ctx, http.MethodPost, g.url("/gists"), req)
+	if err != nil {
+		return err
+	}
+	var created gist
+	if err := json.Unmarshal(body, &created); err != nil || created.ID == "" {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	g.ID = created.ID
+	return nil
+}
+
+// url returns the API URL of path
+func (g *Gist) url(path string) string {
+	return strings.TrimSuffix(g.BaseURL, "/") + path
+}
+
+// request sends a Gist API request with v as the JSON body and returns
+// the response body
+func (g *Gist) request(ctx context.Context, method, rawURL string, v interface{}) ([]byte, error) {
+	var body io.Reader
+	if v != nil {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+	return do(g.HTTP, req)
+}
+
+// HTTP stores the file at a URL, read with GET and written with PUT. This
+// works with WebDAV servers (Nextcloud, ownCloud, Apache mod_dav) and
+// simple object stores.
+type HTTP struct {
+	URL string
+
+	// Username and Token are sent with basic authentication; a Token
+	// without a Username is sent as a bearer token
+	Username string
+	Token    string
+
+	HTTP *http.Client
+}
+
+// NewHTTP creates an HTTP provider for the file at url
+func NewHTTP(url, username, token string) *HTTP {
+	return &HTTP{URL: url, Username: username, Token: token, HTTP: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Name implements Provider
+func (h *HTTP) Name() string {
+	return h.URL
+}
+
+// Pull implements Provider
+func (h *HTTP) Pull(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	h.authorize(req)
+	return do(h.HTTP, req)
+}
+
+// Push implements Provider
+func (h *HTTP) Push(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, h.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	h.authorize(req)
+	_, err = do(h.HTTP, req)
+	return err
+}
+
+// authorize adds the credentials to req
+func (h *HTTP) authorize(req *http.Request) {
+	switch {
+	case h.Username != "":
+		req.SetBasicAuth(h.Username, h.Token)
+	case h.Token != "":
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+}
+
+// do sends req and returns the body of a successful response. A 404 is
+// reported as ErrNotFound.
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("authentication failed (check the sync token): %s", resp.Status)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
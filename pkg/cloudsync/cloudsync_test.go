@@ -0,0 +1,116 @@
+package cloudsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGist_PushCreatesThenUpdates(t *testing.T) {
+	var stored string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("expected bearer token, got %q", got)
+		}
+		var req gist
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/gists":
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Public == nil || *req.Public {
+				t.Error("expected a private gist")
+			}
+			stored = req.Files[FileName].Content
+			fmt.Fprint(w, `{"id": "abc123"}`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/gists/abc123":
+			json.NewDecoder(r.Body).Decode(&req)
+			stored = req.Files[FileName].Content
+			fmt.Fprint(w, `{"id": "abc123"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/gists/abc123":
+			json.NewEncoder(w).Encode(gist{Files: map[string]gistFile{FileName: {Content: stored}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	g := NewGist(srv.URL, "", "secret")
+	if _, err := g.Pull(context.Background()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Pull() without a gist = %v, want ErrNotFound", err)
+	}
+	if err := g.Push(context.Background(), []byte("v1")); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if g.ID != "abc123" || stored != "v1" {
+		t.Fatalf("expected gist abc123 holding v1, got %q holding %q", g.ID, stored)
+	}
+	if err := g.Push(context.Background(), []byte("v2")); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	data, err := g.Pull(context.Background())
+	if err != nil || string(data) != "v2" {
+		t.Errorf("Pull() = %q, %v; want v2", data, err)
+	}
+}
+
+func TestGist_PullTruncated(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/raw" {
+			fmt.Fprint(w, "full content")
+			return
+		}
+		json.NewEncoder(w).Encode(gist{Files: map[string]gistFile{FileName: {Content: "full", Truncated: true, RawURL: srv.URL + "/raw"}}})
+	}))
+	defer srv.Close()
+
+	data, err := NewGist(srv.URL, "abc123", "").Pull(context.Background())
+	if err != nil || string(data) != "full content" {
+		t.Errorf("Pull() = %q, %v; want the raw file", data, err)
+	}
+	if err := NewGist(srv.URL, "abc123", "").Push(context.Background(), nil); err == nil {
+		t.Error("expected Push() without a token to fail")
+	}
+}
+
+func TestHTTP_PullAndPush(t *testing.T) {
+	var stored []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "pw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if stored == nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(stored)
+		case http.MethodPut:
+			stored, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer srv.Close()
+
+	h := NewHTTP(srv.URL+"/dav/favorites.json", "me", "pw")
+	if _, err := h.Pull(context.Background()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Pull() before a push = %v, want ErrNotFound", err)
+	}
+	if err := h.Push(context.Background(), []byte("data")); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if data, err := h.Pull(context.Background()); err != nil || string(data) != "data" {
+		t.Errorf("Pull() = %q, %v; want data", data, err)
+	}
+
+	if _, err := NewHTTP(srv.URL, "me", "wrong").Pull(context.Background()); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -29,26 +31,133 @@ const (
 	SortByName   SortOrder = "Name"
 	SortByPath   SortOrder = "Path"
 	SortByRecent SortOrder = "Recent"
+
+	// SortByActivity puts the projects worked on most recently first,
+	// judged by their last commit or newest file
+	SortByActivity SortOrder = "Activity"
+)
+
+// OpenStrategy is how 'projector open' opens a project of a folder kind,
+// set per kind by openStrategies
+type OpenStrategy string
+
+const (
+	// OpenInEditor opens the folder in the editor, or the workspace file
+	// of a VS Code project in editors that support workspaces
+	OpenInEditor OpenStrategy = "editor"
+
+	// OpenWorkspace opens the workspace file of a VS Code project in the
+	// editor, whether or not it is known to support workspaces
+	OpenWorkspace OpenStrategy = "workspace"
+
+	OpenInTerminal     OpenStrategy = "terminal"
+	OpenInFileManager  OpenStrategy = "reveal"
+	OpenInDevContainer OpenStrategy = "devcontainer"
 )
 
+// validOpenStrategies are the accepted values of openStrategies
+var validOpenStrategies = []OpenStrategy{OpenInEditor, OpenWorkspace, OpenInTerminal, OpenInFileManager, OpenInDevContainer}
+
+// ParseOpenStrategy parses an openStrategies value. The editor and
+// workspace strategies may name the editor to use after a colon, as in
+// "editor:nvim", which is returned as editorName.
+func ParseOpenStrategy(value string) (strategy OpenStrategy, editorName string, err error) {
+	name, editorName, hasEditor := strings.Cut(value, ":")
+	strategy = OpenStrategy(strings.ToLower(strings.TrimSpace(name)))
+	editorName = strings.TrimSpace(editorName)
+	for _, s := range validOpenStrategies {
+		if s != strategy {
+			continue
+		}
+		if hasEditor && (editorName == "" || (s != OpenInEditor && s != OpenWorkspace)) {
+			return "", "", fmt.Errorf("only editor and workspace take an editor name, as in editor:nvim, got '%s'", value)
+		}
+		return strategy, editorName, nil
+	}
+	names := make([]string, len(validOpenStrategies))
+	for i, s := range validOpenStrategies {
+		names[i] = string(s)
+	}
+	return "", "", fmt.Errorf("must be one of %s, got '%s'", strings.Join(names, ", "), value)
+}
+
 // Config represents the application configuration
 type Config struct {
 	// Display settings
 	SortList                     SortOrder `json:"sortList" mapstructure:"sortList"`
 	GroupList                    bool      `json:"groupList" mapstructure:"groupList"`
+	RecentlyUsedCount            int       `json:"recentlyUsedCount" mapstructure:"recentlyUsedCount"`
+	ShowOpenCounts               bool      `json:"showOpenCounts" mapstructure:"showOpenCounts"`
+	PreviewLines                 int       `json:"previewLines" mapstructure:"previewLines"`
 	ShowColors                   bool      `json:"showColors" mapstructure:"showColors"`
 	CheckInvalidPaths            bool      `json:"checkInvalidPathsBeforeListing" mapstructure:"checkInvalidPathsBeforeListing"`
 	ShowParentOnDuplicates       bool      `json:"showParentFolderInfoOnDuplicates" mapstructure:"showParentFolderInfoOnDuplicates"`
 	FilterOnFullPath             bool      `json:"filterOnFullPath" mapstructure:"filterOnFullPath"`
 	RemoveCurrentFromList        bool      `json:"removeCurrentProjectFromList" mapstructure:"removeCurrentProjectFromList"`
 	CacheProjectsBetweenSessions bool      `json:"cacheProjectsBetweenSessions" mapstructure:"cacheProjectsBetweenSessions"`
+	PruneMissingOnLoad           bool      `json:"pruneMissingOnLoad" mapstructure:"pruneMissingOnLoad"`
+	CacheTTL                     string    `json:"cacheTTL" mapstructure:"cacheTTL"`
+	AutoRescan                   bool      `json:"autoRescan" mapstructure:"autoRescan"`
 	IgnoreProjectsWithinProjects bool      `json:"ignoreProjectsWithinProjects" mapstructure:"ignoreProjectsWithinProjects"`
 	SupportSymlinks              bool      `json:"supportSymlinksOnBaseFolders" mapstructure:"supportSymlinksOnBaseFolders"`
+	RespectGitignore             bool      `json:"respectGitignore" mapstructure:"respectGitignore"`
+	OneFileSystem                bool      `json:"oneFileSystem" mapstructure:"oneFileSystem"`
+	SkipNetworkMounts            bool      `json:"skipNetworkMounts" mapstructure:"skipNetworkMounts"`
+	SkipSyncConflicts            bool      `json:"skipSyncConflicts" mapstructure:"skipSyncConflicts"`
+	MaxProjectsPerScan           int       `json:"maxProjectsPerScan" mapstructure:"maxProjectsPerScan"`
+	MaxDirsVisited               int       `json:"maxDirsVisited" mapstructure:"maxDirsVisited"`
+
+	// Output colors
+	Theme ThemeConfig `json:"theme" mapstructure:"theme"`
+
+	// Colors of the names of projects with a tag, keyed by tag
+	TagColors map[string]string `json:"tagColors" mapstructure:"tagColors"`
+
+	// Icons shown before project names in lists and pickers
+	ShowIcons bool       `json:"showIcons" mapstructure:"showIcons"`
+	Icons     IconConfig `json:"icons" mapstructure:"icons"`
+
+	// Tags offered when tagging projects
+	Tags []string `json:"tags" mapstructure:"tags"`
+
+	// Tags given to scanned projects whose folder matches a pattern, keyed
+	// by pattern (e.g. "~/work/**"). Read by readAutoTags rather than viper.
+	AutoTags map[string][]string `json:"autoTags" mapstructure:"-"`
+
+	// Tag scanned projects with their detected language (lang:go)
+	LanguageTags bool `json:"languageTags" mapstructure:"languageTags"`
+
+	// Web page layout of the forges hosting Git remotes, keyed by remote
+	// host: a forge kind (github, gitlab, bitbucket, gitea) or a URL
+	// template. Read by readVerbatimOptions rather than viper.
+	BrowseHosts map[string]string `json:"browseHosts" mapstructure:"-"`
 
 	// Editor settings
 	Editor          string `json:"editor" mapstructure:"editor"`
 	OpenInNewWindow bool   `json:"openInNewWindow" mapstructure:"openInNewWindow"`
 
+	// Record the Git branch and vim session of projects when they are
+	// opened, and offer to restore them the next time
+	RememberContext bool `json:"rememberContext" mapstructure:"rememberContext"`
+
+	// Editors without built-in support, usable by name as the editor
+	CustomEditors []CustomEditorConfig `json:"customEditors" mapstructure:"customEditors"`
+
+	// Terminal settings
+	TerminalCommand string `json:"terminalCommand" mapstructure:"terminalCommand"`
+
+	// How 'projector open' opens projects, keyed by folder kind (git, svn,
+	// mercurial, vscode or any); see OpenStrategy
+	OpenStrategies map[string]string `json:"openStrategies" mapstructure:"openStrategies"`
+
+	// Dependency and build output folders reported by 'projector cleanup'
+	ArtifactDirs []string `json:"artifactDirs" mapstructure:"artifactDirs"`
+
+	// Pager for output longer than the terminal; an empty Pager uses
+	// $PAGER, then less -R
+	UsePager bool   `json:"usePager" mapstructure:"usePager"`
+	Pager    string `json:"pager" mapstructure:"pager"`
+
 	// Git settings
 	GitBaseFolders    []string `json:"gitBaseFolders" mapstructure:"gitBaseFolders"`
 	GitIgnoredFolders []string `json:"gitIgnoredFolders" mapstructure:"gitIgnoredFolders"`
@@ -77,27 +186,137 @@ type Config struct {
 	// Custom projects location
 	ProjectsLocation string `json:"projectsLocation" mapstructure:"projectsLocation"`
 
+	// Encryption of projects.json and archived.json at rest; the key comes from
+	// PROJECTOR_ENCRYPTION_KEY or the output of EncryptionKeyCommand
+	EncryptProjects      bool   `json:"encryptProjects" mapstructure:"encryptProjects"`
+	EncryptionKeyCommand string `json:"encryptionKeyCommand" mapstructure:"encryptionKeyCommand"`
+
+	// Default directory for 'projector clone'
+	CloneDirectory string `json:"cloneDirectory" mapstructure:"cloneDirectory"`
+
+	// Directory that 'projector archive --move/--compress' puts folders in;
+	// empty means an "archive" folder in the projects location
+	ArchiveDirectory string `json:"archiveDirectory" mapstructure:"archiveDirectory"`
+
+	// Number of automatic backups of projects.json, and of archives
+	// written by restore, kept in the "backups" folder of the projects
+	// location; 0 turns the backups off and keeps every archive
+	BackupCount int `json:"backupCount" mapstructure:"backupCount"`
+
+	// Syncing favorites between machines with 'projector sync'
+	Sync SyncConfig `json:"sync" mapstructure:"sync"`
+
+	// Diagnostic logging; a relative LogFile is placed in the config directory
+	LogLevel  string `json:"logLevel" mapstructure:"logLevel"`
+	LogFormat string `json:"logFormat" mapstructure:"logFormat"`
+	LogFile   string `json:"logFile" mapstructure:"logFile"`
+
 	// Internal
 	v          *viper.Viper `json:"-" mapstructure:"-"`
 	configPath string       `json:"-" mapstructure:"-"`
 }
 
+// ThemeConfig selects a built-in theme and optionally overrides
+// individual colors with color names or hex values
+type ThemeConfig struct {
+	Base    string `json:"base" mapstructure:"base"`
+	Name    string `json:"name,omitempty" mapstructure:"name"`
+	Path    string `json:"path,omitempty" mapstructure:"path"`
+	Tag     string `json:"tag,omitempty" mapstructure:"tag"`
+	Kind    string `json:"kind,omitempty" mapstructure:"kind"`
+	Success string `json:"success,omitempty" mapstructure:"success"`
+	Error   string `json:"error,omitempty" mapstructure:"error"`
+	Warning string `json:"warning,omitempty" mapstructure:"warning"`
+	Info    string `json:"info,omitempty" mapstructure:"info"`
+}
+
+// IconConfig selects a built-in icon set and optionally overrides the
+// icon of individual project kinds
+type IconConfig struct {
+	Set       string `json:"set" mapstructure:"set"`
+	Favorites string `json:"favorites,omitempty" mapstructure:"favorites"`
+	Git       string `json:"git,omitempty" mapstructure:"git"`
+	SVN       string `json:"svn,omitempty" mapstructure:"svn"`
+	Mercurial string `json:"mercurial,omitempty" mapstructure:"mercurial"`
+	VSCode    string `json:"vscode,omitempty" mapstructure:"vscode"`
+	Any       string `json:"any,omitempty" mapstructure:"any"`
+}
+
+// SyncConfig selects where 'projector sync' keeps favorites: a private
+// GitHub Gist ("gist") or a URL read with GET and written with PUT, such
+// as a WebDAV file ("http"). The token comes from PROJECTOR_SYNC_TOKEN,
+// the output of TokenCommand, or for gists GITHUB_TOKEN and GH_TOKEN.
+type SyncConfig struct {
+	Provider     string `json:"provider" mapstructure:"provider"`
+	GistID       string `json:"gistId,omitempty" mapstructure:"gistId"`
+	URL          string `json:"url,omitempty" mapstructure:"url"`
+	Username     string `json:"username,omitempty" mapstructure:"username"`
+	TokenCommand string `json:"tokenCommand,omitempty" mapstructure:"tokenCommand"`
+}
+
+// CustomEditorConfig defines an editor by the command that runs it. Args
+// may contain the {{path}} and {{line}} placeholders; without {{path}}
+// the path is appended. NewWindowArg is passed first when a new window is
+// requested, and terminal editors are attached to the terminal.
+type CustomEditorConfig struct {
+	Name         string   `json:"name" mapstructure:"name"`
+	Command      string   `json:"command" mapstructure:"command"`
+	Args         []string `json:"args,omitempty" mapstructure:"args"`
+	NewWindowArg string   `json:"newWindowArg,omitempty" mapstructure:"newWindowArg"`
+	Terminal     bool     `json:"terminal,omitempty" mapstructure:"terminal"`
+}
+
 // DefaultConfig returns a new config with default values
 func DefaultConfig() *Config {
 	return &Config{
 		SortList:                     SortByName,
 		GroupList:                    true,
+		RecentlyUsedCount:            0,
+		ShowOpenCounts:               false,
+		PreviewLines:                 10,
 		ShowColors:                   true,
 		CheckInvalidPaths:            true,
 		ShowParentOnDuplicates:       false,
 		FilterOnFullPath:             false,
 		RemoveCurrentFromList:        true,
 		CacheProjectsBetweenSessions: true,
+		PruneMissingOnLoad:           false,
+		CacheTTL:                     "7d",
+		AutoRescan:                   false,
 		IgnoreProjectsWithinProjects: false,
 		SupportSymlinks:              false,
+		RespectGitignore:             false,
+		OneFileSystem:                false,
+		SkipNetworkMounts:            false,
+		SkipSyncConflicts:            false,
+		MaxProjectsPerScan:           10000,
+		MaxDirsVisited:               250000,
+
+		Theme:     ThemeConfig{Base: "dark"},
+		TagColors: map[string]string{},
+
+		ShowIcons: false,
+		Icons:     IconConfig{Set: "emoji"},
+
+		Tags:     []string{"Personal", "Work"},
+		AutoTags: map[string][]string{},
+
+		LanguageTags: false,
+
+		BrowseHosts: map[string]string{},
 
 		Editor:          detectDefaultEditor(),
 		OpenInNewWindow: false,
+		RememberContext: false,
+		CustomEditors:   []CustomEditorConfig{},
+
+		TerminalCommand: "",
+		OpenStrategies:  map[string]string{},
+
+		ArtifactDirs: []string{"node_modules", "target", ".venv", "venv", "build", "dist", "__pycache__", ".next", ".gradle"},
+
+		UsePager: true,
+		Pager:    "",
 
 		GitBaseFolders:    []string{},
 		GitIgnoredFolders: []string{"node_modules", "out", "typings", "test", ".haxelib", "vendor"},
@@ -120,6 +339,21 @@ func DefaultConfig() *Config {
 		AnyMaxDepth:       4,
 
 		ProjectsLocation: "",
+
+		EncryptProjects:      false,
+		EncryptionKeyCommand: "",
+
+		CloneDirectory: "~/projects",
+
+		ArchiveDirectory: "",
+
+		BackupCount: 10,
+
+		Sync: SyncConfig{Provider: ""},
+
+		LogLevel:  "warn",
+		LogFormat: "text",
+		LogFile:   "",
 	}
 }
 
@@ -152,17 +386,47 @@ func setDefaults(v *viper.Viper) {
 
 	v.SetDefault("sortList", cfg.SortList)
 	v.SetDefault("groupList", cfg.GroupList)
+	v.SetDefault("recentlyUsedCount", cfg.RecentlyUsedCount)
+	v.SetDefault("showOpenCounts", cfg.ShowOpenCounts)
+	v.SetDefault("previewLines", cfg.PreviewLines)
 	v.SetDefault("showColors", cfg.ShowColors)
 	v.SetDefault("checkInvalidPathsBeforeListing", cfg.CheckInvalidPaths)
 	v.SetDefault("showParentFolderInfoOnDuplicates", cfg.ShowParentOnDuplicates)
 	v.SetDefault("filterOnFullPath", cfg.FilterOnFullPath)
 	v.SetDefault("removeCurrentProjectFromList", cfg.RemoveCurrentFromList)
 	v.SetDefault("cacheProjectsBetweenSessions", cfg.CacheProjectsBetweenSessions)
+	v.SetDefault("pruneMissingOnLoad", cfg.PruneMissingOnLoad)
+	v.SetDefault("cacheTTL", cfg.CacheTTL)
+	v.SetDefault("autoRescan", cfg.AutoRescan)
 	v.SetDefault("ignoreProjectsWithinProjects", cfg.IgnoreProjectsWithinProjects)
 	v.SetDefault("supportSymlinksOnBaseFolders", cfg.SupportSymlinks)
+	v.SetDefault("respectGitignore", cfg.RespectGitignore)
+	v.SetDefault("oneFileSystem", cfg.OneFileSystem)
+	v.SetDefault("skipNetworkMounts", cfg.SkipNetworkMounts)
+	v.SetDefault("skipSyncConflicts", cfg.SkipSyncConflicts)
+	v.SetDefault("maxProjectsPerScan", cfg.MaxProjectsPerScan)
+	v.SetDefault("maxDirsVisited", cfg.MaxDirsVisited)
+
+	v.SetDefault("theme.base", cfg.Theme.Base)
+	v.SetDefault("tagColors", cfg.TagColors)
+	v.SetDefault("showIcons", cfg.ShowIcons)
+	v.SetDefault("icons.set", cfg.Icons.Set)
+
+	v.SetDefault("tags", cfg.Tags)
+	v.SetDefault("languageTags", cfg.LanguageTags)
 
 	v.SetDefault("editor", cfg.Editor)
 	v.SetDefault("openInNewWindow", cfg.OpenInNewWindow)
+	v.SetDefault("rememberContext", cfg.RememberContext)
+	v.SetDefault("customEditors", cfg.CustomEditors)
+
+	v.SetDefault("terminalCommand", cfg.TerminalCommand)
+	v.SetDefault("openStrategies", cfg.OpenStrategies)
+
+	v.SetDefault("artifactDirs", cfg.ArtifactDirs)
+
+	v.SetDefault("usePager", cfg.UsePager)
+	v.SetDefault("pager", cfg.Pager)
 
 	v.SetDefault("gitBaseFolders", cfg.GitBaseFolders)
 	v.SetDefault("gitIgnoredFolders", cfg.GitIgnoredFolders)
@@ -185,16 +449,41 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("anyMaxDepthRecursion", cfg.AnyMaxDepth)
 
 	v.SetDefault("projectsLocation", cfg.ProjectsLocation)
+
+	v.SetDefault("encryptProjects", cfg.EncryptProjects)
+	v.SetDefault("encryptionKeyCommand", cfg.EncryptionKeyCommand)
+
+	v.SetDefault("cloneDirectory", cfg.CloneDirectory)
+	v.SetDefault("archiveDirectory", cfg.ArchiveDirectory)
+	v.SetDefault("backupCount", cfg.BackupCount)
+	v.SetDefault("sync.provider", cfg.Sync.Provider)
+
+	v.SetDefault("logLevel", cfg.LogLevel)
+	v.SetDefault("logFormat", cfg.LogFormat)
+	v.SetDefault("logFile", cfg.LogFile)
 }
 
-// LoadConfig loads configuration from the default path
-func LoadConfig() (*Config, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+// LoadOptions select the configuration LoadConfig loads
+type LoadOptions struct {
+	// Profile takes precedence over PROJECTOR_PROFILE and 'profile use';
+	// empty loads the ActiveProfile
+	Profile string
+}
+
+// dir returns the directory of the profile to load
+func (o LoadOptions) dir() (string, error) {
+	if o.Profile != "" {
+		return ProfileDir(o.Profile)
 	}
+	return ProfileDir(ActiveProfile())
+}
 
-	configDir := filepath.Join(homeDir, ".projector")
+// LoadConfig loads configuration of the profile selected by opts
+func LoadConfig(opts LoadOptions) (*Config, error) {
+	configDir, err := opts.dir()
+	if err != nil {
+		return nil, err
+	}
 	return LoadConfigFromDir(configDir)
 }
 
@@ -205,28 +494,21 @@ func LoadConfigFromDir(dir string) (*Config, error) {
 	// Set defaults
 	setDefaults(v)
 
-	// Configure Viper
-	v.SetConfigName(configFileName)
-	v.SetConfigType(configFileType)
-	v.AddConfigPath(dir)
+	// Configure Viper; the format follows the file's extension
+	configPath := findConfigFile(dir)
+	v.SetConfigFile(configPath)
 
 	// Allow environment variable overrides with prefix PROJECTOR_
 	v.SetEnvPrefix("PROJECTOR")
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
-	configPath := filepath.Join(dir, configFileName+"."+configFileType)
-
-	// Try to read config file
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found, use defaults
-			cfg := DefaultConfig()
-			cfg.v = v
-			cfg.configPath = configPath
-			return cfg, nil
+	// Try to read config file; without one, defaults and environment
+	// overrides still apply
+	if _, err := os.Stat(configPath); err == nil {
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
 		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	// Unmarshal into Config struct
@@ -234,6 +516,9 @@ func LoadConfigFromDir(dir string) (*Config, error) {
 	if err := v.Unmarshal(cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	if err := readVerbatimOptions(cfg, configPath); err != nil {
+		return nil, err
+	}
 
 	cfg.v = v
 	cfg.configPath = configPath
@@ -241,14 +526,45 @@ func LoadConfigFromDir(dir string) (*Config, error) {
 	return cfg, nil
 }
 
-// Save saves the configuration to file
+// readVerbatimOptions reads autoTags and browseHosts from the config file
+// as written. Viper lowercases keys and splits them at dots, which would
+// change folder patterns such as "~/Work/github.com/**" and host names,
+// so it skips these options.
+func readVerbatimOptions(cfg *Config, configPath string) error {
+	cfg.AutoTags = map[string][]string{}
+	cfg.BrowseHosts = map[string]string{}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+	if data, err = toJSON(data, fileFormat(configPath)); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	var raw struct {
+		AutoTags    map[string][]string `json:"autoTags"`
+		BrowseHosts map[string]string   `json:"browseHosts"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse autoTags or browseHosts: %w", err)
+	}
+	if raw.AutoTags != nil {
+		cfg.AutoTags = raw.AutoTags
+	}
+	if raw.BrowseHosts != nil {
+		cfg.BrowseHosts = raw.BrowseHosts
+	}
+	return nil
+}
+
+// Save saves the configuration to file in the format it was loaded from
+// (JSON, YAML or TOML)
 func (c *Config) Save() error {
 	if c.configPath == "" {
-		homeDir, err := os.UserHomeDir()
+		dir, err := ProfileDir(ActiveProfile())
 		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
+			return err
 		}
-		c.configPath = filepath.Join(homeDir, ".projector", configFileName+"."+configFileType)
+		c.configPath = findConfigFile(dir)
 	}
 
 	// Create directory if it doesn't exist
@@ -257,7 +573,7 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(c, "", "    ")
+	data, err := c.encode()
 	if err != nil {
 		return fmt.Errorf("failed to serialize config: %w", err)
 	}
@@ -269,27 +585,94 @@ func (c *Config) Save() error {
 	return nil
 }
 
-// GetProjectsLocation returns the effective projects location
+// GetProjectsLocation returns the effective projects location, which
+// defaults to the config directory of the profile
 func (c *Config) GetProjectsLocation() string {
 	if c.ProjectsLocation != "" {
 		return paths.Expand(c.ProjectsLocation)
 	}
-	homeDir, err := os.UserHomeDir()
+	return c.GetConfigDir()
+}
+
+// GetArchiveDirectory returns the directory archived project folders are
+// moved or compressed into, which defaults to "archive" in the projects
+// location
+func (c *Config) GetArchiveDirectory() string {
+	if c.ArchiveDirectory != "" {
+		return paths.Expand(c.ArchiveDirectory)
+	}
+	return filepath.Join(c.GetProjectsLocation(), "archive")
+}
+
+// GetCacheTTL returns how long scan results stay fresh. Besides Go
+// durations such as "36h", whole days like "7d" are accepted; "" and "0"
+// disable the TTL.
+func (c *Config) GetCacheTTL() (time.Duration, error) {
+	ttl := strings.TrimSpace(c.CacheTTL)
+	if ttl == "" || ttl == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(ttl, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid cacheTTL '%s'", c.CacheTTL)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid cacheTTL '%s' (use e.g. 7d or 36h)", c.CacheTTL)
+	}
+	return d, nil
+}
+
+// GetLogFile returns the path of the log file, or "" when logging to a
+// file is off. A relative LogFile is placed in the config directory.
+func (c *Config) GetLogFile() string {
+	if c.LogFile == "" {
+		return ""
+	}
+	file := paths.Expand(c.LogFile)
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(c.GetConfigDir(), file)
+	}
+	return file
+}
+
+// GetConfigPath returns the path of the config file
+func (c *Config) GetConfigPath() string {
+	if c.configPath != "" {
+		return c.configPath
+	}
+	return findConfigFile(c.GetConfigDir())
+}
+
+// GetConfigDir returns the directory holding the config file
+func (c *Config) GetConfigDir() string {
+	if c.configPath != "" {
+		return filepath.Dir(c.configPath)
+	}
+	dir, err := ProfileDir(ActiveProfile())
 	if err != nil {
 		return ""
 	}
-	return filepath.Join(homeDir, ".projector")
+	return dir
 }
 
 // LoadOrCreateConfig loads existing config or creates a new one with defaults.
 // If the config file cannot be read (other than not existing), a warning is printed
 // to stderr and default config is returned.
-func LoadOrCreateConfig() (*Config, error) {
-	cfg, err := LoadConfig()
+func LoadOrCreateConfig(opts LoadOptions) (*Config, error) {
+	cfg, err := LoadConfig(opts)
 	if err != nil {
 		// Log warning to stderr so users are aware of config issues
 		fmt.Fprintf(os.Stderr, "Warning: failed to load config, using defaults: %v\n", err)
-		return DefaultConfig(), nil
+		cfg = DefaultConfig()
+		// Saving the defaults writes to the selected profile
+		if dir, err := opts.dir(); err == nil {
+			cfg.configPath = findConfigFile(dir)
+		}
+		return cfg, nil
 	}
 	return cfg, nil
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ideaspaper/projector/pkg/paths"
 )
@@ -52,6 +53,35 @@ func TestSortOrder_Values(t *testing.T) {
 	}
 }
 
+func TestParseOpenStrategy(t *testing.T) {
+	tests := []struct {
+		value    string
+		strategy OpenStrategy
+		editor   string
+		wantErr  bool
+	}{
+		{"editor", OpenInEditor, "", false},
+		{"Workspace", OpenWorkspace, "", false},
+		{"editor: nvim", OpenInEditor, "nvim", false},
+		{"workspace:cursor", OpenWorkspace, "cursor", false},
+		{"terminal", OpenInTerminal, "", false},
+		{"reveal", OpenInFileManager, "", false},
+		{"devcontainer", OpenInDevContainer, "", false},
+		{"terminal:kitty", "", "", true},
+		{"editor:", "", "", true},
+		{"shell", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			strategy, editor, err := ParseOpenStrategy(tt.value)
+			if (err != nil) != tt.wantErr || strategy != tt.strategy || editor != tt.editor {
+				t.Errorf("ParseOpenStrategy(%q) = %q, %q, %v", tt.value, strategy, editor, err)
+			}
+		})
+	}
+}
+
 func TestLoadConfigFromDir_NonExistent(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "config")
@@ -196,7 +226,7 @@ func TestExpandPath(t *testing.T) {
 
 func TestLoadOrCreateConfig(t *testing.T) {
 	// Should not fail even when config doesn't exist
-	cfg, err := LoadOrCreateConfig()
+	cfg, err := LoadOrCreateConfig(LoadOptions{})
 	if err != nil {
 		t.Fatalf("LoadOrCreateConfig failed: %v", err)
 	}
@@ -223,6 +253,22 @@ func TestConfig_EnvironmentOverrides(t *testing.T) {
 	}
 }
 
+func TestConfig_EnvironmentOverridesConfigFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), []byte(`{"editor": "code"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PROJECTOR_EDITOR", "nvim")
+
+	cfg, err := LoadConfigFromDir(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFromDir failed: %v", err)
+	}
+	if cfg.Editor != "nvim" {
+		t.Errorf("expected editor 'nvim' from env over the config file, got '%s'", cfg.Editor)
+	}
+}
+
 func TestDetectDefaultEditor(t *testing.T) {
 	// Save original EDITOR
 	origEditor := os.Getenv("EDITOR")
@@ -255,14 +301,42 @@ func TestConfig_AllFieldsSerialized(t *testing.T) {
 	cfg.SortList = SortByPath
 	cfg.GroupList = true
 	cfg.ShowColors = false
+	cfg.PruneMissingOnLoad = true
+	cfg.Tags = []string{"Client"}
 	cfg.Editor = "vim"
 	cfg.OpenInNewWindow = true
+	cfg.CustomEditors = []CustomEditorConfig{{Name: "zed", Command: "zed", Args: []string{"{{path}}:{{line}}"}, NewWindowArg: "-n"}}
+	cfg.TerminalCommand = "tmux new-window -c {{path}}"
+	cfg.UsePager = false
+	cfg.ShowIcons = true
+	cfg.Icons = IconConfig{Set: "nerdfont", Git: "G"}
+	cfg.Pager = "most"
 	cfg.GitBaseFolders = []string{"~/git"}
 	cfg.GitMaxDepth = 8
 	cfg.SVNBaseFolders = []string{"~/svn"}
 	cfg.MercurialBaseFolders = []string{"~/hg"}
 	cfg.VSCodeBaseFolders = []string{"~/vscode"}
 	cfg.AnyBaseFolders = []string{"~/any"}
+	cfg.EncryptProjects = true
+	cfg.EncryptionKeyCommand = "pass show projector"
+	cfg.CacheTTL = "2d"
+	cfg.AutoRescan = true
+	cfg.LogLevel = "debug"
+	cfg.LogFormat = "json"
+	cfg.LogFile = "projector.log"
+	cfg.ArchiveDirectory = "~/archive"
+	cfg.OneFileSystem = true
+	cfg.SkipNetworkMounts = true
+	cfg.SkipSyncConflicts = true
+	cfg.MaxProjectsPerScan = 50
+	cfg.MaxDirsVisited = 0
+	cfg.RecentlyUsedCount = 5
+	cfg.ShowOpenCounts = true
+	cfg.FilterOnFullPath = true
+	cfg.TagColors = map[string]string{"clienta": "blue"}
+	cfg.OpenStrategies = map[string]string{"vscode": "workspace", "any": "terminal"}
+	cfg.BackupCount = 3
+	cfg.Sync = SyncConfig{Provider: "http", URL: "https://dav.example.com/favorites.json", Username: "me"}
 
 	if err := cfg.Save(); err != nil {
 		t.Fatalf("Save failed: %v", err)
@@ -283,18 +357,82 @@ func TestConfig_AllFieldsSerialized(t *testing.T) {
 	if loaded.ShowColors != false {
 		t.Error("ShowColors: expected false")
 	}
+	if loaded.PruneMissingOnLoad != true {
+		t.Error("PruneMissingOnLoad: expected true")
+	}
+	if len(loaded.Tags) != 1 || loaded.Tags[0] != "Client" {
+		t.Errorf("Tags: expected [Client], got %v", loaded.Tags)
+	}
 	if loaded.Editor != "vim" {
 		t.Errorf("Editor: expected vim, got %s", loaded.Editor)
 	}
 	if loaded.OpenInNewWindow != true {
 		t.Error("OpenInNewWindow: expected true")
 	}
+	if len(loaded.CustomEditors) != 1 || loaded.CustomEditors[0].Name != "zed" || loaded.CustomEditors[0].NewWindowArg != "-n" ||
+		len(loaded.CustomEditors[0].Args) != 1 {
+		t.Errorf("CustomEditors: expected the zed editor, got %+v", loaded.CustomEditors)
+	}
+	if loaded.TerminalCommand != "tmux new-window -c {{path}}" {
+		t.Errorf("TerminalCommand: expected tmux command, got %s", loaded.TerminalCommand)
+	}
+	if loaded.ShowIcons != true || loaded.Icons.Set != "nerdfont" || loaded.Icons.Git != "G" {
+		t.Errorf("Icons: expected nerdfont with a git override, got %t and %+v", loaded.ShowIcons, loaded.Icons)
+	}
+	if loaded.UsePager != false || loaded.Pager != "most" {
+		t.Errorf("Pager: expected usePager false and most, got %t and %s", loaded.UsePager, loaded.Pager)
+	}
 	if loaded.GitMaxDepth != 8 {
 		t.Errorf("GitMaxDepth: expected 8, got %d", loaded.GitMaxDepth)
 	}
 	if len(loaded.GitBaseFolders) != 1 {
 		t.Errorf("GitBaseFolders: expected 1, got %d", len(loaded.GitBaseFolders))
 	}
+	if loaded.EncryptProjects != true {
+		t.Error("EncryptProjects: expected true")
+	}
+	if loaded.EncryptionKeyCommand != "pass show projector" {
+		t.Errorf("EncryptionKeyCommand: expected pass command, got %s", loaded.EncryptionKeyCommand)
+	}
+	if ttl, err := loaded.GetCacheTTL(); err != nil || ttl != 48*time.Hour {
+		t.Errorf("GetCacheTTL: expected 48h, got %s, %v", ttl, err)
+	}
+	if loaded.AutoRescan != true {
+		t.Error("AutoRescan: expected true")
+	}
+	if loaded.LogLevel != "debug" || loaded.LogFormat != "json" {
+		t.Errorf("LogLevel, LogFormat: expected debug, json, got %s, %s", loaded.LogLevel, loaded.LogFormat)
+	}
+	if loaded.GetLogFile() != filepath.Join(tmpDir, "projector.log") {
+		t.Errorf("GetLogFile: expected file in config dir, got %s", loaded.GetLogFile())
+	}
+	if loaded.ArchiveDirectory != "~/archive" {
+		t.Errorf("ArchiveDirectory: expected ~/archive, got %s", loaded.ArchiveDirectory)
+	}
+	if !loaded.OneFileSystem || !loaded.SkipNetworkMounts || !loaded.SkipSyncConflicts {
+		t.Error("OneFileSystem, SkipNetworkMounts, SkipSyncConflicts: expected true")
+	}
+	if loaded.MaxProjectsPerScan != 50 || loaded.MaxDirsVisited != 0 {
+		t.Errorf("MaxProjectsPerScan, MaxDirsVisited: expected 50, 0, got %d, %d", loaded.MaxProjectsPerScan, loaded.MaxDirsVisited)
+	}
+	if loaded.RecentlyUsedCount != 5 || !loaded.ShowOpenCounts {
+		t.Errorf("RecentlyUsedCount, ShowOpenCounts: expected 5, true, got %d, %t", loaded.RecentlyUsedCount, loaded.ShowOpenCounts)
+	}
+	if !loaded.FilterOnFullPath {
+		t.Error("FilterOnFullPath: expected true")
+	}
+	if loaded.TagColors["clienta"] != "blue" {
+		t.Errorf("TagColors: expected clienta blue, got %v", loaded.TagColors)
+	}
+	if loaded.OpenStrategies["vscode"] != "workspace" || loaded.OpenStrategies["any"] != "terminal" {
+		t.Errorf("OpenStrategies: expected vscode workspace and any terminal, got %v", loaded.OpenStrategies)
+	}
+	if loaded.BackupCount != 3 {
+		t.Errorf("BackupCount: expected 3, got %d", loaded.BackupCount)
+	}
+	if loaded.Sync.Provider != "http" || loaded.Sync.URL != "https://dav.example.com/favorites.json" || loaded.Sync.Username != "me" {
+		t.Errorf("Sync: expected the http provider, got %+v", loaded.Sync)
+	}
 }
 
 func TestConfig_InvalidJSON(t *testing.T) {
@@ -308,3 +446,28 @@ func TestConfig_InvalidJSON(t *testing.T) {
 		t.Error("expected error for invalid JSON")
 	}
 }
+
+func TestConfig_GetCacheTTL(t *testing.T) {
+	tests := []struct {
+		ttl     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"xd", 0, true},
+		{"-1h", 0, true},
+		{"week", 0, true},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{CacheTTL: tt.ttl}
+		got, err := cfg.GetCacheTTL()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("GetCacheTTL(%q) = %s, %v; want %s, error %v", tt.ttl, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
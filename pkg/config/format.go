@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileTypes are the supported config file extensions in the order
+// they are looked for; JSON wins when a directory holds several
+var configFileTypes = []string{"json", "yaml", "yml", "toml"}
+
+// findConfigFile returns the config file in dir, or the path of a new
+// JSON config file if there is none
+func findConfigFile(dir string) string {
+	for _, ext := range configFileTypes {
+		path := filepath.Join(dir, configFileName+"."+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, configFileName+"."+configFileType)
+}
+
+// fileFormat returns the config format of path from its extension
+func fileFormat(path string) string {
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(path), ".")) {
+	case "yaml", "yml":
+		return "yaml"
+	case "toml":
+		return "toml"
+	default:
+		return "json"
+	}
+}
+
+// encode serializes the config in the format of its file. YAML files keep
+// the comments and key order of the existing file; TOML files are written
+// in the order of the options in Config.
+func (c *Config) encode() ([]byte, error) {
+	switch fileFormat(c.configPath) {
+	case "yaml":
+		existing, err := os.ReadFile(c.configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return c.encodeYAML(existing)
+	case "toml":
+		return c.encodeTOML()
+	default:
+		return json.MarshalIndent(c, "", "    ")
+	}
+}
+
+// orderedNode returns the config as a YAML mapping node whose keys are in
+// the order of the options in Config
+func (c *Config) orderedNode() (*yaml.Node, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is valid YAML, and decoding into a node keeps the key order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	resetStyle(node)
+	return node, nil
+}
+
+// encodeYAML serializes the config, updating the values of the existing
+// YAML document so its comments and key order survive
+func (c *Config) encodeYAML(existing []byte) ([]byte, error) {
+	values, err := c.orderedNode()
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if len(bytes.TrimSpace(existing)) > 0 {
+		if err := yaml.Unmarshal(existing, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{values}}
+	} else {
+		mergeMapping(doc.Content[0], values)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeMapping copies the values of src into the mapping dst, keeping the
+// position and comments of keys dst already has and appending new ones
+func mergeMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		found := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value != key.Value {
+				continue
+			}
+			found = true
+			old := dst.Content[j+1]
+			if old.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				mergeMapping(old, value)
+				break
+			}
+			value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			dst.Content[j+1] = value
+			break
+		}
+		if !found {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+}
+
+// resetStyle switches nodes decoded from JSON to YAML's default block style
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// encodeTOML serializes the config as TOML. Top-level options come before
+// tables, as TOML requires, and options without a value are omitted.
+func (c *Config) encodeTOML() ([]byte, error) {
+	values, err := c.orderedNode()
+	if err != nil {
+		return nil, err
+	}
+
+	var options, tables bytes.Buffer
+	for i := 0; i+1 < len(values.Content); i += 2 {
+		key, value := values.Content[i].Value, values.Content[i+1]
+		var v interface{}
+		if err := value.Decode(&v); err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		data, err := toml.Marshal(map[string]interface{}{key: v})
+		if err != nil {
+			return nil, err
+		}
+		if value.Kind == yaml.MappingNode {
+			tables.WriteString("\n")
+			tables.Write(data)
+		} else {
+			options.Write(data)
+		}
+	}
+	options.Write(tables.Bytes())
+	return options.Bytes(), nil
+}
+
+// toJSON converts YAML or TOML config data to JSON for validation
+func toJSON(data []byte, format string) ([]byte, error) {
+	var raw map[string]interface{}
+	switch format {
+	case "yaml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	case "toml":
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid TOML: %w", err)
+		}
+	default:
+		return data, nil
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+	return json.Marshal(raw)
+}
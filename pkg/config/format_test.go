@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFromDir_YAML(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `# Projector settings
+sortList: Path
+editor: vim # my editor
+gitBaseFolders:
+  - ~/code
+theme:
+  base: light
+`
+	os.WriteFile(filepath.Join(tmpDir, "config.yaml"), []byte(content), 0644)
+
+	cfg, err := LoadConfigFromDir(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFromDir failed: %v", err)
+	}
+	if cfg.SortList != SortByPath || cfg.Editor != "vim" || cfg.Theme.Base != "light" {
+		t.Errorf("unexpected config: sortList=%s editor=%s theme=%s", cfg.SortList, cfg.Editor, cfg.Theme.Base)
+	}
+	if len(cfg.GitBaseFolders) != 1 || cfg.GitBaseFolders[0] != "~/code" {
+		t.Errorf("GitBaseFolders: expected [~/code], got %v", cfg.GitBaseFolders)
+	}
+	if cfg.GetConfigPath() != filepath.Join(tmpDir, "config.yaml") {
+		t.Errorf("expected config path config.yaml, got %s", cfg.GetConfigPath())
+	}
+
+	// Saving keeps the format, comments and key order
+	cfg.Editor = "nvim"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "config.yaml"))
+	saved := string(data)
+	if !strings.HasPrefix(saved, "# Projector settings\nsortList: Path\neditor: nvim # my editor\n") {
+		t.Errorf("expected comments and order to be kept, got:\n%s", saved)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "config.json")); !os.IsNotExist(err) {
+		t.Error("expected no config.json to be written")
+	}
+
+	loaded, err := LoadConfigFromDir(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFromDir failed: %v", err)
+	}
+	if loaded.Editor != "nvim" || loaded.SortList != SortByPath {
+		t.Errorf("expected saved values, got editor=%s sortList=%s", loaded.Editor, loaded.SortList)
+	}
+	problems, err := ValidateFile(loaded.GetConfigPath())
+	if err != nil || len(problems) != 0 {
+		t.Errorf("expected saved YAML to be valid, got %v %v", problems, err)
+	}
+}
+
+func TestLoadConfigFromDir_TOML(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `editor = "vim"
+gitMaxDepthRecursion = 6
+
+[theme]
+base = "none"
+`
+	os.WriteFile(filepath.Join(tmpDir, "config.toml"), []byte(content), 0644)
+
+	cfg, err := LoadConfigFromDir(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFromDir failed: %v", err)
+	}
+	if cfg.Editor != "vim" || cfg.GitMaxDepth != 6 || cfg.Theme.Base != "none" {
+		t.Errorf("unexpected config: editor=%s depth=%d theme=%s", cfg.Editor, cfg.GitMaxDepth, cfg.Theme.Base)
+	}
+
+	cfg.Tags = []string{"Work"}
+	// Viper would lowercase these keys and split them at the dot
+	cfg.AutoTags = map[string][]string{"~/Work/github.com/**": {"Work", "OSS"}}
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadConfigFromDir(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfigFromDir failed: %v", err)
+	}
+	if loaded.Editor != "vim" || loaded.GitMaxDepth != 6 || loaded.Theme.Base != "none" {
+		t.Errorf("expected values to survive saving, got editor=%s depth=%d theme=%s", loaded.Editor, loaded.GitMaxDepth, loaded.Theme.Base)
+	}
+	if len(loaded.Tags) != 1 || loaded.Tags[0] != "Work" {
+		t.Errorf("Tags: expected [Work], got %v", loaded.Tags)
+	}
+	if tags := loaded.AutoTags["~/Work/github.com/**"]; len(loaded.AutoTags) != 1 || len(tags) != 2 || tags[1] != "OSS" {
+		t.Errorf("AutoTags: expected the pattern as written, got %v", loaded.AutoTags)
+	}
+	problems, err := ValidateFile(loaded.GetConfigPath())
+	if err != nil || len(problems) != 0 {
+		t.Errorf("expected saved TOML to be valid, got %v %v", problems, err)
+	}
+}
+
+func TestValidateFile_YAMLProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	os.WriteFile(path, []byte("groupList: yes please\neditr: vim\n"), 0644)
+
+	problems, err := ValidateFile(path)
+	if err != nil {
+		t.Fatalf("ValidateFile() error = %v", err)
+	}
+	if len(problems) != 2 {
+		t.Errorf("expected 2 problems, got %v", problems)
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile kept directly in ~/.projector
+	DefaultProfile = "default"
+
+	// ProfileEnvVar selects the profile when --profile is not given
+	ProfileEnvVar = "PROJECTOR_PROFILE"
+
+	profilesDirName        = "profiles"
+	currentProfileFileName = "profile"
+)
+
+// profileNamePattern matches names usable as a profile directory
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateProfileName checks that a profile name is usable as a directory name
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s' (use letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// BaseDir returns the projector directory, ~/.projector, which holds the
+// default profile and the profiles directory
+func BaseDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".projector"), nil
+}
+
+// ProfileDir returns the directory holding a profile's config file,
+// projects.json and cache.json
+func ProfileDir(name string) (string, error) {
+	base, err := BaseDir()
+	if err != nil {
+		return "", err
+	}
+	if name == "" || name == DefaultProfile {
+		return base, nil
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(base, profilesDirName, name), nil
+}
+
+// ActiveProfile returns the profile used when none is given in
+// LoadOptions: PROJECTOR_PROFILE, else the one saved by UseProfile, else
+// the default
+func ActiveProfile() string {
+	if name := os.Getenv(ProfileEnvVar); name != "" {
+		return name
+	}
+	if base, err := BaseDir(); err == nil {
+		if data, err := os.ReadFile(filepath.Join(base, currentProfileFileName)); err == nil {
+			if name := strings.TrimSpace(string(data)); name != "" {
+				return name
+			}
+		}
+	}
+	return DefaultProfile
+}
+
+// UseProfile saves name as the profile used when none is given explicitly
+func UseProfile(name string) error {
+	base, err := BaseDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(base, currentProfileFileName)
+
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset profile: %w", err)
+		}
+		return nil
+	}
+
+	if !ProfileExists(name) {
+		return fmt.Errorf("profile '%s' does not exist", name)
+	}
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	return nil
+}
+
+// ProfileExists reports whether a profile has been created.
+// The default profile always exists.
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// CreateProfile creates a profile directory with a default config.json
+func CreateProfile(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("the '%s' profile already exists", DefaultProfile)
+	}
+	dir, err := ProfileDir(name)
+	if err != nil {
+		return err
+	}
+	if ProfileExists(name) {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.configPath = filepath.Join(dir, configFileName+"."+configFileType)
+	return cfg.Save()
+}
+
+// ListProfiles returns the default profile followed by created profiles, sorted
+func ListProfiles() ([]string, error) {
+	base, err := BaseDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(base, profilesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && ValidateProfileName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
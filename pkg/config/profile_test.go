@@ -0,0 +1,76 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ProfileEnvVar, "")
+
+	if got := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("expected default profile, got %s", got)
+	}
+
+	if err := CreateProfile("work"); err != nil {
+		t.Fatalf("CreateProfile failed: %v", err)
+	}
+	if err := CreateProfile("work"); err == nil {
+		t.Error("expected error creating an existing profile")
+	}
+	if err := CreateProfile("../escape"); err == nil {
+		t.Error("expected error for invalid profile name")
+	}
+	if err := UseProfile("missing"); err == nil {
+		t.Error("expected error using a missing profile")
+	}
+
+	names, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != DefaultProfile || names[1] != "work" {
+		t.Errorf("unexpected profiles: %v", names)
+	}
+
+	// 'profile use' is remembered
+	if err := UseProfile("work"); err != nil {
+		t.Fatalf("UseProfile failed: %v", err)
+	}
+	if got := ActiveProfile(); got != "work" {
+		t.Errorf("expected work profile, got %s", got)
+	}
+
+	cfg, err := LoadConfig(LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	wantDir := filepath.Join(home, ".projector", "profiles", "work")
+	if cfg.GetProjectsLocation() != wantDir {
+		t.Errorf("expected projects in %s, got %s", wantDir, cfg.GetProjectsLocation())
+	}
+
+	// The environment and the profile given to LoadConfig take precedence
+	t.Setenv(ProfileEnvVar, "personal")
+	if got := ActiveProfile(); got != "personal" {
+		t.Errorf("expected env profile, got %s", got)
+	}
+	cfg, _ = LoadConfig(LoadOptions{Profile: DefaultProfile})
+	if cfg.GetProjectsLocation() != filepath.Join(home, ".projector") {
+		t.Errorf("expected default projects location, got %s", cfg.GetProjectsLocation())
+	}
+	if got := ActiveProfile(); got != "personal" {
+		t.Errorf("expected loading a profile not to change the active one, got %s", got)
+	}
+
+	// Switching back to the default forgets the saved profile
+	t.Setenv(ProfileEnvVar, "")
+	if err := UseProfile(DefaultProfile); err != nil {
+		t.Fatalf("UseProfile(default) failed: %v", err)
+	}
+	if got := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("expected default profile after reset, got %s", got)
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/forge"
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// validSortOrders are the accepted values of sortList
+var validSortOrders = []SortOrder{SortBySaved, SortByName, SortByPath, SortByRecent, SortByActivity}
+
+// ValidateFile checks a config file against the schema of Config: unknown
+// keys, values of the wrong type and out-of-range values are reported as
+// problems. YAML and TOML files are recognized by their extension. An
+// error is returned only when the file cannot be read or does not hold an
+// object.
+func ValidateFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if data, err = toJSON(data, fileFormat(path)); err != nil {
+		return nil, err
+	}
+	return Validate(data)
+}
+
+// Validate checks JSON config data against the schema of Config
+func Validate(data []byte) ([]string, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	problems := validateObject(raw, reflect.TypeOf(Config{}), "")
+
+	if v, ok := raw["sortList"].(string); ok && !isValidSortOrder(v) {
+		problems = append(problems, fmt.Sprintf("sortList: must be one of %s, got '%s'", sortOrderNames(), v))
+	}
+	if sync, ok := raw["sync"].(map[string]interface{}); ok {
+		if v, ok := sync["provider"].(string); ok && v != "" && v != "gist" && v != "http" {
+			problems = append(problems, fmt.Sprintf("sync.provider: must be gist or http, got '%s'", v))
+		}
+	}
+	if hosts, ok := raw["browseHosts"].(map[string]interface{}); ok {
+		for host, v := range hosts {
+			if s, ok := v.(string); ok {
+				if err := forge.ValidateWebTemplate(s); err != nil {
+					problems = append(problems, fmt.Sprintf("browseHosts.%s: %v", host, err))
+				}
+			}
+		}
+	}
+	if strategies, ok := raw["openStrategies"].(map[string]interface{}); ok {
+		for kind, v := range strategies {
+			if _, err := models.ParseFolderKind(kind); err != nil {
+				problems = append(problems, fmt.Sprintf("openStrategies.%s: %v", kind, err))
+			}
+			if s, ok := v.(string); ok {
+				if _, _, err := ParseOpenStrategy(s); err != nil {
+					problems = append(problems, fmt.Sprintf("openStrategies.%s: %v", kind, err))
+				}
+			}
+		}
+	}
+	for key, v := range raw {
+		if strings.HasSuffix(key, "MaxDepthRecursion") || key == "maxProjectsPerScan" || key == "maxDirsVisited" || key == "recentlyUsedCount" || key == "previewLines" || key == "backupCount" {
+			if n, ok := v.(float64); ok && n < 0 {
+				problems = append(problems, fmt.Sprintf("%s: must not be negative", key))
+			}
+		}
+	}
+
+	sort.Strings(problems)
+	return problems, nil
+}
+
+// validateObject checks the keys of raw against the json-tagged fields of t
+func validateObject(raw map[string]interface{}, t reflect.Type, prefix string) []string {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fields[name] = f
+	}
+
+	var problems []string
+	for key, value := range raw {
+		f, ok := fields[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s%s: unknown option", prefix, key))
+			continue
+		}
+		if value == nil {
+			continue
+		}
+
+		switch f.Type.Kind() {
+		case reflect.String:
+			if _, ok := value.(string); !ok {
+				problems = append(problems, fmt.Sprintf("%s%s: expected a string", prefix, key))
+			}
+		case reflect.Bool:
+			if _, ok := value.(bool); !ok {
+				problems = append(problems, fmt.Sprintf("%s%s: expected true or false", prefix, key))
+			}
+		case reflect.Int:
+			if n, ok := value.(float64); !ok || n != float64(int(n)) {
+				problems = append(problems, fmt.Sprintf("%s%s: expected a whole number", prefix, key))
+			}
+		case reflect.Slice:
+			items, ok := value.([]interface{})
+			if f.Type.Elem().Kind() == reflect.Struct {
+				problems = append(problems, validateObjectList(items, ok, f.Type.Elem(), prefix+key)...)
+				continue
+			}
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s%s: expected a list of strings", prefix, key))
+				continue
+			}
+			for _, item := range items {
+				if _, ok := item.(string); !ok {
+					problems = append(problems, fmt.Sprintf("%s%s: expected a list of strings", prefix, key))
+					break
+				}
+			}
+		case reflect.Map:
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s%s: expected an object", prefix, key))
+				continue
+			}
+			for name, item := range obj {
+				if f.Type.Elem().Kind() == reflect.Slice {
+					if !isStringList(item) {
+						problems = append(problems, fmt.Sprintf("%s%s.%s: expected a list of strings", prefix, key, name))
+					}
+				} else if _, ok := item.(string); !ok {
+					problems = append(problems, fmt.Sprintf("%s%s.%s: expected a string", prefix, key, name))
+				}
+			}
+		case reflect.Struct:
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s%s: expected an object", prefix, key))
+				continue
+			}
+			problems = append(problems, validateObject(obj, f.Type, prefix+key+".")...)
+		}
+	}
+	return problems
+}
+
+// isStringList reports whether value is a list holding only strings
+func isStringList(value interface{}) bool {
+	items, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if _, ok := item.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// validateObjectList checks that items is a list of objects matching t
+func validateObjectList(items []interface{}, isList bool, t reflect.Type, key string) []string {
+	if !isList {
+		return []string{fmt.Sprintf("%s: expected a list of objects", key)}
+	}
+	var problems []string
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s[%d]: expected an object", key, i))
+			continue
+		}
+		problems = append(problems, validateObject(obj, t, fmt.Sprintf("%s[%d].", key, i))...)
+	}
+	return problems
+}
+
+func isValidSortOrder(v string) bool {
+	for _, o := range validSortOrders {
+		if string(o) == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sortOrderNames() string {
+	names := make([]string, len(validSortOrders))
+	for i, o := range validSortOrders {
+		names[i] = string(o)
+	}
+	return strings.Join(names, ", ")
+}
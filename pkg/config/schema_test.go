@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate_DefaultConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig()
+	cfg.configPath = filepath.Join(dir, "config.json")
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := ValidateFile(cfg.GetConfigPath())
+	if err != nil {
+		t.Fatalf("ValidateFile() error = %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected the default config to be valid, got %v", problems)
+	}
+}
+
+func TestValidate_Problems(t *testing.T) {
+	data := []byte(`{
+		"sortList": "Size",
+		"groupList": "yes",
+		"gitMaxDepthRecursion": -1,
+		"gitBaseFolders": ["~/code", 3],
+		"theme": {"base": "dark", "accent": "red"},
+		"customEditors": [{"name": "zed", "command": "zed", "args": ["{{path}}"]}, {"name": "hx", "wait": true}],
+		"sync": {"provider": "dropbox"},
+		"tagColors": {"Work": "blue", "ClientA": 3},
+		"browseHosts": {"git.example.com": "GitLab", "code.example.com": "https://code.example.com/tree/{branch}"},
+		"openStrategies": {"vscode": "workspace", "git": "editor:nvim", "any": "shell", "folder": "terminal"},
+		"editr": "vim"
+	}`)
+
+	problems, err := Validate(data)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	want := []string{"sortList", "groupList", "gitMaxDepthRecursion", "gitBaseFolders", "theme.accent", "customEditors[1].wait", "sync.provider", "tagColors.ClientA", "browseHosts.code.example.com", "openStrategies.any", "openStrategies.folder", "editr"}
+	joined := strings.Join(problems, "\n")
+	for _, key := range want {
+		if !strings.Contains(joined, key+":") {
+			t.Errorf("expected a problem for %s, got:\n%s", key, joined)
+		}
+	}
+	if len(problems) != len(want) {
+		t.Errorf("expected %d problems, got %d:\n%s", len(want), len(problems), joined)
+	}
+}
+
+func TestValidate_InvalidJSON(t *testing.T) {
+	if _, err := Validate([]byte(`{"sortList": `)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+	if _, err := ValidateFile(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
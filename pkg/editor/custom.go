@@ -0,0 +1,72 @@
+package editor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Placeholders supported in custom editor arguments
+const (
+	placeholderPath = "{{path}}"
+	placeholderLine = "{{line}}"
+)
+
+// custom is an editor defined in the config
+type custom struct {
+	name         string
+	command      string
+	args         []string
+	newWindowArg string
+	terminal     bool
+}
+
+// NewCustom returns an editor that runs command with args. The args may
+// contain {{path}} and {{line}} placeholders ({{line}} is 1 when no line
+// is given); without a {{path}} placeholder the path is appended.
+// newWindowArg, if set, is passed first when a new window is requested.
+// Terminal editors are attached to the terminal and waited for.
+func NewCustom(name, command string, args []string, newWindowArg string, terminal bool) (Editor, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("custom editor has no name")
+	}
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("custom editor '%s' has no command", name)
+	}
+	return &custom{
+		name:         name,
+		command:      command,
+		args:         args,
+		newWindowArg: newWindowArg,
+		terminal:     terminal,
+	}, nil
+}
+
+func (c *custom) Name() string    { return c.name }
+func (c *custom) Command() string { return c.command }
+func (c *custom) Terminal() bool  { return c.terminal }
+func (c *custom) Detect() bool    { return onPath(c.command) }
+
+func (c *custom) Args(path string, opts Options) []string {
+	line := max(opts.Line, 1)
+	replacer := strings.NewReplacer(
+		placeholderPath, path,
+		placeholderLine, strconv.Itoa(line),
+	)
+
+	var args []string
+	if opts.NewWindow && c.newWindowArg != "" {
+		args = append(args, c.newWindowArg)
+	}
+	hasPath := false
+	for _, arg := range c.args {
+		if strings.Contains(arg, placeholderPath) {
+			hasPath = true
+		}
+		args = append(args, replacer.Replace(arg))
+	}
+	if !hasPath {
+		args = append(args, path)
+	}
+	return args
+}
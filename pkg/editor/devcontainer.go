@@ -0,0 +1,98 @@
+package editor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DevContainerCLI is the dev container command installed from VS Code
+// ("Dev Containers: Install devcontainer CLI"), which has an open command
+const DevContainerCLI = "devcontainer"
+
+// DevContainerCommand builds the command that opens the project at root
+// in the dev container described by config, a path relative to root:
+// "devcontainer open" when that command is installed, otherwise e (or VS
+// Code for editors without remote support) with a dev-container folder
+// URI.
+func DevContainerCommand(e Editor, root, config string) *exec.Cmd {
+	if onPath(DevContainerCLI) {
+		return exec.Command(DevContainerCLI, "open", root)
+	}
+	command := Code
+	if e != nil && OpensWorkspaceFiles(e) {
+		command = e.Command()
+	}
+	return exec.Command(command, "--folder-uri", DevContainerURI(root, DevContainerWorkspaceFolder(root, config)))
+}
+
+// DevContainerURI returns the URI VS Code opens the folder workspace of
+// the dev container for root with
+func DevContainerURI(root, workspace string) string {
+	return "vscode-remote://dev-container+" + hex.EncodeToString([]byte(root)) + workspace
+}
+
+// DevContainerWorkspaceFolder returns the folder the project is mounted
+// on inside the container: the workspaceFolder of the configuration, or
+// /workspaces/<folder name> as used by default
+func DevContainerWorkspaceFolder(root, config string) string {
+	fallback := path.Join("/workspaces", filepath.Base(root))
+	data, err := os.ReadFile(filepath.Join(root, config))
+	if err != nil {
+		return fallback
+	}
+	var settings struct {
+		WorkspaceFolder string `json:"workspaceFolder"`
+	}
+	if err := json.Unmarshal(stripJSONComments(data), &settings); err != nil || settings.WorkspaceFolder == "" {
+		return fallback
+	}
+	folder := strings.ReplaceAll(settings.WorkspaceFolder, "${localWorkspaceFolderBasename}", filepath.Base(root))
+	if strings.Contains(folder, "${") {
+		return fallback
+	}
+	return folder
+}
+
+// stripJSONComments removes the // and /* */ comments that
+// devcontainer.json allows, leaving strings untouched
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
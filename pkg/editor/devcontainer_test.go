@@ -0,0 +1,31 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDevContainerWorkspaceFolder(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "api")
+	os.MkdirAll(filepath.Join(root, ".devcontainer"), 0755)
+	config := filepath.Join(".devcontainer", "devcontainer.json")
+
+	if got := DevContainerWorkspaceFolder(root, config); got != "/workspaces/api" {
+		t.Errorf("without a config = %q, want /workspaces/api", got)
+	}
+
+	os.WriteFile(filepath.Join(root, config), []byte(`{
+	// Comments are allowed
+	"image": "mcr.microsoft.com/devcontainers/go", /* and this */
+	"remoteUser": "http://not/a//comment",
+	"workspaceFolder": "/src/${localWorkspaceFolderBasename}"
+}`), 0644)
+	if got := DevContainerWorkspaceFolder(root, config); got != "/src/api" {
+		t.Errorf("with workspaceFolder = %q, want /src/api", got)
+	}
+
+	if got := DevContainerURI("/home/me/api", "/workspaces/api"); got != "vscode-remote://dev-container+2f686f6d652f6d652f617069/workspaces/api" {
+		t.Errorf("DevContainerURI() = %q", got)
+	}
+}
@@ -0,0 +1,397 @@
+// Package editor launches editors and file managers. Editors are looked up
+// by name in a registry holding the built-in editors and, in registries
+// made with NewRegistry, any custom editors defined in the config.
+package editor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+// Names of the built-in editors and their aliases
+const (
+	Code     = "code"
+	VSCode   = "vscode"
+	Cursor   = "cursor"
+	Sublime  = "subl"
+	SublAlt  = "sublime"
+	Atom     = "atom"
+	Vim      = "vim"
+	NeoVim   = "nvim"
+	Emacs    = "emacs"
+	Idea     = "idea"
+	IntelliJ = "intellij"
+	WebStorm = "webstorm"
+	GoLand   = "goland"
+	PyCharm  = "pycharm"
+	Open     = "open"     // macOS
+	XdgOpen  = "xdg-open" // Linux
+	Explorer = "explorer" // Windows
+
+	// Auto picks the best installed editor each time a path is opened
+	Auto = "auto"
+)
+
+// preferred lists the built-in editors Auto picks from, best first: GUI
+// editors, then terminal editors, then the file openers of the OS
+var preferred = []string{Code, Cursor, Sublime, Idea, GoLand, WebStorm, PyCharm, Atom, NeoVim, Vim, Emacs, Open, XdgOpen, Explorer}
+
+// Options controls how a path is opened
+type Options struct {
+	Line      int  // Line to jump to; 0 opens the file at the top
+	NewWindow bool // Open in a new window rather than reusing one
+
+	// Session is a session file that vim and neovim write when they quit;
+	// with LoadSession it is also loaded on start. Other editors ignore it.
+	Session     string
+	LoadSession bool
+}
+
+// Editor is an editor that paths can be opened in
+type Editor interface {
+	// Name returns the name the editor is registered under
+	Name() string
+	// Command returns the executable that is run
+	Command() string
+	// Args returns the arguments that open path
+	Args(path string, opts Options) []string
+	// Terminal reports whether the editor runs in the terminal, in which
+	// case it is attached to it and waited for
+	Terminal() bool
+	// Detect reports whether the editor is installed
+	Detect() bool
+}
+
+// Registry holds editors by name and alias. The package-level functions
+// use a registry of the built-in editors; NewRegistry returns a copy of it
+// that custom editors can be added to.
+type Registry struct {
+	mu      sync.RWMutex
+	editors map[string]Editor
+}
+
+// builtins holds the built-in editors, registered by init
+var builtins = &Registry{editors: make(map[string]Editor)}
+
+// NewRegistry returns a registry holding the built-in editors
+func NewRegistry() *Registry {
+	builtins.mu.RLock()
+	defer builtins.mu.RUnlock()
+	r := &Registry{editors: make(map[string]Editor, len(builtins.editors))}
+	for name, e := range builtins.editors {
+		r.editors[name] = e
+	}
+	return r
+}
+
+// Register adds e to the registry under its name and aliases, replacing
+// any editor already registered under those names
+func (r *Registry) Register(e Editor, aliases ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.editors[e.Name()] = e
+	for _, alias := range aliases {
+		r.editors[alias] = e
+	}
+}
+
+// Lookup returns the editor registered under name
+func (r *Registry) Lookup(name string) (Editor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.editors[name]
+	return e, ok
+}
+
+// Get returns the editor registered under name. Unknown names are run as a
+// command with the path as its only argument.
+func (r *Registry) Get(name string) Editor {
+	if e, ok := r.Lookup(name); ok {
+		return e
+	}
+	return &custom{name: name, command: name}
+}
+
+// Resolve returns the editor to open paths in for the editor setting
+// name, picking the best installed editor for Auto
+func (r *Registry) Resolve(name string) (Editor, error) {
+	if name != Auto {
+		return r.Get(name), nil
+	}
+	for _, candidate := range preferred {
+		if e, ok := r.Lookup(candidate); ok && e.Detect() {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("no known editor found on PATH (run 'projector editors' to see which are supported)")
+}
+
+// Names returns the registered editor names and aliases, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.editors))
+	for name := range r.editors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns the registered editors, without aliases, sorted by name
+func (r *Registry) All() []Editor {
+	var editors []Editor
+	for _, name := range r.Names() {
+		if e, _ := r.Lookup(name); e.Name() == name {
+			editors = append(editors, e)
+		}
+	}
+	return editors
+}
+
+// Aliases returns the other names e is registered under, sorted
+func (r *Registry) Aliases(e Editor) []string {
+	var aliases []string
+	for _, name := range r.Names() {
+		if other, _ := r.Lookup(name); other == e && name != e.Name() {
+			aliases = append(aliases, name)
+		}
+	}
+	return aliases
+}
+
+// Detected returns the names of the registered editors that are
+// installed, sorted. Aliases are not included.
+func (r *Registry) Detected() []string {
+	var names []string
+	for _, e := range r.All() {
+		if e.Detect() {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// OpenPath opens path in the editor registered under name, or the best
+// installed one for Auto
+func (r *Registry) OpenPath(name, path string, opts Options) error {
+	e, err := r.Resolve(name)
+	if err != nil {
+		return err
+	}
+	return Launch(e, path, opts)
+}
+
+// Lookup returns the built-in editor registered under name
+func Lookup(name string) (Editor, bool) { return builtins.Lookup(name) }
+
+// Get is Registry.Get for the built-in editors
+func Get(name string) Editor { return builtins.Get(name) }
+
+// Resolve is Registry.Resolve for the built-in editors
+func Resolve(name string) (Editor, error) { return builtins.Resolve(name) }
+
+// Names returns the built-in editor names and aliases, sorted
+func Names() []string { return builtins.Names() }
+
+// All returns the built-in editors, without aliases, sorted by name
+func All() []Editor { return builtins.All() }
+
+// Aliases returns the other names the built-in editor e is registered under
+func Aliases(e Editor) []string { return builtins.Aliases(e) }
+
+// Detected returns the names of the installed built-in editors, sorted
+func Detected() []string { return builtins.Detected() }
+
+// Command builds the command that opens path in e
+func Command(e Editor, path string, opts Options) *exec.Cmd {
+	cmd := exec.Command(e.Command(), e.Args(path, opts)...)
+	if e.Terminal() {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	return cmd
+}
+
+// OpenPath is Registry.OpenPath for the built-in editors
+func OpenPath(name, path string, opts Options) error {
+	return builtins.OpenPath(name, path, opts)
+}
+
+// Launch opens path in e. GUI editors are started in the background;
+// terminal editors are waited for.
+func Launch(e Editor, path string, opts Options) error {
+	cmd := Command(e, path, opts)
+	if e.Terminal() {
+		return cmd.Run()
+	}
+	return cmd.Start()
+}
+
+// KeepsSessions reports whether e writes and loads Options.Session
+func KeepsSessions(e Editor) bool {
+	return e.Name() == Vim || e.Name() == NeoVim
+}
+
+// OpensWorkspaceFiles reports whether e opens VS Code .code-workspace
+// files as multi-root workspaces
+func OpensWorkspaceFiles(e Editor) bool {
+	return e.Name() == Code || e.Name() == Cursor
+}
+
+// Reveal opens a folder in the file manager of the operating system
+// (Finder, Explorer or the desktop's default via xdg-open)
+func Reveal(path string) error {
+	return FileManagerCommand(runtime.GOOS, path).Start()
+}
+
+// FileManagerCommand builds the command that opens path in the file
+// manager of the operating system goos
+func FileManagerCommand(goos, path string) *exec.Cmd {
+	switch goos {
+	case "darwin":
+		return exec.Command(Open, path)
+	case "windows":
+		return exec.Command(Explorer, path)
+	default:
+		return exec.Command(XdgOpen, path)
+	}
+}
+
+// Browse opens url in the web browser: the one named by $BROWSER, or else
+// the default browser of the operating system
+func Browse(url string) error {
+	return BrowserCommand(runtime.GOOS, os.Getenv("BROWSER"), url).Start()
+}
+
+// BrowserCommand builds the command that opens url in browser, or in the
+// default browser of the operating system goos when browser is empty
+func BrowserCommand(goos, browser, url string) *exec.Cmd {
+	if browser != "" {
+		return exec.Command(browser, url)
+	}
+	switch goos {
+	case "darwin":
+		return exec.Command(Open, url)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return exec.Command(XdgOpen, url)
+	}
+}
+
+// builtin is an editor known to projector
+type builtin struct {
+	name     string
+	command  string
+	terminal bool
+	args     func(path string, opts Options) []string
+}
+
+func (b *builtin) Name() string    { return b.name }
+func (b *builtin) Command() string { return b.command }
+func (b *builtin) Terminal() bool  { return b.terminal }
+func (b *builtin) Detect() bool    { return onPath(b.command) }
+
+func (b *builtin) Args(path string, opts Options) []string {
+	return b.args(path, opts)
+}
+
+func init() {
+	builtins.Register(&builtin{name: Code, command: Code, args: vscodeArgs}, VSCode)
+	builtins.Register(&builtin{name: Cursor, command: Cursor, args: vscodeArgs})
+	builtins.Register(&builtin{name: Sublime, command: Sublime, args: colonLineArgs}, SublAlt)
+	builtins.Register(&builtin{name: Atom, command: Atom, args: colonLineArgs})
+	builtins.Register(&builtin{name: Vim, command: Vim, terminal: true, args: vimArgs})
+	builtins.Register(&builtin{name: NeoVim, command: NeoVim, terminal: true, args: vimArgs})
+	builtins.Register(&builtin{name: Emacs, command: Emacs, terminal: true, args: plusLineArgs})
+	builtins.Register(&builtin{name: Idea, command: Idea, args: jetBrainsArgs}, IntelliJ)
+	builtins.Register(&builtin{name: WebStorm, command: WebStorm, args: jetBrainsArgs})
+	builtins.Register(&builtin{name: GoLand, command: GoLand, args: jetBrainsArgs})
+	builtins.Register(&builtin{name: PyCharm, command: PyCharm, args: jetBrainsArgs})
+	builtins.Register(&builtin{name: Open, command: Open, args: pathArgs})
+	builtins.Register(&builtin{name: XdgOpen, command: XdgOpen, args: pathArgs})
+	builtins.Register(&builtin{name: Explorer, command: Explorer, args: pathArgs})
+}
+
+// onPath reports whether command is an executable on PATH
+func onPath(command string) bool {
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+// vscodeArgs returns the arguments used by VS Code and Cursor. Paths inside
+// a WSL distro (\\wsl$\Distro\...) are opened with the WSL remote
+// extension, "--remote wsl+Distro /linux/path", rather than over the share.
+func vscodeArgs(path string, opts Options) []string {
+	var args []string
+	if opts.NewWindow {
+		args = append(args, "--new-window")
+	}
+	if distro, linuxPath, ok := paths.ParseWSL(path); ok {
+		args = append(args, "--remote", "wsl+"+distro)
+		path = linuxPath
+	}
+	if opts.Line > 0 {
+		return append(args, "--goto", fmt.Sprintf("%s:%d", path, opts.Line))
+	}
+	return append(args, path)
+}
+
+// colonLineArgs returns the "file:line" arguments used by Sublime and Atom
+func colonLineArgs(path string, opts Options) []string {
+	var args []string
+	if opts.NewWindow {
+		args = append(args, "--new-window")
+	}
+	if opts.Line > 0 {
+		return append(args, fmt.Sprintf("%s:%d", path, opts.Line))
+	}
+	return append(args, path)
+}
+
+// plusLineArgs returns the "+line file" arguments used by vim and emacs
+func plusLineArgs(path string, opts Options) []string {
+	if opts.Line > 0 {
+		return []string{fmt.Sprintf("+%d", opts.Line), path}
+	}
+	return []string{path}
+}
+
+// vimArgs returns the arguments used by vim and neovim: those of
+// plusLineArgs, preceded by "-S session" to load a session and a command
+// that writes the session when the editor quits
+func vimArgs(path string, opts Options) []string {
+	if opts.Session == "" {
+		return plusLineArgs(path, opts)
+	}
+	var args []string
+	if opts.LoadSession {
+		args = append(args, "-S", opts.Session)
+	}
+	quoted := "'" + strings.ReplaceAll(opts.Session, "'", "''") + "'"
+	args = append(args, "-c", "autocmd VimLeavePre * execute 'mksession!' fnameescape("+quoted+")")
+	return append(args, plusLineArgs(path, opts)...)
+}
+
+// jetBrainsArgs returns the "--line N file" arguments used by JetBrains IDEs
+func jetBrainsArgs(path string, opts Options) []string {
+	if opts.Line > 0 {
+		return []string{"--line", strconv.Itoa(opts.Line), path}
+	}
+	return []string{path}
+}
+
+// pathArgs returns just the path, for openers that take no options
+func pathArgs(path string, _ Options) []string {
+	return []string{path}
+}
@@ -0,0 +1,187 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCommand_Line(t *testing.T) {
+	tests := []struct {
+		editor string
+		want   []string
+	}{
+		{Code, []string{"code", "--goto", "/p/main.go:7"}},
+		{VSCode, []string{"code", "--goto", "/p/main.go:7"}},
+		{Vim, []string{"vim", "+7", "/p/main.go"}},
+		{Sublime, []string{"subl", "/p/main.go:7"}},
+		{GoLand, []string{"goland", "--line", "7", "/p/main.go"}},
+		{"unknown-editor", []string{"unknown-editor", "/p/main.go"}},
+	}
+	for _, tt := range tests {
+		cmd := Command(Get(tt.editor), "/p/main.go", Options{Line: 7})
+		got := append([]string{filepath.Base(cmd.Args[0])}, cmd.Args[1:]...)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Command(%s) args = %v, want %v", tt.editor, got, tt.want)
+		}
+	}
+
+	cmd := Command(Get(Code), "/p", Options{NewWindow: true})
+	if strings.Join(cmd.Args[1:], " ") != "--new-window /p" {
+		t.Errorf("Command() without line args = %v", cmd.Args)
+	}
+}
+
+func TestCommand_Session(t *testing.T) {
+	save := "-c autocmd VimLeavePre * execute 'mksession!' fnameescape('/s/it''s.vim')"
+	cmd := Command(Get(NeoVim), "/p", Options{Session: "/s/it's.vim", LoadSession: true})
+	if got, want := strings.Join(cmd.Args[1:], " "), "-S /s/it's.vim "+save+" /p"; got != want {
+		t.Errorf("Command() args = %q, want %q", got, want)
+	}
+
+	cmd = Command(Get(Vim), "/p/main.go", Options{Line: 7, Session: "/s/it's.vim"})
+	if got, want := strings.Join(cmd.Args[1:], " "), save+" +7 /p/main.go"; got != want {
+		t.Errorf("Command() without loading args = %q, want %q", got, want)
+	}
+
+	// Editors without sessions ignore them
+	cmd = Command(Get(Code), "/p", Options{Session: "/s/p.vim", LoadSession: true})
+	if got := strings.Join(cmd.Args[1:], " "); got != "/p" {
+		t.Errorf("Command(code) args = %q", got)
+	}
+}
+
+func TestCommand_WSL(t *testing.T) {
+	cmd := Command(Get(Code), `\\wsl$\Ubuntu\home\me\app\main.go`, Options{Line: 3, NewWindow: true})
+	want := "--new-window --remote wsl+Ubuntu --goto /home/me/app/main.go:3"
+	if got := strings.Join(cmd.Args[1:], " "); got != want {
+		t.Errorf("Command() args = %q, want %q", got, want)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	if e, ok := Lookup(IntelliJ); !ok || e.Name() != Idea {
+		t.Errorf("Lookup(%s) = %v, %v; want the %s editor", IntelliJ, e, ok, Idea)
+	}
+	if _, ok := Lookup("zed"); ok {
+		t.Error("Lookup(zed) found an editor before it was registered")
+	}
+	if !Get(Vim).Terminal() || Get(Code).Terminal() {
+		t.Error("expected vim, but not code, to be a terminal editor")
+	}
+
+	zed, err := NewCustom("zed", "zed", nil, "--new", false)
+	if err != nil {
+		t.Fatalf("NewCustom() error = %v", err)
+	}
+	r := NewRegistry()
+	r.Register(zed)
+	if e, ok := r.Lookup("zed"); !ok || e.Command() != "zed" {
+		t.Errorf("Lookup(zed) = %v, %v after registering it", e, ok)
+	}
+	if e, ok := r.Lookup(IntelliJ); !ok || e.Name() != Idea {
+		t.Errorf("expected the registry to hold the built-in editors, got %v, %v", e, ok)
+	}
+	if _, ok := Lookup("zed"); ok {
+		t.Error("expected registering in a registry to leave the built-in editors unchanged")
+	}
+}
+
+func TestCustom_Args(t *testing.T) {
+	tests := []struct {
+		args []string
+		opts Options
+		want string
+	}{
+		{nil, Options{}, "/p/main.go"},
+		{nil, Options{NewWindow: true}, "-n /p/main.go"},
+		{[]string{"--wait"}, Options{Line: 4}, "--wait /p/main.go"},
+		{[]string{"{{path}}:{{line}}"}, Options{Line: 4}, "/p/main.go:4"},
+		{[]string{"{{path}}:{{line}}"}, Options{}, "/p/main.go:1"},
+		{[]string{"-g", "{{line}}", "{{path}}"}, Options{Line: 9, NewWindow: true}, "-n -g 9 /p/main.go"},
+	}
+	for _, tt := range tests {
+		e, err := NewCustom("ed", "ed", tt.args, "-n", false)
+		if err != nil {
+			t.Fatalf("NewCustom() error = %v", err)
+		}
+		if got := strings.Join(e.Args("/p/main.go", tt.opts), " "); got != tt.want {
+			t.Errorf("Args(%v, %+v) = %q, want %q", tt.args, tt.opts, got, tt.want)
+		}
+	}
+
+	if _, err := NewCustom("ed", " ", nil, "", false); err == nil {
+		t.Error("NewCustom() without a command should fail")
+	}
+	if _, err := NewCustom("", "ed", nil, "", false); err == nil {
+		t.Error("NewCustom() without a name should fail")
+	}
+}
+
+func TestFileManagerCommand(t *testing.T) {
+	tests := map[string]string{
+		"darwin":  Open,
+		"windows": Explorer,
+		"linux":   XdgOpen,
+		"freebsd": XdgOpen,
+	}
+	for goos, want := range tests {
+		cmd := FileManagerCommand(goos, "/work/api")
+		if got := filepath.Base(cmd.Args[0]); got != want || cmd.Args[len(cmd.Args)-1] != "/work/api" {
+			t.Errorf("FileManagerCommand(%s) = %v, want %s /work/api", goos, cmd.Args, want)
+		}
+	}
+}
+
+func TestBrowserCommand(t *testing.T) {
+	tests := []struct {
+		goos, browser string
+		want          []string
+	}{
+		{"darwin", "", []string{Open, "https://example.com"}},
+		{"windows", "", []string{"rundll32", "url.dll,FileProtocolHandler", "https://example.com"}},
+		{"linux", "", []string{XdgOpen, "https://example.com"}},
+		{"linux", "firefox", []string{"firefox", "https://example.com"}},
+	}
+	for _, tt := range tests {
+		cmd := BrowserCommand(tt.goos, tt.browser, "https://example.com")
+		got := append([]string{filepath.Base(cmd.Args[0])}, cmd.Args[1:]...)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BrowserCommand(%s, %q) = %v, want %v", tt.goos, tt.browser, got, tt.want)
+		}
+	}
+}
+
+func TestResolve_Auto(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts do not stand in for executables on Windows")
+	}
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+
+	if _, err := Resolve(Auto); err == nil {
+		t.Error("Resolve(auto) should fail when no editor is installed")
+	}
+
+	for _, name := range []string{XdgOpen, Vim} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if e, err := Resolve(Auto); err != nil || e.Name() != Vim {
+		t.Errorf("Resolve(auto) = %v, %v; want vim", e, err)
+	}
+	if e, err := Resolve(Code); err != nil || e.Name() != Code {
+		t.Errorf("Resolve(code) = %v, %v; want code even when not installed", e, err)
+	}
+
+	if got := strings.Join(Detected(), " "); got != "vim xdg-open" {
+		t.Errorf("Detected() = %q, want %q", got, "vim xdg-open")
+	}
+	if got := Aliases(Get(Code)); len(got) != 1 || got[0] != VSCode {
+		t.Errorf("Aliases(code) = %v, want [vscode]", got)
+	}
+}
@@ -0,0 +1,382 @@
+// Package export provides encoding and decoding of projects in portable
+// formats (JSON, YAML, TOML and CSV) for backup and migration between machines.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// Format represents an export file format
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+	FormatCSV  Format = "csv"
+)
+
+// Formats lists all supported formats
+var Formats = []Format{FormatJSON, FormatYAML, FormatTOML, FormatCSV}
+
+// csvHeader is the column layout used for CSV files
+var csvHeader = []string{"kind", "name", "rootPath", "tags", "enabled", "description", "notes", "language"}
+
+// Document is the exported data: favorites and, optionally, the cache
+type Document struct {
+	Favorites []*models.Project       `json:"favorites"`
+	Cache     *storage.CachedProjects `json:"cache,omitempty"`
+}
+
+// ParseFormat parses a format name (case-insensitive, "yml" is accepted for YAML)
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	case "csv":
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported format '%s' (supported: json, yaml, toml, csv)", name)
+	}
+}
+
+// FormatFromPath guesses the format from a file extension
+func FormatFromPath(path string) (Format, bool) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return "", false
+	}
+	format, err := ParseFormat(ext)
+	if err != nil {
+		return "", false
+	}
+	return format, true
+}
+
+// Encode writes the document to w in the given format.
+// Paths are written with the home directory collapsed to ~ so
+// exports can be imported on a machine with a different home.
+func Encode(w io.Writer, doc *Document, format Format) error {
+	doc = collapseDocument(doc)
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(doc, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize projects: %w", err)
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case FormatYAML:
+		generic, err := toGeneric(doc)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(generic)
+		if err != nil {
+			return fmt.Errorf("failed to serialize projects: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case FormatTOML:
+		generic, err := toGeneric(doc)
+		if err != nil {
+			return err
+		}
+		data, err := toml.Marshal(generic)
+		if err != nil {
+			return fmt.Errorf("failed to serialize projects: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case FormatCSV:
+		return encodeCSV(w, doc)
+	default:
+		return fmt.Errorf("unsupported format '%s'", format)
+	}
+}
+
+// Decode reads a document in the given format from r.
+// Paths are expanded and project kinds are set from their location.
+// JSON input may also be a projects.json, of any schema version, or the
+// projects file of the VS Code Project Manager extension; its projects
+// become the favorites.
+func Decode(r io.Reader, format Format) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	doc := &Document{}
+	switch format {
+	case FormatJSON:
+		if storage.IsProjectsFile(data) {
+			if doc.Favorites, err = storage.DecodeProjects(data); err != nil {
+				return nil, fmt.Errorf("failed to parse projects file: %w", err)
+			}
+		} else if err := json.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	case FormatYAML:
+		var generic map[string]interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if err := fromGeneric(generic, doc); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		var generic map[string]interface{}
+		if err := toml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+		if err := fromGeneric(generic, doc); err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		if doc, err = decodeCSV(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format '%s'", format)
+	}
+
+	expandDocument(doc)
+	return doc, nil
+}
+
+// toGeneric converts the document into maps keyed by the JSON field names,
+// so that YAML and TOML exports use the same keys as projects.json.
+func toGeneric(doc *Document) (map[string]interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize projects: %w", err)
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to serialize projects: %w", err)
+	}
+	return dropNulls(generic).(map[string]interface{}), nil
+}
+
+// fromGeneric converts decoded maps back into a document
+func fromGeneric(generic map[string]interface{}, doc *Document) error {
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return fmt.Errorf("failed to convert input: %w", err)
+	}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("failed to convert input: %w", err)
+	}
+	return nil
+}
+
+// dropNulls removes null values, which TOML cannot represent
+func dropNulls(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if item == nil {
+				delete(value, k)
+				continue
+			}
+			value[k] = dropNulls(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = dropNulls(item)
+		}
+	}
+	return v
+}
+
+// encodeCSV writes one row per project with its kind in the first column
+func encodeCSV(w io.Writer, doc *Document) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, group := range documentGroups(doc) {
+		for _, p := range group.projects {
+			row := []string{
+				string(group.kind),
+				p.Name,
+				p.RootPath,
+				strings.Join(p.Tags, ";"),
+				strconv.FormatBool(p.Enabled),
+				p.Description,
+				p.Notes,
+				p.Language,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// decodeCSV parses rows written by encodeCSV
+func decodeCSV(data []byte) (*Document, error) {
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return &Document{}, nil
+	}
+
+	// Map columns by header name so column order doesn't matter
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"name", "rootPath"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("failed to parse CSV: missing '%s' column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	doc := &Document{}
+	for line, record := range records[1:] {
+		p := &models.Project{
+			Name:        field(record, "name"),
+			RootPath:    field(record, "rootPath"),
+			Tags:        []string{},
+			Enabled:     true,
+			Description: field(record, "description"),
+			Notes:       field(record, "notes"),
+			Language:    field(record, "language"),
+		}
+		if tags := field(record, "tags"); tags != "" {
+			p.Tags = strings.Split(tags, ";")
+		}
+		if enabled := field(record, "enabled"); enabled != "" {
+			if p.Enabled, err = strconv.ParseBool(enabled); err != nil {
+				return nil, fmt.Errorf("failed to parse CSV line %d: invalid enabled value '%s'", line+2, enabled)
+			}
+		}
+
+		kind := models.ProjectKind(field(record, "kind"))
+		if kind == "" {
+			kind = models.KindFavorite
+		}
+		if err := addToDocument(doc, kind, p); err != nil {
+			return nil, fmt.Errorf("failed to parse CSV line %d: %w", line+2, err)
+		}
+	}
+
+	return doc, nil
+}
+
+// addToDocument appends a project to the favorites or cache bucket for kind
+func addToDocument(doc *Document, kind models.ProjectKind, p *models.Project) error {
+	if kind == models.KindFavorite {
+		doc.Favorites = append(doc.Favorites, p)
+		return nil
+	}
+
+	if doc.Cache == nil {
+		doc.Cache = &storage.CachedProjects{}
+	}
+	switch kind {
+	case models.KindGit:
+		doc.Cache.Git = append(doc.Cache.Git, p)
+	case models.KindSVN:
+		doc.Cache.SVN = append(doc.Cache.SVN, p)
+	case models.KindMercurial:
+		doc.Cache.Mercurial = append(doc.Cache.Mercurial, p)
+	case models.KindVSCode:
+		doc.Cache.VSCode = append(doc.Cache.VSCode, p)
+	case models.KindAny:
+		doc.Cache.Any = append(doc.Cache.Any, p)
+	default:
+		return fmt.Errorf("unknown kind '%s'", kind)
+	}
+	return nil
+}
+
+// projectGroup pairs a kind with its projects
+type projectGroup struct {
+	kind     models.ProjectKind
+	projects []*models.Project
+}
+
+// documentGroups returns the document's projects grouped by kind
+func documentGroups(doc *Document) []projectGroup {
+	groups := []projectGroup{{models.KindFavorite, doc.Favorites}}
+	if doc.Cache != nil {
+		groups = append(groups,
+			projectGroup{models.KindGit, doc.Cache.Git},
+			projectGroup{models.KindSVN, doc.Cache.SVN},
+			projectGroup{models.KindMercurial, doc.Cache.Mercurial},
+			projectGroup{models.KindVSCode, doc.Cache.VSCode},
+			projectGroup{models.KindAny, doc.Cache.Any},
+		)
+	}
+	return groups
+}
+
+// collapseDocument returns a copy of the document with collapsed paths
+func collapseDocument(doc *Document) *Document {
+	collapse := func(projects []*models.Project) []*models.Project {
+		result := make([]*models.Project, len(projects))
+		for i, p := range projects {
+			cp := *p
+			cp.RootPath = paths.Collapse(p.RootPath)
+			result[i] = &cp
+		}
+		return result
+	}
+
+	out := &Document{Favorites: collapse(doc.Favorites)}
+	if doc.Cache != nil {
+		out.Cache = &storage.CachedProjects{
+			Git:       collapse(doc.Cache.Git),
+			SVN:       collapse(doc.Cache.SVN),
+			Mercurial: collapse(doc.Cache.Mercurial),
+			VSCode:    collapse(doc.Cache.VSCode),
+			Any:       collapse(doc.Cache.Any),
+		}
+	}
+	return out
+}
+
+// expandDocument expands paths and sets kinds in place
+func expandDocument(doc *Document) {
+	for _, group := range documentGroups(doc) {
+		for _, p := range group.projects {
+			p.RootPath = paths.ExpandHome(p.RootPath)
+			p.Kind = group.kind
+			if p.Tags == nil {
+				p.Tags = []string{}
+			}
+		}
+	}
+}
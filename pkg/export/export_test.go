@@ -0,0 +1,162 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+func testDocument() *Document {
+	return &Document{
+		Favorites: []*models.Project{
+			{Name: "api", RootPath: "/srv/api", Tags: []string{"Work", "Go"}, Enabled: true, Description: "Public API"},
+			{Name: "blog", RootPath: "/srv/blog", Tags: []string{}, Enabled: false, Notes: "uses, commas"},
+		},
+		Cache: &storage.CachedProjects{
+			Git: []*models.Project{
+				{Name: "repo", RootPath: "/code/repo", Tags: []string{}, Enabled: true},
+			},
+		},
+	}
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, testDocument(), format); err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+
+			doc, err := Decode(&buf, format)
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+
+			if len(doc.Favorites) != 2 {
+				t.Fatalf("expected 2 favorites, got %d", len(doc.Favorites))
+			}
+			api := doc.Favorites[0]
+			if api.Name != "api" || api.RootPath != "/srv/api" || !api.Enabled {
+				t.Errorf("unexpected project: %+v", api)
+			}
+			if len(api.Tags) != 2 || api.Tags[1] != "Go" {
+				t.Errorf("expected tags [Work Go], got %v", api.Tags)
+			}
+			if api.Description != "Public API" {
+				t.Errorf("expected description to round-trip, got %q", api.Description)
+			}
+			if api.Kind != models.KindFavorite {
+				t.Errorf("expected kind favorites, got %s", api.Kind)
+			}
+
+			blog := doc.Favorites[1]
+			if blog.Enabled || blog.Notes != "uses, commas" {
+				t.Errorf("unexpected project: %+v", blog)
+			}
+
+			if doc.Cache == nil || len(doc.Cache.Git) != 1 {
+				t.Fatalf("expected 1 cached git project, got %+v", doc.Cache)
+			}
+			if doc.Cache.Git[0].Kind != models.KindGit {
+				t.Errorf("expected kind git, got %s", doc.Cache.Git[0].Kind)
+			}
+		})
+	}
+}
+
+func TestEncode_CollapsesHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	doc := &Document{Favorites: []*models.Project{
+		{Name: "p", RootPath: filepath.Join(home, "projects", "p"), Enabled: true},
+	}}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, doc, FormatJSON); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"~/projects/p"`) {
+		t.Errorf("expected collapsed path in output, got:\n%s", buf.String())
+	}
+	// The original document must not be modified
+	if doc.Favorites[0].RootPath != filepath.Join(home, "projects", "p") {
+		t.Errorf("Encode modified the input document")
+	}
+}
+
+func TestDecodeCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing columns", "kind,name\nfavorites,p\n"},
+		{"bad enabled", "name,rootPath,enabled\np,/p,maybe\n"},
+		{"unknown kind", "kind,name,rootPath\nweird,p,/p\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(tt.input), FormatCSV); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{"json", FormatJSON, false},
+		{"YAML", FormatYAML, false},
+		{"yml", FormatYAML, false},
+		{"toml", FormatTOML, false},
+		{"csv", FormatCSV, false},
+		{"xml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if f, ok := FormatFromPath("backup.yml"); !ok || f != FormatYAML {
+		t.Errorf("FormatFromPath(backup.yml) = %q, %v", f, ok)
+	}
+	if _, ok := FormatFromPath("backup"); ok {
+		t.Error("expected no format for path without extension")
+	}
+}
+
+func TestDecode_ProjectsFile(t *testing.T) {
+	// The projects file of the VS Code Project Manager extension
+	data := `[{"name": "api", "rootPath": "/srv/api", "paths": [], "tags": ["Work"]}]`
+	doc, err := Decode(strings.NewReader(data), FormatJSON)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(doc.Favorites) != 1 || doc.Cache != nil {
+		t.Fatalf("expected one favorite and no cache, got %+v", doc)
+	}
+	if p := doc.Favorites[0]; p.Name != "api" || !p.Enabled || p.Kind != models.KindFavorite {
+		t.Errorf("unexpected favorite %+v", p)
+	}
+}
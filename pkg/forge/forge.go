@@ -0,0 +1,214 @@
+// Package forge lists repositories of GitHub organizations and users and
+// GitLab groups through their REST APIs, and builds the web URLs of
+// repositories.
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Default API endpoints
+const (
+	GitHubAPI = "https://api.github.com"
+	GitLabAPI = "https://gitlab.com"
+)
+
+// errNotFound is returned for 404 responses
+var errNotFound = errors.New("not found")
+
+// Repo is a repository hosted on a forge
+type Repo struct {
+	Name        string
+	FullName    string
+	Description string
+	CloneURL    string
+	SSHURL      string
+	Archived    bool
+	Fork        bool
+}
+
+// Client talks to a forge API
+type Client struct {
+	// BaseURL is the API root, e.g. GitHubAPI or a GitHub Enterprise
+	// ".../api/v3" URL for GitHub, and the instance URL for GitLab
+	BaseURL string
+
+	// Token authenticates requests when set, raising rate limits and
+	// including private repositories
+	Token string
+
+	HTTP *http.Client
+}
+
+// NewClient creates a client for the API at baseURL
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL: baseURL,
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// githubRepo is a repository in GitHub API responses
+type githubRepo struct {
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	CloneURL    string `json:"clone_url"`
+	SSHURL      string `json:"ssh_url"`
+	Archived    bool   `json:"archived"`
+	Fork        bool   `json:"fork"`
+}
+
+// gitlabProject is a project in GitLab API responses
+type gitlabProject struct {
+	Path              string          `json:"path"`
+	PathWithNamespace string          `json:"path_with_namespace"`
+	Description       string          `json:"description"`
+	HTTPURL           string          `json:"http_url_to_repo"`
+	SSHURL            string          `json:"ssh_url_to_repo"`
+	Archived          bool            `json:"archived"`
+	ForkedFrom        json.RawMessage `json:"forked_from_project"`
+}
+
+// ListGitHub returns the repositories of a GitHub organization, or of a
+// user when there is no organization with that name
+func (c *Client) ListGitHub(ctx context.Context, owner string) ([]Repo, error) {
+	path := "/orgs/" + url.PathEscape(owner) + "/repos"
+	repos, err := c.listGitHub(ctx, path)
+	if errors.Is(err, errNotFound) {
+		repos, err = c.listGitHub(ctx, "/users/"+url.PathEscape(owner)+"/repos")
+	}
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("GitHub organization or user '%s' not found", owner)
+	}
+	return repos, err
+}
+
+func (c *Client) listGitHub(ctx context.Context, path string) ([]Repo, error) {
+	var repos []Repo
+	next := c.BaseURL + path + "?per_page=100"
+	for next != "" {
+		var page []githubRepo
+		resp, err := c.get(ctx, next, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page {
+			repos = append(repos, Repo{
+				Name:        r.Name,
+				FullName:    r.FullName,
+				Description: r.Description,
+				CloneURL:    r.CloneURL,
+				SSHURL:      r.SSHURL,
+				Archived:    r.Archived,
+				Fork:        r.Fork,
+			})
+		}
+		next = nextLink(resp.Header.Get("Link"))
+	}
+	return repos, nil
+}
+
+// ListGitLab returns the projects of a GitLab group, including subgroups
+func (c *Client) ListGitLab(ctx context.Context, group string) ([]Repo, error) {
+	var repos []Repo
+	base := c.BaseURL + "/api/v4/groups/" + url.PathEscape(group) + "/projects?per_page=100&include_subgroups=true"
+	for page := "1"; page != ""; {
+		var projects []gitlabProject
+		resp, err := c.get(ctx, base+"&page="+page, &projects)
+		if errors.Is(err, errNotFound) {
+			return nil, fmt.Errorf("GitLab group '%s' not found", group)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			repos = append(repos, Repo{
+				Name:        p.Path,
+				FullName:    p.PathWithNamespace,
+				Description: p.Description,
+				CloneURL:    p.HTTPURL,
+				SSHURL:      p.SSHURL,
+				Archived:    p.Archived,
+				Fork:        len(p.ForkedFrom) > 0 && string(p.ForkedFrom) != "null",
+			})
+		}
+		page = resp.Header.Get("X-Next-Page")
+		if _, err := strconv.Atoi(page); err != nil {
+			page = ""
+		}
+	}
+	return repos, nil
+}
+
+// Release is a published release of a repository
+type Release struct {
+	TagName string `json:"tag_name"`
+	URL     string `json:"html_url"`
+}
+
+// LatestGitHubRelease returns the newest published release of a GitHub
+// repository given as "owner/name"; drafts and prereleases are skipped
+func (c *Client) LatestGitHubRelease(ctx context.Context, repo string) (Release, error) {
+	var release Release
+	_, err := c.get(ctx, c.BaseURL+"/repos/"+repo+"/releases/latest", &release)
+	if errors.Is(err, errNotFound) {
+		return Release{}, fmt.Errorf("no releases found for %s", repo)
+	}
+	return release, err
+}
+
+// get fetches rawURL and decodes the JSON body into v
+func (c *Client) get(ctx context.Context, rawURL string, v interface{}) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		// Both GitHub and GitLab accept personal access tokens as bearer tokens
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("authentication failed (check the API token)")
+	case resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return nil, fmt.Errorf("API rate limit exceeded (set a token to raise it)")
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return resp, nil
+}
+
+// linkNext matches the rel="next" URL of an RFC 8288 Link header
+var linkNext = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// nextLink returns the next page URL from a Link header, or "" on the last page
+func nextLink(header string) string {
+	if m := linkNext.FindStringSubmatch(header); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListGitHub_PaginatesAndFallsBackToUser(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("expected bearer token, got %q", got)
+		}
+		switch {
+		case r.URL.Path == "/orgs/alice/repos":
+			http.NotFound(w, r)
+		case r.URL.Path == "/users/alice/repos" && r.URL.Query().Get("page") == "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/users/alice/repos?page=2>; rel="next", <%s/users/alice/repos?page=2>; rel="last"`, srv.URL, srv.URL))
+			fmt.Fprint(w, `[{"name": "api", "full_name": "alice/api", "clone_url": "https://example.com/alice/api.git", "ssh_url": "git@example.com:alice/api.git"}]`)
+		case r.URL.Path == "/users/alice/repos":
+			fmt.Fprint(w, `[{"name": "old", "full_name": "alice/old", "archived": true, "fork": true}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	repos, err := NewClient(srv.URL, "secret").ListGitHub(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("ListGitHub() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("expected 2 repos across pages, got %+v", repos)
+	}
+	if repos[0].Name != "api" || repos[0].SSHURL != "git@example.com:alice/api.git" {
+		t.Errorf("unexpected first repo: %+v", repos[0])
+	}
+	if !repos[1].Archived || !repos[1].Fork {
+		t.Errorf("expected archived fork, got %+v", repos[1])
+	}
+}
+
+func TestListGitLab(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/groups/acme%2Fplatform/projects" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"path": "api", "path_with_namespace": "acme/platform/api", "http_url_to_repo": "https://gitlab.example.com/acme/platform/api.git", "forked_from_project": null}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "")
+		fmt.Fprint(w, `[{"path": "web", "forked_from_project": {"id": 1}}]`)
+	}))
+	defer srv.Close()
+
+	repos, err := NewClient(srv.URL, "").ListGitLab(context.Background(), "acme/platform")
+	if err != nil {
+		t.Fatalf("ListGitLab() error = %v", err)
+	}
+	if len(repos) != 2 || repos[0].FullName != "acme/platform/api" || repos[0].Fork || !repos[1].Fork {
+		t.Errorf("unexpected repos: %+v", repos)
+	}
+}
+
+func TestListGitLab_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "").ListGitLab(context.Background(), "missing"); err == nil {
+		t.Error("expected error for unknown group")
+	}
+}
+
+func TestLatestGitHubRelease(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/alice/tool/releases/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"tag_name": "v1.4.0", "html_url": "https://example.com/alice/tool/releases/v1.4.0"}`)
+	}))
+	defer srv.Close()
+
+	release, err := NewClient(srv.URL, "").LatestGitHubRelease(context.Background(), "alice/tool")
+	if err != nil || release.TagName != "v1.4.0" || release.URL == "" {
+		t.Errorf("LatestGitHubRelease() = %+v, %v", release, err)
+	}
+	if _, err := NewClient(srv.URL, "").LatestGitHubRelease(context.Background(), "alice/none"); err == nil {
+		t.Error("expected an error for a repository without releases")
+	}
+}
@@ -0,0 +1,116 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Placeholders of web URL templates
+const (
+	placeholderHost   = "{host}"
+	placeholderRepo   = "{repo}"
+	placeholderBranch = "{branch}"
+	placeholderPath   = "{path}"
+)
+
+// WebTemplates are the web URL templates of the forge kinds accepted in
+// the browseHosts config option. The part up to {repo} is the repository
+// page; the rest links to a branch and a path in it.
+var WebTemplates = map[string]string{
+	"github":    "https://{host}/{repo}/tree/{branch}/{path}",
+	"gitlab":    "https://{host}/{repo}/-/tree/{branch}/{path}",
+	"bitbucket": "https://{host}/{repo}/src/{branch}/{path}",
+	"gitea":     "https://{host}/{repo}/src/{branch}/{path}",
+}
+
+// publicHosts are the kinds of the public forges
+var publicHosts = map[string]string{
+	"github.com":    "github",
+	"gitlab.com":    "gitlab",
+	"bitbucket.org": "bitbucket",
+	"codeberg.org":  "gitea",
+}
+
+// WebTemplate returns the web URL template for repositories on host: the
+// one configured in hosts, as a forge kind or a template, then the public
+// forges, then a guess from the host name, which falls back to the layout
+// of GitHub. A host with a port also matches hosts configured without it.
+func WebTemplate(host string, hosts map[string]string) string {
+	name, _, _ := strings.Cut(host, ":")
+	kind, ok := "", false
+	for _, candidate := range []string{host, name} {
+		for h, v := range hosts {
+			if strings.EqualFold(h, candidate) {
+				kind, ok = v, true
+				break
+			}
+		}
+		if ok {
+			break
+		}
+	}
+	if !ok {
+		kind, ok = publicHosts[name]
+	}
+	if !ok {
+		kind = "github"
+		for _, guess := range []string{"gitlab", "bitbucket", "gitea", "forgejo"} {
+			if strings.Contains(name, guess) {
+				kind = guess
+				break
+			}
+		}
+		if kind == "forgejo" {
+			kind = "gitea"
+		}
+	}
+	if tmpl, ok := WebTemplates[strings.ToLower(kind)]; ok {
+		return tmpl
+	}
+	return kind
+}
+
+// ValidateWebTemplate checks a browseHosts value: a forge kind, or a
+// template with a {repo} placeholder
+func ValidateWebTemplate(value string) error {
+	if _, ok := WebTemplates[strings.ToLower(value)]; ok {
+		return nil
+	}
+	if !strings.Contains(value, placeholderRepo) {
+		return fmt.Errorf("must be github, gitlab, bitbucket, gitea or a URL template with {repo}, got '%s'", value)
+	}
+	return nil
+}
+
+// WebURL fills a web URL template in for the repository repo (such as
+// "owner/name") on host. Without a branch or path the URL of the
+// repository page is returned; a path without a branch links to it on
+// HEAD, the default branch.
+func WebURL(tmpl, host, repo, branch, path string) string {
+	path = strings.Trim(path, "/")
+	if branch == "" && path == "" {
+		if i := strings.Index(tmpl, placeholderRepo); i >= 0 {
+			tmpl = tmpl[:i+len(placeholderRepo)]
+		}
+	} else if branch == "" {
+		branch = "HEAD"
+	}
+
+	u := strings.NewReplacer(
+		placeholderHost, host,
+		placeholderRepo, repo,
+		placeholderBranch, escapeSegments(branch),
+		placeholderPath, escapeSegments(path),
+	).Replace(tmpl)
+	return strings.TrimSuffix(u, "/")
+}
+
+// escapeSegments escapes each segment of a slash-separated path
+func escapeSegments(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,53 @@
+package forge
+
+import "testing"
+
+func TestWebTemplate(t *testing.T) {
+	hosts := map[string]string{"git.example.com": "gitlab", "SSH.example.com": "https://code.example.com/{repo}"}
+	tests := map[string]string{
+		"github.com":           WebTemplates["github"],
+		"bitbucket.org":        WebTemplates["bitbucket"],
+		"codeberg.org":         WebTemplates["gitea"],
+		"git.example.com":      WebTemplates["gitlab"],
+		"ssh.example.com":      "https://code.example.com/{repo}",
+		"gitlab.corp.local":    WebTemplates["gitlab"],
+		"forgejo.corp":         WebTemplates["gitea"],
+		"git.corp":             WebTemplates["github"],
+		"git.example.com:8443": WebTemplates["gitlab"],
+	}
+	for host, want := range tests {
+		if got := WebTemplate(host, hosts); got != want {
+			t.Errorf("WebTemplate(%s) = %s, want %s", host, got, want)
+		}
+	}
+}
+
+func TestWebURL(t *testing.T) {
+	tests := []struct {
+		kind, branch, path, want string
+	}{
+		{"github", "", "", "https://github.com/acme/api"},
+		{"github", "feature/x", "", "https://github.com/acme/api/tree/feature/x"},
+		{"github", "", "cmd/main.go", "https://github.com/acme/api/tree/HEAD/cmd/main.go"},
+		{"gitlab", "main", "docs/read me.md", "https://github.com/acme/api/-/tree/main/docs/read%20me.md"},
+		{"bitbucket", "main", "/src/", "https://github.com/acme/api/src/main/src"},
+		{"gitea", "", "", "https://github.com/acme/api"},
+		{"gitea", "", "README.md", "https://github.com/acme/api/src/HEAD/README.md"},
+	}
+	for _, tt := range tests {
+		if got := WebURL(WebTemplates[tt.kind], "github.com", "acme/api", tt.branch, tt.path); got != tt.want {
+			t.Errorf("WebURL(%s, %q, %q) = %s, want %s", tt.kind, tt.branch, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWebTemplate(t *testing.T) {
+	for _, ok := range []string{"github", "GitLab", "https://code.example.com/{repo}/src/{branch}/{path}"} {
+		if err := ValidateWebTemplate(ok); err != nil {
+			t.Errorf("ValidateWebTemplate(%s) = %v", ok, err)
+		}
+	}
+	if err := ValidateWebTemplate("sourcehut"); err == nil {
+		t.Error("expected an error for an unknown kind")
+	}
+}
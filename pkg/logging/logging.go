@@ -0,0 +1,90 @@
+// Package logging configures the structured logger (log/slog) used for
+// diagnostics throughout projector. Packages log through the slog default
+// logger; Setup points it at stderr and, optionally, a log file.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures the logger
+type Options struct {
+	Level  string // debug, info, warn or error (default warn)
+	Format string // text or json (default text)
+	File   string // optional log file, written in addition to stderr
+}
+
+// ParseLevel parses a level name (case-insensitive). An empty name is warn.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "", "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level '%s' (use debug, info, warn or error)", name)
+}
+
+// NewHandler creates a handler writing records of at least the level in
+// opts to w in the format in opts
+func NewHandler(w io.Writer, opts Options) (slog.Handler, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		return slog.NewTextHandler(w, handlerOpts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, handlerOpts), nil
+	}
+	return nil, fmt.Errorf("invalid log format '%s' (use text or json)", opts.Format)
+}
+
+// Setup makes a logger configured by opts the slog default. The returned
+// closer closes the log file, if any, and is never nil.
+func Setup(opts Options) (io.Closer, error) {
+	var w io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+
+	if opts.File != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.File), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = io.MultiWriter(os.Stderr, f)
+		closer = f
+	}
+
+	handler, err := NewHandler(w, opts)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	slog.SetDefault(slog.New(handler))
+	return closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":      slog.LevelWarn,
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+	for name, want := range tests {
+		got, err := ParseLevel(name)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, Options{Level: "info", Format: FormatJSON})
+	if err != nil {
+		t.Fatalf("NewHandler failed: %v", err)
+	}
+	logger := slog.New(handler)
+	logger.Debug("hidden")
+	logger.Info("scan finished", "projects", 3)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["msg"] != "scan finished" || record["projects"] != float64(3) {
+		t.Errorf("unexpected record: %v", record)
+	}
+
+	if _, err := NewHandler(&buf, Options{Format: "xml"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestSetup_LogFile(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	file := filepath.Join(t.TempDir(), "logs", "projector.log")
+	closer, err := Setup(Options{Level: "debug", File: file})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	slog.Debug("written to file")
+	closer.Close()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "written to file") {
+		t.Errorf("log file does not contain the record: %q", data)
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ArchivedProject is a project that was archived: hidden from lists and
+// pickers until it is unarchived, with its folder optionally moved or
+// compressed into the archive location
+type ArchivedProject struct {
+	Project    *Project    `json:"project"`
+	Kind       ProjectKind `json:"kind"` // The list the project came from
+	ArchivedAt time.Time   `json:"archivedAt"`
+	Location   string      `json:"location,omitempty"` // Moved folder or .tar.gz file; empty if left in place
+}
+
+// FindArchived finds an archived project by name (case-insensitive)
+func FindArchived(archived []*ArchivedProject, name string) *ArchivedProject {
+	for _, a := range archived {
+		if strings.EqualFold(a.Project.Name, name) {
+			return a
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "errors"
+
+// Errors that project lookups wrap or match, so callers can tell why a
+// lookup failed with errors.Is rather than by the message
+var (
+	// ErrProjectNotFound means a name matched no project
+	ErrProjectNotFound = errors.New("project not found")
+
+	// ErrAmbiguousName means a name matched several projects and none
+	// exactly
+	ErrAmbiguousName = errors.New("ambiguous project name")
+
+	// ErrPathMissing means a project's folder does not exist, or is on a
+	// volume that is not mounted
+	ErrPathMissing = errors.New("project path missing")
+)
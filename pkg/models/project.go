@@ -2,7 +2,13 @@
 // project lists, and project kinds used throughout the projector application.
 package models
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/paths"
+)
 
 // ProjectKind represents the type/source of a project
 type ProjectKind string
@@ -18,11 +24,48 @@ const (
 
 // Project represents a saved project
 type Project struct {
-	Name     string      `json:"name"`
-	RootPath string      `json:"rootPath"`
-	Tags     []string    `json:"tags"`
-	Enabled  bool        `json:"enabled"`
-	Kind     ProjectKind `json:"-"` // Internal use only, not persisted
+	Name          string        `json:"name"`
+	RootPath      string        `json:"rootPath"`
+	Tags          []string      `json:"tags"`
+	Enabled       bool          `json:"enabled"`
+	Description   string        `json:"description,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
+	Language      string        `json:"language,omitempty"`
+	RemoteURL     string        `json:"remoteUrl,omitempty"`
+	DefaultBranch string        `json:"defaultBranch,omitempty"` // Recorded by 'scan --collect-meta'
+	LastCommit    time.Time     `json:"lastCommit,omitzero"`     // Recorded by 'scan --collect-meta'
+	Icon          string        `json:"icon,omitempty"`
+	Color         string        `json:"color,omitempty"`         // Color of the name in lists, overriding tagColors
+	WorkspaceFile string        `json:"workspaceFile,omitempty"` // *.code-workspace file in the root, opened instead of the folder
+	DevContainer  string        `json:"devContainer,omitempty"`  // Dev container configuration, relative to the root, opened by 'open --devcontainer'
+	Parent        string        `json:"parent,omitempty"`        // Name of the project whose folder contains this one, e.g. a monorepo
+	FolderKind    ProjectKind   `json:"folderKind,omitempty"`    // What the folder is (git, svn, ...); set for favorites
+	Volume        string        `json:"volume,omitempty"`        // Mount point of the removable or network volume holding the folder
+	Kind          ProjectKind   `json:"-"`                       // Internal use only, not persisted
+	Offline       bool          `json:"-"`                       // Volume is not mounted; set when loaded, not persisted
+	Kinds         []ProjectKind `json:"-"`                       // Every list the folder was found in, in priority order; set when loaded
+}
+
+// FolderKinds lists the kinds a project folder can have, in detection order
+var FolderKinds = []ProjectKind{KindGit, KindSVN, KindMercurial, KindVSCode, KindAny}
+
+// ParseFolderKind parses a folder kind name (case-insensitive)
+func ParseFolderKind(name string) (ProjectKind, error) {
+	for _, kind := range FolderKinds {
+		if strings.EqualFold(name, string(kind)) {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("invalid kind '%s' (use git, svn, mercurial, vscode or any)", name)
+}
+
+// EffectiveKind returns what the project folder is: its folder kind when
+// known, otherwise the kind of the list it belongs to
+func (p *Project) EffectiveKind() ProjectKind {
+	if p.FolderKind != "" {
+		return p.FolderKind
+	}
+	return p.Kind
 }
 
 // NewProject creates a new enabled project with the given name and path
@@ -46,6 +89,22 @@ func (p *Project) HasTag(tag string) bool {
 	return false
 }
 
+// TagSeparator separates the levels of a hierarchical tag, as in
+// Work/ClientA/Backend
+const TagSeparator = "/"
+
+// HasTagUnder checks if a project has a tag or one below it in the
+// hierarchy: Work matches Work and Work/ClientA, but not Workshop
+func (p *Project) HasTagUnder(tag string) bool {
+	tag = strings.TrimSuffix(tag, TagSeparator)
+	for _, t := range p.Tags {
+		if t == tag || strings.HasPrefix(t, tag+TagSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
 // AddTag adds a tag to the project if not already present
 func (p *Project) AddTag(tag string) {
 	if !p.HasTag(tag) {
@@ -63,6 +122,25 @@ func (p *Project) RemoveTag(tag string) {
 	}
 }
 
+// RenameTag replaces a tag in place, keeping its position.
+// If the project already has the new tag, the old one is just removed.
+// It returns false if the project does not have the old tag.
+func (p *Project) RenameTag(oldTag, newTag string) bool {
+	if !p.HasTag(oldTag) {
+		return false
+	}
+	if p.HasTag(newTag) {
+		p.RemoveTag(oldTag)
+		return true
+	}
+	for i, t := range p.Tags {
+		if t == oldTag {
+			p.Tags[i] = newTag
+		}
+	}
+	return true
+}
+
 // ProjectList represents a collection of projects
 type ProjectList struct {
 	Projects []*Project
@@ -107,18 +185,18 @@ func (pl *ProjectList) FindByName(name string) *Project {
 // FindByPath finds a project by its root path
 func (pl *ProjectList) FindByPath(path string) *Project {
 	for _, p := range pl.Projects {
-		if p.RootPath == path {
+		if paths.Equal(p.RootPath, path) {
 			return p
 		}
 	}
 	return nil
 }
 
-// FilterByTag returns projects that have a specific tag
+// FilterByTag returns projects that have a specific tag or one below it
 func (pl *ProjectList) FilterByTag(tag string) []*Project {
 	var result []*Project
 	for _, p := range pl.Projects {
-		if p.HasTag(tag) {
+		if p.HasTagUnder(tag) {
 			result = append(result, p)
 		}
 	}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -53,6 +54,33 @@ func TestProject_HasTag(t *testing.T) {
 	}
 }
 
+func TestProject_HasTagUnder(t *testing.T) {
+	p := &Project{Tags: []string{"Work/ClientA/Backend", "Go"}}
+
+	tests := []struct {
+		tag      string
+		expected bool
+	}{
+		{"Work", true},
+		{"Work/", true},
+		{"Work/ClientA", true},
+		{"Work/ClientA/Backend", true},
+		{"Work/ClientA/Back", false},
+		{"Work/ClientB", false},
+		{"Wor", false},
+		{"Go", true},
+		{"ClientA", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := p.HasTagUnder(tt.tag); got != tt.expected {
+				t.Errorf("HasTagUnder(%q) = %v, want %v", tt.tag, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestProject_AddTag(t *testing.T) {
 	p := NewProject("test", "/test")
 
@@ -109,6 +137,29 @@ func TestProject_RemoveTag(t *testing.T) {
 	}
 }
 
+func TestProject_RenameTag(t *testing.T) {
+	p := &Project{Name: "test", Tags: []string{"Work", "Go", "Backend"}}
+
+	if !p.RenameTag("Go", "Golang") {
+		t.Error("expected rename of existing tag to succeed")
+	}
+	if p.Tags[1] != "Golang" {
+		t.Errorf("expected renamed tag to keep its position, got %v", p.Tags)
+	}
+
+	// Renaming onto a tag the project already has merges them
+	if !p.RenameTag("Backend", "Work") {
+		t.Error("expected rename onto existing tag to succeed")
+	}
+	if len(p.Tags) != 2 || p.HasTag("Backend") {
+		t.Errorf("expected [Work Golang], got %v", p.Tags)
+	}
+
+	if p.RenameTag("NonExistent", "Other") {
+		t.Error("expected rename of missing tag to return false")
+	}
+}
+
 func TestNewProjectList(t *testing.T) {
 	pl := NewProjectList(KindGit)
 
@@ -327,3 +378,28 @@ func TestProjectKind_Values(t *testing.T) {
 		}
 	}
 }
+
+func TestParseFolderKind(t *testing.T) {
+	for _, kind := range FolderKinds {
+		got, err := ParseFolderKind(strings.ToUpper(string(kind)))
+		if err != nil || got != kind {
+			t.Errorf("ParseFolderKind(%q) = %q, %v; want %q", kind, got, err, kind)
+		}
+	}
+	for _, name := range []string{"", "favorites", "cvs"} {
+		if _, err := ParseFolderKind(name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+}
+
+func TestProject_EffectiveKind(t *testing.T) {
+	p := NewProject("api", "/api")
+	if got := p.EffectiveKind(); got != KindFavorite {
+		t.Errorf("expected %q without a folder kind, got %q", KindFavorite, got)
+	}
+	p.FolderKind = KindGit
+	if got := p.EffectiveKind(); got != KindGit {
+		t.Errorf("expected %q, got %q", KindGit, got)
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// OpenRecord notes that a project was opened in an editor
+type OpenRecord struct {
+	Path     string    `json:"path"`
+	OpenedAt time.Time `json:"openedAt"`
+}
+
+// ProjectContext is what a project was left in when it was last opened:
+// the checked out Git branch and, for vim and neovim, the session file
+// written when the editor quit
+type ProjectContext struct {
+	Branch  string    `json:"branch,omitempty"`
+	Session string    `json:"session,omitempty"`
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// Session is a named set of projects that were open together, so they can
+// be reopened later. Like workspaces, members are referenced by root path.
+type Session struct {
+	Name     string    `json:"name"`
+	SavedAt  time.Time `json:"savedAt"`
+	Projects []string  `json:"projects"`
+}
+
+// OpenedSince returns the paths of the records opened at or after since,
+// most recently opened first, each listed once
+func OpenedSince(records []OpenRecord, since time.Time) []string {
+	seen := make(map[string]bool)
+	var opened []string
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		if r.OpenedAt.Before(since) || seen[r.Path] {
+			continue
+		}
+		seen[r.Path] = true
+		opened = append(opened, r.Path)
+	}
+	return opened
+}
+
+// FindSession finds a session by name (case-insensitive)
+func FindSession(sessions []*Session, name string) *Session {
+	for _, s := range sessions {
+		if strings.EqualFold(s.Name, name) {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpenedSince(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	records := []OpenRecord{
+		{Path: "/old", OpenedAt: now.Add(-48 * time.Hour)},
+		{Path: "/api", OpenedAt: now.Add(-3 * time.Hour)},
+		{Path: "/web", OpenedAt: now.Add(-2 * time.Hour)},
+		{Path: "/api", OpenedAt: now.Add(-1 * time.Hour)},
+	}
+
+	got := OpenedSince(records, now.Add(-8*time.Hour))
+	want := []string{"/api", "/web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OpenedSince() = %v, want %v", got, want)
+	}
+	if got := OpenedSince(records, now); len(got) != 0 {
+		t.Errorf("expected nothing opened since now, got %v", got)
+	}
+}
+
+func TestFindSession(t *testing.T) {
+	sessions := []*Session{{Name: "Feature-X"}}
+	if FindSession(sessions, "feature-x") != sessions[0] {
+		t.Error("expected case-insensitive match")
+	}
+	if FindSession(sessions, "other") != nil {
+		t.Error("expected nil for unknown session")
+	}
+}
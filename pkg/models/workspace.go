@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Workspace groups several projects under a single name.
+// Members are referenced by root path so renaming a project keeps it in the workspace.
+type Workspace struct {
+	Name     string   `json:"name"`
+	Projects []string `json:"projects"`
+}
+
+// ValidateWorkspaceName checks that name can be used as a file name: the
+// workspace file generated for multi-root editors is named after it
+func ValidateWorkspaceName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("workspace name cannot be empty")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid workspace name '%s' (cannot contain '/', '\\' or '..')", name)
+	}
+	return nil
+}
+
+// NewWorkspace creates an empty workspace with the given name
+func NewWorkspace(name string) *Workspace {
+	return &Workspace{
+		Name:     name,
+		Projects: []string{},
+	}
+}
+
+// HasProject checks if the workspace contains a project path
+func (w *Workspace) HasProject(path string) bool {
+	for _, p := range w.Projects {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// AddProject adds a project path if not already present.
+// Returns false if the path was already a member.
+func (w *Workspace) AddProject(path string) bool {
+	if w.HasProject(path) {
+		return false
+	}
+	w.Projects = append(w.Projects, path)
+	return true
+}
+
+// RemoveProject removes a project path.
+// Returns false if the path was not a member.
+func (w *Workspace) RemoveProject(path string) bool {
+	for i, p := range w.Projects {
+		if p == path {
+			w.Projects = append(w.Projects[:i], w.Projects[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// FindWorkspace finds a workspace by name (case-insensitive)
+func FindWorkspace(workspaces []*Workspace, name string) *Workspace {
+	for _, w := range workspaces {
+		if strings.EqualFold(w.Name, name) {
+			return w
+		}
+	}
+	return nil
+}
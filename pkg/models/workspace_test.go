@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestWorkspace_AddRemoveProject(t *testing.T) {
+	w := NewWorkspace("platform")
+
+	if !w.AddProject("/code/api") {
+		t.Error("expected first add to succeed")
+	}
+	if w.AddProject("/code/api") {
+		t.Error("expected duplicate add to fail")
+	}
+	w.AddProject("/code/web")
+
+	if !w.HasProject("/code/web") {
+		t.Error("expected workspace to contain /code/web")
+	}
+	if len(w.Projects) != 2 {
+		t.Errorf("expected 2 projects, got %d", len(w.Projects))
+	}
+
+	if !w.RemoveProject("/code/api") {
+		t.Error("expected remove to succeed")
+	}
+	if w.RemoveProject("/code/api") {
+		t.Error("expected second remove to fail")
+	}
+	if w.HasProject("/code/api") {
+		t.Error("expected /code/api to be removed")
+	}
+}
+
+func TestFindWorkspace(t *testing.T) {
+	workspaces := []*Workspace{NewWorkspace("Platform"), NewWorkspace("docs")}
+
+	if w := FindWorkspace(workspaces, "platform"); w == nil || w.Name != "Platform" {
+		t.Errorf("expected to find Platform, got %v", w)
+	}
+	if w := FindWorkspace(workspaces, "missing"); w != nil {
+		t.Errorf("expected nil, got %v", w)
+	}
+}
+
+func TestValidateWorkspaceName(t *testing.T) {
+	for _, name := range []string{"frontend", "client-a", "v1.2", "My Stack"} {
+		if err := ValidateWorkspaceName(name); err != nil {
+			t.Errorf("ValidateWorkspaceName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", " ", "../../x", "a/b", `a\b`, "..", "x..y"} {
+		if err := ValidateWorkspaceName(name); err == nil {
+			t.Errorf("ValidateWorkspaceName(%q) = nil, want an error", name)
+		}
+	}
+}
@@ -4,11 +4,14 @@ package output
 
 import (
 	"fmt"
+	"io"
+	"path/filepath"
 	"strings"
 
 	"github.com/fatih/color"
 
 	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
 )
 
 // MaxPathDisplayLength is the maximum length for displaying truncated paths.
@@ -17,6 +20,10 @@ const MaxPathDisplayLength = 50
 // Formatter handles output formatting
 type Formatter struct {
 	colored bool
+	icons   Icons
+
+	// tagNameColors color project names by tag, keyed by lowercase tag
+	tagNameColors map[string]*color.Color
 
 	// Colors
 	nameColor    *color.Color
@@ -29,26 +36,58 @@ type Formatter struct {
 	infoColor    *color.Color
 }
 
-// NewFormatter creates a new formatter
-func NewFormatter(colored bool) *Formatter {
+// FormatterOptions configures the colors and icons of a formatter
+type FormatterOptions struct {
+	// Theme colors the output elements, as returned by ResolveTheme; the
+	// zero value uses the dark theme
+	Theme Theme
+
+	// Icons are shown before project names, as returned by ResolveIcons;
+	// nil shows no icons
+	Icons Icons
+
+	// TagColors color the names of projects with one of their tags, as
+	// returned by ParseTagColors. The first of a project's tags with a
+	// color is used.
+	TagColors map[string]*color.Color
+}
+
+// NewFormatter creates a new formatter with the given options.
+// Colors are turned off when colored is false, the theme is "none",
+// or the NO_COLOR environment variable is set.
+func NewFormatter(colored bool, opts FormatterOptions) *Formatter {
+	theme := opts.Theme
+	if theme == (Theme{}) {
+		theme = Themes["dark"]
+	}
 	return &Formatter{
-		colored:      colored,
-		nameColor:    color.New(color.FgWhite, color.Bold),
-		pathColor:    color.New(color.FgCyan),
-		tagColor:     color.New(color.FgMagenta),
-		kindColor:    color.New(color.FgYellow),
-		successColor: color.New(color.FgGreen),
-		errorColor:   color.New(color.FgRed),
-		warnColor:    color.New(color.FgYellow),
-		infoColor:    color.New(color.FgBlue),
+		colored:       colored && !theme.none && !colorDisabledByEnv(),
+		icons:         opts.Icons,
+		tagNameColors: opts.TagColors,
+		nameColor:     mustParseColor(theme.Name),
+		pathColor:     mustParseColor(theme.Path),
+		tagColor:      mustParseColor(theme.Tag),
+		kindColor:     mustParseColor(theme.Kind),
+		successColor:  mustParseColor(theme.Success),
+		errorColor:    mustParseColor(theme.Error),
+		warnColor:     mustParseColor(theme.Warning),
+		infoColor:     mustParseColor(theme.Info),
 	}
 }
 
 // ListOptions configures how FormatProjectList displays projects
 type ListOptions struct {
-	ShowPath  bool // Show full path on separate line
-	ShowIndex bool // Show index numbers for selection
-	Grouped   bool // Group by project kind
+	ShowPath        bool // Show full path on separate line
+	ShowIndex       bool // Show index numbers for selection
+	Grouped         bool // Group by project kind
+	ShowDescription bool // Show description on separate line
+
+	// Pinned projects are listed first under "Recently Used", so they
+	// keep the lowest index numbers, and are left out of the rest
+	Pinned []*models.Project
+
+	// OpenCounts are shown after project names, keyed by root path
+	OpenCounts map[string]int
 }
 
 // formatProjectItem formats a single project item
@@ -67,9 +106,13 @@ func (f *Formatter) formatProjectItem(p *models.Project, index int, opts ListOpt
 		sb.WriteString(" ")
 	}
 
+	if icon := f.icon(p); icon != "" {
+		sb.WriteString(icon + " ")
+	}
+
 	// Name
 	if f.colored {
-		sb.WriteString(f.nameColor.Sprint(p.Name))
+		sb.WriteString(f.projectNameColor(p).Sprint(p.Name))
 	} else {
 		sb.WriteString(p.Name)
 	}
@@ -93,15 +136,40 @@ func (f *Formatter) formatProjectItem(p *models.Project, index int, opts ListOpt
 		}
 	}
 
+	// Offline indicator, for projects on a volume that is not mounted
+	if p.Offline {
+		if f.colored {
+			sb.WriteString(f.warnColor.Sprint(" (offline)"))
+		} else {
+			sb.WriteString(" (offline)")
+		}
+	}
+
+	// Open count badge
+	if n := opts.OpenCounts[p.RootPath]; n > 0 {
+		badge := fmt.Sprintf(" (%d opens)", n)
+		if n == 1 {
+			badge = " (1 open)"
+		}
+		if f.colored {
+			sb.WriteString(f.infoColor.Sprint(badge))
+		} else {
+			sb.WriteString(badge)
+		}
+	}
+
+	// Detail lines are indented to align with the name
+	detailIndent := indent
+	if opts.ShowIndex {
+		detailIndent += "    "
+	}
+
 	// Path
 	path := p.RootPath
 	if opts.ShowPath {
 		// Full path on new line
 		sb.WriteString("\n")
-		sb.WriteString(indent)
-		if opts.ShowIndex {
-			sb.WriteString("    ") // Extra indent to align with name
-		}
+		sb.WriteString(detailIndent)
 		if f.colored {
 			sb.WriteString(f.pathColor.Sprint(path))
 		} else {
@@ -110,9 +178,7 @@ func (f *Formatter) formatProjectItem(p *models.Project, index int, opts ListOpt
 	} else {
 		// Truncated path on same line
 		sb.WriteString(" - ")
-		if len(path) > MaxPathDisplayLength {
-			path = "..." + path[len(path)-(MaxPathDisplayLength-3):]
-		}
+		path = paths.Shorten(path, MaxPathDisplayLength)
 		if f.colored {
 			sb.WriteString(f.pathColor.Sprint(path))
 		} else {
@@ -120,6 +186,25 @@ func (f *Formatter) formatProjectItem(p *models.Project, index int, opts ListOpt
 		}
 	}
 
+	// Description
+	if (opts.ShowPath || opts.ShowDescription) && p.Description != "" {
+		sb.WriteString("\n")
+		sb.WriteString(detailIndent)
+		sb.WriteString(p.Description)
+	}
+
+	// Notes are only shown in the detailed (path) view
+	if opts.ShowPath && p.Notes != "" {
+		sb.WriteString("\n")
+		sb.WriteString(detailIndent)
+		if f.colored {
+			sb.WriteString(f.infoColor.Sprint("Notes: "))
+		} else {
+			sb.WriteString("Notes: ")
+		}
+		sb.WriteString(p.Notes)
+	}
+
 	return sb.String()
 }
 
@@ -134,11 +219,45 @@ func (f *Formatter) FormatProjectList(projects []*models.Project, opts ListOptio
 	indexedProjects := make([]*models.Project, 0, len(projects))
 	currentIndex := 1 // 1-based index
 
-	if opts.Grouped {
-		// Group by kind
-		groups := make(map[models.ProjectKind][]*models.Project)
+	if len(opts.Pinned) > 0 {
+		header := "Recently Used"
+		if f.colored {
+			sb.WriteString(f.kindColor.Sprint(header))
+		} else {
+			sb.WriteString(header)
+		}
+		sb.WriteString("\n")
+
+		pinned := make(map[*models.Project]bool, len(opts.Pinned))
+		for _, p := range opts.Pinned {
+			sb.WriteString(f.formatProjectItem(p, currentIndex, opts, "  "))
+			sb.WriteString("\n")
+			indexedProjects = append(indexedProjects, p)
+			currentIndex++
+			pinned[p] = true
+		}
+		sb.WriteString("\n")
+
+		rest := make([]*models.Project, 0, len(projects))
 		for _, p := range projects {
-			groups[p.Kind] = append(groups[p.Kind], p)
+			if !pinned[p] {
+				rest = append(rest, p)
+			}
+		}
+		projects = rest
+	}
+
+	nested := nestSubprojects(projects)
+
+	if opts.Grouped {
+		// Group by kind, keeping subprojects in the group of their parent
+		groups := make(map[models.ProjectKind][]nestedProject)
+		var kind models.ProjectKind
+		for _, n := range nested {
+			if n.depth == 0 {
+				kind = n.project.Kind
+			}
+			groups[kind] = append(groups[kind], n)
 		}
 
 		kindOrder := []models.ProjectKind{
@@ -165,19 +284,19 @@ func (f *Formatter) FormatProjectList(projects []*models.Project, opts ListOptio
 			}
 			sb.WriteString("\n")
 
-			for _, p := range ps {
-				sb.WriteString(f.formatProjectItem(p, currentIndex, opts, "  "))
+			for _, n := range ps {
+				sb.WriteString(f.formatProjectItem(n.project, currentIndex, opts, "  "+strings.Repeat("  ", n.depth)))
 				sb.WriteString("\n")
-				indexedProjects = append(indexedProjects, p)
+				indexedProjects = append(indexedProjects, n.project)
 				currentIndex++
 			}
 			sb.WriteString("\n")
 		}
 	} else {
-		for _, p := range projects {
-			sb.WriteString(f.formatProjectItem(p, currentIndex, opts, ""))
+		for _, n := range nested {
+			sb.WriteString(f.formatProjectItem(n.project, currentIndex, opts, strings.Repeat("  ", n.depth)))
 			sb.WriteString("\n")
-			indexedProjects = append(indexedProjects, p)
+			indexedProjects = append(indexedProjects, n.project)
 			currentIndex++
 		}
 	}
@@ -185,6 +304,161 @@ func (f *Formatter) FormatProjectList(projects []*models.Project, opts ListOptio
 	return strings.TrimSuffix(sb.String(), "\n"), indexedProjects
 }
 
+// nestedProject is a project in a list with its subproject depth
+type nestedProject struct {
+	project *models.Project
+	depth   int
+}
+
+// nestSubprojects orders projects so subprojects follow their parent,
+// keeping the order of projects otherwise. Subprojects whose parent is not
+// listed stay at the top level.
+func nestSubprojects(projects []*models.Project) []nestedProject {
+	children := make(map[*models.Project][]*models.Project)
+	isChild := make(map[*models.Project]bool)
+	for _, p := range projects {
+		if p.Parent == "" {
+			continue
+		}
+		for _, q := range projects {
+			if q != p && strings.EqualFold(q.Name, p.Parent) {
+				children[q] = append(children[q], p)
+				isChild[p] = true
+				break
+			}
+		}
+	}
+
+	nested := make([]nestedProject, 0, len(projects))
+	seen := make(map[*models.Project]bool, len(projects))
+	var add func(p *models.Project, depth int)
+	add = func(p *models.Project, depth int) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		nested = append(nested, nestedProject{p, depth})
+		for _, c := range children[p] {
+			add(c, depth+1)
+		}
+	}
+	for _, p := range projects {
+		if !isChild[p] {
+			add(p, 0)
+		}
+	}
+	// Projects that are each other's parents are listed at the top level
+	for _, p := range projects {
+		add(p, 0)
+	}
+	return nested
+}
+
+// FormatProjectDetails formats every field of a project as labelled lines
+func (f *Formatter) FormatProjectDetails(p *models.Project) string {
+	label := func(s string) string {
+		return fmt.Sprintf("%-13s", s+":")
+	}
+	value := func(c *color.Color, s string) string {
+		if f.colored {
+			return c.Sprint(s)
+		}
+		return s
+	}
+
+	var sb strings.Builder
+	sb.WriteString(label("Name") + value(f.nameColor, p.Name) + "\n")
+	sb.WriteString(label("Path") + value(f.pathColor, p.RootPath) + "\n")
+	kind := f.getKindHeader(p.Kind)
+	if p.FolderKind != "" && p.FolderKind != p.Kind {
+		kind += " (" + string(p.FolderKind) + ")"
+	}
+	sb.WriteString(label("Type") + value(f.kindColor, kind) + "\n")
+	if len(p.Kinds) > 1 {
+		kinds := make([]string, len(p.Kinds))
+		for i, k := range p.Kinds {
+			kinds[i] = string(k)
+		}
+		sb.WriteString(label("Found in") + strings.Join(kinds, ", ") + "\n")
+	}
+	if p.Parent != "" {
+		sb.WriteString(label("Parent") + value(f.nameColor, p.Parent) + "\n")
+	}
+	if len(p.Tags) > 0 {
+		sb.WriteString(label("Tags") + value(f.tagColor, strings.Join(p.Tags, ", ")) + "\n")
+	}
+	sb.WriteString(label("Enabled") + fmt.Sprintf("%t", p.Enabled) + "\n")
+	if p.Language != "" {
+		sb.WriteString(label("Language") + p.Language + "\n")
+	}
+	if p.RemoteURL != "" {
+		sb.WriteString(label("Remote") + p.RemoteURL + "\n")
+	}
+	if p.DefaultBranch != "" {
+		sb.WriteString(label("Branch") + p.DefaultBranch + "\n")
+	}
+	if !p.LastCommit.IsZero() {
+		sb.WriteString(label("Last commit") + p.LastCommit.Local().Format("2006-01-02 15:04") + "\n")
+	}
+	if p.WorkspaceFile != "" {
+		sb.WriteString(label("Workspace") + p.WorkspaceFile + "\n")
+	}
+	if p.DevContainer != "" {
+		sb.WriteString(label("Container") + p.DevContainer + "\n")
+	}
+	if p.Volume != "" {
+		volume := p.Volume
+		if p.Offline {
+			volume += " (offline)"
+		}
+		sb.WriteString(label("Volume") + volume + "\n")
+	}
+	if p.Description != "" {
+		sb.WriteString(label("Description") + p.Description + "\n")
+	}
+	if p.Notes != "" {
+		sb.WriteString(label("Notes") + p.Notes + "\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// FormatReadmePreview formats the first lines of the README file at
+// path of project p for the preview of interactive pickers. Lines are cut
+// to width runes when width is positive.
+func (f *Formatter) FormatReadmePreview(p *models.Project, path string, lines []string, width int) string {
+	if path == "" {
+		return "  " + f.FormatName(p.Name) + ": no README"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("  " + f.FormatName(p.Name) + ": " + f.FormatPath(filepath.Base(path)) + "\n")
+	for _, line := range lines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if width > 0 {
+			line = truncateCell(line, max(width-6, 4), false)
+		}
+		sb.WriteString(strings.TrimRight("  │ "+line, " ") + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// FormatName formats text in the project name color
+func (f *Formatter) FormatName(s string) string {
+	if f.colored {
+		return f.nameColor.Sprint(s)
+	}
+	return s
+}
+
+// FormatPath formats text in the path color
+func (f *Formatter) FormatPath(s string) string {
+	if f.colored {
+		return f.pathColor.Sprint(s)
+	}
+	return s
+}
+
 // getKindHeader returns the header for a project kind
 func (f *Formatter) getKindHeader(kind models.ProjectKind) string {
 	switch kind {
@@ -236,3 +510,28 @@ func (f *Formatter) FormatInfo(msg string) string {
 	}
 	return "ℹ " + msg
 }
+
+// FprintSuccess writes a success message, formatted by FormatSuccess, and
+// a newline to w. Like the other Fprint helpers it ignores write errors,
+// as fmt.Println does.
+func (f *Formatter) FprintSuccess(w io.Writer, msg string) {
+	fmt.Fprintln(w, f.FormatSuccess(msg))
+}
+
+// FprintError writes an error message, formatted by FormatError, and a
+// newline to w
+func (f *Formatter) FprintError(w io.Writer, msg string) {
+	fmt.Fprintln(w, f.FormatError(msg))
+}
+
+// FprintWarning writes a warning, formatted by FormatWarning, and a
+// newline to w
+func (f *Formatter) FprintWarning(w io.Writer, msg string) {
+	fmt.Fprintln(w, f.FormatWarning(msg))
+}
+
+// FprintInfo writes an info message, formatted by FormatInfo, and a
+// newline to w
+func (f *Formatter) FprintInfo(w io.Writer, msg string) {
+	fmt.Fprintln(w, f.FormatInfo(msg))
+}
@@ -8,7 +8,7 @@ import (
 )
 
 func TestFormatProjectList_Empty(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{}
 
 	output, indexed := f.FormatProjectList(projects, ListOptions{})
@@ -22,7 +22,7 @@ func TestFormatProjectList_Empty(t *testing.T) {
 }
 
 func TestFormatProjectList_NoIndex(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{
 		{Name: "project1", RootPath: "/path/to/project1", Enabled: true, Kind: models.KindFavorite},
 		{Name: "project2", RootPath: "/path/to/project2", Enabled: true, Kind: models.KindFavorite},
@@ -51,7 +51,7 @@ func TestFormatProjectList_NoIndex(t *testing.T) {
 }
 
 func TestFormatProjectList_WithIndex(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{
 		{Name: "project1", RootPath: "/path/to/project1", Enabled: true, Kind: models.KindFavorite},
 		{Name: "project2", RootPath: "/path/to/project2", Enabled: true, Kind: models.KindFavorite},
@@ -83,7 +83,7 @@ func TestFormatProjectList_WithIndex(t *testing.T) {
 }
 
 func TestFormatProjectList_WithTags(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{
 		{Name: "project1", RootPath: "/path/to/project1", Tags: []string{"Work", "Important"}, Enabled: true, Kind: models.KindFavorite},
 		{Name: "project2", RootPath: "/path/to/project2", Tags: []string{}, Enabled: true, Kind: models.KindFavorite},
@@ -113,7 +113,7 @@ func TestFormatProjectList_WithTags(t *testing.T) {
 }
 
 func TestFormatProjectList_Grouped(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{
 		{Name: "favorite1", RootPath: "/path/to/fav1", Enabled: true, Kind: models.KindFavorite},
 		{Name: "gitrepo1", RootPath: "/path/to/git1", Enabled: true, Kind: models.KindGit},
@@ -157,7 +157,7 @@ func TestFormatProjectList_Grouped(t *testing.T) {
 }
 
 func TestFormatProjectList_GroupedIndexMapping(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{
 		{Name: "gitrepo", RootPath: "/path/to/git", Enabled: true, Kind: models.KindGit},
 		{Name: "favorite", RootPath: "/path/to/fav", Enabled: true, Kind: models.KindFavorite},
@@ -185,8 +185,65 @@ func TestFormatProjectList_GroupedIndexMapping(t *testing.T) {
 	}
 }
 
+func TestFormatProjectList_Subprojects(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	projects := []*models.Project{
+		{Name: "billing", RootPath: "/work/mono/billing", Enabled: true, Parent: "mono", Kind: models.KindFavorite},
+		{Name: "orphan", RootPath: "/work/orphan", Enabled: true, Parent: "gone", Kind: models.KindFavorite},
+		{Name: "web", RootPath: "/work/mono/web", Enabled: true, Parent: "Mono", Kind: models.KindFavorite},
+		{Name: "mono", RootPath: "/work/mono", Enabled: true, Kind: models.KindGit},
+	}
+
+	output, indexed := f.FormatProjectList(projects, ListOptions{ShowIndex: true, Grouped: true})
+
+	var names []string
+	for _, p := range indexed {
+		names = append(names, p.Name)
+	}
+	// Subprojects follow their parent, in its group
+	if got := strings.Join(names, " "); got != "orphan mono billing web" {
+		t.Errorf("indexed = %s, want orphan mono billing web", got)
+	}
+	if !strings.Contains(output, "\n  [2] mono - /work/mono\n    [3] billing - /work/mono/billing\n    [4] web") {
+		t.Errorf("expected subprojects indented under their parent, got:\n%s", output)
+	}
+
+	output, _ = f.FormatProjectList(projects, ListOptions{})
+	if !strings.Contains(output, "/work/orphan\nmono - /work/mono\n  billing - /work/mono/billing\n  web") {
+		t.Errorf("expected subprojects indented in the flat list, got:\n%s", output)
+	}
+}
+
+func TestFormatProjectList_Pinned(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	fav := &models.Project{Name: "favorite", RootPath: "/path/to/fav", Enabled: true, Kind: models.KindFavorite}
+	git := &models.Project{Name: "gitrepo", RootPath: "/path/to/git", Enabled: true, Kind: models.KindGit}
+	other := &models.Project{Name: "other", RootPath: "/path/to/other", Enabled: true, Kind: models.KindGit}
+
+	opts := ListOptions{
+		ShowIndex:  true,
+		Grouped:    true,
+		Pinned:     []*models.Project{git},
+		OpenCounts: map[string]int{"/path/to/git": 3, "/path/to/fav": 1},
+	}
+	output, indexed := f.FormatProjectList([]*models.Project{fav, git, other}, opts)
+
+	if !strings.HasPrefix(output, "Recently Used\n  [1] gitrepo (3 opens)") {
+		t.Errorf("Expected pinned project first, got:\n%s", output)
+	}
+	if !strings.Contains(output, "[2] favorite (1 open)") {
+		t.Errorf("Expected favorite as [2] with its badge, got:\n%s", output)
+	}
+	if strings.Count(output, "gitrepo") != 1 {
+		t.Errorf("Expected pinned project listed once, got:\n%s", output)
+	}
+	if len(indexed) != 3 || indexed[0] != git || indexed[1] != fav || indexed[2] != other {
+		t.Errorf("Unexpected index mapping: %v", indexed)
+	}
+}
+
 func TestFormatProjectList_DisabledProject(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{
 		{Name: "enabled", RootPath: "/path/to/enabled", Enabled: true, Kind: models.KindFavorite},
 		{Name: "disabled", RootPath: "/path/to/disabled", Enabled: false, Kind: models.KindFavorite},
@@ -205,7 +262,7 @@ func TestFormatProjectList_DisabledProject(t *testing.T) {
 }
 
 func TestFormatProjectList_TruncatedPath(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	longPath := "/very/long/path/that/exceeds/fifty/characters/and/should/be/truncated"
 	projects := []*models.Project{
 		{Name: "project", RootPath: longPath, Enabled: true, Kind: models.KindFavorite},
@@ -230,7 +287,7 @@ func TestFormatProjectList_TruncatedPath(t *testing.T) {
 }
 
 func TestFormatProjectList_FullPath(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	longPath := "/very/long/path/that/exceeds/fifty/characters/and/should/not/be/truncated"
 	projects := []*models.Project{
 		{Name: "project", RootPath: longPath, Enabled: true, Kind: models.KindFavorite},
@@ -250,7 +307,7 @@ func TestFormatProjectList_FullPath(t *testing.T) {
 }
 
 func TestFormatProjectList_AllKinds(t *testing.T) {
-	f := NewFormatter(false)
+	f := NewFormatter(false, FormatterOptions{})
 	projects := []*models.Project{
 		{Name: "any", RootPath: "/path/any", Enabled: true, Kind: models.KindAny},
 		{Name: "vscode", RootPath: "/path/vscode", Enabled: true, Kind: models.KindVSCode},
@@ -294,3 +351,105 @@ func TestFormatProjectList_AllKinds(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatProjectList_Description(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	projects := []*models.Project{
+		{
+			Name:        "xq7",
+			RootPath:    "/path/xq7",
+			Enabled:     true,
+			Kind:        models.KindFavorite,
+			Description: "Billing service prototype",
+			Notes:       "Ask Dana before deploying",
+		},
+	}
+
+	// Plain list hides description and notes
+	output, _ := f.FormatProjectList(projects, ListOptions{})
+	if strings.Contains(output, "Billing") || strings.Contains(output, "Dana") {
+		t.Errorf("Expected no description or notes in plain list, got: %s", output)
+	}
+
+	// Picker shows description but not notes
+	output, _ = f.FormatProjectList(projects, ListOptions{ShowIndex: true, ShowDescription: true})
+	if !strings.Contains(output, "\n    Billing service prototype") {
+		t.Errorf("Expected indented description in picker, got: %s", output)
+	}
+	if strings.Contains(output, "Dana") {
+		t.Errorf("Expected no notes in picker, got: %s", output)
+	}
+
+	// Path view shows both
+	output, _ = f.FormatProjectList(projects, ListOptions{ShowPath: true})
+	if !strings.Contains(output, "Billing service prototype") {
+		t.Errorf("Expected description in path view, got: %s", output)
+	}
+	if !strings.Contains(output, "Notes: Ask Dana before deploying") {
+		t.Errorf("Expected notes in path view, got: %s", output)
+	}
+}
+
+func TestFormatProjectDetails(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	p := &models.Project{
+		Name:        "api",
+		RootPath:    "/work/api",
+		Tags:        []string{"Work", "Go"},
+		Enabled:     true,
+		Language:    "go",
+		Description: "Public API",
+		Kind:        models.KindGit,
+	}
+
+	output := f.FormatProjectDetails(p)
+	for _, want := range []string{"Name:        api", "Path:        /work/api", "Type:        Git Repositories", "Tags:        Work, Go", "Language:    go", "Description: Public API"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in details, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Notes:") {
+		t.Errorf("expected empty notes to be omitted, got:\n%s", output)
+	}
+
+	fav := &models.Project{Name: "web", RootPath: "/work/web", Kind: models.KindFavorite, FolderKind: models.KindGit}
+	if output := f.FormatProjectDetails(fav); !strings.Contains(output, "Type:        Favorites (git)") {
+		t.Errorf("expected folder kind in details, got:\n%s", output)
+	}
+	if strings.Contains(f.FormatProjectDetails(fav), "Found in:") {
+		t.Error("expected no lists for a project found in one")
+	}
+	fav.Kinds = []models.ProjectKind{models.KindFavorite, models.KindGit}
+	if output := f.FormatProjectDetails(fav); !strings.Contains(output, "Found in:    favorites, git") {
+		t.Errorf("expected the lists in details, got:\n%s", output)
+	}
+}
+
+func TestFormatter_Fprint(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	var buf strings.Builder
+	f.FprintSuccess(&buf, "saved")
+	f.FprintWarning(&buf, "careful")
+	f.FprintInfo(&buf, "note")
+	f.FprintError(&buf, "failed")
+
+	want := "✓ saved\n⚠ careful\nℹ note\n✗ failed\n"
+	if buf.String() != want {
+		t.Errorf("wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFormatReadmePreview(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	p := &models.Project{Name: "api", RootPath: "/work/api"}
+
+	if output := f.FormatReadmePreview(p, "", nil, 0); output != "  api: no README" {
+		t.Errorf("unexpected preview without README: %q", output)
+	}
+
+	output := f.FormatReadmePreview(p, "/work/api/README.md", []string{"# api", "", "A long\tdescription line"}, 20)
+	want := "  api: README.md\n  │ # api\n  │\n  │ A long    d..."
+	if output != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, output)
+	}
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// Icons maps project kinds to the icon shown before project names
+type Icons map[models.ProjectKind]string
+
+// IconSets are the built-in icon sets, selectable by name. The nerdfont
+// set needs a Nerd Font (https://www.nerdfonts.com) in the terminal.
+var IconSets = map[string]Icons{
+	"emoji": {
+		models.KindFavorite:  "⭐",
+		models.KindGit:       "🌿",
+		models.KindSVN:       "🐢",
+		models.KindMercurial: "☿",
+		models.KindVSCode:    "💻",
+		models.KindAny:       "📁",
+	},
+	"nerdfont": {
+		models.KindFavorite:  "\uf005", // nf-fa-star
+		models.KindGit:       "\ue702", // nf-dev-git
+		models.KindSVN:       "\ue0a0", // nf-pl-branch
+		models.KindMercurial: "\uf0c3", // nf-fa-flask
+		models.KindVSCode:    "\ue70c", // nf-dev-visualstudio
+		models.KindAny:       "\uf07b", // nf-fa-folder
+	},
+}
+
+// IconSetNames returns the names of the built-in icon sets, sorted
+func IconSetNames() []string {
+	names := make([]string, 0, len(IconSets))
+	for name := range IconSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveIcons returns a built-in icon set with the non-empty overrides
+// applied, for FormatterOptions
+func ResolveIcons(set string, overrides Icons) (Icons, error) {
+	if set == "" {
+		set = "emoji"
+	}
+	base, ok := IconSets[set]
+	if !ok {
+		return nil, fmt.Errorf("unknown icon set '%s' (available: %s)", set, strings.Join(IconSetNames(), ", "))
+	}
+	icons := make(Icons, len(base))
+	for kind, icon := range base {
+		icons[kind] = icon
+	}
+	for kind, icon := range overrides {
+		if icon != "" {
+			icons[kind] = icon
+		}
+	}
+	return icons, nil
+}
+
+// icon returns the icon for p: its own icon, or the icon of its kind
+func (f *Formatter) icon(p *models.Project) string {
+	if f.icons == nil {
+		return ""
+	}
+	if p.Icon != "" {
+		return p.Icon
+	}
+	return f.icons[p.Kind]
+}
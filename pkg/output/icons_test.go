@@ -0,0 +1,40 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestResolveIcons(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "api", RootPath: "/work/api", Enabled: true, Kind: models.KindFavorite},
+		{Name: "web", RootPath: "/work/web", Enabled: true, Kind: models.KindGit},
+		{Name: "rocket", RootPath: "/work/rocket", Enabled: true, Kind: models.KindGit, Icon: "🚀"},
+	}
+
+	if out, _ := NewFormatter(false, FormatterOptions{}).FormatProjectList(projects, ListOptions{}); strings.Contains(out, "⭐") {
+		t.Errorf("expected no icons by default, got:\n%s", out)
+	}
+
+	icons, err := ResolveIcons("emoji", Icons{models.KindGit: "G"})
+	if err != nil {
+		t.Fatalf("ResolveIcons failed: %v", err)
+	}
+	out, _ := NewFormatter(false, FormatterOptions{Icons: icons}).FormatProjectList(projects, ListOptions{})
+	for _, want := range []string{"⭐ api", "G web", "🚀 rocket"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in list, got:\n%s", want, out)
+		}
+	}
+
+	// Overrides do not change the built-in set
+	if IconSets["emoji"][models.KindGit] == "G" {
+		t.Error("expected ResolveIcons not to modify the built-in set")
+	}
+
+	if _, err := ResolveIcons("bogus", nil); err == nil {
+		t.Error("expected error for an unknown icon set")
+	}
+}
@@ -0,0 +1,209 @@
+package output
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+// TableOptions configures FormatProjectTable
+type TableOptions struct {
+	Width      int                  // Maximum line width; 0 disables truncation
+	Borders    bool                 // Draw unicode box borders
+	LastOpened map[string]time.Time // Last open time by project root path
+}
+
+// minTableColumn is the narrowest a column is truncated to
+const minTableColumn = 8
+
+// tableColumn is a column of the project table
+type tableColumn struct {
+	header     string
+	cells      []string
+	width      int
+	shrinkable bool         // Whether the column may be truncated
+	keepTail   bool         // Truncate from the left, keeping the end
+	color      *color.Color // Cell color, if any
+}
+
+// FormatProjectTable formats projects as a table with the columns NAME,
+// KIND, TAGS, PATH and LAST OPENED, and BRANCH after KIND when a project
+// has a recorded default branch. Columns are as wide as their widest
+// cell; when the table is wider than opts.Width, the path, tags and name
+// columns are truncated, widest first.
+func (f *Formatter) FormatProjectTable(projects []*models.Project, opts TableOptions) string {
+	if len(projects) == 0 {
+		return f.FormatInfo("No projects found.")
+	}
+
+	showBranch := false
+	for _, p := range projects {
+		showBranch = showBranch || p.DefaultBranch != ""
+	}
+
+	columns := []*tableColumn{
+		{header: "NAME", shrinkable: true, color: f.nameColor},
+		{header: "KIND", color: f.kindColor},
+	}
+	if showBranch {
+		columns = append(columns, &tableColumn{header: "BRANCH"})
+	}
+	columns = append(columns,
+		&tableColumn{header: "TAGS", shrinkable: true, color: f.tagColor},
+		&tableColumn{header: "PATH", shrinkable: true, keepTail: true, color: f.pathColor},
+		&tableColumn{header: "LAST OPENED"},
+	)
+	for _, p := range projects {
+		name := p.Name
+		if !p.Enabled {
+			name += " (disabled)"
+		}
+		if p.Offline {
+			name += " (offline)"
+		}
+		kind := string(p.Kind)
+		if p.FolderKind != "" && p.FolderKind != p.Kind {
+			kind += " (" + string(p.FolderKind) + ")"
+		}
+		lastOpened := "-"
+		if t, ok := opts.LastOpened[p.RootPath]; ok {
+			lastOpened = t.Local().Format("2006-01-02 15:04")
+		}
+		row := []string{name, kind}
+		if showBranch {
+			branch := "-"
+			if p.DefaultBranch != "" {
+				branch = p.DefaultBranch
+			}
+			row = append(row, branch)
+		}
+		row = append(row, strings.Join(p.Tags, ", "), paths.Collapse(p.RootPath), lastOpened)
+		for i, cell := range row {
+			columns[i].cells = append(columns[i].cells, cell)
+		}
+	}
+
+	for _, c := range columns {
+		c.width = utf8.RuneCountInString(c.header)
+		for _, cell := range c.cells {
+			c.width = max(c.width, utf8.RuneCountInString(cell))
+		}
+	}
+	if opts.Width > 0 {
+		fitColumns(columns, opts.Width-tableOverhead(len(columns), opts.Borders))
+	}
+
+	var sb strings.Builder
+	line := func(left, fill, sep, right string) {
+		sb.WriteString(left)
+		for i, c := range columns {
+			if i > 0 {
+				sb.WriteString(sep)
+			}
+			sb.WriteString(strings.Repeat(fill, c.width+2))
+		}
+		sb.WriteString(right + "\n")
+	}
+	row := func(cell func(c *tableColumn) string) {
+		parts := make([]string, len(columns))
+		for i, c := range columns {
+			parts[i] = cell(c)
+		}
+		if opts.Borders {
+			sb.WriteString("│ " + strings.Join(parts, " │ ") + " │\n")
+		} else {
+			sb.WriteString(strings.TrimRight(strings.Join(parts, "  "), " ") + "\n")
+		}
+	}
+
+	if opts.Borders {
+		line("┌", "─", "┬", "┐")
+	}
+	row(func(c *tableColumn) string {
+		return f.colorize(f.infoColor, pad(c.header, c.width))
+	})
+	if opts.Borders {
+		line("├", "─", "┼", "┤")
+	}
+	for i, p := range projects {
+		row(func(c *tableColumn) string {
+			cell := truncateCell(c.cells[i], c.width, c.keepTail)
+			if c == columns[0] {
+				return f.colorize(f.projectNameColor(p), pad(cell, c.width))
+			}
+			return f.colorize(c.color, pad(cell, c.width))
+		})
+	}
+	if opts.Borders {
+		line("└", "─", "┴", "┘")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// tableOverhead returns the width taken by column separators and borders
+func tableOverhead(columns int, borders bool) int {
+	if borders {
+		return 3*columns + 1
+	}
+	return 2 * (columns - 1)
+}
+
+// fitColumns narrows the widest shrinkable columns until the total width
+// fits in available or no column can shrink further
+func fitColumns(columns []*tableColumn, available int) {
+	total := 0
+	for _, c := range columns {
+		total += c.width
+	}
+	for total > available {
+		var widest *tableColumn
+		for _, c := range columns {
+			floor := max(minTableColumn, utf8.RuneCountInString(c.header))
+			if c.shrinkable && c.width > floor && (widest == nil || c.width > widest.width) {
+				widest = c
+			}
+		}
+		if widest == nil {
+			return
+		}
+		widest.width--
+		total--
+	}
+}
+
+// truncateCell shortens s to width runes, marking the cut with "..."
+func truncateCell(s string, width int, keepTail bool) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	if keepTail {
+		return "..." + string(runes[len(runes)-(width-3):])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// pad pads s with spaces to width runes
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// colorize applies c to s when colors are enabled
+func (f *Formatter) colorize(c *color.Color, s string) string {
+	if f.colored && c != nil {
+		return c.Sprint(s)
+	}
+	return s
+}
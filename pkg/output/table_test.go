@@ -0,0 +1,113 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func tableProjects() []*models.Project {
+	return []*models.Project{
+		{Name: "api", RootPath: "/work/services/api", Tags: []string{"Work", "Go"}, Enabled: true, Kind: models.KindFavorite, FolderKind: models.KindGit},
+		{Name: "old-website", RootPath: "/work/sites/old-website", Enabled: false, Kind: models.KindGit},
+	}
+}
+
+func TestFormatProjectTable(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	opened := time.Date(2026, 3, 4, 5, 6, 0, 0, time.Local)
+	out := f.FormatProjectTable(tableProjects(), TableOptions{
+		LastOpened: map[string]time.Time{"/work/services/api": opened},
+	})
+
+	lines := strings.Split(out, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got:\n%s", out)
+	}
+	for _, col := range []string{"NAME", "KIND", "TAGS", "PATH", "LAST OPENED"} {
+		if !strings.Contains(lines[0], col) {
+			t.Errorf("expected column %s in header %q", col, lines[0])
+		}
+	}
+
+	// Columns are aligned: every row has its path where the header has PATH
+	pathCol := strings.Index(lines[0], "PATH")
+	if !strings.HasPrefix(lines[1][pathCol:], "/work/services/api") || !strings.HasPrefix(lines[2][pathCol:], "/work/sites/old-website") {
+		t.Errorf("expected aligned path column, got:\n%s", out)
+	}
+	for _, want := range []string{"favorites (git)", "Work, Go", "2026-03-04 05:06", "old-website (disabled)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in table, got:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(lines[2], "-") {
+		t.Errorf("expected '-' for a project never opened, got %q", lines[2])
+	}
+}
+
+func TestFormatProjectTable_Branch(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	if out := f.FormatProjectTable(tableProjects(), TableOptions{}); strings.Contains(out, "BRANCH") {
+		t.Errorf("expected no BRANCH column without recorded branches, got:\n%s", out)
+	}
+
+	projects := tableProjects()
+	projects[0].DefaultBranch = "main"
+	lines := strings.Split(f.FormatProjectTable(projects, TableOptions{}), "\n")
+	branchCol := strings.Index(lines[0], "BRANCH")
+	if branchCol < 0 || !strings.HasPrefix(lines[1][branchCol:], "main") || !strings.HasPrefix(lines[2][branchCol:], "-") {
+		t.Errorf("expected a BRANCH column with main and -, got:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestFormatProjectTable_TruncatesToWidth(t *testing.T) {
+	f := NewFormatter(false, FormatterOptions{})
+	for _, borders := range []bool{false, true} {
+		out := f.FormatProjectTable(tableProjects(), TableOptions{Width: 70, Borders: borders})
+		for _, line := range strings.Split(out, "\n") {
+			if n := utf8.RuneCountInString(line); n > 70 {
+				t.Errorf("borders=%t: line is %d wide, want at most 70: %q", borders, n, line)
+			}
+		}
+		// Paths keep their end when truncated
+		if !strings.Contains(out, "...") || !strings.Contains(out, "site ") {
+			t.Errorf("borders=%t: expected truncated paths, got:\n%s", borders, out)
+		}
+	}
+}
+
+func TestFormatProjectTable_Borders(t *testing.T) {
+	out := NewFormatter(false, FormatterOptions{}).FormatProjectTable(tableProjects(), TableOptions{Borders: true})
+	lines := strings.Split(out, "\n")
+	if !strings.HasPrefix(lines[0], "┌") || !strings.HasPrefix(lines[2], "├") || !strings.HasPrefix(lines[len(lines)-1], "└") {
+		t.Errorf("expected box borders, got:\n%s", out)
+	}
+	width := utf8.RuneCountInString(lines[0])
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) != width {
+			t.Errorf("expected every line to be %d wide, got %q", width, line)
+		}
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	tests := []struct {
+		s        string
+		width    int
+		keepTail bool
+		want     string
+	}{
+		{"short", 10, false, "short"},
+		{"a-long-name", 8, false, "a-lon..."},
+		{"/a/long/path", 8, true, ".../path"},
+		{"héllo wörld", 6, false, "hél..."},
+	}
+	for _, tt := range tests {
+		if got := truncateCell(tt.s, tt.width, tt.keepTail); got != tt.want {
+			t.Errorf("truncateCell(%q, %d, %t) = %q, want %q", tt.s, tt.width, tt.keepTail, got, tt.want)
+		}
+	}
+}
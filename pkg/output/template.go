@@ -0,0 +1,44 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// templateEscapes turns the escapes users type in shell-quoted templates
+// into the characters they stand for
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`)
+
+// templateFuncs are the helpers available to list templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseListTemplate parses a Go text/template that is executed once per
+// project, with the project's fields (Name, RootPath, Tags, Enabled,
+// Description, Notes, Language, Kind) as data. The escapes \t and \n are
+// expanded, and join, upper and lower are available as functions.
+func ParseListTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("list").Funcs(templateFuncs).Option("missingkey=error").Parse(templateEscapes.Replace(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// FormatTemplate renders each project with tmpl, one per line
+func FormatTemplate(tmpl *template.Template, projects []*models.Project) (string, error) {
+	var sb strings.Builder
+	for _, p := range projects {
+		if err := tmpl.Execute(&sb, p); err != nil {
+			return "", fmt.Errorf("failed to render template for %s: %w", p.Name, err)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "api", RootPath: "/code/api", Tags: []string{"Work", "Go"}, Kind: models.KindGit},
+		{Name: "notes", RootPath: "/home/notes", Kind: models.KindFavorite},
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{.Name}}\t{{.RootPath}}`, "api\t/code/api\nnotes\t/home/notes\n"},
+		{`{{.Name}} [{{join .Tags ","}}]`, "api [Work,Go]\nnotes []\n"},
+		{`{{upper .Name}} ({{.Kind}})`, "API (git)\nNOTES (favorites)\n"},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseListTemplate(tt.text)
+		if err != nil {
+			t.Fatalf("ParseListTemplate(%q) error = %v", tt.text, err)
+		}
+		got, err := FormatTemplate(tmpl, projects)
+		if err != nil {
+			t.Fatalf("FormatTemplate(%q) error = %v", tt.text, err)
+		}
+		if got != tt.want {
+			t.Errorf("FormatTemplate(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTemplate_Errors(t *testing.T) {
+	if _, err := ParseListTemplate(`{{.Name`); err == nil {
+		t.Error("expected parse error for unclosed action")
+	}
+
+	tmpl, err := ParseListTemplate(`{{.Missing}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FormatTemplate(tmpl, []*models.Project{{Name: "api"}}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
@@ -0,0 +1,244 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// Theme maps each output element to a color spec.
+// A spec is a space-separated list of a color name ("red", "hi-cyan"),
+// a hex color ("#268bd2") and attributes ("bold", "underline").
+// An empty spec prints the element uncolored.
+type Theme struct {
+	Name    string
+	Path    string
+	Tag     string
+	Kind    string
+	Success string
+	Error   string
+	Warning string
+	Info    string
+
+	// none disables colors entirely, for the "none" theme
+	none bool
+}
+
+// ThemeNone is the built-in theme that disables colors entirely
+const ThemeNone = "none"
+
+// Themes are the built-in themes, selectable by name
+var Themes = map[string]Theme{
+	"dark": {
+		Name:    "white bold",
+		Path:    "cyan",
+		Tag:     "magenta",
+		Kind:    "yellow",
+		Success: "green",
+		Error:   "red",
+		Warning: "yellow",
+		Info:    "blue",
+	},
+	"light": {
+		Name:    "black bold",
+		Path:    "blue",
+		Tag:     "magenta",
+		Kind:    "hi-black bold",
+		Success: "green",
+		Error:   "red",
+		Warning: "#b58900",
+		Info:    "blue",
+	},
+	"solarized": {
+		Name:    "#93a1a1 bold",
+		Path:    "#2aa198",
+		Tag:     "#d33682",
+		Kind:    "#b58900",
+		Success: "#859900",
+		Error:   "#dc322f",
+		Warning: "#cb4b16",
+		Info:    "#268bd2",
+	},
+	ThemeNone: {none: true},
+}
+
+// ThemeNames returns the names of the built-in themes, sorted
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Merge returns the theme with every non-empty field of override applied
+func (t Theme) Merge(override Theme) Theme {
+	pick := func(base, o string) string {
+		if o != "" {
+			return o
+		}
+		return base
+	}
+	return Theme{
+		Name:    pick(t.Name, override.Name),
+		Path:    pick(t.Path, override.Path),
+		Tag:     pick(t.Tag, override.Tag),
+		Kind:    pick(t.Kind, override.Kind),
+		Success: pick(t.Success, override.Success),
+		Error:   pick(t.Error, override.Error),
+		Warning: pick(t.Warning, override.Warning),
+		Info:    pick(t.Info, override.Info),
+		none:    t.none,
+	}
+}
+
+// Validate checks that every color spec in the theme parses
+func (t Theme) Validate() error {
+	for field, spec := range map[string]string{
+		"name": t.Name, "path": t.Path, "tag": t.Tag, "kind": t.Kind,
+		"success": t.Success, "error": t.Error, "warning": t.Warning, "info": t.Info,
+	} {
+		if _, err := ParseColor(spec); err != nil {
+			return fmt.Errorf("theme %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// ResolveTheme returns a built-in theme with overrides applied, for
+// FormatterOptions. The "none" theme disables colors.
+func ResolveTheme(base string, overrides Theme) (Theme, error) {
+	if base == "" {
+		base = "dark"
+	}
+	theme, ok := Themes[base]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme '%s' (available: %s)", base, strings.Join(ThemeNames(), ", "))
+	}
+	theme = theme.Merge(overrides)
+	if err := theme.Validate(); err != nil {
+		return Theme{}, err
+	}
+	return theme, nil
+}
+
+// ParseTagColors parses colors, which maps tags (ignoring case) to color
+// specs, into the tag colors of FormatterOptions, keyed by lowercase tag
+func ParseTagColors(colors map[string]string) (map[string]*color.Color, error) {
+	parsed := make(map[string]*color.Color, len(colors))
+	for tag, spec := range colors {
+		c, err := ParseColor(spec)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", tag, err)
+		}
+		parsed[strings.ToLower(tag)] = c
+	}
+	return parsed, nil
+}
+
+// projectNameColor returns the color of p's name: its own color, the
+// color of its first tag that has one, or the theme's name color
+func (f *Formatter) projectNameColor(p *models.Project) *color.Color {
+	if p.Color != "" {
+		if c, err := ParseColor(p.Color); err == nil {
+			return c
+		}
+	}
+	for _, tag := range p.Tags {
+		if c, ok := f.tagNameColors[strings.ToLower(tag)]; ok {
+			return c
+		}
+	}
+	return f.nameColor
+}
+
+// colorDisabledByEnv reports whether the NO_COLOR convention
+// (https://no-color.org) asks for plain output
+func colorDisabledByEnv() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// colorNames maps color names to their foreground attributes
+var colorNames = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// attributeNames maps style names to their attributes
+var attributeNames = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"faint":     color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+}
+
+// ParseColor parses a color spec into a color. An empty spec returns a
+// color with no attributes.
+func ParseColor(spec string) (*color.Color, error) {
+	c := color.New()
+	for _, token := range strings.Fields(strings.ToLower(spec)) {
+		if attr, ok := attributeNames[token]; ok {
+			c.Add(attr)
+			continue
+		}
+		if attr, ok := colorNames[token]; ok {
+			c.Add(attr)
+			continue
+		}
+		if name, ok := strings.CutPrefix(token, "hi-"); ok {
+			if attr, ok := colorNames[name]; ok {
+				c.Add(attr + (color.FgHiBlack - color.FgBlack))
+				continue
+			}
+		}
+		if strings.HasPrefix(token, "#") {
+			r, g, b, err := parseHex(token)
+			if err != nil {
+				return nil, err
+			}
+			// 24-bit foreground: ESC[38;2;r;g;bm
+			c.Add(38, 2, color.Attribute(r), color.Attribute(g), color.Attribute(b))
+			continue
+		}
+		return nil, fmt.Errorf("unknown color '%s'", token)
+	}
+	return c, nil
+}
+
+// parseHex parses #rgb or #rrggbb
+func parseHex(token string) (int, int, int, error) {
+	hex := strings.TrimPrefix(token, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid hex color '%s'", token)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hex color '%s'", token)
+	}
+	return int(value >> 16 & 0xff), int(value >> 8 & 0xff), int(value & 0xff), nil
+}
+
+// mustParseColor parses a spec already checked by ResolveTheme
+func mustParseColor(spec string) *color.Color {
+	c, err := ParseColor(spec)
+	if err != nil {
+		return color.New()
+	}
+	return c
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestParseColor(t *testing.T) {
+	valid := []string{"", "red", "hi-cyan", "bold white", "#268bd2", "#fff underline"}
+	for _, spec := range valid {
+		if _, err := ParseColor(spec); err != nil {
+			t.Errorf("ParseColor(%q) failed: %v", spec, err)
+		}
+	}
+
+	invalid := []string{"purple", "#12", "#gggggg", "hi-orange"}
+	for _, spec := range invalid {
+		if _, err := ParseColor(spec); err == nil {
+			t.Errorf("ParseColor(%q) expected error", spec)
+		}
+	}
+}
+
+func TestParseColor_Hex(t *testing.T) {
+	c, _ := ParseColor("#268bd2")
+	c.EnableColor()
+	if got := c.Sprint("x"); !strings.Contains(got, "38;2;38;139;210") {
+		t.Errorf("expected 24-bit color sequence, got %q", got)
+	}
+}
+
+func TestTheme_Merge(t *testing.T) {
+	merged := Themes["dark"].Merge(Theme{Path: "#ffffff"})
+	if merged.Path != "#ffffff" {
+		t.Errorf("expected overridden path color, got %q", merged.Path)
+	}
+	if merged.Name != Themes["dark"].Name {
+		t.Errorf("expected base name color, got %q", merged.Name)
+	}
+}
+
+func TestBuiltinThemesAreValid(t *testing.T) {
+	for name, theme := range Themes {
+		if err := theme.Validate(); err != nil {
+			t.Errorf("theme %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	if _, err := ResolveTheme("nope", Theme{}); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+	if _, err := ResolveTheme("dark", Theme{Tag: "purple"}); err == nil {
+		t.Error("expected error for invalid override")
+	}
+
+	theme, err := ResolveTheme("light", Theme{Tag: "red"})
+	if err != nil {
+		t.Fatalf("ResolveTheme failed: %v", err)
+	}
+	if theme.Tag != "red" || theme.Path != Themes["light"].Path {
+		t.Errorf("expected the light theme with a red tag, got %+v", theme)
+	}
+
+	none, err := ResolveTheme(ThemeNone, Theme{})
+	if err != nil {
+		t.Fatalf("ResolveTheme(none) failed: %v", err)
+	}
+	if NewFormatter(true, FormatterOptions{Theme: none}).colored {
+		t.Error("expected 'none' theme to disable colors")
+	}
+	t.Setenv("NO_COLOR", "")
+	if !NewFormatter(true, FormatterOptions{}).colored {
+		t.Error("expected the default theme to keep colors")
+	}
+}
+
+func TestNewFormatter_NoColorEnv(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if NewFormatter(true, FormatterOptions{}).colored {
+		t.Error("expected NO_COLOR to disable colors")
+	}
+}
+
+func TestParseTagColors(t *testing.T) {
+	if _, err := ParseTagColors(map[string]string{"clienta": "purple"}); err == nil {
+		t.Error("expected error for an invalid color")
+	}
+	tagColors, err := ParseTagColors(map[string]string{"ClientA": "red", "clientb": "#268bd2 bold"})
+	if err != nil {
+		t.Fatalf("ParseTagColors failed: %v", err)
+	}
+
+	red, _ := ParseColor("red")
+	blue, _ := ParseColor("#268bd2 bold")
+	green, _ := ParseColor("green")
+	f := NewFormatter(true, FormatterOptions{TagColors: tagColors})
+	tests := []struct {
+		project *models.Project
+		want    *color.Color
+	}{
+		{&models.Project{Name: "a", Tags: []string{"Work", "ClientA"}}, red},
+		{&models.Project{Name: "b", Tags: []string{"CLIENTB", "ClientA"}}, blue},
+		{&models.Project{Name: "c", Tags: []string{"ClientA"}, Color: "green"}, green},
+		{&models.Project{Name: "d", Tags: []string{"Work"}}, f.nameColor},
+	}
+	for _, tt := range tests {
+		if got := f.projectNameColor(tt.project); !got.Equals(tt.want) {
+			t.Errorf("projectNameColor(%s) = %v, want %v", tt.project.Name, got, tt.want)
+		}
+	}
+}
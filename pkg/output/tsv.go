@@ -0,0 +1,92 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// TSVFields are the fields FormatTSV can print, in no particular order
+var TSVFields = []string{"name", "path", "kind", "type", "kinds", "tags", "language", "enabled", "description", "parent", "remote", "branch"}
+
+// DefaultTSVFields are printed when no fields are chosen
+var DefaultTSVFields = []string{"name", "path", "kind", "tags"}
+
+// ParseTSVFields parses a comma-separated list of field names, such as
+// "name,path,tags"; an empty list gives DefaultTSVFields
+func ParseTSVFields(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return DefaultTSVFields, nil
+	}
+	var fields []string
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(TSVFields, field) {
+			return nil, fmt.Errorf("unknown field '%s' (valid: %s)", field, strings.Join(TSVFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// FormatTSV prints one project per record with the fields separated by
+// tabs and no colors, for awk, cut and fzf. Records end with a newline,
+// or a NUL with nul. Tabs and line breaks inside values become spaces so
+// the columns stay aligned; tags are joined with commas.
+func FormatTSV(projects []*models.Project, fields []string, nul bool) string {
+	end := "\n"
+	if nul {
+		end = "\x00"
+	}
+	var sb strings.Builder
+	values := make([]string, len(fields))
+	for _, p := range projects {
+		for i, field := range fields {
+			values[i] = tsvEscaper.Replace(tsvValue(p, field))
+		}
+		sb.WriteString(strings.Join(values, "\t"))
+		sb.WriteString(end)
+	}
+	return sb.String()
+}
+
+// tsvEscaper keeps values on one line and in one column
+var tsvEscaper = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ", "\x00", "")
+
+// tsvValue returns the text of a project field
+func tsvValue(p *models.Project, field string) string {
+	switch field {
+	case "name":
+		return p.Name
+	case "path":
+		return p.RootPath
+	case "kind":
+		return string(p.EffectiveKind())
+	case "type":
+		return string(p.Kind)
+	case "kinds":
+		kinds := make([]string, len(p.Kinds))
+		for i, kind := range p.Kinds {
+			kinds[i] = string(kind)
+		}
+		return strings.Join(kinds, ",")
+	case "tags":
+		return strings.Join(p.Tags, ",")
+	case "language":
+		return p.Language
+	case "enabled":
+		return strconv.FormatBool(p.Enabled)
+	case "description":
+		return p.Description
+	case "parent":
+		return p.Parent
+	case "remote":
+		return p.RemoteURL
+	case "branch":
+		return p.DefaultBranch
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestFormatTSV(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "api", RootPath: "/work/api", Tags: []string{"Work", "Go"}, Kind: models.KindFavorite, FolderKind: models.KindGit, Kinds: []models.ProjectKind{models.KindFavorite, models.KindGit}},
+		{Name: "notes", RootPath: "/home/me/notes", Description: "line one\nline\ttwo", Kind: models.KindAny},
+	}
+
+	out := FormatTSV(projects, DefaultTSVFields, false)
+	want := "api\t/work/api\tgit\tWork,Go\n" +
+		"notes\t/home/me/notes\tany\t\n"
+	if out != want {
+		t.Errorf("FormatTSV() = %q, want %q", out, want)
+	}
+
+	out = FormatTSV(projects, []string{"description", "name"}, true)
+	want = "\tapi\x00line one line two\tnotes\x00"
+	if out != want {
+		t.Errorf("FormatTSV() with print0 = %q, want %q", out, want)
+	}
+
+	out = FormatTSV(projects, []string{"name", "kinds"}, false)
+	want = "api\tfavorites,git\nnotes\t\n"
+	if out != want {
+		t.Errorf("FormatTSV() with kinds = %q, want %q", out, want)
+	}
+}
+
+func TestParseTSVFields(t *testing.T) {
+	fields, err := ParseTSVFields(" Name, path ,tags")
+	if err != nil || len(fields) != 3 || fields[0] != "name" || fields[1] != "path" || fields[2] != "tags" {
+		t.Errorf("ParseTSVFields() = %v, %v", fields, err)
+	}
+	if fields, _ := ParseTSVFields(""); len(fields) != len(DefaultTSVFields) {
+		t.Errorf("expected the default fields, got %v", fields)
+	}
+	if _, err := ParseTSVFields("name,size"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
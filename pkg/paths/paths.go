@@ -4,44 +4,152 @@ package paths
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 )
 
-// Expand expands ~ and $home/$HOME to the actual home directory.
+// Expand expands a leading ~ to the home directory and environment
+// variables anywhere in the path, written $VAR, ${VAR} or %VAR%. $home
+// and $HOME always stand for the home directory, as does %USERPROFILE%
+// where it is not set. Unset $ variables expand to nothing, as with
+// os.ExpandEnv, while an unset %VAR% is kept as written.
 func Expand(path string) string {
 	home, err := os.UserHomeDir()
-	if err != nil {
-		return path
+	lookup := func(name string) (string, bool) {
+		if err == nil && (name == "home" || name == "HOME") {
+			return home, true
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		if err == nil && strings.EqualFold(name, "USERPROFILE") {
+			return home, true
+		}
+		return "", false
 	}
 
-	if strings.HasPrefix(path, "~") {
-		path = strings.Replace(path, "~", home, 1)
+	if err == nil && (path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`)) {
+		path = home + path[1:]
 	}
-	if strings.HasPrefix(path, "$home") {
-		path = strings.Replace(path, "$home", home, 1)
+	path = os.Expand(path, func(name string) string {
+		value, _ := lookup(name)
+		return value
+	})
+	return expandPercent(path, lookup)
+}
+
+// ExpandHome expands a leading ~, $home or $HOME to the home directory,
+// undoing Collapse for stored paths. Unlike Expand it leaves any other $
+// and % alone, since they may be part of folder names.
+func ExpandHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
 	}
-	if strings.HasPrefix(path, "$HOME") {
-		path = strings.Replace(path, "$HOME", home, 1)
+	for _, prefix := range []string{"~", "$home", "$HOME"} {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if ok && (rest == "" || rest[0] == '/' || rest[0] == '\\') {
+			return home + rest
+		}
 	}
-
 	return path
 }
 
+// expandPercent expands Windows-style %VAR% references that lookup
+// knows, leaving the others as written
+func expandPercent(path string, lookup func(name string) (string, bool)) string {
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(path, '%')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(path[start+1:], '%')
+		if end < 0 {
+			break
+		}
+		end += start + 1
+
+		name := path[start+1 : end]
+		if name != "" && !strings.ContainsAny(name, `/\`) {
+			if value, ok := lookup(name); ok {
+				sb.WriteString(path[:start])
+				sb.WriteString(value)
+				path = path[end+1:]
+				continue
+			}
+		}
+		// Not a variable: keep the first % and look again from the second
+		sb.WriteString(path[:end])
+		path = path[end:]
+	}
+	sb.WriteString(path)
+	return sb.String()
+}
+
 // Collapse replaces the home directory with ~.
 func Collapse(path string) string {
 	home, err := os.UserHomeDir()
-	if err != nil {
+	if err != nil || home == "" || len(path) < len(home) {
 		return path
 	}
 
-	if strings.HasPrefix(path, home) {
-		return strings.Replace(path, home, "~", 1)
+	if Within(home, path) {
+		return "~" + path[len(home):]
 	}
 
 	return path
 }
 
-// ExpandAll expands ~ and $home/$HOME in all paths.
+// Key returns path in the form used to compare and index paths: cleaned
+// and, on Windows where paths ignore case, lower-cased.
+func Key(path string) string {
+	path = filepath.Clean(path)
+	if ignoreCase {
+		path = strings.ToLower(path)
+	}
+	return path
+}
+
+// Equal reports whether a and b name the same location, ignoring case on
+// Windows.
+func Equal(a, b string) bool {
+	return Key(a) == Key(b)
+}
+
+// Within reports whether path is dir or inside it. Drive letters and UNC
+// shares (\\server\share) are compared like any other folder.
+func Within(dir, path string) bool {
+	dir, path = Key(dir), Key(path)
+	if path == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, dir)
+}
+
+// Shorten cuts path to at most max characters for display, replacing the
+// start with "..." but keeping its volume, such as C: or \\server\share
+// on Windows.
+func Shorten(path string, max int) string {
+	runes := []rune(path)
+	if len(runes) <= max {
+		return path
+	}
+	volume := []rune(filepath.VolumeName(path))
+	keep := max - len(volume) - 3
+	if keep <= 0 {
+		volume, keep = nil, max-3
+	}
+	if keep <= 0 {
+		return string(runes[len(runes)-max:])
+	}
+	return string(volume) + "..." + string(runes[len(runes)-keep:])
+}
+
+// ExpandAll expands ~ and environment variables in all paths (see Expand).
 func ExpandAll(paths []string) []string {
 	result := make([]string, len(paths))
 	for i, path := range paths {
@@ -50,6 +158,15 @@ func ExpandAll(paths []string) []string {
 	return result
 }
 
+// ExpandHomeAll expands the home directory in all paths (see ExpandHome).
+func ExpandHomeAll(paths []string) []string {
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		result[i] = ExpandHome(path)
+	}
+	return result
+}
+
 // Exists checks if a path exists.
 func Exists(path string) bool {
 	_, err := os.Stat(path)
@@ -64,3 +181,17 @@ func IsDir(path string) bool {
 	}
 	return info.IsDir()
 }
+
+// Canonical returns the expanded, absolute, cleaned form of a path with
+// symlinks resolved. If the path cannot be resolved (e.g. it no longer
+// exists), the cleaned absolute path is returned.
+func Canonical(path string) string {
+	path = Expand(path)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
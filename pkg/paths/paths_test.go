@@ -32,6 +32,72 @@ func TestExpand(t *testing.T) {
 	}
 }
 
+func TestExpand_EnvVars(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	t.Setenv("PROJECTOR_TEST_ROOT", "/srv/code")
+	t.Setenv("PROJECTOR_TEST_TEAM", "acme")
+	// Unset for the test; t.Setenv restores them afterwards
+	for _, name := range []string{"PROJECTOR_TEST_UNSET", "USERPROFILE"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"$PROJECTOR_TEST_ROOT/api", "/srv/code/api"},
+		{"${PROJECTOR_TEST_ROOT}/clients/$PROJECTOR_TEST_TEAM", "/srv/code/clients/acme"},
+		{"~/work/${PROJECTOR_TEST_TEAM}-api", home + "/work/acme-api"},
+		{"/data/$home/x", "/data/" + home + "/x"},
+		{"$PROJECTOR_TEST_UNSET/api", "/api"},
+		{`%USERPROFILE%\projects`, home + `\projects`},
+		{`%PROJECTOR_TEST_ROOT%\%PROJECTOR_TEST_TEAM%`, `/srv/code\acme`},
+		{"%PROJECTOR_TEST_UNSET%/api", "%PROJECTOR_TEST_UNSET%/api"},
+		{"/builds/100%/%PROJECTOR_TEST_TEAM%", "/builds/100%/acme"},
+		{"$PROJECTOR_TEST_ROOT/%PROJECTOR_TEST_TEAM%/$home", "/srv/code/acme/" + home},
+		{"~user/projects", "~user/projects"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := Expand(tt.input); result != tt.expected {
+				t.Errorf("Expand(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	t.Setenv("PROJECTOR_TEST_ROOT", "/srv/code")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"~/projects", home + "/projects"},
+		{"~", home},
+		{"$home/projects", home + "/projects"},
+		{"$HOME/projects", home + "/projects"},
+		{"~user/projects", "~user/projects"},
+		{"$homework/a", "$homework/a"},
+		{"/data/price$list", "/data/price$list"},
+		{"/data/a$-b", "/data/a$-b"},
+		{"/data/$PROJECTOR_TEST_ROOT", "/data/$PROJECTOR_TEST_ROOT"},
+		{"/data/50%HOME%x", "/data/50%HOME%x"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := ExpandHome(tt.input); result != tt.expected {
+				t.Errorf("ExpandHome(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestCollapse(t *testing.T) {
 	home, _ := os.UserHomeDir()
 
@@ -118,3 +184,26 @@ func TestIsDir(t *testing.T) {
 		t.Error("expected IsDir to return false for non-existent path")
 	}
 }
+
+func TestCanonical(t *testing.T) {
+	tmpDir, _ := filepath.EvalSymlinks(t.TempDir())
+	target := filepath.Join(tmpDir, "target")
+	os.Mkdir(target, 0755)
+
+	link := filepath.Join(tmpDir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported")
+	}
+
+	if got := Canonical(link); got != target {
+		t.Errorf("Canonical(symlink) = %q, want %q", got, target)
+	}
+	if got := Canonical(target + "/./sub/.."); got != target {
+		t.Errorf("Canonical(unclean) = %q, want %q", got, target)
+	}
+
+	missing := filepath.Join(tmpDir, "missing", "..", "gone")
+	if got := Canonical(missing); got != filepath.Join(tmpDir, "gone") {
+		t.Errorf("Canonical(missing) = %q", got)
+	}
+}
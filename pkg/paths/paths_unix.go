@@ -0,0 +1,7 @@
+//go:build !windows
+
+package paths
+
+// ignoreCase reports whether paths differing only in case name the same
+// file
+const ignoreCase = false
@@ -0,0 +1,90 @@
+//go:build !windows
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		dir, path string
+		want      bool
+	}{
+		{"/work/mono", "/work/mono", true},
+		{"/work/mono", "/work/mono/services/api", true},
+		{"/work/mono/", "/work/mono/api", true},
+		{"/work/mono", "/work/monorepo", false},
+		{"/work/mono", "/Work/mono/api", false},
+		{"/", "/work", true},
+	}
+	for _, tt := range tests {
+		if got := Within(tt.dir, tt.path); got != tt.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", tt.dir, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCollapse_SiblingOfHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got := Collapse(home + "-old/projects"); got != home+"-old/projects" {
+		t.Errorf("Collapse() = %q, want the path unchanged", got)
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		path string
+		max  int
+		want string
+	}{
+		{"/work/api", 20, "/work/api"},
+		{"/home/me/projects/clients/acme/api", 20, ".../clients/acme/api"},
+		{"/home/me/projekte/bücher", 10, ".../bücher"},
+	}
+	for _, tt := range tests {
+		if got := Shorten(tt.path, tt.max); got != tt.want {
+			t.Errorf("Shorten(%q, %d) = %q, want %q", tt.path, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestVolumeMounted(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "usb")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !VolumeMounted("/") {
+		t.Error("expected / to be mounted")
+	}
+	if VolumeMounted(dir) {
+		t.Errorf("expected the plain folder %s not to be a mount point", dir)
+	}
+	if VolumeMounted(filepath.Join(dir, "missing")) {
+		t.Error("expected a missing folder not to be mounted")
+	}
+}
+
+func TestVolumeRoot(t *testing.T) {
+	if home, err := os.UserHomeDir(); err == nil {
+		if got := VolumeRoot(home); got != "" {
+			t.Errorf("VolumeRoot(home) = %q, want \"\"", got)
+		}
+	}
+	if VolumeRoot("/") != "" {
+		t.Error("expected / to have no volume root")
+	}
+
+	if runtime.GOOS != "linux" || !VolumeMounted("/proc") {
+		t.Skip("uses /proc as a second file system")
+	}
+	if got := VolumeRoot("/proc/1"); got != "/proc" {
+		t.Errorf("VolumeRoot(/proc/1) = %q, want /proc", got)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package paths
+
+// ignoreCase reports whether paths differing only in case name the same
+// file. NTFS folders are case-insensitive unless set otherwise.
+const ignoreCase = true
@@ -0,0 +1,73 @@
+//go:build windows
+
+package paths
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		dir, path string
+		want      bool
+	}{
+		{`C:\Work\Mono`, `c:\work\mono\services\api`, true},
+		{`C:\Work\Mono`, `C:/Work/Mono/api`, true},
+		{`C:\`, `C:\Work`, true},
+		{`C:\Work\Mono`, `C:\Work\Monorepo`, false},
+		{`C:\Work`, `D:\Work\api`, false},
+		{`\\server\share`, `\\SERVER\Share\projects\api`, true},
+		{`\\server\share`, `\\server\shared\api`, false},
+	}
+	for _, tt := range tests {
+		if got := Within(tt.dir, tt.path); got != tt.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", tt.dir, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	if !Equal(`C:\Users\Me\Projects`, `c:/users/me/projects/`) {
+		t.Error("expected paths differing in case and separators to be equal")
+	}
+	if Equal(`\\server\share\api`, `\\server\other\api`) {
+		t.Error("expected different UNC shares to differ")
+	}
+}
+
+func TestCollapse_Case(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	upper := []byte(home)
+	for i, c := range upper {
+		if 'a' <= c && c <= 'z' {
+			upper[i] = c - 'a' + 'A'
+		}
+	}
+	if got := Collapse(string(upper) + `\projects`); got != `~\projects` {
+		t.Errorf("Collapse() = %q, want ~\\projects", got)
+	}
+	if got := Collapse(home + `-old\projects`); got != home+`-old\projects` {
+		t.Errorf("Collapse() = %q, want the path unchanged", got)
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		path string
+		max  int
+		want string
+	}{
+		{`C:\Work\api`, 20, `C:\Work\api`},
+		{`C:\Users\me\projects\clients\acme\api`, 22, `C:...\clients\acme\api`},
+		{`\\server\share\projects\clients\acme\api`, 26, `\\server\share...\acme\api`},
+	}
+	for _, tt := range tests {
+		if got := Shorten(tt.path, tt.max); got != tt.want {
+			t.Errorf("Shorten(%q, %d) = %q, want %q", tt.path, tt.max, got, tt.want)
+		}
+	}
+}
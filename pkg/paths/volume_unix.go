@@ -0,0 +1,69 @@
+//go:build !windows
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// device returns the device number of the file system path is on
+func device(path string) (uint64, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// VolumeRoot returns the mount point of the volume path is on, such as
+// /media/me/usb or /Volumes/Backup, or "" when path is on the file system
+// of / or of the home folder, which are always mounted
+func VolumeRoot(path string) string {
+	dev, ok := device(path)
+	if !ok {
+		return ""
+	}
+	system := []string{"/"}
+	if home, err := os.UserHomeDir(); err == nil {
+		system = append(system, home)
+	}
+	for _, dir := range system {
+		if d, ok := device(dir); ok && d == dev {
+			return ""
+		}
+	}
+
+	root := filepath.Clean(path)
+	for {
+		parent := filepath.Dir(root)
+		if parent == root {
+			return root
+		}
+		if d, ok := device(parent); !ok || d != dev {
+			return root
+		}
+		root = parent
+	}
+}
+
+// VolumeMounted reports whether a volume is mounted on root: the folder
+// exists and is on another file system than the folder above it. Mount
+// points often stay behind as empty folders once a drive is ejected.
+func VolumeMounted(root string) bool {
+	dev, ok := device(root)
+	if !ok {
+		return false
+	}
+	parent := filepath.Dir(root)
+	if parent == root {
+		return true
+	}
+	d, ok := device(parent)
+	return !ok || d != dev
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VolumeRoot returns the root of the drive or network share path is on,
+// such as E:\ or \\server\share\, or "" when path is on the system drive
+// or the drive of the home folder, which are always there. WSL shares
+// are left out as well: opening them starts the distro.
+func VolumeRoot(path string) string {
+	if _, _, ok := ParseWSL(path); ok {
+		return ""
+	}
+	volume := filepath.VolumeName(path)
+	if volume == "" {
+		return ""
+	}
+	system := []string{os.Getenv("SystemDrive")}
+	if home, err := os.UserHomeDir(); err == nil {
+		system = append(system, filepath.VolumeName(home))
+	}
+	for _, v := range system {
+		if strings.EqualFold(volume, v) {
+			return ""
+		}
+	}
+	return volume + `\`
+}
+
+// VolumeMounted reports whether the drive or share at root is available
+func VolumeMounted(root string) bool {
+	_, err := os.Stat(root)
+	return err == nil
+}
@@ -0,0 +1,48 @@
+package paths
+
+import (
+	"strings"
+)
+
+// wslHosts are the UNC host names under which Windows exposes WSL distros
+var wslHosts = []string{"wsl$", "wsl.localhost"}
+
+// ParseWSL splits a Windows UNC path into a WSL distro, such as
+// \\wsl$\Ubuntu\home\me\app or \\wsl.localhost\Ubuntu\home\me\app, into
+// the distro name and the Linux path inside it ("/home/me/app").
+// Forward slashes are accepted as well. ok is false for other paths.
+func ParseWSL(path string) (distro, linuxPath string, ok bool) {
+	p := strings.ReplaceAll(path, `\`, "/")
+	if !strings.HasPrefix(p, "//") {
+		return "", "", false
+	}
+	host, rest, _ := strings.Cut(p[2:], "/")
+
+	isWSL := false
+	for _, h := range wslHosts {
+		if strings.EqualFold(host, h) {
+			isWSL = true
+			break
+		}
+	}
+	if !isWSL {
+		return "", "", false
+	}
+
+	distro, rest, _ = strings.Cut(rest, "/")
+	if distro == "" {
+		return "", "", false
+	}
+	return distro, "/" + strings.TrimRight(rest, "/"), true
+}
+
+// WSLPath returns the Windows UNC path of linuxPath inside a WSL distro,
+// e.g. \\wsl$\Ubuntu\home\me\app for "/home/me/app"
+func WSLPath(distro, linuxPath string) string {
+	rest := strings.Trim(strings.ReplaceAll(linuxPath, `\`, "/"), "/")
+	unc := `\\wsl$\` + distro
+	if rest != "" {
+		unc += `\` + strings.ReplaceAll(rest, "/", `\`)
+	}
+	return unc
+}
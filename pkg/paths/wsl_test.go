@@ -0,0 +1,44 @@
+package paths
+
+import "testing"
+
+func TestParseWSL(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantDistro string
+		wantLinux  string
+		wantOK     bool
+	}{
+		{`\\wsl$\Ubuntu\home\me\app`, "Ubuntu", "/home/me/app", true},
+		{`\\wsl.localhost\Debian\srv\`, "Debian", "/srv", true},
+		{`//WSL$/Ubuntu`, "Ubuntu", "/", true},
+		{`\\server\share\app`, "", "", false},
+		{`\\wsl$\`, "", "", false},
+		{`/home/me/app`, "", "", false},
+		{`C:\Users\me`, "", "", false},
+	}
+
+	for _, tt := range tests {
+		distro, linux, ok := ParseWSL(tt.path)
+		if ok != tt.wantOK || distro != tt.wantDistro || linux != tt.wantLinux {
+			t.Errorf("ParseWSL(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.path, distro, linux, ok, tt.wantDistro, tt.wantLinux, tt.wantOK)
+		}
+	}
+}
+
+func TestWSLPath(t *testing.T) {
+	tests := []struct {
+		distro, linux, want string
+	}{
+		{"Ubuntu", "/home/me/app", `\\wsl$\Ubuntu\home\me\app`},
+		{"Ubuntu", "/", `\\wsl$\Ubuntu`},
+		{"Debian", "/srv/", `\\wsl$\Debian\srv`},
+	}
+
+	for _, tt := range tests {
+		if got := WSLPath(tt.distro, tt.linux); got != tt.want {
+			t.Errorf("WSLPath(%q, %q) = %q, want %q", tt.distro, tt.linux, got, tt.want)
+		}
+	}
+}
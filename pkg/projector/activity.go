@@ -0,0 +1,92 @@
+package projector
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// ActivityTTL is how long a cached activity time is used before the
+// project folder is checked again
+const ActivityTTL = time.Hour
+
+// activityRetention is how long activity is kept for folders that are
+// no longer checked, such as removed projects
+const activityRetention = 30 * 24 * time.Hour
+
+// activityWorkers bounds how many project folders are checked at once
+const activityWorkers = 8
+
+// ActivityTimes returns when each project was last worked on, keyed by
+// root path (see scanner.LastActivity). Times checked within ActivityTTL
+// come from the activity cache; the others are checked in parallel and
+// saved back to the cache.
+func ActivityTimes(store *storage.Storage, projects []*models.Project) map[string]time.Time {
+	cached, err := store.LoadActivity()
+	if err != nil {
+		cached = make(map[string]storage.ActivityEntry)
+	}
+
+	now := time.Now()
+	times := make(map[string]time.Time, len(projects))
+	var stale []string
+	for _, p := range projects {
+		if entry, ok := cached[p.RootPath]; ok && now.Sub(entry.CheckedAt) < ActivityTTL {
+			times[p.RootPath] = entry.Time
+		} else if _, queued := times[p.RootPath]; !queued {
+			times[p.RootPath] = time.Time{}
+			stale = append(stale, p.RootPath)
+		}
+	}
+	if len(stale) == 0 {
+		return times
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	paths := make(chan string)
+	for range min(activityWorkers, len(stale)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				t := scanner.LastActivity(path)
+				mu.Lock()
+				times[path] = t
+				cached[path] = storage.ActivityEntry{Time: t, CheckedAt: now}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range stale {
+		paths <- path
+	}
+	close(paths)
+	wg.Wait()
+
+	for path, entry := range cached {
+		if now.Sub(entry.CheckedAt) > activityRetention {
+			delete(cached, path)
+		}
+	}
+	// The cache only saves time, so failing to write it is not an error
+	_ = store.SaveActivity(cached)
+	return times
+}
+
+// SortByActivity sorts projects by their activity time, most recent first.
+// Projects without a known time go last, sorted by name.
+func SortByActivity(projects []*models.Project, activity map[string]time.Time) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		ti, tj := activity[projects[i].RootPath], activity[projects[j].RootPath]
+		if ti.Equal(tj) {
+			return strings.ToLower(projects[i].Name) < strings.ToLower(projects[j].Name)
+		}
+		return ti.After(tj)
+	})
+}
@@ -0,0 +1,66 @@
+package projector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+func TestActivityTimes(t *testing.T) {
+	m := newTestManager(t)
+	store := m.Storage()
+
+	dir := t.TempDir()
+	mtime := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("x"), 0644)
+	os.Chtimes(filepath.Join(dir, "main.go"), mtime, mtime)
+
+	cachedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := store.SaveActivity(map[string]storage.ActivityEntry{
+		"/fresh": {Time: cachedAt, CheckedAt: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := []*models.Project{{Name: "dir", RootPath: dir}, {Name: "fresh", RootPath: "/fresh"}}
+	times := ActivityTimes(store, projects)
+	if !times[dir].Equal(mtime) {
+		t.Errorf("expected %s to be checked, got %v", dir, times[dir])
+	}
+	if !times["/fresh"].Equal(cachedAt) {
+		t.Errorf("expected the fresh cached time to be used, got %v", times["/fresh"])
+	}
+
+	cached, err := store.LoadActivity()
+	if err != nil || !cached[dir].Time.Equal(mtime) {
+		t.Errorf("expected the checked time to be cached, got %v, %v", cached, err)
+	}
+}
+
+func TestSortByActivity(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "unknown-b", RootPath: "/b"},
+		{Name: "old", RootPath: "/old"},
+		{Name: "Unknown-a", RootPath: "/a"},
+		{Name: "new", RootPath: "/new"},
+	}
+	SortByActivity(projects, map[string]time.Time{
+		"/old": time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		"/new": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+	want := []string{"new", "old", "Unknown-a", "unknown-b"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("SortByActivity() order = %v, want %v", names, want)
+		}
+	}
+}
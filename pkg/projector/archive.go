@@ -0,0 +1,340 @@
+package projector
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+// ArchiveMode chooses what Manager.Archive does with the project folder
+type ArchiveMode int
+
+const (
+	// ArchiveInPlace leaves the folder where it is
+	ArchiveInPlace ArchiveMode = iota
+	// ArchiveMove moves the folder into the archive directory
+	ArchiveMove
+	// ArchiveCompress packs the folder into a .tar.gz file in the archive
+	// directory and removes it
+	ArchiveCompress
+)
+
+// archiveExt is the extension of compressed project folders
+const archiveExt = ".tar.gz"
+
+// Archive archives the project named name (matched as by Find, disabled
+// projects included): it is hidden from lists and pickers, including when
+// rescans find it again, until it is unarchived. Favorites are removed
+// from projects.json and restored by Unarchive.
+func (m *Manager) Archive(name string, mode ArchiveMode) (*models.ArchivedProject, error) {
+	archived, err := m.store.LoadArchived()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archived projects: %w", err)
+	}
+	all, err := m.store.LoadAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	project, err := m.findByName(all, name)
+	if err != nil {
+		return nil, err
+	}
+	if models.FindArchived(archived, project.Name) != nil {
+		return nil, fmt.Errorf("an archived project named '%s' already exists", project.Name)
+	}
+
+	entry := &models.ArchivedProject{Project: project, Kind: project.Kind, ArchivedAt: time.Now()}
+	if mode != ArchiveInPlace {
+		if entry.Location, err = archiveFolder(project.RootPath, m.cfg.GetArchiveDirectory(), mode); err != nil {
+			return nil, err
+		}
+	}
+
+	if project.Kind == models.KindFavorite {
+		err := m.store.Update(func(projects *models.ProjectList) error {
+			projects.Remove(project.Name)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	archived = append(archived, entry)
+	if err := m.store.SaveArchived(archived); err != nil {
+		return nil, fmt.Errorf("failed to save archived projects: %w", err)
+	}
+	return entry, nil
+}
+
+// Unarchive restores the archived project named name (case-insensitive):
+// its folder is moved back or extracted to its original path, and the
+// project returns to favorites or the scan cache it came from
+func (m *Manager) Unarchive(name string) (*models.Project, error) {
+	archived, err := m.store.LoadArchived()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archived projects: %w", err)
+	}
+	entry := models.FindArchived(archived, name)
+	if entry == nil {
+		return nil, fmt.Errorf("archived project '%s' not found", name)
+	}
+	project := entry.Project
+
+	// Check for clashes before touching the folder
+	if entry.Kind == models.KindFavorite {
+		favorites, err := m.store.LoadProjects()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load projects: %w", err)
+		}
+		for _, p := range favorites.Projects {
+			if paths.Equal(p.RootPath, project.RootPath) || strings.EqualFold(p.Name, project.Name) {
+				return nil, fmt.Errorf("cannot unarchive '%s': favorite '%s' uses the same name or path", project.Name, p.Name)
+			}
+		}
+	}
+	if entry.Location != "" {
+		if err := restoreFolder(entry.Location, project.RootPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if entry.Kind == models.KindFavorite {
+		err := m.store.Update(func(favorites *models.ProjectList) error {
+			favorites.Add(project)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if cache, err := m.store.LoadCache(); err == nil {
+		// Put cached projects back now rather than at the next scan
+		scannerType := scanner.ScannerType(entry.Kind)
+		putCacheBucket(cache, scannerType, append(CacheBucket(cache, scannerType), project))
+		if err := m.store.SaveCache(cache); err != nil {
+			return nil, fmt.Errorf("failed to save cache: %w", err)
+		}
+	}
+
+	kept := archived[:0]
+	for _, a := range archived {
+		if a != entry {
+			kept = append(kept, a)
+		}
+	}
+	if err := m.store.SaveArchived(kept); err != nil {
+		return nil, fmt.Errorf("failed to save archived projects: %w", err)
+	}
+	return project, nil
+}
+
+// archiveFolder moves or compresses root into dir and returns where it
+// ended up
+func archiveFolder(root, dir string, mode ArchiveMode) (string, error) {
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("project folder does not exist: %s", root)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	if mode == ArchiveCompress {
+		dest := uniquePath(filepath.Join(dir, filepath.Base(root)), archiveExt)
+		if err := compressFolder(root, dest); err != nil {
+			return "", fmt.Errorf("failed to compress %s: %w", root, err)
+		}
+		if err := os.RemoveAll(root); err != nil {
+			return "", fmt.Errorf("compressed to %s but failed to remove the folder: %w", dest, err)
+		}
+		return dest, nil
+	}
+
+	dest := uniquePath(filepath.Join(dir, filepath.Base(root)), "")
+	if err := os.Rename(root, dest); err != nil {
+		return "", fmt.Errorf("failed to move %s to %s (use --compress across file systems): %w", root, dest, err)
+	}
+	return dest, nil
+}
+
+// restoreFolder moves or extracts an archived folder back to root, which
+// must not exist
+func restoreFolder(location, root string) error {
+	if _, err := os.Lstat(root); err == nil {
+		return fmt.Errorf("cannot restore %s: the path already exists", root)
+	}
+	if err := os.MkdirAll(filepath.Dir(root), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(root), err)
+	}
+
+	if !strings.HasSuffix(location, archiveExt) {
+		if err := os.Rename(location, root); err != nil {
+			return fmt.Errorf("failed to move %s back to %s: %w", location, root, err)
+		}
+		return nil
+	}
+
+	if err := extractArchive(location, root); err != nil {
+		os.RemoveAll(root)
+		return fmt.Errorf("failed to extract %s: %w", location, err)
+	}
+	if err := os.Remove(location); err != nil {
+		return fmt.Errorf("restored %s but failed to remove %s: %w", root, location, err)
+	}
+	return nil
+}
+
+// uniquePath returns base+ext, or base-2+ext, base-3+ext, ... when that
+// is taken
+func uniquePath(base, ext string) string {
+	path := base + ext
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+// compressFolder writes the contents of root to a new gzipped tar file at
+// dest. Symlinks are stored as links; sockets and devices are skipped.
+func compressFolder(root, dest string) (err error) {
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() && info.Mode()&fs.ModeSymlink == 0 {
+			return nil
+		}
+
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	return errors.Join(walkErr, tw.Close(), gz.Close(), f.Close())
+}
+
+// extractArchive unpacks a file written by compressFolder into root.
+// Entries that would land outside root are rejected.
+func extractArchive(archive, root string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(root, filepath.FromSlash(hdr.Name))
+		if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("entry is outside the project: %s", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, hdr.FileInfo().Mode().Perm()|0700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, target, hdr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extractFile writes the current tar entry to target, keeping its
+// permissions and modification time
+func extractFile(tr *tar.Reader, target string, hdr *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, hdr.FileInfo().Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, tr); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+}
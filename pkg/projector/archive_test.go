@@ -0,0 +1,101 @@
+package projector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+func TestManager_ArchiveCompress(t *testing.T) {
+	m := newTestManager(t)
+	m.Config().ArchiveDirectory = t.TempDir()
+
+	root := filepath.Join(t.TempDir(), "api")
+	if err := os.MkdirAll(filepath.Join(root, "cmd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cmd", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("cmd/main.go", filepath.Join(root, "main.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(&models.Project{Name: "api", RootPath: root, Enabled: true, Tags: []string{"Work"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := m.Archive("api", ArchiveCompress)
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if !strings.HasSuffix(entry.Location, "api.tar.gz") || entry.Kind != models.KindFavorite {
+		t.Errorf("unexpected archive entry: %+v", entry)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Error("expected the project folder to be removed")
+	}
+	if projects, _ := m.List(TypeFilter{}); len(projects) != 0 {
+		t.Errorf("expected the archived project to be hidden, got %d projects", len(projects))
+	}
+
+	project, err := m.Unarchive("API")
+	if err != nil {
+		t.Fatalf("Unarchive failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "main.go")); err != nil || string(data) != "package main\n" {
+		t.Errorf("expected the folder to be restored, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(entry.Location); !os.IsNotExist(err) {
+		t.Error("expected the archive file to be removed")
+	}
+	found, err := m.Find("api")
+	if err != nil || found.RootPath != project.RootPath || !found.HasTag("Work") {
+		t.Errorf("expected the favorite back with its tags, got %+v, %v", found, err)
+	}
+	if archived, _ := m.Storage().LoadArchived(); len(archived) != 0 {
+		t.Errorf("expected no archived projects left, got %d", len(archived))
+	}
+}
+
+func TestManager_ArchiveCached(t *testing.T) {
+	m := newTestManager(t)
+	m.Config().ArchiveDirectory = t.TempDir()
+
+	root := filepath.Join(t.TempDir(), "old")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cached := &storage.CachedProjects{Git: []*models.Project{{Name: "old", RootPath: root, Enabled: true}}}
+	if err := m.Storage().SaveCache(cached); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := m.Archive("old", ArchiveMove)
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if entry.Location != filepath.Join(m.Config().ArchiveDirectory, "old") {
+		t.Errorf("expected the folder in the archive directory, got %s", entry.Location)
+	}
+	if _, err := m.Archive("old", ArchiveInPlace); err == nil {
+		t.Error("expected error archiving a project that is already archived")
+	}
+
+	if _, err := m.Unarchive("old"); err != nil {
+		t.Fatalf("Unarchive failed: %v", err)
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Errorf("expected the folder moved back: %v", err)
+	}
+	cache, err := m.Storage().LoadCache()
+	if err != nil || len(cache.Git) != 1 || cache.Git[0].RootPath != root {
+		t.Errorf("expected the project back in the git cache, got %+v, %v", cache, err)
+	}
+	if _, err := m.Unarchive("old"); err == nil {
+		t.Error("expected error unarchiving a project that is not archived")
+	}
+}
@@ -0,0 +1,136 @@
+package projector
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+// Artifact is a dependency or build output folder inside a project, which
+// can be deleted and rebuilt
+type Artifact struct {
+	Project string `json:"project"`
+	Path    string `json:"path"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// artifactWorkers bounds how many folders are searched or measured at
+// once
+const artifactWorkers = 8
+
+// vcsDirs are the folders of version control systems, which never hold
+// artifacts
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// FindArtifacts returns the folders inside the projects whose name is one
+// of names, such as node_modules, with their disk usage, largest first.
+// Artifacts are not looked for inside other artifacts, and folders holding
+// files tracked by Git are left out. Missing and offline projects are
+// skipped; an artifact inside nested projects belongs to the innermost.
+func FindArtifacts(projects []*models.Project, names []string) []Artifact {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var searched []*models.Project
+	for _, p := range projects {
+		if PathError(p) == nil {
+			searched = append(searched, p)
+		}
+	}
+	if len(searched) == 0 || len(wanted) == 0 {
+		return nil
+	}
+
+	// Look for artifacts, then give each to its innermost project
+	dirs := make([][]string, len(searched))
+	inParallel(len(searched), func(i int) {
+		dirs[i] = artifactDirs(searched[i].RootPath, wanted)
+	})
+	found := make(map[string]Artifact)
+	owners := make(map[string]*models.Project)
+	for i, p := range searched {
+		for _, dir := range dirs[i] {
+			key := paths.Key(dir)
+			if owner, ok := owners[key]; ok && len(owner.RootPath) >= len(p.RootPath) {
+				continue
+			}
+			owners[key] = p
+			found[key] = Artifact{Project: p.Name, Path: dir}
+		}
+	}
+
+	artifacts := make([]Artifact, 0, len(found))
+	for _, a := range found {
+		artifacts = append(artifacts, a)
+	}
+	inParallel(len(artifacts), func(i int) {
+		artifacts[i].Bytes = DirSize(artifacts[i].Path)
+	})
+	sort.Slice(artifacts, func(i, j int) bool {
+		if artifacts[i].Bytes != artifacts[j].Bytes {
+			return artifacts[i].Bytes > artifacts[j].Bytes
+		}
+		return artifacts[i].Path < artifacts[j].Path
+	})
+	return artifacts
+}
+
+// artifactDirs returns the folders under root named in wanted, without
+// those holding files tracked by Git. Symlinks are not followed.
+func artifactDirs(root string, wanted map[string]bool) []string {
+	var dirs, rels []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == root {
+			return nil
+		}
+		if vcsDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if wanted[d.Name()] {
+			rel, _ := filepath.Rel(root, path)
+			dirs = append(dirs, path)
+			rels = append(rels, rel)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	tracked := scanner.TrackedFolders(root, rels)
+	if len(tracked) == 0 {
+		return dirs
+	}
+	untracked := dirs[:0]
+	for i, dir := range dirs {
+		if !tracked[rels[i]] {
+			untracked = append(untracked, dir)
+		}
+	}
+	return untracked
+}
+
+// inParallel calls fn for 0 to n-1 on up to artifactWorkers goroutines
+func inParallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for range min(artifactWorkers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,49 @@
+package projector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestFindArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string, size int) {
+		path := filepath.Join(dir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, make([]byte, size), 0644)
+	}
+	write("mono/node_modules/a/index.js", 100)
+	write("mono/node_modules/a/node_modules/b/index.js", 50)
+	write("mono/packages/web/node_modules/c/index.js", 300)
+	write("mono/packages/web/src/build/keep.txt", 10)
+	write("mono/.git/build/x", 10)
+	write("mono/main.go", 1000)
+
+	projects := []*models.Project{
+		{Name: "mono", RootPath: filepath.Join(dir, "mono")},
+		{Name: "web", RootPath: filepath.Join(dir, "mono", "packages", "web")},
+		{Name: "gone", RootPath: filepath.Join(dir, "gone")},
+	}
+	artifacts := FindArtifacts(projects, []string{"node_modules", "build"})
+
+	want := []Artifact{
+		{Project: "web", Path: filepath.Join(dir, "mono", "packages", "web", "node_modules"), Bytes: 300},
+		{Project: "mono", Path: filepath.Join(dir, "mono", "node_modules"), Bytes: 150},
+		{Project: "web", Path: filepath.Join(dir, "mono", "packages", "web", "src", "build"), Bytes: 10},
+	}
+	if len(artifacts) != len(want) {
+		t.Fatalf("expected %d artifacts, got %+v", len(want), artifacts)
+	}
+	for i := range want {
+		if artifacts[i] != want[i] {
+			t.Errorf("artifact %d = %+v, want %+v", i, artifacts[i], want[i])
+		}
+	}
+
+	if artifacts := FindArtifacts(projects, nil); artifacts != nil {
+		t.Errorf("expected no artifacts without names, got %+v", artifacts)
+	}
+}
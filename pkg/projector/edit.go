@@ -0,0 +1,261 @@
+package projector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/output"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+// Filter is a condition on a project field, written "field=value" or
+// "field!=value". Values are matched case-insensitively and may use the
+// wildcards * and ?. The tag field matches when any tag does.
+type Filter struct {
+	Field  string
+	Value  string
+	Negate bool
+}
+
+// filterFields are the fields a Filter can test
+var filterFields = []string{"name", "path", "tag", "kind", "language", "enabled", "description", "notes", "icon", "color"}
+
+// ParseFilter parses a filter expression such as "tag=Old",
+// "path=~/clients/*" or "enabled!=true"
+func ParseFilter(expr string) (Filter, error) {
+	field, op, value, ok := splitExpr(expr, "!=", "=")
+	if !ok {
+		return Filter{}, fmt.Errorf("invalid filter '%s' (expected field=value or field!=value)", expr)
+	}
+	if !containsString(filterFields, field) {
+		return Filter{}, fmt.Errorf("invalid filter '%s': unknown field '%s' (valid: %s)", expr, field, strings.Join(filterFields, ", "))
+	}
+	if field == "enabled" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return Filter{}, fmt.Errorf("invalid filter '%s': enabled must be true or false", expr)
+		}
+	}
+	if field == "path" {
+		value = paths.Expand(value)
+	}
+	return Filter{Field: field, Value: value, Negate: op == "!="}, nil
+}
+
+// Match reports whether the project satisfies the filter
+func (f Filter) Match(p *models.Project) bool {
+	var matched bool
+	switch f.Field {
+	case "tag":
+		for _, tag := range p.Tags {
+			if globMatch(f.Value, tag) {
+				matched = true
+				break
+			}
+		}
+	case "enabled":
+		want, _ := strconv.ParseBool(f.Value)
+		matched = p.Enabled == want
+	default:
+		matched = globMatch(f.Value, filterValue(p, f.Field))
+	}
+	return matched != f.Negate
+}
+
+// MatchFilters reports whether the project satisfies every filter
+func MatchFilters(p *models.Project, filters []Filter) bool {
+	for _, f := range filters {
+		if !f.Match(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterValue returns the text of a project field for matching
+func filterValue(p *models.Project, field string) string {
+	switch field {
+	case "name":
+		return p.Name
+	case "path":
+		return p.RootPath
+	case "kind":
+		return string(p.EffectiveKind())
+	case "type":
+		return string(p.Kind)
+	case "language":
+		return p.Language
+	case "description":
+		return p.Description
+	case "notes":
+		return p.Notes
+	case "icon":
+		return p.Icon
+	case "color":
+		return p.Color
+	case "parent":
+		return p.Parent
+	case "remote":
+		return p.RemoteURL
+	case "branch":
+		return p.DefaultBranch
+	}
+	return ""
+}
+
+// globMatch matches s against a pattern where * matches any text,
+// including path separators, and ? one character, ignoring case
+func globMatch(pattern, s string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	re, err := regexp.Compile("(?is)^" + expr + "$")
+	return err == nil && re.MatchString(s)
+}
+
+// Assignment is a change to a project field, written "field=value", or
+// "tag+=value" and "tag-=value" to add or remove one tag
+type Assignment struct {
+	Field string
+	Op    string // "=", "+=" or "-="
+	Value string
+}
+
+// assignFields are the fields an Assignment can set. Names and paths are
+// left out as they must stay unique.
+var assignFields = []string{"enabled", "tag", "tags", "kind", "description", "notes", "icon", "color"}
+
+// ParseAssignment parses an assignment such as "enabled=false",
+// "tag+=Archived" or "tags=Work,Go" (which replaces all tags)
+func ParseAssignment(expr string) (Assignment, error) {
+	field, op, value, ok := splitExpr(expr, "+=", "-=", "=")
+	if !ok {
+		return Assignment{}, fmt.Errorf("invalid assignment '%s' (expected field=value, tag+=value or tag-=value)", expr)
+	}
+	if field == "name" || field == "path" {
+		return Assignment{}, fmt.Errorf("invalid assignment '%s': %s cannot be assigned as it must stay unique", expr, field)
+	}
+	if !containsString(assignFields, field) {
+		return Assignment{}, fmt.Errorf("invalid assignment '%s': unknown field '%s' (valid: %s)", expr, field, strings.Join(assignFields, ", "))
+	}
+	if op != "=" && field != "tag" && field != "tags" {
+		return Assignment{}, fmt.Errorf("invalid assignment '%s': %s only works with tags", expr, op)
+	}
+	if field == "tag" && op == "=" {
+		return Assignment{}, fmt.Errorf("invalid assignment '%s': use tag+=, tag-= or tags=", expr)
+	}
+	switch field {
+	case "enabled":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return Assignment{}, fmt.Errorf("invalid assignment '%s': enabled must be true or false", expr)
+		}
+	case "kind":
+		if _, err := models.ParseFolderKind(value); err != nil {
+			return Assignment{}, err
+		}
+	case "color":
+		if _, err := output.ParseColor(value); err != nil {
+			return Assignment{}, fmt.Errorf("invalid assignment '%s': %w", expr, err)
+		}
+	}
+	if (field == "tag" || field == "tags") && op != "=" {
+		field = "tag"
+	}
+	return Assignment{Field: field, Op: op, Value: value}, nil
+}
+
+// Apply makes the change to the project and reports whether it changed
+func (a Assignment) Apply(p *models.Project) bool {
+	switch a.Field {
+	case "enabled":
+		enabled, _ := strconv.ParseBool(a.Value)
+		if p.Enabled == enabled {
+			return false
+		}
+		p.Enabled = enabled
+	case "tag":
+		tag := strings.TrimSpace(a.Value)
+		if a.Op == "+=" {
+			if tag == "" || p.HasTag(tag) {
+				return false
+			}
+			p.AddTag(tag)
+		} else {
+			if !p.HasTag(tag) {
+				return false
+			}
+			p.RemoveTag(tag)
+		}
+	case "tags":
+		var tags []string
+		for _, tag := range strings.Split(a.Value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" && !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if strings.Join(tags, ",") == strings.Join(p.Tags, ",") {
+			return false
+		}
+		p.Tags = tags
+	case "kind":
+		kind, _ := models.ParseFolderKind(a.Value)
+		if p.FolderKind == kind {
+			return false
+		}
+		p.FolderKind = kind
+		p.WorkspaceFile = ""
+		if kind == models.KindVSCode {
+			p.WorkspaceFile = scanner.WorkspaceFile(p.RootPath)
+		}
+	case "description":
+		if p.Description == a.Value {
+			return false
+		}
+		p.Description = a.Value
+	case "notes":
+		if p.Notes == a.Value {
+			return false
+		}
+		p.Notes = a.Value
+	case "icon":
+		if p.Icon == a.Value {
+			return false
+		}
+		p.Icon = a.Value
+	case "color":
+		if p.Color == a.Value {
+			return false
+		}
+		p.Color = a.Value
+	default:
+		return false
+	}
+	return true
+}
+
+// splitExpr splits "field<op>value" at the first of ops found, trying
+// them in order at each position, and lowercases the field
+func splitExpr(expr string, ops ...string) (field, op, value string, ok bool) {
+	for i := range expr {
+		for _, op := range ops {
+			if strings.HasPrefix(expr[i:], op) {
+				field = strings.ToLower(strings.TrimSpace(expr[:i]))
+				return field, op, strings.TrimSpace(expr[i+len(op):]), field != ""
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package projector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Filter
+		wantErr bool
+	}{
+		{expr: "tag=Old", want: Filter{Field: "tag", Value: "Old"}},
+		{expr: " Name != api* ", want: Filter{Field: "name", Value: "api*", Negate: true}},
+		{expr: "enabled=false", want: Filter{Field: "enabled", Value: "false"}},
+		{expr: "description=a=b", want: Filter{Field: "description", Value: "a=b"}},
+		{expr: "enabled=maybe", wantErr: true},
+		{expr: "owner=me", wantErr: true},
+		{expr: "tag", wantErr: true},
+		{expr: "=Old", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFilter(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFilter(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	p := &models.Project{Name: "acme-api", RootPath: "/home/me/clients/acme/api", Tags: []string{"Work", "Go"}, Enabled: true, FolderKind: models.KindGit}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"name=ACME-API", true},
+		{"name=acme", false},
+		{"name=acme*", true},
+		{"name=acme-ap?", true},
+		{"path=/home/me/clients/*", true},
+		{"tag=go", true},
+		{"tag=Old", false},
+		{"tag!=Old", true},
+		{"kind=git", true},
+		{"enabled=true", true},
+		{"enabled!=true", false},
+		{"language=", true},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q) error = %v", tt.expr, err)
+		}
+		if got := f.Match(p); got != tt.want {
+			t.Errorf("%q matched = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseAssignment(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Assignment
+		wantErr bool
+	}{
+		{expr: "enabled=false", want: Assignment{Field: "enabled", Op: "=", Value: "false"}},
+		{expr: "tag+=Archived", want: Assignment{Field: "tag", Op: "+=", Value: "Archived"}},
+		{expr: "tags-=Old", want: Assignment{Field: "tag", Op: "-=", Value: "Old"}},
+		{expr: "tags=Work, Go", want: Assignment{Field: "tags", Op: "=", Value: "Work, Go"}},
+		{expr: "description=", want: Assignment{Field: "description", Op: "=", Value: ""}},
+		{expr: "tag=Old", wantErr: true},
+		{expr: "name=x", wantErr: true},
+		{expr: "path=/x", wantErr: true},
+		{expr: "notes+=x", wantErr: true},
+		{expr: "kind=cvs", wantErr: true},
+		{expr: "enabled=maybe", wantErr: true},
+		{expr: "color=#268bd2 bold", want: Assignment{Field: "color", Op: "=", Value: "#268bd2 bold"}},
+		{expr: "color=purple", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAssignment(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAssignment(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseAssignment(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestAssignment_Apply(t *testing.T) {
+	p := &models.Project{Name: "api", Tags: []string{"Work", "Active"}, Enabled: true}
+	apply := func(expr string) bool {
+		t.Helper()
+		a, err := ParseAssignment(expr)
+		if err != nil {
+			t.Fatalf("ParseAssignment(%q) error = %v", expr, err)
+		}
+		return a.Apply(p)
+	}
+
+	if !apply("enabled=false") || p.Enabled {
+		t.Error("expected enabled=false to disable the project")
+	}
+	if apply("enabled=false") {
+		t.Error("expected no change when already disabled")
+	}
+	if !apply("tag+=Archived") || !apply("tag-=Active") || apply("tag-=Active") {
+		t.Error("expected tag+= and tag-= to change tags once")
+	}
+	if !reflect.DeepEqual(p.Tags, []string{"Work", "Archived"}) {
+		t.Errorf("Tags = %v, want [Work Archived]", p.Tags)
+	}
+	if !apply("tags=Go, Go ,CLI") || !reflect.DeepEqual(p.Tags, []string{"Go", "CLI"}) {
+		t.Errorf("Tags = %v, want [Go CLI]", p.Tags)
+	}
+	if !apply("description=Old service") || p.Description != "Old service" {
+		t.Errorf("Description = %q, want Old service", p.Description)
+	}
+	if !apply("color=blue") || p.Color != "blue" {
+		t.Errorf("Color = %q, want blue", p.Color)
+	}
+}
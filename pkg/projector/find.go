@@ -0,0 +1,484 @@
+package projector
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// TypeFilter specifies which project types to include when loading projects.
+type TypeFilter struct {
+	Favorites bool
+	Git       bool
+	SVN       bool
+	Mercurial bool
+	VSCode    bool
+	Any       bool
+}
+
+// ShowAll returns true if no specific type filter is set.
+func (f TypeFilter) ShowAll() bool {
+	return !f.Favorites && !f.Git && !f.SVN && !f.Mercurial && !f.VSCode && !f.Any
+}
+
+// NotFoundError reports a name that matches no project. It matches
+// models.ErrProjectNotFound.
+type NotFoundError struct {
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("project '%s' not found", e.Name)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == models.ErrProjectNotFound
+}
+
+// AmbiguousError reports a name that matches several projects, none of
+// them exactly. It matches models.ErrAmbiguousName.
+type AmbiguousError struct {
+	Name    string
+	Matches []*models.Project // Best match first
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("multiple projects match '%s'", e.Name)
+}
+
+func (e *AmbiguousError) Is(target error) bool {
+	return target == models.ErrAmbiguousName
+}
+
+// TypeNames are the project type names accepted by ParseTypes
+var TypeNames = []string{"favorites", "git", "svn", "mercurial", "vscode", "any"}
+
+// ParseTypes parses project type names, given separately or
+// comma-separated (e.g. "git,vscode"), into a filter selecting them
+func ParseTypes(names []string) (TypeFilter, error) {
+	var f TypeFilter
+	for _, arg := range names {
+		for _, name := range strings.Split(arg, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if !f.set(name) {
+				return TypeFilter{}, fmt.Errorf("invalid type '%s' (use %s)", name, strings.Join(TypeNames, ", "))
+			}
+		}
+	}
+	return f, nil
+}
+
+// set selects the named type, reporting false for an unknown name
+func (f *TypeFilter) set(name string) bool {
+	switch name {
+	case "favorites":
+		f.Favorites = true
+	case "git":
+		f.Git = true
+	case "svn":
+		f.SVN = true
+	case "mercurial":
+		f.Mercurial = true
+	case "vscode":
+		f.VSCode = true
+	case "any":
+		f.Any = true
+	default:
+		return false
+	}
+	return true
+}
+
+// Exclude returns the filter without the types selected by excluded; an
+// empty filter, which shows all types, loses them from all types. It is
+// an error to exclude every type that was selected.
+func (f TypeFilter) Exclude(excluded TypeFilter) (TypeFilter, error) {
+	if excluded.ShowAll() {
+		return f, nil
+	}
+	if f.ShowAll() {
+		f = TypeFilter{Favorites: true, Git: true, SVN: true, Mercurial: true, VSCode: true, Any: true}
+	}
+	f.Favorites = f.Favorites && !excluded.Favorites
+	f.Git = f.Git && !excluded.Git
+	f.SVN = f.SVN && !excluded.SVN
+	f.Mercurial = f.Mercurial && !excluded.Mercurial
+	f.VSCode = f.VSCode && !excluded.VSCode
+	f.Any = f.Any && !excluded.Any
+	if f.ShowAll() {
+		return TypeFilter{}, fmt.Errorf("every selected type is excluded")
+	}
+	return f, nil
+}
+
+// includes reports whether the filter selects projects of the scanner type
+func (f TypeFilter) includes(t scanner.ScannerType) bool {
+	switch t {
+	case scanner.ScannerGit:
+		return f.Git
+	case scanner.ScannerSVN:
+		return f.SVN
+	case scanner.ScannerMercurial:
+		return f.Mercurial
+	case scanner.ScannerVSCode:
+		return f.VSCode
+	case scanner.ScannerAny:
+		return f.Any
+	}
+	return false
+}
+
+// LoadOptions control what LoadFilteredProjects does besides loading
+type LoadOptions struct {
+	// OnStaleCache, when set, is called with the scanner types whose
+	// cached projects are older than the storage's cache TTL, so callers
+	// can suggest or start a rescan
+	OnStaleCache func(store *storage.Storage, stale []scanner.ScannerType)
+}
+
+// LoadFilteredProjects loads projects from storage based on the given type filter.
+// It returns all matching projects from both favorites and cache.
+func LoadFilteredProjects(store *storage.Storage, filter TypeFilter, opts LoadOptions) ([]*models.Project, error) {
+	var allProjects []*models.Project
+	showAll := filter.ShowAll()
+
+	// Load favorites
+	if showAll || filter.Favorites {
+		projects, err := store.LoadProjects()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load projects: %w", err)
+		}
+		allProjects = append(allProjects, projects.Projects...)
+	}
+
+	// Load cached auto-detected projects
+	if showAll || filter.Git || filter.SVN || filter.Mercurial || filter.VSCode || filter.Any {
+		cache, err := store.LoadCache()
+		if errors.Is(err, storage.ErrCacheCorrupt) {
+			// Scanning again replaces the cache
+			slog.Warn("ignoring the cache", "error", err)
+		}
+		if err == nil {
+			if opts.OnStaleCache != nil {
+				var stale []scanner.ScannerType
+				for _, kind := range cache.StaleKinds(store.CacheTTL(), time.Now()) {
+					if showAll || filter.includes(kind) {
+						stale = append(stale, kind)
+					}
+				}
+				if len(stale) > 0 {
+					opts.OnStaleCache(store, stale)
+				}
+			}
+			if showAll || filter.Git {
+				allProjects = append(allProjects, cache.Git...)
+			}
+			if showAll || filter.SVN {
+				allProjects = append(allProjects, cache.SVN...)
+			}
+			if showAll || filter.Mercurial {
+				allProjects = append(allProjects, cache.Mercurial...)
+			}
+			if showAll || filter.VSCode {
+				allProjects = append(allProjects, cache.VSCode...)
+			}
+			if showAll || filter.Any {
+				allProjects = append(allProjects, cache.Any...)
+			}
+		}
+	}
+
+	allProjects = MergeKinds(allProjects)
+	MarkOffline(allProjects)
+	return allProjects, nil
+}
+
+// kindPriority orders the lists a folder can be found in: a favorite wins
+// over cached projects, and Git over the other kinds of folders
+var kindPriority = map[models.ProjectKind]int{
+	models.KindFavorite:  0,
+	models.KindGit:       1,
+	models.KindSVN:       2,
+	models.KindMercurial: 3,
+	models.KindVSCode:    4,
+	models.KindAny:       5,
+}
+
+// MergeKinds keeps one project per folder when it was found in several
+// lists, such as a Git repository holding a .code-workspace file that is
+// cached as both git and vscode. The entry of the highest-priority list
+// (favorites, git, svn, mercurial, vscode, any) is kept in its place and
+// Kinds of every kept project lists the lists its folder was found in.
+func MergeKinds(projects []*models.Project) []*models.Project {
+	merged := make([]*models.Project, 0, len(projects))
+	byPath := make(map[string]int, len(projects))
+	for _, p := range projects {
+		key := paths.Key(paths.ExpandHome(p.RootPath))
+		i, ok := byPath[key]
+		if !ok {
+			p.Kinds = []models.ProjectKind{p.Kind}
+			byPath[key] = len(merged)
+			merged = append(merged, p)
+			continue
+		}
+
+		kept := merged[i]
+		kinds := kept.Kinds
+		if !slices.Contains(kinds, p.Kind) {
+			kinds = append(kinds, p.Kind)
+		}
+		if kindPriority[p.Kind] < kindPriority[kept.Kind] {
+			merged[i], kept = p, p
+		}
+		slices.SortStableFunc(kinds, func(a, b models.ProjectKind) int {
+			return kindPriority[a] - kindPriority[b]
+		})
+		kept.Kinds = kinds
+	}
+	return merged
+}
+
+// MarkOffline sets Offline on the projects whose volume is not mounted.
+// Each volume is checked once.
+func MarkOffline(projects []*models.Project) {
+	mounted := make(map[string]bool)
+	for _, p := range projects {
+		if p.Volume == "" {
+			continue
+		}
+		up, ok := mounted[p.Volume]
+		if !ok {
+			up = paths.VolumeMounted(p.Volume)
+			mounted[p.Volume] = up
+		}
+		p.Offline = !up
+	}
+}
+
+// FilterEnabled returns only enabled projects from the given list.
+func FilterEnabled(projects []*models.Project) []*models.Project {
+	filtered := make([]*models.Project, 0, len(projects))
+	for _, p := range projects {
+		if p.Enabled {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// FilterByTag returns only projects that have the specified tag, or a
+// tag below it such as Work/ClientA for Work.
+func FilterByTag(projects []*models.Project, tag string) []*models.Project {
+	if tag == "" {
+		return projects
+	}
+	filtered := make([]*models.Project, 0)
+	for _, p := range projects {
+		if p.HasTagUnder(tag) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// FilterByLanguage returns only projects whose detected language matches (case-insensitive).
+func FilterByLanguage(projects []*models.Project, language string) []*models.Project {
+	if language == "" {
+		return projects
+	}
+	filtered := make([]*models.Project, 0)
+	for _, p := range projects {
+		if strings.EqualFold(p.Language, language) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// CurrentProjectArg is the project name shorthand for the project
+// containing the current working directory
+const CurrentProjectArg = "."
+
+// PreviousProjectArg is the project name shorthand for the project opened
+// before the most recently opened one, like "cd -"
+const PreviousProjectArg = "-"
+
+// PreviousOpen returns the path of the project opened before the most
+// recently opened one in the open history, or "" when no other project was
+// opened. Going back to it makes it the most recent, so repeating this
+// switches between two projects.
+func PreviousOpen(history []models.OpenRecord) string {
+	if len(history) == 0 {
+		return ""
+	}
+	last := history[len(history)-1].Path
+	for i := len(history) - 2; i >= 0; i-- {
+		if history[i].Path != last {
+			return history[i].Path
+		}
+	}
+	return ""
+}
+
+// FindPreviousProject returns the project among projects that was opened
+// before the most recently opened one (see PreviousOpen)
+func FindPreviousProject(store *storage.Storage, projects []*models.Project) (*models.Project, error) {
+	history, err := store.LoadHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	path := PreviousOpen(history)
+	if path == "" {
+		return nil, fmt.Errorf("no previously opened project")
+	}
+	for _, p := range projects {
+		if paths.Equal(p.RootPath, path) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("the previously opened project (%s) is not available", paths.Collapse(path))
+}
+
+// FindProjectContaining returns the project whose root path equals dir or
+// is its nearest ancestor, walking up from dir.
+func FindProjectContaining(projects []*models.Project, dir string) *models.Project {
+	byPath := make(map[string]*models.Project, len(projects))
+	for _, p := range projects {
+		byPath[paths.Key(p.RootPath)] = p
+	}
+
+	for dir = filepath.Clean(dir); ; dir = filepath.Dir(dir) {
+		if p, ok := byPath[paths.Key(dir)]; ok {
+			return p
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return nil
+		}
+	}
+}
+
+// FindParent returns the project p is a subproject of, or nil when p has
+// no parent or it is not among projects
+func FindParent(projects []*models.Project, p *models.Project) *models.Project {
+	if p.Parent == "" {
+		return nil
+	}
+	for _, q := range projects {
+		if q != p && strings.EqualFold(q.Name, p.Parent) {
+			return q
+		}
+	}
+	return nil
+}
+
+// ResolveParent finds the project named name for p to become a subproject
+// of. Its folder must contain p's folder.
+func ResolveParent(projects []*models.Project, p *models.Project, name string) (*models.Project, error) {
+	var parent *models.Project
+	for _, q := range projects {
+		if strings.EqualFold(q.Name, name) {
+			parent = q
+			break
+		}
+	}
+	if parent == nil {
+		return nil, &NotFoundError{Name: name}
+	}
+	if !paths.Within(parent.RootPath, p.RootPath) || paths.Equal(parent.RootPath, p.RootPath) {
+		return nil, fmt.Errorf("%s is not inside the folder of '%s' (%s)", paths.Collapse(p.RootPath), parent.Name, paths.Collapse(parent.RootPath))
+	}
+	return parent, nil
+}
+
+// FindCurrentProject returns the project containing the current working directory.
+func FindCurrentProject(projects []*models.Project) (*models.Project, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if p := FindProjectContaining(projects, cwd); p != nil {
+		return p, nil
+	}
+	// The stored path may be the unresolved form of a symlinked cwd
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		if p := FindProjectContaining(projects, resolved); p != nil {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no project contains the current directory (%s)", cwd)
+}
+
+// ExcludeCurrent returns projects without the project containing the
+// current working directory, or projects itself when no project contains
+// it. Other entries for the same folder are left out too.
+func ExcludeCurrent(projects []*models.Project) []*models.Project {
+	current, err := FindCurrentProject(projects)
+	if err != nil {
+		return projects
+	}
+	filtered := make([]*models.Project, 0, len(projects))
+	for _, p := range projects {
+		if !paths.Equal(p.RootPath, current.RootPath) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// FindProjectByName finds a project by name with exact or partial matching.
+// Returns the matched project and any error.
+// If multiple partial matches are found, returns an error with the matches,
+// ranked best first (see RankMatches).
+// The name "." resolves to the project containing the current directory.
+// With opts.FullPath, names are also matched against project paths.
+func FindProjectByName(projects []*models.Project, name string, opts MatchOptions) (*models.Project, []*models.Project, error) {
+	if name == CurrentProjectArg {
+		p, err := FindCurrentProject(projects)
+		return p, nil, err
+	}
+
+	// First try exact match (case-insensitive)
+	for _, p := range projects {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil, nil
+		}
+	}
+
+	// With opts.FullPath, a single project whose path ends with the name,
+	// such as "clients/acme", counts as an exact match
+	if opts.FullPath {
+		var found []*models.Project
+		for _, p := range projects {
+			if pathEndsWith(p, name) {
+				found = append(found, p)
+			}
+		}
+		if len(found) == 1 {
+			return found[0], nil, nil
+		}
+	}
+
+	// Try partial and fuzzy matches
+	matches := RankMatches(projects, name, opts)
+
+	if len(matches) == 1 {
+		return matches[0], nil, nil
+	} else if len(matches) > 1 {
+		return nil, matches, &AmbiguousError{Name: name, Matches: matches}
+	}
+
+	return nil, nil, &NotFoundError{Name: name}
+}
@@ -0,0 +1,576 @@
+package projector
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+func TestTypeFilter_ShowAll(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter TypeFilter
+		want   bool
+	}{
+		{
+			name:   "empty filter shows all",
+			filter: TypeFilter{},
+			want:   true,
+		},
+		{
+			name:   "favorites only",
+			filter: TypeFilter{Favorites: true},
+			want:   false,
+		},
+		{
+			name:   "git only",
+			filter: TypeFilter{Git: true},
+			want:   false,
+		},
+		{
+			name:   "multiple filters",
+			filter: TypeFilter{Favorites: true, Git: true},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.ShowAll(); got != tt.want {
+				t.Errorf("ShowAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterEnabled(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "enabled1", Enabled: true},
+		{Name: "disabled1", Enabled: false},
+		{Name: "enabled2", Enabled: true},
+		{Name: "disabled2", Enabled: false},
+	}
+
+	filtered := FilterEnabled(projects)
+
+	if len(filtered) != 2 {
+		t.Errorf("expected 2 enabled projects, got %d", len(filtered))
+	}
+
+	for _, p := range filtered {
+		if !p.Enabled {
+			t.Errorf("expected all filtered projects to be enabled, got disabled: %s", p.Name)
+		}
+	}
+}
+
+func TestFilterByTag(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "work1", Tags: []string{"Work"}},
+		{Name: "personal1", Tags: []string{"Personal"}},
+		{Name: "work2", Tags: []string{"Work", "Go"}},
+		{Name: "notags", Tags: []string{}},
+		{Name: "client", Tags: []string{"Work/ClientA"}},
+		{Name: "workshop", Tags: []string{"Workshop"}},
+	}
+
+	tests := []struct {
+		name      string
+		tag       string
+		wantCount int
+		wantNames []string
+	}{
+		{
+			name:      "filter by Work",
+			tag:       "Work",
+			wantCount: 3,
+			wantNames: []string{"work1", "work2", "client"},
+		},
+		{
+			name:      "filter by Personal",
+			tag:       "Personal",
+			wantCount: 1,
+			wantNames: []string{"personal1"},
+		},
+		{
+			name:      "filter by Go",
+			tag:       "Go",
+			wantCount: 1,
+			wantNames: []string{"work2"},
+		},
+		{
+			name:      "empty tag returns all",
+			tag:       "",
+			wantCount: 6,
+		},
+		{
+			name:      "filter by nested tag",
+			tag:       "Work/ClientA",
+			wantCount: 1,
+			wantNames: []string{"client"},
+		},
+		{
+			name:      "non-existent tag",
+			tag:       "NonExistent",
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := FilterByTag(projects, tt.tag)
+			if len(filtered) != tt.wantCount {
+				t.Errorf("got %d projects, want %d", len(filtered), tt.wantCount)
+			}
+
+			if tt.wantNames != nil {
+				gotNames := make(map[string]bool)
+				for _, p := range filtered {
+					gotNames[p.Name] = true
+				}
+				for _, name := range tt.wantNames {
+					if !gotNames[name] {
+						t.Errorf("expected project %q not found", name)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindProjectByName(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "my-project"},
+		{Name: "another-project"},
+		{Name: "my-other-project"},
+		{Name: "test"},
+	}
+
+	tests := []struct {
+		name        string
+		searchName  string
+		wantProject string
+		wantMatches int
+		wantErr     bool
+	}{
+		{
+			name:        "exact match",
+			searchName:  "my-project",
+			wantProject: "my-project",
+			wantErr:     false,
+		},
+		{
+			name:        "exact match case insensitive",
+			searchName:  "MY-PROJECT",
+			wantProject: "my-project",
+			wantErr:     false,
+		},
+		{
+			name:        "single partial match",
+			searchName:  "test",
+			wantProject: "test",
+			wantErr:     false,
+		},
+		{
+			name:        "fuzzy match",
+			searchName:  "myoth",
+			wantProject: "my-other-project",
+			wantErr:     false,
+		},
+		{
+			name:        "multiple partial matches",
+			searchName:  "my",
+			wantMatches: 2,
+			wantErr:     true,
+		},
+		{
+			name:       "no match",
+			searchName: "nonexistent",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project, matches, err := FindProjectByName(projects, tt.searchName, MatchOptions{})
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				if tt.wantMatches > 0 && len(matches) != tt.wantMatches {
+					t.Errorf("expected %d matches, got %d", tt.wantMatches, len(matches))
+				}
+				var notFound *NotFoundError
+				if errors.As(err, &notFound) != (tt.wantMatches == 0) {
+					t.Errorf("NotFoundError = %v for %d matches", notFound, len(matches))
+				}
+				if errors.Is(err, models.ErrProjectNotFound) != (tt.wantMatches == 0) || errors.Is(err, models.ErrAmbiguousName) != (tt.wantMatches > 0) {
+					t.Errorf("error %v does not match the sentinel for %d matches", err, len(matches))
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if project == nil {
+					t.Errorf("expected project, got nil")
+				} else if project.Name != tt.wantProject {
+					t.Errorf("got project %q, want %q", project.Name, tt.wantProject)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterByLanguage(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "api", Language: "go"},
+		{Name: "web", Language: "typescript"},
+		{Name: "docs"},
+	}
+
+	if got := FilterByLanguage(projects, "Go"); len(got) != 1 || got[0].Name != "api" {
+		t.Errorf("expected [api], got %v", got)
+	}
+	if got := FilterByLanguage(projects, ""); len(got) != 3 {
+		t.Errorf("expected all projects for empty language, got %d", len(got))
+	}
+}
+
+func TestResolveParent(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "mono", RootPath: "/work/mono"},
+		{Name: "other", RootPath: "/work/other"},
+	}
+	child := &models.Project{Name: "svc", RootPath: "/work/mono/services/svc"}
+
+	parent, err := ResolveParent(projects, child, "MONO")
+	if err != nil || parent != projects[0] {
+		t.Fatalf("ResolveParent() = %v, %v; want mono", parent, err)
+	}
+	if _, err := ResolveParent(projects, child, "other"); err == nil {
+		t.Error("expected an error for a parent not containing the folder")
+	}
+	if _, err := ResolveParent(projects, projects[0], "mono"); err == nil {
+		t.Error("expected an error for a project as its own parent")
+	}
+	var notFound *NotFoundError
+	if _, err := ResolveParent(projects, child, "missing"); !errors.As(err, &notFound) {
+		t.Errorf("ResolveParent() error = %v, want NotFoundError", err)
+	}
+
+	child.Parent = "mono"
+	if got := FindParent(projects, child); got != projects[0] {
+		t.Errorf("FindParent() = %v, want mono", got)
+	}
+	child.Parent = "missing"
+	if got := FindParent(projects, child); got != nil {
+		t.Errorf("FindParent() = %v, want nil", got)
+	}
+}
+
+func TestFindProjectContaining(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "mono", RootPath: "/work/mono"},
+		{Name: "svc", RootPath: "/work/mono/services/svc"},
+		{Name: "other", RootPath: "/work/other"},
+	}
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/work/mono", "mono"},
+		{"/work/mono/docs", "mono"},
+		{"/work/mono/services/svc/cmd/server", "svc"},
+		{"/work/other/", "other"},
+		{"/work/monorepo", ""},
+		{"/", ""},
+	}
+
+	for _, tt := range tests {
+		got := FindProjectContaining(projects, tt.dir)
+		name := ""
+		if got != nil {
+			name = got.Name
+		}
+		if name != tt.want {
+			t.Errorf("FindProjectContaining(%q) = %q, want %q", tt.dir, name, tt.want)
+		}
+	}
+}
+
+func TestFindProjectByName_CurrentDirectory(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src", "pkg")
+	os.MkdirAll(sub, 0755)
+
+	projects := []*models.Project{{Name: "here", RootPath: root}}
+
+	t.Chdir(sub)
+	p, _, err := FindProjectByName(projects, ".", MatchOptions{})
+	if err != nil {
+		t.Fatalf("FindProjectByName(\".\") failed: %v", err)
+	}
+	if p.Name != "here" {
+		t.Errorf("expected 'here', got '%s'", p.Name)
+	}
+
+	t.Chdir(t.TempDir())
+	if _, _, err := FindProjectByName(projects, ".", MatchOptions{}); err == nil {
+		t.Error("expected error outside any project")
+	}
+}
+
+func TestFindProjectByName_FullPath(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "portal", RootPath: "/work/clients/acme"},
+		{Name: "acme-tools", RootPath: "/work/internal/tools"},
+		{Name: "site", RootPath: "/work/clients/globex"},
+	}
+
+	if _, _, err := FindProjectByName(projects, "clients/acme", MatchOptions{}); err == nil {
+		t.Error("expected paths to be ignored without FullPath")
+	}
+
+	opts := MatchOptions{FullPath: true}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"clients/acme", "portal"},
+		{"/work/clients/acme", "portal"},
+		{"CLIENTS/GLOBEX", "site"},
+		{"internal", "acme-tools"},
+		{"portal", "portal"},
+	}
+	for _, tt := range tests {
+		p, _, err := FindProjectByName(projects, tt.query, opts)
+		if err != nil {
+			t.Errorf("FindProjectByName(%q) error = %v", tt.query, err)
+			continue
+		}
+		if p.Name != tt.want {
+			t.Errorf("FindProjectByName(%q) = %s, want %s", tt.query, p.Name, tt.want)
+		}
+	}
+
+	// Names containing the query rank before paths containing it
+	_, matches, err := FindProjectByName(projects, "acm", opts)
+	if err == nil || len(matches) != 2 || matches[0].Name != "acme-tools" || matches[1].Name != "portal" {
+		t.Errorf("FindProjectByName(acm) = %v, %v; want acme-tools then portal", matches, err)
+	}
+	// Segments match from their start only
+	if _, _, err := FindProjectByName(projects, "ients", opts); err == nil {
+		t.Error("expected no match inside a path segment")
+	}
+}
+
+func TestExcludeCurrent(t *testing.T) {
+	root := t.TempDir()
+	here := filepath.Join(root, "here")
+	os.MkdirAll(filepath.Join(here, "src"), 0755)
+
+	projects := []*models.Project{
+		{Name: "here", RootPath: here},
+		{Name: "here-git", RootPath: here + string(filepath.Separator)},
+		{Name: "parent", RootPath: root},
+	}
+
+	t.Chdir(filepath.Join(here, "src"))
+	got := ExcludeCurrent(projects)
+	if len(got) != 1 || got[0].Name != "parent" {
+		t.Errorf("ExcludeCurrent() = %v, want only parent", got)
+	}
+
+	t.Chdir(t.TempDir())
+	if got := ExcludeCurrent(projects); len(got) != len(projects) {
+		t.Errorf("ExcludeCurrent() outside any project = %v, want all", got)
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	f, err := ParseTypes([]string{"git,VSCode", " any "})
+	if err != nil {
+		t.Fatalf("ParseTypes() error = %v", err)
+	}
+	if want := (TypeFilter{Git: true, VSCode: true, Any: true}); f != want {
+		t.Errorf("ParseTypes() = %+v, want %+v", f, want)
+	}
+	if f, err := ParseTypes(nil); err != nil || !f.ShowAll() {
+		t.Errorf("ParseTypes(nil) = %+v, %v; want all types", f, err)
+	}
+	if _, err := ParseTypes([]string{"git,cvs"}); err == nil {
+		t.Error("expected error for an unknown type")
+	}
+}
+
+func TestTypeFilter_Exclude(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   TypeFilter
+		excluded TypeFilter
+		want     TypeFilter
+		wantErr  bool
+	}{
+		{
+			name:     "nothing excluded",
+			filter:   TypeFilter{Git: true},
+			excluded: TypeFilter{},
+			want:     TypeFilter{Git: true},
+		},
+		{
+			name:     "excluded from all types",
+			filter:   TypeFilter{},
+			excluded: TypeFilter{Any: true, SVN: true},
+			want:     TypeFilter{Favorites: true, Git: true, Mercurial: true, VSCode: true},
+		},
+		{
+			name:     "excluded from selected types",
+			filter:   TypeFilter{Git: true, VSCode: true},
+			excluded: TypeFilter{VSCode: true, Any: true},
+			want:     TypeFilter{Git: true},
+		},
+		{
+			name:     "every selected type excluded",
+			filter:   TypeFilter{Git: true},
+			excluded: TypeFilter{Git: true},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.Exclude(tt.excluded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Exclude() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Exclude() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviousOpen(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	record := func(paths ...string) []models.OpenRecord {
+		var history []models.OpenRecord
+		for i, p := range paths {
+			history = append(history, models.OpenRecord{Path: p, OpenedAt: at.Add(time.Duration(i) * time.Minute)})
+		}
+		return history
+	}
+
+	tests := []struct {
+		history []models.OpenRecord
+		want    string
+	}{
+		{nil, ""},
+		{record("/a", "/a"), ""},
+		{record("/a", "/b"), "/a"},
+		{record("/a", "/b", "/c", "/c"), "/b"},
+		{record("/a", "/b", "/a"), "/b"},
+	}
+	for _, tt := range tests {
+		if got := PreviousOpen(tt.history); got != tt.want {
+			t.Errorf("PreviousOpen(%v) = %q, want %q", tt.history, got, tt.want)
+		}
+	}
+}
+
+func TestMergeKinds(t *testing.T) {
+	m := newTestManager(t)
+	store := m.Storage()
+
+	dir := t.TempDir()
+	app := filepath.Join(dir, "app")
+	lib := filepath.Join(dir, "lib")
+	tools := filepath.Join(dir, "tools")
+	if err := store.SaveProjects(&models.ProjectList{Projects: []*models.Project{
+		{Name: "My App", RootPath: app, Enabled: true},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveCache(&storage.CachedProjects{
+		Git:    []*models.Project{{Name: "app", RootPath: app, Enabled: true}, {Name: "lib", RootPath: lib, Enabled: true}},
+		VSCode: []*models.Project{{Name: "lib", RootPath: lib + string(filepath.Separator), Enabled: true}, {Name: "app", RootPath: app, Enabled: true}},
+		Any:    []*models.Project{{Name: "tools", RootPath: tools, Enabled: true}, {Name: "lib", RootPath: lib, Enabled: true}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := LoadFilteredProjects(store, TypeFilter{}, LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name  string
+		kinds []models.ProjectKind
+	}{
+		{"My App", []models.ProjectKind{models.KindFavorite, models.KindGit, models.KindVSCode}},
+		{"lib", []models.ProjectKind{models.KindGit, models.KindVSCode, models.KindAny}},
+		{"tools", []models.ProjectKind{models.KindAny}},
+	}
+	if len(projects) != len(want) {
+		t.Fatalf("expected %d projects, got %d", len(want), len(projects))
+	}
+	for i, w := range want {
+		if projects[i].Name != w.name || !slices.Equal(projects[i].Kinds, w.kinds) {
+			t.Errorf("project %d = %s %v, want %s %v", i, projects[i].Name, projects[i].Kinds, w.name, w.kinds)
+		}
+	}
+	if projects[1].Kind != models.KindGit {
+		t.Errorf("expected the git entry of lib to be kept, got %s", projects[1].Kind)
+	}
+
+	// A lower-priority entry listed first is replaced in its place
+	merged := MergeKinds([]*models.Project{
+		{Name: "ws", RootPath: app, Kind: models.KindVSCode},
+		{Name: "repo", RootPath: app, Kind: models.KindGit},
+	})
+	if len(merged) != 1 || merged[0].Name != "repo" || !slices.Equal(merged[0].Kinds, []models.ProjectKind{models.KindGit, models.KindVSCode}) {
+		t.Errorf("MergeKinds() = %+v", merged[0])
+	}
+
+	// Only the loaded lists are merged
+	projects, err = LoadFilteredProjects(store, TypeFilter{VSCode: true}, LoadOptions{})
+	if err != nil || len(projects) != 2 {
+		t.Errorf("expected both vscode projects with --type vscode, got %d, %v", len(projects), err)
+	}
+}
+
+func TestLoadFilteredProjects_OnStaleCache(t *testing.T) {
+	store, _ := storage.NewStorage(t.TempDir())
+	store.SetCacheTTL(time.Hour)
+	if err := store.SaveCache(&storage.CachedProjects{
+		Git: []*models.Project{{Name: "app", RootPath: "/work/app", Enabled: true}},
+		Any: []*models.Project{{Name: "tools", RootPath: "/work/tools", Enabled: true}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a handler, loading never acts on the stale cache
+	if _, err := LoadFilteredProjects(store, TypeFilter{}, LoadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []scanner.ScannerType
+	opts := LoadOptions{OnStaleCache: func(_ *storage.Storage, stale []scanner.ScannerType) {
+		got = stale
+	}}
+	if _, err := LoadFilteredProjects(store, TypeFilter{Git: true}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []scanner.ScannerType{scanner.ScannerGit}) {
+		t.Errorf("expected only the loaded stale types, got %v", got)
+	}
+}
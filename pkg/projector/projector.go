@@ -0,0 +1,499 @@
+// Package projector is the library interface to projector. A Manager
+// lists, adds, removes, scans for and opens projects using the same
+// config and storage as the projector command, so other Go tools and
+// launchers can embed it instead of running the binary.
+//
+//	m, err := projector.Load()
+//	if err != nil {
+//		return err
+//	}
+//	p, err := m.Find("api")
+//	if err != nil {
+//		return err
+//	}
+//	return m.Open(p, projector.OpenOptions{})
+package projector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// Manager provides project operations on top of a config and its storage
+type Manager struct {
+	cfg   *config.Config
+	store *storage.Storage
+
+	// onStaleCache is passed to LoadFilteredProjects by List
+	onStaleCache func(store *storage.Storage, stale []scanner.ScannerType)
+
+	// editors are the built-in and custom editors Open resolves names in;
+	// editorsErr reports the custom editors that were skipped
+	editors    *editor.Registry
+	editorsErr error
+}
+
+// OpenOptions control how Manager.Open launches the editor
+type OpenOptions struct {
+	// Editor overrides the configured editor when set
+	Editor string
+
+	// File opens a file relative to the project root instead of the root
+	File string
+
+	// Line jumps to a line of File in editors that support it
+	Line int
+
+	// NewWindow opens a new editor window even if the config does not
+	NewWindow bool
+
+	// Reveal opens the project folder in the file manager instead of
+	// the editor
+	Reveal bool
+
+	// RestoreSession loads the vim session saved when the project was
+	// last opened, with rememberContext set
+	RestoreSession bool
+
+	// DevContainer opens the project inside its dev container (see
+	// editor.DevContainerCommand) instead of the local folder
+	DevContainer bool
+
+	// Workspace opens the workspace file of a VS Code project even in
+	// editors not known to support workspaces
+	Workspace bool
+}
+
+// Load creates a Manager for the active profile's config, creating a
+// default config if there is none
+func Load() (*Manager, error) {
+	cfg, err := config.LoadOrCreateConfig(config.LoadOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return New(cfg)
+}
+
+// New creates a Manager using the storage at cfg's projects location,
+// opening projects in the custom editors from cfg as well as the built-in
+// ones. Invalid custom editors are skipped; Editors reports them.
+func New(cfg *config.Config) (*Manager, error) {
+	store, err := OpenStorage(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return NewWithStorage(cfg, store), nil
+}
+
+// NewWithStorage creates a Manager like New, using store instead of
+// opening the storage at cfg's projects location
+func NewWithStorage(cfg *config.Config, store *storage.Storage) *Manager {
+	editors, err := EditorRegistry(cfg)
+	return &Manager{cfg: cfg, store: store, editors: editors, editorsErr: err}
+}
+
+// Editors returns the editors projects are opened in, and an error
+// reporting the invalid custom editors left out of them
+func (m *Manager) Editors() (*editor.Registry, error) {
+	return m.editors, m.editorsErr
+}
+
+// EditorRegistry returns a registry of the built-in editors and the custom
+// editors from cfg, which take precedence over built-in editors of the
+// same name. Invalid entries are skipped and reported in the returned
+// error.
+func EditorRegistry(cfg *config.Config) (*editor.Registry, error) {
+	r := editor.NewRegistry()
+	var errs []error
+	for _, c := range cfg.CustomEditors {
+		e, err := editor.NewCustom(c.Name, c.Command, c.Args, c.NewWindowArg, c.Terminal)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.Register(e)
+	}
+	return r, errors.Join(errs...)
+}
+
+// EncryptionKeyEnv is the environment variable holding the passphrase for
+// encrypted projects files
+const EncryptionKeyEnv = "PROJECTOR_ENCRYPTION_KEY"
+
+// OpenStorage opens the storage at the configured projects location with
+// the storage options from cfg applied
+func OpenStorage(cfg *config.Config) (*storage.Storage, error) {
+	store, err := storage.NewStorage(cfg.GetProjectsLocation())
+	if err != nil {
+		return nil, err
+	}
+	store.SetPruneMissingOnLoad(cfg.PruneMissingOnLoad)
+	store.SetBackupCount(cfg.BackupCount)
+
+	ttl, err := cfg.GetCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	store.SetCacheTTL(ttl)
+
+	key, err := EncryptionKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.EncryptProjects && key == "" {
+		return nil, fmt.Errorf("encryptProjects is set but no key was found (set %s or encryptionKeyCommand)", EncryptionKeyEnv)
+	}
+	if key != "" {
+		store.SetEncryption(key, cfg.EncryptProjects)
+	}
+	return store, nil
+}
+
+// EncryptionKey returns the passphrase for encrypted projects files from
+// the environment or, when encryption is enabled, from the output of the
+// configured key command (e.g. a keyring or password manager lookup)
+func EncryptionKey(cfg *config.Config) (string, error) {
+	if key := os.Getenv(EncryptionKeyEnv); key != "" {
+		return key, nil
+	}
+	fields := strings.Fields(cfg.EncryptionKeyCommand)
+	if !cfg.EncryptProjects || len(fields) == 0 {
+		return "", nil
+	}
+	out, err := exec.Command(fields[0], fields[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run encryptionKeyCommand: %w", err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// Config returns the manager's config
+func (m *Manager) Config() *config.Config {
+	return m.cfg
+}
+
+// Storage returns the manager's storage
+func (m *Manager) Storage() *storage.Storage {
+	return m.store
+}
+
+// SetStaleCacheHandler sets the function List calls with the scanner types
+// whose cached projects are stale (see LoadOptions)
+func (m *Manager) SetStaleCacheHandler(handler func(store *storage.Storage, stale []scanner.ScannerType)) {
+	m.onStaleCache = handler
+}
+
+// List returns the enabled favorites and cached projects of the types
+// selected by filter
+func (m *Manager) List(filter TypeFilter) ([]*models.Project, error) {
+	projects, err := LoadFilteredProjects(m.store, filter, LoadOptions{OnStaleCache: m.onStaleCache})
+	if err != nil {
+		return nil, err
+	}
+	return FilterEnabled(projects), nil
+}
+
+// Find returns the enabled project named name, matched as by
+// FindProjectByName. The name "-" finds the previously opened project.
+func (m *Manager) Find(name string) (*models.Project, error) {
+	projects, err := m.List(TypeFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if name == PreviousProjectArg {
+		return FindPreviousProject(m.store, projects)
+	}
+	return m.findByName(projects, name)
+}
+
+// findByName matches name as by FindProjectByName, with paths matched as
+// set by filterOnFullPath, listing the candidates in the error when it is
+// ambiguous
+func (m *Manager) findByName(projects []*models.Project, name string) (*models.Project, error) {
+	project, matches, err := FindProjectByName(projects, name, MatchOptions{FullPath: m.cfg.FilterOnFullPath})
+	if err != nil && len(matches) > 0 {
+		names := make([]string, len(matches))
+		for i, p := range matches {
+			names[i] = p.Name
+		}
+		return nil, fmt.Errorf("%w: %s", err, strings.Join(names, ", "))
+	}
+	return project, err
+}
+
+// Add saves project as a favorite
+func (m *Manager) Add(project *models.Project) error {
+	return AddFavorite(m.store, project)
+}
+
+// FindFavorite returns the favorite named name, or the one containing the
+// current directory for "."
+func (m *Manager) FindFavorite(name string) (*models.Project, error) {
+	projects, err := m.store.LoadProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load projects: %w", err)
+	}
+	return findFavorite(projects, name)
+}
+
+func findFavorite(projects *models.ProjectList, name string) (*models.Project, error) {
+	if name == CurrentProjectArg {
+		return FindCurrentProject(projects.Projects)
+	}
+	if project := projects.FindByName(name); project != nil {
+		return project, nil
+	}
+	return nil, &NotFoundError{Name: name}
+}
+
+// Remove removes the favorite named name ("." for the one containing the
+// current directory) and returns it
+func (m *Manager) Remove(name string) (*models.Project, error) {
+	var project *models.Project
+	err := m.store.Update(func(projects *models.ProjectList) error {
+		var err error
+		if project, err = findFavorite(projects, name); err != nil {
+			return err
+		}
+		projects.Remove(project.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// PathMissingError reports a project whose folder does not exist, or is
+// on a volume that is not mounted. It matches models.ErrPathMissing.
+type PathMissingError struct {
+	Name string
+	Path string
+	// Volume is set when the project is offline: the volume holding its
+	// folder is not mounted
+	Volume string
+}
+
+func (e *PathMissingError) Error() string {
+	if e.Volume != "" {
+		return fmt.Sprintf("project '%s' is offline: %s is not mounted", e.Name, e.Volume)
+	}
+	return fmt.Sprintf("project path does not exist: %s", e.Path)
+}
+
+func (e *PathMissingError) Is(target error) bool {
+	return target == models.ErrPathMissing
+}
+
+// PathError returns a *PathMissingError when the folder of project is
+// missing, because the volume it is on is not mounted or it no longer
+// exists, and nil when the folder exists
+func PathError(project *models.Project) error {
+	if _, err := os.Stat(project.RootPath); !os.IsNotExist(err) {
+		return nil
+	}
+	missing := &PathMissingError{Name: project.Name, Path: project.RootPath}
+	if project.Volume != "" && !paths.VolumeMounted(project.Volume) {
+		missing.Volume = project.Volume
+	}
+	return missing
+}
+
+// Open opens project, or a file within it, in the configured editor (or
+// the file manager with opts.Reveal) and records the project in the open
+// history. VS Code projects open their workspace file in editors that
+// support workspaces. With rememberContext, the branch and the vim session
+// the project is left in are saved for the next open (see SaveContext).
+func (m *Manager) Open(project *models.Project, opts OpenOptions) error {
+	if err := PathError(project); err != nil {
+		return err
+	}
+
+	name := opts.Editor
+	if name == "" {
+		name = m.cfg.Editor
+	}
+	newWindow := opts.NewWindow || m.cfg.OpenInNewWindow
+
+	path, line := project.RootPath, 0
+	if opts.File != "" {
+		var err error
+		if path, err = ResolveProjectFile(project.RootPath, opts.File); err != nil {
+			return err
+		}
+		line = opts.Line
+	}
+	if opts.DevContainer {
+		config := project.DevContainer
+		if config == "" {
+			config = scanner.DevContainerConfig(project.RootPath)
+		}
+		if config == "" {
+			return fmt.Errorf("project '%s' has no dev container configuration (.devcontainer/devcontainer.json)", project.Name)
+		}
+		// Without a resolvable editor the dev container opens in VS Code
+		e, _ := m.editors.Resolve(name)
+		if err := editor.DevContainerCommand(e, project.RootPath, config).Start(); err != nil {
+			return fmt.Errorf("failed to open the dev container: %w", err)
+		}
+	} else if opts.Reveal {
+		if err := editor.Reveal(path); err != nil {
+			return fmt.Errorf("failed to open file manager: %w", err)
+		}
+	} else {
+		e, err := m.editors.Resolve(name)
+		if err != nil {
+			return err
+		}
+		if opts.File == "" && (opts.Workspace || editor.OpensWorkspaceFiles(e)) {
+			if file := WorkspaceFilePath(project); file != "" {
+				path = file
+			}
+		}
+		editorOpts := editor.Options{Line: line, NewWindow: newWindow}
+		if m.cfg.RememberContext && editor.KeepsSessions(e) {
+			editorOpts.Session = m.store.SessionFilePath(project.RootPath)
+			editorOpts.LoadSession = opts.RestoreSession && opts.File == "" && paths.Exists(editorOpts.Session)
+			if err := os.MkdirAll(filepath.Dir(editorOpts.Session), 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(editorOpts.Session), err)
+			}
+		}
+		if err := editor.Launch(e, path, editorOpts); err != nil {
+			return err
+		}
+		if m.cfg.RememberContext {
+			// Like the history, the context is a convenience
+			_ = m.SaveContext(project)
+		}
+	}
+
+	// The history only feeds sessions, so failing to record is not an error
+	_ = m.store.RecordOpen(time.Now(), project.RootPath)
+	return nil
+}
+
+// OpenStrategyFor returns how project opens when no other way is asked
+// for, as set for its folder kind by openStrategies, and the editor named
+// with it. Kinds without a strategy open in the editor.
+func OpenStrategyFor(cfg *config.Config, project *models.Project) (config.OpenStrategy, string, error) {
+	kind := string(project.EffectiveKind())
+	for key, value := range cfg.OpenStrategies {
+		if !strings.EqualFold(key, kind) {
+			continue
+		}
+		strategy, editorName, err := config.ParseOpenStrategy(value)
+		if err != nil {
+			return "", "", fmt.Errorf("openStrategies.%s: %w", kind, err)
+		}
+		return strategy, editorName, nil
+	}
+	return config.OpenInEditor, "", nil
+}
+
+// SaveContext records the branch checked out in project and its vim
+// session file, if one was written, so the next open can offer to
+// restore them. Terminal editors are waited for, so for them this is the
+// state they were quit in.
+func (m *Manager) SaveContext(project *models.Project) error {
+	ctx := &models.ProjectContext{
+		Branch:  scanner.CurrentBranch(project.RootPath),
+		SavedAt: time.Now(),
+	}
+	if session := m.store.SessionFilePath(project.RootPath); paths.Exists(session) {
+		ctx.Session = session
+	}
+	if ctx.Branch == "" && ctx.Session == "" {
+		return m.store.SaveContext(project.RootPath, nil)
+	}
+	return m.store.SaveContext(project.RootPath, ctx)
+}
+
+// SavedContext returns the context recorded when project was last opened,
+// or nil
+func (m *Manager) SavedContext(project *models.Project) (*models.ProjectContext, error) {
+	contexts, err := m.store.LoadContexts()
+	if err != nil {
+		return nil, err
+	}
+	return contexts[project.RootPath], nil
+}
+
+// WorkspaceFilePath returns the full path of the workspace file of a VS
+// Code project: the one recorded on the project, or the one found in its
+// root if that is gone or was never recorded. Other projects, and VS Code
+// projects without a workspace file, return an empty string.
+func WorkspaceFilePath(project *models.Project) string {
+	if project.EffectiveKind() != models.KindVSCode {
+		return ""
+	}
+	if project.WorkspaceFile != "" {
+		if file := filepath.Join(project.RootPath, project.WorkspaceFile); paths.Exists(file) {
+			return file
+		}
+	}
+	if name := scanner.WorkspaceFile(project.RootPath); name != "" {
+		return filepath.Join(project.RootPath, name)
+	}
+	return ""
+}
+
+// AddFavorite saves a new project to favorites, rejecting projects whose
+// path or name is already in use. The folder kind, and the workspace file
+// of VS Code projects, are detected when not set.
+func AddFavorite(store *storage.Storage, project *models.Project) error {
+	if project.FolderKind == "" {
+		project.FolderKind = scanner.DetectKind(project.RootPath)
+	}
+	if project.FolderKind == models.KindVSCode && project.WorkspaceFile == "" {
+		project.WorkspaceFile = scanner.WorkspaceFile(project.RootPath)
+	}
+	if project.DevContainer == "" {
+		project.DevContainer = scanner.DevContainerConfig(project.RootPath)
+	}
+	if project.Volume == "" {
+		project.Volume = paths.VolumeRoot(project.RootPath)
+	}
+
+	return store.Update(func(projects *models.ProjectList) error {
+		// Check if project already exists
+		for _, p := range projects.Projects {
+			if paths.Equal(p.RootPath, project.RootPath) {
+				return fmt.Errorf("project already exists: %s", p.Name)
+			}
+			if p.Name == project.Name {
+				return fmt.Errorf("project with name '%s' already exists", project.Name)
+			}
+		}
+		projects.Add(project)
+		return nil
+	})
+}
+
+// ResolveProjectFile joins a file reference to the project root, rejecting
+// paths that escape the project
+func ResolveProjectFile(root, file string) (string, error) {
+	if filepath.IsAbs(file) {
+		return "", fmt.Errorf("file must be relative to the project root: %s", file)
+	}
+	path := filepath.Join(root, file)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file is outside the project: %s", file)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", fmt.Errorf("file does not exist: %s", path)
+	}
+	return path, nil
+}
@@ -0,0 +1,403 @@
+package projector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/editor"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	cfg.ProjectsLocation = t.TempDir()
+	m, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return m
+}
+
+func TestManager_AddFindRemove(t *testing.T) {
+	m := newTestManager(t)
+
+	api := &models.Project{Name: "api", RootPath: t.TempDir(), Enabled: true, Kind: models.KindFavorite}
+	web := &models.Project{Name: "api-web", RootPath: t.TempDir(), Enabled: true, Kind: models.KindFavorite}
+	for _, p := range []*models.Project{api, web} {
+		if err := m.Add(p); err != nil {
+			t.Fatalf("Add(%s) failed: %v", p.Name, err)
+		}
+	}
+	if err := m.Add(&models.Project{Name: "api", RootPath: "/elsewhere"}); err == nil {
+		t.Error("expected error adding a duplicate name")
+	}
+
+	projects, err := m.List(TypeFilter{})
+	if err != nil || len(projects) != 2 {
+		t.Fatalf("List() = %d projects, %v; want 2", len(projects), err)
+	}
+
+	found, err := m.Find("API")
+	if err != nil || found.RootPath != api.RootPath {
+		t.Errorf("Find(API) = %v, %v", found, err)
+	}
+	if _, err := m.Find("ap"); err == nil {
+		t.Error("expected error for an ambiguous name")
+	}
+
+	removed, err := m.Remove("api")
+	if err != nil || removed.Name != "api" {
+		t.Fatalf("Remove(api) = %v, %v", removed, err)
+	}
+	if _, err := m.Remove("api"); err == nil {
+		t.Error("expected error removing a missing project")
+	}
+	if projects, _ := m.List(TypeFilter{}); len(projects) != 1 {
+		t.Errorf("expected 1 project after remove, got %d", len(projects))
+	}
+}
+
+func TestManager_AddDetectsFolderKind(t *testing.T) {
+	m := newTestManager(t)
+
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(&models.Project{Name: "repo", RootPath: repo, Enabled: true}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := m.Add(&models.Project{Name: "svn", RootPath: t.TempDir(), Enabled: true, FolderKind: models.KindSVN}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	for name, want := range map[string]models.ProjectKind{"repo": models.KindGit, "svn": models.KindSVN} {
+		p, err := m.Find(name)
+		if err != nil {
+			t.Fatalf("Find(%s) failed: %v", name, err)
+		}
+		if p.FolderKind != want {
+			t.Errorf("expected %s to have folder kind %q, got %q", name, want, p.FolderKind)
+		}
+	}
+}
+
+func TestPathError(t *testing.T) {
+	dir := t.TempDir()
+	if err := PathError(&models.Project{Name: "here", RootPath: dir}); err != nil {
+		t.Errorf("PathError() = %v for an existing folder", err)
+	}
+
+	err := PathError(&models.Project{Name: "gone", RootPath: filepath.Join(dir, "gone")})
+	var missing *PathMissingError
+	if !errors.Is(err, models.ErrPathMissing) || !errors.As(err, &missing) || missing.Volume != "" {
+		t.Errorf("PathError() = %v, want a missing path", err)
+	}
+
+	usb := filepath.Join(dir, "usb")
+	err = PathError(&models.Project{Name: "ejected", RootPath: filepath.Join(usb, "ejected"), Volume: usb})
+	if !errors.As(err, &missing) || missing.Volume != usb {
+		t.Errorf("PathError() = %v, want an offline project", err)
+	}
+}
+
+func TestWorkspaceFilePath(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "app.code-workspace"), []byte("{}"), 0644)
+
+	tests := []struct {
+		project *models.Project
+		want    string
+	}{
+		{&models.Project{RootPath: root, Kind: models.KindVSCode, WorkspaceFile: "app.code-workspace"}, "app.code-workspace"},
+		{&models.Project{RootPath: root, Kind: models.KindVSCode, WorkspaceFile: "gone.code-workspace"}, "app.code-workspace"},
+		{&models.Project{RootPath: root, Kind: models.KindFavorite, FolderKind: models.KindVSCode}, "app.code-workspace"},
+		{&models.Project{RootPath: root, Kind: models.KindGit}, ""},
+		{&models.Project{RootPath: t.TempDir(), Kind: models.KindVSCode}, ""},
+	}
+	for i, tt := range tests {
+		got := WorkspaceFilePath(tt.project)
+		if tt.want != "" {
+			tt.want = filepath.Join(root, tt.want)
+		}
+		if got != tt.want {
+			t.Errorf("case %d: WorkspaceFilePath() = %q, want %q", i, got, tt.want)
+		}
+	}
+}
+
+func TestManager_Scan(t *testing.T) {
+	m := newTestManager(t)
+
+	base := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(base, "repo", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m.Config().GitBaseFolders = []string{base}
+	m.Config().CacheProjectsBetweenSessions = true
+
+	var reported int
+	cache, err := m.Scan(context.Background(), ScanOptions{Types: []scanner.ScannerType{scanner.ScannerGit}}, func(job ScanJob, found int, err error) {
+		reported += found
+	})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(cache.Git) != 1 || reported != 1 {
+		t.Fatalf("expected 1 git repository, got %d (reported %d)", len(cache.Git), reported)
+	}
+
+	projects, err := m.List(TypeFilter{Git: true})
+	if err != nil || len(projects) != 1 || projects[0].Name != "repo" {
+		t.Errorf("expected the scan to be cached, got %v, %v", projects, err)
+	}
+}
+
+func TestResolveProjectFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := ResolveProjectFile(root, "main.go")
+	if err != nil || path != filepath.Join(root, "main.go") {
+		t.Errorf("ResolveProjectFile() = %q, %v", path, err)
+	}
+	for _, file := range []string{"missing.go", "../outside.go", filepath.Join(root, "main.go")} {
+		if _, err := ResolveProjectFile(root, file); err == nil {
+			t.Errorf("ResolveProjectFile(%q) expected error", file)
+		}
+	}
+}
+
+func TestOpenStrategyFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.OpenStrategies = map[string]string{"vscode": "workspace", "any": "terminal", "git": "editor:nvim"}
+
+	tests := []struct {
+		project  *models.Project
+		strategy config.OpenStrategy
+		editor   string
+	}{
+		{&models.Project{Kind: models.KindVSCode}, config.OpenWorkspace, ""},
+		{&models.Project{Kind: models.KindFavorite, FolderKind: models.KindAny}, config.OpenInTerminal, ""},
+		{&models.Project{Kind: models.KindGit}, config.OpenInEditor, "nvim"},
+		{&models.Project{Kind: models.KindSVN}, config.OpenInEditor, ""},
+		{&models.Project{Kind: models.KindFavorite}, config.OpenInEditor, ""},
+	}
+	for _, tt := range tests {
+		strategy, editor, err := OpenStrategyFor(cfg, tt.project)
+		if err != nil || strategy != tt.strategy || editor != tt.editor {
+			t.Errorf("OpenStrategyFor(%s) = %q, %q, %v, want %q, %q", tt.project.EffectiveKind(), strategy, editor, err, tt.strategy, tt.editor)
+		}
+	}
+
+	cfg.OpenStrategies["svn"] = "shell"
+	if _, _, err := OpenStrategyFor(cfg, &models.Project{Kind: models.KindSVN}); err == nil {
+		t.Error("expected an error for an invalid strategy")
+	}
+}
+
+func TestManager_OpenRecordsHistory(t *testing.T) {
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("no 'true' executable to stand in for an editor")
+	}
+	m := newTestManager(t)
+	project := &models.Project{Name: "api", RootPath: t.TempDir()}
+
+	if err := m.Open(project, OpenOptions{Editor: "true"}); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	history, err := m.Storage().LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Path != project.RootPath {
+		t.Errorf("expected the opened project in history, got %+v", history)
+	}
+}
+
+func TestEncryptionKey(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("no 'echo' executable to stand in for a key command")
+	}
+	cfg := config.DefaultConfig()
+	cfg.EncryptionKeyCommand = "echo from-command"
+
+	t.Setenv(EncryptionKeyEnv, "")
+	if key, err := EncryptionKey(cfg); err != nil || key != "" {
+		t.Errorf("expected no key while encryption is off, got %q, %v", key, err)
+	}
+
+	cfg.EncryptProjects = true
+	if key, err := EncryptionKey(cfg); err != nil || key != "from-command" {
+		t.Errorf("EncryptionKey() = %q, %v; want from-command", key, err)
+	}
+
+	t.Setenv(EncryptionKeyEnv, "from-env")
+	if key, err := EncryptionKey(cfg); err != nil || key != "from-env" {
+		t.Errorf("EncryptionKey() = %q, %v; want from-env", key, err)
+	}
+
+	t.Setenv(EncryptionKeyEnv, "")
+	cfg.EncryptionKeyCommand = ""
+	cfg.ProjectsLocation = t.TempDir()
+	if _, err := OpenStorage(cfg); err == nil {
+		t.Error("expected OpenStorage to fail when encryption has no key")
+	}
+}
+
+func TestManager_PreviewScanJobs(t *testing.T) {
+	m := newTestManager(t)
+
+	base := t.TempDir()
+	for _, name := range []string{"kept", "new"} {
+		if err := os.MkdirAll(filepath.Join(base, name, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Config().GitBaseFolders = []string{base}
+	m.Config().CacheProjectsBetweenSessions = true
+
+	cached := &storage.CachedProjects{Git: []*models.Project{
+		{Name: "kept", RootPath: filepath.Join(base, "kept"), Enabled: true},
+		{Name: "gone", RootPath: filepath.Join(base, "gone"), Enabled: true},
+	}}
+	if err := m.Storage().SaveCache(cached); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := ScanJobs(m.Config(), ScanOptions{Types: []scanner.ScannerType{scanner.ScannerGit}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previews, err := m.PreviewScanJobs(context.Background(), jobs, false)
+	if err != nil {
+		t.Fatalf("PreviewScanJobs failed: %v", err)
+	}
+	if len(previews) != 1 {
+		t.Fatalf("expected 1 preview, got %d", len(previews))
+	}
+	p := previews[0]
+	if len(p.Projects) != 2 || len(p.Added) != 1 || p.Added[0] != filepath.Join(base, "new") ||
+		len(p.Removed) != 1 || p.Removed[0] != filepath.Join(base, "gone") {
+		t.Errorf("unexpected preview: %d projects, added %v, removed %v", len(p.Projects), p.Added, p.Removed)
+	}
+
+	cache, err := m.Storage().LoadCache()
+	if err != nil || len(cache.Git) != 2 || cache.Git[1].Name != "gone" {
+		t.Errorf("expected the cache to be left unchanged, got %+v, %v", cache, err)
+	}
+	if states, err := m.Storage().LoadScanState(); err == nil && len(states) > 0 {
+		t.Errorf("expected no scan state to be recorded, got %d types", len(states))
+	}
+}
+
+func TestManager_ScanLimitKeepsCache(t *testing.T) {
+	m := newTestManager(t)
+
+	base := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(base, name, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Config().GitBaseFolders = []string{base}
+	m.Config().CacheProjectsBetweenSessions = true
+	m.Config().MaxProjectsPerScan = 1
+
+	cached := &storage.CachedProjects{Git: []*models.Project{{Name: "old", RootPath: filepath.Join(base, "old"), Enabled: true}}}
+	if err := m.Storage().SaveCache(cached); err != nil {
+		t.Fatal(err)
+	}
+
+	var reported error
+	_, err := m.Scan(context.Background(), ScanOptions{Types: []scanner.ScannerType{scanner.ScannerGit}}, func(job ScanJob, found int, err error) {
+		reported = err
+	})
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !errors.Is(reported, scanner.ErrScanLimit) {
+		t.Errorf("expected the scan limit to be reported, got %v", reported)
+	}
+	cache, err := m.Storage().LoadCache()
+	if err != nil || len(cache.Git) != 1 || cache.Git[0].Name != "old" {
+		t.Errorf("expected the cached projects to be kept, got %+v, %v", cache, err)
+	}
+}
+
+func TestManager_RunScanJobsKeepsOffline(t *testing.T) {
+	m := newTestManager(t)
+
+	base := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(base, "local", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	m.Config().GitBaseFolders = []string{base}
+	m.Config().CacheProjectsBetweenSessions = true
+
+	usb := filepath.Join(t.TempDir(), "usb")
+	cached := &storage.CachedProjects{Git: []*models.Project{
+		{Name: "ejected", RootPath: filepath.Join(usb, "ejected"), Volume: usb, Enabled: true},
+	}}
+	if err := m.Storage().SaveCache(cached); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := ScanJobs(m.Config(), ScanOptions{Types: []scanner.ScannerType{scanner.ScannerGit}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RunScanJobs(context.Background(), jobs, false, nil); err != nil {
+		t.Fatalf("RunScanJobs failed: %v", err)
+	}
+
+	cache, err := m.Storage().LoadCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, p := range cache.Git {
+		names[p.Name] = true
+	}
+	if len(cache.Git) != 2 || !names["local"] || !names["ejected"] {
+		t.Errorf("expected the scanned and the offline project, got %+v", cache.Git)
+	}
+}
+
+func TestNewWithStorage_CustomEditors(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ProjectsLocation = t.TempDir()
+	cfg.CustomEditors = []config.CustomEditorConfig{
+		{Name: "zed", Command: "zed"},
+		{Name: "broken"},
+	}
+	store, err := OpenStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	editors, err := NewWithStorage(cfg, store).Editors()
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected the invalid custom editor to be reported, got %v", err)
+	}
+	if e, ok := editors.Lookup("zed"); !ok || e.Command() != "zed" {
+		t.Errorf("expected the zed editor to be registered, got %v, %v", e, ok)
+	}
+	if _, ok := editor.Lookup("zed"); ok {
+		t.Error("expected the custom editor to stay out of the built-in editors")
+	}
+}
@@ -0,0 +1,334 @@
+package projector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+// Query is a filter expression over project fields, such as
+//
+//	kind==git && tag in (Work,OSS) && name~api
+//
+// Conditions compare a field with a value: == and != match the whole
+// value, with * and ? wildcards, and ~ and !~ match part of it; "in"
+// matches any of a list of values. Values ignore case and may be quoted.
+// Conditions are combined with && (and), || (or), ! (not) and
+// parentheses, && binding tighter than ||.
+type Query struct {
+	expr string
+	root queryNode
+}
+
+// queryFields are the fields a Query can test: those of Filter, plus the
+// list a project comes from and its recorded repository details
+var queryFields = append(append([]string{}, filterFields...), "type", "parent", "remote", "branch")
+
+// queryNode is a parsed part of a Query
+type queryNode interface {
+	match(p *models.Project) bool
+}
+
+type queryAnd []queryNode
+type queryOr []queryNode
+type queryNot struct{ node queryNode }
+
+func (n queryAnd) match(p *models.Project) bool {
+	for _, c := range n {
+		if !c.match(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n queryOr) match(p *models.Project) bool {
+	for _, c := range n {
+		if c.match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n queryNot) match(p *models.Project) bool {
+	return !n.node.match(p)
+}
+
+func (f Filter) match(p *models.Project) bool {
+	return f.Match(p)
+}
+
+// ParseQuery parses a filter expression (see Query)
+func ParseQuery(expr string) (*Query, error) {
+	qp := &queryParser{s: expr}
+	root, err := qp.parseOr()
+	if err == nil && !qp.done() {
+		err = qp.errorf("unexpected '%s'", qp.rest())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter '%s': %w", expr, err)
+	}
+	return &Query{expr: expr, root: root}, nil
+}
+
+// String returns the expression the query was parsed from
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Match reports whether the project satisfies the query
+func (q *Query) Match(p *models.Project) bool {
+	return q.root.match(p)
+}
+
+// FilterByQuery returns the projects matching every query
+func FilterByQuery(projects []*models.Project, queries []*Query) []*models.Project {
+	if len(queries) == 0 {
+		return projects
+	}
+	filtered := make([]*models.Project, 0, len(projects))
+	for _, p := range projects {
+		matched := true
+		for _, q := range queries {
+			if !q.Match(p) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// queryParser is a recursive descent parser over a query expression
+type queryParser struct {
+	s   string
+	pos int
+}
+
+func (qp *queryParser) skipSpace() {
+	for qp.pos < len(qp.s) && unicode.IsSpace(rune(qp.s[qp.pos])) {
+		qp.pos++
+	}
+}
+
+func (qp *queryParser) done() bool {
+	qp.skipSpace()
+	return qp.pos == len(qp.s)
+}
+
+func (qp *queryParser) rest() string {
+	return qp.s[qp.pos:]
+}
+
+func (qp *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at position %d: %s", qp.pos+1, fmt.Sprintf(format, args...))
+}
+
+// accept consumes tok if the input continues with it
+func (qp *queryParser) accept(tok string) bool {
+	qp.skipSpace()
+	if strings.HasPrefix(qp.s[qp.pos:], tok) {
+		qp.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// acceptWord consumes the keyword word (ignoring case) if it is the next
+// whole word
+func (qp *queryParser) acceptWord(word string) bool {
+	qp.skipSpace()
+	end := qp.pos + len(word)
+	if end > len(qp.s) || !strings.EqualFold(qp.s[qp.pos:end], word) {
+		return false
+	}
+	if end < len(qp.s) && isWordChar(qp.s[end]) {
+		return false
+	}
+	qp.pos = end
+	return true
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '-' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func (qp *queryParser) parseOr() (queryNode, error) {
+	var or queryOr
+	for {
+		node, err := qp.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, node)
+		if !qp.accept("||") && !qp.acceptWord("or") {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (qp *queryParser) parseAnd() (queryNode, error) {
+	var and queryAnd
+	for {
+		node, err := qp.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, node)
+		if !qp.accept("&&") && !qp.acceptWord("and") {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (qp *queryParser) parseUnary() (queryNode, error) {
+	qp.skipSpace()
+	if !strings.HasPrefix(qp.rest(), "!=") && qp.accept("!") || qp.acceptWord("not") {
+		node, err := qp.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{node}, nil
+	}
+	if qp.accept("(") {
+		node, err := qp.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !qp.accept(")") {
+			return nil, qp.errorf("expected ')'")
+		}
+		return node, nil
+	}
+	return qp.parseCondition()
+}
+
+// parseCondition parses "field op value" or "field in (value, ...)"
+func (qp *queryParser) parseCondition() (queryNode, error) {
+	qp.skipSpace()
+	start := qp.pos
+	for qp.pos < len(qp.s) && isWordChar(qp.s[qp.pos]) {
+		qp.pos++
+	}
+	field := strings.ToLower(qp.s[start:qp.pos])
+	if field == "" {
+		if qp.done() {
+			return nil, qp.errorf("expected a condition")
+		}
+		return nil, qp.errorf("expected a field name at '%s'", qp.rest())
+	}
+	if !containsString(queryFields, field) {
+		qp.pos = start
+		return nil, qp.errorf("unknown field '%s' (valid: %s)", field, strings.Join(queryFields, ", "))
+	}
+
+	if qp.acceptWord("in") {
+		values, err := qp.parseList()
+		if err != nil {
+			return nil, err
+		}
+		var or queryOr
+		for _, v := range values {
+			f, err := qp.filter(field, v, false)
+			if err != nil {
+				return nil, err
+			}
+			or = append(or, f)
+		}
+		return or, nil
+	}
+
+	for _, op := range []string{"==", "!=", "!~", "=", "~"} {
+		if !qp.accept(op) {
+			continue
+		}
+		value, err := qp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if op == "~" || op == "!~" {
+			value = "*" + value + "*"
+		}
+		return qp.filter(field, value, op == "!=" || op == "!~")
+	}
+	return nil, qp.errorf("expected ==, !=, ~, !~ or in after '%s'", field)
+}
+
+// parseList parses "(value, value, ...)"
+func (qp *queryParser) parseList() ([]string, error) {
+	if !qp.accept("(") {
+		return nil, qp.errorf("expected '(' after in")
+	}
+	var values []string
+	for {
+		value, err := qp.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if qp.accept(")") {
+			return values, nil
+		}
+		if !qp.accept(",") {
+			return nil, qp.errorf("expected ',' or ')'")
+		}
+	}
+}
+
+// parseValue parses a quoted value, or a bare one running up to space,
+// a comma, a closing parenthesis, && or ||
+func (qp *queryParser) parseValue() (string, error) {
+	qp.skipSpace()
+	if qp.pos < len(qp.s) && (qp.s[qp.pos] == '"' || qp.s[qp.pos] == '\'') {
+		quote := qp.s[qp.pos]
+		end := strings.IndexByte(qp.s[qp.pos+1:], quote)
+		if end < 0 {
+			return "", qp.errorf("unterminated quote")
+		}
+		value := qp.s[qp.pos+1 : qp.pos+1+end]
+		qp.pos += end + 2
+		return value, nil
+	}
+	start := qp.pos
+	for qp.pos < len(qp.s) {
+		rest := qp.s[qp.pos:]
+		if unicode.IsSpace(rune(rest[0])) || rest[0] == ',' || rest[0] == ')' ||
+			strings.HasPrefix(rest, "&&") || strings.HasPrefix(rest, "||") {
+			break
+		}
+		qp.pos++
+	}
+	if qp.pos == start {
+		return "", qp.errorf("expected a value")
+	}
+	return qp.s[start:qp.pos], nil
+}
+
+// filter builds the condition on field, checking the value
+func (qp *queryParser) filter(field, value string, negate bool) (Filter, error) {
+	switch field {
+	case "enabled":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return Filter{}, qp.errorf("enabled must be true or false")
+		}
+	case "path":
+		value = paths.Expand(value)
+	}
+	return Filter{Field: field, Value: value, Negate: negate}, nil
+}
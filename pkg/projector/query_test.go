@@ -0,0 +1,69 @@
+package projector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestQuery_Match(t *testing.T) {
+	api := &models.Project{Name: "billing-api", RootPath: "/work/acme/billing-api", Tags: []string{"Work"}, Enabled: true, FolderKind: models.KindGit, Kind: models.KindFavorite, DefaultBranch: "main"}
+	oss := &models.Project{Name: "cobra", RootPath: "/src/cobra", Tags: []string{"OSS", "Go"}, Enabled: true, Kind: models.KindGit}
+	notes := &models.Project{Name: "Notes", RootPath: "/home/me/notes", Enabled: false, FolderKind: models.KindAny, Kind: models.KindFavorite}
+	projects := []*models.Project{api, oss, notes}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"kind==git", "billing-api cobra"},
+		{"kind==git && tag in (Work,OSS) && name~api", "billing-api"},
+		{"tag in (work, 'oss')", "billing-api cobra"},
+		{"type==favorites", "billing-api Notes"},
+		{"name~API || enabled==false", "billing-api Notes"},
+		{"!(kind==git) or branch==main", "billing-api Notes"},
+		{"not tag==Go and kind != any", "billing-api"},
+		{"path==/work/* && name!~cobra", "billing-api"},
+		{`name=="Notes"`, "Notes"},
+		{"kind==git && (tag==Go || tag==Work) && name !~ billing", "cobra"},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.expr)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) error = %v", tt.expr, err)
+			continue
+		}
+		var names []string
+		for _, p := range FilterByQuery(projects, []*Query{q}) {
+			names = append(names, p.Name)
+		}
+		if got := strings.Join(names, " "); got != tt.want {
+			t.Errorf("%q matched %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "expected a condition"},
+		{"nmae==api", "unknown field 'nmae'"},
+		{"name", "expected ==, !=, ~, !~ or in"},
+		{"name==", "expected a value"},
+		{"(kind==git", "expected ')'"},
+		{"tag in Work", "expected '(' after in"},
+		{"tag in (Work OSS)", "expected ',' or ')'"},
+		{"name=='api", "unterminated quote"},
+		{"enabled==maybe", "enabled must be true or false"},
+		{"kind==git kind==svn", "unexpected 'kind==svn'"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseQuery(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package projector
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+// Fuzzy scoring weights, modelled on fzf: every matched character scores,
+// characters at word starts and runs of consecutive characters score more,
+// and gaps between matched characters cost a little.
+const (
+	scoreMatch        = 16
+	bonusBoundary     = 8
+	bonusConsecutive  = 8
+	bonusFirstChar    = 8
+	penaltyGapStart   = 3
+	penaltyGapExtends = 1
+)
+
+// FuzzyScore scores how well pattern matches text as a case-insensitive
+// subsequence. It returns false when pattern is not a subsequence of text.
+// Higher scores are better; matches at word boundaries (after '-', '_',
+// '.', '/', ' ' or at a camelCase hump) and contiguous matches rank first.
+func FuzzyScore(pattern, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(p) == 0 {
+		return 0, true
+	}
+	if len(lower) != len(t) {
+		// Lowercasing changed the length; fall back to the lowered text
+		t = lower
+	}
+
+	best, found := 0, false
+	for start := range lower {
+		if lower[start] != p[0] {
+			continue
+		}
+		score, ok := scoreFrom(p, t, lower, start)
+		if ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// scoreFrom greedily matches p in lower starting at start and scores the match
+func scoreFrom(p, t, lower []rune, start int) (int, bool) {
+	score := 0
+	prev := -1
+	pi := 0
+	for i := start; i < len(lower) && pi < len(p); i++ {
+		if lower[i] != p[pi] {
+			continue
+		}
+		score += scoreMatch
+		if isBoundary(t, i) {
+			score += bonusBoundary
+			if pi == 0 {
+				score += bonusFirstChar
+			}
+		}
+		if prev >= 0 {
+			if gap := i - prev - 1; gap == 0 {
+				score += bonusConsecutive
+			} else {
+				score -= penaltyGapStart + (gap-1)*penaltyGapExtends
+			}
+		}
+		prev = i
+		pi++
+	}
+	return score, pi == len(p)
+}
+
+// isBoundary reports whether t[i] starts a word
+func isBoundary(t []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := t[i-1], t[i]
+	if strings.ContainsRune("-_./\\ ", prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// MatchOptions control how FindProjectByName and RankMatches match names
+type MatchOptions struct {
+	// FullPath also matches the query against the segments of each
+	// project's path, so "clients/acme" finds the project at
+	// ~/work/clients/acme. The projector command sets it from the
+	// filterOnFullPath option.
+	FullPath bool
+}
+
+// RankMatches returns the projects whose name matches query, best match
+// first. Names containing query are preferred: fuzzy (subsequence) matches
+// are only returned when no name contains it. With opts.FullPath, projects
+// whose path contains query from the start of a segment follow the names
+// containing it.
+func RankMatches(projects []*models.Project, query string, opts MatchOptions) []*models.Project {
+	type scored struct {
+		project  *models.Project
+		score    int
+		pathOnly bool
+	}
+
+	var substring, fuzzy []scored
+	lowerQuery := strings.ToLower(query)
+	for _, p := range projects {
+		score, ok := FuzzyScore(query, p.Name)
+		switch {
+		case ok && strings.Contains(strings.ToLower(p.Name), lowerQuery):
+			substring = append(substring, scored{p, score, false})
+		case opts.FullPath && pathContains(p, query):
+			score, _ = FuzzyScore(query, PathTail(p, 0))
+			substring = append(substring, scored{p, score, true})
+		case ok:
+			fuzzy = append(fuzzy, scored{p, score, false})
+		}
+	}
+
+	matches := substring
+	if len(matches) == 0 {
+		matches = fuzzy
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].pathOnly != matches[j].pathOnly {
+			return !matches[i].pathOnly
+		}
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		// Prefer shorter names, which match a larger share of the name
+		return len(matches[i].project.Name) < len(matches[j].project.Name)
+	})
+
+	ranked := make([]*models.Project, len(matches))
+	for i, m := range matches {
+		ranked[i] = m.project
+	}
+	return ranked
+}
+
+// PathTail returns the last n segments of the project's path, separated by
+// '/', or the whole path with the home directory shortened to ~ when n is
+// 0 or the path has fewer segments
+func PathTail(p *models.Project, n int) string {
+	path := filepath.ToSlash(paths.Collapse(p.RootPath))
+	if n <= 0 {
+		return path
+	}
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if n >= len(segments) {
+		return path
+	}
+	return strings.Join(segments[len(segments)-n:], "/")
+}
+
+// pathContains reports whether query appears in the project's path
+// starting at a segment, case-insensitively
+func pathContains(p *models.Project, query string) bool {
+	path := strings.ToLower(PathTail(p, 0))
+	q := strings.ToLower(strings.Trim(filepath.ToSlash(query), "/"))
+	return q != "" && (strings.HasPrefix(path, q) || strings.Contains(path, "/"+q))
+}
+
+// pathEndsWith reports whether the last segments of the project's path are
+// query, or query is the whole path, case-insensitively
+func pathEndsWith(p *models.Project, query string) bool {
+	path := strings.ToLower(PathTail(p, 0))
+	q := strings.ToLower(filepath.ToSlash(query))
+	return path == q || strings.ToLower(filepath.ToSlash(p.RootPath)) == q ||
+		strings.HasSuffix(path, "/"+strings.Trim(q, "/"))
+}
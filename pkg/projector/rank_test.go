@@ -0,0 +1,63 @@
+package projector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := FuzzyScore("xyz", "projector"); ok {
+		t.Error("expected no match when pattern is not a subsequence")
+	}
+	if _, ok := FuzzyScore("PJR", "projector"); !ok {
+		t.Error("expected a case-insensitive subsequence match")
+	}
+
+	better := [][2]string{
+		// pattern matches the first text better than the second
+		{"api", "api"},
+		{"api", "rapid"},
+		{"mp", "my-project"},
+		{"mp", "company"},
+		{"pm", "projectManager"},
+		{"pm", "prompt"},
+	}
+	for i := 0; i < len(better); i += 2 {
+		pattern := better[i][0]
+		a, _ := FuzzyScore(pattern, better[i][1])
+		b, _ := FuzzyScore(pattern, better[i+1][1])
+		if a <= b {
+			t.Errorf("FuzzyScore(%q): %q scored %d, want more than %q (%d)", pattern, better[i][1], a, better[i+1][1], b)
+		}
+	}
+}
+
+func TestRankMatches(t *testing.T) {
+	projects := []*models.Project{
+		{Name: "legacy-api-v1"},
+		{Name: "rapid"},
+		{Name: "api"},
+		{Name: "web"},
+	}
+
+	names := func(ps []*models.Project) []string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	if got := names(RankMatches(projects, "api", MatchOptions{})); !reflect.DeepEqual(got, []string{"api", "legacy-api-v1", "rapid"}) {
+		t.Errorf("RankMatches(api) = %v", got)
+	}
+	// Fuzzy matches only count when no name contains the query
+	if got := names(RankMatches(projects, "lgv", MatchOptions{})); !reflect.DeepEqual(got, []string{"legacy-api-v1"}) {
+		t.Errorf("RankMatches(lgv) = %v", got)
+	}
+	if got := RankMatches(projects, "zzz", MatchOptions{}); len(got) != 0 {
+		t.Errorf("RankMatches(zzz) = %v, want none", names(got))
+	}
+}
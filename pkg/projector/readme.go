@@ -0,0 +1,58 @@
+package projector
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readmeNames are the README file names looked for, in order of
+// preference; case is ignored
+var readmeNames = []string{"README.md", "README.markdown", "README.rst", "README.txt", "README.adoc", "README.org", "README"}
+
+// FindReadme returns the path of the README file in the root folder of a
+// project, or "" when it has none
+func FindReadme(root string) string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return ""
+	}
+	for _, name := range readmeNames {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(entry.Name(), name) {
+				return filepath.Join(root, entry.Name())
+			}
+		}
+	}
+	return ""
+}
+
+// ReadmePreview returns the README file of the project in root and its
+// first n lines, with trailing blank lines dropped. Both are empty when
+// the project has no README.
+func ReadmePreview(root string, n int) (string, []string, error) {
+	path := FindReadme(root)
+	if path == "" || n <= 0 {
+		return path, nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return path, nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for len(lines) < n && scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), " \t\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return path, nil, err
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return path, lines, nil
+}
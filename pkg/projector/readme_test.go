@@ -0,0 +1,35 @@
+package projector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadmePreview(t *testing.T) {
+	dir := t.TempDir()
+	if path, lines, err := ReadmePreview(dir, 5); path != "" || lines != nil || err != nil {
+		t.Errorf("expected no README, got %q, %v, %v", path, lines, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "README.txt"), []byte("plain\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "readme.md"), []byte("# api\r\n\nThe API server.  \n\n\nMore\n"), 0644)
+	os.Mkdir(filepath.Join(dir, "README"), 0755)
+
+	path, lines, err := ReadmePreview(dir, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "readme.md" {
+		t.Errorf("expected readme.md to be preferred, got %s", path)
+	}
+	want := []string{"# api", "", "The API server."}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("expected %q, got %q", want, lines)
+	}
+
+	if _, lines, _ := ReadmePreview(dir, 0); lines != nil {
+		t.Errorf("expected no lines for n = 0, got %q", lines)
+	}
+}
@@ -0,0 +1,606 @@
+package projector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/config"
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
+	"github.com/ideaspaper/projector/pkg/storage"
+)
+
+// GlobalIgnoreFileName is the gitignore-style file in the config directory
+// that, when present, replaces the per-type ignored folder lists
+const GlobalIgnoreFileName = "ignore"
+
+// ScanOptions selects what a scan covers
+type ScanOptions struct {
+	// Types are the project types to scan; empty means all of them
+	Types []scanner.ScannerType
+
+	// Paths replace the configured base folders when not empty
+	Paths []string
+
+	// Depth overrides the configured maximum depth when positive
+	Depth int
+
+	// RespectGitignore honors .gitignore files even if the config does not
+	RespectGitignore bool
+
+	// OneFileSystem skips other file systems even if the config does not
+	OneFileSystem bool
+
+	// WSLDistro treats the base folders as Linux paths inside this WSL
+	// distro, reached through \\wsl$ (Windows only)
+	WSLDistro string
+
+	// Full reads every directory instead of reusing the state recorded by
+	// the previous scan for unchanged ones
+	Full bool
+
+	// Timeout bounds each scanner run when positive
+	Timeout time.Duration
+
+	// CollectMeta records the remote URL, default branch and last commit
+	// time of each repository found
+	CollectMeta bool
+}
+
+// ScanJob describes a single scanner run for one project type
+type ScanJob struct {
+	Type                 scanner.ScannerType
+	Label                string
+	BaseFolders          []string
+	IgnoredFolders       []string
+	MaxDepth             int
+	IgnoreWithinProjects bool
+	SupportSymlinks      bool
+	RespectGitignore     bool
+	GlobalIgnore         *scanner.Ignore
+	OneFileSystem        bool
+	SkipNetworkMounts    bool
+	SkipSyncConflicts    bool
+	MaxProjects          int
+	MaxDirs              int
+	Timeout              time.Duration
+	CollectMeta          bool
+	AutoTags             map[string][]string
+	LanguageTags         bool
+}
+
+// ScanJobs returns the scanner runs selected by opts with the global
+// ignore file attached. Paths in opts replace the configured base folders,
+// and types without any base folder are skipped.
+func ScanJobs(cfg *config.Config, opts ScanOptions) ([]ScanJob, error) {
+	globalIgnore, err := LoadGlobalIgnore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := BuildScanJobs(cfg, opts)
+	for i := range jobs {
+		jobs[i].GlobalIgnore = globalIgnore
+		if opts.WSLDistro != "" {
+			if jobs[i].BaseFolders, err = WSLBaseFolders(opts.WSLDistro, jobs[i].BaseFolders); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return jobs, nil
+}
+
+// BuildScanJobs returns the scanner runs selected by opts without loading
+// the global ignore file
+func BuildScanJobs(cfg *config.Config, opts ScanOptions) []ScanJob {
+	depthOr := func(configured int) int {
+		if opts.Depth > 0 {
+			return opts.Depth
+		}
+		return configured
+	}
+
+	candidates := []ScanJob{
+		{
+			Type:                 scanner.ScannerGit,
+			Label:                "Git repositories",
+			BaseFolders:          cfg.GitBaseFolders,
+			IgnoredFolders:       cfg.GitIgnoredFolders,
+			MaxDepth:             depthOr(cfg.GitMaxDepth),
+			IgnoreWithinProjects: cfg.IgnoreProjectsWithinProjects,
+			SupportSymlinks:      cfg.SupportSymlinks,
+		},
+		{
+			Type:           scanner.ScannerSVN,
+			Label:          "SVN repositories",
+			BaseFolders:    cfg.SVNBaseFolders,
+			IgnoredFolders: cfg.SVNIgnoredFolders,
+			MaxDepth:       depthOr(cfg.SVNMaxDepth),
+		},
+		{
+			Type:           scanner.ScannerMercurial,
+			Label:          "Mercurial repositories",
+			BaseFolders:    cfg.MercurialBaseFolders,
+			IgnoredFolders: cfg.MercurialIgnoredFolders,
+			MaxDepth:       depthOr(cfg.MercurialMaxDepth),
+		},
+		{
+			Type:           scanner.ScannerVSCode,
+			Label:          "VS Code workspaces",
+			BaseFolders:    cfg.VSCodeBaseFolders,
+			IgnoredFolders: cfg.VSCodeIgnoredFolders,
+			MaxDepth:       depthOr(cfg.VSCodeMaxDepth),
+		},
+		{
+			Type:           scanner.ScannerAny,
+			Label:          "folders",
+			BaseFolders:    cfg.AnyBaseFolders,
+			IgnoredFolders: cfg.AnyIgnoredFolders,
+			MaxDepth:       depthOr(cfg.AnyMaxDepth),
+		},
+	}
+
+	selected := func(t scanner.ScannerType) bool {
+		if len(opts.Types) == 0 {
+			return true
+		}
+		for _, want := range opts.Types {
+			if want == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	var jobs []ScanJob
+	for _, job := range candidates {
+		if !selected(job.Type) {
+			continue
+		}
+		if len(opts.Paths) > 0 {
+			job.BaseFolders = opts.Paths
+		}
+		if len(job.BaseFolders) == 0 {
+			continue
+		}
+		job.RespectGitignore = cfg.RespectGitignore || opts.RespectGitignore
+		job.OneFileSystem = cfg.OneFileSystem || opts.OneFileSystem
+		job.SkipNetworkMounts = cfg.SkipNetworkMounts
+		job.SkipSyncConflicts = cfg.SkipSyncConflicts
+		job.MaxProjects = cfg.MaxProjectsPerScan
+		job.MaxDirs = cfg.MaxDirsVisited
+		job.Timeout = opts.Timeout
+		job.CollectMeta = opts.CollectMeta
+		job.AutoTags = cfg.AutoTags
+		job.LanguageTags = cfg.LanguageTags
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// LoadGlobalIgnore reads the global ignore file, returning nil if there is none
+func LoadGlobalIgnore(cfg *config.Config) (*scanner.Ignore, error) {
+	path := filepath.Join(cfg.GetConfigDir(), GlobalIgnoreFileName)
+	ig, err := scanner.LoadIgnore(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ignore file: %w", err)
+	}
+	return ig, nil
+}
+
+// WSLBaseFolders translates Linux base folders inside a WSL distro to the
+// \\wsl$ paths Windows uses to reach them
+func WSLBaseFolders(distro string, folders []string) ([]string, error) {
+	translated := make([]string, len(folders))
+	for i, folder := range folders {
+		if _, _, ok := paths.ParseWSL(folder); ok {
+			translated[i] = folder
+			continue
+		}
+		if !strings.HasPrefix(folder, "/") {
+			return nil, fmt.Errorf("WSL paths must be absolute Linux paths, got %s", folder)
+		}
+		translated[i] = paths.WSLPath(distro, folder)
+	}
+	return translated, nil
+}
+
+// NewScanner creates a scanner configured for this job
+func (j ScanJob) NewScanner() *scanner.Scanner {
+	s := scanner.NewScanner(j.Type)
+	s.SetBaseFolders(j.BaseFolders)
+	s.SetIgnoredFolders(j.IgnoredFolders)
+	s.SetMaxDepth(j.MaxDepth)
+	s.SetIgnoreWithinProjects(j.IgnoreWithinProjects)
+	s.SetSupportSymlinks(j.SupportSymlinks)
+	s.SetRespectGitignore(j.RespectGitignore)
+	s.SetGlobalIgnore(j.GlobalIgnore)
+	s.SetOneFileSystem(j.OneFileSystem)
+	s.SetSkipNetworkMounts(j.SkipNetworkMounts)
+	s.SetSkipSyncConflicts(j.SkipSyncConflicts)
+	s.SetLimits(j.MaxProjects, j.MaxDirs)
+	return s
+}
+
+// Run scans with the job's settings, bounded by its timeout if set,
+// reusing previous for directories that have not changed, and tags the
+// projects found as set by AutoTags and LanguageTags. It returns the
+// directory state recorded by the scan.
+func (j ScanJob) Run(ctx context.Context, previous scanner.ScanState) ([]*models.Project, scanner.ScanState, error) {
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+		defer cancel()
+	}
+	s := j.NewScanner()
+	s.SetPreviousState(previous)
+	started := time.Now()
+	projects, err := s.Scan(ctx)
+	if err != nil {
+		slog.Warn("scan failed", "type", j.Type, "error", err)
+		return nil, nil, err
+	}
+	slog.Info("scan finished", "type", j.Type, "projects", len(projects), "incremental", previous != nil, "duration", time.Since(started))
+	AutoTag(projects, j.AutoTags)
+	if j.LanguageTags {
+		for _, p := range projects {
+			DetectProjectLanguage(p)
+			TagLanguage(p)
+		}
+	}
+	RecordVolumes(projects)
+	return projects, s.State(), nil
+}
+
+// RecordVolumes records the volume each project's folder is on (see
+// paths.VolumeRoot), so the projects show as offline rather than missing
+// while the volume is not mounted
+func RecordVolumes(projects []*models.Project) {
+	for _, p := range projects {
+		p.Volume = paths.VolumeRoot(p.RootPath)
+	}
+}
+
+// AutoTag adds to each project the tags of every rule whose folder
+// pattern matches its root path, as matched by scanner.MatchFolder, so
+// "~/work/**" tags every project under ~/work. Rules are applied in
+// pattern order and tags a project already has are not repeated.
+func AutoTag(projects []*models.Project, rules map[string][]string) {
+	if len(rules) == 0 {
+		return
+	}
+	patterns := make([]string, 0, len(rules))
+	for pattern := range rules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, p := range projects {
+		for _, pattern := range patterns {
+			if !scanner.MatchFolder(pattern, p.RootPath) {
+				continue
+			}
+			for _, tag := range rules[pattern] {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					p.AddTag(tag)
+				}
+			}
+		}
+	}
+}
+
+// LanguageTagPrefix starts the tags naming the language of a project
+const LanguageTagPrefix = "lang:"
+
+// languageTagNames are the short names used in language tags where the
+// language name is long or not a plain word
+var languageTagNames = map[string]string{
+	"typescript": "ts",
+	"javascript": "js",
+	"c++":        "cpp",
+	"c#":         "csharp",
+}
+
+// LanguageTag returns the tag for a detected language, such as lang:go or
+// lang:ts for typescript, or "" for no language
+func LanguageTag(language string) string {
+	if language == "" {
+		return ""
+	}
+	if short, ok := languageTagNames[language]; ok {
+		language = short
+	}
+	return LanguageTagPrefix + language
+}
+
+// DetectProjectLanguage sets the language of a project without one from
+// the extensions of its source files, see scanner.DetectLanguageByFiles
+func DetectProjectLanguage(p *models.Project) {
+	if p.Language == "" {
+		p.Language = scanner.DetectLanguageByFiles(p.RootPath)
+	}
+}
+
+// TagLanguage gives a project the language tag of its detected language,
+// replacing the tag of a language detected before. It reports whether the
+// tags changed.
+func TagLanguage(p *models.Project) bool {
+	want := LanguageTag(p.Language)
+	changed := false
+	for _, tag := range slices.Clone(p.Tags) {
+		if strings.HasPrefix(tag, LanguageTagPrefix) && tag != want {
+			p.RemoveTag(tag)
+			changed = true
+		}
+	}
+	if want != "" && !p.HasTag(want) {
+		p.AddTag(want)
+		changed = true
+	}
+	return changed
+}
+
+// BaseFolderFor returns the base folder of the job that contains path
+func (j ScanJob) BaseFolderFor(path string) (string, bool) {
+	for _, base := range paths.ExpandAll(j.BaseFolders) {
+		rel, err := filepath.Rel(base, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return base, true
+	}
+	return "", false
+}
+
+// SetCacheBucket stores scanned projects in the cache bucket for the scanner
+// type, keeping projects disabled that were disabled in the replaced bucket
+// along with their version control information, and records the scan time
+func SetCacheBucket(cache *storage.CachedProjects, scannerType scanner.ScannerType, projects []*models.Project) {
+	KeepDisabled(CacheBucket(cache, scannerType), projects)
+	KeepVCSMeta(CacheBucket(cache, scannerType), projects)
+	projects = KeepOffline(CacheBucket(cache, scannerType), projects)
+	cache.MarkScanned(scannerType, time.Now())
+	putCacheBucket(cache, scannerType, projects)
+}
+
+// putCacheBucket replaces the cached projects for the scanner type
+func putCacheBucket(cache *storage.CachedProjects, scannerType scanner.ScannerType, projects []*models.Project) {
+	switch scannerType {
+	case scanner.ScannerGit:
+		cache.Git = projects
+	case scanner.ScannerSVN:
+		cache.SVN = projects
+	case scanner.ScannerMercurial:
+		cache.Mercurial = projects
+	case scanner.ScannerVSCode:
+		cache.VSCode = projects
+	case scanner.ScannerAny:
+		cache.Any = projects
+	}
+}
+
+// CacheBucket returns the cached projects for the scanner type
+func CacheBucket(cache *storage.CachedProjects, scannerType scanner.ScannerType) []*models.Project {
+	if cache == nil {
+		return nil
+	}
+	switch scannerType {
+	case scanner.ScannerGit:
+		return cache.Git
+	case scanner.ScannerSVN:
+		return cache.SVN
+	case scanner.ScannerMercurial:
+		return cache.Mercurial
+	case scanner.ScannerVSCode:
+		return cache.VSCode
+	case scanner.ScannerAny:
+		return cache.Any
+	}
+	return nil
+}
+
+// KeepDisabled disables the scanned projects whose path belongs to a
+// disabled project in previous, so disabled projects survive rescans
+func KeepDisabled(previous, scanned []*models.Project) {
+	disabled := make(map[string]bool)
+	for _, p := range previous {
+		if !p.Enabled {
+			disabled[paths.Key(p.RootPath)] = true
+		}
+	}
+	if len(disabled) == 0 {
+		return
+	}
+	for _, p := range scanned {
+		if disabled[paths.Key(p.RootPath)] {
+			p.Enabled = false
+		}
+	}
+}
+
+// KeepOffline returns scanned with the projects of previous that it misses
+// because their volume is not mounted, so ejecting a drive does not drop
+// its projects on the next scan
+func KeepOffline(previous, scanned []*models.Project) []*models.Project {
+	found := make(map[string]bool, len(scanned))
+	for _, p := range scanned {
+		found[paths.Key(p.RootPath)] = true
+	}
+	MarkOffline(previous)
+	for _, p := range previous {
+		if p.Offline && !found[paths.Key(p.RootPath)] {
+			scanned = append(scanned, p)
+		}
+	}
+	return scanned
+}
+
+// CollectVCSMeta records the remote URL, default branch and last commit
+// time of each project's working copy, as read by scanner.ReadVCSMeta
+func CollectVCSMeta(projects []*models.Project) {
+	for _, p := range projects {
+		meta := scanner.ReadVCSMeta(p.RootPath)
+		p.RemoteURL = meta.RemoteURL
+		p.DefaultBranch = meta.DefaultBranch
+		p.LastCommit = meta.LastCommit
+	}
+}
+
+// KeepVCSMeta copies the version control information recorded by
+// CollectVCSMeta from previous to the scanned projects with the same path,
+// so it survives rescans that do not collect it
+func KeepVCSMeta(previous, scanned []*models.Project) {
+	byPath := make(map[string]*models.Project)
+	for _, p := range previous {
+		if p.RemoteURL != "" || p.DefaultBranch != "" || !p.LastCommit.IsZero() {
+			byPath[paths.Key(p.RootPath)] = p
+		}
+	}
+	if len(byPath) == 0 {
+		return
+	}
+	for _, p := range scanned {
+		if old, ok := byPath[paths.Key(p.RootPath)]; ok && p.RemoteURL == "" && p.DefaultBranch == "" && p.LastCommit.IsZero() {
+			p.RemoteURL, p.DefaultBranch, p.LastCommit = old.RemoteURL, old.DefaultBranch, old.LastCommit
+		}
+	}
+}
+
+// ScanReporter is called after each scanner run of Manager.Scan with the
+// number of projects found or the error that skipped the run
+type ScanReporter func(job ScanJob, found int, err error)
+
+// Scan runs every scan job selected by opts and, when the config caches
+// projects between sessions, saves the cache and the directory state used
+// by the next incremental scan. A failed run is passed to report and
+// skipped; cancelling ctx or hitting the timeout aborts the whole scan
+// without touching the cache. report may be nil.
+func (m *Manager) Scan(ctx context.Context, opts ScanOptions, report ScanReporter) (*storage.CachedProjects, error) {
+	jobs, err := ScanJobs(m.cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return m.RunScanJobs(ctx, jobs, opts.Full, report)
+}
+
+// RunScanJobs is Scan for jobs built by ScanJobs
+func (m *Manager) RunScanJobs(ctx context.Context, jobs []ScanJob, full bool, report ScanReporter) (*storage.CachedProjects, error) {
+	caching := m.cfg.CacheProjectsBetweenSessions
+	cache := &storage.CachedProjects{}
+
+	// The previous cache carries over disabled and offline projects
+	previous, _ := m.store.LoadCache()
+	states := m.scanStates(full)
+
+	for _, job := range jobs {
+		projects, state, err := job.Run(ctx, states[job.Type])
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if report != nil {
+			report(job, len(projects), err)
+		}
+		if errors.Is(err, scanner.ErrScanLimit) {
+			// Keep what was cached rather than a truncated scan
+			putCacheBucket(cache, job.Type, CacheBucket(previous, job.Type))
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		states[job.Type] = state
+		KeepDisabled(CacheBucket(previous, job.Type), projects)
+		if job.CollectMeta {
+			CollectVCSMeta(projects)
+		} else {
+			KeepVCSMeta(CacheBucket(previous, job.Type), projects)
+		}
+		projects = KeepOffline(CacheBucket(previous, job.Type), projects)
+		SetCacheBucket(cache, job.Type, projects)
+	}
+
+	if caching {
+		if err := m.store.SaveCache(cache); err != nil {
+			return nil, fmt.Errorf("failed to save cache: %w", err)
+		}
+		_ = m.store.SaveScanState(states)
+	}
+	return cache, nil
+}
+
+// scanStates returns the directory state recorded by the previous scan.
+// The state is an optimization: without it every directory is read.
+func (m *Manager) scanStates(full bool) map[scanner.ScannerType]scanner.ScanState {
+	if m.cfg.CacheProjectsBetweenSessions && !full {
+		if loaded, err := m.store.LoadScanState(); err == nil {
+			return loaded
+		}
+	}
+	return make(map[scanner.ScannerType]scanner.ScanState)
+}
+
+// ScanPreview is what a dry-run scan found for one project type, compared
+// with the cache
+type ScanPreview struct {
+	Type     scanner.ScannerType `json:"type"`
+	Label    string              `json:"label"`
+	Projects []*models.Project   `json:"projects"`
+	Added    []string            `json:"added"`           // Root paths not in the cache
+	Removed  []string            `json:"removed"`         // Cached root paths no longer found
+	Error    string              `json:"error,omitempty"` // Why the run was skipped
+}
+
+// PreviewScanJobs runs jobs like RunScanJobs but only reports what they
+// find: the cache and the recorded directory state are left unchanged
+func (m *Manager) PreviewScanJobs(ctx context.Context, jobs []ScanJob, full bool) ([]ScanPreview, error) {
+	previous, _ := m.store.LoadCache()
+	states := m.scanStates(full)
+
+	previews := make([]ScanPreview, 0, len(jobs))
+	for _, job := range jobs {
+		preview := ScanPreview{Type: job.Type, Label: job.Label, Projects: []*models.Project{}, Added: []string{}, Removed: []string{}}
+		projects, _, err := job.Run(ctx, states[job.Type])
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if err != nil {
+			preview.Error = err.Error()
+			previews = append(previews, preview)
+			continue
+		}
+		cached := CacheBucket(previous, job.Type)
+		KeepDisabled(cached, projects)
+		projects = KeepOffline(cached, projects)
+		preview.Projects = projects
+
+		found := make(map[string]bool, len(projects))
+		for _, p := range projects {
+			found[paths.Key(p.RootPath)] = true
+		}
+		known := make(map[string]bool, len(cached))
+		for _, p := range cached {
+			known[paths.Key(p.RootPath)] = true
+			if !found[paths.Key(p.RootPath)] {
+				preview.Removed = append(preview.Removed, p.RootPath)
+			}
+		}
+		for _, p := range projects {
+			if !known[paths.Key(p.RootPath)] {
+				preview.Added = append(preview.Added, p.RootPath)
+			}
+		}
+		previews = append(previews, preview)
+	}
+	return previews, nil
+}
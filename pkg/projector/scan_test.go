@@ -0,0 +1,178 @@
+package projector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+func TestScanJob_BaseFolderFor(t *testing.T) {
+	job := ScanJob{BaseFolders: []string{"/code", "/work"}}
+
+	tests := []struct {
+		path     string
+		wantBase string
+		wantOK   bool
+	}{
+		{"/code/app", "/code", true},
+		{"/work/a/b", "/work", true},
+		{"/code", "/code", true},
+		{"/codebase/app", "", false},
+		{"/elsewhere", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			base, ok := job.BaseFolderFor(tt.path)
+			if ok != tt.wantOK || base != tt.wantBase {
+				t.Errorf("baseFolderFor(%q) = (%q, %v), want (%q, %v)", tt.path, base, ok, tt.wantBase, tt.wantOK)
+			}
+		})
+	}
+
+}
+
+func TestWSLBaseFolders(t *testing.T) {
+	got, err := WSLBaseFolders("Ubuntu", []string{"/home/me/code", `\\wsl$\Debian\srv`})
+	if err != nil {
+		t.Fatalf("WSLBaseFolders() error = %v", err)
+	}
+	want := []string{`\\wsl$\Ubuntu\home\me\code`, `\\wsl$\Debian\srv`}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("WSLBaseFolders() = %v, want %v", got, want)
+	}
+
+	if _, err := WSLBaseFolders("Ubuntu", []string{"~/code"}); err == nil {
+		t.Error("expected error for a path that is not an absolute Linux path")
+	}
+}
+
+func TestKeepVCSMeta(t *testing.T) {
+	committed := time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+	previous := []*models.Project{
+		{Name: "a", RootPath: "/a", RemoteURL: "git@example.com:a.git", DefaultBranch: "main", LastCommit: committed},
+	}
+	scanned := []*models.Project{
+		{Name: "a", RootPath: "/a"},
+		{Name: "b", RootPath: "/b"},
+	}
+	KeepVCSMeta(previous, scanned)
+
+	if scanned[0].DefaultBranch != "main" || scanned[0].RemoteURL == "" || !scanned[0].LastCommit.Equal(committed) {
+		t.Errorf("expected /a to keep its metadata, got %+v", scanned[0])
+	}
+	if scanned[1].DefaultBranch != "" || !scanned[1].LastCommit.IsZero() {
+		t.Errorf("expected /b without metadata, got %+v", scanned[1])
+	}
+}
+
+func TestKeepDisabled(t *testing.T) {
+	previous := []*models.Project{
+		{Name: "a", RootPath: "/a", Enabled: false},
+		{Name: "b", RootPath: "/b", Enabled: true},
+	}
+	scanned := []*models.Project{
+		{Name: "a", RootPath: "/a", Enabled: true},
+		{Name: "b", RootPath: "/b", Enabled: true},
+		{Name: "c", RootPath: "/c", Enabled: true},
+	}
+	KeepDisabled(previous, scanned)
+
+	if scanned[0].Enabled {
+		t.Error("expected /a to stay disabled after a rescan")
+	}
+	if !scanned[1].Enabled || !scanned[2].Enabled {
+		t.Error("expected other projects to stay enabled")
+	}
+}
+
+func TestKeepOffline(t *testing.T) {
+	usb := filepath.Join(t.TempDir(), "usb")
+	previous := []*models.Project{
+		{Name: "a", RootPath: "/a", Enabled: true},
+		{Name: "ejected", RootPath: filepath.Join(usb, "ejected"), Volume: usb, Enabled: true},
+	}
+	scanned := []*models.Project{
+		{Name: "b", RootPath: "/b", Enabled: true},
+	}
+	kept := KeepOffline(previous, scanned)
+
+	if len(kept) != 2 || kept[1].Name != "ejected" {
+		t.Fatalf("expected b and the offline project, got %v", kept)
+	}
+	if !kept[1].Offline {
+		t.Error("expected the kept project to be marked offline")
+	}
+}
+
+func TestAutoTag(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	projects := []*models.Project{
+		{Name: "api", RootPath: filepath.Join(home, "work", "acme", "api")},
+		{Name: "lib", RootPath: filepath.Join(home, "oss", "lib"), Tags: []string{"OSS"}},
+		{Name: "client-x", RootPath: "/srv/client-x"},
+		{Name: "notes", RootPath: filepath.Join(home, "notes")},
+	}
+	AutoTag(projects, map[string][]string{
+		"~/work/**": {"Work"},
+		"~/oss/**":  {"OSS", " Public "},
+		"client-*":  {"Client", "Work"},
+	})
+
+	want := [][]string{{"Work"}, {"OSS", "Public"}, {"Client", "Work"}, nil}
+	for i, p := range projects {
+		if strings.Join(p.Tags, ",") != strings.Join(want[i], ",") {
+			t.Errorf("%s tags = %v, want %v", p.Name, p.Tags, want[i])
+		}
+	}
+}
+
+func TestTagLanguage(t *testing.T) {
+	p := &models.Project{Name: "web", Language: "typescript", Tags: []string{"Work", "lang:js"}}
+	if !TagLanguage(p) {
+		t.Error("expected the tags to change")
+	}
+	if strings.Join(p.Tags, ",") != "Work,lang:ts" {
+		t.Errorf("expected lang:js replaced by lang:ts, got %v", p.Tags)
+	}
+	if TagLanguage(p) {
+		t.Error("expected no change the second time")
+	}
+
+	p.Language = ""
+	if !TagLanguage(p) || strings.Join(p.Tags, ",") != "Work" {
+		t.Errorf("expected the language tag removed, got %v", p.Tags)
+	}
+
+	if got := LanguageTag("go"); got != "lang:go" {
+		t.Errorf("LanguageTag(go) = %q", got)
+	}
+}
+
+func TestScanJobRun_LanguageTags(t *testing.T) {
+	base := t.TempDir()
+	for _, dir := range []string{"api", "scripts"} {
+		os.MkdirAll(filepath.Join(base, dir, ".git"), 0755)
+	}
+	os.WriteFile(filepath.Join(base, "api", "go.mod"), []byte("module api\n"), 0644)
+	os.WriteFile(filepath.Join(base, "scripts", "deploy.py"), nil, 0644)
+
+	job := ScanJob{Type: scanner.ScannerGit, BaseFolders: []string{base}, MaxDepth: 2, LanguageTags: true}
+	projects, _, err := job.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := map[string]string{}
+	for _, p := range projects {
+		tags[p.Name] = strings.Join(p.Tags, ",")
+	}
+	if tags["api"] != "lang:go" || tags["scripts"] != "lang:python" {
+		t.Errorf("expected lang:go and lang:python, got %v", tags)
+	}
+}
@@ -0,0 +1,193 @@
+package projector
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// Count is a label with the number of projects it applies to
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ProjectUsage describes how often and how recently a project was opened
+type ProjectUsage struct {
+	Name       string     `json:"name"`
+	Path       string     `json:"path"`
+	Opens      int        `json:"opens"`
+	LastOpened *time.Time `json:"lastOpened,omitempty"`
+}
+
+// ProjectSize is the disk usage of a project folder in bytes
+type ProjectSize struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Stats summarizes a set of projects and their open history
+type Stats struct {
+	Total      int            `json:"total"`
+	Enabled    int            `json:"enabled"`
+	ByKind     []Count        `json:"byKind"`
+	ByTag      []Count        `json:"byTag"`
+	ByLanguage []Count        `json:"byLanguage"`
+	MostOpened []ProjectUsage `json:"mostOpened"`
+	Stale      []ProjectUsage `json:"stale"`
+	Sizes      []ProjectSize  `json:"sizes,omitempty"`
+}
+
+// StatsOptions configures ComputeStats
+type StatsOptions struct {
+	Top        int       // Maximum number of most-opened projects (0 for all)
+	StaleSince time.Time // Projects not opened since then are stale
+	Sizes      bool      // Measure the disk usage of each project
+}
+
+// ComputeStats summarizes projects using the open history. Projects listed
+// under several kinds are counted once per kind, but only once for usage
+// and size. The history keeps a limited number of records, so open counts
+// cover recent use only.
+func ComputeStats(projects []*models.Project, history []models.OpenRecord, opts StatsOptions) *Stats {
+	stats := &Stats{Total: len(projects)}
+
+	kinds := make(map[string]int)
+	tags := make(map[string]int)
+	languages := make(map[string]int)
+	for _, p := range projects {
+		if p.Enabled {
+			stats.Enabled++
+		}
+		kinds[string(p.Kind)]++
+		for _, tag := range p.Tags {
+			tags[tag]++
+		}
+		language := p.Language
+		if language == "" {
+			language = "unknown"
+		}
+		languages[language]++
+	}
+	stats.ByKind = sortedCounts(kinds)
+	stats.ByTag = sortedCounts(tags)
+	stats.ByLanguage = sortedCounts(languages)
+
+	opens := make(map[string]int)
+	for _, r := range history {
+		opens[r.Path]++
+	}
+	lastOpened := LastOpened(history)
+
+	seen := make(map[string]bool)
+	var usage []ProjectUsage
+	for _, p := range projects {
+		if seen[p.RootPath] {
+			continue
+		}
+		seen[p.RootPath] = true
+
+		u := ProjectUsage{Name: p.Name, Path: p.RootPath, Opens: opens[p.RootPath]}
+		if t, ok := lastOpened[p.RootPath]; ok {
+			u.LastOpened = &t
+		}
+		usage = append(usage, u)
+
+		if opts.Sizes {
+			stats.Sizes = append(stats.Sizes, ProjectSize{Name: p.Name, Path: p.RootPath, Bytes: DirSize(p.RootPath)})
+		}
+	}
+
+	for _, u := range usage {
+		if u.Opens > 0 {
+			stats.MostOpened = append(stats.MostOpened, u)
+		}
+		if u.LastOpened == nil || u.LastOpened.Before(opts.StaleSince) {
+			stats.Stale = append(stats.Stale, u)
+		}
+	}
+	sort.SliceStable(stats.MostOpened, func(i, j int) bool {
+		return stats.MostOpened[i].Opens > stats.MostOpened[j].Opens
+	})
+	if opts.Top > 0 && len(stats.MostOpened) > opts.Top {
+		stats.MostOpened = stats.MostOpened[:opts.Top]
+	}
+	sort.SliceStable(stats.Sizes, func(i, j int) bool {
+		return stats.Sizes[i].Bytes > stats.Sizes[j].Bytes
+	})
+
+	return stats
+}
+
+// LastOpened returns the most recent open time of each project path in
+// the open history
+func LastOpened(history []models.OpenRecord) map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, r := range history {
+		if r.OpenedAt.After(last[r.Path]) {
+			last[r.Path] = r.OpenedAt
+		}
+	}
+	return last
+}
+
+// MostUsed returns up to n of projects with the highest open counts,
+// keyed by root path, skipping projects never opened. Projects with the
+// same count keep their order in projects.
+func MostUsed(projects []*models.Project, counts map[string]int, n int) []*models.Project {
+	if n <= 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var used []*models.Project
+	for _, p := range projects {
+		if counts[p.RootPath] > 0 && !seen[p.RootPath] {
+			seen[p.RootPath] = true
+			used = append(used, p)
+		}
+	}
+	sort.SliceStable(used, func(i, j int) bool {
+		return counts[used[i].RootPath] > counts[used[j].RootPath]
+	})
+	if len(used) > n {
+		used = used[:n]
+	}
+	return used
+}
+
+// sortedCounts returns counts ordered by count, then by name
+func sortedCounts(m map[string]int) []Count {
+	counts := make([]Count, 0, len(m))
+	for name, n := range m {
+		counts = append(counts, Count{Name: name, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// DirSize returns the total size of the regular files under root.
+// Entries that cannot be read are skipped and symlinks are not followed.
+func DirSize(root string) int64 {
+	var size int64
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,108 @@
+package projector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestComputeStats(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	api := &models.Project{Name: "api", RootPath: "/p/api", Tags: []string{"Work"}, Enabled: true, Language: "Go", Kind: models.KindFavorite}
+	apiGit := &models.Project{Name: "api", RootPath: "/p/api", Enabled: true, Language: "Go", Kind: models.KindGit}
+	web := &models.Project{Name: "web", RootPath: "/p/web", Tags: []string{"Work", "Frontend"}, Enabled: true, Language: "TypeScript", Kind: models.KindFavorite}
+	old := &models.Project{Name: "old", RootPath: "/p/old", Kind: models.KindGit}
+
+	history := []models.OpenRecord{
+		{Path: "/p/web", OpenedAt: now.AddDate(-1, 0, 0)},
+		{Path: "/p/api", OpenedAt: now.Add(-2 * time.Hour)},
+		{Path: "/p/api", OpenedAt: now.Add(-time.Hour)},
+	}
+
+	stats := ComputeStats([]*models.Project{api, apiGit, web, old}, history, StatsOptions{
+		Top:        1,
+		StaleSince: now.AddDate(0, -6, 0),
+	})
+
+	if stats.Total != 4 || stats.Enabled != 3 {
+		t.Errorf("Total, Enabled = %d, %d; want 4, 3", stats.Total, stats.Enabled)
+	}
+	wantKinds := []Count{{"favorites", 2}, {"git", 2}}
+	if !reflect.DeepEqual(stats.ByKind, wantKinds) {
+		t.Errorf("ByKind = %v, want %v", stats.ByKind, wantKinds)
+	}
+	wantTags := []Count{{"Work", 2}, {"Frontend", 1}}
+	if !reflect.DeepEqual(stats.ByTag, wantTags) {
+		t.Errorf("ByTag = %v, want %v", stats.ByTag, wantTags)
+	}
+	wantLanguages := []Count{{"Go", 2}, {"TypeScript", 1}, {"unknown", 1}}
+	if !reflect.DeepEqual(stats.ByLanguage, wantLanguages) {
+		t.Errorf("ByLanguage = %v, want %v", stats.ByLanguage, wantLanguages)
+	}
+
+	if len(stats.MostOpened) != 1 || stats.MostOpened[0].Name != "api" || stats.MostOpened[0].Opens != 2 {
+		t.Errorf("MostOpened = %+v, want only api with 2 opens", stats.MostOpened)
+	}
+	if !stats.MostOpened[0].LastOpened.Equal(now.Add(-time.Hour)) {
+		t.Errorf("LastOpened = %v, want the latest open", stats.MostOpened[0].LastOpened)
+	}
+
+	var stale []string
+	for _, u := range stats.Stale {
+		stale = append(stale, u.Name)
+	}
+	if !reflect.DeepEqual(stale, []string{"web", "old"}) {
+		t.Errorf("Stale = %v, want [web old]", stale)
+	}
+	if stats.Sizes != nil {
+		t.Error("expected no sizes without the Sizes option")
+	}
+}
+
+func TestMostUsed(t *testing.T) {
+	api := &models.Project{Name: "api", RootPath: "/p/api", Kind: models.KindFavorite}
+	apiGit := &models.Project{Name: "api", RootPath: "/p/api", Kind: models.KindGit}
+	web := &models.Project{Name: "web", RootPath: "/p/web"}
+	cli := &models.Project{Name: "cli", RootPath: "/p/cli"}
+	old := &models.Project{Name: "old", RootPath: "/p/old"}
+	counts := map[string]int{"/p/api": 2, "/p/web": 5, "/p/cli": 2}
+
+	var names []string
+	for _, p := range MostUsed([]*models.Project{api, apiGit, web, cli, old}, counts, 3) {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"web", "api", "cli"}) {
+		t.Errorf("MostUsed = %v, want [web api cli]", names)
+	}
+	if used := MostUsed([]*models.Project{api, web}, counts, 1); len(used) != 1 || used[0] != web {
+		t.Errorf("expected only web with n = 1, got %v", used)
+	}
+	if used := MostUsed([]*models.Project{api, web}, counts, 0); used != nil {
+		t.Errorf("expected nothing with n = 0, got %v", used)
+	}
+}
+
+func TestDirSize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 50), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := DirSize(dir); got != 150 {
+		t.Errorf("DirSize = %d, want 150", got)
+	}
+	if got := DirSize(filepath.Join(dir, "missing")); got != 0 {
+		t.Errorf("DirSize of missing folder = %d, want 0", got)
+	}
+}
@@ -0,0 +1,60 @@
+package projector
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/cloudsync"
+	"github.com/ideaspaper/projector/pkg/config"
+)
+
+// SyncTokenEnv is the environment variable holding the token for the
+// sync provider
+const SyncTokenEnv = "PROJECTOR_SYNC_TOKEN"
+
+// SyncProvider returns the sync provider set up in the sync section of
+// the config
+func SyncProvider(cfg *config.Config) (cloudsync.Provider, error) {
+	token, err := SyncToken(cfg)
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.Sync.Provider {
+	case "gist":
+		return cloudsync.NewGist(cloudsync.GitHubAPI, cfg.Sync.GistID, token), nil
+	case "http":
+		if cfg.Sync.URL == "" {
+			return nil, fmt.Errorf("sync.url must be set for the http sync provider")
+		}
+		return cloudsync.NewHTTP(cfg.Sync.URL, cfg.Sync.Username, token), nil
+	case "":
+		return nil, fmt.Errorf("sync is not set up (set sync.provider to gist or http in the config)")
+	default:
+		return nil, fmt.Errorf("unknown sync provider '%s' (use gist or http)", cfg.Sync.Provider)
+	}
+}
+
+// SyncToken returns the token for the sync provider from the environment
+// or the output of the configured token command (e.g. a keyring lookup).
+// Gists also fall back to GITHUB_TOKEN and GH_TOKEN.
+func SyncToken(cfg *config.Config) (string, error) {
+	if token := os.Getenv(SyncTokenEnv); token != "" {
+		return token, nil
+	}
+	if fields := strings.Fields(cfg.Sync.TokenCommand); len(fields) > 0 {
+		out, err := exec.Command(fields[0], fields[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to run sync.tokenCommand: %w", err)
+		}
+		return strings.TrimRight(string(out), "\r\n"), nil
+	}
+	if cfg.Sync.Provider == "gist" {
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+			return token, nil
+		}
+		return os.Getenv("GH_TOKEN"), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,57 @@
+package projector
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/cloudsync"
+	"github.com/ideaspaper/projector/pkg/config"
+)
+
+func TestSyncProvider(t *testing.T) {
+	t.Setenv(SyncTokenEnv, "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "gh")
+	cfg := config.DefaultConfig()
+
+	if _, err := SyncProvider(cfg); err == nil {
+		t.Error("expected an error while sync is not set up")
+	}
+
+	cfg.Sync = config.SyncConfig{Provider: "gist", GistID: "abc"}
+	p, err := SyncProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, ok := p.(*cloudsync.Gist); !ok || g.ID != "abc" || g.Token != "gh" {
+		t.Errorf("expected gist abc with the GH_TOKEN, got %+v", p)
+	}
+
+	cfg.Sync = config.SyncConfig{Provider: "http"}
+	if _, err := SyncProvider(cfg); err == nil {
+		t.Error("expected an error for the http provider without a URL")
+	}
+	cfg.Sync.URL = "https://dav.example.com/f.json"
+	cfg.Sync.Username = "me"
+	t.Setenv(SyncTokenEnv, "pw")
+	if h, err := SyncProvider(cfg); err != nil || h.(*cloudsync.HTTP).Token != "pw" {
+		t.Errorf("expected the http provider with the token from %s, got %+v, %v", SyncTokenEnv, h, err)
+	}
+
+	cfg.Sync.Provider = "dropbox"
+	if _, err := SyncProvider(cfg); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestSyncToken_Command(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("no 'echo' executable to stand in for a token command")
+	}
+	t.Setenv(SyncTokenEnv, "")
+	cfg := config.DefaultConfig()
+	cfg.Sync = config.SyncConfig{Provider: "http", TokenCommand: "echo from-command"}
+	if token, err := SyncToken(cfg); err != nil || token != "from-command" {
+		t.Errorf("SyncToken() = %q, %v; want from-command", token, err)
+	}
+}
@@ -0,0 +1,52 @@
+package projector
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// ExpandTags returns the unique tags with the parents of hierarchical
+// tags added, sorted level by level so that the tags below a tag follow
+// it directly
+func ExpandTags(tags []string) []string {
+	set := make(map[string]struct{})
+	for _, tag := range tags {
+		parts := strings.Split(tag, models.TagSeparator)
+		for i := range parts {
+			if parent := strings.Join(parts[:i+1], models.TagSeparator); parent != "" {
+				set[parent] = struct{}{}
+			}
+		}
+	}
+
+	expanded := make([]string, 0, len(set))
+	for tag := range set {
+		expanded = append(expanded, tag)
+	}
+	slices.SortFunc(expanded, func(a, b string) int {
+		return slices.Compare(strings.Split(a, models.TagSeparator), strings.Split(b, models.TagSeparator))
+	})
+	return expanded
+}
+
+// CompleteTag returns the tags at the level of partial that start with
+// it, ignoring case. Tags with tags below them are also offered with a
+// trailing separator, to complete the next level from.
+func CompleteTag(tags []string, partial string) []string {
+	level := strings.Count(partial, models.TagSeparator)
+	expanded := ExpandTags(tags)
+	var matches []string
+	for i, tag := range expanded {
+		if strings.Count(tag, models.TagSeparator) != level ||
+			!strings.HasPrefix(strings.ToLower(tag), strings.ToLower(partial)) {
+			continue
+		}
+		matches = append(matches, tag)
+		if i+1 < len(expanded) && strings.HasPrefix(expanded[i+1], tag+models.TagSeparator) {
+			matches = append(matches, tag+models.TagSeparator)
+		}
+	}
+	return matches
+}
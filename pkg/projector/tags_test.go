@@ -0,0 +1,37 @@
+package projector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTags(t *testing.T) {
+	got := ExpandTags([]string{"Work/ClientA/Backend", "Work-Old", "Go", "Work/ClientB", "Work", "Go"})
+	want := []string{"Go", "Work", "Work/ClientA", "Work/ClientA/Backend", "Work/ClientB", "Work-Old"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandTags() = %q, want %q", got, want)
+	}
+}
+
+func TestCompleteTag(t *testing.T) {
+	tags := []string{"Work/ClientA/Backend", "Work/ClientB", "Workshop", "Go"}
+
+	tests := []struct {
+		partial string
+		want    []string
+	}{
+		{"", []string{"Go", "Work", "Work/", "Workshop"}},
+		{"wo", []string{"Work", "Work/", "Workshop"}},
+		{"Work/", []string{"Work/ClientA", "Work/ClientA/", "Work/ClientB"}},
+		{"Work/clientb", []string{"Work/ClientB"}},
+		{"Work/ClientA/", []string{"Work/ClientA/Backend"}},
+		{"Other/", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.partial, func(t *testing.T) {
+			if got := CompleteTag(tags, tt.partial); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompleteTag(%q) = %q, want %q", tt.partial, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// maxActivityEntries bounds how many entries LastActivity looks at in a
+// folder, so huge projects do not stall sorting
+const maxActivityEntries = 20000
+
+// activitySkipDirs are dependency and build output folders whose files do
+// not reflect work on the project
+var activitySkipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	"out":          true,
+	"__pycache__":  true,
+}
+
+// LastActivity returns when the project in folder was last worked on: the
+// commit time of HEAD for Git repositories (when git is installed), and
+// otherwise the newest modification time of the files inside it. Hidden,
+// dependency and build folders are skipped, and at most 20000 entries are
+// looked at. It returns the zero time when nothing can be read.
+func LastActivity(folder string) time.Time {
+	if DetectKind(folder) == models.KindGit {
+		if t, ok := headCommitTime(folder); ok {
+			return t
+		}
+	}
+	return newestModTime(folder)
+}
+
+// headCommitTime returns the committer time of HEAD in a Git repository
+func headCommitTime(folder string) (time.Time, bool) {
+	out, err := exec.Command("git", "-C", folder, "log", "-1", "--format=%ct", "HEAD").Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
+
+// newestModTime returns the newest modification time of the files in folder
+func newestModTime(folder string) time.Time {
+	var newest time.Time
+	seen := 0
+	_ = filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if seen++; seen > maxActivityEntries {
+			return fs.SkipAll
+		}
+		if d.IsDir() {
+			if path != folder && (strings.HasPrefix(d.Name(), ".") || activitySkipDirs[d.Name()]) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	return newest
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLastActivity_Files(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	newest := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	files := map[string]time.Time{
+		"README.md":                 old,
+		"src/main.go":               recent,
+		"node_modules/pkg/index.js": newest,
+		".cache/data":               newest,
+	}
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x"), 0644)
+		os.Chtimes(path, mtime, mtime)
+	}
+
+	if got := LastActivity(dir); !got.Equal(recent) {
+		t.Errorf("LastActivity() = %v, want %v (dependency and hidden folders skipped)", got, recent)
+	}
+	if got := LastActivity(filepath.Join(dir, "missing")); !got.IsZero() {
+		t.Errorf("LastActivity() of a missing folder = %v, want zero", got)
+	}
+}
+
+func TestLastActivity_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	committed := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com",
+			"GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com",
+			"GIT_COMMITTER_DATE="+committed.Format(time.RFC3339),
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+
+	// The commit time wins over newer files in the working tree
+	if got := LastActivity(dir); !got.Equal(committed) {
+		t.Errorf("LastActivity() = %v, want the commit time %v", got, committed)
+	}
+}
@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileNames are the per-directory ignore files read when
+// respecting gitignore during a scan
+var IgnoreFileNames = []string{".gitignore", ".ignore"}
+
+// Ignore is a set of gitignore-style patterns relative to a root directory
+type Ignore struct {
+	rules []ignoreRule
+}
+
+// ignoreRule is a single parsed pattern
+type ignoreRule struct {
+	segments []string // pattern split on "/"
+	negate   bool     // pattern started with "!"
+	dirOnly  bool     // pattern ended with "/"
+	anchored bool     // pattern contained a "/" other than a trailing one
+}
+
+// ParseIgnore parses gitignore syntax: blank lines and lines starting
+// with # are skipped, a leading ! re-includes, a trailing / matches only
+// directories, a leading or inner / anchors the pattern to the root, and
+// * ? [...] and ** work as in git.
+func ParseIgnore(r io.Reader) (*Ignore, error) {
+	ig := &Ignore{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // escaped leading # or !
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		rule.segments = strings.Split(line, "/")
+		ig.rules = append(ig.rules, rule)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return ig, nil
+}
+
+// LoadIgnore reads an ignore file. A missing file returns an error
+// for which os.IsNotExist is true.
+func LoadIgnore(path string) (*Ignore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ig, err := ParseIgnore(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file %s: %w", path, err)
+	}
+	return ig, nil
+}
+
+// Match reports whether rel, a slash- or OS-separated path relative to
+// the ignore root, is ignored. The last matching pattern wins.
+func (ig *Ignore) Match(rel string, isDir bool) bool {
+	ignored, _ := ig.match(rel, isDir)
+	return ignored
+}
+
+// match returns the result of the last matching rule and whether any rule matched
+func (ig *Ignore) match(rel string, isDir bool) (ignored, matched bool) {
+	if ig == nil {
+		return false, false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, rule := range ig.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.matches(parts) {
+			ignored, matched = !rule.negate, true
+		}
+	}
+	return ignored, matched
+}
+
+// matches checks the rule against path segments
+func (r ignoreRule) matches(parts []string) bool {
+	if r.anchored {
+		return matchSegments(r.segments, parts)
+	}
+	// Unanchored patterns match the last path element at any depth
+	return matchSegments(r.segments, parts[len(parts)-1:])
+}
+
+// matchSegments matches pattern segments against path segments, where a
+// "**" segment matches zero or more path segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// scopedIgnore is an ignore file found during the walk, with the directory it applies to
+type scopedIgnore struct {
+	dir    string
+	ignore *Ignore
+}
+
+// IgnoreStack holds the ignore files in effect for a directory, outermost
+// first. The zero value is an empty stack.
+type IgnoreStack []scopedIgnore
+
+// Push returns a new stack with the ignore files in dir added
+func (st IgnoreStack) Push(dir string, onError func(path string, err error)) IgnoreStack {
+	return st.PushFiles(dir, IgnoreFileNames, onError)
+}
+
+// PushFiles is Push for a directory known to hold only the ignore files
+// in names, in the order of IgnoreFileNames, which saves looking for the
+// others
+func (st IgnoreStack) PushFiles(dir string, names []string, onError func(path string, err error)) IgnoreStack {
+	next := st
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		ig, err := LoadIgnore(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				onError(path, err)
+			}
+			continue
+		}
+		// Copy so sibling directories don't share appended entries
+		next = append(next[:len(next):len(next)], scopedIgnore{dir: dir, ignore: ig})
+	}
+	return next
+}
+
+// Ignored reports whether path is ignored; inner files override outer ones
+func (st IgnoreStack) Ignored(path string, isDir bool) bool {
+	result := false
+	for _, scoped := range st {
+		rel, err := filepath.Rel(scoped.dir, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if ignored, matched := scoped.ignore.match(rel, isDir); matched {
+			result = ignored
+		}
+	}
+	return result
+}
+
+// ignoreFilesIn returns the ignore files among entries, in the order of
+// IgnoreFileNames
+func ignoreFilesIn(entries []fs.DirEntry) []string {
+	var names []string
+	for _, name := range IgnoreFileNames {
+		for _, entry := range entries {
+			if entry.Name() == name && !entry.IsDir() {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
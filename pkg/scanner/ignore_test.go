@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnore_Match(t *testing.T) {
+	ig, err := ParseIgnore(strings.NewReader(`
+# comment
+node_modules
+build/
+/dist
+docs/*.tmp
+**/cache/**
+*.log
+!keep.log
+`))
+	if err != nil {
+		t.Fatalf("ParseIgnore failed: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"node_modules", true, true},
+		{"a/b/node_modules", true, true},
+		{"build", true, true},
+		{"build", false, false}, // trailing slash matches directories only
+		{"dist", true, true},
+		{"sub/dist", true, false}, // leading slash anchors to the root
+		{"docs/a.tmp", false, true},
+		{"other/docs/a.tmp", false, false},
+		{"x/cache/y", true, true},
+		{"debug.log", false, true},
+		{"keep.log", false, false}, // negated by a later pattern
+		{"src", true, false},
+	}
+
+	for _, tt := range tests {
+		if got := ig.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestLoadIgnore_Missing(t *testing.T) {
+	_, err := LoadIgnore(filepath.Join(t.TempDir(), "ignore"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestScanner_RespectGitignore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.MkdirAll(filepath.Join(tmpDir, "kept", ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "generated", "repo", ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "nested", "skip", ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "nested", "other", ".git"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("generated/\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "nested", ".ignore"), []byte("skip\n"), 0644)
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{tmpDir})
+
+	projects, _ := s.Scan(context.Background())
+	if len(projects) != 4 {
+		t.Errorf("expected 4 projects without gitignore support, got %d", len(projects))
+	}
+
+	s.SetRespectGitignore(true)
+	projects, _ = s.Scan(context.Background())
+	names := make(map[string]bool)
+	for _, p := range projects {
+		names[p.Name] = true
+	}
+	if len(projects) != 2 || !names["kept"] || !names["other"] {
+		t.Errorf("expected kept and other, got %v", names)
+	}
+}
+
+func TestScanner_GlobalIgnoreReplacesIgnoredFolders(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.MkdirAll(filepath.Join(tmpDir, "vendor", "lib", ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "archive", "old", ".git"), 0755)
+
+	ig, _ := ParseIgnore(strings.NewReader("archive/\n"))
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{tmpDir})
+	s.SetIgnoredFolders([]string{"vendor"})
+	s.SetGlobalIgnore(ig)
+
+	projects, _ := s.Scan(context.Background())
+	if len(projects) != 1 || projects[0].Name != "lib" {
+		t.Errorf("expected only the vendored repo, got %v", projects)
+	}
+}
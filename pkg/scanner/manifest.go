@@ -0,0 +1,135 @@
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest associates a file found in a project root with a language
+type Manifest struct {
+	// File is the file name, or a suffix when it starts with "*" (e.g. "*.csproj")
+	File     string
+	Language string
+}
+
+// DefaultManifests lists the manifests recognized by DetectLanguage, in priority order.
+// More specific manifests come first, e.g. tsconfig.json before package.json.
+var DefaultManifests = []Manifest{
+	{File: "go.mod", Language: "go"},
+	{File: "Cargo.toml", Language: "rust"},
+	{File: "tsconfig.json", Language: "typescript"},
+	{File: "package.json", Language: "javascript"},
+	{File: "pyproject.toml", Language: "python"},
+	{File: "setup.py", Language: "python"},
+	{File: "requirements.txt", Language: "python"},
+	{File: "pom.xml", Language: "java"},
+	{File: "build.gradle", Language: "java"},
+	{File: "build.gradle.kts", Language: "kotlin"},
+	{File: "composer.json", Language: "php"},
+	{File: "Gemfile", Language: "ruby"},
+	{File: "mix.exs", Language: "elixir"},
+	{File: "pubspec.yaml", Language: "dart"},
+	{File: "Package.swift", Language: "swift"},
+	{File: "CMakeLists.txt", Language: "c++"},
+	{File: "*.csproj", Language: "c#"},
+	{File: "*.sln", Language: "c#"},
+}
+
+// DetectLanguage returns the language indicated by the first matching
+// manifest in folder, or an empty string if none is found
+func DetectLanguage(folder string) string {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return ""
+	}
+	return detectLanguageIn(entries)
+}
+
+// detectLanguageIn is DetectLanguage for a folder whose entries were
+// already read
+func detectLanguageIn(entries []fs.DirEntry) string {
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names[entry.Name()] = true
+		}
+	}
+
+	for _, m := range DefaultManifests {
+		if suffix, ok := strings.CutPrefix(m.File, "*"); ok {
+			for name := range names {
+				if strings.HasSuffix(name, suffix) {
+					return m.Language
+				}
+			}
+			continue
+		}
+		if names[m.File] {
+			return m.Language
+		}
+	}
+	return ""
+}
+
+// sourceExtensions maps the extensions of source files to their language,
+// for projects without a manifest
+var sourceExtensions = map[string]string{
+	".go": "go", ".rs": "rust", ".ts": "typescript", ".tsx": "typescript",
+	".js": "javascript", ".jsx": "javascript", ".mjs": "javascript", ".cjs": "javascript",
+	".py": "python", ".java": "java", ".kt": "kotlin", ".kts": "kotlin",
+	".php": "php", ".rb": "ruby", ".ex": "elixir", ".exs": "elixir",
+	".dart": "dart", ".swift": "swift", ".c": "c", ".h": "c",
+	".cc": "c++", ".cpp": "c++", ".cxx": "c++", ".hpp": "c++", ".cs": "c#",
+	".scala": "scala", ".hs": "haskell", ".lua": "lua", ".zig": "zig",
+	".sh": "shell", ".bash": "shell", ".ps1": "powershell",
+}
+
+// dependencyDirs hold code a project depends on rather than its own, so
+// their files are not counted
+var dependencyDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "target": true, "build": true,
+	"dist": true, "venv": true, "__pycache__": true, "Pods": true,
+}
+
+// maxLanguageFiles bounds the files DetectLanguageByFiles looks at, so a
+// huge folder without a manifest does not slow a scan down
+const maxLanguageFiles = 2000
+
+// DetectLanguageByFiles returns the language most source files in folder
+// are written in, judged by their extension, or an empty string if there
+// are none. Hidden and dependency folders are skipped, and at most
+// maxLanguageFiles files are looked at.
+func DetectLanguageByFiles(folder string) string {
+	counts := make(map[string]int)
+	files := 0
+	_ = filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != folder && (strings.HasPrefix(name, ".") || dependencyDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if files++; files > maxLanguageFiles {
+			return filepath.SkipAll
+		}
+		if language, ok := sourceExtensions[strings.ToLower(filepath.Ext(name))]; ok {
+			counts[language]++
+		}
+		return nil
+	})
+
+	// The most files wins; ties go to the first language by name
+	best := ""
+	for language, n := range counts {
+		if n > counts[best] || (n == counts[best] && language < best) {
+			best = language
+		}
+	}
+	return best
+}
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"go module", []string{"go.mod", "main.go"}, "go"},
+		{"rust crate", []string{"Cargo.toml"}, "rust"},
+		{"typescript wins over javascript", []string{"package.json", "tsconfig.json"}, "typescript"},
+		{"javascript", []string{"package.json"}, "javascript"},
+		{"python", []string{"pyproject.toml"}, "python"},
+		{"suffix manifest", []string{"App.csproj"}, "c#"},
+		{"no manifest", []string{"README.md"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				os.WriteFile(filepath.Join(dir, f), []byte{}, 0644)
+			}
+			if got := DetectLanguage(dir); got != tt.want {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguageByFiles(t *testing.T) {
+	dir := t.TempDir()
+	if got := DetectLanguageByFiles(dir); got != "" {
+		t.Errorf("expected no language for an empty folder, got %q", got)
+	}
+
+	files := []string{
+		"main.py", "lib/util.py", "lib/io.py", "web/app.js",
+		"node_modules/a/index.js", "node_modules/b/index.js", "node_modules/c/index.js",
+		".cache/x.js", ".cache/y.js", "README.md",
+	}
+	for _, f := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), 0755)
+		os.WriteFile(filepath.Join(dir, f), nil, 0644)
+	}
+	if got := DetectLanguageByFiles(dir); got != "python" {
+		t.Errorf("DetectLanguageByFiles() = %q, want python", got)
+	}
+}
+
+func TestDetectLanguage_IgnoresDirectories(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "go.mod"), 0755)
+
+	if got := DetectLanguage(dir); got != "" {
+		t.Errorf("expected no language for directory named go.mod, got %q", got)
+	}
+}
+
+func TestScanner_SetsLanguage(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	os.WriteFile(filepath.Join(repo, "Cargo.toml"), []byte{}, 0644)
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{tmpDir})
+
+	projects, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Language != "rust" {
+		t.Errorf("expected one rust project, got %+v", projects)
+	}
+}
@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// VCSMeta is version control information about a working copy
+type VCSMeta struct {
+	RemoteURL     string
+	DefaultBranch string
+	LastCommit    time.Time
+}
+
+// ReadVCSMeta returns the remote URL, default branch and last commit time
+// of the Git, Mercurial or SVN working copy in folder. Git remotes and
+// branches and Mercurial remotes are read from the repository; commit
+// times and SVN information come from the git, hg and svn commands and are
+// left empty when those are not installed.
+func ReadVCSMeta(folder string) VCSMeta {
+	var meta VCSMeta
+	switch DetectKind(folder) {
+	case models.KindGit:
+		meta.RemoteURL = RemoteURL(folder)
+		meta.DefaultBranch = gitDefaultBranch(folder)
+		meta.LastCommit, _ = headCommitTime(folder)
+	case models.KindMercurial:
+		meta.RemoteURL = RemoteURL(folder)
+		// Mercurial's main line of development is always named "default"
+		meta.DefaultBranch = "default"
+		meta.LastCommit = hgCommitTime(folder)
+	case models.KindSVN:
+		meta.RemoteURL = svnInfo(folder, "url")
+		if t, err := time.Parse(time.RFC3339Nano, svnInfo(folder, "last-changed-date")); err == nil {
+			meta.LastCommit = t
+		}
+	}
+	return meta
+}
+
+// gitDefaultBranch returns the branch origin/HEAD points to, which is the
+// default branch of the remote, falling back to the checked out branch
+func gitDefaultBranch(folder string) string {
+	dir := gitDir(folder)
+	if dir == "" {
+		return ""
+	}
+	for _, ref := range []struct{ file, prefix string }{
+		{filepath.Join(dir, "refs", "remotes", "origin", "HEAD"), "refs/remotes/origin/"},
+		{filepath.Join(dir, "HEAD"), "refs/heads/"},
+	} {
+		data, err := os.ReadFile(ref.file)
+		if err != nil {
+			continue
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref:")
+		if !ok {
+			continue
+		}
+		if branch, ok := strings.CutPrefix(strings.TrimSpace(target), ref.prefix); ok {
+			return branch
+		}
+	}
+	return ""
+}
+
+// CurrentBranch returns the branch checked out in the Git working copy in
+// folder, or an empty string when it is not a Git repository or HEAD is
+// detached
+func CurrentBranch(folder string) string {
+	dir := gitDir(folder)
+	if dir == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref:")
+	if !ok {
+		return ""
+	}
+	branch, _ := strings.CutPrefix(strings.TrimSpace(target), "refs/heads/")
+	return branch
+}
+
+// TrackedFolders returns which of the folders, relative to the Git
+// working copy in folder, hold files tracked by Git. It returns nil when
+// folder is not a Git repository or git cannot be run.
+func TrackedFolders(folder string, folders []string) map[string]bool {
+	if gitDir(folder) == "" || len(folders) == 0 {
+		return nil
+	}
+	args := append([]string{"--literal-pathspecs", "-C", folder, "ls-files", "-z", "--"}, folders...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil
+	}
+	tracked := make(map[string]bool)
+	for _, file := range strings.Split(string(out), "\x00") {
+		for _, f := range folders {
+			if strings.HasPrefix(file, filepath.ToSlash(f)+"/") {
+				tracked[f] = true
+			}
+		}
+	}
+	return tracked
+}
+
+// hgCommitTime returns the commit time of the working copy's parent
+// revision in a Mercurial repository
+func hgCommitTime(folder string) time.Time {
+	out, err := exec.Command("hg", "--cwd", folder, "log", "-r", ".", "--template", "{date|hgdate}").Output()
+	if err != nil {
+		return time.Time{}
+	}
+	// hgdate is "<unix seconds> <timezone offset>"
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+	secs, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
+// svnInfo returns one item of 'svn info' for an SVN working copy
+func svnInfo(folder, item string) string {
+	out, err := exec.Command("svn", "info", "--show-item", item, folder).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadVCSMeta(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := filepath.Join(dir, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git", "refs", "remotes", "origin"), 0755)
+	os.WriteFile(filepath.Join(repo, ".git", "config"), []byte("[remote \"origin\"]\n\turl = git@github.com:acme/api.git\n"), 0644)
+	os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/feature\n"), 0644)
+	os.WriteFile(filepath.Join(repo, ".git", "refs", "remotes", "origin", "HEAD"), []byte("ref: refs/remotes/origin/main\n"), 0644)
+
+	// Without origin/HEAD the checked out branch is used
+	local := filepath.Join(dir, "local")
+	os.MkdirAll(filepath.Join(local, ".git"), 0755)
+	os.WriteFile(filepath.Join(local, ".git", "HEAD"), []byte("ref: refs/heads/trunk\n"), 0644)
+
+	hg := filepath.Join(dir, "hg")
+	os.MkdirAll(filepath.Join(hg, ".hg"), 0755)
+	os.WriteFile(filepath.Join(hg, ".hg", "hgrc"), []byte("[paths]\ndefault = https://hg.example.com/api\n"), 0644)
+
+	tests := []struct {
+		folder         string
+		remote, branch string
+	}{
+		{repo, "git@github.com:acme/api.git", "main"},
+		{local, "", "trunk"},
+		{hg, "https://hg.example.com/api", "default"},
+		{dir, "", ""},
+	}
+	for _, tt := range tests {
+		meta := ReadVCSMeta(tt.folder)
+		if meta.RemoteURL != tt.remote || meta.DefaultBranch != tt.branch {
+			t.Errorf("ReadVCSMeta(%s) = %+v, want remote %q and branch %q", filepath.Base(tt.folder), meta, tt.remote, tt.branch)
+		}
+	}
+}
+
+func TestCurrentBranch(t *testing.T) {
+	dir := t.TempDir()
+	repo := filepath.Join(dir, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/feature/login\n"), 0644)
+
+	detached := filepath.Join(dir, "detached")
+	os.MkdirAll(filepath.Join(detached, ".git"), 0755)
+	os.WriteFile(filepath.Join(detached, ".git", "HEAD"), []byte("4b825dc642cb6eb9a060e54bf8d69288fbee4904\n"), 0644)
+
+	tests := map[string]string{repo: "feature/login", detached: "", dir: ""}
+	for folder, want := range tests {
+		if got := CurrentBranch(folder); got != want {
+			t.Errorf("CurrentBranch(%s) = %q, want %q", filepath.Base(folder), got, want)
+		}
+	}
+}
+
+func TestTrackedFolders(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if TrackedFolders(dir, []string{"build"}) != nil {
+		t.Error("expected nil outside a Git repository")
+	}
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	os.MkdirAll(filepath.Join(dir, "build", "docker"), 0755)
+	os.MkdirAll(filepath.Join(dir, "web", "node_modules", "x"), 0755)
+	os.WriteFile(filepath.Join(dir, "build", "docker", "Dockerfile"), []byte("FROM scratch\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "node_modules", "x", "index.js"), []byte("\n"), 0644)
+	run("add", "build")
+
+	got := TrackedFolders(dir, []string{"build", filepath.Join("web", "node_modules")})
+	if want := map[string]bool{"build": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TrackedFolders() = %v, want %v", got, want)
+	}
+}
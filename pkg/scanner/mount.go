@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// syncConflictMarkers appear in the names of the copies Dropbox makes of
+// folders that were changed on two machines at once
+var syncConflictMarkers = []string{"conflicted copy", "case conflict"}
+
+// hostname returns the lowercase name of this machine, which OneDrive
+// appends to the folders it could not merge
+var hostname = sync.OnceValue(func() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(name)
+})
+
+// IsSyncConflict reports whether a folder name is a conflict copy made by
+// a cloud sync client: "api (conflicted copy 2024-05-01)" or "api (Case
+// Conflict)" from Dropbox, or "api-<this machine>" from OneDrive
+func IsSyncConflict(name string) bool {
+	lower := strings.ToLower(name)
+	for _, marker := range syncConflictMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	host := hostname()
+	return host != "" && strings.HasSuffix(lower, "-"+host)
+}
@@ -0,0 +1,24 @@
+//go:build darwin || freebsd
+
+package scanner
+
+import "golang.org/x/sys/unix"
+
+// networkFileSystems are the names of network file systems as reported
+// by statfs
+var networkFileSystems = map[string]bool{
+	"nfs":    true,
+	"smbfs":  true,
+	"afpfs":  true,
+	"webdav": true,
+	"cifs":   true,
+}
+
+// isNetworkMount reports whether path is on a network file system
+func isNetworkMount(path string) bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return false
+	}
+	return networkFileSystems[unix.ByteSliceToString(st.Fstypename[:])]
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package scanner
+
+import "golang.org/x/sys/unix"
+
+// networkFileSystems are the statfs magic numbers of network file systems.
+// 9p is left out since WSL mounts the Windows drives with it.
+var networkFileSystems = map[int64]bool{
+	unix.NFS_SUPER_MAGIC:  true,
+	unix.SMB_SUPER_MAGIC:  true,
+	unix.SMB2_SUPER_MAGIC: true,
+	unix.CIFS_SUPER_MAGIC: true,
+	unix.AFS_SUPER_MAGIC:  true,
+	unix.AFS_FS_MAGIC:     true,
+	unix.CODA_SUPER_MAGIC: true,
+	unix.NCP_SUPER_MAGIC:  true,
+	unix.CEPH_SUPER_MAGIC: true,
+}
+
+// isNetworkMount reports whether path is on a network file system
+func isNetworkMount(path string) bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return false
+	}
+	return networkFileSystems[int64(st.Type)]
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package scanner
+
+// isNetworkMount reports whether path is on a network file system, which
+// is not detected on this platform
+func isNetworkMount(path string) bool {
+	return false
+}
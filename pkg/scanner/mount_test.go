@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestIsSyncConflict(t *testing.T) {
+	tests := map[string]bool{
+		"api":                              false,
+		"api (conflicted copy 2024-05-01)": true,
+		"api (Alice's conflicted copy)":    true,
+		"API (Case Conflict)":              true,
+		"api-" + hostname():                hostname() != "",
+		"conflict-resolver":                false,
+		"my-" + hostname() + "-tools":      false,
+	}
+	for name, want := range tests {
+		if got := IsSyncConflict(name); got != want {
+			t.Errorf("IsSyncConflict(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestScanner_SkipSyncConflicts(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"api", "api (conflicted copy 2024-05-01)"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, name, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{tmpDir})
+	s.SetSkipSyncConflicts(true)
+	projects, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].Name != "api" {
+		t.Errorf("expected only api, got %v", projects)
+	}
+}
+
+func TestScanner_SkipMount(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses /proc as a second file system")
+	}
+	tmpDir := t.TempDir()
+	mount, ok := mountID(tmpDir, nil)
+	if !ok {
+		t.Fatal("mountID failed for a temp dir")
+	}
+	if proc, ok := mountID("/proc", nil); !ok || proc == mount {
+		t.Skip("/proc is not a separate file system")
+	}
+
+	s := NewScanner(ScannerAny)
+	s.SetSkipNetworkMounts(true)
+	if s.skipMount(mount, "/proc", nil) {
+		t.Error("expected /proc, a local file system, not to be skipped as a network mount")
+	}
+	if err := os.Mkdir(filepath.Join(tmpDir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	s.SetOneFileSystem(true)
+	if s.skipMount(mount, filepath.Join(tmpDir, "sub"), nil) {
+		t.Error("expected a folder on the same file system not to be skipped")
+	}
+	if !s.skipMount(mount, "/proc", nil) {
+		t.Error("expected /proc to be skipped with oneFileSystem")
+	}
+}
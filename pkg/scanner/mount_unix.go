@@ -0,0 +1,26 @@
+//go:build !windows
+
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// mountID identifies the file system path is on, by its device number.
+// info is the file info of path when it is already known, or nil.
+func mountID(path string, info fs.FileInfo) (string, bool) {
+	if info == nil {
+		var err error
+		if info, err = os.Stat(path); err != nil {
+			return "", false
+		}
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatUint(uint64(st.Dev), 10), true
+}
@@ -0,0 +1,54 @@
+//go:build windows
+
+package scanner
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+// volumePath returns the root of the volume path is on, such as C:\ or
+// the folder a volume is mounted on
+func volumePath(path string) (string, bool) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return "", false
+	}
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	if err := windows.GetVolumePathName(p, &buf[0], uint32(len(buf))); err != nil {
+		return "", false
+	}
+	return windows.UTF16ToString(buf), true
+}
+
+// mountID identifies the file system path is on, by its volume root; the
+// file info of path is not needed
+func mountID(path string, _ fs.FileInfo) (string, bool) {
+	return volumePath(path)
+}
+
+// isNetworkMount reports whether path is on a network share: a UNC path
+// or a mapped network drive
+func isNetworkMount(path string) bool {
+	if _, _, ok := paths.ParseWSL(path); ok {
+		// \\wsl$ shares are local
+		return false
+	}
+	if strings.HasPrefix(filepath.VolumeName(path), `\\`) {
+		return true
+	}
+	root, ok := volumePath(path)
+	if !ok {
+		return false
+	}
+	p, err := windows.UTF16PtrFromString(root)
+	if err != nil {
+		return false
+	}
+	return windows.GetDriveType(p) == windows.DRIVE_REMOTE
+}
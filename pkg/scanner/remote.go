@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+// RemoteURL returns the URL of the remote a Git or Mercurial working copy
+// was cloned from: the origin remote (or the first remote) for Git and the
+// default path for Mercurial. It reads the repository config directly and
+// returns an empty string when there is no remote.
+func RemoteURL(folder string) string {
+	switch DetectKind(folder) {
+	case models.KindGit:
+		dir := gitDir(folder)
+		if dir == "" {
+			return ""
+		}
+		remotes := readConfigSections(filepath.Join(dir, "config"), "remote", "url")
+		if url, ok := remotes["origin"]; ok {
+			return url
+		}
+		return firstValue(remotes)
+	case models.KindMercurial:
+		paths := readConfigSections(filepath.Join(folder, ".hg", "hgrc"), "paths", "")
+		return paths["default"]
+	}
+	return ""
+}
+
+// gitDir returns the folder holding the repository config for a working
+// copy, following .git files (worktrees and submodules) to the real
+// repository
+func gitDir(folder string) string {
+	dir := filepath.Join(folder, ".git")
+	info, err := os.Stat(dir)
+	if err != nil {
+		return ""
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(dir)
+		if err != nil {
+			return ""
+		}
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !ok {
+			return ""
+		}
+		dir = strings.TrimSpace(target)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(folder, dir)
+		}
+	}
+	// Worktrees share the config of the main repository
+	if data, err := os.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(dir, common)
+		}
+		dir = common
+	}
+	return dir
+}
+
+// readConfigSections reads an INI-style config file (Git config or hgrc)
+// and returns values from the sections named section. With a key, it maps
+// each section's subsection name (as in [remote "origin"]) to the key's
+// value; without one, it maps every key in [section] to its value.
+func readConfigSections(path, section, key string) map[string]string {
+	values := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return values
+	}
+	defer f.Close()
+
+	var current string
+	inSection := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name, sub, _ := strings.Cut(strings.Trim(line, "[]"), " ")
+			inSection = strings.EqualFold(strings.TrimSpace(name), section)
+			current = strings.Trim(strings.TrimSpace(sub), `"`)
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		switch {
+		case key == "":
+			values[k] = v
+		case strings.EqualFold(k, key):
+			if _, seen := values[current]; !seen {
+				values[current] = v
+			}
+		}
+	}
+	return values
+}
+
+// firstValue returns the value of the alphabetically first key in m
+func firstValue(m map[string]string) string {
+	first, value := "", ""
+	for k, v := range m {
+		if value == "" || k < first {
+			first, value = k, v
+		}
+	}
+	return value
+}
+
+// ParseRemoteURL splits a remote URL into its host and repository path,
+// handling https, ssh:// and scp-like (git@host:org/repo.git) forms. The
+// host of an http(s) remote keeps a non-default port, as its web pages are
+// served there too; ssh ports are dropped. The path has no leading slash or
+// .git suffix. Local paths return an empty host.
+func ParseRemoteURL(url string) (host, path string) {
+	scheme := ""
+	if s, after, ok := strings.Cut(url, "://"); ok {
+		scheme = strings.ToLower(s)
+		host, path, _ = strings.Cut(after, "/")
+	} else if h, p, ok := strings.Cut(url, ":"); ok && !strings.ContainsAny(h, `/\`) && len(h) > 1 {
+		host, path = h, p
+	} else {
+		return "", ""
+	}
+
+	// Drop user info, and the port unless the web host is on it
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if h, port, ok := strings.Cut(host, ":"); ok {
+		keep := (scheme == "https" && port != "443") || (scheme == "http" && port != "80")
+		if !keep {
+			host = h
+		}
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return strings.ToLower(host), path
+}
+
+// RemoteTags derives default tags from a remote URL: the host and the
+// owner (organization, user or group path) of the repository
+func RemoteTags(url string) []string {
+	host, path := ParseRemoteURL(url)
+	if host == "" {
+		return nil
+	}
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	tags := []string{host}
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		tags = append(tags, path[:i])
+	}
+	return tags
+}
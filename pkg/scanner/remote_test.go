@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url, host, path string
+	}{
+		{"https://github.com/acme/api.git", "github.com", "acme/api"},
+		{"https://user@GitHub.com:443/acme/api/", "github.com", "acme/api"},
+		{"ssh://git@gitlab.com:2222/acme/platform/api.git", "gitlab.com", "acme/platform/api"},
+		{"https://git.corp:8443/acme/api.git", "git.corp:8443", "acme/api"},
+		{"http://git.corp:80/acme/api", "git.corp", "acme/api"},
+		{"git@github.com:acme/api.git", "github.com", "acme/api"},
+		{"/srv/git/api.git", "", ""},
+		{`C:\repos\api`, "", ""},
+	}
+
+	for _, tt := range tests {
+		host, path := ParseRemoteURL(tt.url)
+		if host != tt.host || path != tt.path {
+			t.Errorf("ParseRemoteURL(%q) = %q, %q; want %q, %q", tt.url, host, path, tt.host, tt.path)
+		}
+	}
+}
+
+func TestRemoteTags(t *testing.T) {
+	tests := map[string][]string{
+		"git@github.com:acme/api.git":              {"github.com", "acme"},
+		"https://gitlab.com/acme/platform/api.git": {"gitlab.com", "acme/platform"},
+		"https://git.example.com/api.git":          {"git.example.com"},
+		"https://git.corp:8443/acme/api.git":       {"git.corp", "acme"},
+		"/srv/git/api.git":                         nil,
+	}
+	for url, want := range tests {
+		if got := RemoteTags(url); !reflect.DeepEqual(got, want) {
+			t.Errorf("RemoteTags(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	dir := t.TempDir()
+
+	repo := filepath.Join(dir, "repo")
+	os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	os.WriteFile(filepath.Join(repo, ".git", "config"), []byte(`[core]
+	bare = false
+[remote "upstream"]
+	url = https://github.com/upstream/api.git
+[remote "origin"]
+	url = git@github.com:acme/api.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+`), 0644)
+
+	// A worktree points at the main repository through a .git file
+	worktree := filepath.Join(dir, "worktree")
+	gitdir := filepath.Join(repo, ".git", "worktrees", "wt")
+	os.MkdirAll(worktree, 0755)
+	os.MkdirAll(gitdir, 0755)
+	os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+gitdir+"\n"), 0644)
+	os.WriteFile(filepath.Join(gitdir, "commondir"), []byte("../..\n"), 0644)
+
+	hg := filepath.Join(dir, "hg")
+	os.MkdirAll(filepath.Join(hg, ".hg"), 0755)
+	os.WriteFile(filepath.Join(hg, ".hg", "hgrc"), []byte("[paths]\ndefault = https://hg.example.com/api\n"), 0644)
+
+	plain := filepath.Join(dir, "plain")
+	os.MkdirAll(plain, 0755)
+
+	tests := map[string]string{
+		repo:     "git@github.com:acme/api.git",
+		worktree: "git@github.com:acme/api.git",
+		hg:       "https://hg.example.com/api",
+		plain:    "",
+	}
+	for folder, want := range tests {
+		if got := RemoteURL(folder); got != want {
+			t.Errorf("RemoteURL(%s) = %q, want %q", filepath.Base(folder), got, want)
+		}
+	}
+}
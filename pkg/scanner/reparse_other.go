@@ -0,0 +1,11 @@
+//go:build !windows
+
+package scanner
+
+import "io/fs"
+
+// isReparsePoint reports whether entry is a Windows reparse point, which
+// only exist on Windows
+func isReparsePoint(entry fs.DirEntry) bool {
+	return false
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// isReparsePoint reports whether entry is a directory reparse point, such
+// as a junction or a OneDrive placeholder. Scanning into them can loop
+// through junctions or download a whole cloud folder, so they are
+// followed like symlinks. Go no longer reports junctions as symlinks, nor
+// as directories. Reading the attributes needs no system call, as they
+// come with the directory listing.
+func isReparsePoint(entry fs.DirEntry) bool {
+	info, err := entry.Info()
+	if err != nil {
+		return false
+	}
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	const dirReparse = syscall.FILE_ATTRIBUTE_DIRECTORY | syscall.FILE_ATTRIBUTE_REPARSE_POINT
+	return ok && attrs.FileAttributes&dirReparse == dirReparse
+}
@@ -0,0 +1,71 @@
+//go:build windows
+
+package scanner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanner_Junctions(t *testing.T) {
+	base := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "a", "repo", ".git"), 0755)
+	os.MkdirAll(filepath.Join(outside, "linked", ".git"), 0755)
+
+	junctions := map[string]string{
+		filepath.Join(base, "a", "loop"): base,
+		filepath.Join(base, "out"):       outside,
+	}
+	for link, target := range junctions {
+		if out, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput(); err != nil {
+			t.Skipf("junctions not supported: %v: %s", err, out)
+		}
+	}
+
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if got := isReparsePoint(entry); got != (entry.Name() == "out") {
+			t.Errorf("isReparsePoint(%s) = %v", entry.Name(), got)
+		}
+	}
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{base})
+	s.SetMaxDepth(10)
+
+	projects, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(projects) != 1 || projects[0].Name != "repo" {
+		t.Errorf("without supportSymlinks expected only repo, got %v", projects)
+	}
+	// base, a and repo; the junctions are not entered
+	if s.visited != 3 {
+		t.Errorf("expected 3 folders visited, got %d", s.visited)
+	}
+
+	s.SetSupportSymlinks(true)
+	projects, err = s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	names := map[string]bool{}
+	for _, p := range projects {
+		names[p.Name] = true
+	}
+	if len(projects) != 2 || !names["repo"] || !names["linked"] {
+		t.Errorf("expected repo and linked once each, got %v", projects)
+	}
+	// Every real folder is scanned once: base, a, repo, outside and linked
+	if s.visited != 5 {
+		t.Errorf("expected 5 folders visited, got %d", s.visited)
+	}
+}
@@ -4,10 +4,15 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/paths"
@@ -24,6 +29,10 @@ const (
 	ScannerAny       ScannerType = "any"
 )
 
+// ErrScanLimit is returned by Scan when it finds more projects or visits
+// more folders than the limits set with SetLimits
+var ErrScanLimit = errors.New("scan limit exceeded")
+
 // ErrorHandler is a callback for handling scan errors
 type ErrorHandler func(path string, err error)
 
@@ -35,7 +44,31 @@ type Scanner struct {
 	scannerType          ScannerType
 	ignoreWithinProjects bool
 	supportSymlinks      bool
+	respectGitignore     bool
+	globalIgnore         *Ignore
 	errorHandler         ErrorHandler
+
+	// Folders skipped to keep scans off other and slow file systems
+	oneFileSystem     bool
+	skipNetworkMounts bool
+	skipSyncConflicts bool
+
+	// Limits that abort runaway scans, and what the current scan has used
+	maxProjects int
+	maxDirs     int
+	found       int
+	visited     int
+
+	// scanned maps the real path of each folder scanned while following
+	// symlinks to the shallowest depth it was scanned at, so links back
+	// into a scanned tree are not scanned again or followed in a loop
+	scanned map[string]int
+
+	// previous is the state reused for unchanged directories; state is
+	// recorded by the current scan
+	previous ScanState
+	state    ScanState
+	started  time.Time
 }
 
 // NewScanner creates a new project scanner
@@ -75,38 +108,109 @@ func (s *Scanner) SetSupportSymlinks(support bool) {
 	s.supportSymlinks = support
 }
 
+// SetRespectGitignore sets whether .gitignore and .ignore files found
+// during the walk are honored
+func (s *Scanner) SetRespectGitignore(respect bool) {
+	s.respectGitignore = respect
+}
+
+// SetGlobalIgnore sets gitignore-style rules applied relative to each base
+// folder. When set, they replace the ignored folder name list.
+func (s *Scanner) SetGlobalIgnore(ig *Ignore) {
+	s.globalIgnore = ig
+}
+
+// SetOneFileSystem sets whether folders on another file system than the
+// folder above them, such as mount points, are skipped
+func (s *Scanner) SetOneFileSystem(one bool) {
+	s.oneFileSystem = one
+}
+
+// SetSkipNetworkMounts sets whether folders on network file systems (NFS,
+// SMB and the like) are skipped. Base folders are always scanned.
+func (s *Scanner) SetSkipNetworkMounts(skip bool) {
+	s.skipNetworkMounts = skip
+}
+
+// SetSkipSyncConflicts sets whether conflict copies made by cloud sync
+// clients are skipped (see IsSyncConflict)
+func (s *Scanner) SetSkipSyncConflicts(skip bool) {
+	s.skipSyncConflicts = skip
+}
+
+// SetLimits sets how many projects a scan may find and how many folders
+// it may visit before it stops with ErrScanLimit, so a scan pointed at a
+// huge tree such as / cannot run away. 0 means no limit.
+func (s *Scanner) SetLimits(maxProjects, maxDirs int) {
+	s.maxProjects = maxProjects
+	s.maxDirs = maxDirs
+}
+
 // SetErrorHandler sets the callback for handling scan errors
 func (s *Scanner) SetErrorHandler(handler ErrorHandler) {
 	s.errorHandler = handler
 }
 
-// logError calls the error handler if set
+// SetPreviousState makes Scan reuse the state recorded by an earlier scan
+// for directories whose mtime has not changed since
+func (s *Scanner) SetPreviousState(state ScanState) {
+	s.previous = state
+}
+
+// State returns the directory state recorded by the last Scan, to be
+// passed to SetPreviousState on the next one
+func (s *Scanner) State() ScanState {
+	return s.state
+}
+
+// logError logs a scan error and calls the error handler if set
 func (s *Scanner) logError(path string, err error) {
+	slog.Debug("scan error", "type", s.scannerType, "path", path, "error", err)
 	if s.errorHandler != nil {
 		s.errorHandler(path, err)
 	}
 }
 
-// Scan scans all base folders for projects
-func (s *Scanner) Scan() ([]*models.Project, error) {
+// Scan scans all base folders for projects.
+// It stops and returns ctx.Err() as soon as the context is cancelled.
+func (s *Scanner) Scan(ctx context.Context) ([]*models.Project, error) {
 	var projects []*models.Project
 	seen := make(map[string]bool)
 
+	s.state = make(ScanState)
+	s.started = time.Now()
+	s.found, s.visited = 0, 0
+	s.scanned = make(map[string]int)
+
 	for _, baseFolder := range s.baseFolders {
-		if _, err := os.Stat(baseFolder); os.IsNotExist(err) {
+		info, err := os.Stat(baseFolder)
+		if os.IsNotExist(err) {
 			s.logError(baseFolder, fmt.Errorf("base folder does not exist: %w", err))
 			continue
 		}
 
-		found, err := s.scanFolder(baseFolder, 0, false)
+		slog.Debug("scanning base folder", "type", s.scannerType, "folder", baseFolder, "maxDepth", s.maxDepth)
+
+		var ignores IgnoreStack
+		if s.globalIgnore != nil {
+			ignores = IgnoreStack{{dir: baseFolder, ignore: s.globalIgnore}}
+		}
+
+		found, err := s.scanFolder(ctx, baseFolder, info, 0, false, ignores)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, ErrScanLimit) {
+			return nil, err
+		}
 		if err != nil {
 			s.logError(baseFolder, fmt.Errorf("failed to scan folder: %w", err))
 			continue
 		}
 
 		for _, project := range found {
-			if !seen[project.RootPath] {
-				seen[project.RootPath] = true
+			if key := paths.Key(project.RootPath); !seen[key] {
+				seen[key] = true
 				projects = append(projects, project)
 			}
 		}
@@ -142,59 +246,94 @@ func deduplicateNames(projects []*models.Project) {
 	}
 }
 
-// scanFolder recursively scans a folder for projects
-func (s *Scanner) scanFolder(folder string, depth int, insideProject bool) ([]*models.Project, error) {
+// scanFolder recursively scans a folder for projects. info is the
+// folder's file info, or nil when it is not known yet; it is looked up
+// once and shared by the incremental state and mount checks.
+func (s *Scanner) scanFolder(ctx context.Context, folder string, info fs.FileInfo, depth int, insideProject bool, ignores IgnoreStack) ([]*models.Project, error) {
 	var projects []*models.Project
 
+	if err := ctx.Err(); err != nil {
+		return projects, err
+	}
+
 	if depth > s.maxDepth {
 		return projects, nil
 	}
 
-	// Check if current folder is a project of this type
-	isProject := s.isProject(folder)
+	if s.supportSymlinks && s.scannedBefore(folder, depth) {
+		slog.Debug("skipping folder already scanned through another path", "type", s.scannerType, "path", folder)
+		return projects, nil
+	}
+
+	if s.visited++; s.maxDirs > 0 && s.visited > s.maxDirs {
+		return projects, fmt.Errorf("%w: visited more than %d folders (maxDirsVisited) at %s", ErrScanLimit, s.maxDirs, folder)
+	}
+
+	if info == nil {
+		info, _ = os.Stat(folder)
+	}
+
+	// Check if current folder is a project of this type and list its subdirectories
+	found, read, err := s.readFolder(folder, info)
+
+	if s.respectGitignore {
+		if read.reused {
+			ignores = ignores.Push(folder, s.logError)
+		} else {
+			ignores = ignores.PushFiles(folder, read.ignoreFiles, s.logError)
+		}
+	}
 
-	if isProject {
+	if found.IsProject {
 		if !s.ignoreWithinProjects || !insideProject {
 			project := &models.Project{
-				Name:     filepath.Base(folder),
-				RootPath: folder,
-				Tags:     []string{},
-				Enabled:  true,
-				Kind:     s.getProjectKind(),
+				Name:          filepath.Base(folder),
+				RootPath:      folder,
+				Tags:          []string{},
+				Enabled:       true,
+				Language:      found.Language,
+				WorkspaceFile: found.WorkspaceFile,
+				DevContainer:  found.DevContainer,
+				Kind:          s.getProjectKind(),
 			}
 			projects = append(projects, project)
+			if s.found++; s.maxProjects > 0 && s.found > s.maxProjects {
+				return projects, fmt.Errorf("%w: found more than %d projects (maxProjectsPerScan) at %s", ErrScanLimit, s.maxProjects, folder)
+			}
 		}
 		insideProject = true
 	}
 
 	// Scan subdirectories
-	entries, err := os.ReadDir(folder)
 	if err != nil {
 		s.logError(folder, fmt.Errorf("failed to read directory: %w", err))
 		return projects, nil
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
+	var mount string
+	checkMounts := false
+	if s.oneFileSystem || s.skipNetworkMounts {
+		mount, checkMounts = mountID(folder, info)
+	}
 
-		name := entry.Name()
+	for _, dir := range read.dirs {
+		name := dir.name
+		subPath := filepath.Join(folder, name)
 
-		// Skip hidden directories (except .vscode for workspace detection)
-		if strings.HasPrefix(name, ".") && name != ".vscode" {
+		// Skip ignored folders; a global ignore file replaces the name list
+		if s.globalIgnore == nil && s.isIgnored(subPath) {
 			continue
 		}
-
-		// Skip ignored folders
-		if s.isIgnored(name) {
+		if ignores.Ignored(subPath, true) {
+			continue
+		}
+		if s.skipSyncConflicts && IsSyncConflict(name) {
+			slog.Debug("skipping sync conflict copy", "type", s.scannerType, "path", subPath)
 			continue
 		}
-
-		subPath := filepath.Join(folder, name)
 
 		// Handle symlinks
-		if entry.Type()&os.ModeSymlink != 0 {
+		if dir.symlink {
 			if !s.supportSymlinks {
 				continue
 			}
@@ -207,7 +346,22 @@ func (s *Scanner) scanFolder(folder string, depth int, insideProject bool) ([]*m
 			subPath = resolved
 		}
 
-		subProjects, err := s.scanFolder(subPath, depth+1, insideProject)
+		// Folders past the maximum depth are not read, so need no info
+		var subInfo fs.FileInfo
+		if checkMounts || depth < s.maxDepth {
+			subInfo = dir.stat(subPath)
+		}
+		if checkMounts && s.skipMount(mount, subPath, subInfo) {
+			continue
+		}
+
+		subProjects, err := s.scanFolder(ctx, subPath, subInfo, depth+1, insideProject, ignores)
+		if ctx.Err() != nil {
+			return projects, ctx.Err()
+		}
+		if errors.Is(err, ErrScanLimit) {
+			return projects, err
+		}
 		if err != nil {
 			s.logError(subPath, fmt.Errorf("failed to scan subfolder: %w", err))
 			continue
@@ -218,6 +372,40 @@ func (s *Scanner) scanFolder(folder string, depth int, insideProject bool) ([]*m
 	return projects, nil
 }
 
+// scannedBefore reports whether the real path of folder was already
+// scanned at depth or shallower, recording it otherwise. Folders whose
+// path cannot be resolved are always scanned.
+func (s *Scanner) scannedBefore(folder string, depth int) bool {
+	resolved, err := filepath.EvalSymlinks(folder)
+	if err != nil {
+		return false
+	}
+	if d, ok := s.scanned[resolved]; ok && d <= depth {
+		return true
+	}
+	s.scanned[resolved] = depth
+	return false
+}
+
+// skipMount reports whether subPath, whose file info is subInfo, is
+// skipped for being on another file system than mount, the one of the
+// folder above it
+func (s *Scanner) skipMount(mount, subPath string, subInfo fs.FileInfo) bool {
+	sub, ok := mountID(subPath, subInfo)
+	if !ok || sub == mount {
+		return false
+	}
+	if s.oneFileSystem {
+		slog.Debug("skipping other file system", "type", s.scannerType, "path", subPath)
+		return true
+	}
+	if isNetworkMount(subPath) {
+		slog.Debug("skipping network mount", "type", s.scannerType, "path", subPath)
+		return true
+	}
+	return false
+}
+
 // isProject checks if a folder is a project of the scanner's type
 func (s *Scanner) isProject(folder string) bool {
 	switch s.scannerType {
@@ -236,22 +424,110 @@ func (s *Scanner) isProject(folder string) bool {
 	}
 }
 
-// isIgnored checks if a folder name should be ignored
-func (s *Scanner) isIgnored(name string) bool {
-	for _, ignored := range s.ignoredFolders {
-		// Support simple glob patterns
-		if strings.Contains(ignored, "*") {
-			matched, _ := filepath.Match(ignored, name)
-			if matched {
-				return true
-			}
-		} else if name == ignored {
+// isProjectIn is isProject for a folder whose entries were already read,
+// so only symlinks need another lookup
+func (s *Scanner) isProjectIn(folder string, entries []fs.DirEntry) bool {
+	switch s.scannerType {
+	case ScannerGit:
+		return dirEntryExists(folder, entries, ".git")
+	case ScannerSVN:
+		return dirEntryExists(folder, entries, ".svn")
+	case ScannerMercurial:
+		return dirEntryExists(folder, entries, ".hg")
+	case ScannerVSCode:
+		return hasFileWithExt(entries, ".code-workspace")
+	case ScannerAny:
+		return true
+	default:
+		return false
+	}
+}
+
+// dirEntryExists reports whether entries has a directory, or a symlink to
+// one, called name, like dirExists does for the path
+func dirEntryExists(folder string, entries []fs.DirEntry, name string) bool {
+	for _, entry := range entries {
+		if entry.Name() != name {
+			continue
+		}
+		if entry.Type()&os.ModeSymlink != 0 {
+			return dirExists(filepath.Join(folder, name))
+		}
+		return entry.IsDir()
+	}
+	return false
+}
+
+// DetectKind returns what kind of project folder is: a Git, SVN or
+// Mercurial working copy, a folder with a VS Code workspace, or any folder.
+// Git worktrees and submodules, whose .git is a file, count as Git.
+func DetectKind(folder string) models.ProjectKind {
+	switch {
+	case paths.Exists(filepath.Join(folder, ".git")):
+		return models.KindGit
+	case dirExists(filepath.Join(folder, ".svn")):
+		return models.KindSVN
+	case dirExists(filepath.Join(folder, ".hg")):
+		return models.KindMercurial
+	case fileExistsWithExt(folder, ".code-workspace"):
+		return models.KindVSCode
+	default:
+		return models.KindAny
+	}
+}
+
+// isIgnored checks if the folder at path should be ignored
+func (s *Scanner) isIgnored(path string) bool {
+	return IsIgnoredFolder(s.ignoredFolders, path)
+}
+
+// IsIgnoredFolder reports whether the folder at path matches an ignored
+// folder pattern, as by MatchFolder
+func IsIgnoredFolder(patterns []string, path string) bool {
+	for _, ignored := range patterns {
+		if MatchFolder(ignored, path) {
 			return true
 		}
 	}
 	return false
 }
 
+// MatchFolder reports whether the folder at path matches pattern.
+// Patterns starting with / or ~ (or a drive letter on Windows) match the
+// full path: without wildcards they match the folder and everything below
+// it, and with wildcards they are globs in which ** spans directories.
+// Other patterns match the folder's base name, with simple glob support.
+func MatchFolder(pattern, path string) bool {
+	if isFullPathPattern(pattern) {
+		return matchFullPath(paths.Expand(pattern), path)
+	}
+	// Support simple glob patterns
+	if strings.Contains(pattern, "*") {
+		matched, _ := filepath.Match(pattern, filepath.Base(path))
+		return matched
+	}
+	return filepath.Base(path) == pattern
+}
+
+// isFullPathPattern reports whether an ignore pattern refers to a full path
+func isFullPathPattern(pattern string) bool {
+	return strings.HasPrefix(pattern, "~") ||
+		strings.HasPrefix(pattern, "$HOME") ||
+		strings.HasPrefix(pattern, "/") ||
+		filepath.IsAbs(pattern)
+}
+
+// matchFullPath matches an expanded full-path pattern against path
+func matchFullPath(pattern, path string) bool {
+	pattern = filepath.ToSlash(filepath.Clean(pattern))
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		return path == pattern || strings.HasPrefix(path, strings.TrimSuffix(pattern, "/")+"/")
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
 // getProjectKind returns the project kind for this scanner
 func (s *Scanner) getProjectKind() models.ProjectKind {
 	switch s.scannerType {
@@ -279,12 +555,87 @@ func dirExists(path string) bool {
 	return info.IsDir()
 }
 
+// DevContainerFiles are the dev container configurations looked for in a
+// project root, relative to it, in priority order
+var DevContainerFiles = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// DevContainerConfig returns the dev container configuration of the
+// project in folder, relative to it, or an empty string when there is none
+func DevContainerConfig(folder string) string {
+	for _, name := range DevContainerFiles {
+		if info, err := os.Stat(filepath.Join(folder, name)); err == nil && !info.IsDir() {
+			return name
+		}
+	}
+	return ""
+}
+
+// devContainerIn is DevContainerConfig for a folder whose entries were
+// already read: the files are only looked up when their first component
+// is among them
+func devContainerIn(folder string, entries []fs.DirEntry) string {
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	for _, name := range DevContainerFiles {
+		first, _, _ := strings.Cut(filepath.ToSlash(name), "/")
+		if !names[first] {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(folder, name)); err == nil && !info.IsDir() {
+			return name
+		}
+	}
+	return ""
+}
+
+// WorkspaceFile returns the name of the VS Code workspace file
+// (*.code-workspace) in folder: the one named after the folder if there
+// are several, otherwise the first by name. It returns an empty string
+// when there is none.
+func WorkspaceFile(folder string) string {
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		return ""
+	}
+	return workspaceFileIn(folder, entries)
+}
+
+// workspaceFileIn is WorkspaceFile for a folder whose entries were
+// already read
+func workspaceFileIn(folder string, entries []fs.DirEntry) string {
+	preferred := filepath.Base(folder) + ".code-workspace"
+	first := ""
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".code-workspace") {
+			continue
+		}
+		if name == preferred {
+			return name
+		}
+		if first == "" {
+			first = name
+		}
+	}
+	return first
+}
+
 // fileExistsWithExt checks if any file with the given extension exists in the folder
 func fileExistsWithExt(folder, ext string) bool {
 	entries, err := os.ReadDir(folder)
 	if err != nil {
 		return false
 	}
+	return hasFileWithExt(entries, ext)
+}
+
+// hasFileWithExt checks if any of entries is a file with the given extension
+func hasFileWithExt(entries []fs.DirEntry, ext string) bool {
 	for _, entry := range entries {
 		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ext) {
 			return true
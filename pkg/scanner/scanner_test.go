@@ -1,9 +1,13 @@
 package scanner
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/paths"
@@ -78,7 +82,7 @@ func TestScanner_ScanGit(t *testing.T) {
 	s.SetBaseFolders([]string{tmpDir})
 	s.SetMaxDepth(4)
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan failed: %v", err)
 	}
@@ -108,7 +112,7 @@ func TestScanner_ScanSVN(t *testing.T) {
 	s := NewScanner(ScannerSVN)
 	s.SetBaseFolders([]string{tmpDir})
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan failed: %v", err)
 	}
@@ -132,7 +136,7 @@ func TestScanner_ScanMercurial(t *testing.T) {
 	s := NewScanner(ScannerMercurial)
 	s.SetBaseFolders([]string{tmpDir})
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan failed: %v", err)
 	}
@@ -157,7 +161,7 @@ func TestScanner_ScanVSCode(t *testing.T) {
 	s := NewScanner(ScannerVSCode)
 	s.SetBaseFolders([]string{tmpDir})
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan failed: %v", err)
 	}
@@ -169,6 +173,82 @@ func TestScanner_ScanVSCode(t *testing.T) {
 	if projects[0].Kind != models.KindVSCode {
 		t.Errorf("expected kind KindVSCode, got %s", projects[0].Kind)
 	}
+	if projects[0].WorkspaceFile != "project.code-workspace" {
+		t.Errorf("expected workspace file project.code-workspace, got %q", projects[0].WorkspaceFile)
+	}
+}
+
+func TestWorkspaceFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	os.MkdirAll(dir, 0755)
+
+	if got := WorkspaceFile(dir); got != "" {
+		t.Errorf("WorkspaceFile() = %q for a folder without one", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "b.code-workspace"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(dir, "a.code-workspace"), []byte("{}"), 0644)
+	if got := WorkspaceFile(dir); got != "a.code-workspace" {
+		t.Errorf("WorkspaceFile() = %q, want the first by name", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "app.code-workspace"), []byte("{}"), 0644)
+	if got := WorkspaceFile(dir); got != "app.code-workspace" {
+		t.Errorf("WorkspaceFile() = %q, want the one named after the folder", got)
+	}
+}
+
+func TestDevContainerConfig(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	os.MkdirAll(dir, 0755)
+
+	if got := DevContainerConfig(dir); got != "" {
+		t.Errorf("DevContainerConfig() = %q for a folder without one", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".devcontainer.json"), []byte("{}"), 0644)
+	if got := DevContainerConfig(dir); got != ".devcontainer.json" {
+		t.Errorf("DevContainerConfig() = %q, want .devcontainer.json", got)
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0755)
+	os.WriteFile(filepath.Join(dir, ".devcontainer", "devcontainer.json"), []byte("{}"), 0644)
+	if got, want := DevContainerConfig(dir), filepath.Join(".devcontainer", "devcontainer.json"); got != want {
+		t.Errorf("DevContainerConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	tmpDir := t.TempDir()
+	setup := map[string]string{
+		"git":       ".git/",
+		"svn":       ".svn/",
+		"hg":        ".hg/",
+		"workspace": "app.code-workspace",
+		"plain":     "README.md",
+	}
+	for dir, entry := range setup {
+		path := filepath.Join(tmpDir, dir, entry)
+		if entry[len(entry)-1] == '/' {
+			os.MkdirAll(path, 0755)
+		} else {
+			os.MkdirAll(filepath.Dir(path), 0755)
+			os.WriteFile(path, []byte("{}"), 0644)
+		}
+	}
+
+	tests := map[string]models.ProjectKind{
+		"git":       models.KindGit,
+		"svn":       models.KindSVN,
+		"hg":        models.KindMercurial,
+		"workspace": models.KindVSCode,
+		"plain":     models.KindAny,
+	}
+	for dir, want := range tests {
+		if got := DetectKind(filepath.Join(tmpDir, dir)); got != want {
+			t.Errorf("DetectKind(%s) = %q, want %q", dir, got, want)
+		}
+	}
 }
 
 func TestScanner_ScanIgnoresFolders(t *testing.T) {
@@ -186,7 +266,7 @@ func TestScanner_ScanIgnoresFolders(t *testing.T) {
 	s.SetBaseFolders([]string{tmpDir})
 	s.SetIgnoredFolders([]string{"node_modules"})
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan failed: %v", err)
 	}
@@ -215,7 +295,7 @@ func TestScanner_ScanRespectsMaxDepth(t *testing.T) {
 	s.SetBaseFolders([]string{tmpDir})
 	s.SetMaxDepth(4)
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan failed: %v", err)
 	}
@@ -230,7 +310,7 @@ func TestScanner_ScanNonExistentBasePath(t *testing.T) {
 	s := NewScanner(ScannerGit)
 	s.SetBaseFolders([]string{"/nonexistent/path/that/does/not/exist"})
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan should not fail for non-existent path: %v", err)
 	}
@@ -253,7 +333,7 @@ func TestScanner_DeduplicatesNames(t *testing.T) {
 	s := NewScanner(ScannerGit)
 	s.SetBaseFolders([]string{tmpDir})
 
-	projects, err := s.Scan()
+	projects, err := s.Scan(context.Background())
 	if err != nil {
 		t.Fatalf("Scan failed: %v", err)
 	}
@@ -296,7 +376,7 @@ func TestScanner_IgnoreWithinProjects(t *testing.T) {
 	s.SetBaseFolders([]string{tmpDir})
 	s.SetIgnoreWithinProjects(false)
 
-	projects, _ := s.Scan()
+	projects, _ := s.Scan(context.Background())
 	if len(projects) != 2 {
 		t.Errorf("expected 2 projects (nested allowed), got %d", len(projects))
 	}
@@ -306,7 +386,7 @@ func TestScanner_IgnoreWithinProjects(t *testing.T) {
 	s2.SetBaseFolders([]string{tmpDir})
 	s2.SetIgnoreWithinProjects(true)
 
-	projects2, _ := s2.Scan()
+	projects2, _ := s2.Scan(context.Background())
 	if len(projects2) != 1 {
 		t.Errorf("expected 1 project (nested ignored), got %d", len(projects2))
 	}
@@ -326,7 +406,7 @@ func TestScanner_SkipsHiddenDirectories(t *testing.T) {
 	s := NewScanner(ScannerGit)
 	s.SetBaseFolders([]string{tmpDir})
 
-	projects, _ := s.Scan()
+	projects, _ := s.Scan(context.Background())
 
 	// Should only find normal-repo, not the one in .hidden
 	if len(projects) != 1 {
@@ -477,7 +557,7 @@ func TestScanner_SetErrorHandler(t *testing.T) {
 
 	// Set a non-existent base folder to trigger an error
 	s.SetBaseFolders([]string{"/nonexistent/path/that/does/not/exist"})
-	s.Scan()
+	s.Scan(context.Background())
 
 	// Verify the error handler was called
 	if capturedPath != "/nonexistent/path/that/does/not/exist" {
@@ -493,10 +573,348 @@ func TestScanner_ErrorHandlerNotSet(t *testing.T) {
 
 	// Should not panic when error handler is not set
 	s.SetBaseFolders([]string{"/nonexistent/path"})
-	_, err := s.Scan()
+	_, err := s.Scan(context.Background())
 
 	// Scan should complete without error even though base folder doesn't exist
 	if err != nil {
 		t.Errorf("expected Scan to succeed, got error: %v", err)
 	}
 }
+
+func TestScanner_ScanCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "repo", ".git"), 0755)
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{tmpDir})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	projects, err := s.Scan(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if projects != nil {
+		t.Errorf("expected no projects from a cancelled scan, got %d", len(projects))
+	}
+}
+
+func TestIsIgnoredFolder_FullPathPatterns(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	patterns := []string{"~/work/legacy/**", "/mnt/backup/*", "/srv/archive", "node_modules"}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{filepath.Join(home, "work", "legacy"), true},
+		{filepath.Join(home, "work", "legacy", "old", "app"), true},
+		{filepath.Join(home, "work", "legacy-new"), false},
+		{"/mnt/backup/daily", true},
+		{"/mnt/backup/daily/repo", false}, // single * does not span directories
+		{"/srv/archive", true},
+		{"/srv/archive/2019/repo", true}, // plain path excludes the subtree
+		{"/srv/archived", false},
+		{"/code/app/node_modules", true},
+		{"/code/app/src", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsIgnoredFolder(patterns, tt.path); got != tt.expected {
+			t.Errorf("IsIgnoredFolder(%q) = %v, want %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestScanner_ScanIgnoresFullPath(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.MkdirAll(filepath.Join(tmpDir, "legacy", "old", ".git"), 0755)
+	os.MkdirAll(filepath.Join(tmpDir, "current", "legacy", ".git"), 0755)
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{tmpDir})
+	s.SetIgnoredFolders([]string{filepath.Join(tmpDir, "legacy")})
+
+	projects, _ := s.Scan(context.Background())
+	if len(projects) != 1 || projects[0].Name != "legacy" {
+		t.Errorf("expected only current/legacy, got %v", projects)
+	}
+}
+
+func TestScanner_IncrementalScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	for _, dir := range []string{"a/.git", "group/b/.git"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setOld := func(dirs ...string) {
+		for _, dir := range dirs {
+			if err := os.Chtimes(filepath.Join(tmpDir, dir), old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	setOld(".", "a", "group", "group/b")
+
+	scan := func(previous ScanState) ([]*models.Project, ScanState) {
+		s := NewScanner(ScannerGit)
+		s.SetBaseFolders([]string{tmpDir})
+		s.SetPreviousState(previous)
+		projects, err := s.Scan(context.Background())
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		return projects, s.State()
+	}
+
+	projects, state := scan(nil)
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+	if _, ok := state[filepath.Join(tmpDir, "a")]; !ok {
+		t.Error("expected state to be recorded for unchanged directory")
+	}
+
+	// Removing .git without touching a's mtime is invisible to an
+	// incremental scan, which shows a is not read again
+	if err := os.RemoveAll(filepath.Join(tmpDir, "a", ".git")); err != nil {
+		t.Fatal(err)
+	}
+	setOld("a")
+
+	// A new repository changes its parent's mtime and is found
+	if err := os.MkdirAll(filepath.Join(tmpDir, "group", "c", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, _ = scan(state)
+	names := make(map[string]bool)
+	for _, p := range projects {
+		names[p.Name] = true
+	}
+	if !names["a"] || !names["b"] || !names["c"] {
+		t.Errorf("expected a (reused), b and c, got %v", names)
+	}
+
+	projects, _ = scan(nil)
+	for _, p := range projects {
+		if p.Name == "a" {
+			t.Error("expected full scan to notice a is no longer a repository")
+		}
+	}
+}
+
+func TestScanner_Limits(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, name, "src"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewScanner(ScannerAny)
+	s.SetBaseFolders([]string{tmpDir})
+	s.SetMaxDepth(1)
+	s.SetLimits(3, 0)
+	if _, err := s.Scan(context.Background()); !errors.Is(err, ErrScanLimit) {
+		t.Errorf("Scan() with maxProjects 3 = %v, want ErrScanLimit", err)
+	}
+
+	s.SetLimits(0, 3)
+	if _, err := s.Scan(context.Background()); !errors.Is(err, ErrScanLimit) {
+		t.Errorf("Scan() with maxDirs 3 = %v, want ErrScanLimit", err)
+	}
+
+	s.SetLimits(4, 4)
+	projects, err := s.Scan(context.Background())
+	if err != nil || len(projects) != 4 {
+		t.Errorf("Scan() within the limits = %d projects, %v; want 4", len(projects), err)
+	}
+}
+
+func TestScanner_SymlinkLoops(t *testing.T) {
+	base := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "a", "repo", ".git"), 0755)
+	os.MkdirAll(filepath.Join(outside, "linked", ".git"), 0755)
+
+	links := map[string]string{
+		filepath.Join(base, "a", "loop"):         base,
+		filepath.Join(base, "a", "repo", "self"): filepath.Join(base, "a", "repo"),
+		filepath.Join(base, "b"):                 filepath.Join(base, "a"),
+		filepath.Join(base, "out"):               outside,
+		filepath.Join(base, "a", "out-again"):    outside,
+		filepath.Join(base, "broken"):            filepath.Join(base, "missing"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{base})
+	s.SetMaxDepth(10)
+
+	projects, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(projects) != 1 {
+		t.Errorf("without supportSymlinks expected only repo, got %v", projects)
+	}
+
+	s.SetSupportSymlinks(true)
+	projects, err = s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	names := map[string]bool{}
+	for _, p := range projects {
+		names[p.Name] = true
+	}
+	if len(projects) != 2 || !names["repo"] || !names["linked"] {
+		t.Errorf("expected repo and linked once each, got %v", projects)
+	}
+	// Every real folder is scanned once: base, a, repo, outside and linked
+	if s.visited != 5 {
+		t.Errorf("expected 5 folders visited, got %d", s.visited)
+	}
+}
+
+func TestScanner_DetailsFromEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	mkdir := func(dirs ...string) {
+		for _, dir := range dirs {
+			if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	mkdir("nested/.git", "nested/.devcontainer", "flat/.git", "empty/.git", "empty/.devcontainer", "worktree", "linked", "gitdirs/real")
+	os.WriteFile(filepath.Join(tmpDir, "nested", ".devcontainer", "devcontainer.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "flat", ".devcontainer.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "worktree", ".git"), []byte("gitdir: /elsewhere\n"), 0644)
+	if err := os.Symlink(filepath.Join(tmpDir, "gitdirs", "real"), filepath.Join(tmpDir, "linked", ".git")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{tmpDir})
+	projects, err := s.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	got := make(map[string]string)
+	for _, p := range projects {
+		got[p.Name] = p.DevContainer
+	}
+	want := map[string]string{
+		"nested": filepath.Join(".devcontainer", "devcontainer.json"),
+		"flat":   ".devcontainer.json",
+		"empty":  "",
+		"linked": "",
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected projects %v, got %v", want, got)
+	}
+	for name, dc := range want {
+		if d, ok := got[name]; !ok || d != dc {
+			t.Errorf("project %s: dev container %q (found %t), want %q", name, d, ok, dc)
+		}
+	}
+}
+
+// benchTree creates a base folder of 8 groups of 8 Go repositories, each
+// with a few source folders, and dates it an hour back so scans record
+// its state
+func benchTree(b *testing.B) string {
+	b.Helper()
+	base := b.TempDir()
+	old := time.Now().Add(-time.Hour)
+	var dirs []string
+	for g := range 8 {
+		group := filepath.Join(base, fmt.Sprintf("group%d", g))
+		for r := range 8 {
+			repo := filepath.Join(group, fmt.Sprintf("repo%d", r))
+			for _, sub := range []string{".git/objects", "cmd/app", "internal/store", "pkg/api", "docs"} {
+				if err := os.MkdirAll(filepath.Join(repo, sub), 0755); err != nil {
+					b.Fatal(err)
+				}
+			}
+			for _, file := range []string{"go.mod", "README.md", ".gitignore", "cmd/app/main.go"} {
+				if err := os.WriteFile(filepath.Join(repo, file), []byte("\n"), 0644); err != nil {
+					b.Fatal(err)
+				}
+			}
+			dirs = append(dirs, repo, filepath.Join(repo, "cmd"), filepath.Join(repo, "cmd", "app"),
+				filepath.Join(repo, "internal"), filepath.Join(repo, "internal", "store"),
+				filepath.Join(repo, "pkg"), filepath.Join(repo, "pkg", "api"), filepath.Join(repo, "docs"))
+		}
+		dirs = append(dirs, group)
+	}
+	for _, dir := range append(dirs, base) {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return base
+}
+
+// benchScan runs a scan of base per iteration; set configures the scanner
+func benchScan(b *testing.B, kind ScannerType, set func(s *Scanner)) {
+	base := benchTree(b)
+	b.ResetTimer()
+	for range b.N {
+		s := NewScanner(kind)
+		s.SetBaseFolders([]string{base})
+		s.SetMaxDepth(5)
+		if set != nil {
+			set(s)
+		}
+		projects, err := s.Scan(context.Background())
+		if err != nil || len(projects) == 0 {
+			b.Fatalf("Scan() = %d projects, %v", len(projects), err)
+		}
+	}
+}
+
+func BenchmarkScan_Git(b *testing.B) {
+	benchScan(b, ScannerGit, nil)
+}
+
+func BenchmarkScan_Any(b *testing.B) {
+	benchScan(b, ScannerAny, nil)
+}
+
+func BenchmarkScan_AllChecks(b *testing.B) {
+	benchScan(b, ScannerGit, func(s *Scanner) {
+		s.SetRespectGitignore(true)
+		s.SetSkipNetworkMounts(true)
+	})
+}
+
+func BenchmarkScan_Incremental(b *testing.B) {
+	base := benchTree(b)
+	s := NewScanner(ScannerGit)
+	s.SetBaseFolders([]string{base})
+	s.SetMaxDepth(5)
+	if _, err := s.Scan(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	state := s.State()
+	b.ResetTimer()
+	for range b.N {
+		s := NewScanner(ScannerGit)
+		s.SetBaseFolders([]string{base})
+		s.SetMaxDepth(5)
+		s.SetPreviousState(state)
+		if _, err := s.Scan(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
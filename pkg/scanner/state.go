@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// racyWindow is how recently a directory may have been modified for its
+// state to be recorded. A change made within the same mtime tick as the
+// scan would otherwise go unnoticed on filesystems with coarse timestamps.
+const racyWindow = 2 * time.Second
+
+// DirState is what a scan learned about one directory. While the
+// directory's mtime is unchanged, its entries are known to be the same and
+// later scans reuse the state instead of reading the directory again.
+type DirState struct {
+	ModTime       time.Time `json:"modTime"`
+	IsProject     bool      `json:"isProject,omitempty"`
+	Language      string    `json:"language,omitempty"`
+	WorkspaceFile string    `json:"workspaceFile,omitempty"`
+	DevContainer  string    `json:"devContainer,omitempty"`
+	Dirs          []string  `json:"dirs,omitempty"`
+	Symlinks      []string  `json:"symlinks,omitempty"`
+}
+
+// ScanState maps directory paths to their state for one scanner type
+type ScanState map[string]DirState
+
+// subdir is a directory entry the scanner may descend into
+type subdir struct {
+	name    string
+	symlink bool
+
+	// entry is the entry read from the parent, when it was read; info is
+	// the target of a symlink, already looked up
+	entry fs.DirEntry
+	info  fs.FileInfo
+}
+
+// stat returns the file info of the subdirectory at path, reusing what
+// reading its parent learned. It returns nil when path cannot be read.
+func (d subdir) stat(path string) fs.FileInfo {
+	if d.info != nil {
+		return d.info
+	}
+	if d.entry != nil && !d.symlink {
+		if info, err := d.entry.Info(); err == nil {
+			return info
+		}
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// folderRead is what reading a folder learned besides its state
+type folderRead struct {
+	dirs []subdir
+
+	// reused is set when the previous state was used instead of reading
+	// the folder; otherwise ignoreFiles are the ignore files in it
+	reused      bool
+	ignoreFiles []string
+}
+
+// readFolder returns what folder is - whether it is a project and, if so,
+// its language, workspace file and dev container configuration - and its
+// candidate subdirectories. info is the folder's file info, or nil to
+// look it up. The previous state is used when the folder's mtime is
+// unchanged; otherwise the folder is read once, everything is worked out
+// from its entries and, unless it changed too recently to be trusted, its
+// new state is recorded.
+func (s *Scanner) readFolder(folder string, info fs.FileInfo) (state DirState, read folderRead, err error) {
+	if info == nil {
+		info, _ = os.Stat(folder)
+	}
+	if info != nil {
+		if prev, ok := s.previous[folder]; ok && prev.ModTime.Equal(info.ModTime()) {
+			s.state[folder] = prev
+			return prev, folderRead{dirs: prev.subdirs(), reused: true}, nil
+		}
+	}
+
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		// A folder that can be entered but not listed may still be one
+		state.IsProject = s.isProject(folder)
+		return state, read, err
+	}
+
+	state.IsProject = s.isProjectIn(folder, entries)
+	if state.IsProject {
+		state.Language = detectLanguageIn(entries)
+		if s.scannerType == ScannerVSCode {
+			state.WorkspaceFile = workspaceFileIn(folder, entries)
+		}
+		state.DevContainer = devContainerIn(folder, entries)
+	}
+	read.ignoreFiles = ignoreFilesIn(entries)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		// Junctions and cloud placeholders on Windows are only entered
+		// when symlinks are followed
+		symlink := entry.Type()&os.ModeSymlink != 0 || isReparsePoint(entry)
+		if !entry.IsDir() && !symlink {
+			continue
+		}
+
+		// Skip hidden directories (except .vscode for workspace detection)
+		if strings.HasPrefix(name, ".") && name != ".vscode" {
+			continue
+		}
+
+		dir := subdir{name: name, symlink: symlink, entry: entry}
+		if symlink {
+			// Only links to directories are candidates; broken links are
+			// left out
+			target, err := os.Stat(filepath.Join(folder, name))
+			if err != nil || !target.IsDir() {
+				continue
+			}
+			dir.info = target
+			state.Symlinks = append(state.Symlinks, name)
+		} else {
+			state.Dirs = append(state.Dirs, name)
+		}
+		read.dirs = append(read.dirs, dir)
+	}
+
+	if info != nil && info.ModTime().Before(s.started.Add(-racyWindow)) {
+		state.ModTime = info.ModTime()
+		s.state[folder] = state
+	}
+	return state, read, nil
+}
+
+// subdirs returns the recorded subdirectories, symlinks last
+func (d DirState) subdirs() []subdir {
+	dirs := make([]subdir, 0, len(d.Dirs)+len(d.Symlinks))
+	for _, name := range d.Dirs {
+		dirs = append(dirs, subdir{name: name})
+	}
+	for _, name := range d.Symlinks {
+		dirs = append(dirs, subdir{name: name, symlink: true})
+	}
+	return dirs
+}
@@ -0,0 +1,148 @@
+// Package search provides a pure Go, ripgrep-style search of the files
+// below a directory, skipping VCS metadata, ignored and binary files.
+package search
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ideaspaper/projector/pkg/scanner"
+)
+
+// DefaultMaxFileSize is the size above which files are skipped
+const DefaultMaxFileSize = 1 << 20
+
+// binaryProbeSize is how much of a file is checked for NUL bytes
+const binaryProbeSize = 8000
+
+// skippedDirs are never searched
+var skippedDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+}
+
+// Match is a line matching the search pattern
+type Match struct {
+	Path string // Absolute file path
+	Rel  string // Path relative to the searched directory
+	Line int    // 1-based line number
+	Text string // Matching line without the line ending
+}
+
+// Options configures Dir
+type Options struct {
+	Globs         []string // Only search files whose name matches one of these patterns
+	IncludeHidden bool     // Search hidden files and directories
+	NoIgnore      bool     // Do not respect .gitignore and .ignore files
+	MaxFileSize   int64    // Skip larger files; 0 means DefaultMaxFileSize
+}
+
+// Dir searches the files below root for lines matching re, calling fn for
+// each match in directory order. Unreadable files and directories are
+// skipped. The walk stops at the first error returned by fn or when ctx
+// is done.
+func Dir(ctx context.Context, root string, re *regexp.Regexp, opts Options, fn func(Match) error) error {
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultMaxFileSize
+	}
+	return walk(ctx, root, root, re, opts, nil, fn)
+}
+
+func walk(ctx context.Context, root, dir string, re *regexp.Regexp, opts Options, ignores scanner.IgnoreStack, fn func(Match) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	if !opts.NoIgnore {
+		ignores = ignores.Push(dir, func(string, error) {})
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		if !opts.IncludeHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if entry.IsDir() {
+			if skippedDirs[name] || (!opts.NoIgnore && ignores.Ignored(path, true)) {
+				continue
+			}
+			if err := walk(ctx, root, path, re, opts, ignores, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Symlinks and other special files are not followed
+		if !entry.Type().IsRegular() || !matchesGlobs(name, opts.Globs) {
+			continue
+		}
+		if !opts.NoIgnore && ignores.Ignored(path, false) {
+			continue
+		}
+		if err := searchFile(root, path, re, opts, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchesGlobs reports whether name matches one of globs; no globs match everything
+func matchesGlobs(name string, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// searchFile reports the matching lines of a text file
+func searchFile(root, path string, re *regexp.Regexp, opts Options, fn func(Match) error) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > opts.MaxFileSize {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || isBinary(data) || !re.Match(data) {
+		return nil
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = path
+	}
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if !re.Match(line) {
+			continue
+		}
+		if err := fn(Match{Path: path, Rel: rel, Line: i + 1, Text: string(line)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isBinary reports whether data looks like a binary file
+func isBinary(data []byte) bool {
+	if len(data) > binaryProbeSize {
+		data = data[:binaryProbeSize]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func collect(t *testing.T, root, pattern string, opts Options) []string {
+	t.Helper()
+	var got []string
+	err := Dir(context.Background(), root, regexp.MustCompile(pattern), opts, func(m Match) error {
+		got = append(got, fmt.Sprintf("%s:%d:%s", filepath.ToSlash(m.Rel), m.Line, m.Text))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Dir() error = %v", err)
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestDir(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"main.go":             "package main\n\nfunc NewServer() {}\r\n",
+		"pkg/api/api.go":      "package api\n// NewServer wraps\n",
+		"README.md":           "NewServer docs\n",
+		"build/out.go":        "func NewServer() {}\n",
+		".gitignore":          "build/\n",
+		".hidden/secret.go":   "NewServer\n",
+		".git/config":         "NewServer\n",
+		"node_modules/x/x.js": "NewServer\n",
+		"image.bin":           "NewServer\x00\x01",
+	})
+
+	got := collect(t, root, `NewServer`, Options{})
+	want := []string{"README.md:1:NewServer docs", "main.go:3:func NewServer() {}", "pkg/api/api.go:2:// NewServer wraps"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("match %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// Globs restrict the searched files
+	if got := collect(t, root, `NewServer`, Options{Globs: []string{"*.md"}}); len(got) != 1 {
+		t.Errorf("expected 1 match with glob, got %v", got)
+	}
+
+	// Ignored and hidden files can be included
+	if got := collect(t, root, `NewServer`, Options{NoIgnore: true, IncludeHidden: true}); len(got) != 5 {
+		t.Errorf("expected 5 matches including ignored and hidden files, got %v", got)
+	}
+
+	// Oversized files are skipped
+	if got := collect(t, root, `NewServer`, Options{MaxFileSize: 10}); len(got) != 0 {
+		t.Errorf("expected no matches in small-file mode, got %v", got)
+	}
+}
+
+func TestDir_Cancelled(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"a.txt": "x\n"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Dir(ctx, root, regexp.MustCompile(`x`), Options{}, func(Match) error { return nil })
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
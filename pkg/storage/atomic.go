@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const lockFileName = ".lock"
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	// Clean up the temp file on any failure
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	success = true
+	return nil
+}
+
+// fileLock is an advisory lock on the storage directory shared between processes
+type fileLock struct {
+	file *os.File
+}
+
+// acquireLock takes the storage lock, blocking until it is available.
+// Exclusive locks are used for writes, shared locks for reads.
+func (s *Storage) acquireLock(exclusive bool) (*fileLock, error) {
+	f, err := os.OpenFile(filepath.Join(s.basePath, lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock storage: %w", err)
+	}
+
+	return &fileLock{file: f}, nil
+}
+
+// release unlocks and closes the lock file
+func (l *fileLock) release() {
+	unlockFile(l.file)
+	l.file.Close()
+}
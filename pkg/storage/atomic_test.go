@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "data.json")
+
+	if err := writeFileAtomic(path, []byte("first"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("second"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("expected 'second', got %q", data)
+	}
+
+	// No temp files should be left behind
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name()
+		}
+		t.Errorf("expected only data.json, got %v", names)
+	}
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "data.json")
+	if err := writeFileAtomic(path, []byte("x"), 0644); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestStorage_ConcurrentSaves(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Separate Storage instances simulate separate processes sharing the directory
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store, err := NewStorage(tmpDir)
+			if err != nil {
+				t.Errorf("NewStorage failed: %v", err)
+				return
+			}
+
+			pl := models.NewProjectList(models.KindFavorite)
+			for j := 0; j <= i; j++ {
+				pl.Add(models.NewProject(fmt.Sprintf("p%d-%d", i, j), fmt.Sprintf("/p/%d/%d", i, j)))
+			}
+			if err := store.SaveProjects(pl); err != nil {
+				t.Errorf("SaveProjects failed: %v", err)
+			}
+			if _, err := store.LoadProjects(); err != nil {
+				t.Errorf("LoadProjects failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The final file must be one complete write
+	data, err := os.ReadFile(filepath.Join(tmpDir, "projects.json"))
+	if err != nil {
+		t.Fatalf("failed to read projects file: %v", err)
+	}
+	var file projectsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatalf("projects file is corrupt: %v", err)
+	}
+}
+
+func TestStorage_ConcurrentUpdates(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Each update adds one project; none may be lost to another's save
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store, err := NewStorage(tmpDir)
+			if err != nil {
+				t.Errorf("NewStorage failed: %v", err)
+				return
+			}
+			err = store.Update(func(projects *models.ProjectList) error {
+				projects.Add(models.NewProject(fmt.Sprintf("p%d", i), fmt.Sprintf("/p/%d", i)))
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Update failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	store, _ := NewStorage(tmpDir)
+	projects, err := store.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects failed: %v", err)
+	}
+	if len(projects.Projects) != 10 {
+		t.Errorf("expected 10 projects, got %d", len(projects.Projects))
+	}
+}
+
+func TestStorage_UpdateNotSaved(t *testing.T) {
+	store, _ := NewStorage(t.TempDir())
+
+	errFailed := fmt.Errorf("failed")
+	for _, want := range []error{ErrNoChanges, errFailed} {
+		err := store.Update(func(projects *models.ProjectList) error {
+			projects.Add(models.NewProject("api", "/p/api"))
+			return want
+		})
+		if want == ErrNoChanges && err != nil {
+			t.Errorf("expected nil for ErrNoChanges, got %v", err)
+		}
+		if want == errFailed && err != errFailed {
+			t.Errorf("expected the function's error, got %v", err)
+		}
+	}
+	if _, err := os.Stat(store.GetProjectsPath()); !os.IsNotExist(err) {
+		t.Errorf("expected projects.json not to be written, got %v", err)
+	}
+}
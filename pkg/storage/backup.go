@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// backupDirName holds the automatic backups of projects.json
+	backupDirName = "backups"
+
+	// autoBackupPrefix starts the names of automatic backups
+	autoBackupPrefix = "projects-"
+
+	// restoreBackupPrefix starts the names of the archives of the files
+	// replaced by a restore
+	restoreBackupPrefix = "before-restore-"
+
+	// backupTimeFormat timestamps backup file names so they sort by age
+	backupTimeFormat = "20060102-150405.000000000"
+)
+
+// backupFiles are the storage files included in a backup, when present.
+// Caches that are rebuilt on their own, the undo log and lock files are
+// left out.
+var backupFiles = []string{
+	projectsFileName,
+	cacheFileName,
+	workspacesFileName,
+	sessionsFileName,
+	archivedFileName,
+	historyFileName,
+	opensFileName,
+	contextsFileName,
+}
+
+// backupKinds are the name prefixes and extensions of the automatic
+// backups: copies of projects.json and archives written before restoring
+var backupKinds = []struct{ prefix, ext string }{
+	{autoBackupPrefix, ".json"},
+	{restoreBackupPrefix, ".tar.gz"},
+}
+
+// SetBackupCount sets how many automatic backups of projects.json, and of
+// archives written before restoring, are kept; 0 turns the copies of
+// projects.json off and keeps every archive
+func (s *Storage) SetBackupCount(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backupCount = n
+}
+
+// GetBackupDir returns the directory holding automatic backups
+func (s *Storage) GetBackupDir() string {
+	return filepath.Join(s.basePath, backupDirName)
+}
+
+// RestoreBackupPath returns the path of the archive to write the current
+// files to before restoring a backup at the given time
+func (s *Storage) RestoreBackupPath(at time.Time) string {
+	return filepath.Join(s.GetBackupDir(), restoreBackupPrefix+at.Format(backupTimeFormat)+".tar.gz")
+}
+
+// ListBackups returns the paths of the automatic backups of both kinds,
+// oldest first
+func (s *Storage) ListBackups() ([]string, error) {
+	var backups []string
+	for _, kind := range backupKinds {
+		found, err := s.listBackups(kind.prefix, kind.ext)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, found...)
+	}
+	// Names of both kinds end in a timestamp of the same length
+	sort.Slice(backups, func(i, j int) bool {
+		return backupTime(backups[i]) < backupTime(backups[j])
+	})
+	return backups, nil
+}
+
+// listBackups returns the paths of the automatic backups of one kind,
+// oldest first
+func (s *Storage) listBackups(prefix, ext string) ([]string, error) {
+	entries, err := os.ReadDir(s.GetBackupDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) && strings.HasSuffix(e.Name(), ext) {
+			backups = append(backups, filepath.Join(s.GetBackupDir(), e.Name()))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// backupTime returns the name of an automatic backup without its prefix,
+// which starts with its timestamp
+func backupTime(path string) string {
+	name := filepath.Base(path)
+	for _, kind := range backupKinds {
+		if rest, ok := strings.CutPrefix(name, kind.prefix); ok {
+			return rest
+		}
+	}
+	return name
+}
+
+// PruneBackups removes the oldest automatic backups of each kind beyond
+// the backup count
+func (s *Storage) PruneBackups() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pruneBackups()
+}
+
+func (s *Storage) pruneBackups() error {
+	if s.backupCount <= 0 {
+		return nil
+	}
+	for _, kind := range backupKinds {
+		backups, err := s.listBackups(kind.prefix, kind.ext)
+		if err != nil {
+			return err
+		}
+		for len(backups) > s.backupCount {
+			if err := os.Remove(backups[0]); err != nil {
+				return fmt.Errorf("failed to remove old backup: %w", err)
+			}
+			backups = backups[1:]
+		}
+	}
+	return nil
+}
+
+// WriteBackup writes the config file at configPath and the storage files
+// to w as a gzipped tar archive, returning the names of the files written
+func (s *Storage) WriteBackup(w io.Writer, configPath string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	sources := make(map[string]string)
+	names := []string{}
+	if configPath != "" {
+		names = append(names, filepath.Base(configPath))
+		sources[filepath.Base(configPath)] = configPath
+	}
+	for _, name := range backupFiles {
+		names = append(names, name)
+		sources[name] = filepath.Join(s.basePath, name)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	var written []string
+	for _, name := range names {
+		data, err := os.ReadFile(sources[name])
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+		written = append(written, name)
+	}
+	if err := errors.Join(tw.Close(), gz.Close()); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return written, nil
+}
+
+// ReadBackup reads a backup written by WriteBackup, or a single
+// projects.json such as an automatic backup, into file contents keyed by
+// file name. Files a backup cannot contain are rejected.
+func ReadBackup(r io.Reader) (map[string][]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		// Not gzipped: a plain projects.json
+		return map[string][]byte{projectsFileName: data}, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !isBackupFile(hdr.Name) {
+			return nil, fmt.Errorf("unexpected file in backup: %s", hdr.Name)
+		}
+		if files[hdr.Name], err = io.ReadAll(tr); err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("backup is empty")
+	}
+	return files, nil
+}
+
+// IsConfigFile reports whether a backup file name is the config file
+func IsConfigFile(name string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	return strings.TrimSuffix(name, filepath.Ext(name)) == "config" &&
+		(ext == "json" || ext == "yaml" || ext == "yml" || ext == "toml")
+}
+
+// isBackupFile reports whether name may appear in a backup
+func isBackupFile(name string) bool {
+	if IsConfigFile(name) {
+		return true
+	}
+	for _, f := range backupFiles {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore writes the files read by ReadBackup back into the storage
+// directory, and the config file into configDir. The replaced
+// projects.json is backed up and recorded in the undo log first.
+func (s *Storage) Restore(files map[string][]byte, configDir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	previous, err := os.ReadFile(s.GetProjectsPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read projects file: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !isBackupFile(name) {
+			return fmt.Errorf("unexpected file in backup: %s", name)
+		}
+		dir := s.basePath
+		if IsConfigFile(name) {
+			dir = configDir
+		}
+		if err := writeFileAtomic(filepath.Join(dir, name), files[name], 0644); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+		if name == projectsFileName || name == cacheFileName {
+			s.keepLastGood(filepath.Join(dir, name), files[name])
+		}
+	}
+
+	if _, ok := files[projectsFileName]; ok {
+		s.afterProjectsWrite(previous, files[projectsFileName])
+	}
+	return nil
+}
+
+// afterProjectsWrite keeps the projects.json that was just replaced in
+// the undo log and the automatic backups. Both are conveniences, so
+// failures are not reported. The caller holds the storage lock.
+func (s *Storage) afterProjectsWrite(previous, current []byte) {
+	now := time.Now()
+	_ = s.recordUndo(previous, now)
+	if previous != nil && !bytes.Equal(previous, current) {
+		_ = s.autoBackup(previous, now)
+	}
+}
+
+// autoBackup saves data as a new automatic backup and removes the oldest
+// ones beyond the backup count
+func (s *Storage) autoBackup(data []byte, at time.Time) error {
+	if s.backupCount <= 0 {
+		return nil
+	}
+	if err := os.MkdirAll(s.GetBackupDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	name := autoBackupPrefix + at.Format(backupTimeFormat) + ".json"
+	if err := writeFileAtomic(filepath.Join(s.GetBackupDir(), name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return s.pruneBackups()
+}
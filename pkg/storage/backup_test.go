@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestStorage_BackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewStorage(dir)
+	configPath := filepath.Join(dir, "config.json")
+	os.WriteFile(configPath, []byte(`{"editor": "vim"}`), 0644)
+
+	pl := models.NewProjectList(models.KindFavorite)
+	pl.Add(models.NewProject("api", "/work/api"))
+	if err := store.SaveProjects(pl); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	names, err := store.WriteBackup(&buf, configPath)
+	if err != nil {
+		t.Fatalf("WriteBackup failed: %v", err)
+	}
+	if len(names) != 2 || names[0] != "config.json" || names[1] != "projects.json" {
+		t.Errorf("expected config.json and projects.json in the backup, got %v", names)
+	}
+
+	// Change everything, then restore
+	pl.Add(models.NewProject("web", "/work/web"))
+	store.SaveProjects(pl)
+	os.WriteFile(configPath, []byte(`{"editor": "code"}`), 0644)
+
+	files, err := ReadBackup(&buf)
+	if err != nil {
+		t.Fatalf("ReadBackup failed: %v", err)
+	}
+	if err := store.Restore(files, dir); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if loaded, _ := store.LoadProjects(); len(loaded.Projects) != 1 {
+		t.Errorf("expected the backed up project only, got %v", loaded.Projects)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != `{"editor": "vim"}` {
+		t.Errorf("expected the backed up config, got %s", data)
+	}
+
+	// The restore can be undone
+	if _, err := store.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := store.LoadProjects(); len(loaded.Projects) != 2 {
+		t.Errorf("expected both projects after undoing the restore, got %v", loaded.Projects)
+	}
+}
+
+func TestReadBackup(t *testing.T) {
+	files, err := ReadBackup(bytes.NewReader([]byte(`[]`)))
+	if err != nil || string(files["projects.json"]) != "[]" || len(files) != 1 {
+		t.Errorf("expected a plain file read as projects.json, got %v, %v", files, err)
+	}
+
+	if !IsConfigFile("config.yaml") || IsConfigFile("config.txt") || IsConfigFile("../config.json") {
+		t.Error("unexpected IsConfigFile result")
+	}
+	if isBackupFile("undo.json") || isBackupFile("../projects.json") {
+		t.Error("expected only storage and config files in backups")
+	}
+}
+
+func TestStorage_AutoBackups(t *testing.T) {
+	store, _ := NewStorage(t.TempDir())
+	store.SetBackupCount(2)
+
+	pl := models.NewProjectList(models.KindFavorite)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		pl.Add(models.NewProject(name, "/work/"+name))
+		if err := store.SaveProjects(pl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Saving the same list again is not a change worth a backup
+	store.SaveProjects(pl)
+
+	backups, err := store.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups kept, got %v", backups)
+	}
+	files, _ := os.Open(backups[1])
+	defer files.Close()
+	restored, err := ReadBackup(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Restore(restored, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := store.LoadProjects(); len(loaded.Projects) != 3 {
+		t.Errorf("expected the newest backup to hold a, b and c, got %v", loaded.Projects)
+	}
+
+	store.SetBackupCount(0)
+	pl.Remove("a")
+	store.SaveProjects(pl)
+	if after, _ := store.ListBackups(); len(after) != 2 {
+		t.Errorf("expected no new backups with a count of 0, got %v", after)
+	}
+}
+
+func TestStorage_RestoreBackups(t *testing.T) {
+	store, _ := NewStorage(t.TempDir())
+	store.SetBackupCount(2)
+	os.MkdirAll(store.GetBackupDir(), 0755)
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		os.WriteFile(store.RestoreBackupPath(start.Add(time.Duration(i)*time.Hour)), nil, 0600)
+	}
+	if err := store.autoBackup([]byte("[]"), start.Add(90*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PruneBackups(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := store.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, b := range backups {
+		names = append(names, filepath.Base(b))
+	}
+	want := []string{
+		"before-restore-20240501-130000.000000000.tar.gz",
+		"projects-20240501-133000.000000000.json",
+		"before-restore-20240501-140000.000000000.tar.gz",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("expected the newest archives and the backup by age, got %v", names)
+	}
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+const (
+	contextsFileName = "contexts.json"
+	contextsDirName  = "contexts"
+)
+
+// GetContextDir returns the directory holding the vim session files of
+// projects
+func (s *Storage) GetContextDir() string {
+	return filepath.Join(s.basePath, contextsDirName)
+}
+
+// SessionFilePath returns the vim session file kept for the project at
+// projectPath. The name combines the folder name with a hash of the path,
+// so projects with the same folder name do not share a session.
+func (s *Storage) SessionFilePath(projectPath string) string {
+	sum := sha256.Sum256([]byte(paths.Key(projectPath)))
+	name := fmt.Sprintf("%s-%s.vim", filepath.Base(projectPath), hex.EncodeToString(sum[:])[:12])
+	return filepath.Join(s.GetContextDir(), name)
+}
+
+// LoadContexts loads the saved context of each project, keyed by root path
+func (s *Storage) LoadContexts() (map[string]*models.ProjectContext, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	return s.loadContexts()
+}
+
+func (s *Storage) loadContexts() (map[string]*models.ProjectContext, error) {
+	contexts := make(map[string]*models.ProjectContext)
+	data, err := os.ReadFile(filepath.Join(s.basePath, contextsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return contexts, nil
+		}
+		return nil, fmt.Errorf("failed to read contexts file: %w", err)
+	}
+
+	var saved map[string]*models.ProjectContext
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse contexts file: %w", err)
+	}
+	for path, ctx := range saved {
+		if ctx.Session != "" {
+			ctx.Session = paths.ExpandHome(ctx.Session)
+		}
+		contexts[paths.ExpandHome(path)] = ctx
+	}
+	return contexts, nil
+}
+
+// SaveContext records the context of the project at projectPath; a nil
+// context forgets it
+func (s *Storage) SaveContext(projectPath string, ctx *models.ProjectContext) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	contexts, err := s.loadContexts()
+	if err != nil {
+		// A corrupt file only costs the contexts in it
+		contexts = make(map[string]*models.ProjectContext)
+	}
+	if ctx == nil {
+		delete(contexts, projectPath)
+	} else {
+		contexts[projectPath] = ctx
+	}
+
+	saveContexts := make(map[string]*models.ProjectContext, len(contexts))
+	for path, c := range contexts {
+		saved := *c
+		if saved.Session != "" {
+			saved.Session = paths.Collapse(saved.Session)
+		}
+		saveContexts[paths.Collapse(path)] = &saved
+	}
+	data, err := json.MarshalIndent(saveContexts, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize contexts: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, contextsFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write contexts file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestStorage_SaveContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	home, _ := os.UserHomeDir()
+	api := filepath.Join(home, "code", "api")
+	session := store.SessionFilePath(api)
+	if filepath.Dir(session) != store.GetContextDir() || !strings.HasPrefix(filepath.Base(session), "api-") {
+		t.Errorf("unexpected session file %s", session)
+	}
+	if other := store.SessionFilePath("/srv/api"); other == session {
+		t.Error("expected projects with the same folder name to get different session files")
+	}
+
+	saved := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.SaveContext(api, &models.ProjectContext{Branch: "feature", Session: session, SavedAt: saved}); err != nil {
+		t.Fatalf("SaveContext failed: %v", err)
+	}
+	store.SaveContext("/srv/web", &models.ProjectContext{Branch: "main", SavedAt: saved})
+
+	contexts, err := store.LoadContexts()
+	if err != nil {
+		t.Fatalf("LoadContexts failed: %v", err)
+	}
+	ctx := contexts[api]
+	if len(contexts) != 2 || ctx == nil || ctx.Branch != "feature" || ctx.Session != session || !ctx.SavedAt.Equal(saved) {
+		t.Errorf("unexpected contexts: %v", contexts)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "contexts.json"))
+	if !strings.Contains(string(data), "~/code/api") {
+		t.Errorf("expected collapsed path in file, got:\n%s", data)
+	}
+
+	store.SaveContext("/srv/web", nil)
+	if contexts, _ := store.LoadContexts(); len(contexts) != 1 || contexts["/srv/web"] != nil {
+		t.Errorf("expected /srv/web to be forgotten, got %v", contexts)
+	}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	encryptionCipher = "aes-256-gcm"
+	encryptionKDF    = "pbkdf2-sha256"
+
+	// kdfIterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	kdfIterations = 600000
+)
+
+// ErrNoEncryptionKey is returned when reading an encrypted file without a key
+var ErrNoEncryptionKey = errors.New("projects file is encrypted but no encryption key is set")
+
+// encryptedFile is the on-disk envelope of an encrypted file. Each save
+// uses a fresh nonce; the salt of the file read is kept so the slow key
+// derivation runs once per command.
+type encryptedFile struct {
+	Encrypted  string `json:"encrypted"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// encryption holds the passphrase for encrypted files and whether saves
+// should encrypt. Derived keys are cached per salt, since deriving one is
+// deliberately slow.
+type encryption struct {
+	passphrase string
+	enabled    bool
+
+	mu   sync.Mutex
+	keys map[string][]byte
+	salt []byte // salt of the last file opened, reused when sealing
+}
+
+// SetEncryption sets the passphrase used to read encrypted projects files
+// and, with enabled set, makes SaveProjects and SaveArchived encrypt
+// projects.json and archived.json with AES-256-GCM. Plaintext files are still read, so enabling encryption
+// takes effect the next time projects are saved.
+func (s *Storage) SetEncryption(passphrase string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encryption = &encryption{passphrase: passphrase, enabled: enabled}
+}
+
+// key derives the AES key for salt from the passphrase
+func (e *encryption) key(salt []byte, iterations int) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := fmt.Sprintf("%x/%d", salt, iterations)
+	if k, ok := e.keys[id]; ok {
+		return k, nil
+	}
+	k, err := pbkdf2.Key(sha256.New, e.passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	if e.keys == nil {
+		e.keys = make(map[string][]byte)
+	}
+	e.keys[id] = k
+	return k, nil
+}
+
+// seal encrypts data into an envelope when encryption is enabled, and
+// returns data unchanged otherwise
+func (e *encryption) seal(data []byte) ([]byte, error) {
+	if e == nil || !e.enabled {
+		return data, nil
+	}
+	if e.passphrase == "" {
+		return nil, fmt.Errorf("encryption is enabled but no encryption key is set")
+	}
+
+	e.mu.Lock()
+	salt := e.salt
+	e.mu.Unlock()
+	if salt == nil {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+	}
+	key, err := e.key(salt, kdfIterations)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(encryptedFile{
+		Encrypted:  encryptionCipher,
+		KDF:        encryptionKDF,
+		Iterations: kdfIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Data:       gcm.Seal(nil, nonce, data, nil),
+	}, "", "    ")
+}
+
+// open returns the plaintext of data, decrypting it if it is an envelope
+func (e *encryption) open(data []byte) ([]byte, error) {
+	var env encryptedFile
+	if json.Unmarshal(data, &env) != nil || env.Encrypted == "" {
+		// Plaintext files hold a JSON array, which is not an envelope
+		return data, nil
+	}
+	if e == nil || e.passphrase == "" {
+		return nil, ErrNoEncryptionKey
+	}
+	if env.Encrypted != encryptionCipher || env.KDF != encryptionKDF || env.Iterations < 1 {
+		return nil, fmt.Errorf("unsupported encryption %s with %s", env.Encrypted, env.KDF)
+	}
+
+	key, err := e.key(env.Salt, env.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce in encrypted file")
+	}
+	plain, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt (wrong encryption key?)")
+	}
+	if env.Iterations == kdfIterations {
+		e.mu.Lock()
+		e.salt = env.Salt
+		e.mu.Unlock()
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
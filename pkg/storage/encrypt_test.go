@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestStorage_EncryptedProjects(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	// Plaintext files stay readable once encryption is enabled
+	plain := models.NewProjectList(models.KindFavorite)
+	plain.Add(models.NewProject("acme-client", "/srv/acme"))
+	if err := store.SaveProjects(plain); err != nil {
+		t.Fatalf("SaveProjects failed: %v", err)
+	}
+
+	store.SetEncryption("correct horse", true)
+	projects, err := store.LoadProjects()
+	if err != nil || len(projects.Projects) != 1 {
+		t.Fatalf("expected plaintext projects to load, got %v, %v", projects, err)
+	}
+	if err := store.SaveProjects(projects); err != nil {
+		t.Fatalf("SaveProjects failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(store.GetProjectsPath())
+	if strings.Contains(string(data), "acme") || !strings.Contains(string(data), encryptionCipher) {
+		t.Errorf("expected encrypted projects file, got:\n%s", data)
+	}
+
+	loaded, err := store.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects failed: %v", err)
+	}
+	if len(loaded.Projects) != 1 || loaded.Projects[0].Name != "acme-client" || loaded.Projects[0].RootPath != "/srv/acme" {
+		t.Errorf("unexpected decrypted projects: %+v", loaded.Projects)
+	}
+
+	other, _ := NewStorage(tmpDir)
+	if _, err := other.LoadProjects(); !errors.Is(err, ErrNoEncryptionKey) {
+		t.Errorf("expected ErrNoEncryptionKey without a key, got %v", err)
+	}
+	other.SetEncryption("wrong", true)
+	if _, err := other.LoadProjects(); err == nil {
+		t.Error("expected error with the wrong key")
+	}
+
+	// Disabling encryption writes plaintext on the next save
+	store.SetEncryption("correct horse", false)
+	if err := store.SaveProjects(loaded); err != nil {
+		t.Fatalf("SaveProjects failed: %v", err)
+	}
+	data, _ = os.ReadFile(store.GetProjectsPath())
+	if !strings.Contains(string(data), "acme-client") {
+		t.Errorf("expected plaintext after disabling encryption, got:\n%s", data)
+	}
+}
+
+func TestStorage_EncryptedArchived(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+	store.SetEncryption("correct horse", true)
+
+	archived := []*models.ArchivedProject{{
+		Project:  models.NewProject("acme-client", "/srv/acme"),
+		Kind:     models.KindFavorite,
+		Location: "/archive/acme-client",
+	}}
+	if err := store.SaveArchived(archived); err != nil {
+		t.Fatalf("SaveArchived failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, archivedFileName))
+	if strings.Contains(string(data), "acme") || !strings.Contains(string(data), encryptionCipher) {
+		t.Errorf("expected encrypted archived file, got:\n%s", data)
+	}
+
+	loaded, err := store.LoadArchived()
+	if err != nil {
+		t.Fatalf("LoadArchived failed: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Project.Name != "acme-client" || loaded[0].Location != "/archive/acme-client" {
+		t.Errorf("unexpected decrypted archived projects: %+v", loaded)
+	}
+
+	other, _ := NewStorage(tmpDir)
+	if _, err := other.LoadArchived(); !errors.Is(err, ErrNoEncryptionKey) {
+		t.Errorf("expected ErrNoEncryptionKey without a key, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ideaspaper/projector/pkg/models"
+	"github.com/ideaspaper/projector/pkg/paths"
+)
+
+const (
+	// lastGoodSuffix names the copy of the last successful write of
+	// projects.json and cache.json, which Repair can restore
+	lastGoodSuffix = ".bak"
+
+	// corruptSuffix names the copy Repair keeps of a file it replaces
+	corruptSuffix = ".corrupt"
+)
+
+// RepairFiles are the storage files Diagnose checks and Repair fixes
+var RepairFiles = []string{projectsFileName, cacheFileName}
+
+// RepairSource is where Repair takes the projects of a broken file from
+type RepairSource int
+
+const (
+	// RepairFromBackup restores the copy of the last good write
+	RepairFromBackup RepairSource = iota
+	// RepairBySalvage keeps the projects that can still be read from the
+	// broken file
+	RepairBySalvage
+)
+
+// Diagnosis tells whether a storage file can be read and, if not, how
+// many projects Repair could recover from each source
+type Diagnosis struct {
+	File string
+	Err  error // Why the file cannot be parsed; nil when it is fine or absent
+
+	// BackupProjects is the number of projects in the copy of the last
+	// good write, or -1 when there is no readable copy
+	BackupProjects int
+	// SalvageProjects is the number of projects that can be read from the
+	// broken file
+	SalvageProjects int
+}
+
+// keepLastGood saves data, just written to path, as the copy Repair
+// restores. It is a convenience, so failures are not reported.
+func (s *Storage) keepLastGood(path string, data []byte) {
+	_ = writeFileAtomic(path+lastGoodSuffix, data, 0644)
+}
+
+// Diagnose checks file, one of RepairFiles
+func (s *Storage) Diagnose(file string) (*Diagnosis, error) {
+	if !slices.Contains(RepairFiles, file) {
+		return nil, fmt.Errorf("cannot repair %s", file)
+	}
+	d := &Diagnosis{File: file, BackupProjects: -1}
+	path := filepath.Join(s.basePath, file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return d, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if _, d.Err = s.parseFile(file, data); d.Err == nil {
+		return d, nil
+	}
+
+	if backup, err := os.ReadFile(path + lastGoodSuffix); err == nil {
+		if buckets, err := s.parseFile(file, backup); err == nil {
+			d.BackupProjects = countProjects(buckets)
+		}
+	}
+	if plain, err := s.encryption.open(data); err == nil {
+		d.SalvageProjects = countProjects(salvageProjects(plain))
+	}
+	return d, nil
+}
+
+// Repair replaces file, one of RepairFiles, with the projects recovered
+// from source and returns how many there are. The broken file is kept
+// next to it with a .corrupt suffix.
+func (s *Storage) Repair(file string, source RepairSource) (int, error) {
+	if !slices.Contains(RepairFiles, file) {
+		return 0, fmt.Errorf("cannot repair %s", file)
+	}
+	path := filepath.Join(s.basePath, file)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var buckets map[string][]*models.Project
+	switch source {
+	case RepairFromBackup:
+		backup, err := os.ReadFile(path + lastGoodSuffix)
+		if err != nil {
+			return 0, fmt.Errorf("no backup of %s to restore: %w", file, err)
+		}
+		if buckets, err = s.parseFile(file, backup); err != nil {
+			return 0, fmt.Errorf("the backup of %s cannot be read either: %w", file, err)
+		}
+	case RepairBySalvage:
+		plain, err := s.encryption.open(data)
+		if err != nil {
+			return 0, fmt.Errorf("cannot salvage %s: %w", file, err)
+		}
+		buckets = salvageProjects(plain)
+	}
+
+	if err := writeFileAtomic(path+corruptSuffix, data, 0644); err != nil {
+		return 0, fmt.Errorf("failed to keep a copy of %s: %w", file, err)
+	}
+	for _, projects := range buckets {
+		for _, p := range projects {
+			p.RootPath = paths.ExpandHome(p.RootPath)
+		}
+	}
+	if file == projectsFileName {
+		list := models.NewProjectList(models.KindFavorite)
+		list.Projects = buckets[""]
+		err = s.SaveProjects(list)
+	} else {
+		err = s.SaveCache(&CachedProjects{
+			Git:       buckets["git"],
+			SVN:       buckets["svn"],
+			Mercurial: buckets["mercurial"],
+			VSCode:    buckets["vscode"],
+			Any:       buckets["any"],
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+	return countProjects(buckets), nil
+}
+
+// parseFile parses the contents of file into its projects, keyed by cache
+// bucket ("" for projects.json)
+func (s *Storage) parseFile(file string, data []byte) (map[string][]*models.Project, error) {
+	plain, err := s.encryption.open(data)
+	if err != nil {
+		return nil, err
+	}
+	if file == projectsFileName {
+		projects, err := DecodeProjects(plain)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrProjectsCorrupt, err)
+		}
+		return map[string][]*models.Project{"": projects}, nil
+	}
+	var cache CachedProjects
+	if _, err := decodeVersioned(plain, "", cacheMigrations, &cacheFile{CachedProjects: &cache}); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCacheCorrupt, err)
+	}
+	return map[string][]*models.Project{
+		"git":       cache.Git,
+		"svn":       cache.SVN,
+		"mercurial": cache.Mercurial,
+		"vscode":    cache.VSCode,
+		"any":       cache.Any,
+	}, nil
+}
+
+// salvageProjects reads the projects that are still whole in broken JSON:
+// every object directly inside the top-level array of an unversioned
+// projects.json, or inside an array of the top-level object of
+// projects.json or cache.json, keyed by that array's name ("" for the
+// projects of projects.json). Objects that do not parse, or have no name or path, are
+// skipped, and reading stops at the first object left unterminated.
+func salvageProjects(data []byte) map[string][]*models.Project {
+	found := make(map[string][]*models.Project)
+
+	// containers holds the open arrays and objects, each with the key it
+	// is the value of
+	type container struct {
+		array bool
+		key   string
+	}
+	var containers []container
+	var lastString, key string
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			end := stringEnd(data, i)
+			if end < 0 {
+				return found
+			}
+			lastString = string(data[i+1 : end])
+			i = end
+		case ':':
+			key = lastString
+		case ',':
+			key = ""
+		case '[':
+			containers = append(containers, container{array: true, key: key})
+			key = ""
+		case '{':
+			depth := len(containers)
+			isProject := depth > 0 && containers[depth-1].array &&
+				(depth == 1 || depth == 2 && !containers[0].array)
+			if !isProject {
+				containers = append(containers, container{key: key})
+				key = ""
+				continue
+			}
+			end := objectEnd(data, i)
+			if end < 0 {
+				return found
+			}
+			var p models.Project
+			if json.Unmarshal(data[i:end+1], &p) == nil && p.Name != "" && p.RootPath != "" {
+				bucket := containers[depth-1].key
+				if bucket == "projects" {
+					bucket = ""
+				}
+				found[bucket] = append(found[bucket], &p)
+			}
+			i = end
+		case ']', '}':
+			if len(containers) > 0 {
+				containers = containers[:len(containers)-1]
+			}
+		}
+	}
+	return found
+}
+
+// stringEnd returns the index of the quote closing the string starting
+// at data[start], or -1 when it is unterminated
+func stringEnd(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// objectEnd returns the index of the brace closing the object starting at
+// data[start], or -1 when it is unterminated
+func objectEnd(data []byte, start int) int {
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			if i = stringEnd(data, i); i < 0 {
+				return -1
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func countProjects(buckets map[string][]*models.Project) int {
+	n := 0
+	for _, projects := range buckets {
+		n += len(projects)
+	}
+	return n
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestSalvageProjects(t *testing.T) {
+	projects := []byte(`[
+    {"name": "api", "rootPath": "/work/api", "tags": ["Go", "a]b{"]},
+    {"name": "broken", "rootPath": 42},
+    {"name": "web", "rootPath": "/work/web"},
+    {"name": "trunc`)
+	found := salvageProjects(projects)
+	if len(found[""]) != 2 || found[""][0].Name != "api" || found[""][1].Name != "web" {
+		t.Errorf("salvaged %v, want api and web", found[""])
+	}
+
+	versioned := []byte(`{"version": 1, "projects": [{"name": "api", "rootPath": "/work/api"}, {"na`)
+	if found = salvageProjects(versioned); len(found[""]) != 1 || found[""][0].Name != "api" {
+		t.Errorf("salvaged %v from a versioned file, want api", found)
+	}
+
+	cache := []byte(`{"git": [{"name": "a", "rootPath": "/a"}, {"name": "b", "rootPath": "/b"}],
+    "scannedAt": {"git": "2024-05-01T00:00:00Z"},
+    "any": [{"name": "c", "rootPath": "/c"}, {"name": "d", "rootP`)
+	found = salvageProjects(cache)
+	if len(found["git"]) != 2 || len(found["any"]) != 1 || found["any"][0].Name != "c" {
+		t.Errorf("salvaged %v, want a and b in git and c in any", found)
+	}
+}
+
+func TestStorage_Repair(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+	list := models.NewProjectList(models.KindFavorite)
+	for _, name := range []string{"api", "web", "docs"} {
+		list.Add(models.NewProject(name, filepath.Join(tmpDir, name)))
+	}
+	if err := store.SaveProjects(list); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut the file off in the middle of the last project
+	path := store.GetProjectsPath()
+	data, _ := os.ReadFile(path)
+	broken := data[:len(data)-40]
+	if err := os.WriteFile(path, broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.LoadProjects(); !errors.Is(err, ErrProjectsCorrupt) {
+		t.Fatalf("LoadProjects() error = %v, want ErrProjectsCorrupt", err)
+	}
+
+	d, err := store.Diagnose(projectsFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(d.Err, ErrProjectsCorrupt) || d.BackupProjects != 3 || d.SalvageProjects != 2 {
+		t.Errorf("Diagnose() = %+v, want a corrupt file with 3 projects in the backup and 2 to salvage", d)
+	}
+
+	if n, err := store.Repair(projectsFileName, RepairBySalvage); err != nil || n != 2 {
+		t.Fatalf("Repair(salvage) = %d, %v", n, err)
+	}
+	loaded, err := store.LoadProjects()
+	if err != nil || len(loaded.Projects) != 2 {
+		t.Fatalf("expected the 2 salvaged projects, got %v, %v", loaded, err)
+	}
+	if kept, _ := os.ReadFile(path + corruptSuffix); string(kept) != string(broken) {
+		t.Error("expected the broken file to be kept")
+	}
+
+	// The salvaged file is now the last good write
+	if n, err := store.Repair(projectsFileName, RepairFromBackup); err != nil || n != 2 {
+		t.Errorf("Repair(backup) = %d, %v, want the 2 projects last saved", n, err)
+	}
+}
+
+func TestStorage_RepairFromBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+	cache := &CachedProjects{Git: []*models.Project{{Name: "a", RootPath: "/a", Enabled: true}}}
+	if err := store.SaveCache(cache); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.GetCachePath(), []byte("\x00\x00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if d, _ := store.Diagnose(cacheFileName); d.BackupProjects != 1 || d.SalvageProjects != 0 {
+		t.Errorf("Diagnose() = %+v, want 1 project in the backup", d)
+	}
+	if _, err := store.Repair(cacheFileName, RepairFromBackup); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := store.LoadCache()
+	if err != nil || len(loaded.Git) != 1 || loaded.Git[0].Kind != models.KindGit {
+		t.Errorf("expected the cache from the backup, got %v, %v", loaded, err)
+	}
+
+	if err := store.ClearCache(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(store.GetCachePath() + lastGoodSuffix); !os.IsNotExist(err) {
+		t.Error("expected clearing the cache to remove its backup")
+	}
+}
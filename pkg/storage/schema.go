@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+const (
+	// ProjectsVersion is the schema version of the projects.json this
+	// build writes. Files without a version hold a bare array of projects.
+	ProjectsVersion = 1
+
+	// CacheVersion is the schema version of the cache.json this build
+	// writes
+	CacheVersion = 1
+)
+
+// ErrNewerSchema is wrapped by the error SaveProjects returns when
+// projects.json was written by a newer version of projector. Saving it
+// would drop the fields this version does not know.
+var ErrNewerSchema = errors.New("written by a newer version of projector")
+
+// migration upgrades the decoded contents of a storage file by one
+// schema version
+type migration func(doc map[string]any) error
+
+// projectsMigrations[i] upgrades projects.json from version i to i+1, so
+// there are ProjectsVersion of them
+var projectsMigrations = []migration{
+	migrateProjectsV0,
+}
+
+// cacheMigrations[i] upgrades cache.json from version i to i+1, so there
+// are CacheVersion of them
+var cacheMigrations = []migration{
+	migrateCacheV0,
+}
+
+// projectsFile is the layout of projects.json
+type projectsFile struct {
+	Version  int               `json:"version"`
+	Projects []*models.Project `json:"projects"`
+}
+
+// cacheFile is the layout of cache.json
+type cacheFile struct {
+	Version int `json:"version"`
+	*CachedProjects
+}
+
+// migrateProjectsV0 handles the unversioned files of earlier releases and
+// of the VS Code Project Manager extension, whose projects left out
+// "enabled" before it could be turned off: such projects are enabled.
+func migrateProjectsV0(doc map[string]any) error {
+	projects, _ := doc["projects"].([]any)
+	for _, item := range projects {
+		p, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("project is not an object: %v", item)
+		}
+		if _, ok := p["enabled"]; !ok {
+			p["enabled"] = true
+		}
+	}
+	return nil
+}
+
+// migrateCacheV0 handles the unversioned cache of earlier releases, which
+// has the same layout
+func migrateCacheV0(doc map[string]any) error {
+	return nil
+}
+
+// DecodeProjects parses the contents of a projects.json, of any schema
+// version, or of the projects file of the VS Code Project Manager
+// extension. Paths are returned as written.
+func DecodeProjects(data []byte) ([]*models.Project, error) {
+	var file projectsFile
+	if _, err := decodeVersioned(data, "projects", projectsMigrations, &file); err != nil {
+		return nil, err
+	}
+	return file.Projects, nil
+}
+
+// IsProjectsFile reports whether data looks like a projects.json, rather
+// than an export document: a bare array, or an object with projects.
+func IsProjectsFile(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		return true
+	}
+	var probe struct {
+		Projects json.RawMessage `json:"projects"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Projects != nil
+}
+
+// decodeVersioned unmarshals data, a storage file whose upgrades are
+// migrations, into v and returns the version it was written with. Older
+// versions are upgraded first; a bare array is version 0 and becomes the
+// value of arrayKey, when the file has one. Newer versions are read as
+// far as this build knows them.
+func decodeVersioned(data []byte, arrayKey string, migrations []migration, v any) (int, error) {
+	version, err := schemaVersion(data)
+	if err != nil {
+		return 0, err
+	}
+	if version >= len(migrations) {
+		return version, json.Unmarshal(data, v)
+	}
+
+	var doc map[string]any
+	if version == 0 && arrayKey != "" && bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var items []any
+		if err := json.Unmarshal(data, &items); err != nil {
+			return 0, err
+		}
+		doc = map[string]any{arrayKey: items}
+	} else if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, err
+	}
+
+	for from := version; from < len(migrations); from++ {
+		if err := migrations[from](doc); err != nil {
+			return 0, fmt.Errorf("failed to upgrade from version %d: %w", from, err)
+		}
+	}
+	doc["version"] = len(migrations)
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return 0, err
+	}
+	return version, json.Unmarshal(upgraded, v)
+}
+
+// schemaVersion returns the version field of a storage file; bare arrays
+// and objects without one are version 0
+func schemaVersion(data []byte) (int, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return 0, nil
+	}
+	var head struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return 0, err
+	}
+	return head.Version, nil
+}
+
+// checkNotNewer refuses to replace the projects.json holding previous
+// when a newer version of projector wrote it. Unreadable contents are
+// left to Repair, so they do not block saving.
+func (s *Storage) checkNotNewer(previous []byte) error {
+	if previous == nil {
+		return nil
+	}
+	plain, err := s.encryption.open(previous)
+	if err != nil {
+		return nil
+	}
+	if version, err := schemaVersion(plain); err == nil && version > ProjectsVersion {
+		return fmt.Errorf("cannot save %s: %w (schema version %d, this version writes %d); upgrade projector",
+			s.GetProjectsPath(), ErrNewerSchema, version, ProjectsVersion)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestMigrations_MatchVersions(t *testing.T) {
+	if len(projectsMigrations) != ProjectsVersion {
+		t.Errorf("%d projects migrations for version %d", len(projectsMigrations), ProjectsVersion)
+	}
+	if len(cacheMigrations) != CacheVersion {
+		t.Errorf("%d cache migrations for version %d", len(cacheMigrations), CacheVersion)
+	}
+}
+
+func TestDecodeProjects(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []bool // Enabled of each project
+	}{
+		{"unversioned", `[{"name": "a", "rootPath": "/a", "enabled": false}, {"name": "b", "rootPath": "/b", "enabled": true}]`, []bool{false, true}},
+		{"vscode extension", `[{"name": "a", "rootPath": "$home/a", "paths": [], "tags": []}]`, []bool{true}},
+		{"current", `{"version": 1, "projects": [{"name": "a", "rootPath": "/a"}]}`, []bool{false}},
+		{"newer", `{"version": 99, "projects": [{"name": "a", "rootPath": "/a", "enabled": true}], "future": 1}`, []bool{true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, err := DecodeProjects([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("DecodeProjects failed: %v", err)
+			}
+			if len(projects) != len(tt.want) {
+				t.Fatalf("got %d projects, want %d", len(projects), len(tt.want))
+			}
+			for i, p := range projects {
+				if p.Enabled != tt.want[i] {
+					t.Errorf("%s: enabled = %v, want %v", p.Name, p.Enabled, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := DecodeProjects([]byte(`{"version": "1"}`)); err == nil {
+		t.Error("expected an error for a version that is not a number")
+	}
+}
+
+func TestStorage_UpgradesUnversionedFiles(t *testing.T) {
+	store, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(store.GetProjectsPath(), []byte(`[{"name": "a", "rootPath": "/a"}]`), 0644)
+	os.WriteFile(store.GetCachePath(), []byte(`{"git": [{"name": "r", "rootPath": "/r", "enabled": true}]}`), 0644)
+
+	list, err := store.LoadProjects()
+	if err != nil || len(list.Projects) != 1 || !list.Projects[0].Enabled {
+		t.Fatalf("LoadProjects = %v, %v; want the enabled project", list, err)
+	}
+	if err := store.SaveProjects(list); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(store.GetProjectsPath())
+	if !strings.HasPrefix(string(data), "{\n    \"version\": 1,") {
+		t.Errorf("expected a versioned projects file, got %s", data)
+	}
+
+	cache, err := store.LoadCache()
+	if err != nil || len(cache.Git) != 1 || cache.Git[0].Kind != models.KindGit {
+		t.Fatalf("LoadCache = %v, %v; want the git project", cache, err)
+	}
+	if err := store.SaveCache(cache); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(store.GetCachePath())
+	if !strings.Contains(string(data), `"version": 1`) {
+		t.Errorf("expected a versioned cache file, got %s", data)
+	}
+}
+
+func TestStorage_SaveProjects_NewerSchema(t *testing.T) {
+	store, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer := `{"version": 2, "projects": [{"name": "a", "rootPath": "/a", "enabled": true, "future": "kept"}]}`
+	os.WriteFile(store.GetProjectsPath(), []byte(newer), 0644)
+
+	list, err := store.LoadProjects()
+	if err != nil || len(list.Projects) != 1 {
+		t.Fatalf("LoadProjects = %v, %v; want the project", list, err)
+	}
+	if err := store.SaveProjects(list); !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("SaveProjects = %v, want ErrNewerSchema", err)
+	}
+	if data, _ := os.ReadFile(store.GetProjectsPath()); string(data) != newer {
+		t.Errorf("the newer file was changed: %s", data)
+	}
+}
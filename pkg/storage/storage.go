@@ -5,24 +5,61 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
 )
 
 const (
-	projectsFileName = "projects.json"
-	cacheFileName    = "cache.json"
+	projectsFileName   = "projects.json"
+	cacheFileName      = "cache.json"
+	workspacesFileName = "workspaces.json"
+	scanStateFileName  = "scanstate.json"
+	historyFileName    = "history.json"
+	sessionsFileName   = "sessions.json"
+	activityFileName   = "activity.json"
+	archivedFileName   = "archived.json"
+	opensFileName      = "opens.json"
+
+	// maxHistory bounds the open history; the oldest records are dropped
+	maxHistory = 500
 )
 
+// ErrProjectsCorrupt is wrapped by the error LoadProjects returns when
+// projects.json cannot be parsed (see Repair)
+var ErrProjectsCorrupt = errors.New("corrupt projects file")
+
+// ErrCacheCorrupt is wrapped by the error LoadCache returns when cache.json
+// cannot be parsed. The cache only holds scan results, so it can be
+// cleared and rebuilt by scanning again.
+var ErrCacheCorrupt = errors.New("corrupt cache file")
+
 // Storage handles persistence of projects
 type Storage struct {
 	basePath string
 	mu       sync.RWMutex
+
+	// pruneMissingOnLoad drops cached projects whose folders no longer exist
+	pruneMissingOnLoad bool
+
+	// encryption protects projects.json at rest when enabled
+	encryption *encryption
+
+	// cacheTTL is how long scan results stay fresh; 0 means forever
+	cacheTTL time.Duration
+
+	// undoLabel describes the changes recorded in the undo log
+	undoLabel string
+
+	// backupCount is how many automatic backups of projects.json are kept
+	backupCount int
 }
 
 // CachedProjects holds auto-detected project caches
@@ -32,6 +69,63 @@ type CachedProjects struct {
 	Mercurial []*models.Project `json:"mercurial,omitempty"`
 	VSCode    []*models.Project `json:"vscode,omitempty"`
 	Any       []*models.Project `json:"any,omitempty"`
+
+	// ScannedAt records when each bucket was last filled by a scan
+	ScannedAt map[scanner.ScannerType]time.Time `json:"scannedAt,omitempty"`
+}
+
+// ActivityEntry is the cached time a project folder was last worked on
+type ActivityEntry struct {
+	Time      time.Time `json:"time"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// CacheKinds lists the scanner types of the cache buckets in display order
+var CacheKinds = []scanner.ScannerType{
+	scanner.ScannerGit,
+	scanner.ScannerSVN,
+	scanner.ScannerMercurial,
+	scanner.ScannerVSCode,
+	scanner.ScannerAny,
+}
+
+// MarkScanned records that the bucket for scannerType was scanned at the given time
+func (c *CachedProjects) MarkScanned(scannerType scanner.ScannerType, at time.Time) {
+	if c.ScannedAt == nil {
+		c.ScannedAt = make(map[scanner.ScannerType]time.Time)
+	}
+	c.ScannedAt[scannerType] = at
+}
+
+// StaleKinds returns the scanner types whose last scan is older than ttl
+// at now. Buckets holding projects without a scan time, written before
+// scan times were recorded, are stale too. A ttl of 0 disables the check.
+func (c *CachedProjects) StaleKinds(ttl time.Duration, now time.Time) []scanner.ScannerType {
+	if ttl <= 0 {
+		return nil
+	}
+	buckets := map[scanner.ScannerType][]*models.Project{
+		scanner.ScannerGit:       c.Git,
+		scanner.ScannerSVN:       c.SVN,
+		scanner.ScannerMercurial: c.Mercurial,
+		scanner.ScannerVSCode:    c.VSCode,
+		scanner.ScannerAny:       c.Any,
+	}
+
+	var stale []scanner.ScannerType
+	for _, kind := range CacheKinds {
+		scannedAt, ok := c.ScannedAt[kind]
+		if !ok {
+			if len(buckets[kind]) > 0 {
+				stale = append(stale, kind)
+			}
+			continue
+		}
+		if now.Sub(scannedAt) > ttl {
+			stale = append(stale, kind)
+		}
+	}
+	return stale
 }
 
 // NewStorage creates a new storage instance
@@ -59,16 +153,48 @@ func (s *Storage) GetBasePath() string {
 	return s.basePath
 }
 
+// SetPruneMissingOnLoad makes LoadCache remove cached projects whose
+// folders no longer exist, writing the pruned cache back to disk
+func (s *Storage) SetPruneMissingOnLoad(prune bool) {
+	s.pruneMissingOnLoad = prune
+}
+
+// SetCacheTTL sets how long scan results stay fresh (0 means forever)
+func (s *Storage) SetCacheTTL(ttl time.Duration) {
+	s.cacheTTL = ttl
+}
+
+// CacheTTL returns how long scan results stay fresh (0 means forever)
+func (s *Storage) CacheTTL() time.Duration {
+	return s.cacheTTL
+}
+
 // GetProjectsPath returns the path to projects.json
 func (s *Storage) GetProjectsPath() string {
 	return filepath.Join(s.basePath, projectsFileName)
 }
 
+// GetCachePath returns the path to cache.json
+func (s *Storage) GetCachePath() string {
+	return filepath.Join(s.basePath, cacheFileName)
+}
+
 // LoadProjects loads saved (favorite) projects from projects.json
 func (s *Storage) LoadProjects() (*models.ProjectList, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	return s.loadProjects()
+}
+
+// loadProjects reads projects.json; the caller holds the storage lock
+func (s *Storage) loadProjects() (*models.ProjectList, error) {
 	projectList := models.NewProjectList(models.KindFavorite)
 	projectsPath := s.GetProjectsPath()
 
@@ -79,55 +205,221 @@ func (s *Storage) LoadProjects() (*models.ProjectList, error) {
 		}
 		return nil, fmt.Errorf("failed to read projects file: %w", err)
 	}
+	if data, err = s.encryption.open(data); err != nil {
+		return nil, fmt.Errorf("failed to read projects file: %w", err)
+	}
 
-	var projects []*models.Project
-	if err := json.Unmarshal(data, &projects); err != nil {
-		return nil, fmt.Errorf("failed to parse projects file: %w", err)
+	projects, err := DecodeProjects(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrProjectsCorrupt, projectsPath, err)
 	}
 
 	for _, p := range projects {
 		p.Kind = models.KindFavorite
-		p.RootPath = paths.Expand(p.RootPath)
+		p.RootPath = paths.ExpandHome(p.RootPath)
 		projectList.Projects = append(projectList.Projects, p)
 	}
 
 	return projectList, nil
 }
 
-// SaveProjects saves favorite projects to projects.json
+// SaveProjects saves favorite projects to projects.json, keeping the
+// previous contents in the undo log
 func (s *Storage) SaveProjects(projects *models.ProjectList) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	return s.saveProjects(projects)
+}
+
+// ErrNoChanges is returned by the function passed to Update when it left
+// the projects as they were, so they need not be saved
+var ErrNoChanges = errors.New("no changes")
+
+// Update loads the favorites, passes them to fn and saves them unless fn
+// returns an error, holding the exclusive storage lock throughout so
+// changes made by other processes in between are not lost. Update returns
+// nil without saving when fn returns ErrNoChanges.
+func (s *Storage) Update(fn func(projects *models.ProjectList) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	projects, err := s.loadProjects()
+	if err != nil {
+		return err
+	}
+	if err := fn(projects); errors.Is(err, ErrNoChanges) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return s.saveProjects(projects)
+}
+
+// saveProjects writes projects.json; the caller holds the exclusive
+// storage lock
+func (s *Storage) saveProjects(projects *models.ProjectList) error {
 	// Prepare projects for saving (collapse paths)
 	saveProjects := make([]*models.Project, len(projects.Projects))
 	for i, p := range projects.Projects {
-		saveProjects[i] = &models.Project{
-			Name:     p.Name,
-			RootPath: paths.Collapse(p.RootPath),
-			Tags:     p.Tags,
-			Enabled:  p.Enabled,
-		}
+		saveProjects[i] = collapsedCopy(p)
 	}
 
-	data, err := json.MarshalIndent(saveProjects, "", "    ")
+	previous, err := os.ReadFile(s.GetProjectsPath())
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read projects file: %w", err)
+	}
+	if err := s.checkNotNewer(previous); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(projectsFile{Version: ProjectsVersion, Projects: saveProjects}, "", "    ")
 	if err != nil {
 		return fmt.Errorf("failed to serialize projects: %w", err)
 	}
+	if data, err = s.encryption.seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt projects: %w", err)
+	}
 
-	if err := os.WriteFile(s.GetProjectsPath(), data, 0644); err != nil {
+	if err := writeFileAtomic(s.GetProjectsPath(), data, 0644); err != nil {
 		return fmt.Errorf("failed to write projects file: %w", err)
 	}
 
+	s.afterProjectsWrite(previous, data)
+	s.keepLastGood(s.GetProjectsPath(), data)
 	return nil
 }
 
-// LoadCache loads cached auto-detected projects
+// collapsedCopy returns a copy of the project with its path collapsed for saving
+func collapsedCopy(p *models.Project) *models.Project {
+	cp := *p
+	cp.RootPath = paths.Collapse(p.RootPath)
+	return &cp
+}
+
+// LoadCache loads cached auto-detected projects. Archived projects are
+// left out, so they stay hidden when rescans find their folders again.
 func (s *Storage) LoadCache() (*CachedProjects, error) {
+	cache, err := s.loadCache()
+	if err != nil {
+		return nil, err
+	}
+	if archived, err := s.LoadArchived(); err == nil && len(archived) > 0 {
+		cache.removeArchived(archived)
+	}
+	if !s.pruneMissingOnLoad {
+		return cache, nil
+	}
+
+	// Pruning on load is best-effort: a cache that cannot be written back
+	// is still returned pruned and is pruned again next time
+	if len(cache.PruneMissing()) > 0 {
+		s.SaveCache(cache)
+	}
+	return cache, nil
+}
+
+// PruneCache removes cached projects whose folders no longer exist and
+// saves the cache, returning the removed projects
+func (s *Storage) PruneCache() ([]*models.Project, error) {
+	cache, err := s.loadCache()
+	if err != nil {
+		return nil, err
+	}
+	removed := cache.PruneMissing()
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	if err := s.SaveCache(cache); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// PruneMissing removes projects whose folders no longer exist from every
+// bucket and returns them. Projects on a volume that is not mounted are
+// kept: their folders are only offline.
+func (c *CachedProjects) PruneMissing() []*models.Project {
+	var removed []*models.Project
+	mounted := make(map[string]bool)
+	prune := func(projects []*models.Project) []*models.Project {
+		kept := make([]*models.Project, 0, len(projects))
+		for _, p := range projects {
+			if p.Volume != "" {
+				up, ok := mounted[p.Volume]
+				if !ok {
+					up = paths.VolumeMounted(p.Volume)
+					mounted[p.Volume] = up
+				}
+				if !up {
+					kept = append(kept, p)
+					continue
+				}
+			}
+			if _, err := os.Stat(p.RootPath); os.IsNotExist(err) {
+				removed = append(removed, p)
+				continue
+			}
+			kept = append(kept, p)
+		}
+		return kept
+	}
+
+	c.Git = prune(c.Git)
+	c.SVN = prune(c.SVN)
+	c.Mercurial = prune(c.Mercurial)
+	c.VSCode = prune(c.VSCode)
+	c.Any = prune(c.Any)
+	return removed
+}
+
+// removeArchived drops the cached projects whose path is archived
+func (c *CachedProjects) removeArchived(archived []*models.ArchivedProject) {
+	archivedPaths := make(map[string]bool, len(archived))
+	for _, a := range archived {
+		archivedPaths[paths.Key(a.Project.RootPath)] = true
+	}
+	remove := func(projects []*models.Project) []*models.Project {
+		kept := projects[:0]
+		for _, p := range projects {
+			if !archivedPaths[paths.Key(p.RootPath)] {
+				kept = append(kept, p)
+			}
+		}
+		return kept
+	}
+
+	c.Git = remove(c.Git)
+	c.SVN = remove(c.SVN)
+	c.Mercurial = remove(c.Mercurial)
+	c.VSCode = remove(c.VSCode)
+	c.Any = remove(c.Any)
+}
+
+// loadCache reads cache.json, expanding paths and setting kinds
+func (s *Storage) loadCache() (*CachedProjects, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	cachePath := filepath.Join(s.basePath, cacheFileName)
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	cachePath := s.GetCachePath()
 
 	data, err := os.ReadFile(cachePath)
 	if err != nil {
@@ -138,29 +430,29 @@ func (s *Storage) LoadCache() (*CachedProjects, error) {
 	}
 
 	var cache CachedProjects
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, fmt.Errorf("failed to parse cache file: %w", err)
+	if _, err := decodeVersioned(data, "", cacheMigrations, &cacheFile{CachedProjects: &cache}); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrCacheCorrupt, cachePath, err)
 	}
 
 	// Expand paths and set kinds
 	for _, p := range cache.Git {
-		p.RootPath = paths.Expand(p.RootPath)
+		p.RootPath = paths.ExpandHome(p.RootPath)
 		p.Kind = models.KindGit
 	}
 	for _, p := range cache.SVN {
-		p.RootPath = paths.Expand(p.RootPath)
+		p.RootPath = paths.ExpandHome(p.RootPath)
 		p.Kind = models.KindSVN
 	}
 	for _, p := range cache.Mercurial {
-		p.RootPath = paths.Expand(p.RootPath)
+		p.RootPath = paths.ExpandHome(p.RootPath)
 		p.Kind = models.KindMercurial
 	}
 	for _, p := range cache.VSCode {
-		p.RootPath = paths.Expand(p.RootPath)
+		p.RootPath = paths.ExpandHome(p.RootPath)
 		p.Kind = models.KindVSCode
 	}
 	for _, p := range cache.Any {
-		p.RootPath = paths.Expand(p.RootPath)
+		p.RootPath = paths.ExpandHome(p.RootPath)
 		p.Kind = models.KindAny
 	}
 
@@ -172,16 +464,17 @@ func (s *Storage) SaveCache(cache *CachedProjects) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
 	// Collapse paths before saving
 	saveCacheProjects := func(projects []*models.Project) []*models.Project {
 		result := make([]*models.Project, len(projects))
 		for i, p := range projects {
-			result[i] = &models.Project{
-				Name:     p.Name,
-				RootPath: paths.Collapse(p.RootPath),
-				Tags:     p.Tags,
-				Enabled:  p.Enabled,
-			}
+			result[i] = collapsedCopy(p)
 		}
 		return result
 	}
@@ -192,30 +485,226 @@ func (s *Storage) SaveCache(cache *CachedProjects) error {
 		Mercurial: saveCacheProjects(cache.Mercurial),
 		VSCode:    saveCacheProjects(cache.VSCode),
 		Any:       saveCacheProjects(cache.Any),
+		ScannedAt: cache.ScannedAt,
 	}
 
-	data, err := json.MarshalIndent(saveCache, "", "    ")
+	data, err := json.MarshalIndent(cacheFile{Version: CacheVersion, CachedProjects: saveCache}, "", "    ")
 	if err != nil {
 		return fmt.Errorf("failed to serialize cache: %w", err)
 	}
 
-	cachePath := filepath.Join(s.basePath, cacheFileName)
-	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+	cachePath := s.GetCachePath()
+	if err := writeFileAtomic(cachePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
 
+	s.keepLastGood(cachePath, data)
 	return nil
 }
 
-// ClearCache removes the cache file
+// ClearCache removes the cache file along with the scan state and the
+// activity cache
 func (s *Storage) ClearCache() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	cachePath := filepath.Join(s.basePath, cacheFileName)
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	cachePath := s.GetCachePath()
 	if err := os.Remove(cachePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove cache file: %w", err)
 	}
+	_ = os.Remove(cachePath + lastGoodSuffix)
+	statePath := filepath.Join(s.basePath, scanStateFileName)
+	if err := os.Remove(statePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove scan state file: %w", err)
+	}
+	activityPath := filepath.Join(s.basePath, activityFileName)
+	if err := os.Remove(activityPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove activity file: %w", err)
+	}
+	return nil
+}
+
+// LoadScanState loads the directory state recorded by previous scans,
+// keyed by scanner type. A missing file yields an empty map.
+func (s *Storage) LoadScanState() (map[scanner.ScannerType]scanner.ScanState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	states := make(map[scanner.ScannerType]scanner.ScanState)
+	data, err := os.ReadFile(filepath.Join(s.basePath, scanStateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return nil, fmt.Errorf("failed to read scan state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to parse scan state file: %w", err)
+	}
+	return states, nil
+}
+
+// SaveScanState saves the directory state recorded by a scan
+func (s *Storage) SaveScanState(states map[scanner.ScannerType]scanner.ScanState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("failed to serialize scan state: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, scanStateFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write scan state file: %w", err)
+	}
+	return nil
+}
+
+// LoadActivity loads the cached activity times, keyed by project root
+// path. A missing file yields an empty map.
+func (s *Storage) LoadActivity() (map[string]ActivityEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	activity := make(map[string]ActivityEntry)
+	data, err := os.ReadFile(filepath.Join(s.basePath, activityFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return activity, nil
+		}
+		return nil, fmt.Errorf("failed to read activity file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &activity); err != nil {
+		return nil, fmt.Errorf("failed to parse activity file: %w", err)
+	}
+	return activity, nil
+}
+
+// SaveActivity saves the cached activity times
+func (s *Storage) SaveActivity(activity map[string]ActivityEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	data, err := json.Marshal(activity)
+	if err != nil {
+		return fmt.Errorf("failed to serialize activity: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, activityFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write activity file: %w", err)
+	}
+	return nil
+}
+
+// LoadArchived loads the archived projects from archived.json. A missing
+// file yields an empty list.
+func (s *Storage) LoadArchived() ([]*models.ArchivedProject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	data, err := os.ReadFile(filepath.Join(s.basePath, archivedFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.ArchivedProject{}, nil
+		}
+		return nil, fmt.Errorf("failed to read archived file: %w", err)
+	}
+	if data, err = s.encryption.open(data); err != nil {
+		return nil, fmt.Errorf("failed to read archived file: %w", err)
+	}
+
+	var archived []*models.ArchivedProject
+	if err := json.Unmarshal(data, &archived); err != nil {
+		return nil, fmt.Errorf("failed to parse archived file: %w", err)
+	}
+
+	kept := archived[:0]
+	for _, a := range archived {
+		if a.Project == nil {
+			continue
+		}
+		a.Project.RootPath = paths.ExpandHome(a.Project.RootPath)
+		a.Project.Kind = a.Kind
+		if a.Location != "" {
+			a.Location = paths.ExpandHome(a.Location)
+		}
+		kept = append(kept, a)
+	}
+
+	return kept, nil
+}
+
+// SaveArchived saves the archived projects to archived.json
+func (s *Storage) SaveArchived(archived []*models.ArchivedProject) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	saveArchived := make([]*models.ArchivedProject, len(archived))
+	for i, a := range archived {
+		cp := *a
+		cp.Project = collapsedCopy(a.Project)
+		if a.Location != "" {
+			cp.Location = paths.Collapse(a.Location)
+		}
+		saveArchived[i] = &cp
+	}
+
+	data, err := json.MarshalIndent(saveArchived, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize archived projects: %w", err)
+	}
+	if data, err = s.encryption.seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt archived projects: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, archivedFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write archived file: %w", err)
+	}
+
 	return nil
 }
 
@@ -242,3 +731,265 @@ func (s *Storage) LoadAllProjects() ([]*models.Project, error) {
 
 	return allProjects, nil
 }
+
+// LoadWorkspaces loads project workspaces from workspaces.json
+func (s *Storage) LoadWorkspaces() ([]*models.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	data, err := os.ReadFile(filepath.Join(s.basePath, workspacesFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.Workspace{}, nil
+		}
+		return nil, fmt.Errorf("failed to read workspaces file: %w", err)
+	}
+
+	var workspaces []*models.Workspace
+	if err := json.Unmarshal(data, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse workspaces file: %w", err)
+	}
+
+	for _, w := range workspaces {
+		w.Projects = paths.ExpandHomeAll(w.Projects)
+	}
+
+	return workspaces, nil
+}
+
+// SaveWorkspaces saves project workspaces to workspaces.json
+func (s *Storage) SaveWorkspaces(workspaces []*models.Workspace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	saveWorkspaces := make([]*models.Workspace, len(workspaces))
+	for i, w := range workspaces {
+		members := make([]string, len(w.Projects))
+		for j, p := range w.Projects {
+			members[j] = paths.Collapse(p)
+		}
+		saveWorkspaces[i] = &models.Workspace{Name: w.Name, Projects: members}
+	}
+
+	data, err := json.MarshalIndent(saveWorkspaces, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize workspaces: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, workspacesFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write workspaces file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadHistory loads the open history from history.json, oldest first
+func (s *Storage) LoadHistory() ([]models.OpenRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	return s.loadHistory()
+}
+
+func (s *Storage) loadHistory() ([]models.OpenRecord, error) {
+	data, err := os.ReadFile(filepath.Join(s.basePath, historyFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.OpenRecord{}, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var records []models.OpenRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse history file: %w", err)
+	}
+	for i := range records {
+		records[i].Path = paths.ExpandHome(records[i].Path)
+	}
+	return records, nil
+}
+
+// RecordOpen appends the project paths opened at the given time to the
+// open history, keeping only the most recent records
+func (s *Storage) RecordOpen(at time.Time, projectPaths ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	records, err := s.loadHistory()
+	if err != nil {
+		// A corrupt history only costs the records in it
+		records = nil
+	}
+	for _, path := range projectPaths {
+		records = append(records, models.OpenRecord{Path: path, OpenedAt: at})
+	}
+	if len(records) > maxHistory {
+		records = records[len(records)-maxHistory:]
+	}
+
+	saveRecords := make([]models.OpenRecord, len(records))
+	for i, r := range records {
+		saveRecords[i] = models.OpenRecord{Path: paths.Collapse(r.Path), OpenedAt: r.OpenedAt}
+	}
+
+	data, err := json.MarshalIndent(saveRecords, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize history: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, historyFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	return s.countOpens(projectPaths)
+}
+
+// LoadOpenCounts loads how many times each project was opened, keyed by
+// root path. Unlike the open history, the counts are never trimmed.
+func (s *Storage) LoadOpenCounts() (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	return s.loadOpenCounts()
+}
+
+func (s *Storage) loadOpenCounts() (map[string]int, error) {
+	counts := make(map[string]int)
+	data, err := os.ReadFile(filepath.Join(s.basePath, opensFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return counts, nil
+		}
+		return nil, fmt.Errorf("failed to read open counts file: %w", err)
+	}
+
+	var saved map[string]int
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse open counts file: %w", err)
+	}
+	for path, n := range saved {
+		counts[paths.ExpandHome(path)] += n
+	}
+	return counts, nil
+}
+
+// countOpens adds one open to the count of each path. The caller holds
+// the storage lock.
+func (s *Storage) countOpens(projectPaths []string) error {
+	counts, err := s.loadOpenCounts()
+	if err != nil {
+		// A corrupt file only costs the counts in it
+		counts = make(map[string]int)
+	}
+	for _, path := range projectPaths {
+		counts[path]++
+	}
+
+	saveCounts := make(map[string]int, len(counts))
+	for path, n := range counts {
+		saveCounts[paths.Collapse(path)] += n
+	}
+	data, err := json.MarshalIndent(saveCounts, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize open counts: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, opensFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write open counts file: %w", err)
+	}
+	return nil
+}
+
+// LoadSessions loads saved sessions from sessions.json
+func (s *Storage) LoadSessions() ([]*models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	data, err := os.ReadFile(filepath.Join(s.basePath, sessionsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.Session{}, nil
+		}
+		return nil, fmt.Errorf("failed to read sessions file: %w", err)
+	}
+
+	var sessions []*models.Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions file: %w", err)
+	}
+
+	for _, session := range sessions {
+		session.Projects = paths.ExpandHomeAll(session.Projects)
+	}
+
+	return sessions, nil
+}
+
+// SaveSessions saves sessions to sessions.json
+func (s *Storage) SaveSessions(sessions []*models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	saveSessions := make([]*models.Session, len(sessions))
+	for i, session := range sessions {
+		members := make([]string, len(session.Projects))
+		for j, p := range session.Projects {
+			members[j] = paths.Collapse(p)
+		}
+		saveSessions[i] = &models.Session{Name: session.Name, SavedAt: session.SavedAt, Projects: members}
+	}
+
+	data, err := json.MarshalIndent(saveSessions, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize sessions: %w", err)
+	}
+
+	if err := writeFileAtomic(filepath.Join(s.basePath, sessionsFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write sessions file: %w", err)
+	}
+
+	return nil
+}
@@ -1,13 +1,18 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ideaspaper/projector/pkg/models"
 	"github.com/ideaspaper/projector/pkg/paths"
+	"github.com/ideaspaper/projector/pkg/scanner"
 )
 
 func TestNewStorage(t *testing.T) {
@@ -128,6 +133,99 @@ func TestStorage_SaveAndLoadProjects(t *testing.T) {
 	}
 }
 
+func TestStorage_SaveAndLoadProjects_DescriptionAndNotes(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	pl := models.NewProjectList(models.KindFavorite)
+	p := models.NewProject("xq7", "/path/to/xq7")
+	p.Description = "Billing service prototype"
+	p.Notes = "Deploys from the release branch"
+	pl.Add(p)
+	pl.Add(models.NewProject("plain", "/path/to/plain"))
+
+	if err := store.SaveProjects(pl); err != nil {
+		t.Fatalf("SaveProjects failed: %v", err)
+	}
+
+	// Empty fields should be omitted from the file
+	data, _ := os.ReadFile(store.GetProjectsPath())
+	if strings.Count(string(data), `"description"`) != 1 {
+		t.Errorf("expected exactly one description field in file, got:\n%s", data)
+	}
+
+	loaded, err := store.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects failed: %v", err)
+	}
+
+	lp := loaded.FindByName("xq7")
+	if lp == nil {
+		t.Fatal("expected to find xq7")
+	}
+	if lp.Description != p.Description {
+		t.Errorf("expected description %q, got %q", p.Description, lp.Description)
+	}
+	if lp.Notes != p.Notes {
+		t.Errorf("expected notes %q, got %q", p.Notes, lp.Notes)
+	}
+}
+
+func TestStorage_SaveAndLoadProjects_FolderKind(t *testing.T) {
+	store, _ := NewStorage(t.TempDir())
+
+	pl := models.NewProjectList(models.KindFavorite)
+	p := models.NewProject("api", "/path/to/api")
+	p.FolderKind = models.KindGit
+	pl.Add(p)
+
+	if err := store.SaveProjects(pl); err != nil {
+		t.Fatalf("SaveProjects failed: %v", err)
+	}
+	loaded, err := store.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects failed: %v", err)
+	}
+
+	lp := loaded.FindByName("api")
+	if lp.FolderKind != models.KindGit {
+		t.Errorf("expected folder kind %q, got %q", models.KindGit, lp.FolderKind)
+	}
+	if lp.Kind != models.KindFavorite {
+		t.Errorf("expected kind %q, got %q", models.KindFavorite, lp.Kind)
+	}
+}
+
+func TestStorage_SaveAndLoadProjects_KeepsDollarAndPercent(t *testing.T) {
+	store, _ := NewStorage(t.TempDir())
+	home, _ := os.UserHomeDir()
+	t.Setenv("HOME_TEST_VAR", "/elsewhere")
+
+	rootPaths := []string{
+		"/data/price$list",
+		"/data/a$-b",
+		"/data/50%HOME%x",
+		"/data/$HOME_TEST_VAR",
+		filepath.Join(home, "$team", "api"),
+	}
+	pl := models.NewProjectList(models.KindFavorite)
+	for i, path := range rootPaths {
+		pl.Add(models.NewProject(fmt.Sprintf("p%d", i), path))
+	}
+	if err := store.SaveProjects(pl); err != nil {
+		t.Fatalf("SaveProjects failed: %v", err)
+	}
+	loaded, err := store.LoadProjects()
+	if err != nil {
+		t.Fatalf("LoadProjects failed: %v", err)
+	}
+	for i, path := range rootPaths {
+		if got := loaded.FindByName(fmt.Sprintf("p%d", i)).RootPath; got != path {
+			t.Errorf("expected %q to be loaded unchanged, got %q", path, got)
+		}
+	}
+}
+
 func TestStorage_LoadProjects_NonExistent(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, _ := NewStorage(tmpDir)
@@ -191,6 +289,189 @@ func TestStorage_SaveAndLoadCache(t *testing.T) {
 	}
 }
 
+func TestStorage_PruneCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+
+	cache := &CachedProjects{
+		Git: []*models.Project{
+			{Name: "here", RootPath: tmpDir, Enabled: true},
+			{Name: "gone", RootPath: filepath.Join(tmpDir, "gone"), Enabled: true},
+		},
+		Any: []*models.Project{
+			{Name: "gone-too", RootPath: filepath.Join(tmpDir, "gone-too"), Enabled: true},
+		},
+	}
+	if err := store.SaveCache(cache); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+
+	removed, err := store.PruneCache()
+	if err != nil {
+		t.Fatalf("PruneCache failed: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("expected 2 removed, got %d", len(removed))
+	}
+
+	loaded, _ := store.LoadCache()
+	if len(loaded.Git) != 1 || len(loaded.Any) != 0 {
+		t.Errorf("expected pruned cache to be saved, got %d git, %d any", len(loaded.Git), len(loaded.Any))
+	}
+}
+
+func TestCachedProjects_StaleKinds(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	cache := &CachedProjects{
+		Git: []*models.Project{{Name: "fresh", RootPath: tmpDir}},
+		SVN: []*models.Project{{Name: "old", RootPath: tmpDir}},
+		Any: []*models.Project{{Name: "unknown", RootPath: tmpDir}},
+	}
+	cache.MarkScanned(scanner.ScannerGit, now.Add(-time.Hour))
+	cache.MarkScanned(scanner.ScannerSVN, now.AddDate(0, 0, -8))
+	cache.MarkScanned(scanner.ScannerVSCode, now.AddDate(0, 0, -8))
+
+	if err := store.SaveCache(cache); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+	loaded, err := store.LoadCache()
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	if !loaded.ScannedAt[scanner.ScannerGit].Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected scan times to be saved, got %v", loaded.ScannedAt)
+	}
+
+	// Empty buckets scanned long ago are stale; empty buckets never scanned are not
+	want := []scanner.ScannerType{scanner.ScannerSVN, scanner.ScannerVSCode, scanner.ScannerAny}
+	if got := loaded.StaleKinds(7*24*time.Hour, now); !reflect.DeepEqual(got, want) {
+		t.Errorf("StaleKinds = %v, want %v", got, want)
+	}
+	if got := loaded.StaleKinds(0, now); got != nil {
+		t.Errorf("StaleKinds with no TTL = %v, want none", got)
+	}
+}
+
+func TestStorage_LoadCache_PruneMissingOnLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+
+	cache := &CachedProjects{
+		Git: []*models.Project{
+			{Name: "here", RootPath: tmpDir, Enabled: true},
+			{Name: "gone", RootPath: filepath.Join(tmpDir, "gone"), Enabled: true},
+		},
+	}
+	if err := store.SaveCache(cache); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+
+	// Without the option, missing projects are kept
+	loaded, _ := store.LoadCache()
+	if len(loaded.Git) != 2 {
+		t.Fatalf("expected 2 git repos without pruning, got %d", len(loaded.Git))
+	}
+
+	store.SetPruneMissingOnLoad(true)
+	loaded, _ = store.LoadCache()
+	if len(loaded.Git) != 1 {
+		t.Errorf("expected 1 git repo after pruning, got %d", len(loaded.Git))
+	}
+
+	// The pruned cache is written back
+	store.SetPruneMissingOnLoad(false)
+	loaded, _ = store.LoadCache()
+	if len(loaded.Git) != 1 {
+		t.Errorf("expected pruned cache on disk, got %d git repos", len(loaded.Git))
+	}
+}
+
+func TestStorage_LoadCache_Corrupt(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := NewStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewStorage failed: %v", err)
+	}
+	if err := os.WriteFile(store.GetCachePath(), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.LoadCache(); !errors.Is(err, ErrCacheCorrupt) {
+		t.Errorf("LoadCache() error = %v, want ErrCacheCorrupt", err)
+	}
+}
+
+func TestCachedProjects_PruneMissing_KeepsOffline(t *testing.T) {
+	tmpDir := t.TempDir()
+	usb := filepath.Join(tmpDir, "usb")
+	cache := &CachedProjects{
+		Git: []*models.Project{
+			{Name: "gone", RootPath: filepath.Join(tmpDir, "gone"), Enabled: true},
+			{Name: "ejected", RootPath: filepath.Join(usb, "ejected"), Volume: usb, Enabled: true},
+		},
+	}
+
+	removed := cache.PruneMissing()
+	if len(removed) != 1 || removed[0].Name != "gone" {
+		t.Errorf("expected only gone to be pruned, got %v", removed)
+	}
+	if len(cache.Git) != 1 || cache.Git[0].Name != "ejected" {
+		t.Errorf("expected the offline project to be kept, got %v", cache.Git)
+	}
+}
+
+func TestStorage_SaveAndLoadWorkspaces(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	// Loading without a file returns no workspaces
+	workspaces, err := store.LoadWorkspaces()
+	if err != nil {
+		t.Fatalf("LoadWorkspaces failed: %v", err)
+	}
+	if len(workspaces) != 0 {
+		t.Errorf("expected no workspaces, got %d", len(workspaces))
+	}
+
+	home, _ := os.UserHomeDir()
+	w := models.NewWorkspace("platform")
+	w.AddProject(filepath.Join(home, "code", "api"))
+	w.AddProject("/srv/web")
+
+	if err := store.SaveWorkspaces([]*models.Workspace{w}); err != nil {
+		t.Fatalf("SaveWorkspaces failed: %v", err)
+	}
+
+	// Home paths are collapsed on disk
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "workspaces.json"))
+	if !strings.Contains(string(data), "~/code/api") {
+		t.Errorf("expected collapsed path in file, got:\n%s", data)
+	}
+
+	loaded, err := store.LoadWorkspaces()
+	if err != nil {
+		t.Fatalf("LoadWorkspaces failed: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Name != "platform" {
+		t.Fatalf("unexpected workspaces: %+v", loaded)
+	}
+	if !loaded[0].HasProject(filepath.Join(home, "code", "api")) || !loaded[0].HasProject("/srv/web") {
+		t.Errorf("unexpected members: %v", loaded[0].Projects)
+	}
+}
+
 func TestStorage_LoadCache_NonExistent(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, _ := NewStorage(tmpDir)
@@ -232,6 +513,73 @@ func TestStorage_ClearCache(t *testing.T) {
 	}
 }
 
+func TestStorage_Activity(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	activity, err := store.LoadActivity()
+	if err != nil || len(activity) != 0 {
+		t.Fatalf("expected no activity before saving, got %v, %v", activity, err)
+	}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.SaveActivity(map[string]ActivityEntry{"/repo": {Time: at, CheckedAt: at}}); err != nil {
+		t.Fatalf("SaveActivity failed: %v", err)
+	}
+	activity, err = store.LoadActivity()
+	if err != nil || !activity["/repo"].Time.Equal(at) {
+		t.Errorf("expected the saved activity back, got %v, %v", activity, err)
+	}
+
+	// Clearing the cache drops the activity too
+	if err := store.ClearCache(); err != nil {
+		t.Fatalf("ClearCache failed: %v", err)
+	}
+	if activity, _ := store.LoadActivity(); len(activity) != 0 {
+		t.Errorf("expected ClearCache to remove the activity, got %v", activity)
+	}
+}
+
+func TestStorage_Archived(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	cache := &CachedProjects{Git: []*models.Project{
+		{Name: "old", RootPath: "/work/old", Enabled: true},
+		{Name: "new", RootPath: "/work/new", Enabled: true},
+	}}
+	if err := store.SaveCache(cache); err != nil {
+		t.Fatalf("SaveCache failed: %v", err)
+	}
+
+	archived := []*models.ArchivedProject{{
+		Project:    &models.Project{Name: "old", RootPath: "/work/old", Enabled: true},
+		Kind:       models.KindGit,
+		ArchivedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Location:   "/archive/old.tar.gz",
+	}}
+	if err := store.SaveArchived(archived); err != nil {
+		t.Fatalf("SaveArchived failed: %v", err)
+	}
+
+	loaded, err := store.LoadArchived()
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("expected one archived project, got %v, %v", loaded, err)
+	}
+	if a := loaded[0]; a.Project.Kind != models.KindGit || a.Location != "/archive/old.tar.gz" {
+		t.Errorf("archived project not restored: %+v", a)
+	}
+
+	// Archived projects are hidden from the cache
+	cache, err = store.LoadCache()
+	if err != nil {
+		t.Fatalf("LoadCache failed: %v", err)
+	}
+	if len(cache.Git) != 1 || cache.Git[0].Name != "new" {
+		t.Errorf("expected only the unarchived project in the cache, got %v", cache.Git)
+	}
+}
+
 func TestStorage_ClearCache_NonExistent(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, _ := NewStorage(tmpDir)
@@ -337,3 +685,121 @@ func TestPaths_Exists(t *testing.T) {
 		t.Error("expected Exists to return false for non-existent path")
 	}
 }
+
+func TestStorage_ScanState(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	states, err := store.LoadScanState()
+	if err != nil {
+		t.Fatalf("LoadScanState failed: %v", err)
+	}
+	if len(states) != 0 {
+		t.Errorf("expected empty state without a file, got %v", states)
+	}
+
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	states[scanner.ScannerGit] = scanner.ScanState{
+		"/repo": {ModTime: modTime, IsProject: true, Language: "Go", Dirs: []string{"cmd"}},
+	}
+	if err := store.SaveScanState(states); err != nil {
+		t.Fatalf("SaveScanState failed: %v", err)
+	}
+
+	loaded, err := store.LoadScanState()
+	if err != nil {
+		t.Fatalf("LoadScanState failed: %v", err)
+	}
+	got := loaded[scanner.ScannerGit]["/repo"]
+	if !got.ModTime.Equal(modTime) || !got.IsProject || got.Language != "Go" || len(got.Dirs) != 1 {
+		t.Errorf("unexpected state after round trip: %+v", got)
+	}
+
+	if err := store.ClearCache(); err != nil {
+		t.Fatalf("ClearCache failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "scanstate.json")); !os.IsNotExist(err) {
+		t.Error("expected ClearCache to remove the scan state")
+	}
+}
+
+func TestStorage_RecordOpen(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	records, err := store.LoadHistory()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected empty history, got %v, %v", records, err)
+	}
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < maxHistory+10; i++ {
+		if err := store.RecordOpen(at.Add(time.Duration(i)*time.Second), fmt.Sprintf("/code/p%d", i)); err != nil {
+			t.Fatalf("RecordOpen failed: %v", err)
+		}
+	}
+
+	records, err = store.LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if len(records) != maxHistory {
+		t.Fatalf("expected history capped at %d, got %d", maxHistory, len(records))
+	}
+	last := records[len(records)-1]
+	if last.Path != fmt.Sprintf("/code/p%d", maxHistory+9) || !last.OpenedAt.Equal(at.Add(time.Duration(maxHistory+9)*time.Second)) {
+		t.Errorf("unexpected last record: %+v", last)
+	}
+}
+
+func TestStorage_OpenCounts(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	home, _ := os.UserHomeDir()
+	api := filepath.Join(home, "code", "api")
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store.RecordOpen(at, api)
+	store.RecordOpen(at, api, "/srv/web")
+
+	counts, err := store.LoadOpenCounts()
+	if err != nil {
+		t.Fatalf("LoadOpenCounts failed: %v", err)
+	}
+	if counts[api] != 2 || counts["/srv/web"] != 1 || len(counts) != 2 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "opens.json"))
+	if !strings.Contains(string(data), "~/code/api") {
+		t.Errorf("expected collapsed path in file, got:\n%s", data)
+	}
+}
+
+func TestStorage_SaveAndLoadSessions(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStorage(tmpDir)
+
+	home, _ := os.UserHomeDir()
+	saved := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	session := &models.Session{Name: "feature-x", SavedAt: saved, Projects: []string{filepath.Join(home, "code", "api"), "/srv/web"}}
+	if err := store.SaveSessions([]*models.Session{session}); err != nil {
+		t.Fatalf("SaveSessions failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "sessions.json"))
+	if !strings.Contains(string(data), "~/code/api") {
+		t.Errorf("expected collapsed path in file, got:\n%s", data)
+	}
+
+	loaded, err := store.LoadSessions()
+	if err != nil {
+		t.Fatalf("LoadSessions failed: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Name != "feature-x" || !loaded[0].SavedAt.Equal(saved) {
+		t.Fatalf("unexpected sessions: %+v", loaded)
+	}
+	if !reflect.DeepEqual(loaded[0].Projects, session.Projects) {
+		t.Errorf("expected members %v, got %v", session.Projects, loaded[0].Projects)
+	}
+}
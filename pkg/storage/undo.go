@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	undoFileName = "undo.json"
+
+	// maxUndo bounds the undo log; the oldest entries are dropped
+	maxUndo = 20
+)
+
+// ErrNothingToUndo is returned by Undo when the undo log is empty
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// UndoEntry holds projects.json as it was before a change, so the change
+// can be undone. Data is kept as stored, so encrypted files stay encrypted.
+type UndoEntry struct {
+	At      time.Time `json:"at"`
+	Command string    `json:"command,omitempty"` // What made the change
+	Data    []byte    `json:"data"`              // nil when there was no projects.json
+}
+
+// SetUndoLabel sets the description recorded in the undo log with each
+// change to projects.json, such as the command line making it
+func (s *Storage) SetUndoLabel(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.undoLabel = label
+}
+
+// LoadUndo loads the undo log, oldest change first
+func (s *Storage) LoadUndo() ([]UndoEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lock, err := s.acquireLock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	return s.loadUndo()
+}
+
+// Undo restores projects.json to how it was before the most recent change
+// and drops that change from the undo log, returning it
+func (s *Storage) Undo() (*UndoEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireLock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	entries, err := s.loadUndo()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	last := entries[len(entries)-1]
+
+	current, err := os.ReadFile(s.GetProjectsPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read projects file: %w", err)
+	}
+
+	if last.Data == nil {
+		if err := os.Remove(s.GetProjectsPath()); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove projects file: %w", err)
+		}
+	} else if err := writeFileAtomic(s.GetProjectsPath(), last.Data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write projects file: %w", err)
+	}
+
+	if err := s.saveUndo(entries[:len(entries)-1]); err != nil {
+		return nil, err
+	}
+	// Undone changes cannot be redone, but stay in the automatic backups
+	if current != nil && !bytes.Equal(current, last.Data) {
+		_ = s.autoBackup(current, time.Now())
+	}
+	return &last, nil
+}
+
+// recordUndo appends the previous contents of projects.json to the undo
+// log. The caller holds the storage lock.
+func (s *Storage) recordUndo(previous []byte, at time.Time) error {
+	entries, err := s.loadUndo()
+	if err != nil {
+		// A corrupt undo log only costs the entries in it
+		entries = nil
+	}
+	entries = append(entries, UndoEntry{At: at, Command: s.undoLabel, Data: previous})
+	if len(entries) > maxUndo {
+		entries = entries[len(entries)-maxUndo:]
+	}
+	return s.saveUndo(entries)
+}
+
+// loadUndo reads undo.json; a missing file yields an empty log
+func (s *Storage) loadUndo() ([]UndoEntry, error) {
+	data, err := os.ReadFile(filepath.Join(s.basePath, undoFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []UndoEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read undo file: %w", err)
+	}
+
+	var entries []UndoEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse undo file: %w", err)
+	}
+	return entries, nil
+}
+
+// saveUndo writes undo.json
+func (s *Storage) saveUndo(entries []UndoEntry) error {
+	data, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize undo log: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.basePath, undoFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write undo file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ideaspaper/projector/pkg/models"
+)
+
+func TestStorage_Undo(t *testing.T) {
+	store, _ := NewStorage(t.TempDir())
+
+	if _, err := store.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("Undo() on an empty log = %v, want ErrNothingToUndo", err)
+	}
+
+	pl := models.NewProjectList(models.KindFavorite)
+	pl.Add(models.NewProject("api", "/work/api"))
+	store.SetUndoLabel("add api")
+	if err := store.SaveProjects(pl); err != nil {
+		t.Fatal(err)
+	}
+	pl.Add(models.NewProject("web", "/work/web"))
+	store.SetUndoLabel("add web")
+	if err := store.SaveProjects(pl); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := store.Undo()
+	if err != nil || entry.Command != "add web" {
+		t.Fatalf("Undo() = %+v, %v; want the add web change", entry, err)
+	}
+	if loaded, _ := store.LoadProjects(); len(loaded.Projects) != 1 || loaded.Projects[0].Name != "api" {
+		t.Errorf("expected only api after undo, got %v", loaded.Projects)
+	}
+
+	// Undoing the first save removes projects.json again
+	if _, err := store.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := store.LoadProjects(); len(loaded.Projects) != 0 {
+		t.Errorf("expected no projects after undoing everything, got %v", loaded.Projects)
+	}
+	if entries, _ := store.LoadUndo(); len(entries) != 0 {
+		t.Errorf("expected an empty undo log, got %d entries", len(entries))
+	}
+}
+
+func TestStorage_UndoBounded(t *testing.T) {
+	store, _ := NewStorage(t.TempDir())
+
+	pl := models.NewProjectList(models.KindFavorite)
+	for i := range maxUndo + 5 {
+		store.SetUndoLabel(fmt.Sprintf("change %d", i))
+		if err := store.SaveProjects(pl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := store.LoadUndo()
+	if err != nil || len(entries) != maxUndo {
+		t.Fatalf("expected %d undo entries, got %d, %v", maxUndo, len(entries), err)
+	}
+	if entries[0].Command != "change 5" {
+		t.Errorf("expected the oldest entries to be dropped, first is %q", entries[0].Command)
+	}
+}
@@ -0,0 +1,82 @@
+// Package trash moves folders to the trash of the operating system (the
+// macOS Trash, the Windows Recycle Bin or the freedesktop.org trash used
+// by Linux and BSD desktops) so they can be restored, rather than
+// deleting them.
+package trash
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Move moves the folder at path to the trash
+func Move(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if _, err := os.Lstat(abs); err != nil {
+		return err
+	}
+	if err := moveToTrash(abs); err != nil {
+		return fmt.Errorf("failed to move %s to the trash: %w", abs, err)
+	}
+	return nil
+}
+
+// infoTimeFormat is the DeletionDate format of .trashinfo files
+const infoTimeFormat = "2006-01-02T15:04:05"
+
+// freedesktopTrash moves path into the freedesktop.org trash directory
+// trashDir: the folder goes to trashDir/files and a .trashinfo file
+// recording where it came from to trashDir/info, so file managers can put
+// it back. Names already in the trash get a numeric suffix. It returns
+// the path the folder was moved to.
+func freedesktopTrash(trashDir, path string, now time.Time) (string, error) {
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", err
+		}
+	}
+
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", (&url.URL{Path: path}).EscapedPath(), now.Format(infoTimeFormat))
+	base := filepath.Base(path)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = base + "." + strconv.Itoa(i)
+		}
+		// Creating the info file exclusively claims the name
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(info)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		dest := filepath.Join(filesDir, name)
+		if err == nil {
+			if _, statErr := os.Lstat(dest); statErr == nil {
+				os.Remove(infoPath)
+				continue
+			}
+			err = os.Rename(path, dest)
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return "", err
+		}
+		return dest, nil
+	}
+}
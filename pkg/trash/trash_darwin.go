@@ -0,0 +1,40 @@
+//go:build darwin
+
+package trash
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// moveToTrash asks the Finder to move path to the Trash, so "Put Back"
+// works. Without the Finder, such as over SSH, the folder is moved to
+// ~/.Trash directly.
+func moveToTrash(path string) error {
+	quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(path)
+	script := `tell application "Finder" to delete POSIX file "` + quoted + `"`
+	if exec.Command("osascript", "-e", script).Run() == nil {
+		return nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	trashDir := filepath.Join(home, ".Trash")
+	base := filepath.Base(path)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = base + " " + strconv.Itoa(i)
+		}
+		dest := filepath.Join(trashDir, name)
+		if _, err := os.Lstat(dest); err == nil {
+			continue
+		}
+		return os.Rename(path, dest)
+	}
+}
@@ -0,0 +1,40 @@
+//go:build !darwin && !windows
+
+package trash
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// moveToTrash moves path to the freedesktop.org trash in the user's home.
+// Folders on another file system cannot be moved there, so gio or
+// trash-put, which know the trash folders of other mounts, are tried
+// instead when installed.
+func moveToTrash(path string) error {
+	_, err := freedesktopTrash(homeTrashDir(), path, time.Now())
+	if err == nil {
+		return nil
+	}
+	for _, command := range [][]string{{"gio", "trash"}, {"trash-put"}} {
+		if _, lookErr := exec.LookPath(command[0]); lookErr != nil {
+			continue
+		}
+		if exec.Command(command[0], append(command[1:], path)...).Run() == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// homeTrashDir returns $XDG_DATA_HOME/Trash, by default
+// ~/.local/share/Trash
+func homeTrashDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "Trash")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "Trash")
+}
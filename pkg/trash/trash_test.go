@@ -0,0 +1,56 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFreedesktopTrash(t *testing.T) {
+	dir := t.TempDir()
+	trashDir := filepath.Join(dir, "Trash")
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.Local)
+
+	moved := make([]string, 2)
+	for i := range moved {
+		project := filepath.Join(dir, "my project")
+		os.MkdirAll(project, 0755)
+		os.WriteFile(filepath.Join(project, "main.go"), []byte("package main"), 0644)
+
+		dest, err := freedesktopTrash(trashDir, project, now)
+		if err != nil {
+			t.Fatalf("freedesktopTrash() error = %v", err)
+		}
+		if _, err := os.Stat(project); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be gone", project)
+		}
+		if _, err := os.Stat(filepath.Join(dest, "main.go")); err != nil {
+			t.Errorf("expected the folder's files in %s: %v", dest, err)
+		}
+		moved[i] = dest
+	}
+
+	// A second folder with the same name gets a suffix
+	if filepath.Base(moved[0]) != "my project" || filepath.Base(moved[1]) != "my project.2" {
+		t.Errorf("unexpected trash names %v", moved)
+	}
+
+	info, err := os.ReadFile(filepath.Join(trashDir, "info", "my project.2.trashinfo"))
+	if err != nil {
+		t.Fatalf("expected a .trashinfo file: %v", err)
+	}
+	want := "[Trash Info]\nPath=" + filepath.ToSlash(strings.ReplaceAll(dir, " ", "%20")) + "/my%20project\nDeletionDate=2024-05-01T12:30:00\n"
+	if filepath.Separator == '/' && string(info) != want {
+		t.Errorf("trashinfo = %q, want %q", info, want)
+	}
+
+	if _, err := freedesktopTrash(trashDir, filepath.Join(dir, "missing"), now); err == nil {
+		t.Error("expected an error for a missing folder")
+	}
+	entries, _ := os.ReadDir(filepath.Join(trashDir, "info"))
+	if len(entries) != 2 {
+		t.Errorf("expected the failed move to leave no .trashinfo file, got %d", len(entries))
+	}
+}
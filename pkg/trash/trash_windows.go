@@ -0,0 +1,25 @@
+//go:build windows
+
+package trash
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// moveToTrash sends path to the Recycle Bin through the .NET
+// FileSystem.DeleteDirectory method, which PowerShell can call
+func moveToTrash(path string) error {
+	quoted := "'" + strings.ReplaceAll(path, "'", "''") + "'"
+	script := "Add-Type -AssemblyName Microsoft.VisualBasic; " +
+		"[Microsoft.VisualBasic.FileIO.FileSystem]::DeleteDirectory(" + quoted + ", 'OnlyErrorDialogs', 'SendToRecycleBin')"
+	out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}